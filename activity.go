@@ -0,0 +1,131 @@
+package shipping
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CustomActivityBase is the first handling event type available to
+// activities defined outside this package, such as inspections or weighing.
+// Types below it are reserved for the standard activities.
+const CustomActivityBase HandlingEventType = 100
+
+// IsCustom returns whether the handling event type is one defined outside
+// this package.
+func (t HandlingEventType) IsCustom() bool {
+	return t >= CustomActivityBase
+}
+
+// ActivityType defines a handling activity beyond the standard ones, and how
+// it affects the delivery of a cargo. Custom activities take place in port,
+// between the standard activities, without moving the cargo.
+type ActivityType struct {
+	// Type identifies the activity in handling events. It must be
+	// CustomActivityBase or above.
+	Type HandlingEventType
+
+	// Name is how the activity is reported, e.g. "Fumigation".
+	Name string
+
+	// TransportStatus is where a cargo is after the activity. Defaults to
+	// InPort.
+	TransportStatus TransportStatus
+
+	// IsExpected returns whether the itinerary of a cargo expects the
+	// activity. Defaults to any location the itinerary loads or unloads the
+	// cargo at.
+	IsExpected func(a HandlingActivity, itinerary Itinerary) bool
+
+	// Next returns the activity expected to follow. Defaults to resuming the
+	// itinerary where the activity took place: loading the cargo onto the
+	// leg departing from there, or claiming it at its final destination.
+	Next func(a HandlingActivity, itinerary Itinerary) HandlingActivity
+}
+
+func (at ActivityType) transportStatus() TransportStatus {
+	if at.TransportStatus == NotReceived {
+		return InPort
+	}
+	return at.TransportStatus
+}
+
+func (at ActivityType) isExpected(a HandlingActivity, itinerary Itinerary) bool {
+	if at.IsExpected != nil {
+		return at.IsExpected(a, itinerary)
+	}
+	for _, l := range itinerary.Legs {
+		if l.LoadLocation == a.Location || l.UnloadLocation == a.Location {
+			return true
+		}
+	}
+	return false
+}
+
+func (at ActivityType) next(a HandlingActivity, itinerary Itinerary) HandlingActivity {
+	if at.Next != nil {
+		return at.Next(a, itinerary)
+	}
+	for _, l := range itinerary.Legs {
+		if l.LoadLocation == a.Location {
+			return expectLoad(l)
+		}
+	}
+	if itinerary.FinalArrivalLocation() == a.Location {
+		return HandlingActivity{Type: Claim, Location: a.Location}
+	}
+	return HandlingActivity{}
+}
+
+// ActivityTypes are the custom activities an operator handles cargos with.
+type ActivityTypes []ActivityType
+
+// Find returns the definition of a custom handling event type.
+func (ts ActivityTypes) Find(t HandlingEventType) (ActivityType, bool) {
+	for _, at := range ts {
+		if at.Type == t {
+			return at, true
+		}
+	}
+	return ActivityType{}, false
+}
+
+// Parse parses the name of a standard or custom handling event type,
+// ignoring case.
+func (ts ActivityTypes) Parse(s string) (HandlingEventType, error) {
+	for _, at := range ts {
+		if strings.EqualFold(s, at.Name) {
+			return at.Type, nil
+		}
+	}
+	return ParseHandlingEventType(s)
+}
+
+// Name returns the name of a standard or custom handling event type.
+func (ts ActivityTypes) Name(t HandlingEventType) string {
+	if at, ok := ts.Find(t); ok {
+		return at.Name
+	}
+	return t.String()
+}
+
+// Validate checks that every custom activity uses a type of its own, above
+// the standard ones, and a name of its own.
+func (ts ActivityTypes) Validate() error {
+	for i, at := range ts {
+		if !at.Type.IsCustom() {
+			return fmt.Errorf("activity %q: type %d is reserved", at.Name, at.Type)
+		}
+		if at.Name == "" {
+			return fmt.Errorf("activity %d: missing name", at.Type)
+		}
+		if _, err := ParseHandlingEventType(at.Name); err == nil {
+			return fmt.Errorf("activity %q: name of a standard activity", at.Name)
+		}
+		for _, other := range ts[:i] {
+			if other.Type == at.Type || strings.EqualFold(other.Name, at.Name) {
+				return fmt.Errorf("activity %q: defined twice", at.Name)
+			}
+		}
+	}
+	return nil
+}
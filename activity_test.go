@@ -0,0 +1,131 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	inspection HandlingEventType = CustomActivityBase + iota
+	fumigation
+	weighing
+)
+
+func TestStandardDeliveryPolicy_Activities(t *testing.T) {
+	var (
+		rs = RouteSpecification{Origin: CNHKG, Destination: SESTO}
+		i  = Itinerary{Legs: []Leg{
+			NewLeg("V100", CNHKG, DEHAM, time.Time{}, time.Time{}),
+			NewLeg("V200", DEHAM, SESTO, time.Time{}, time.Time{}),
+		}}
+	)
+
+	p := StandardDeliveryPolicy{Activities: ActivityTypes{
+		{Type: inspection, Name: "Inspection"},
+		{
+			// Fumigation is only done at the origin, and the cargo is
+			// inspected again afterwards.
+			Type: fumigation,
+			Name: "Fumigation",
+			IsExpected: func(a HandlingActivity, i Itinerary) bool {
+				return a.Location == i.InitialDepartureLocation()
+			},
+			Next: func(a HandlingActivity, i Itinerary) HandlingActivity {
+				return HandlingActivity{Type: inspection, Location: a.Location}
+			},
+		},
+	}}
+
+	event := func(typ HandlingEventType, loc UNLocode) HandlingHistory {
+		return HandlingHistory{HandlingEvents: []HandlingEvent{
+			{Activity: HandlingActivity{Type: typ, Location: loc}},
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		history HandlingHistory
+
+		misdirected bool
+		status      TransportStatus
+		next        HandlingActivity
+	}{
+		{"inspected at origin", event(inspection, CNHKG), false, InPort, HandlingActivity{Type: Load, Location: CNHKG, VoyageNumber: "V100"}},
+		{"inspected at transshipment", event(inspection, DEHAM), false, InPort, HandlingActivity{Type: Load, Location: DEHAM, VoyageNumber: "V200"}},
+		{"inspected at destination", event(inspection, SESTO), false, InPort, HandlingActivity{Type: Claim, Location: SESTO}},
+		{"inspected elsewhere", event(inspection, NLRTM), true, InPort, HandlingActivity{}},
+		{"fumigated at origin", event(fumigation, CNHKG), false, InPort, HandlingActivity{Type: inspection, Location: CNHKG}},
+		{"fumigated at transshipment", event(fumigation, DEHAM), true, InPort, HandlingActivity{}},
+		{"weighed, undefined", event(weighing, DEHAM), false, Unknown, HandlingActivity{}},
+	}
+
+	for _, tt := range tests {
+		d := DeriveDeliveryWith(p, rs, i, tt.history)
+
+		if d.IsMisdirected != tt.misdirected {
+			t.Errorf("%s: IsMisdirected = %v; want = %v", tt.name, d.IsMisdirected, tt.misdirected)
+		}
+		if d.TransportStatus != tt.status {
+			t.Errorf("%s: TransportStatus = %v; want = %v", tt.name, d.TransportStatus, tt.status)
+		}
+		if d.NextExpectedActivity != tt.next {
+			t.Errorf("%s: NextExpectedActivity = %+v; want = %+v", tt.name, d.NextExpectedActivity, tt.next)
+		}
+	}
+}
+
+func TestActivityTypes_Parse(t *testing.T) {
+	ts := ActivityTypes{{Type: inspection, Name: "Inspection"}}
+
+	tests := []struct {
+		in   string
+		want HandlingEventType
+	}{
+		{"inspection", inspection},
+		{"Load", Load},
+		{"Activity 101", fumigation},
+	}
+
+	for _, tt := range tests {
+		got, err := ts.Parse(tt.in)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %v; want = %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"Weighing", "Activity 5", "Activity x"} {
+		if _, err := ts.Parse(s); err != ErrUnknownHandlingEventType {
+			t.Errorf("Parse(%q): err = %v; want = %v", s, err, ErrUnknownHandlingEventType)
+		}
+	}
+
+	if got := ts.Name(inspection); got != "Inspection" {
+		t.Errorf("Name(inspection) = %q", got)
+	}
+	if got := ts.Name(fumigation); got != "Activity 101" {
+		t.Errorf("Name(fumigation) = %q", got)
+	}
+}
+
+func TestActivityTypes_Validate(t *testing.T) {
+	tests := []struct {
+		ts ActivityTypes
+		ok bool
+	}{
+		{ActivityTypes{{Type: inspection, Name: "Inspection"}, {Type: weighing, Name: "Weighing"}}, true},
+		{ActivityTypes{{Type: Customs + 1, Name: "Inspection"}}, false},
+		{ActivityTypes{{Type: inspection}}, false},
+		{ActivityTypes{{Type: inspection, Name: "load"}}, false},
+		{ActivityTypes{{Type: inspection, Name: "Inspection"}, {Type: inspection, Name: "Weighing"}}, false},
+		{ActivityTypes{{Type: inspection, Name: "Inspection"}, {Type: weighing, Name: "INSPECTION"}}, false},
+	}
+
+	for _, tt := range tests {
+		if err := tt.ts.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate(%+v) = %v; want ok = %v", tt.ts, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package shipping
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pborman/uuid"
+)
+
+// AmendmentID uniquely identifies an amendment.
+type AmendmentID string
+
+// NextAmendmentID generates a new amendment ID.
+func NextAmendmentID() AmendmentID {
+	return AmendmentID(strings.Split(strings.ToUpper(uuid.New()), "-")[0])
+}
+
+// AmendmentType is the kind of change an amendment makes to a booking.
+type AmendmentType int
+
+// Valid amendment types.
+const (
+	DestinationChange AmendmentType = iota
+	DeadlineExtension
+)
+
+func (t AmendmentType) String() string {
+	switch t {
+	case DestinationChange:
+		return "DestinationChange"
+	case DeadlineExtension:
+		return "DeadlineExtension"
+	}
+	return ""
+}
+
+// AmendmentStatus describes where an amendment is in the approval workflow.
+type AmendmentStatus int
+
+// Valid amendment statuses.
+const (
+	AmendmentPending AmendmentStatus = iota
+	AmendmentApproved
+	AmendmentRejected
+)
+
+func (s AmendmentStatus) String() string {
+	switch s {
+	case AmendmentPending:
+		return "Pending"
+	case AmendmentApproved:
+		return "Approved"
+	case AmendmentRejected:
+		return "Rejected"
+	}
+	return ""
+}
+
+// Amendment is a requested change to the booking of a cargo that is only
+// applied once approved.
+type Amendment struct {
+	ID         AmendmentID
+	TrackingID TrackingID
+	Type       AmendmentType
+
+	// Destination is the new destination of a destination change.
+	Destination UNLocode
+
+	// ArrivalDeadline is the new arrival deadline of a deadline extension.
+	ArrivalDeadline time.Time
+
+	Status      AmendmentStatus
+	RequestedBy UserID
+	Requested   time.Time
+
+	// DecidedBy is the user who approved or rejected the amendment, and
+	// Reason why, if given.
+	DecidedBy UserID
+	Decided   time.Time
+	Reason    string
+}
+
+var (
+	// ErrUnknownAmendment is used when an amendment could not be found.
+	ErrUnknownAmendment = errors.New("unknown amendment")
+
+	// ErrAmendmentDecided is used when deciding on an amendment that has
+	// already been approved or rejected.
+	ErrAmendmentDecided = errors.New("amendment already decided")
+
+	// ErrApprovalRequired is used when a change to a booking may only be
+	// made through an approved amendment.
+	ErrApprovalRequired = errors.New("change requires approval")
+)
+
+// Approve marks the amendment as approved.
+func (a *Amendment) Approve(by UserID, at time.Time) error {
+	return a.decide(AmendmentApproved, by, at, "")
+}
+
+// Reject marks the amendment as rejected.
+func (a *Amendment) Reject(by UserID, at time.Time, reason string) error {
+	return a.decide(AmendmentRejected, by, at, reason)
+}
+
+func (a *Amendment) decide(s AmendmentStatus, by UserID, at time.Time, reason string) error {
+	if a.Status != AmendmentPending {
+		return ErrAmendmentDecided
+	}
+	a.Status = s
+	a.DecidedBy = by
+	a.Decided = at
+	a.Reason = reason
+	return nil
+}
+
+// ApplyTo changes the route specification of the cargo as requested.
+func (a Amendment) ApplyTo(c *Cargo) {
+	rs := c.RouteSpecification
+	switch a.Type {
+	case DestinationChange:
+		rs.Destination = a.Destination
+	case DeadlineExtension:
+		rs.ArrivalDeadline = a.ArrivalDeadline
+	}
+	c.SpecifyNewRoute(rs)
+}
+
+// HasBeenLoaded returns whether the cargo has left its origin, after which
+// changing its destination requires approval.
+func (c *Cargo) HasBeenLoaded() bool {
+	switch c.Delivery.TransportStatus {
+	case NotReceived:
+		return false
+	case InPort:
+		return c.Delivery.LastKnownLocation != c.Origin
+	}
+	return true
+}
+
+// AmendmentRepository provides access an amendment store.
+type AmendmentRepository interface {
+	Store(a *Amendment) error
+	Find(id AmendmentID) (*Amendment, error)
+
+	// FindByCargo returns the amendments of a cargo, in the order they were
+	// requested.
+	FindByCargo(id TrackingID) []*Amendment
+
+	// FindPending returns the amendments awaiting a decision, in the order
+	// they were requested.
+	FindPending() []*Amendment
+}
@@ -0,0 +1,97 @@
+package amendment
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) RequestDestinationChange(ctx context.Context, by Credentials, id shipping.TrackingID, destination shipping.UNLocode) (shipping.AmendmentID, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "request_destination_change").Add(1)
+		s.requestLatency.With("method", "request_destination_change").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.RequestDestinationChange(ctx, by, id, destination)
+}
+
+func (s *instrumentingService) RequestDeadlineExtension(ctx context.Context, by Credentials, id shipping.TrackingID, deadline time.Time) (shipping.AmendmentID, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "request_deadline_extension").Add(1)
+		s.requestLatency.With("method", "request_deadline_extension").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.RequestDeadlineExtension(ctx, by, id, deadline)
+}
+
+func (s *instrumentingService) Approve(ctx context.Context, by Credentials, id shipping.AmendmentID) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "approve").Add(1)
+		s.requestLatency.With("method", "approve").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Approve(ctx, by, id)
+}
+
+func (s *instrumentingService) Reject(ctx context.Context, by Credentials, id shipping.AmendmentID, reason string) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "reject").Add(1)
+		s.requestLatency.With("method", "reject").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Reject(ctx, by, id, reason)
+}
+
+func (s *instrumentingService) Amendments(ctx context.Context, id shipping.TrackingID) []Amendment {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "list_amendments").Add(1)
+		s.requestLatency.With("method", "list_amendments").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Amendments(ctx, id)
+}
+
+func (s *instrumentingService) Pending(ctx context.Context) []Amendment {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "list_pending").Add(1)
+		s.requestLatency.With("method", "list_pending").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Pending(ctx)
+}
+
+func (s *instrumentingService) RegisterUser(ctx context.Context, id shipping.UserID, name, key string, roles []shipping.Role) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "register_user").Add(1)
+		s.requestLatency.With("method", "register_user").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.RegisterUser(ctx, id, name, key, roles)
+}
+
+func (s *instrumentingService) Users(ctx context.Context) []User {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "list_users").Add(1)
+		s.requestLatency.With("method", "list_users").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Users(ctx)
+}
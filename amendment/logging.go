@@ -0,0 +1,130 @@
+package amendment
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) RequestDestinationChange(ctx context.Context, by Credentials, id shipping.TrackingID, destination shipping.UNLocode) (amendment shipping.AmendmentID, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "request_destination_change",
+			"request_id", correlation.FromContext(ctx),
+			"user", by.User,
+			"tracking_id", id,
+			"destination", destination,
+			"amendment_id", amendment,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.RequestDestinationChange(ctx, by, id, destination)
+}
+
+func (s *loggingService) RequestDeadlineExtension(ctx context.Context, by Credentials, id shipping.TrackingID, deadline time.Time) (amendment shipping.AmendmentID, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "request_deadline_extension",
+			"request_id", correlation.FromContext(ctx),
+			"user", by.User,
+			"tracking_id", id,
+			"arrival_deadline", deadline,
+			"amendment_id", amendment,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.RequestDeadlineExtension(ctx, by, id, deadline)
+}
+
+func (s *loggingService) Approve(ctx context.Context, by Credentials, id shipping.AmendmentID) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "approve",
+			"request_id", correlation.FromContext(ctx),
+			"user", by.User,
+			"amendment_id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Approve(ctx, by, id)
+}
+
+func (s *loggingService) Reject(ctx context.Context, by Credentials, id shipping.AmendmentID, reason string) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "reject",
+			"request_id", correlation.FromContext(ctx),
+			"user", by.User,
+			"amendment_id", id,
+			"reason", reason,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Reject(ctx, by, id, reason)
+}
+
+func (s *loggingService) Amendments(ctx context.Context, id shipping.TrackingID) []Amendment {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_amendments",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.Amendments(ctx, id)
+}
+
+func (s *loggingService) Pending(ctx context.Context) []Amendment {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_pending",
+			"request_id", correlation.FromContext(ctx),
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.Pending(ctx)
+}
+
+func (s *loggingService) RegisterUser(ctx context.Context, id shipping.UserID, name, key string, roles []shipping.Role) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "register_user",
+			"request_id", correlation.FromContext(ctx),
+			"user", id,
+			"roles", len(roles),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.RegisterUser(ctx, id, name, key, roles)
+}
+
+func (s *loggingService) Users(ctx context.Context) []User {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_users",
+			"request_id", correlation.FromContext(ctx),
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.Users(ctx)
+}
@@ -0,0 +1,327 @@
+// Package amendment provides the use-case of changing bookings subject to
+// approval. Used by views facing the back office.
+package amendment
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// Credentials identify and authenticate the user requesting or deciding on
+// an amendment.
+type Credentials struct {
+	User shipping.UserID
+	Key  string
+}
+
+// Service is the interface that provides amendment methods. Changes are
+// requested by any registered user, but only applied to the cargo once
+// approved by a user with the approver role.
+type Service interface {
+	// RequestDestinationChange requests a cargo to be redirected to another
+	// destination, which is the only way to do so once it has been loaded.
+	RequestDestinationChange(ctx context.Context, by Credentials, id shipping.TrackingID, destination shipping.UNLocode) (shipping.AmendmentID, error)
+
+	// RequestDeadlineExtension requests the arrival deadline of a cargo to be
+	// moved to a later time.
+	RequestDeadlineExtension(ctx context.Context, by Credentials, id shipping.TrackingID, deadline time.Time) (shipping.AmendmentID, error)
+
+	// Approve approves a pending amendment and applies it to the cargo.
+	Approve(ctx context.Context, by Credentials, id shipping.AmendmentID) error
+
+	// Reject rejects a pending amendment, leaving the cargo as is.
+	Reject(ctx context.Context, by Credentials, id shipping.AmendmentID, reason string) error
+
+	// Amendments returns the amendments requested for a cargo.
+	Amendments(ctx context.Context, id shipping.TrackingID) []Amendment
+
+	// Pending returns the amendments awaiting a decision, oldest first.
+	Pending(ctx context.Context) []Amendment
+
+	// RegisterUser registers a user that authenticates with the given key
+	// and is granted the given roles. Registering an existing user replaces
+	// its name, key and roles.
+	RegisterUser(ctx context.Context, id shipping.UserID, name, key string, roles []shipping.Role) error
+
+	// Users returns all registered users.
+	Users(ctx context.Context) []User
+}
+
+type service struct {
+	amendments shipping.AmendmentRepository
+	cargos     shipping.CargoRepository
+	locations  shipping.LocationRepository
+	users      shipping.UserRepository
+}
+
+func (s *service) RequestDestinationChange(ctx context.Context, by Credentials, id shipping.TrackingID, destination shipping.UNLocode) (shipping.AmendmentID, error) {
+	if id == "" || destination == "" {
+		return "", ErrInvalidArgument
+	}
+
+	if err := s.authorize(by, ""); err != nil {
+		return "", err
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return "", err
+	}
+
+	if c.IsConsolidated() || c.IsMaster() {
+		return "", shipping.ErrConsolidated
+	}
+
+	if _, err := s.locations.Find(destination); err != nil {
+		return "", err
+	}
+
+	return s.request(&shipping.Amendment{
+		TrackingID:  id,
+		Type:        shipping.DestinationChange,
+		Destination: destination,
+		RequestedBy: by.User,
+	})
+}
+
+func (s *service) RequestDeadlineExtension(ctx context.Context, by Credentials, id shipping.TrackingID, deadline time.Time) (shipping.AmendmentID, error) {
+	if id == "" || deadline.IsZero() {
+		return "", ErrInvalidArgument
+	}
+
+	if err := s.authorize(by, ""); err != nil {
+		return "", err
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return "", err
+	}
+
+	// Consolidated cargos are bound by the deadline of their master
+	// shipment.
+	if c.IsConsolidated() {
+		return "", shipping.ErrConsolidated
+	}
+
+	if !deadline.After(c.RouteSpecification.ArrivalDeadline) {
+		return "", ErrInvalidArgument
+	}
+
+	return s.request(&shipping.Amendment{
+		TrackingID:      id,
+		Type:            shipping.DeadlineExtension,
+		ArrivalDeadline: deadline,
+		RequestedBy:     by.User,
+	})
+}
+
+// request stores a new amendment, pending approval.
+func (s *service) request(a *shipping.Amendment) (shipping.AmendmentID, error) {
+	a.ID = shipping.NextAmendmentID()
+	a.Status = shipping.AmendmentPending
+	a.Requested = time.Now()
+
+	if err := s.amendments.Store(a); err != nil {
+		return "", err
+	}
+
+	return a.ID, nil
+}
+
+func (s *service) Approve(ctx context.Context, by Credentials, id shipping.AmendmentID) error {
+	if id == "" {
+		return ErrInvalidArgument
+	}
+
+	if err := s.authorize(by, shipping.ApproverRole); err != nil {
+		return err
+	}
+
+	a, err := s.amendments.Find(id)
+	if err != nil {
+		return err
+	}
+
+	if err := a.Approve(by.User, time.Now()); err != nil {
+		return err
+	}
+
+	c, err := s.cargos.Find(a.TrackingID)
+	if err != nil {
+		return err
+	}
+
+	// The cargo may have been consolidated since the amendment was
+	// requested.
+	if c.IsConsolidated() || (a.Type == shipping.DestinationChange && c.IsMaster()) {
+		return shipping.ErrConsolidated
+	}
+
+	a.ApplyTo(c)
+
+	if err := s.cargos.Store(c); err != nil {
+		return err
+	}
+
+	return s.amendments.Store(a)
+}
+
+func (s *service) Reject(ctx context.Context, by Credentials, id shipping.AmendmentID, reason string) error {
+	if id == "" {
+		return ErrInvalidArgument
+	}
+
+	if err := s.authorize(by, shipping.ApproverRole); err != nil {
+		return err
+	}
+
+	a, err := s.amendments.Find(id)
+	if err != nil {
+		return err
+	}
+
+	if err := a.Reject(by.User, time.Now(), reason); err != nil {
+		return err
+	}
+
+	return s.amendments.Store(a)
+}
+
+// authorize checks that the user is registered, authenticates and has been
+// granted the role, if any.
+func (s *service) authorize(by Credentials, role shipping.Role) error {
+	if by.User == "" {
+		return shipping.ErrUnauthorizedUser
+	}
+
+	u, err := s.users.Find(by.User)
+	if err == shipping.ErrUnknownUser {
+		return shipping.ErrUnauthorizedUser
+	}
+	if err != nil {
+		return err
+	}
+
+	if !u.Authenticate(by.Key) {
+		return shipping.ErrUnauthorizedUser
+	}
+
+	if role != "" && !u.HasRole(role) {
+		return shipping.ErrRoleRequired
+	}
+
+	return nil
+}
+
+func (s *service) Amendments(ctx context.Context, id shipping.TrackingID) []Amendment {
+	return assembleAll(s.amendments.FindByCargo(id))
+}
+
+func (s *service) Pending(ctx context.Context) []Amendment {
+	return assembleAll(s.amendments.FindPending())
+}
+
+func (s *service) RegisterUser(ctx context.Context, id shipping.UserID, name, key string, roles []shipping.Role) error {
+	if id == "" || key == "" {
+		return ErrInvalidArgument
+	}
+
+	for _, r := range roles {
+		if r != shipping.ApproverRole && r != shipping.DataProtectionRole {
+			return ErrInvalidArgument
+		}
+	}
+
+	return s.users.Store(&shipping.User{
+		ID:      id,
+		Name:    name,
+		Roles:   roles,
+		KeyHash: shipping.HashUserKey(key),
+	})
+}
+
+func (s *service) Users(ctx context.Context) []User {
+	var result []User
+	for _, u := range s.users.FindAll() {
+		roles := make([]string, len(u.Roles))
+		for i, r := range u.Roles {
+			roles[i] = string(r)
+		}
+		result = append(result, User{
+			ID:    string(u.ID),
+			Name:  u.Name,
+			Roles: roles,
+		})
+	}
+	return result
+}
+
+// NewService creates an amendment service with necessary dependencies.
+func NewService(amendments shipping.AmendmentRepository, cargos shipping.CargoRepository, locations shipping.LocationRepository, users shipping.UserRepository) Service {
+	return &service{
+		amendments: amendments,
+		cargos:     cargos,
+		locations:  locations,
+		users:      users,
+	}
+}
+
+// Amendment is a read model for amendment views.
+type Amendment struct {
+	ID              string     `json:"id"`
+	TrackingID      string     `json:"tracking_id"`
+	Type            string     `json:"type"`
+	Destination     string     `json:"destination,omitempty"`
+	ArrivalDeadline *time.Time `json:"arrival_deadline,omitempty"`
+	Status          string     `json:"status"`
+	RequestedBy     string     `json:"requested_by"`
+	Requested       time.Time  `json:"requested"`
+	DecidedBy       string     `json:"decided_by,omitempty"`
+	Decided         *time.Time `json:"decided,omitempty"`
+	Reason          string     `json:"reason,omitempty"`
+}
+
+// User is a read model for amendment views.
+type User struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
+}
+
+func assembleAll(amendments []*shipping.Amendment) []Amendment {
+	result := make([]Amendment, 0, len(amendments))
+	for _, a := range amendments {
+		result = append(result, assemble(a))
+	}
+	return result
+}
+
+func assemble(a *shipping.Amendment) Amendment {
+	result := Amendment{
+		ID:          string(a.ID),
+		TrackingID:  string(a.TrackingID),
+		Type:        a.Type.String(),
+		Destination: string(a.Destination),
+		Status:      a.Status.String(),
+		RequestedBy: string(a.RequestedBy),
+		Requested:   a.Requested,
+		DecidedBy:   string(a.DecidedBy),
+		Reason:      a.Reason,
+	}
+	if !a.ArrivalDeadline.IsZero() {
+		t := a.ArrivalDeadline
+		result.ArrivalDeadline = &t
+	}
+	if !a.Decided.IsZero() {
+		t := a.Decided
+		result.Decided = &t
+	}
+	return result
+}
@@ -0,0 +1,167 @@
+package amendment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+var (
+	requester = Credentials{User: "jdoe", Key: "secret"}
+	approver  = Credentials{User: "asmith", Key: "secret"}
+)
+
+func newUserRepository() *mock.UserRepository {
+	stored := map[shipping.UserID]shipping.User{
+		"jdoe":   {ID: "jdoe", KeyHash: shipping.HashUserKey("secret")},
+		"asmith": {ID: "asmith", KeyHash: shipping.HashUserKey("secret"), Roles: []shipping.Role{shipping.ApproverRole}},
+	}
+
+	var users mock.UserRepository
+	users.FindFn = func(id shipping.UserID) (*shipping.User, error) {
+		u, ok := stored[id]
+		if !ok {
+			return nil, shipping.ErrUnknownUser
+		}
+		return &u, nil
+	}
+	return &users
+}
+
+func newAmendmentRepository() *mock.AmendmentRepository {
+	stored := make(map[shipping.AmendmentID]shipping.Amendment)
+
+	var amendments mock.AmendmentRepository
+	amendments.StoreFn = func(a *shipping.Amendment) error {
+		stored[a.ID] = *a
+		return nil
+	}
+	amendments.FindFn = func(id shipping.AmendmentID) (*shipping.Amendment, error) {
+		a, ok := stored[id]
+		if !ok {
+			return nil, shipping.ErrUnknownAmendment
+		}
+		return &a, nil
+	}
+	return &amendments
+}
+
+func newCargoRepository(c *shipping.Cargo) *mock.CargoRepository {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		if id != c.TrackingID {
+			return nil, shipping.ErrUnknownCargo
+		}
+		return c, nil
+	}
+	cargos.StoreFn = func(*shipping.Cargo) error {
+		return nil
+	}
+	return &cargos
+}
+
+func newLocationRepository() *mock.LocationRepository {
+	var locations mock.LocationRepository
+	locations.FindFn = func(loc shipping.UNLocode) (*shipping.Location, error) {
+		return &shipping.Location{UNLocode: loc}, nil
+	}
+	return &locations
+}
+
+func TestApproveDestinationChange(t *testing.T) {
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{Origin: shipping.SESTO, Destination: shipping.CNHKG})
+
+	cargos := newCargoRepository(c)
+
+	s := NewService(newAmendmentRepository(), cargos, newLocationRepository(), newUserRepository())
+
+	id, err := s.RequestDestinationChange(context.Background(), requester, "ABC", shipping.AUMEL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cargos.StoreInvoked {
+		t.Fatal("cargo changed before approval")
+	}
+
+	if err := s.Approve(context.Background(), requester, id); err != shipping.ErrRoleRequired {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrRoleRequired)
+	}
+
+	if err := s.Approve(context.Background(), approver, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if !cargos.StoreInvoked {
+		t.Error("cargo not stored")
+	}
+	if c.RouteSpecification.Destination != shipping.AUMEL {
+		t.Errorf("Destination = %s; want = %s", c.RouteSpecification.Destination, shipping.AUMEL)
+	}
+
+	if err := s.Reject(context.Background(), approver, id, "changed my mind"); err != shipping.ErrAmendmentDecided {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrAmendmentDecided)
+	}
+}
+
+func TestRejectDeadlineExtension(t *testing.T) {
+	deadline := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{
+		Origin:          shipping.SESTO,
+		Destination:     shipping.CNHKG,
+		ArrivalDeadline: deadline,
+	})
+
+	cargos := newCargoRepository(c)
+	amendments := newAmendmentRepository()
+
+	s := NewService(amendments, cargos, newLocationRepository(), newUserRepository())
+
+	if _, err := s.RequestDeadlineExtension(context.Background(), requester, "ABC", deadline); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+
+	id, err := s.RequestDeadlineExtension(context.Background(), requester, "ABC", deadline.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Reject(context.Background(), approver, id, "no room"); err != nil {
+		t.Fatal(err)
+	}
+
+	if cargos.StoreInvoked {
+		t.Error("cargo changed by rejected amendment")
+	}
+
+	a, err := amendments.Find(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Status != shipping.AmendmentRejected {
+		t.Errorf("a.Status = %s; want = %s", a.Status, shipping.AmendmentRejected)
+	}
+	if a.DecidedBy != approver.User {
+		t.Errorf("a.DecidedBy = %s; want = %s", a.DecidedBy, approver.User)
+	}
+}
+
+func TestRequestUnauthorized(t *testing.T) {
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{Origin: shipping.SESTO, Destination: shipping.CNHKG})
+
+	s := NewService(newAmendmentRepository(), newCargoRepository(c), newLocationRepository(), newUserRepository())
+
+	for _, by := range []Credentials{
+		{User: "jdoe", Key: "wrong"},
+		{User: "unknown", Key: "secret"},
+		{},
+	} {
+		if _, err := s.RequestDestinationChange(context.Background(), by, "ABC", shipping.AUMEL); err != shipping.ErrUnauthorizedUser {
+			t.Errorf("err = %v when requested by %q; want = %v", err, by.User, shipping.ErrUnauthorizedUser)
+		}
+	}
+}
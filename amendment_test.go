@@ -0,0 +1,81 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAmendmentDecide(t *testing.T) {
+	at := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	a := Amendment{Status: AmendmentPending}
+
+	if err := a.Reject("asmith", at, "too late"); err != nil {
+		t.Fatal(err)
+	}
+	if a.Status != AmendmentRejected {
+		t.Errorf("a.Status = %s; want = %s", a.Status, AmendmentRejected)
+	}
+	if a.DecidedBy != "asmith" || !a.Decided.Equal(at) || a.Reason != "too late" {
+		t.Errorf("decision = %s %v %q; want = asmith %v %q", a.DecidedBy, a.Decided, a.Reason, at, "too late")
+	}
+
+	if err := a.Approve("asmith", at); err != ErrAmendmentDecided {
+		t.Errorf("err = %v; want = %v", err, ErrAmendmentDecided)
+	}
+	if a.Status != AmendmentRejected {
+		t.Errorf("a.Status = %s; want = %s", a.Status, AmendmentRejected)
+	}
+}
+
+func TestAmendmentApplyTo(t *testing.T) {
+	deadline := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	c := NewCargo("ABC", RouteSpecification{
+		Origin:          SESTO,
+		Destination:     CNHKG,
+		ArrivalDeadline: deadline,
+	})
+
+	Amendment{Type: DestinationChange, Destination: AUMEL}.ApplyTo(c)
+
+	if c.RouteSpecification.Destination != AUMEL {
+		t.Errorf("Destination = %s; want = %s", c.RouteSpecification.Destination, AUMEL)
+	}
+	if !c.RouteSpecification.ArrivalDeadline.Equal(deadline) {
+		t.Errorf("ArrivalDeadline = %v; want = %v", c.RouteSpecification.ArrivalDeadline, deadline)
+	}
+
+	extended := deadline.AddDate(0, 0, 7)
+
+	Amendment{Type: DeadlineExtension, ArrivalDeadline: extended}.ApplyTo(c)
+
+	if !c.RouteSpecification.ArrivalDeadline.Equal(extended) {
+		t.Errorf("ArrivalDeadline = %v; want = %v", c.RouteSpecification.ArrivalDeadline, extended)
+	}
+	if c.RouteSpecification.Destination != AUMEL {
+		t.Errorf("Destination = %s; want = %s", c.RouteSpecification.Destination, AUMEL)
+	}
+}
+
+func TestCargoHasBeenLoaded(t *testing.T) {
+	for _, tt := range []struct {
+		status   TransportStatus
+		location UNLocode
+		want     bool
+	}{
+		{NotReceived, "", false},
+		{InPort, SESTO, false},
+		{OnboardCarrier, SESTO, true},
+		{InPort, CNHKG, true},
+		{Claimed, CNHKG, true},
+	} {
+		c := NewCargo("ABC", RouteSpecification{Origin: SESTO, Destination: AUMEL})
+		c.Delivery.TransportStatus = tt.status
+		c.Delivery.LastKnownLocation = tt.location
+
+		if got := c.HasBeenLoaded(); got != tt.want {
+			t.Errorf("HasBeenLoaded() = %v when %s at %s; want = %v", got, tt.status, tt.location, tt.want)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+// Package apptest boots the shipping application in-process, for end-to-end
+// tests and examples.
+//
+// The application is wired as in production, with the services behind the
+// HTTP API, but keeps its state in memory and routes cargos on the voyages
+// of its fixtures in place of the routing service. Requests are made through
+// the client package, exercising the API just as other applications would.
+package apptest
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/amendment"
+	"github.com/marcusolsson/goddd/audit"
+	"github.com/marcusolsson/goddd/booking"
+	"github.com/marcusolsson/goddd/carrier"
+	"github.com/marcusolsson/goddd/changefeed"
+	"github.com/marcusolsson/goddd/client"
+	"github.com/marcusolsson/goddd/command"
+	"github.com/marcusolsson/goddd/consolidation"
+	"github.com/marcusolsson/goddd/dashboard"
+	"github.com/marcusolsson/goddd/fixtures"
+	"github.com/marcusolsson/goddd/handling"
+	"github.com/marcusolsson/goddd/inbound"
+	"github.com/marcusolsson/goddd/inmem"
+	"github.com/marcusolsson/goddd/inspection"
+	"github.com/marcusolsson/goddd/notification"
+	"github.com/marcusolsson/goddd/portal"
+	"github.com/marcusolsson/goddd/portstatus"
+	"github.com/marcusolsson/goddd/position"
+	"github.com/marcusolsson/goddd/privacy"
+	"github.com/marcusolsson/goddd/reconciliation"
+	"github.com/marcusolsson/goddd/replication"
+	"github.com/marcusolsson/goddd/routing"
+	"github.com/marcusolsson/goddd/scheduling"
+	"github.com/marcusolsson/goddd/server"
+	"github.com/marcusolsson/goddd/sla"
+	"github.com/marcusolsson/goddd/task"
+	"github.com/marcusolsson/goddd/telemetry"
+	"github.com/marcusolsson/goddd/tracking"
+)
+
+// ErrNoRoute is used when no route satisfies the route specification of a
+// cargo.
+var ErrNoRoute = errors.New("no route found")
+
+// terminalKey is the key of the terminals registered by the application,
+// one at every location, and terminalSecret the secret they sign their
+// reports with. adminToken is the token they are registered with.
+const (
+	terminalKey    = "apptest"
+	terminalSecret = "apptest-signing"
+	adminToken     = "apptest-admin"
+)
+
+// Option configures an application.
+type Option func(*config)
+
+type config struct {
+	dataset     fixtures.Dataset
+	routing     shipping.RoutingService
+	connections shipping.ConnectionTimes
+	maxBodySize int64
+	logger      log.Logger
+}
+
+// WithFixtures sets the dataset the repositories are seeded with, in
+// addition to the sample dataset.
+func WithFixtures(d fixtures.Dataset) Option {
+	return func(c *config) {
+		c.dataset = d
+	}
+}
+
+// WithRoutingService sets the routing service cargos are routed with, in
+// place of routing on the voyages in the repository.
+func WithRoutingService(rs shipping.RoutingService) Option {
+	return func(c *config) {
+		c.routing = rs
+	}
+}
+
+// WithConnectionTimes sets the minimum connection times at transshipment
+// ports.
+func WithConnectionTimes(ct shipping.ConnectionTimes) Option {
+	return func(c *config) {
+		c.connections = ct
+	}
+}
+
+// WithMaxBodySize sets the largest request body the API accepts, in bytes.
+func WithMaxBodySize(n int64) Option {
+	return func(c *config) {
+		c.maxBodySize = n
+	}
+}
+
+// WithLogger sets the logger of the application, which discards everything
+// by default.
+func WithLogger(l log.Logger) Option {
+	return func(c *config) {
+		c.logger = l
+	}
+}
+
+// Repositories holds the repositories of the application, for setting up
+// state and checking it in ways the API does not allow.
+type Repositories struct {
+	Cargos         shipping.CargoRepository
+	Locations      shipping.LocationRepository
+	Voyages        shipping.VoyageRepository
+	ServiceStrings shipping.ServiceStringRepository
+	HandlingEvents shipping.HandlingEventRepository
+	Customers      shipping.CustomerRepository
+	Tasks          shipping.TaskRepository
+	AuditEntries   shipping.AuditRepository
+	CargoChanges   shipping.CargoChangeRepository
+}
+
+// App is the shipping application running in-process.
+type App struct {
+	// URL is the base URL of the HTTP API, such as http://127.0.0.1:41231.
+	URL string
+
+	// Client calls the HTTP API of the application.
+	Client *client.Client
+
+	Repositories Repositories
+
+	srv    *httptest.Server
+	server *server.Server
+
+	mu        sync.Mutex
+	terminals map[shipping.UNLocode]bool
+}
+
+// New boots the application, listening on a random port of the loopback
+// interface. Close must be called to shut it down.
+func New(opts ...Option) (*App, error) {
+	cfg := config{
+		connections: shipping.ConnectionTimes{Default: 2 * time.Hour},
+		maxBodySize: server.DefaultMaxBodySize,
+		logger:      log.NewNopLogger(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	r := Repositories{
+		Cargos:         inmem.NewCargoRepository(),
+		Locations:      inmem.NewLocationRepository(),
+		Voyages:        inmem.NewVoyageRepository(),
+		ServiceStrings: inmem.NewServiceStringRepository(),
+		HandlingEvents: inmem.NewHandlingEventRepository(),
+		Customers:      inmem.NewCustomerRepository(),
+		Tasks:          inmem.NewTaskRepository(),
+		AuditEntries:   inmem.NewAuditRepository(),
+		CargoChanges:   inmem.NewCargoChangeRepository(),
+	}
+
+	var (
+		portStatuses = inmem.NewPortStatusRepository()
+		terminals    = inmem.NewTerminalRepository()
+		slas         = inmem.NewSLARepository()
+		users        = inmem.NewUserRepository()
+		amendments   = inmem.NewAmendmentRepository()
+		calendars    = inmem.NewWorkingCalendarRepository()
+		positions    = inmem.NewVesselPositionRepository()
+		telemetryLog = inmem.NewTelemetryRepository()
+		drafts       = inmem.NewBookingDraftRepository()
+		variances    = inmem.NewHandlingVarianceRepository()
+		releaseCodes = inmem.NewReleaseCodeRepository()
+		reportNonces = inmem.NewReportNonceRepository()
+		checkpoints  = inmem.NewProjectionCheckpointRepository()
+	)
+
+	if err := cfg.dataset.Seed(r.Locations, r.Voyages, r.ServiceStrings); err != nil {
+		return nil, err
+	}
+
+	rs := cfg.routing
+	if rs == nil {
+		rs = routing.NewLocalService(r.Voyages)
+	}
+	rs = routing.NewConnectionMiddleware(cfg.connections)(rs)
+	rs = routing.NewPortStatusMiddleware(portStatuses)(rs)
+
+	logger := cfg.logger
+
+	var (
+		cfs                       = changefeed.NewService(r.CargoChanges)
+		aus                       = audit.NewService(r.AuditEntries)
+		taskService               = task.NewService(r.Tasks, users)
+		notifier                  = notification.NewNotifier(r.Customers, notification.NewLoggingSender(log.With(logger, "component", "notification")))
+		consolidationEventHandler = consolidation.NewEventHandler(r.Cargos, r.HandlingEvents)
+		slaService                = sla.NewService(r.Cargos, r.HandlingEvents, slas,
+			sla.EventHandlers{
+				task.NewSLAEventHandler(taskService),
+				notification.NewSLAEventHandler(notifier),
+			},
+			sla.WithCalendars(calendars),
+		)
+		ps = portstatus.NewService(portStatuses, calendars, r.Locations,
+			portstatus.WithVariances(r.Cargos, variances, task.NewPortStatusEventHandler(taskService)),
+		)
+		inspectionEventHandler = inspection.EventHandlers{
+			task.NewInspectionEventHandler(taskService),
+			notification.NewInspectionEventHandler(notifier),
+			changefeed.NewInspectionEventHandler(cfs),
+		}
+		handlingEventHandler = handling.EventHandlers{
+			consolidationEventHandler,
+			handling.NewEventHandler(
+				inspection.NewService(r.Cargos, r.HandlingEvents, inspectionEventHandler),
+			),
+			sla.NewHandlingEventHandler(slaService),
+			portstatus.NewHandlingEventHandler(ps),
+			notification.NewHandlingEventHandler(notifier, r.Cargos),
+			changefeed.NewHandlingEventHandler(cfs),
+		}
+	)
+
+	var bs booking.Service
+	bs = booking.NewService(r.Cargos, r.Locations, r.HandlingEvents, rs,
+		booking.WithServiceStrings(r.ServiceStrings),
+		booking.WithVoyages(r.Voyages),
+		booking.WithCalendars(calendars),
+		booking.WithConnectionTimes(cfg.connections),
+		booking.WithEventHandler(booking.EventHandlers{
+			consolidationEventHandler,
+			carrier.NewBookingEventHandler(r.Cargos, carrier.NewMockAdapter()),
+			changefeed.NewBookingEventHandler(cfs),
+			notification.NewBookingEventHandler(notifier),
+		}),
+		booking.WithDrafts(drafts),
+		booking.WithReleaseCodes(releaseCodes),
+		booking.WithTimeline(r.AuditEntries, r.CargoChanges, r.Tasks),
+	)
+
+	bus := command.NewBus(
+		command.Validating(),
+		command.Idempotent(time.Hour),
+		audit.NewCommandMiddleware(aus),
+	)
+	booking.RegisterCommands(bus, bs)
+	bs = booking.NewCommandService(bus, bs)
+
+	var hs handling.Service
+	hs = handling.NewService(r.HandlingEvents, shipping.HandlingEventFactory{
+		CargoRepository:    r.Cargos,
+		VoyageRepository:   r.Voyages,
+		LocationRepository: r.Locations,
+	}, handlingEventHandler, terminals,
+		handling.WithReleaseCodes(releaseCodes),
+		handling.WithReportNonces(reportNonces),
+		handling.WithAdminToken(adminToken),
+	)
+	hs = audit.NewHandlingService(aus, hs)
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	rcs := reconciliation.NewService(r.Voyages, r.Cargos, r.HandlingEvents, task.NewReconciliationEventHandler(taskService))
+
+	services := server.Services{
+		Booking:       bs,
+		Tracking:      tracking.NewService(r.Cargos, r.HandlingEvents, tracking.WithPortStatuses(portStatuses), tracking.WithCalendars(calendars)),
+		Handling:      hs,
+		Scheduling:    scheduling.NewService(r.Voyages, r.Locations, r.ServiceStrings),
+		Consolidation: consolidation.NewService(r.Cargos),
+		PortStatus:    ps,
+		SLA:           slaService,
+		Portal:        portal.NewService(r.Customers, r.Cargos, r.HandlingEvents, secret),
+		Amendment:     audit.NewAmendmentService(aus, amendment.NewService(amendments, r.Cargos, r.Locations, users)),
+		Task:          taskService,
+		Inbound:       inbound.NewService(hs, nil, "", task.NewMailReporter(taskService)),
+		Audit:         aus,
+		ChangeFeed:    cfs,
+		Carrier:       carrier.NewService(r.Cargos, ""),
+		Position: position.NewService(positions, r.Voyages, r.Cargos, r.Locations, nil, inspectionEventHandler,
+			position.WithEventHandler(reconciliation.NewPositionEventHandler(rcs)),
+		),
+		Telemetry:      telemetry.NewService(telemetryLog, r.Cargos, notification.NewTelemetryEventHandler(notifier)),
+		Privacy:        audit.NewPrivacyService(aus, privacy.NewService(r.Customers, r.Cargos, r.HandlingEvents, r.AuditEntries, users)),
+		Dashboard:      dashboard.NewService(bs, taskService),
+		Reconciliation: rcs,
+		Replication:    replication.NewService(replication.NewFence(replication.Primary), inmem.NewReplicationLogRepository(), r.Cargos, r.HandlingEvents, r.Voyages, checkpoints, ""),
+	}
+
+	srv := server.New(services, log.With(logger, "component", "http"), server.WithMaxBodySize(cfg.maxBodySize))
+
+	a := &App{
+		Repositories: r,
+		srv:          httptest.NewServer(srv),
+		server:       srv,
+		terminals:    make(map[shipping.UNLocode]bool),
+	}
+	a.URL = a.srv.URL
+
+	c, err := client.New(a.URL)
+	if err != nil {
+		a.srv.Close()
+		return nil, err
+	}
+	a.Client = c
+
+	return a, nil
+}
+
+// Start boots the application for a test, which fails if the application
+// cannot be booted. The application is shut down when the test completes.
+func Start(tb testing.TB, opts ...Option) *App {
+	tb.Helper()
+
+	a, err := New(opts...)
+	if err != nil {
+		tb.Fatalf("apptest: %v", err)
+	}
+	tb.Cleanup(a.Close)
+
+	return a
+}
+
+// Close shuts the application down.
+func (a *App) Close() {
+	a.srv.Close()
+}
+
+// BookCargo books a 20ft container from origin to destination.
+func (a *App) BookCargo(ctx context.Context, origin, destination shipping.UNLocode, deadline time.Time) (shipping.TrackingID, error) {
+	return a.Client.Booking.BookNewCargo(ctx, "", origin, destination, deadline, shipping.Container20)
+}
+
+// RouteCargo assigns the cargo to the first of its possible routes, which
+// is returned.
+func (a *App) RouteCargo(ctx context.Context, id shipping.TrackingID) (shipping.Itinerary, error) {
+	candidates, err := a.Client.Booking.RequestPossibleRoutesForCargo(ctx, id)
+	if err != nil {
+		return shipping.Itinerary{}, err
+	}
+	if len(candidates.Itineraries) == 0 {
+		return shipping.Itinerary{}, ErrNoRoute
+	}
+
+	itinerary := candidates.Itineraries[0]
+	if err := a.Client.Booking.AssignCargoToRoute(ctx, id, itinerary); err != nil {
+		return shipping.Itinerary{}, err
+	}
+
+	return itinerary, nil
+}
+
+// Handle registers the handling of a cargo at a location, reported by the
+// terminal of the location. Voyage is empty for handling not related to a
+// voyage, such as receiving the cargo. Claims present a release code issued
+// to the consignee for the purpose.
+func (a *App) Handle(ctx context.Context, id shipping.TrackingID, voyage shipping.VoyageNumber, location shipping.UNLocode, typ shipping.HandlingEventType, completed time.Time) error {
+	source, err := a.terminal(ctx, location)
+	if err != nil {
+		return err
+	}
+	if typ == shipping.Claim {
+		if source.ReleaseCode, err = a.Client.Booking.IssueReleaseCode(ctx, id); err != nil {
+			return err
+		}
+	}
+	return a.Client.Handling.RegisterHandlingEvent(ctx, source, completed, id, voyage, location, typ)
+}
+
+// HandleItinerary registers the cargo as received at the origin of the
+// itinerary, loaded and unloaded as planned for each of its legs, and
+// claimed at its final destination.
+func (a *App) HandleItinerary(ctx context.Context, id shipping.TrackingID, itinerary shipping.Itinerary) error {
+	if itinerary.IsEmpty() {
+		return ErrNoRoute
+	}
+
+	if err := a.Handle(ctx, id, "", itinerary.InitialDepartureLocation(), shipping.Receive, itinerary.InitialDepartureTime().Add(-time.Hour)); err != nil {
+		return err
+	}
+	for _, l := range itinerary.Legs {
+		if err := a.Handle(ctx, id, l.VoyageNumber, l.LoadLocation, shipping.Load, l.LoadTime); err != nil {
+			return err
+		}
+		if err := a.Handle(ctx, id, l.VoyageNumber, l.UnloadLocation, shipping.Unload, l.UnloadTime); err != nil {
+			return err
+		}
+	}
+	return a.Handle(ctx, id, "", itinerary.FinalArrivalLocation(), shipping.Claim, itinerary.FinalArrivalTime().Add(time.Hour))
+}
+
+// Track returns the cargo as tracked by customers.
+func (a *App) Track(ctx context.Context, id shipping.TrackingID) (tracking.Cargo, error) {
+	return a.Client.Tracking.Track(ctx, string(id))
+}
+
+// terminal returns the credentials of the terminal at a location,
+// registering the terminal unless already registered.
+func (a *App) terminal(ctx context.Context, location shipping.UNLocode) (handling.Credentials, error) {
+	source := handling.Credentials{Terminal: shipping.TerminalID(location), Key: terminalKey, Secret: terminalSecret}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.terminals[location] {
+		return source, nil
+	}
+
+	if err := a.Client.Handling.RegisterTerminal(ctx, adminToken, source.Terminal, string(location), location, terminalKey, terminalSecret); err != nil {
+		return handling.Credentials{}, err
+	}
+	a.terminals[location] = true
+
+	return source, nil
+}
@@ -0,0 +1,69 @@
+package apptest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+func TestApp(t *testing.T) {
+	a := Start(t)
+
+	ctx := context.Background()
+
+	id, err := a.BookCargo(ctx, shipping.CNHKG, shipping.NLRTM, time.Now().AddDate(0, 2, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	itinerary, err := a.RouteCargo(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if itinerary.FinalArrivalLocation() != shipping.NLRTM {
+		t.Errorf("route arrives at %s; want %s", itinerary.FinalArrivalLocation(), shipping.NLRTM)
+	}
+
+	if err := a.HandleItinerary(ctx, id, itinerary); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := a.Track(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 2 + 2*len(itinerary.Legs); len(c.Events) != want {
+		t.Errorf("len(events) = %d; want = %d", len(c.Events), want)
+	}
+
+	stored, err := a.Repositories.Cargos.Find(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Delivery.TransportStatus != shipping.Claimed {
+		t.Errorf("transport status = %v; want = %v", stored.Delivery.TransportStatus, shipping.Claimed)
+	}
+	if stored.Delivery.IsMisdirected {
+		t.Errorf("cargo handled as planned should not be misdirected")
+	}
+}
+
+func TestApp_NoRoute(t *testing.T) {
+	a := Start(t)
+
+	ctx := context.Background()
+
+	id, err := a.BookCargo(ctx, shipping.SESTO, shipping.CNHKG, time.Now().AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.RouteCargo(ctx, id); err != ErrNoRoute {
+		t.Errorf("err = %v; want = %v", err, ErrNoRoute)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	Conformance(t)
+}
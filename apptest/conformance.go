@@ -0,0 +1,248 @@
+package apptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// conformanceBodySize is the largest request body accepted by the
+// application checked for conformance, kept small for oversized bodies to be
+// cheap to send.
+const conformanceBodySize = 64 << 10
+
+// anyClientStatus is expected of requests that may either succeed or be
+// refused, but must not fail with a server error.
+const anyClientStatus = 0
+
+// unknownID is substituted for every parameter in the path of an endpoint.
+const unknownID = "UNKNOWN0"
+
+// Endpoints not reading a request body, for which malformed bodies cannot be
+// detected.
+var bodyless = map[string]bool{
+	"/amendment/v1/amendments/{amendmentID}/approve":       true,
+	"/booking/v1/cargos/recalculate_deliveries":            true,
+	"/booking/v1/cargos/{trackingID}/recalculate_delivery": true,
+	"/booking/v1/cargos/{trackingID}/release_code":         true,
+	"/booking/v1/drafts/{draftID}/book":                    true,
+	"/consolidation/v1/masters/{masterID}/split":           true,
+	"/privacy/v1/customers/{customerID}/anonymize":         true,
+	"/reconciliation/v1/voyages/{voyageNumber}/complete":   true,
+	"/replication/v1/demote":                               true,
+	"/sla/v1/cargos/{trackingID}/evaluate":                 true,
+}
+
+// Endpoints taking bodies other than JSON documents, which are not checked
+// for malformed bodies.
+var unstructured = map[string]bool{
+	"/portstatus/v1/advisories/import": true, // CSV
+	"/portal/v1/unsubscribe":           true, // form
+}
+
+// Endpoints authenticating requests before reading their bodies or looking
+// up the resources they name, and the status of requests without
+// credentials.
+var authenticated = map[string]int{
+	"/amendment/v1/amendments/{amendmentID}/approve":                    http.StatusUnauthorized,
+	"/carrier/v1/cargos/{trackingID}/legs/{voyageNumber}/{from}/reject": http.StatusForbidden,
+	"/carrier/v1/voyages/{voyageNumber}/legs":                           http.StatusForbidden,
+	"/inbound/v1/mail":                             http.StatusForbidden,
+	"/portal/v1/cargos":                            http.StatusUnauthorized,
+	"/portal/v1/contact":                           http.StatusUnauthorized,
+	"/portal/v1/events":                            http.StatusUnauthorized,
+	"/portal/v1/frequency":                         http.StatusUnauthorized,
+	"/portal/v1/preferences":                       http.StatusUnauthorized,
+	"/privacy/v1/customers/{customerID}/anonymize": http.StatusUnauthorized,
+	"/privacy/v1/customers/{customerID}/export":    http.StatusUnauthorized,
+}
+
+// Endpoints listing what is associated with a resource, which list nothing
+// for unknown resources rather than failing.
+var listings = map[string]bool{
+	"/amendment/v1/cargos/{trackingID}/amendments":   true,
+	"/booking/v1/cargos/{trackingID}/request_routes": true,
+}
+
+var pathParam = regexp.MustCompile(`\{[^}]+\}`)
+
+// Conformance checks that every endpoint of the API refuses requests it
+// cannot serve alike: with the expected status and an error envelope, i.e. a
+// JSON object holding a message in its "error" field, and never with a
+// server error. Every endpoint is sent
+//
+//   - a method it is not routed for, refused with 405,
+//   - unknown IDs for the parameters in its path, refused with 404,
+//   - a malformed JSON body, refused with 400,
+//   - an empty JSON document, which may be refused but must not fail,
+//   - a body larger than accepted, refused with 413, and
+//   - no credentials, if it requires authentication, refused with 401 or 403.
+//
+// Endpoints serving documentation and metrics are left out. New endpoints
+// diverging from these rules, e.g. by authenticating requests before
+// reading their bodies, are to be listed here with their expected status.
+func Conformance(t *testing.T, opts ...Option) {
+	a := Start(t, append([]Option{WithMaxBodySize(conformanceBodySize)}, opts...)...)
+
+	methods := make(map[string][]string)
+	var patterns []string
+	for _, r := range a.server.Routes() {
+		if strings.HasSuffix(r.Pattern, "/docs") || r.Pattern == "/metrics" {
+			continue
+		}
+		if _, ok := methods[r.Pattern]; !ok {
+			patterns = append(patterns, r.Pattern)
+		}
+		methods[r.Pattern] = append(methods[r.Pattern], r.Method)
+	}
+
+	for _, pattern := range patterns {
+		pattern := pattern
+		path := pathParam.ReplaceAllString(pattern, unknownID)
+
+		t.Run(pattern, func(t *testing.T) {
+			c := &conformanceCheck{t: t, url: a.URL + path}
+
+			c.expect(unroutedMethod(methods[pattern]), nil, http.StatusMethodNotAllowed)
+
+			for _, method := range methods[pattern] {
+				takesBody := method == "POST" || method == "PUT"
+
+				authStatus, authFirst := authenticated[pattern]
+				if authFirst {
+					body := []byte(nil)
+					if takesBody {
+						body = []byte("{}")
+					}
+					c.expect(method, body, authStatus)
+				}
+
+				if !takesBody {
+					if !authFirst && pathParam.MatchString(pattern) {
+						want := http.StatusNotFound
+						if listings[pattern] {
+							want = http.StatusOK
+						}
+						c.expect(method, nil, want)
+					}
+					continue
+				}
+
+				c.expect(method, bytes.Repeat([]byte(" "), conformanceBodySize+1), http.StatusRequestEntityTooLarge)
+
+				if bodyless[pattern] || unstructured[pattern] {
+					continue
+				}
+
+				malformed, oversized := http.StatusBadRequest, http.StatusRequestEntityTooLarge
+				if authFirst {
+					malformed, oversized = authStatus, authStatus
+				}
+				c.expect(method, []byte(`{"broken`), malformed)
+				c.expect(method, []byte("{}"), anyClientStatus)
+				c.expectStreamed(method, oversizedDocument(), oversized)
+			}
+		})
+	}
+}
+
+type conformanceCheck struct {
+	t   *testing.T
+	url string
+}
+
+// expect sends a request, checking the status of the response.
+func (c *conformanceCheck) expect(method string, body []byte, want int) {
+	c.t.Helper()
+
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	c.do(method, r, body, want)
+}
+
+// expectStreamed sends a request without declaring the length of its body,
+// so that oversized bodies are only detected while being read.
+func (c *conformanceCheck) expectStreamed(method string, body []byte, want int) {
+	c.t.Helper()
+
+	c.do(method, ioutil.NopCloser(bytes.NewReader(body)), body, want)
+}
+
+func (c *conformanceCheck) do(method string, r io.Reader, body []byte, want int) {
+	c.t.Helper()
+
+	req, err := http.NewRequest(method, c.url, r)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s with %s: %v", method, describe(body), err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case want == anyClientStatus && resp.StatusCode >= 500:
+		c.t.Errorf("%s with %s: status = %d; want no server error", method, describe(body), resp.StatusCode)
+	case want != anyClientStatus && resp.StatusCode != want:
+		c.t.Errorf("%s with %s: status = %d; want = %d", method, describe(body), resp.StatusCode, want)
+	}
+	if resp.StatusCode < 400 {
+		return
+	}
+
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt != "application/json" {
+		c.t.Errorf("%s with %s: Content-Type = %q; want application/json", method, describe(body), resp.Header.Get("Content-Type"))
+	}
+	var envelope struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || envelope.Error == "" {
+		c.t.Errorf("%s with %s: body is not an error envelope (%v)", method, describe(body), err)
+	}
+}
+
+// oversizedDocument returns a well-formed JSON document larger than the
+// application accepts.
+func oversizedDocument() []byte {
+	return []byte(fmt.Sprintf(`{"padding":%q}`, strings.Repeat("x", conformanceBodySize)))
+}
+
+func describe(body []byte) string {
+	switch {
+	case body == nil:
+		return "no body"
+	case len(body) > conformanceBodySize:
+		return "oversized body"
+	}
+	return fmt.Sprintf("body %q", body)
+}
+
+// unroutedMethod returns a method the endpoint is not routed for.
+func unroutedMethod(routed []string) string {
+	for _, m := range []string{"PATCH", "DELETE", "PUT", "POST", "GET"} {
+		found := false
+		for _, r := range routed {
+			if r == m {
+				found = true
+			}
+		}
+		if !found {
+			return m
+		}
+	}
+	return "PATCH"
+}
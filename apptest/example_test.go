@@ -0,0 +1,42 @@
+package apptest_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/apptest"
+)
+
+func Example() {
+	a, err := apptest.New()
+	if err != nil {
+		panic(err)
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+
+	id, err := a.BookCargo(ctx, shipping.CNHKG, shipping.NLRTM, time.Now().AddDate(0, 2, 0))
+	if err != nil {
+		panic(err)
+	}
+
+	itinerary, err := a.RouteCargo(ctx, id)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := a.HandleItinerary(ctx, id, itinerary); err != nil {
+		panic(err)
+	}
+
+	c, err := a.Track(ctx, id)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(c.Destination, len(c.Events))
+	// Output: NLRTM 6
+}
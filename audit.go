@@ -0,0 +1,186 @@
+package shipping
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"time"
+)
+
+// AuditAction describes a change recorded in the audit log.
+type AuditAction string
+
+// Audited actions.
+const (
+	AuditCargoBooked             AuditAction = "cargo.booked"
+	AuditCargoRouted             AuditAction = "cargo.routed"
+	AuditCargoRejected           AuditAction = "cargo.rejected"
+	AuditCargoRolled             AuditAction = "cargo.rolled"
+	AuditCargoTransferred        AuditAction = "cargo.transferred"
+	AuditDestinationChanged      AuditAction = "cargo.destination_changed"
+	AuditDeadlineTypeChanged     AuditAction = "cargo.deadline_type_changed"
+	AuditPortCallOmitted         AuditAction = "voyage.port_call_omitted"
+	AuditHandlingEventRegistered AuditAction = "handling.registered"
+	AuditTerminalRegistered      AuditAction = "terminal.registered"
+	AuditAmendmentRequested      AuditAction = "amendment.requested"
+	AuditAmendmentApproved       AuditAction = "amendment.approved"
+	AuditAmendmentRejected       AuditAction = "amendment.rejected"
+	AuditUserRegistered          AuditAction = "user.registered"
+	AuditPartyScreened           AuditAction = "screening.completed"
+	AuditCustomerExported        AuditAction = "customer.exported"
+	AuditCustomerAnonymized      AuditAction = "customer.anonymized"
+)
+
+// AuditEntry records who changed what, and when. Entries are chained by
+// hash: each entry includes the hash of the one before it, so that changing
+// or removing a historical entry breaks every hash that follows.
+type AuditEntry struct {
+	Sequence uint64
+	Time     time.Time
+
+	// Actor identifies the user or terminal the change was made by. It is
+	// empty for changes made by parties that do not authenticate.
+	Actor string
+
+	Action     AuditAction
+	TrackingID TrackingID
+	Detail     string
+
+	// RequestID is the correlation ID of the request that made the change.
+	RequestID string
+
+	PrevHash string
+	Hash     string
+}
+
+var (
+	// ErrAuditConflict is used when an entry is appended to the audit log
+	// after another entry with the same sequence number.
+	ErrAuditConflict = errors.New("audit entry already exists")
+
+	// ErrAuditTampered is used when the audit log does not match its hash
+	// chain.
+	ErrAuditTampered = errors.New("audit log has been tampered with")
+)
+
+// Seal links the entry to the entry before it, which is nil for the first
+// entry in the log, and computes its hash.
+func (e *AuditEntry) Seal(prev *AuditEntry) {
+	e.Sequence = 1
+	e.PrevHash = ""
+	if prev != nil {
+		e.Sequence = prev.Sequence + 1
+		e.PrevHash = prev.Hash
+	}
+
+	// Stores may not keep more than millisecond precision, which would
+	// change the hash when the entry is read back.
+	e.Time = e.Time.UTC().Truncate(time.Millisecond)
+
+	e.Hash = e.ComputeHash()
+}
+
+// ComputeHash returns the hash of the entry contents and the hash of the
+// entry before it.
+func (e AuditEntry) ComputeHash() string {
+	h := sha256.New()
+	for _, f := range []string{
+		strconv.FormatUint(e.Sequence, 10),
+		e.Time.UTC().Format(time.RFC3339Nano),
+		e.Actor,
+		string(e.Action),
+		string(e.TrackingID),
+		e.Detail,
+		e.RequestID,
+		e.PrevHash,
+	} {
+		writeField(h, f)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeField writes a length-prefixed field, so that moving characters
+// between adjacent fields changes the hash.
+func writeField(h hash.Hash, f string) {
+	fmt.Fprintf(h, "%d:%s", len(f), f)
+}
+
+// AuditChainError is returned when verifying an audit log that does not
+// match its hash chain.
+type AuditChainError struct {
+	// Sequence is the sequence number of the first entry that does not
+	// match the chain.
+	Sequence uint64
+}
+
+func (e *AuditChainError) Error() string {
+	return fmt.Sprintf("%s at entry %d", ErrAuditTampered, e.Sequence)
+}
+
+// VerifyAuditChain verifies that a complete audit log, ordered by sequence
+// number, matches its hash chain.
+func VerifyAuditChain(entries []*AuditEntry) error {
+	var prev *AuditEntry
+	for _, e := range entries {
+		want := uint64(1)
+		prevHash := ""
+		if prev != nil {
+			want = prev.Sequence + 1
+			prevHash = prev.Hash
+		}
+		if e.Sequence != want {
+			return &AuditChainError{Sequence: want}
+		}
+		if e.PrevHash != prevHash || e.Hash != e.ComputeHash() {
+			return &AuditChainError{Sequence: e.Sequence}
+		}
+		prev = e
+	}
+	return nil
+}
+
+// AuditQuery selects entries from the audit log. Empty fields match all
+// entries.
+type AuditQuery struct {
+	TrackingID TrackingID
+	Actor      string
+	Action     AuditAction
+
+	// From and To limit the entries to those recorded within [From, To).
+	From time.Time
+	To   time.Time
+}
+
+// Matches returns whether the entry is selected by the query.
+func (q AuditQuery) Matches(e *AuditEntry) bool {
+	switch {
+	case q.TrackingID != "" && e.TrackingID != q.TrackingID:
+		return false
+	case q.Actor != "" && e.Actor != q.Actor:
+		return false
+	case q.Action != "" && e.Action != q.Action:
+		return false
+	case !q.From.IsZero() && e.Time.Before(q.From):
+		return false
+	case !q.To.IsZero() && !e.Time.Before(q.To):
+		return false
+	}
+	return true
+}
+
+// AuditRepository provides access to an append-only audit log.
+type AuditRepository interface {
+	// Append adds a sealed entry to the log. It returns ErrAuditConflict if
+	// an entry with the same sequence number has already been appended.
+	Append(e *AuditEntry) error
+
+	// Last returns the most recent entry, or nil if the log is empty.
+	Last() (*AuditEntry, error)
+
+	// Find returns the entries selected by the query, ordered by sequence
+	// number.
+	Find(q AuditQuery) []*AuditEntry
+}
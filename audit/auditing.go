@@ -0,0 +1,186 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/amendment"
+	"github.com/marcusolsson/goddd/command"
+	"github.com/marcusolsson/goddd/handling"
+	"github.com/marcusolsson/goddd/privacy"
+)
+
+// Changes are only recorded once they have succeeded. Failing to record a
+// change does not fail the change itself, since it has already been made;
+// such failures are left to be reported by the logging Service.
+
+// NewCommandMiddleware returns a command bus middleware that records the
+// commands handled through it in the audit log.
+func NewCommandMiddleware(a Service) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			response, err := next(ctx, request)
+			if err != nil {
+				return response, err
+			}
+			if cmd, ok := request.(command.Audited); ok {
+				for _, e := range cmd.AuditEntries(response) {
+					a.Record(ctx, e.Actor, e.Action, e.TrackingID, e.Detail)
+				}
+			}
+			return response, nil
+		}
+	}
+}
+
+type handlingService struct {
+	handling.Service
+	audit Service
+}
+
+// NewHandlingService returns a handling Service that records the changes made
+// through it in the audit log.
+func NewHandlingService(a Service, next handling.Service) handling.Service {
+	return &handlingService{Service: next, audit: a}
+}
+
+func (s *handlingService) RegisterHandlingEvent(ctx context.Context, source handling.Credentials, completed time.Time, id shipping.TrackingID, voyageNumber shipping.VoyageNumber, unLocode shipping.UNLocode, eventType shipping.HandlingEventType) error {
+	err := s.Service.RegisterHandlingEvent(ctx, source, completed, id, voyageNumber, unLocode, eventType)
+	if err == nil {
+		detail := fmt.Sprintf("%s at %s, completed %s", eventType, unLocode, completed.Format(time.RFC3339))
+		if voyageNumber != "" {
+			detail = fmt.Sprintf("%s on %s at %s, completed %s", eventType, voyageNumber, unLocode, completed.Format(time.RFC3339))
+		}
+		s.audit.Record(ctx, string(source.Terminal), shipping.AuditHandlingEventRegistered, id, detail)
+	}
+	return err
+}
+
+func (s *handlingService) RegisterTerminal(ctx context.Context, token string, id shipping.TerminalID, name string, loc shipping.UNLocode, key, secret string) error {
+	err := s.Service.RegisterTerminal(ctx, token, id, name, loc, key, secret)
+	if err == nil {
+		s.audit.Record(ctx, "", shipping.AuditTerminalRegistered, "", fmt.Sprintf("%s at %s", id, loc))
+	}
+	return err
+}
+
+type amendmentService struct {
+	amendment.Service
+	audit Service
+}
+
+// NewAmendmentService returns an amendment Service that records the changes
+// made through it in the audit log.
+func NewAmendmentService(a Service, next amendment.Service) amendment.Service {
+	return &amendmentService{Service: next, audit: a}
+}
+
+func (s *amendmentService) RequestDestinationChange(ctx context.Context, by amendment.Credentials, id shipping.TrackingID, destination shipping.UNLocode) (shipping.AmendmentID, error) {
+	aid, err := s.Service.RequestDestinationChange(ctx, by, id, destination)
+	if err == nil {
+		s.audit.Record(ctx, string(by.User), shipping.AuditAmendmentRequested, id,
+			fmt.Sprintf("%s: destination change to %s", aid, destination))
+	}
+	return aid, err
+}
+
+func (s *amendmentService) RequestDeadlineExtension(ctx context.Context, by amendment.Credentials, id shipping.TrackingID, deadline time.Time) (shipping.AmendmentID, error) {
+	aid, err := s.Service.RequestDeadlineExtension(ctx, by, id, deadline)
+	if err == nil {
+		s.audit.Record(ctx, string(by.User), shipping.AuditAmendmentRequested, id,
+			fmt.Sprintf("%s: deadline extension to %s", aid, deadline.Format(time.RFC3339)))
+	}
+	return aid, err
+}
+
+func (s *amendmentService) Approve(ctx context.Context, by amendment.Credentials, id shipping.AmendmentID) error {
+	cargo := s.cargoOf(ctx, id)
+	err := s.Service.Approve(ctx, by, id)
+	if err == nil {
+		s.audit.Record(ctx, string(by.User), shipping.AuditAmendmentApproved, cargo, string(id))
+	}
+	return err
+}
+
+func (s *amendmentService) Reject(ctx context.Context, by amendment.Credentials, id shipping.AmendmentID, reason string) error {
+	cargo := s.cargoOf(ctx, id)
+	err := s.Service.Reject(ctx, by, id, reason)
+	if err == nil {
+		s.audit.Record(ctx, string(by.User), shipping.AuditAmendmentRejected, cargo, fmt.Sprintf("%s: %s", id, reason))
+	}
+	return err
+}
+
+// cargoOf returns the cargo a pending amendment is about, so that decisions
+// can be found when querying the audit log by cargo.
+func (s *amendmentService) cargoOf(ctx context.Context, id shipping.AmendmentID) shipping.TrackingID {
+	for _, a := range s.Service.Pending(ctx) {
+		if a.ID == string(id) {
+			return shipping.TrackingID(a.TrackingID)
+		}
+	}
+	return ""
+}
+
+func (s *amendmentService) RegisterUser(ctx context.Context, id shipping.UserID, name, key string, roles []shipping.Role) error {
+	err := s.Service.RegisterUser(ctx, id, name, key, roles)
+	if err == nil {
+		s.audit.Record(ctx, "", shipping.AuditUserRegistered, "", fmt.Sprintf("%s with roles %v", id, roles))
+	}
+	return err
+}
+
+type privacyService struct {
+	privacy.Service
+	audit Service
+}
+
+// NewPrivacyService returns a privacy Service that records every export and
+// anonymization of the data of a customer in the audit log. Exports are
+// recorded too, since they disclose personal data.
+func NewPrivacyService(a Service, next privacy.Service) privacy.Service {
+	return &privacyService{Service: next, audit: a}
+}
+
+func (s *privacyService) Export(ctx context.Context, by privacy.Credentials, id shipping.CustomerID) (privacy.Archive, error) {
+	archive, err := s.Service.Export(ctx, by, id)
+	if err == nil {
+		s.audit.Record(ctx, string(by.User), shipping.AuditCustomerExported, "", fmt.Sprintf("customer %s, %d cargos", id, len(archive.Cargos)))
+	}
+	return archive, err
+}
+
+func (s *privacyService) Anonymize(ctx context.Context, by privacy.Credentials, id shipping.CustomerID) error {
+	err := s.Service.Anonymize(ctx, by, id)
+	if err == nil {
+		s.audit.Record(ctx, string(by.User), shipping.AuditCustomerAnonymized, "", fmt.Sprintf("customer %s", id))
+	}
+	return err
+}
+
+type screener struct {
+	next  shipping.Screener
+	audit Service
+}
+
+// NewScreener returns a Screener that records the result of every screening
+// in the audit log, including the ones refusing a booking.
+func NewScreener(a Service, next shipping.Screener) shipping.Screener {
+	return &screener{next: next, audit: a}
+}
+
+func (s *screener) Screen(ctx context.Context, r shipping.ScreeningRequest) (shipping.ScreeningResult, error) {
+	result, err := s.next.Screen(ctx, r)
+	if err == nil {
+		detail := fmt.Sprintf("customer %s", r.Customer)
+		if len(r.Locations) > 0 {
+			detail += fmt.Sprintf(", %v", r.Locations)
+		}
+		s.audit.Record(ctx, "", shipping.AuditPartyScreened, r.TrackingID, detail+": "+result.String())
+	}
+	return result, err
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/command"
+	"github.com/marcusolsson/goddd/handling"
+)
+
+// stubHandlingService registers handling events through fn.
+type stubHandlingService struct {
+	handling.Service
+	fn func(shipping.TrackingID) error
+}
+
+func (s *stubHandlingService) RegisterHandlingEvent(ctx context.Context, source handling.Credentials, completed time.Time, id shipping.TrackingID, voyage shipping.VoyageNumber, loc shipping.UNLocode, typ shipping.HandlingEventType) error {
+	return s.fn(id)
+}
+
+func TestHandlingServiceRecordsChanges(t *testing.T) {
+	a := NewService(newAuditRepository())
+
+	hs := NewHandlingService(a, &stubHandlingService{
+		fn: func(id shipping.TrackingID) error {
+			if id == "UNKNOWN" {
+				return errors.New("unknown cargo")
+			}
+			return nil
+		},
+	})
+
+	ctx := context.Background()
+	source := handling.Credentials{Terminal: "SESTO-1", Key: "secret"}
+	completed := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := hs.RegisterHandlingEvent(ctx, source, completed, "ABC", "V100", "SESTO", shipping.Unload); err != nil {
+		t.Fatal(err)
+	}
+	if err := hs.RegisterHandlingEvent(ctx, source, completed, "UNKNOWN", "V100", "SESTO", shipping.Unload); err == nil {
+		t.Fatal("err = nil; want an error")
+	}
+
+	got, err := a.Entries(ctx, shipping.AuditQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("len(got) = %d; want = %d", len(got), 1)
+	}
+	if got[0].Actor != "SESTO-1" || got[0].Action != string(shipping.AuditHandlingEventRegistered) || got[0].TrackingID != "ABC" {
+		t.Errorf("got[0] = %+v; want the unload of ABC by SESTO-1", got[0])
+	}
+}
+
+// auditedCommand is a command recording an entry for the cargo it is about.
+type auditedCommand struct {
+	id shipping.TrackingID
+}
+
+func (auditedCommand) CommandName() string { return "audited" }
+
+func (c auditedCommand) AuditEntries(response interface{}) []shipping.AuditEntry {
+	return []shipping.AuditEntry{{Action: shipping.AuditCargoRouted, TrackingID: c.id, Detail: response.(string)}}
+}
+
+func TestCommandMiddlewareRecordsCommands(t *testing.T) {
+	a := NewService(newAuditRepository())
+
+	bus := command.NewBus(NewCommandMiddleware(a))
+	bus.Handle("audited", func(ctx context.Context, request interface{}) (interface{}, error) {
+		if request.(auditedCommand).id == "UNKNOWN" {
+			return nil, errors.New("unknown cargo")
+		}
+		return "2 legs", nil
+	})
+
+	ctx := context.Background()
+
+	if _, err := bus.Dispatch(ctx, auditedCommand{id: "ABC"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bus.Dispatch(ctx, auditedCommand{id: "UNKNOWN"}); err == nil {
+		t.Fatal("err = nil; want an error")
+	}
+
+	got, err := a.Entries(ctx, shipping.AuditQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("len(got) = %d; want = %d", len(got), 1)
+	}
+	if got[0].Action != string(shipping.AuditCargoRouted) || got[0].TrackingID != "ABC" || got[0].Detail != "2 legs" {
+		t.Errorf("got[0] = %+v; want the routing of ABC", got[0])
+	}
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) Record(ctx context.Context, actor string, action shipping.AuditAction, id shipping.TrackingID, detail string) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "record").Add(1)
+		s.requestLatency.With("method", "record").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Record(ctx, actor, action, id, detail)
+}
+
+func (s *instrumentingService) Entries(ctx context.Context, q shipping.AuditQuery) ([]Entry, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "entries").Add(1)
+		s.requestLatency.With("method", "entries").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Entries(ctx, q)
+}
+
+func (s *instrumentingService) Verify(ctx context.Context) (Verification, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "verify").Add(1)
+		s.requestLatency.With("method", "verify").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Verify(ctx)
+}
@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) Record(ctx context.Context, actor string, action shipping.AuditAction, id shipping.TrackingID, detail string) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "record",
+			"request_id", correlation.FromContext(ctx),
+			"actor", actor,
+			"action", action,
+			"tracking_id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Record(ctx, actor, action, id, detail)
+}
+
+func (s *loggingService) Entries(ctx context.Context, q shipping.AuditQuery) (entries []Entry, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "entries",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", q.TrackingID,
+			"actor", q.Actor,
+			"action", q.Action,
+			"from", q.From,
+			"to", q.To,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Entries(ctx, q)
+}
+
+func (s *loggingService) Verify(ctx context.Context) (v Verification, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "verify",
+			"request_id", correlation.FromContext(ctx),
+			"entries", v.Entries,
+			"intact", v.Intact,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Verify(ctx)
+}
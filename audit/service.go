@@ -0,0 +1,152 @@
+// Package audit provides the use-case of recording who changed what, and
+// querying the record. Entries are chained by hash so that tampering with
+// them can be detected. Used by views facing the back office and auditors.
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// Service is the interface that provides audit methods.
+type Service interface {
+	// Record appends an entry to the audit log.
+	Record(ctx context.Context, actor string, action shipping.AuditAction, id shipping.TrackingID, detail string) error
+
+	// Entries returns the entries selected by the query, oldest first.
+	Entries(ctx context.Context, q shipping.AuditQuery) ([]Entry, error)
+
+	// Verify checks the complete audit log against its hash chain.
+	Verify(ctx context.Context) (Verification, error)
+}
+
+// maxAppendAttempts bounds the retries when another instance appends to the
+// log at the same time.
+const maxAppendAttempts = 3
+
+type service struct {
+	mtx     sync.Mutex
+	entries shipping.AuditRepository
+}
+
+func (s *service) Record(ctx context.Context, actor string, action shipping.AuditAction, id shipping.TrackingID, detail string) error {
+	if action == "" {
+		return ErrInvalidArgument
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var err error
+	for i := 0; i < maxAppendAttempts; i++ {
+		var last *shipping.AuditEntry
+		if last, err = s.entries.Last(); err != nil {
+			return err
+		}
+
+		e := &shipping.AuditEntry{
+			Time:       time.Now(),
+			Actor:      actor,
+			Action:     action,
+			TrackingID: id,
+			Detail:     detail,
+			RequestID:  correlation.FromContext(ctx),
+		}
+		e.Seal(last)
+
+		if err = s.entries.Append(e); err != shipping.ErrAuditConflict {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (s *service) Entries(ctx context.Context, q shipping.AuditQuery) ([]Entry, error) {
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return nil, ErrInvalidArgument
+	}
+
+	result := make([]Entry, 0)
+	for _, e := range s.entries.Find(q) {
+		result = append(result, assemble(e))
+	}
+	return result, nil
+}
+
+func (s *service) Verify(ctx context.Context) (Verification, error) {
+	entries := s.entries.Find(shipping.AuditQuery{})
+
+	v := Verification{
+		Entries: len(entries),
+		Intact:  true,
+	}
+	if len(entries) > 0 {
+		v.Head = entries[len(entries)-1].Hash
+	}
+
+	err := shipping.VerifyAuditChain(entries)
+	if cerr, ok := err.(*shipping.AuditChainError); ok {
+		v.Intact = false
+		v.BrokenAt = cerr.Sequence
+		return v, nil
+	}
+
+	return v, err
+}
+
+// NewService creates an audit service with necessary dependencies.
+func NewService(entries shipping.AuditRepository) Service {
+	return &service{
+		entries: entries,
+	}
+}
+
+// Entry is a read model for audit views.
+type Entry struct {
+	Sequence   uint64    `json:"sequence"`
+	Time       time.Time `json:"time"`
+	Actor      string    `json:"actor,omitempty"`
+	Action     string    `json:"action"`
+	TrackingID string    `json:"tracking_id,omitempty"`
+	Detail     string    `json:"detail,omitempty"`
+	RequestID  string    `json:"request_id,omitempty"`
+	PrevHash   string    `json:"prev_hash,omitempty"`
+	Hash       string    `json:"hash"`
+}
+
+// Verification is a read model for the result of verifying the audit log.
+type Verification struct {
+	Entries int  `json:"entries"`
+	Intact  bool `json:"intact"`
+
+	// BrokenAt is the sequence number of the first entry that does not
+	// match the hash chain.
+	BrokenAt uint64 `json:"broken_at,omitempty"`
+
+	// Head is the hash of the last entry, which auditors may keep to later
+	// detect entries being removed from the end of the log.
+	Head string `json:"head,omitempty"`
+}
+
+func assemble(e *shipping.AuditEntry) Entry {
+	return Entry{
+		Sequence:   e.Sequence,
+		Time:       e.Time,
+		Actor:      e.Actor,
+		Action:     string(e.Action),
+		TrackingID: string(e.TrackingID),
+		Detail:     e.Detail,
+		RequestID:  e.RequestID,
+		PrevHash:   e.PrevHash,
+		Hash:       e.Hash,
+	}
+}
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+func newAuditRepository() *mock.AuditRepository {
+	var entries []*shipping.AuditEntry
+
+	var r mock.AuditRepository
+	r.AppendFn = func(e *shipping.AuditEntry) error {
+		for _, x := range entries {
+			if x.Sequence == e.Sequence {
+				return shipping.ErrAuditConflict
+			}
+		}
+		entries = append(entries, e)
+		return nil
+	}
+	r.LastFn = func() (*shipping.AuditEntry, error) {
+		if len(entries) == 0 {
+			return nil, nil
+		}
+		return entries[len(entries)-1], nil
+	}
+	r.FindFn = func(q shipping.AuditQuery) []*shipping.AuditEntry {
+		var result []*shipping.AuditEntry
+		for _, e := range entries {
+			if q.Matches(e) {
+				result = append(result, e)
+			}
+		}
+		return result
+	}
+	return &r
+}
+
+func TestRecord(t *testing.T) {
+	s := NewService(newAuditRepository())
+
+	ctx := correlation.NewContext(context.Background(), "req-1")
+
+	if err := s.Record(ctx, "", shipping.AuditCargoBooked, "ABC", "booked"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Record(ctx, "jdoe", shipping.AuditAmendmentApproved, "ABC", "approved"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Record(ctx, "SESTO-1", shipping.AuditHandlingEventRegistered, "DEF", "unload"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Record(ctx, "jdoe", "", "ABC", ""); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+
+	got, err := s.Entries(ctx, shipping.AuditQuery{TrackingID: "ABC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %d; want = %d", len(got), 2)
+	}
+	if got[1].Sequence != 2 || got[1].Actor != "jdoe" || got[1].RequestID != "req-1" {
+		t.Errorf("got[1] = %+v; want the approval by jdoe", got[1])
+	}
+	if got[1].PrevHash != got[0].Hash {
+		t.Errorf("PrevHash = %s; want = %s", got[1].PrevHash, got[0].Hash)
+	}
+
+	if got, _ := s.Entries(ctx, shipping.AuditQuery{Actor: "SESTO-1"}); len(got) != 1 {
+		t.Errorf("len(got) = %d; want = %d", len(got), 1)
+	}
+
+	now := time.Now()
+	if _, err := s.Entries(ctx, shipping.AuditQuery{From: now, To: now}); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	r := newAuditRepository()
+	s := NewService(r)
+
+	ctx := context.Background()
+
+	v, err := s.Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Intact || v.Entries != 0 {
+		t.Errorf("v = %+v; want an intact, empty log", v)
+	}
+
+	for _, id := range []shipping.TrackingID{"ABC", "DEF", "GHI"} {
+		if err := s.Record(ctx, "", shipping.AuditCargoBooked, id, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v, err = s.Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Intact || v.Entries != 3 {
+		t.Errorf("v = %+v; want an intact log of 3 entries", v)
+	}
+
+	r.Find(shipping.AuditQuery{TrackingID: "DEF"})[0].Detail = "rewritten"
+
+	v, err = s.Verify(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Intact || v.BrokenAt != 2 {
+		t.Errorf("v = %+v; want broken at entry 2", v)
+	}
+}
@@ -0,0 +1,91 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func newAuditChain(n int) []*AuditEntry {
+	var entries []*AuditEntry
+	var prev *AuditEntry
+	for i := 0; i < n; i++ {
+		e := &AuditEntry{
+			Time:       time.Date(2016, time.March, 1, 0, i, 0, 123456789, time.UTC),
+			Actor:      "jdoe",
+			Action:     AuditCargoBooked,
+			TrackingID: "ABC",
+		}
+		e.Seal(prev)
+		entries = append(entries, e)
+		prev = e
+	}
+	return entries
+}
+
+func TestAuditChain(t *testing.T) {
+	entries := newAuditChain(3)
+
+	if entries[0].Sequence != 1 || entries[0].PrevHash != "" {
+		t.Errorf("first entry = %d, %q; want = 1, \"\"", entries[0].Sequence, entries[0].PrevHash)
+	}
+	if entries[2].PrevHash != entries[1].Hash {
+		t.Errorf("PrevHash = %s; want = %s", entries[2].PrevHash, entries[1].Hash)
+	}
+	if entries[0].Time.Nanosecond() != 123000000 {
+		t.Errorf("Time = %v; want millisecond precision", entries[0].Time)
+	}
+
+	if err := VerifyAuditChain(entries); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuditChainTampered(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		tamper func([]*AuditEntry) []*AuditEntry
+		want   uint64
+	}{
+		{"changed", func(ee []*AuditEntry) []*AuditEntry { ee[1].Actor = "mallory"; return ee }, 2},
+		{"rehashed", func(ee []*AuditEntry) []*AuditEntry {
+			ee[1].Actor = "mallory"
+			ee[1].Hash = ee[1].ComputeHash()
+			return ee
+		}, 3},
+		{"removed", func(ee []*AuditEntry) []*AuditEntry { return append(ee[:1], ee[2:]...) }, 2},
+		{"reordered", func(ee []*AuditEntry) []*AuditEntry { ee[1], ee[2] = ee[2], ee[1]; return ee }, 2},
+	} {
+		err := VerifyAuditChain(tt.tamper(newAuditChain(3)))
+		cerr, ok := err.(*AuditChainError)
+		if !ok {
+			t.Errorf("%s: err = %v; want an AuditChainError", tt.name, err)
+			continue
+		}
+		if cerr.Sequence != tt.want {
+			t.Errorf("%s: Sequence = %d; want = %d", tt.name, cerr.Sequence, tt.want)
+		}
+	}
+}
+
+func TestAuditQueryMatches(t *testing.T) {
+	at := time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+	e := &AuditEntry{Time: at, Actor: "jdoe", Action: AuditCargoRouted, TrackingID: "ABC"}
+
+	for _, tt := range []struct {
+		q    AuditQuery
+		want bool
+	}{
+		{AuditQuery{}, true},
+		{AuditQuery{TrackingID: "ABC", Actor: "jdoe", Action: AuditCargoRouted}, true},
+		{AuditQuery{TrackingID: "DEF"}, false},
+		{AuditQuery{Actor: "mallory"}, false},
+		{AuditQuery{Action: AuditCargoBooked}, false},
+		{AuditQuery{From: at, To: at.Add(time.Hour)}, true},
+		{AuditQuery{To: at}, false},
+		{AuditQuery{From: at.Add(time.Second)}, false},
+	} {
+		if got := tt.q.Matches(e); got != tt.want {
+			t.Errorf("Matches(%+v) = %v; want = %v", tt.q, got, tt.want)
+		}
+	}
+}
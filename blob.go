@@ -0,0 +1,30 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// BlobStore holds large binary objects, such as scanned documents, outside of
+// the database. Clients download them straight from the store through signed
+// URLs, rather than through the API server.
+type BlobStore interface {
+	// Put stores the object under a key, replacing any previous one.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Delete removes the object stored under a key.
+	Delete(ctx context.Context, key string) error
+
+	// SignedURL returns a URL from which anyone holding it may download the
+	// object, until it expires.
+	SignedURL(key string, expires time.Duration) (string, error)
+}
+
+// MaxSignedURLExpiry is the longest a signed URL may be valid.
+const MaxSignedURLExpiry = 7 * 24 * time.Hour
+
+// ErrInvalidExpiry is used when a signed URL is requested to expire too late,
+// or not at all.
+var ErrInvalidExpiry = errors.New("invalid signed url expiry")
@@ -0,0 +1,119 @@
+package blob
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// gcsHost is the host of the Google Cloud Storage XML API.
+const gcsHost = "storage.googleapis.com"
+
+// GCSConfig holds the bucket and service account of a Google Cloud Storage
+// store.
+type GCSConfig struct {
+	Bucket string
+
+	// ServiceAccount is the email of the service account signing requests,
+	// and PrivateKey its PEM encoded key, both found in the JSON key file of
+	// the account.
+	ServiceAccount string
+	PrivateKey     []byte
+}
+
+type gcsSigner struct {
+	cfg GCSConfig
+	key *rsa.PrivateKey
+}
+
+// sign returns a signed URL, as described by the V4 signing process of Cloud
+// Storage.
+func (s *gcsSigner) sign(method, key string, expires time.Duration, now time.Time) (string, error) {
+	if err := checkExpiry(expires); err != nil {
+		return "", err
+	}
+
+	now = now.UTC()
+	date := now.Format("20060102")
+	googDate := now.Format("20060102T150405Z")
+	scope := strings.Join([]string{date, "auto", "storage", "goog4_request"}, "/")
+
+	path := "/" + uriEncode(s.cfg.Bucket, true) + "/" + uriEncode(key, false)
+	query := canonicalQuery([][2]string{
+		{"X-Goog-Algorithm", "GOOG4-RSA-SHA256"},
+		{"X-Goog-Credential", s.cfg.ServiceAccount + "/" + scope},
+		{"X-Goog-Date", googDate},
+		{"X-Goog-Expires", strconv.Itoa(int(expires.Seconds()))},
+		{"X-Goog-SignedHeaders", "host"},
+	})
+
+	hash := sha256.Sum256([]byte(canonicalRequest(method, path, query, gcsHost)))
+	stringToSign := strings.Join([]string{
+		"GOOG4-RSA-SHA256",
+		googDate,
+		scope,
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	digest := sha256.Sum256([]byte(stringToSign))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return "https://" + gcsHost + path + "?" + query + "&X-Goog-Signature=" + hex.EncodeToString(sig), nil
+}
+
+// parsePrivateKey parses a PEM encoded RSA key, in either PKCS #8 form, as
+// found in service account key files, or PKCS #1 form.
+func parsePrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("blob: no PEM encoded private key")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("blob: private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// NewGCSStore returns a BlobStore keeping objects in a Google Cloud Storage
+// bucket. A nil client uses http.DefaultClient.
+func NewGCSStore(cfg GCSConfig, client *http.Client) (shipping.BlobStore, error) {
+	key, err := parsePrivateKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &store{
+		signer: &gcsSigner{cfg: cfg, key: key},
+		client: client,
+		now:    time.Now,
+	}, nil
+}
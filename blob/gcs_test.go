@@ -0,0 +1,82 @@
+package blob
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGCSSignedURL(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err := NewGCSStore(GCSConfig{
+		Bucket:         "example-bucket",
+		ServiceAccount: "documents@example-project.iam.gserviceaccount.com",
+		PrivateKey:     pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := bs.(*store)
+	s.now = func() time.Time { return time.Date(2019, time.February, 1, 9, 0, 0, 0, time.UTC) }
+
+	got, err := s.SignedURL("cargos/ABC123/bill of lading.pdf", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "/example-bucket/cargos/ABC123/bill%20of%20lading.pdf"; u.EscapedPath() != want {
+		t.Errorf("path = %s; want = %s", u.EscapedPath(), want)
+	}
+
+	q := u.Query()
+	if want := "documents@example-project.iam.gserviceaccount.com/20190201/auto/storage/goog4_request"; q.Get("X-Goog-Credential") != want {
+		t.Errorf("X-Goog-Credential = %s; want = %s", q.Get("X-Goog-Credential"), want)
+	}
+	if q.Get("X-Goog-Expires") != "3600" {
+		t.Errorf("X-Goog-Expires = %s; want = %s", q.Get("X-Goog-Expires"), "3600")
+	}
+
+	// The signature covers everything up to itself.
+	i := strings.Index(u.RawQuery, "&X-Goog-Signature=")
+	canonical := canonicalRequest("GET", u.EscapedPath(), u.RawQuery[:i], gcsHost)
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := "GOOG4-RSA-SHA256\n20190201T090000Z\n20190201/auto/storage/goog4_request\n" + hex.EncodeToString(hash[:])
+	digest := sha256.Sum256([]byte(stringToSign))
+
+	sig, err := hex.DecodeString(q.Get("X-Goog-Signature"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+}
+
+func TestNewGCSStoreInvalidKey(t *testing.T) {
+	if _, err := NewGCSStore(GCSConfig{Bucket: "example-bucket", PrivateKey: []byte("not a key")}, nil); err == nil {
+		t.Error("expected an error")
+	}
+}
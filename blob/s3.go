@@ -0,0 +1,88 @@
+package blob
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// S3Config holds the bucket and credentials of an Amazon S3 store.
+type S3Config struct {
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+type s3Signer struct {
+	cfg  S3Config
+	host string
+}
+
+// sign returns a presigned URL, as described by AWS Signature Version 4.
+func (s *s3Signer) sign(method, key string, expires time.Duration, now time.Time) (string, error) {
+	if err := checkExpiry(expires); err != nil {
+		return "", err
+	}
+
+	now = now.UTC()
+	date := now.Format("20060102")
+	amzDate := now.Format("20060102T150405Z")
+	scope := strings.Join([]string{date, s.cfg.Region, "s3", "aws4_request"}, "/")
+
+	path := "/" + uriEncode(key, false)
+	query := canonicalQuery([][2]string{
+		{"X-Amz-Algorithm", "AWS4-HMAC-SHA256"},
+		{"X-Amz-Credential", s.cfg.AccessKeyID + "/" + scope},
+		{"X-Amz-Date", amzDate},
+		{"X-Amz-Expires", strconv.Itoa(int(expires.Seconds()))},
+		{"X-Amz-SignedHeaders", "host"},
+	})
+
+	hash := sha256.Sum256([]byte(canonicalRequest(method, path, query, s.host)))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	signingKey := []byte("AWS4" + s.cfg.SecretAccessKey)
+	for _, part := range []string{date, s.cfg.Region, "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	return "https://" + s.host + path + "?" + query + "&X-Amz-Signature=" + signature, nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// NewS3Store returns a BlobStore keeping objects in an Amazon S3 bucket,
+// addressed virtual-hosted style. A nil client uses http.DefaultClient.
+func NewS3Store(cfg S3Config, client *http.Client) shipping.BlobStore {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	host := cfg.Bucket + ".s3." + cfg.Region + ".amazonaws.com"
+	if cfg.Region == "us-east-1" {
+		host = cfg.Bucket + ".s3.amazonaws.com"
+	}
+
+	return &store{
+		signer: &s3Signer{cfg: cfg, host: host},
+		client: client,
+		now:    time.Now,
+	}
+}
@@ -0,0 +1,223 @@
+package booking
+
+import (
+	"context"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type authorizingService struct {
+	authorize Authorizer
+	next      Service
+}
+
+// NewAuthorizingService returns a new instance of an authorizing Service,
+// refusing the calls the authorizer returns an error for. Methods that cannot
+// fail return nothing when refused.
+func NewAuthorizingService(a Authorizer, s Service) Service {
+	return &authorizingService{authorize: a, next: s}
+}
+
+func (s *authorizingService) BookNewCargo(ctx context.Context, customer shipping.CustomerID, origin, destination shipping.UNLocode, deadline time.Time, equipment shipping.EquipmentType) (shipping.TrackingID, error) {
+	if err := s.authorize(ctx, "book"); err != nil {
+		return "", err
+	}
+	return s.next.BookNewCargo(ctx, customer, origin, destination, deadline, equipment)
+}
+
+func (s *authorizingService) SaveDraft(ctx context.Context, d shipping.BookingDraft) (shipping.DraftID, error) {
+	if err := s.authorize(ctx, "save_draft"); err != nil {
+		return "", err
+	}
+	return s.next.SaveDraft(ctx, d)
+}
+
+func (s *authorizingService) Drafts(ctx context.Context, customer shipping.CustomerID) []Draft {
+	if err := s.authorize(ctx, "list_drafts"); err != nil {
+		return nil
+	}
+	return s.next.Drafts(ctx, customer)
+}
+
+func (s *authorizingService) BookDraft(ctx context.Context, id shipping.DraftID) (shipping.TrackingID, error) {
+	if err := s.authorize(ctx, "book_draft"); err != nil {
+		return "", err
+	}
+	return s.next.BookDraft(ctx, id)
+}
+
+func (s *authorizingService) DiscardDraft(ctx context.Context, id shipping.DraftID) error {
+	if err := s.authorize(ctx, "discard_draft"); err != nil {
+		return err
+	}
+	return s.next.DiscardDraft(ctx, id)
+}
+
+func (s *authorizingService) LoadCargo(ctx context.Context, id shipping.TrackingID) (Cargo, error) {
+	if err := s.authorize(ctx, "load"); err != nil {
+		return Cargo{}, err
+	}
+	return s.next.LoadCargo(ctx, id)
+}
+
+func (s *authorizingService) Timeline(ctx context.Context, id shipping.TrackingID) ([]TimelineEntry, error) {
+	if err := s.authorize(ctx, "timeline"); err != nil {
+		return nil, err
+	}
+	return s.next.Timeline(ctx, id)
+}
+
+func (s *authorizingService) RequestPossibleRoutesForCargo(ctx context.Context, id shipping.TrackingID, services ...shipping.ServiceCode) RouteCandidates {
+	if err := s.authorize(ctx, "request_routes"); err != nil {
+		return RouteCandidates{}
+	}
+	return s.next.RequestPossibleRoutesForCargo(ctx, id, services...)
+}
+
+func (s *authorizingService) CheckDeadlineFeasibility(ctx context.Context, origin, destination shipping.UNLocode, deadline time.Time) (Feasibility, error) {
+	if err := s.authorize(ctx, "check_deadline_feasibility"); err != nil {
+		return Feasibility{}, err
+	}
+	return s.next.CheckDeadlineFeasibility(ctx, origin, destination, deadline)
+}
+
+func (s *authorizingService) AssignCargoToRoute(ctx context.Context, id shipping.TrackingID, itinerary shipping.Itinerary) error {
+	if err := s.authorize(ctx, "assign_to_route"); err != nil {
+		return err
+	}
+	return s.next.AssignCargoToRoute(ctx, id, itinerary)
+}
+
+func (s *authorizingService) ReplaceLeg(ctx context.Context, id shipping.TrackingID, index int, leg shipping.Leg) error {
+	if err := s.authorize(ctx, "replace_leg"); err != nil {
+		return err
+	}
+	return s.next.ReplaceLeg(ctx, id, index, leg)
+}
+
+func (s *authorizingService) RollCargo(ctx context.Context, id shipping.TrackingID, voyage shipping.VoyageNumber) error {
+	if err := s.authorize(ctx, "roll_cargo"); err != nil {
+		return err
+	}
+	return s.next.RollCargo(ctx, id, voyage)
+}
+
+func (s *authorizingService) ChangeDestination(ctx context.Context, id shipping.TrackingID, l shipping.UNLocode) error {
+	if err := s.authorize(ctx, "change_destination"); err != nil {
+		return err
+	}
+	return s.next.ChangeDestination(ctx, id, l)
+}
+
+func (s *authorizingService) ChangeDeadlineType(ctx context.Context, id shipping.TrackingID, t shipping.DeadlineType) error {
+	if err := s.authorize(ctx, "change_deadline_type"); err != nil {
+		return err
+	}
+	return s.next.ChangeDeadlineType(ctx, id, t)
+}
+
+func (s *authorizingService) TransferCargo(ctx context.Context, id shipping.TrackingID, from, to shipping.CustomerID) error {
+	if err := s.authorize(ctx, "transfer_cargo"); err != nil {
+		return err
+	}
+	return s.next.TransferCargo(ctx, id, from, to)
+}
+
+func (s *authorizingService) DeclareCargo(ctx context.Context, id shipping.TrackingID, d shipping.CargoDeclaration) error {
+	if err := s.authorize(ctx, "declare_cargo"); err != nil {
+		return err
+	}
+	return s.next.DeclareCargo(ctx, id, d)
+}
+
+func (s *authorizingService) RejectCargo(ctx context.Context, id shipping.TrackingID, deadline time.Time) (shipping.TrackingID, error) {
+	if err := s.authorize(ctx, "reject"); err != nil {
+		return "", err
+	}
+	return s.next.RejectCargo(ctx, id, deadline)
+}
+
+func (s *authorizingService) IssueReleaseCode(ctx context.Context, id shipping.TrackingID) (string, error) {
+	if err := s.authorize(ctx, "issue_release_code"); err != nil {
+		return "", err
+	}
+	return s.next.IssueReleaseCode(ctx, id)
+}
+
+func (s *authorizingService) OmitPortCall(ctx context.Context, voyage shipping.VoyageNumber, locode shipping.UNLocode) ([]shipping.TrackingID, error) {
+	if err := s.authorize(ctx, "omit_port_call"); err != nil {
+		return nil, err
+	}
+	return s.next.OmitPortCall(ctx, voyage, locode)
+}
+
+func (s *authorizingService) RerouteAllVia(ctx context.Context, locode shipping.UNLocode, from, to time.Time) (RerouteResult, error) {
+	if err := s.authorize(ctx, "reroute_all_via"); err != nil {
+		return RerouteResult{}, err
+	}
+	return s.next.RerouteAllVia(ctx, locode, from, to)
+}
+
+func (s *authorizingService) Cargos(ctx context.Context) []Cargo {
+	if err := s.authorize(ctx, "list_cargos"); err != nil {
+		return nil
+	}
+	return s.next.Cargos(ctx)
+}
+
+func (s *authorizingService) StreamCargos(ctx context.Context, fn func(Cargo) error) error {
+	if err := s.authorize(ctx, "stream_cargos"); err != nil {
+		return err
+	}
+	return s.next.StreamCargos(ctx, fn)
+}
+
+func (s *authorizingService) SearchCargos(ctx context.Context, filter string) ([]Cargo, error) {
+	if err := s.authorize(ctx, "search_cargos"); err != nil {
+		return nil, err
+	}
+	return s.next.SearchCargos(ctx, filter)
+}
+
+func (s *authorizingService) Locations(ctx context.Context) []Location {
+	if err := s.authorize(ctx, "list_locations"); err != nil {
+		return nil
+	}
+	return s.next.Locations(ctx)
+}
+
+func (s *authorizingService) RecalculateDelivery(ctx context.Context, id shipping.TrackingID) (Cargo, error) {
+	if err := s.authorize(ctx, "recalculate_delivery"); err != nil {
+		return Cargo{}, err
+	}
+	return s.next.RecalculateDelivery(ctx, id)
+}
+
+func (s *authorizingService) RecalculateAllDeliveries(ctx context.Context, progress ProgressFunc) (RecalculationReport, error) {
+	if err := s.authorize(ctx, "recalculate_all_deliveries"); err != nil {
+		return RecalculationReport{}, err
+	}
+	return s.next.RecalculateAllDeliveries(ctx, progress)
+}
+
+func (s *authorizingService) EmissionsReport(ctx context.Context, from, to time.Time) (EmissionsReport, error) {
+	if err := s.authorize(ctx, "emissions_report"); err != nil {
+		return EmissionsReport{}, err
+	}
+	return s.next.EmissionsReport(ctx, from, to)
+}
+
+func (s *authorizingService) CapacityForecast(ctx context.Context, from, to time.Time) ([]VoyageUtilization, error) {
+	if err := s.authorize(ctx, "capacity_forecast"); err != nil {
+		return nil, err
+	}
+	return s.next.CapacityForecast(ctx, from, to)
+}
+
+func (s *authorizingService) OverbookingReport(ctx context.Context, from, to time.Time) ([]OverbookedVoyage, error) {
+	if err := s.authorize(ctx, "overbooking_report"); err != nil {
+		return nil, err
+	}
+	return s.next.OverbookingReport(ctx, from, to)
+}
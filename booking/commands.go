@@ -0,0 +1,431 @@
+package booking
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/command"
+)
+
+// BookNewCargoCommand books a new cargo. The response is the tracking id of
+// the cargo.
+type BookNewCargoCommand struct {
+	Customer    shipping.CustomerID
+	Origin      shipping.UNLocode
+	Destination shipping.UNLocode
+	Deadline    time.Time
+	Equipment   shipping.EquipmentType
+}
+
+// CommandName implements command.Command.
+func (BookNewCargoCommand) CommandName() string { return "book_new_cargo" }
+
+// Validate implements command.Validator.
+func (c BookNewCargoCommand) Validate() error {
+	if c.Origin == "" || c.Destination == "" || c.Deadline.IsZero() || c.Equipment.String() == "" {
+		return ErrInvalidArgument
+	}
+	return nil
+}
+
+// AuditEntries implements command.Audited.
+func (c BookNewCargoCommand) AuditEntries(response interface{}) []shipping.AuditEntry {
+	return []shipping.AuditEntry{{
+		Action:     shipping.AuditCargoBooked,
+		TrackingID: response.(shipping.TrackingID),
+		Detail: fmt.Sprintf("customer %s, %s to %s by %s",
+			c.Customer, c.Origin, c.Destination, c.Deadline.Format(time.RFC3339)),
+	}}
+}
+
+// AssignCargoToRouteCommand assigns a cargo to a route.
+type AssignCargoToRouteCommand struct {
+	TrackingID shipping.TrackingID
+	Itinerary  shipping.Itinerary
+}
+
+// CommandName implements command.Command.
+func (AssignCargoToRouteCommand) CommandName() string { return "assign_cargo_to_route" }
+
+// Validate implements command.Validator.
+func (c AssignCargoToRouteCommand) Validate() error {
+	if c.TrackingID == "" || len(c.Itinerary.Legs) == 0 {
+		return ErrInvalidArgument
+	}
+	return nil
+}
+
+// AuditEntries implements command.Audited.
+func (c AssignCargoToRouteCommand) AuditEntries(response interface{}) []shipping.AuditEntry {
+	return []shipping.AuditEntry{{
+		Action:     shipping.AuditCargoRouted,
+		TrackingID: c.TrackingID,
+		Detail:     fmt.Sprintf("%d legs", len(c.Itinerary.Legs)),
+	}}
+}
+
+// ReplaceLegCommand replaces a leg of the itinerary of a cargo.
+type ReplaceLegCommand struct {
+	TrackingID shipping.TrackingID
+	Index      int
+	Leg        shipping.Leg
+}
+
+// CommandName implements command.Command.
+func (ReplaceLegCommand) CommandName() string { return "replace_leg" }
+
+// Validate implements command.Validator.
+func (c ReplaceLegCommand) Validate() error {
+	if c.TrackingID == "" || c.Index < 0 || c.Leg.VoyageNumber == "" || c.Leg.LoadLocation == "" || c.Leg.UnloadLocation == "" {
+		return ErrInvalidArgument
+	}
+	return nil
+}
+
+// RollCargoCommand rolls a cargo to a later sailing.
+type RollCargoCommand struct {
+	TrackingID shipping.TrackingID
+	Voyage     shipping.VoyageNumber
+}
+
+// CommandName implements command.Command.
+func (RollCargoCommand) CommandName() string { return "roll_cargo" }
+
+// Validate implements command.Validator.
+func (c RollCargoCommand) Validate() error {
+	if c.TrackingID == "" || c.Voyage == "" {
+		return ErrInvalidArgument
+	}
+	return nil
+}
+
+// AuditEntries implements command.Audited.
+func (c RollCargoCommand) AuditEntries(response interface{}) []shipping.AuditEntry {
+	return []shipping.AuditEntry{{
+		Action:     shipping.AuditCargoRolled,
+		TrackingID: c.TrackingID,
+		Detail:     "to " + string(c.Voyage),
+	}}
+}
+
+// ChangeDestinationCommand changes the destination of a cargo.
+type ChangeDestinationCommand struct {
+	TrackingID  shipping.TrackingID
+	Destination shipping.UNLocode
+}
+
+// CommandName implements command.Command.
+func (ChangeDestinationCommand) CommandName() string { return "change_destination" }
+
+// Validate implements command.Validator.
+func (c ChangeDestinationCommand) Validate() error {
+	if c.TrackingID == "" || c.Destination == "" {
+		return ErrInvalidArgument
+	}
+	return nil
+}
+
+// AuditEntries implements command.Audited.
+func (c ChangeDestinationCommand) AuditEntries(response interface{}) []shipping.AuditEntry {
+	return []shipping.AuditEntry{{
+		Action:     shipping.AuditDestinationChanged,
+		TrackingID: c.TrackingID,
+		Detail:     "to " + string(c.Destination),
+	}}
+}
+
+// ChangeDeadlineTypeCommand changes how strictly the arrival deadline of a
+// cargo holds.
+type ChangeDeadlineTypeCommand struct {
+	TrackingID   shipping.TrackingID
+	DeadlineType shipping.DeadlineType
+}
+
+// CommandName implements command.Command.
+func (ChangeDeadlineTypeCommand) CommandName() string { return "change_deadline_type" }
+
+// Validate implements command.Validator.
+func (c ChangeDeadlineTypeCommand) Validate() error {
+	if c.TrackingID == "" || c.DeadlineType.String() == "" {
+		return ErrInvalidArgument
+	}
+	return nil
+}
+
+// AuditEntries implements command.Audited.
+func (c ChangeDeadlineTypeCommand) AuditEntries(response interface{}) []shipping.AuditEntry {
+	return []shipping.AuditEntry{{
+		Action:     shipping.AuditDeadlineTypeChanged,
+		TrackingID: c.TrackingID,
+		Detail:     "to " + strings.ToLower(c.DeadlineType.String()),
+	}}
+}
+
+// TransferCargoCommand transfers a cargo to another customer.
+type TransferCargoCommand struct {
+	TrackingID shipping.TrackingID
+	From       shipping.CustomerID
+	To         shipping.CustomerID
+}
+
+// CommandName implements command.Command.
+func (TransferCargoCommand) CommandName() string { return "transfer_cargo" }
+
+// Validate implements command.Validator.
+func (c TransferCargoCommand) Validate() error {
+	if c.TrackingID == "" || c.From == "" || c.To == "" || c.From == c.To {
+		return ErrInvalidArgument
+	}
+	return nil
+}
+
+// AuditEntries implements command.Audited.
+func (c TransferCargoCommand) AuditEntries(response interface{}) []shipping.AuditEntry {
+	return []shipping.AuditEntry{{
+		Action:     shipping.AuditCargoTransferred,
+		TrackingID: c.TrackingID,
+		Detail:     "from " + string(c.From) + " to " + string(c.To),
+	}}
+}
+
+// DeclareCargoCommand declares the contents of a cargo.
+type DeclareCargoCommand struct {
+	TrackingID  shipping.TrackingID
+	Declaration shipping.CargoDeclaration
+}
+
+// CommandName implements command.Command.
+func (DeclareCargoCommand) CommandName() string { return "declare_cargo" }
+
+// Validate implements command.Validator.
+func (c DeclareCargoCommand) Validate() error {
+	if c.TrackingID == "" {
+		return ErrInvalidArgument
+	}
+	return nil
+}
+
+// RejectCargoCommand rejects a cargo at its destination. The response is
+// the tracking id of the return cargo.
+type RejectCargoCommand struct {
+	TrackingID shipping.TrackingID
+	Deadline   time.Time
+}
+
+// CommandName implements command.Command.
+func (RejectCargoCommand) CommandName() string { return "reject_cargo" }
+
+// Validate implements command.Validator.
+func (c RejectCargoCommand) Validate() error {
+	if c.TrackingID == "" || c.Deadline.IsZero() {
+		return ErrInvalidArgument
+	}
+	return nil
+}
+
+// AuditEntries implements command.Audited.
+func (c RejectCargoCommand) AuditEntries(response interface{}) []shipping.AuditEntry {
+	return []shipping.AuditEntry{{
+		Action:     shipping.AuditCargoRejected,
+		TrackingID: c.TrackingID,
+		Detail:     "returned as " + string(response.(shipping.TrackingID)),
+	}}
+}
+
+// OmitPortCallCommand omits a port call from the schedule of a voyage. The
+// response is the tracking ids of the cargos that need rerouting.
+type OmitPortCallCommand struct {
+	Voyage   shipping.VoyageNumber
+	Location shipping.UNLocode
+}
+
+// CommandName implements command.Command.
+func (OmitPortCallCommand) CommandName() string { return "omit_port_call" }
+
+// Validate implements command.Validator.
+func (c OmitPortCallCommand) Validate() error {
+	if c.Voyage == "" || c.Location == "" {
+		return ErrInvalidArgument
+	}
+	return nil
+}
+
+// AuditEntries implements command.Audited. An entry is recorded for every
+// affected cargo, or one without a cargo if there are none.
+func (c OmitPortCallCommand) AuditEntries(response interface{}) []shipping.AuditEntry {
+	detail := fmt.Sprintf("%s at %s", c.Voyage, c.Location)
+
+	ids := response.([]shipping.TrackingID)
+	if len(ids) == 0 {
+		return []shipping.AuditEntry{{Action: shipping.AuditPortCallOmitted, Detail: detail}}
+	}
+
+	result := make([]shipping.AuditEntry, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, shipping.AuditEntry{Action: shipping.AuditPortCallOmitted, TrackingID: id, Detail: detail})
+	}
+	return result
+}
+
+// RerouteAllViaCommand reroutes the cargos calling at a disrupted port.
+type RerouteAllViaCommand struct {
+	Location shipping.UNLocode
+	From     time.Time
+	To       time.Time
+}
+
+// CommandName implements command.Command.
+func (RerouteAllViaCommand) CommandName() string { return "reroute_all_via" }
+
+// Validate implements command.Validator.
+func (c RerouteAllViaCommand) Validate() error {
+	if c.Location == "" || c.From.IsZero() || c.To.Before(c.From) {
+		return ErrInvalidArgument
+	}
+	return nil
+}
+
+// AuditEntries implements command.Audited. An entry is recorded for every
+// cargo rerouted.
+func (c RerouteAllViaCommand) AuditEntries(response interface{}) []shipping.AuditEntry {
+	r := response.(RerouteResult)
+
+	result := make([]shipping.AuditEntry, 0, len(r.Rerouted))
+	for _, rc := range r.Rerouted {
+		result = append(result, shipping.AuditEntry{
+			Action:     shipping.AuditCargoRouted,
+			TrackingID: shipping.TrackingID(rc.TrackingID),
+			Detail:     fmt.Sprintf("%d legs, avoiding %s", len(rc.Legs), c.Location),
+		})
+	}
+	return result
+}
+
+// RegisterCommands registers handlers for the booking commands with the
+// bus, handling them with s.
+func RegisterCommands(b *command.Bus, s Service) {
+	b.Handle(BookNewCargoCommand{}.CommandName(), func(ctx context.Context, request interface{}) (interface{}, error) {
+		c := request.(BookNewCargoCommand)
+		return s.BookNewCargo(ctx, c.Customer, c.Origin, c.Destination, c.Deadline, c.Equipment)
+	})
+	b.Handle(AssignCargoToRouteCommand{}.CommandName(), func(ctx context.Context, request interface{}) (interface{}, error) {
+		c := request.(AssignCargoToRouteCommand)
+		return nil, s.AssignCargoToRoute(ctx, c.TrackingID, c.Itinerary)
+	})
+	b.Handle(ReplaceLegCommand{}.CommandName(), func(ctx context.Context, request interface{}) (interface{}, error) {
+		c := request.(ReplaceLegCommand)
+		return nil, s.ReplaceLeg(ctx, c.TrackingID, c.Index, c.Leg)
+	})
+	b.Handle(RollCargoCommand{}.CommandName(), func(ctx context.Context, request interface{}) (interface{}, error) {
+		c := request.(RollCargoCommand)
+		return nil, s.RollCargo(ctx, c.TrackingID, c.Voyage)
+	})
+	b.Handle(ChangeDestinationCommand{}.CommandName(), func(ctx context.Context, request interface{}) (interface{}, error) {
+		c := request.(ChangeDestinationCommand)
+		return nil, s.ChangeDestination(ctx, c.TrackingID, c.Destination)
+	})
+	b.Handle(ChangeDeadlineTypeCommand{}.CommandName(), func(ctx context.Context, request interface{}) (interface{}, error) {
+		c := request.(ChangeDeadlineTypeCommand)
+		return nil, s.ChangeDeadlineType(ctx, c.TrackingID, c.DeadlineType)
+	})
+	b.Handle(TransferCargoCommand{}.CommandName(), func(ctx context.Context, request interface{}) (interface{}, error) {
+		c := request.(TransferCargoCommand)
+		return nil, s.TransferCargo(ctx, c.TrackingID, c.From, c.To)
+	})
+	b.Handle(DeclareCargoCommand{}.CommandName(), func(ctx context.Context, request interface{}) (interface{}, error) {
+		c := request.(DeclareCargoCommand)
+		return nil, s.DeclareCargo(ctx, c.TrackingID, c.Declaration)
+	})
+	b.Handle(RejectCargoCommand{}.CommandName(), func(ctx context.Context, request interface{}) (interface{}, error) {
+		c := request.(RejectCargoCommand)
+		return s.RejectCargo(ctx, c.TrackingID, c.Deadline)
+	})
+	b.Handle(OmitPortCallCommand{}.CommandName(), func(ctx context.Context, request interface{}) (interface{}, error) {
+		c := request.(OmitPortCallCommand)
+		return s.OmitPortCall(ctx, c.Voyage, c.Location)
+	})
+	b.Handle(RerouteAllViaCommand{}.CommandName(), func(ctx context.Context, request interface{}) (interface{}, error) {
+		c := request.(RerouteAllViaCommand)
+		return s.RerouteAllVia(ctx, c.Location, c.From, c.To)
+	})
+}
+
+type commandService struct {
+	Service
+	bus *command.Bus
+}
+
+// NewCommandService returns a Service dispatching the booking commands
+// through the bus, which should have them registered with
+// RegisterCommands. Queries are passed on to next.
+func NewCommandService(b *command.Bus, next Service) Service {
+	return &commandService{Service: next, bus: b}
+}
+
+func (s *commandService) BookNewCargo(ctx context.Context, customer shipping.CustomerID, origin, destination shipping.UNLocode, deadline time.Time, equipment shipping.EquipmentType) (shipping.TrackingID, error) {
+	response, err := s.bus.Dispatch(ctx, BookNewCargoCommand{
+		Customer:    customer,
+		Origin:      origin,
+		Destination: destination,
+		Deadline:    deadline,
+		Equipment:   equipment,
+	})
+	id, _ := response.(shipping.TrackingID)
+	return id, err
+}
+
+func (s *commandService) AssignCargoToRoute(ctx context.Context, id shipping.TrackingID, itinerary shipping.Itinerary) error {
+	_, err := s.bus.Dispatch(ctx, AssignCargoToRouteCommand{TrackingID: id, Itinerary: itinerary})
+	return err
+}
+
+func (s *commandService) ReplaceLeg(ctx context.Context, id shipping.TrackingID, index int, leg shipping.Leg) error {
+	_, err := s.bus.Dispatch(ctx, ReplaceLegCommand{TrackingID: id, Index: index, Leg: leg})
+	return err
+}
+
+func (s *commandService) RollCargo(ctx context.Context, id shipping.TrackingID, voyage shipping.VoyageNumber) error {
+	_, err := s.bus.Dispatch(ctx, RollCargoCommand{TrackingID: id, Voyage: voyage})
+	return err
+}
+
+func (s *commandService) ChangeDestination(ctx context.Context, id shipping.TrackingID, destination shipping.UNLocode) error {
+	_, err := s.bus.Dispatch(ctx, ChangeDestinationCommand{TrackingID: id, Destination: destination})
+	return err
+}
+
+func (s *commandService) ChangeDeadlineType(ctx context.Context, id shipping.TrackingID, t shipping.DeadlineType) error {
+	_, err := s.bus.Dispatch(ctx, ChangeDeadlineTypeCommand{TrackingID: id, DeadlineType: t})
+	return err
+}
+
+func (s *commandService) TransferCargo(ctx context.Context, id shipping.TrackingID, from, to shipping.CustomerID) error {
+	_, err := s.bus.Dispatch(ctx, TransferCargoCommand{TrackingID: id, From: from, To: to})
+	return err
+}
+
+func (s *commandService) DeclareCargo(ctx context.Context, id shipping.TrackingID, d shipping.CargoDeclaration) error {
+	_, err := s.bus.Dispatch(ctx, DeclareCargoCommand{TrackingID: id, Declaration: d})
+	return err
+}
+
+func (s *commandService) RejectCargo(ctx context.Context, id shipping.TrackingID, deadline time.Time) (shipping.TrackingID, error) {
+	response, err := s.bus.Dispatch(ctx, RejectCargoCommand{TrackingID: id, Deadline: deadline})
+	returnID, _ := response.(shipping.TrackingID)
+	return returnID, err
+}
+
+func (s *commandService) OmitPortCall(ctx context.Context, voyage shipping.VoyageNumber, locode shipping.UNLocode) ([]shipping.TrackingID, error) {
+	response, err := s.bus.Dispatch(ctx, OmitPortCallCommand{Voyage: voyage, Location: locode})
+	ids, _ := response.([]shipping.TrackingID)
+	return ids, err
+}
+
+func (s *commandService) RerouteAllVia(ctx context.Context, locode shipping.UNLocode, from, to time.Time) (RerouteResult, error) {
+	response, err := s.bus.Dispatch(ctx, RerouteAllViaCommand{Location: locode, From: from, To: to})
+	r, _ := response.(RerouteResult)
+	return r, err
+}
@@ -1,6 +1,7 @@
 package booking
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-kit/kit/metrics"
@@ -23,65 +24,263 @@ func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram,
 	}
 }
 
-func (s *instrumentingService) BookNewCargo(origin, destination shipping.UNLocode, deadline time.Time) (shipping.TrackingID, error) {
+func (s *instrumentingService) BookNewCargo(ctx context.Context, customer shipping.CustomerID, origin, destination shipping.UNLocode, deadline time.Time, equipment shipping.EquipmentType) (shipping.TrackingID, error) {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "book").Add(1)
 		s.requestLatency.With("method", "book").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.next.BookNewCargo(origin, destination, deadline)
+	return s.next.BookNewCargo(ctx, customer, origin, destination, deadline, equipment)
 }
 
-func (s *instrumentingService) LoadCargo(id shipping.TrackingID) (c Cargo, err error) {
+func (s *instrumentingService) SaveDraft(ctx context.Context, d shipping.BookingDraft) (shipping.DraftID, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "save_draft").Add(1)
+		s.requestLatency.With("method", "save_draft").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.SaveDraft(ctx, d)
+}
+
+func (s *instrumentingService) Drafts(ctx context.Context, customer shipping.CustomerID) []Draft {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "list_drafts").Add(1)
+		s.requestLatency.With("method", "list_drafts").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Drafts(ctx, customer)
+}
+
+func (s *instrumentingService) BookDraft(ctx context.Context, id shipping.DraftID) (shipping.TrackingID, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "book_draft").Add(1)
+		s.requestLatency.With("method", "book_draft").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.BookDraft(ctx, id)
+}
+
+func (s *instrumentingService) DiscardDraft(ctx context.Context, id shipping.DraftID) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "discard_draft").Add(1)
+		s.requestLatency.With("method", "discard_draft").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.DiscardDraft(ctx, id)
+}
+
+func (s *instrumentingService) LoadCargo(ctx context.Context, id shipping.TrackingID) (c Cargo, err error) {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "load").Add(1)
 		s.requestLatency.With("method", "load").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.next.LoadCargo(id)
+	return s.next.LoadCargo(ctx, id)
+}
+
+func (s *instrumentingService) Timeline(ctx context.Context, id shipping.TrackingID) ([]TimelineEntry, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "timeline").Add(1)
+		s.requestLatency.With("method", "timeline").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Timeline(ctx, id)
 }
 
-func (s *instrumentingService) RequestPossibleRoutesForCargo(id shipping.TrackingID) []shipping.Itinerary {
+func (s *instrumentingService) RequestPossibleRoutesForCargo(ctx context.Context, id shipping.TrackingID, services ...shipping.ServiceCode) RouteCandidates {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "request_routes").Add(1)
 		s.requestLatency.With("method", "request_routes").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.next.RequestPossibleRoutesForCargo(id)
+	return s.next.RequestPossibleRoutesForCargo(ctx, id, services...)
 }
 
-func (s *instrumentingService) AssignCargoToRoute(id shipping.TrackingID, itinerary shipping.Itinerary) (err error) {
+func (s *instrumentingService) CheckDeadlineFeasibility(ctx context.Context, origin, destination shipping.UNLocode, deadline time.Time) (Feasibility, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "check_deadline_feasibility").Add(1)
+		s.requestLatency.With("method", "check_deadline_feasibility").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.CheckDeadlineFeasibility(ctx, origin, destination, deadline)
+}
+
+func (s *instrumentingService) AssignCargoToRoute(ctx context.Context, id shipping.TrackingID, itinerary shipping.Itinerary) (err error) {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "assign_to_route").Add(1)
 		s.requestLatency.With("method", "assign_to_route").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.next.AssignCargoToRoute(id, itinerary)
+	return s.next.AssignCargoToRoute(ctx, id, itinerary)
+}
+
+func (s *instrumentingService) ReplaceLeg(ctx context.Context, id shipping.TrackingID, index int, leg shipping.Leg) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "replace_leg").Add(1)
+		s.requestLatency.With("method", "replace_leg").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.ReplaceLeg(ctx, id, index, leg)
 }
 
-func (s *instrumentingService) ChangeDestination(id shipping.TrackingID, l shipping.UNLocode) (err error) {
+func (s *instrumentingService) RollCargo(ctx context.Context, id shipping.TrackingID, voyage shipping.VoyageNumber) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "roll_cargo").Add(1)
+		s.requestLatency.With("method", "roll_cargo").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.RollCargo(ctx, id, voyage)
+}
+
+func (s *instrumentingService) ChangeDestination(ctx context.Context, id shipping.TrackingID, l shipping.UNLocode) (err error) {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "change_destination").Add(1)
 		s.requestLatency.With("method", "change_destination").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.next.ChangeDestination(id, l)
+	return s.next.ChangeDestination(ctx, id, l)
+}
+
+func (s *instrumentingService) ChangeDeadlineType(ctx context.Context, id shipping.TrackingID, t shipping.DeadlineType) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "change_deadline_type").Add(1)
+		s.requestLatency.With("method", "change_deadline_type").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.ChangeDeadlineType(ctx, id, t)
+}
+
+func (s *instrumentingService) TransferCargo(ctx context.Context, id shipping.TrackingID, from, to shipping.CustomerID) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "transfer_cargo").Add(1)
+		s.requestLatency.With("method", "transfer_cargo").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.TransferCargo(ctx, id, from, to)
+}
+
+func (s *instrumentingService) DeclareCargo(ctx context.Context, id shipping.TrackingID, d shipping.CargoDeclaration) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "declare_cargo").Add(1)
+		s.requestLatency.With("method", "declare_cargo").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.DeclareCargo(ctx, id, d)
 }
 
-func (s *instrumentingService) Cargos() []Cargo {
+func (s *instrumentingService) RejectCargo(ctx context.Context, id shipping.TrackingID, deadline time.Time) (shipping.TrackingID, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "reject").Add(1)
+		s.requestLatency.With("method", "reject").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.RejectCargo(ctx, id, deadline)
+}
+
+func (s *instrumentingService) IssueReleaseCode(ctx context.Context, id shipping.TrackingID) (string, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "issue_release_code").Add(1)
+		s.requestLatency.With("method", "issue_release_code").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.IssueReleaseCode(ctx, id)
+}
+
+func (s *instrumentingService) OmitPortCall(ctx context.Context, voyage shipping.VoyageNumber, locode shipping.UNLocode) ([]shipping.TrackingID, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "omit_port_call").Add(1)
+		s.requestLatency.With("method", "omit_port_call").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.OmitPortCall(ctx, voyage, locode)
+}
+
+func (s *instrumentingService) RerouteAllVia(ctx context.Context, locode shipping.UNLocode, from, to time.Time) (RerouteResult, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "reroute_all_via").Add(1)
+		s.requestLatency.With("method", "reroute_all_via").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.RerouteAllVia(ctx, locode, from, to)
+}
+
+func (s *instrumentingService) Cargos(ctx context.Context) []Cargo {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "list_cargos").Add(1)
 		s.requestLatency.With("method", "list_cargos").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.next.Cargos()
+	return s.next.Cargos(ctx)
+}
+
+func (s *instrumentingService) StreamCargos(ctx context.Context, fn func(Cargo) error) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "stream_cargos").Add(1)
+		s.requestLatency.With("method", "stream_cargos").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.StreamCargos(ctx, fn)
 }
 
-func (s *instrumentingService) Locations() []Location {
+func (s *instrumentingService) SearchCargos(ctx context.Context, filter string) ([]Cargo, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "search_cargos").Add(1)
+		s.requestLatency.With("method", "search_cargos").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.SearchCargos(ctx, filter)
+}
+
+func (s *instrumentingService) Locations(ctx context.Context) []Location {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "list_locations").Add(1)
 		s.requestLatency.With("method", "list_locations").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.next.Locations()
+	return s.next.Locations(ctx)
+}
+
+func (s *instrumentingService) RecalculateDelivery(ctx context.Context, id shipping.TrackingID) (Cargo, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "recalculate_delivery").Add(1)
+		s.requestLatency.With("method", "recalculate_delivery").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.RecalculateDelivery(ctx, id)
+}
+
+func (s *instrumentingService) RecalculateAllDeliveries(ctx context.Context, progress ProgressFunc) (RecalculationReport, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "recalculate_all_deliveries").Add(1)
+		s.requestLatency.With("method", "recalculate_all_deliveries").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.RecalculateAllDeliveries(ctx, progress)
+}
+
+func (s *instrumentingService) EmissionsReport(ctx context.Context, from, to time.Time) (EmissionsReport, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "emissions_report").Add(1)
+		s.requestLatency.With("method", "emissions_report").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.EmissionsReport(ctx, from, to)
+}
+
+func (s *instrumentingService) CapacityForecast(ctx context.Context, from, to time.Time) ([]VoyageUtilization, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "capacity_forecast").Add(1)
+		s.requestLatency.With("method", "capacity_forecast").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.CapacityForecast(ctx, from, to)
+}
+
+func (s *instrumentingService) OverbookingReport(ctx context.Context, from, to time.Time) ([]OverbookedVoyage, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "overbooking_report").Add(1)
+		s.requestLatency.With("method", "overbooking_report").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.OverbookingReport(ctx, from, to)
 }
@@ -1,11 +1,14 @@
 package booking
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/go-kit/kit/log"
 
 	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
 )
 
 type loggingService struct {
@@ -18,84 +21,413 @@ func NewLoggingService(logger log.Logger, s Service) Service {
 	return &loggingService{logger, s}
 }
 
-func (s *loggingService) BookNewCargo(origin shipping.UNLocode, destination shipping.UNLocode, deadline time.Time) (id shipping.TrackingID, err error) {
+func (s *loggingService) BookNewCargo(ctx context.Context, customer shipping.CustomerID, origin shipping.UNLocode, destination shipping.UNLocode, deadline time.Time, equipment shipping.EquipmentType) (id shipping.TrackingID, err error) {
 	defer func(begin time.Time) {
 		s.logger.Log(
 			"method", "book",
+			"request_id", correlation.FromContext(ctx),
+			"customer", customer,
 			"origin", origin,
 			"destination", destination,
 			"arrival_deadline", deadline,
+			"equipment", equipment,
 			"took", time.Since(begin),
 			"err", err,
 		)
 	}(time.Now())
-	return s.next.BookNewCargo(origin, destination, deadline)
+	return s.next.BookNewCargo(ctx, customer, origin, destination, deadline, equipment)
 }
 
-func (s *loggingService) LoadCargo(id shipping.TrackingID) (c Cargo, err error) {
+func (s *loggingService) SaveDraft(ctx context.Context, d shipping.BookingDraft) (id shipping.DraftID, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "save_draft",
+			"request_id", correlation.FromContext(ctx),
+			"draft_id", id,
+			"customer", d.Customer,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.SaveDraft(ctx, d)
+}
+
+func (s *loggingService) Drafts(ctx context.Context, customer shipping.CustomerID) []Draft {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_drafts",
+			"request_id", correlation.FromContext(ctx),
+			"customer", customer,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.Drafts(ctx, customer)
+}
+
+func (s *loggingService) BookDraft(ctx context.Context, id shipping.DraftID) (trackingID shipping.TrackingID, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "book_draft",
+			"request_id", correlation.FromContext(ctx),
+			"draft_id", id,
+			"tracking_id", trackingID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.BookDraft(ctx, id)
+}
+
+func (s *loggingService) DiscardDraft(ctx context.Context, id shipping.DraftID) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "discard_draft",
+			"request_id", correlation.FromContext(ctx),
+			"draft_id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.DiscardDraft(ctx, id)
+}
+
+func (s *loggingService) LoadCargo(ctx context.Context, id shipping.TrackingID) (c Cargo, err error) {
 	defer func(begin time.Time) {
 		s.logger.Log(
 			"method", "load",
+			"request_id", correlation.FromContext(ctx),
 			"tracking_id", id,
 			"took", time.Since(begin),
 			"err", err,
 		)
 	}(time.Now())
-	return s.next.LoadCargo(id)
+	return s.next.LoadCargo(ctx, id)
 }
 
-func (s *loggingService) RequestPossibleRoutesForCargo(id shipping.TrackingID) []shipping.Itinerary {
+func (s *loggingService) Timeline(ctx context.Context, id shipping.TrackingID) (entries []TimelineEntry, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "timeline",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"entries", len(entries),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Timeline(ctx, id)
+}
+
+func (s *loggingService) RequestPossibleRoutesForCargo(ctx context.Context, id shipping.TrackingID, services ...shipping.ServiceCode) (rc RouteCandidates) {
 	defer func(begin time.Time) {
 		s.logger.Log(
 			"method", "request_routes",
+			"request_id", correlation.FromContext(ctx),
 			"tracking_id", id,
+			"services", fmt.Sprint(services),
+			"degraded", rc.Degraded,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.RequestPossibleRoutesForCargo(ctx, id, services...)
+}
+
+func (s *loggingService) CheckDeadlineFeasibility(ctx context.Context, origin, destination shipping.UNLocode, deadline time.Time) (f Feasibility, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "check_deadline_feasibility",
+			"request_id", correlation.FromContext(ctx),
+			"origin", origin,
+			"destination", destination,
+			"arrival_deadline", deadline,
+			"feasible", f.Feasible,
+			"degraded", f.Degraded,
 			"took", time.Since(begin),
+			"err", err,
 		)
 	}(time.Now())
-	return s.next.RequestPossibleRoutesForCargo(id)
+	return s.next.CheckDeadlineFeasibility(ctx, origin, destination, deadline)
 }
 
-func (s *loggingService) AssignCargoToRoute(id shipping.TrackingID, itinerary shipping.Itinerary) (err error) {
+func (s *loggingService) AssignCargoToRoute(ctx context.Context, id shipping.TrackingID, itinerary shipping.Itinerary) (err error) {
 	defer func(begin time.Time) {
 		s.logger.Log(
 			"method", "assign_to_route",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.AssignCargoToRoute(ctx, id, itinerary)
+}
+
+func (s *loggingService) ReplaceLeg(ctx context.Context, id shipping.TrackingID, index int, leg shipping.Leg) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "replace_leg",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"index", index,
+			"voyage", leg.VoyageNumber,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.ReplaceLeg(ctx, id, index, leg)
+}
+
+func (s *loggingService) RollCargo(ctx context.Context, id shipping.TrackingID, voyage shipping.VoyageNumber) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "roll_cargo",
+			"request_id", correlation.FromContext(ctx),
 			"tracking_id", id,
+			"voyage", voyage,
 			"took", time.Since(begin),
 			"err", err,
 		)
 	}(time.Now())
-	return s.next.AssignCargoToRoute(id, itinerary)
+	return s.next.RollCargo(ctx, id, voyage)
 }
 
-func (s *loggingService) ChangeDestination(id shipping.TrackingID, l shipping.UNLocode) (err error) {
+func (s *loggingService) ChangeDestination(ctx context.Context, id shipping.TrackingID, l shipping.UNLocode) (err error) {
 	defer func(begin time.Time) {
 		s.logger.Log(
 			"method", "change_destination",
+			"request_id", correlation.FromContext(ctx),
 			"tracking_id", id,
 			"destination", l,
 			"took", time.Since(begin),
 			"err", err,
 		)
 	}(time.Now())
-	return s.next.ChangeDestination(id, l)
+	return s.next.ChangeDestination(ctx, id, l)
+}
+
+func (s *loggingService) ChangeDeadlineType(ctx context.Context, id shipping.TrackingID, t shipping.DeadlineType) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "change_deadline_type",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"deadline_type", t,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.ChangeDeadlineType(ctx, id, t)
+}
+
+func (s *loggingService) TransferCargo(ctx context.Context, id shipping.TrackingID, from, to shipping.CustomerID) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "transfer_cargo",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"from", from,
+			"to", to,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.TransferCargo(ctx, id, from, to)
+}
+
+func (s *loggingService) DeclareCargo(ctx context.Context, id shipping.TrackingID, d shipping.CargoDeclaration) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "declare_cargo",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"commodities", len(d.Commodities),
+			"documents", len(d.Documents),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.DeclareCargo(ctx, id, d)
+}
+
+func (s *loggingService) RejectCargo(ctx context.Context, id shipping.TrackingID, deadline time.Time) (returnID shipping.TrackingID, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "reject",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"arrival_deadline", deadline,
+			"return_id", returnID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.RejectCargo(ctx, id, deadline)
 }
 
-func (s *loggingService) Cargos() []Cargo {
+// The PIN of the release code is left out of the log.
+func (s *loggingService) IssueReleaseCode(ctx context.Context, id shipping.TrackingID) (pin string, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "issue_release_code",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.IssueReleaseCode(ctx, id)
+}
+
+func (s *loggingService) OmitPortCall(ctx context.Context, voyage shipping.VoyageNumber, locode shipping.UNLocode) (ids []shipping.TrackingID, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "omit_port_call",
+			"request_id", correlation.FromContext(ctx),
+			"voyage_number", voyage,
+			"location", locode,
+			"affected", len(ids),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.OmitPortCall(ctx, voyage, locode)
+}
+
+func (s *loggingService) RerouteAllVia(ctx context.Context, locode shipping.UNLocode, from, to time.Time) (r RerouteResult, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "reroute_all_via",
+			"request_id", correlation.FromContext(ctx),
+			"location", locode,
+			"from", from,
+			"to", to,
+			"rerouted", len(r.Rerouted),
+			"needs_review", len(r.NeedsReview),
+			"infeasible", len(r.Infeasible),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.RerouteAllVia(ctx, locode, from, to)
+}
+
+func (s *loggingService) Cargos(ctx context.Context) []Cargo {
 	defer func(begin time.Time) {
 		s.logger.Log(
 			"method", "list_cargos",
+			"request_id", correlation.FromContext(ctx),
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.Cargos(ctx)
+}
+
+func (s *loggingService) StreamCargos(ctx context.Context, fn func(Cargo) error) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "stream_cargos",
+			"request_id", correlation.FromContext(ctx),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.StreamCargos(ctx, fn)
+}
+
+func (s *loggingService) SearchCargos(ctx context.Context, filter string) (cs []Cargo, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "search_cargos",
+			"request_id", correlation.FromContext(ctx),
+			"filter", filter,
+			"results", len(cs),
 			"took", time.Since(begin),
+			"err", err,
 		)
 	}(time.Now())
-	return s.next.Cargos()
+	return s.next.SearchCargos(ctx, filter)
 }
 
-func (s *loggingService) Locations() []Location {
+func (s *loggingService) Locations(ctx context.Context) []Location {
 	defer func(begin time.Time) {
 		s.logger.Log(
 			"method", "list_locations",
+			"request_id", correlation.FromContext(ctx),
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.Locations(ctx)
+}
+
+func (s *loggingService) RecalculateDelivery(ctx context.Context, id shipping.TrackingID) (c Cargo, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "recalculate_delivery",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.RecalculateDelivery(ctx, id)
+}
+
+func (s *loggingService) RecalculateAllDeliveries(ctx context.Context, progress ProgressFunc) (r RecalculationReport, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "recalculate_all_deliveries",
+			"request_id", correlation.FromContext(ctx),
+			"total", r.Total,
+			"recalculated", r.Recalculated,
+			"failed", len(r.Failed),
 			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.RecalculateAllDeliveries(ctx, progress)
+}
+
+func (s *loggingService) EmissionsReport(ctx context.Context, from, to time.Time) (r EmissionsReport, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "emissions_report",
+			"request_id", correlation.FromContext(ctx),
+			"from", from,
+			"to", to,
+			"cargos", r.Cargos,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.EmissionsReport(ctx, from, to)
+}
+
+func (s *loggingService) CapacityForecast(ctx context.Context, from, to time.Time) (voyages []VoyageUtilization, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "capacity_forecast",
+			"request_id", correlation.FromContext(ctx),
+			"from", from,
+			"to", to,
+			"voyages", len(voyages),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.CapacityForecast(ctx, from, to)
+}
+
+func (s *loggingService) OverbookingReport(ctx context.Context, from, to time.Time) (voyages []OverbookedVoyage, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "overbooking_report",
+			"request_id", correlation.FromContext(ctx),
+			"from", from,
+			"to", to,
+			"voyages", len(voyages),
+			"took", time.Since(begin),
+			"err", err,
 		)
 	}(time.Now())
-	return s.next.Locations()
+	return s.next.OverbookingReport(ctx, from, to)
 }
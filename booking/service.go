@@ -3,193 +3,2441 @@
 package booking
 
 import (
+	"context"
 	"errors"
+	"math"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+
 	shipping "github.com/marcusolsson/goddd"
 )
 
 // ErrInvalidArgument is returned when one or more arguments are invalid.
 var ErrInvalidArgument = errors.New("invalid argument")
 
+// ErrDuplicateBooking is returned when a booking is blocked for repeating a
+// recent booking.
+var ErrDuplicateBooking = errors.New("possible duplicate booking")
+
+// EventHandler provides a means of subscribing to routing changes.
+type EventHandler interface {
+	// CargoWasBooked is called when a new cargo is booked.
+	CargoWasBooked(ctx context.Context, c *shipping.Cargo)
+
+	CargoWasRouted(context.Context, *shipping.Cargo)
+
+	// CargoWasRolled is called instead of CargoWasRouted when a cargo is
+	// rolled from a voyage to a later sailing.
+	CargoWasRolled(ctx context.Context, c *shipping.Cargo, from shipping.VoyageNumber)
+
+	// CargoWasTransferred is called when a cargo is transferred from one
+	// customer to another.
+	CargoWasTransferred(ctx context.Context, c *shipping.Cargo, from shipping.CustomerID)
+}
+
+// EventHandlers notifies several event handlers in order.
+type EventHandlers []EventHandler
+
+// CargoWasBooked notifies every handler.
+func (hs EventHandlers) CargoWasBooked(ctx context.Context, c *shipping.Cargo) {
+	for _, h := range hs {
+		h.CargoWasBooked(ctx, c)
+	}
+}
+
+// CargoWasRouted notifies every handler.
+func (hs EventHandlers) CargoWasRouted(ctx context.Context, c *shipping.Cargo) {
+	for _, h := range hs {
+		h.CargoWasRouted(ctx, c)
+	}
+}
+
+// CargoWasRolled notifies every handler.
+func (hs EventHandlers) CargoWasRolled(ctx context.Context, c *shipping.Cargo, from shipping.VoyageNumber) {
+	for _, h := range hs {
+		h.CargoWasRolled(ctx, c, from)
+	}
+}
+
+// CargoWasTransferred notifies every handler.
+func (hs EventHandlers) CargoWasTransferred(ctx context.Context, c *shipping.Cargo, from shipping.CustomerID) {
+	for _, h := range hs {
+		h.CargoWasTransferred(ctx, c, from)
+	}
+}
+
 // Service is the interface that provides booking methods.
 type Service interface {
-	// BookNewCargo registers a new cargo in the tracking system, not yet
-	// routed.
-	BookNewCargo(origin shipping.UNLocode, destination shipping.UNLocode, deadline time.Time) (shipping.TrackingID, error)
+	// BookNewCargo registers a new cargo, shipped in the given equipment, in
+	// the tracking system, not yet routed. If duplicate detection is enabled
+	// and the customer booked the same route specification within the
+	// detection window, the booking is either flagged as a possible
+	// duplicate or blocked, in which case the tracking ID of the earlier
+	// cargo is returned along with ErrDuplicateBooking. If screening is
+	// enabled, bookings involving a sanctioned party or an embargoed
+	// destination are either flagged for review or refused with
+	// shipping.ErrScreeningBlocked.
+	BookNewCargo(ctx context.Context, customer shipping.CustomerID, origin shipping.UNLocode, destination shipping.UNLocode, deadline time.Time, equipment shipping.EquipmentType) (shipping.TrackingID, error)
+
+	// SaveDraft saves a booking request that may be missing its route
+	// specification, to be booked later, and returns the ID of the draft.
+	// Saving a draft with an ID replaces the draft. Drafts are not cargos,
+	// and are given no tracking ID until booked.
+	SaveDraft(ctx context.Context, d shipping.BookingDraft) (shipping.DraftID, error)
+
+	// Drafts returns the drafts saved for a customer, most recently saved
+	// first.
+	Drafts(ctx context.Context, customer shipping.CustomerID) []Draft
+
+	// BookDraft books the cargo of a complete draft, like BookNewCargo,
+	// and removes the draft. Incomplete drafts are refused with
+	// shipping.ErrIncompleteDraft.
+	BookDraft(ctx context.Context, id shipping.DraftID) (shipping.TrackingID, error)
+
+	// DiscardDraft removes a draft without booking it.
+	DiscardDraft(ctx context.Context, id shipping.DraftID) error
 
 	// LoadCargo returns a read model of a shipping.
-	LoadCargo(id shipping.TrackingID) (Cargo, error)
+	LoadCargo(ctx context.Context, id shipping.TrackingID) (Cargo, error)
+
+	// Timeline returns what has happened to a cargo, oldest first, merged
+	// from its handling history, the audit log, the changes found by
+	// inspecting it, the delays of the voyages it is routed on and the
+	// tasks raised about it. Sources that are not configured are left out.
+	Timeline(ctx context.Context, id shipping.TrackingID) ([]TimelineEntry, error)
 
 	// RequestPossibleRoutesForCargo requests a list of itineraries describing
-	// possible routes for this shipping.
-	RequestPossibleRoutesForCargo(id shipping.TrackingID) []shipping.Itinerary
+	// possible routes for this shipping. If the routing service does not
+	// respond within the latency budget, previously fetched candidates are
+	// returned and flagged as degraded. If service strings are given, only
+	// itineraries sailing exclusively on them are returned. Itineraries
+	// arriving after a hard arrival deadline are left out, while those
+	// arriving after a soft one are flagged as late.
+	RequestPossibleRoutesForCargo(ctx context.Context, id shipping.TrackingID, services ...shipping.ServiceCode) RouteCandidates
+
+	// CheckDeadlineFeasibility tells whether any route from origin to
+	// destination arrives by the deadline, before a cargo is booked. The
+	// earliest achievable arrival is reported either way.
+	CheckDeadlineFeasibility(ctx context.Context, origin, destination shipping.UNLocode, deadline time.Time) (Feasibility, error)
 
 	// AssignCargoToRoute assigns a cargo to the route specified by the
-	// itinerary.
-	AssignCargoToRoute(id shipping.TrackingID, itinerary shipping.Itinerary) error
+	// itinerary. Every voyage of the itinerary must have room left for the
+	// equipment of the cargo, and the itinerary must not arrive after a hard
+	// arrival deadline.
+	AssignCargoToRoute(ctx context.Context, id shipping.TrackingID, itinerary shipping.Itinerary) error
+
+	// ReplaceLeg substitutes a single leg of the itinerary of a cargo, such
+	// as a delayed voyage, without reassigning the whole route. The leg must
+	// not have been loaded yet, and the substitute must connect with the
+	// legs around it and arrive by the arrival deadline. Its booking awaits
+	// confirmation by the carrier.
+	ReplaceLeg(ctx context.Context, id shipping.TrackingID, index int, leg shipping.Leg) error
+
+	// RollCargo moves a cargo from the next voyage it is to be loaded onto,
+	// when full or delayed, to another sailing between the same ports. Later
+	// legs the cargo no longer makes in time are rebuilt on the next
+	// sailings of their service strings. The rollover is counted on the
+	// cargo for service level reporting, and the customer is notified of
+	// the new arrival.
+	RollCargo(ctx context.Context, id shipping.TrackingID, voyage shipping.VoyageNumber) error
+
+	// ChangeDestination changes the destination of a shipping. Once the
+	// cargo has been loaded, the change requires an approved amendment
+	// instead. The new destination is screened like a new booking, and the
+	// declaration of the cargo is checked against its import restrictions.
+	ChangeDestination(ctx context.Context, id shipping.TrackingID, destination shipping.UNLocode) error
+
+	// ChangeDeadlineType changes whether the arrival deadline of a cargo
+	// must be met or may be missed. A cargo routed to arrive after a hard
+	// deadline is misrouted.
+	ChangeDeadlineType(ctx context.Context, id shipping.TrackingID, t shipping.DeadlineType) error
+
+	// TransferCargo transfers a cargo sold in transit from the customer
+	// owning it to another customer, who is screened like a new booking.
+	// The cargo is tracked by the new owner from then on, and both
+	// customers are notified.
+	TransferCargo(ctx context.Context, id shipping.TrackingID, from, to shipping.CustomerID) error
+
+	// DeclareCargo declares what a cargo contains and the documents that
+	// come with it, replacing any previous declaration. A declaration
+	// violating the import restrictions of the destination is refused with
+	// the violations, as shipping.ImportViolations.
+	DeclareCargo(ctx context.Context, id shipping.TrackingID, d shipping.CargoDeclaration) error
 
-	// ChangeDestination changes the destination of a shipping.
-	ChangeDestination(id shipping.TrackingID, destination shipping.UNLocode) error
+	// RejectCargo marks a cargo delivered at its destination as rejected by
+	// the consignee and books a return cargo back to its origin, to arrive
+	// before the given deadline.
+	RejectCargo(ctx context.Context, id shipping.TrackingID, deadline time.Time) (shipping.TrackingID, error)
+
+	// IssueReleaseCode issues the release code of a cargo to its consignee,
+	// once the freight has been paid and the cargo has cleared customs, and
+	// returns its PIN. Claims of the cargo must present the PIN. Issuing
+	// the code again replaces the previous one, such as when it is lost or
+	// locked after too many wrong PINs.
+	IssueReleaseCode(ctx context.Context, id shipping.TrackingID) (string, error)
+
+	// OmitPortCall removes a port call the carrier skips from the schedule of
+	// a voyage. Cargos yet to be loaded or unloaded there are flagged as
+	// misrouted and have new route candidates requested, and their tracking
+	// IDs are returned.
+	OmitPortCall(ctx context.Context, voyage shipping.VoyageNumber, locode shipping.UNLocode) ([]shipping.TrackingID, error)
+
+	// RerouteAllVia reroutes the cargos to be loaded or unloaded at a
+	// disrupted port between two times, such as during a strike. Cargos yet
+	// to be loaded are assigned to the earliest arriving route avoiding the
+	// port in that window. The others, and those whose new route has no
+	// room for them, are left for an operator to review.
+	RerouteAllVia(ctx context.Context, locode shipping.UNLocode, from, to time.Time) (RerouteResult, error)
 
 	// Cargos returns a list of all cargos that have been booked.
-	Cargos() []Cargo
+	Cargos(ctx context.Context) []Cargo
+
+	// StreamCargos calls fn for every booked cargo, without holding all of
+	// them in memory. Streaming stops at the first error returned by fn.
+	StreamCargos(ctx context.Context, fn func(Cargo) error) error
+
+	// SearchCargos returns the booked cargos matching a filter expression,
+	// such as "status=IN_PORT AND destination=SESTO AND eta<2016-07-01". A
+	// malformed expression fails with a *shipping.CargoQueryError telling
+	// where and why.
+	SearchCargos(ctx context.Context, filter string) ([]Cargo, error)
 
 	// Locations returns a list of registered locations.
-	Locations() []Location
+	Locations(ctx context.Context) []Location
+
+	// RecalculateDelivery derives the delivery of a cargo anew from its
+	// complete handling history and current itinerary.
+	RecalculateDelivery(ctx context.Context, id shipping.TrackingID) (Cargo, error)
+
+	// RecalculateAllDeliveries recalculates the delivery of every booked
+	// cargo, reporting progress after each cargo has been processed.
+	RecalculateAllDeliveries(ctx context.Context, progress ProgressFunc) (RecalculationReport, error)
+
+	// EmissionsReport sums up the estimated emissions of the cargos routed to
+	// depart within a period.
+	EmissionsReport(ctx context.Context, from, to time.Time) (EmissionsReport, error)
+
+	// CapacityForecast reports the utilization of the voyages departing
+	// within a period, per equipment type, by the cargos routed on them.
+	// Voyages without any capacity declared are left out.
+	CapacityForecast(ctx context.Context, from, to time.Time) ([]VoyageUtilization, error)
+
+	// OverbookingReport returns the voyages departing within a period whose
+	// confirmed bookings exceed their physical capacity, so that cargo can
+	// be rolled to later voyages ahead of departure.
+	OverbookingReport(ctx context.Context, from, to time.Time) ([]OverbookedVoyage, error)
+}
+
+// RouteCandidates holds the itineraries returned when requesting possible
+// routes for a cargo.
+type RouteCandidates struct {
+	Itineraries []shipping.Itinerary `json:"routes"`
+
+	// Emissions holds the estimated emissions of each itinerary, in the same
+	// order, if known.
+	Emissions []*Emissions `json:"emissions,omitempty"`
+
+	// Performance holds how the lanes of each itinerary performed for the
+	// cargos that sailed them before, in the same order, if known.
+	Performance []*RoutePerformance `json:"performance,omitempty"`
+
+	// Late holds whether each itinerary arrives after the soft arrival
+	// deadline of the cargo, in the same order, if any does.
+	Late []bool `json:"late,omitempty"`
+
+	// Degraded is set if the routing service failed to respond in time and
+	// the itineraries were served from cache, if at all.
+	Degraded bool `json:"degraded"`
+}
+
+// RerouteResult is a read model of the cargos rerouted around a disrupted
+// port.
+type RerouteResult struct {
+	Location string    `json:"location"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+
+	// Rerouted holds the cargos assigned to a new route, NeedsReview those
+	// left for an operator to reroute and Infeasible those for which no
+	// route avoiding the port was found.
+	Rerouted    []ReroutedCargo `json:"rerouted"`
+	NeedsReview []ReroutedCargo `json:"needs_review"`
+	Infeasible  []ReroutedCargo `json:"infeasible"`
+}
+
+// ReroutedCargo is a read model of a cargo affected by a disrupted port.
+type ReroutedCargo struct {
+	TrackingID string `json:"tracking_id"`
+
+	// Legs holds the new route of a rerouted cargo, or the route proposed
+	// for one left for review.
+	Legs []shipping.Leg `json:"legs,omitempty"`
+
+	// Reason tells why the cargo was not rerouted.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Emissions is a read model of the estimated emissions of moving a tonne of
+// cargo along a route, in kilograms of CO2 equivalents.
+type Emissions struct {
+	Total float64   `json:"total"`
+	Legs  []float64 `json:"legs"`
+}
+
+// RoutePerformance is a read model of how the lanes of a route performed
+// historically, as a whole and for each leg.
+type RoutePerformance struct {
+	Total Performance   `json:"total"`
+	Legs  []Performance `json:"legs"`
+}
+
+// Performance is a read model of how the legs sailed on one or more lanes
+// turned out.
+type Performance struct {
+	Sailed             int     `json:"sailed"`
+	AverageDelayHours  float64 `json:"average_delay_hours"`
+	OnTimePercentage   float64 `json:"on_time_percentage"`
+	MisroutePercentage float64 `json:"misroute_percentage"`
+}
+
+// EmissionsReport sums up the estimated emissions of the cargos departing
+// within a period, in kilograms of CO2 equivalents per tonne of cargo.
+type EmissionsReport struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Cargos int       `json:"cargos"`
+	Total  float64   `json:"total"`
+
+	// Unknown lists the cargos whose emissions could not be estimated.
+	Unknown []string `json:"unknown,omitempty"`
+}
+
+// VoyageUtilization is a read model of the capacity booked on a voyage.
+type VoyageUtilization struct {
+	VoyageNumber string                 `json:"voyage_number"`
+	Departure    time.Time              `json:"departure"`
+	Equipment    []EquipmentUtilization `json:"equipment"`
+}
+
+// EquipmentUtilization is the capacity of a voyage booked for an equipment
+// type, in units. Limit is the capacity plus the overbooking allowed.
+type EquipmentUtilization struct {
+	Type     string  `json:"type"`
+	Capacity int     `json:"capacity"`
+	Limit    int     `json:"limit"`
+	Booked   int     `json:"booked"`
+	Percent  float64 `json:"percent"`
+}
+
+// OverbookedVoyage is a read model of a voyage whose confirmed bookings
+// exceed its physical capacity for some equipment types.
+type OverbookedVoyage struct {
+	VoyageNumber string                 `json:"voyage_number"`
+	Departure    time.Time              `json:"departure"`
+	Equipment    []EquipmentUtilization `json:"equipment"`
+
+	// Cargos lists the cargos confirmed on the voyage in the equipment
+	// types exceeded, which are the candidates for rolling.
+	Cargos []string `json:"cargos"`
+}
+
+// Feasibility is the outcome of checking whether a deadline can be made.
+type Feasibility struct {
+	Feasible bool `json:"feasible"`
+
+	// EarliestArrival is the arrival time of the fastest route, if any.
+	EarliestArrival *time.Time `json:"earliest_arrival,omitempty"`
+
+	// Degraded is set if the routing service failed to respond in time and
+	// the check is based on cached routes, if any.
+	Degraded bool `json:"degraded"`
+}
+
+// ProgressFunc is called by long-running operations after each processed
+// item.
+type ProgressFunc func(done, total int)
+
+// RecalculationReport summarizes a bulk recalculation of deliveries.
+type RecalculationReport struct {
+	Total        int      `json:"total"`
+	Recalculated int      `json:"recalculated"`
+	Failed       []string `json:"failed,omitempty"`
 }
 
 type service struct {
 	cargos         shipping.CargoRepository
 	locations      shipping.LocationRepository
+	voyages        shipping.VoyageRepository
 	handlingEvents shipping.HandlingEventRepository
 	routingService shipping.RoutingService
+	routingTimeout time.Duration
+	serviceStrings shipping.ServiceStringRepository
+	connections    shipping.ConnectionTimes
+	schedules      *time.Duration
+	handler        EventHandler
+	emissions      *shipping.EmissionsCalculator
+	lanes          shipping.LanePerformanceRepository
+	policy         shipping.DeliveryPolicy
+	calendars      shipping.WorkingCalendarRepository
+	screener       shipping.Screener
+	customers      shipping.CustomerRepository
+	restrictions   shipping.ImportRestrictions
+	overbooking    shipping.OverbookingPolicy
+	drafts         shipping.BookingDraftRepository
+	auditLog       shipping.AuditRepository
+	changes        shipping.CargoChangeRepository
+	tasks          shipping.TaskRepository
+	releaseCodes   shipping.ReleaseCodeRepository
+
+	// Repositories used by the query side, possibly backed by read
+	// replicas.
+	queryCargos         shipping.CargoRepository
+	queryHandlingEvents shipping.HandlingEventRepository
+
+	duplicateWindow time.Duration
+	duplicatePolicy DuplicatePolicy
+
+	mtx     sync.RWMutex
+	routes  map[routeKey][]shipping.Itinerary
+	fetches map[routeKey]*routeFetch
+	recent  map[bookingKey]recentBooking
+
+	now func() time.Time
+
+	// Decorators assembled around the service by NewService.
+	authorizer     Authorizer
+	logger         log.Logger
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+}
+
+// assemblyWorkers bounds the number of views being assembled concurrently.
+const assemblyWorkers = 8
+
+// routeKey identifies cached route candidates.
+type routeKey struct {
+	origin      shipping.UNLocode
+	destination shipping.UNLocode
+}
+
+// bookingKey identifies bookings considered duplicates of each other.
+type bookingKey struct {
+	customer    shipping.CustomerID
+	origin      shipping.UNLocode
+	destination shipping.UNLocode
+	deadline    time.Time
+	equipment   shipping.EquipmentType
 }
 
-func (s *service) AssignCargoToRoute(id shipping.TrackingID, itinerary shipping.Itinerary) error {
+// recentBooking is a booking made within the duplicate detection window.
+type recentBooking struct {
+	id     shipping.TrackingID
+	booked time.Time
+}
+
+// DuplicatePolicy decides what happens to a booking repeating a recent one.
+type DuplicatePolicy int
+
+// Duplicate policies.
+const (
+	// DuplicateWarn books the cargo but flags it as a possible duplicate.
+	DuplicateWarn DuplicatePolicy = iota
+
+	// DuplicateBlock refuses the booking.
+	DuplicateBlock
+)
+
+func (s *service) AssignCargoToRoute(ctx context.Context, id shipping.TrackingID, itinerary shipping.Itinerary) error {
 	if id == "" || len(itinerary.Legs) == 0 {
 		return ErrInvalidArgument
 	}
 
+	itinerary, err := s.schedule(itinerary)
+	if err != nil {
+		return err
+	}
+
+	if err := itinerary.ValidateConnections(s.connections); err != nil {
+		return err
+	}
+
 	c, err := s.cargos.Find(id)
 	if err != nil {
 		return err
 	}
 
-	c.AssignToRoute(itinerary)
+	// Consolidated cargos are routed with their master shipment.
+	if c.IsConsolidated() {
+		return shipping.ErrConsolidated
+	}
 
-	return s.cargos.Store(c)
+	if rs := c.RouteSpecification; rs.DeadlineType == shipping.HardDeadline && rs.IsLate(itinerary) {
+		return shipping.ErrArrivalDeadlineMissed
+	}
+
+	return s.assign(ctx, c, itinerary)
 }
 
-func (s *service) BookNewCargo(origin, destination shipping.UNLocode, deadline time.Time) (shipping.TrackingID, error) {
-	if origin == "" || destination == "" || deadline.IsZero() {
-		return "", ErrInvalidArgument
+// schedule validates the known times of the legs of an itinerary against
+// the schedules of their voyages, and fills in the unknown ones from them,
+// if configured to.
+func (s *service) schedule(itinerary shipping.Itinerary) (shipping.Itinerary, error) {
+	if s.schedules == nil || s.voyages == nil {
+		return itinerary, nil
 	}
+	if err := itinerary.ValidateSchedules(s.voyages, *s.schedules); err != nil {
+		return itinerary, err
+	}
+	return itinerary.Scheduled(s.voyages)
+}
 
-	id := shipping.NextTrackingID()
-	rs := shipping.RouteSpecification{
-		Origin:          origin,
-		Destination:     destination,
-		ArrivalDeadline: deadline,
+// scheduleLeg is schedule for a single leg.
+func (s *service) scheduleLeg(leg shipping.Leg) (shipping.Leg, error) {
+	i, err := s.schedule(shipping.Itinerary{Legs: []shipping.Leg{leg}})
+	if err != nil {
+		return leg, err
 	}
+	return i.Legs[0], nil
+}
 
-	c := shipping.NewCargo(id, rs)
+// assign assigns the cargo to a route, once the carriers have confirmed
+// the bookings of its legs, and notifies the event handler.
+func (s *service) assign(ctx context.Context, c *shipping.Cargo, itinerary shipping.Itinerary) error {
+	if err := s.checkCapacity(c, itinerary); err != nil {
+		return err
+	}
+
+	// The carriers have yet to confirm the bookings of the legs.
+	c.AssignToRoute(itinerary.PendingConfirmation())
 
 	if err := s.cargos.Store(c); err != nil {
-		return "", err
+		return err
 	}
 
-	return c.TrackingID, nil
+	if s.handler != nil {
+		s.handler.CargoWasRouted(ctx, c)
+	}
+
+	return nil
 }
 
-func (s *service) LoadCargo(id shipping.TrackingID) (Cargo, error) {
-	if id == "" {
-		return Cargo{}, ErrInvalidArgument
+func (s *service) ReplaceLeg(ctx context.Context, id shipping.TrackingID, index int, leg shipping.Leg) error {
+	if id == "" || index < 0 || leg.VoyageNumber == "" || leg.LoadLocation == "" || leg.UnloadLocation == "" {
+		return ErrInvalidArgument
 	}
 
 	c, err := s.cargos.Find(id)
 	if err != nil {
-		return Cargo{}, err
+		return err
 	}
 
-	return assemble(c, s.handlingEvents), nil
-}
+	// Consolidated cargos are routed with their master shipment.
+	if c.IsConsolidated() {
+		return shipping.ErrConsolidated
+	}
 
-func (s *service) ChangeDestination(id shipping.TrackingID, destination shipping.UNLocode) error {
-	if id == "" || destination == "" {
-		return ErrInvalidArgument
+	if leg, err = s.scheduleLeg(leg); err != nil {
+		return err
 	}
 
-	c, err := s.cargos.Find(id)
+	itinerary, err := c.Itinerary.ReplaceLeg(index, leg)
 	if err != nil {
 		return err
 	}
 
-	l, err := s.locations.Find(destination)
-	if err != nil {
+	old := c.Itinerary.Legs[index]
+	if !c.Delivery.IsScheduledAt(old.VoyageNumber, old.LoadLocation) {
+		return shipping.ErrLegHandled
+	}
+
+	if err := itinerary.ValidateConnections(s.connections); err != nil {
 		return err
 	}
 
-	c.SpecifyNewRoute(shipping.RouteSpecification{
-		Origin:          c.Origin,
-		Destination:     l.UNLocode,
-		ArrivalDeadline: c.RouteSpecification.ArrivalDeadline,
-	})
+	if rs := c.RouteSpecification; rs.DeadlineType == shipping.HardDeadline && rs.IsLate(itinerary) {
+		return shipping.ErrArrivalDeadlineMissed
+	}
+
+	if err := s.checkCapacity(c, shipping.Itinerary{Legs: []shipping.Leg{leg}}); err != nil {
+		return err
+	}
+
+	c.AssignToRoute(itinerary)
 
 	if err := s.cargos.Store(c); err != nil {
 		return err
 	}
 
+	if s.handler != nil {
+		s.handler.CargoWasRouted(ctx, c)
+	}
+
 	return nil
 }
 
-func (s *service) RequestPossibleRoutesForCargo(id shipping.TrackingID) []shipping.Itinerary {
-	if id == "" {
-		return nil
+func (s *service) RollCargo(ctx context.Context, id shipping.TrackingID, voyage shipping.VoyageNumber) error {
+	if id == "" || voyage == "" {
+		return ErrInvalidArgument
+	}
+
+	if s.voyages == nil {
+		return shipping.ErrUnknownVoyage
+	}
+
+	v, err := s.voyages.Find(voyage)
+	if err != nil {
+		return err
 	}
 
 	c, err := s.cargos.Find(id)
 	if err != nil {
-		return []shipping.Itinerary{}
+		return err
 	}
 
-	return s.routingService.FetchRoutesForSpecification(c.RouteSpecification)
-}
+	// Consolidated cargos are routed with their master shipment.
+	if c.IsConsolidated() {
+		return shipping.ErrConsolidated
+	}
 
-func (s *service) Cargos() []Cargo {
-	var result []Cargo
-	for _, c := range s.cargos.FindAll() {
-		result = append(result, assemble(c, s.handlingEvents))
+	k, ok := c.Delivery.NextLeg()
+	if !ok {
+		return shipping.ErrNothingToRoll
 	}
-	return result
+	from := c.Itinerary.Legs[k].VoyageNumber
+
+	// Roll a copy, so that the cargo is left untouched if the new legs
+	// have no room for it.
+	rolled := *c
+	if err := rolled.Roll(v, s.connections, s.nextSailing); err != nil {
+		return err
+	}
+
+	if rs := rolled.RouteSpecification; rs.DeadlineType == shipping.HardDeadline && rs.IsLate(rolled.Itinerary) {
+		return shipping.ErrArrivalDeadlineMissed
+	}
+
+	if err := s.checkCapacity(&rolled, shipping.Itinerary{Legs: rolled.Itinerary.Legs[k:]}); err != nil {
+		return err
+	}
+
+	if err := s.cargos.Store(&rolled); err != nil {
+		return err
+	}
+
+	if s.handler != nil {
+		s.handler.CargoWasRolled(ctx, &rolled, from)
+	}
+
+	return nil
 }
 
-func (s *service) Locations() []Location {
-	var result []Location
-	for _, v := range s.locations.FindAll() {
-		result = append(result, Location{
-			UNLocode: string(v.UNLocode),
-			Name:     v.Name,
-		})
+// nextSailing returns the leg between the same ports as l on the earliest
+// voyage of its service string that loads no sooner than a time.
+func (s *service) nextSailing(l shipping.Leg, after time.Time) (shipping.Leg, error) {
+	ss, ok := s.allServiceStrings().Find(l.VoyageNumber)
+	if !ok {
+		return l, shipping.ErrNoLaterSailing
 	}
-	return result
+
+	var (
+		result shipping.Leg
+		found  bool
+	)
+	for _, n := range ss.Voyages {
+		v, err := s.voyages.Find(n)
+		if err != nil {
+			continue
+		}
+		leg, err := v.Leg(l.LoadLocation, l.UnloadLocation)
+		if err != nil || leg.LoadTime.Before(after) {
+			continue
+		}
+		if !found || leg.LoadTime.Before(result.LoadTime) {
+			result, found = leg, true
+		}
+	}
+
+	if !found {
+		return l, shipping.ErrNoLaterSailing
+	}
+	return result, nil
 }
 
-// NewService creates a booking service with necessary dependencies.
-func NewService(cargos shipping.CargoRepository, locations shipping.LocationRepository, events shipping.HandlingEventRepository, rs shipping.RoutingService) Service {
-	return &service{
-		cargos:         cargos,
-		locations:      locations,
-		handlingEvents: events,
-		routingService: rs,
+func (s *service) BookNewCargo(ctx context.Context, customer shipping.CustomerID, origin, destination shipping.UNLocode, deadline time.Time, equipment shipping.EquipmentType) (shipping.TrackingID, error) {
+	if origin == "" || destination == "" || deadline.IsZero() || equipment.String() == "" {
+		return "", ErrInvalidArgument
+	}
+
+	id := shipping.NextTrackingID()
+	rs := shipping.RouteSpecification{
+		Origin:          origin,
+		Destination:     destination,
+		ArrivalDeadline: deadline,
+	}
+
+	c := shipping.NewCargo(id, rs)
+	c.Customer = customer
+	c.Equipment = equipment
+
+	if err := s.screen(ctx, c); err != nil {
+		return "", err
+	}
+
+	key := bookingKey{customer, origin, destination, deadline.UTC(), equipment}
+	if s.duplicateWindow > 0 {
+		if dup, ok := s.checkDuplicate(key, id, s.now()); ok {
+			if s.duplicatePolicy == DuplicateBlock {
+				return dup, ErrDuplicateBooking
+			}
+			c.DuplicateOf = dup
+		}
+	}
+
+	if err := s.cargos.Store(c); err != nil {
+		s.forgetBooking(key, id)
+		return "", err
 	}
+
+	if s.handler != nil {
+		s.handler.CargoWasBooked(ctx, c)
+	}
+
+	return c.TrackingID, nil
 }
 
-// Location is a read model for booking views.
-type Location struct {
-	UNLocode string `json:"locode"`
-	Name     string `json:"name"`
+func (s *service) SaveDraft(ctx context.Context, d shipping.BookingDraft) (shipping.DraftID, error) {
+	if s.drafts == nil || d.Equipment.String() == "" {
+		return "", ErrInvalidArgument
+	}
+
+	if d.ID == "" {
+		d.ID = shipping.NextDraftID()
+	} else if _, err := s.drafts.Find(d.ID); err != nil {
+		return "", err
+	}
+
+	d.Saved = s.now()
+
+	if err := s.drafts.Store(&d); err != nil {
+		return "", err
+	}
+
+	return d.ID, nil
 }
 
-// Cargo is a read model for booking views.
-type Cargo struct {
-	ArrivalDeadline time.Time      `json:"arrival_deadline"`
-	Destination     string         `json:"destination"`
-	Legs            []shipping.Leg `json:"legs,omitempty"`
-	Misrouted       bool           `json:"misrouted"`
-	Origin          string         `json:"origin"`
-	Routed          bool           `json:"routed"`
-	TrackingID      string         `json:"tracking_id"`
+func (s *service) Drafts(ctx context.Context, customer shipping.CustomerID) []Draft {
+	result := make([]Draft, 0)
+	if s.drafts == nil {
+		return result
+	}
+	for _, d := range s.drafts.FindByCustomer(customer) {
+		result = append(result, assembleDraft(d))
+	}
+	return result
 }
 
-func assemble(c *shipping.Cargo, events shipping.HandlingEventRepository) Cargo {
-	return Cargo{
-		TrackingID:      string(c.TrackingID),
-		Origin:          string(c.Origin),
-		Destination:     string(c.RouteSpecification.Destination),
-		Misrouted:       c.Delivery.RoutingStatus == shipping.Misrouted,
-		Routed:          !c.Itinerary.IsEmpty(),
-		ArrivalDeadline: c.RouteSpecification.ArrivalDeadline,
-		Legs:            c.Itinerary.Legs,
+func (s *service) BookDraft(ctx context.Context, id shipping.DraftID) (shipping.TrackingID, error) {
+	if id == "" {
+		return "", ErrInvalidArgument
+	}
+	if s.drafts == nil {
+		return "", shipping.ErrUnknownDraft
 	}
+
+	d, err := s.drafts.Find(id)
+	if err != nil {
+		return "", err
+	}
+
+	if !d.IsComplete() {
+		return "", shipping.ErrIncompleteDraft
+	}
+
+	trackingID, err := s.BookNewCargo(ctx, d.Customer, d.Origin, d.Destination, d.ArrivalDeadline, d.Equipment)
+	if err != nil {
+		return trackingID, err
+	}
+
+	return trackingID, s.drafts.Remove(id)
+}
+
+func (s *service) DiscardDraft(ctx context.Context, id shipping.DraftID) error {
+	if id == "" {
+		return ErrInvalidArgument
+	}
+	if s.drafts == nil {
+		return shipping.ErrUnknownDraft
+	}
+	return s.drafts.Remove(id)
+}
+
+// screen screens the customer and route of a cargo, if a screener is set.
+// Blocked cargos are refused, and flagged cargos are marked for review. If
+// the screener fails, the cargo is flagged rather than refused.
+func (s *service) screen(ctx context.Context, c *shipping.Cargo) error {
+	if s.screener == nil {
+		return nil
+	}
+
+	r := shipping.ScreeningRequest{
+		TrackingID: c.TrackingID,
+		Customer:   c.Customer,
+		Locations:  []shipping.UNLocode{c.RouteSpecification.Origin, c.RouteSpecification.Destination},
+	}
+	if c.Customer != "" && s.customers != nil {
+		if cust, err := s.customers.Find(c.Customer); err == nil {
+			r.CustomerName = cust.Name
+		}
+	}
+
+	result, err := s.screener.Screen(ctx, r)
+	if err != nil {
+		result = shipping.ScreeningResult{
+			Decision: shipping.ScreeningFlagged,
+			Reasons:  []string{"screening failed: " + err.Error()},
+		}
+	}
+
+	if result.IsBlocked() {
+		return shipping.ErrScreeningBlocked
+	}
+
+	c.Screening = shipping.ScreeningResult{}
+	if result.IsFlagged() {
+		c.Screening = result
+	}
+
+	return nil
+}
+
+// checkDuplicate returns the cargo booked under the same key within the
+// duplicate detection window, if any. Otherwise the booking is remembered
+// under the key.
+func (s *service) checkDuplicate(key bookingKey, id shipping.TrackingID, now time.Time) (shipping.TrackingID, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for k, b := range s.recent {
+		if now.Sub(b.booked) > s.duplicateWindow {
+			delete(s.recent, k)
+		}
+	}
+
+	if b, ok := s.recent[key]; ok {
+		return b.id, true
+	}
+
+	s.recent[key] = recentBooking{id: id, booked: now}
+
+	return "", false
+}
+
+// forgetBooking drops the booking remembered under the key, if it is the
+// cargo with the given id, so that a booking that could not be stored does
+// not count as a duplicate.
+func (s *service) forgetBooking(key bookingKey, id shipping.TrackingID) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if b, ok := s.recent[key]; ok && b.id == id {
+		delete(s.recent, key)
+	}
+}
+
+func (s *service) LoadCargo(ctx context.Context, id shipping.TrackingID) (Cargo, error) {
+	if id == "" {
+		return Cargo{}, ErrInvalidArgument
+	}
+
+	c, err := s.queryCargos.Find(id)
+	if err != nil {
+		return Cargo{}, err
+	}
+
+	return s.assembleCargo(c), nil
+}
+
+func (s *service) Timeline(ctx context.Context, id shipping.TrackingID) ([]TimelineEntry, error) {
+	if id == "" {
+		return nil, ErrInvalidArgument
+	}
+
+	c, err := s.queryCargos.Find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TimelineEntry, 0)
+
+	for _, e := range s.queryHandlingEvents.QueryHandlingHistory(id).HandlingEvents {
+		result = append(result, TimelineEntry{
+			Time:         e.Completed,
+			Source:       "handling",
+			Type:         e.Activity.Type.String(),
+			Location:     string(e.Activity.Location),
+			VoyageNumber: string(e.Activity.VoyageNumber),
+		})
+	}
+
+	if s.auditLog != nil {
+		for _, e := range s.auditLog.Find(shipping.AuditQuery{TrackingID: id}) {
+			// Handling events are taken from the handling history, which
+			// tells when they were completed rather than registered.
+			if e.Action == shipping.AuditHandlingEventRegistered {
+				continue
+			}
+			result = append(result, TimelineEntry{
+				Time:        e.Time,
+				Source:      "audit",
+				Type:        string(e.Action),
+				Actor:       e.Actor,
+				Description: e.Detail,
+			})
+		}
+	}
+
+	if s.changes != nil {
+		for _, ch := range s.changes.FindByCargo(id) {
+			// Booking, routing and handling are already on the timeline,
+			// from the audit log and the handling history.
+			if ch.Type == shipping.CargoBookedChange || ch.Type == shipping.CargoRoutedChange || ch.Type == shipping.CargoHandledChange {
+				continue
+			}
+			entry := TimelineEntry{
+				Time:         ch.Time,
+				Source:       "inspection",
+				Type:         string(ch.Type),
+				Location:     string(ch.Location),
+				VoyageNumber: string(ch.VoyageNumber),
+			}
+			if ch.Slip > 0 {
+				entry.Description = "projected to arrive " + ch.Slip.String() + " late"
+			}
+			result = append(result, entry)
+		}
+	}
+
+	if s.voyages != nil {
+		result = append(result, s.voyageDelays(c.Itinerary)...)
+	}
+
+	if s.tasks != nil {
+		for _, t := range s.tasks.FindByCargo(id) {
+			result = append(result, TimelineEntry{
+				Time:        t.Opened,
+				Source:      "task",
+				Type:        t.Kind.String(),
+				Actor:       string(t.Assignee),
+				Description: t.Description,
+			})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+
+	return result, nil
+}
+
+// voyageDelays returns timeline entries for the late departures and
+// arrivals recorded for the port calls an itinerary loads and unloads at.
+func (s *service) voyageDelays(itinerary shipping.Itinerary) []TimelineEntry {
+	var result []TimelineEntry
+	for _, leg := range itinerary.Legs {
+		v, err := s.voyages.Find(leg.VoyageNumber)
+		if err != nil {
+			continue
+		}
+
+		if pc, ok := closestPortCall(v.Schedule, leg.LoadLocation, leg.LoadTime); ok && pc.DepartureDelay() > 0 {
+			result = append(result, TimelineEntry{
+				Time:         pc.ActualDepartureTime,
+				Source:       "voyage",
+				Type:         "departure_delayed",
+				Location:     string(leg.LoadLocation),
+				VoyageNumber: string(leg.VoyageNumber),
+				Description:  "departed " + pc.DepartureDelay().String() + " late",
+			})
+		}
+		if pc, ok := closestPortCall(v.Schedule, leg.UnloadLocation, leg.UnloadTime); ok && pc.ArrivalDelay() > 0 {
+			result = append(result, TimelineEntry{
+				Time:         pc.ActualArrivalTime,
+				Source:       "voyage",
+				Type:         "arrival_delayed",
+				Location:     string(leg.UnloadLocation),
+				VoyageNumber: string(leg.VoyageNumber),
+				Description:  "arrived " + pc.ArrivalDelay().String() + " late",
+			})
+		}
+	}
+	return result
+}
+
+// closestPortCall returns the port call of a schedule at a location that is
+// scheduled closest to the given time, as voyages may call at a location
+// more than once.
+func closestPortCall(s shipping.Schedule, locode shipping.UNLocode, t time.Time) (shipping.PortCall, bool) {
+	var (
+		result shipping.PortCall
+		found  bool
+		best   float64
+	)
+	for _, pc := range s.PortCalls() {
+		if pc.Location != locode {
+			continue
+		}
+		for _, scheduled := range []time.Time{pc.ArrivalTime, pc.DepartureTime} {
+			if scheduled.IsZero() {
+				continue
+			}
+			if d := math.Abs(float64(scheduled.Sub(t))); !found || d < best {
+				result, found, best = pc, true, d
+			}
+		}
+	}
+	return result, found
+}
+
+func (s *service) ChangeDestination(ctx context.Context, id shipping.TrackingID, destination shipping.UNLocode) error {
+	if id == "" || destination == "" {
+		return ErrInvalidArgument
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return err
+	}
+
+	if c.IsConsolidated() || c.IsMaster() {
+		return shipping.ErrConsolidated
+	}
+
+	if c.HasBeenLoaded() {
+		return shipping.ErrApprovalRequired
+	}
+
+	l, err := s.locations.Find(destination)
+	if err != nil {
+		return err
+	}
+
+	rs := c.RouteSpecification
+	rs.Origin = c.Origin
+	rs.Destination = l.UNLocode
+	c.SpecifyNewRoute(rs)
+
+	if err := s.screen(ctx, c); err != nil {
+		return err
+	}
+
+	if err := s.restrictions.Check(l.UNLocode, c.Declaration); err != nil {
+		return err
+	}
+
+	if err := s.cargos.Store(c); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) ChangeDeadlineType(ctx context.Context, id shipping.TrackingID, t shipping.DeadlineType) error {
+	if id == "" || t.String() == "" {
+		return ErrInvalidArgument
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return err
+	}
+
+	rs := c.RouteSpecification
+	rs.DeadlineType = t
+	c.SpecifyNewRoute(rs)
+
+	return s.cargos.Store(c)
+}
+
+func (s *service) TransferCargo(ctx context.Context, id shipping.TrackingID, from, to shipping.CustomerID) error {
+	if id == "" || from == "" || to == "" || from == to {
+		return ErrInvalidArgument
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return err
+	}
+
+	if c.Customer != from {
+		return shipping.ErrNotCargoOwner
+	}
+
+	if s.customers == nil {
+		return shipping.ErrUnknownCustomer
+	}
+	if _, err := s.customers.Find(to); err != nil {
+		return err
+	}
+
+	// Transfer a copy, so that the cargo is left untouched if the new owner
+	// is blocked.
+	transferred := *c
+	transferred.Customer = to
+
+	if err := s.screen(ctx, &transferred); err != nil {
+		return err
+	}
+
+	if err := s.cargos.Store(&transferred); err != nil {
+		return err
+	}
+
+	if s.handler != nil {
+		s.handler.CargoWasTransferred(ctx, &transferred, from)
+	}
+
+	return nil
+}
+
+func (s *service) DeclareCargo(ctx context.Context, id shipping.TrackingID, d shipping.CargoDeclaration) error {
+	if id == "" {
+		return ErrInvalidArgument
+	}
+	for _, cc := range d.Commodities {
+		if cc == "" {
+			return ErrInvalidArgument
+		}
+	}
+	for _, doc := range d.Documents {
+		if doc == "" {
+			return ErrInvalidArgument
+		}
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.restrictions.Check(c.RouteSpecification.Destination, d); err != nil {
+		return err
+	}
+
+	c.Declaration = d
+
+	return s.cargos.Store(c)
+}
+
+func (s *service) RejectCargo(ctx context.Context, id shipping.TrackingID, deadline time.Time) (shipping.TrackingID, error) {
+	if id == "" || deadline.IsZero() {
+		return "", ErrInvalidArgument
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return "", err
+	}
+
+	r, err := c.Reject(shipping.NextTrackingID(), deadline)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.cargos.Store(r); err != nil {
+		return "", err
+	}
+
+	if err := s.cargos.Store(c); err != nil {
+		return "", err
+	}
+
+	return r.TrackingID, nil
+}
+
+func (s *service) IssueReleaseCode(ctx context.Context, id shipping.TrackingID) (string, error) {
+	if id == "" || s.releaseCodes == nil {
+		return "", ErrInvalidArgument
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return "", err
+	}
+
+	if c.Delivery.TransportStatus == shipping.Claimed {
+		return "", shipping.ErrAlreadyClaimed
+	}
+
+	rc, pin, err := shipping.NewReleaseCode(id, s.now())
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.releaseCodes.Store(rc); err != nil {
+		return "", err
+	}
+
+	return pin, nil
+}
+
+func (s *service) RequestPossibleRoutesForCargo(ctx context.Context, id shipping.TrackingID, services ...shipping.ServiceCode) RouteCandidates {
+	if id == "" {
+		return RouteCandidates{}
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return RouteCandidates{Itineraries: []shipping.Itinerary{}}
+	}
+
+	rc := s.fetchRoutes(ctx, c.RouteSpecification)
+	if len(services) > 0 {
+		rc.Itineraries = permitted(rc.Itineraries, s.findServiceStrings(services))
+	}
+	rc.Itineraries, rc.Late = punctual(rc.Itineraries, c.RouteSpecification)
+
+	if s.emissions != nil {
+		rc.Emissions = make([]*Emissions, len(rc.Itineraries))
+		for i, it := range rc.Itineraries {
+			rc.Emissions[i] = s.estimate(it)
+		}
+	}
+
+	if s.lanes != nil && len(rc.Itineraries) > 0 {
+		services := s.allServiceStrings()
+		lanes := s.lanes.FindAll()
+		rc.Performance = make([]*RoutePerformance, len(rc.Itineraries))
+		for i, it := range rc.Itineraries {
+			rc.Performance[i] = routePerformance(it, lanes, services)
+		}
+	}
+
+	return rc
+}
+
+// punctual returns the itineraries that do not arrive after a hard arrival
+// deadline, and whether each arrives after a soft one, or nil if none does.
+func punctual(itineraries []shipping.Itinerary, rs shipping.RouteSpecification) ([]shipping.Itinerary, []bool) {
+	var (
+		result  = make([]shipping.Itinerary, 0, len(itineraries))
+		late    = make([]bool, 0, len(itineraries))
+		anyLate bool
+	)
+	for _, it := range itineraries {
+		l := rs.IsLate(it)
+		if l && rs.DeadlineType == shipping.HardDeadline {
+			continue
+		}
+		result = append(result, it)
+		late = append(late, l)
+		anyLate = anyLate || l
+	}
+
+	if !anyLate {
+		late = nil
+	}
+	return result, late
+}
+
+// routePerformance returns how the lanes of an itinerary performed, or nil
+// if none of them has been sailed before.
+func routePerformance(i shipping.Itinerary, lanes map[shipping.Lane]shipping.LanePerformance, services shipping.ServiceStrings) *RoutePerformance {
+	var (
+		total shipping.LanePerformance
+		legs  = make([]Performance, len(i.Legs))
+	)
+	for k, l := range i.Legs {
+		if p, ok := lanes[services.Lane(l)]; ok {
+			total.Merge(p)
+			legs[k] = assemblePerformance(p)
+		}
+	}
+
+	if total.Sailed == 0 {
+		return nil
+	}
+
+	return &RoutePerformance{Total: assemblePerformance(total), Legs: legs}
+}
+
+func assemblePerformance(p shipping.LanePerformance) Performance {
+	return Performance{
+		Sailed:             p.Sailed,
+		AverageDelayHours:  p.AverageDelay().Hours(),
+		OnTimePercentage:   100 * p.OnTimeRate(),
+		MisroutePercentage: 100 * p.MisrouteRate(),
+	}
+}
+
+func (s *service) OmitPortCall(ctx context.Context, voyage shipping.VoyageNumber, locode shipping.UNLocode) ([]shipping.TrackingID, error) {
+	if voyage == "" || locode == "" {
+		return nil, ErrInvalidArgument
+	}
+
+	if s.voyages == nil {
+		return nil, shipping.ErrUnknownVoyage
+	}
+
+	v, err := s.voyages.Find(voyage)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := v.Schedule.OmitPortCall(locode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Leave the voyage as found untouched, in case it is shared.
+	omitted := *v
+	omitted.Schedule = schedule
+
+	if err := s.voyages.Store(&omitted); err != nil {
+		return nil, err
+	}
+
+	s.forgetRoutes(voyage, locode)
+
+	var affected []*shipping.Cargo
+	if err := s.cargos.ForEach(func(c *shipping.Cargo) error {
+		if c.Delivery.IsScheduledAt(voyage, locode) {
+			affected = append(affected, c)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var ids []shipping.TrackingID
+	for _, c := range affected {
+		c.DisruptRoute()
+
+		if err := s.cargos.Store(c); err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, c.TrackingID)
+	}
+
+	// Have new route candidates ready for the operators. Consolidated
+	// cargos are rerouted with their master shipment.
+	requested := make(map[routeKey]bool)
+	for _, c := range affected {
+		key := routeKey{c.RouteSpecification.Origin, c.RouteSpecification.Destination}
+		if c.IsConsolidated() || requested[key] {
+			continue
+		}
+		requested[key] = true
+
+		s.fetchRoutes(ctx, c.RouteSpecification)
+	}
+
+	return ids, nil
+}
+
+func (s *service) RerouteAllVia(ctx context.Context, locode shipping.UNLocode, from, to time.Time) (RerouteResult, error) {
+	if locode == "" || from.IsZero() || to.Before(from) {
+		return RerouteResult{}, ErrInvalidArgument
+	}
+
+	if _, err := s.locations.Find(locode); err != nil {
+		return RerouteResult{}, err
+	}
+
+	now := s.now()
+
+	var affected []*shipping.Cargo
+	if err := s.cargos.ForEach(func(c *shipping.Cargo) error {
+		// Only the legs the cargo has yet to be unloaded from matter.
+		completed := c.Delivery.Progress(now).CompletedLegs
+		remaining := shipping.Itinerary{Legs: c.Itinerary.Legs[completed:]}
+		if remaining.CallsAtDuring(locode, from, to) {
+			affected = append(affected, c)
+		}
+		return nil
+	}); err != nil {
+		return RerouteResult{}, err
+	}
+
+	result := RerouteResult{
+		Location:    string(locode),
+		From:        from,
+		To:          to,
+		Rerouted:    []ReroutedCargo{},
+		NeedsReview: []ReroutedCargo{},
+		Infeasible:  []ReroutedCargo{},
+	}
+
+	for _, c := range affected {
+		rc := ReroutedCargo{TrackingID: string(c.TrackingID)}
+
+		if c.IsConsolidated() {
+			rc.Reason = "routed with master shipment " + string(c.MasterID)
+			result.NeedsReview = append(result.NeedsReview, rc)
+			continue
+		}
+		if k, ok := c.Delivery.NextLeg(); !ok || k > 0 {
+			rc.Reason = "in transit"
+			result.NeedsReview = append(result.NeedsReview, rc)
+			continue
+		}
+
+		itinerary, ok := s.alternative(ctx, c.RouteSpecification, locode, from, to)
+		if !ok {
+			rc.Reason = "no route avoiding " + string(locode)
+			result.Infeasible = append(result.Infeasible, rc)
+			continue
+		}
+
+		if err := s.assign(ctx, c, itinerary); err == shipping.ErrCapacityExceeded {
+			rc.Reason = err.Error()
+			rc.Legs = itinerary.Legs
+			result.NeedsReview = append(result.NeedsReview, rc)
+			continue
+		} else if err != nil {
+			return result, err
+		}
+
+		rc.Legs = c.Itinerary.Legs
+		result.Rerouted = append(result.Rerouted, rc)
+	}
+
+	return result, nil
+}
+
+// alternative returns the earliest arriving route satisfying the route
+// specification, by its arrival deadline, without loading or unloading at a
+// location between two times.
+func (s *service) alternative(ctx context.Context, spec shipping.RouteSpecification, locode shipping.UNLocode, from, to time.Time) (shipping.Itinerary, bool) {
+	var (
+		result shipping.Itinerary
+		found  bool
+	)
+	for _, it := range s.fetchRoutes(ctx, spec).Itineraries {
+		if !spec.IsSatisfiedBy(it) || it.CallsAtDuring(locode, from, to) || it.ValidateConnections(s.connections) != nil {
+			continue
+		}
+		if !spec.ArrivalDeadline.IsZero() && it.FinalArrivalTime().After(spec.ArrivalDeadline) {
+			continue
+		}
+		if !found || it.FinalArrivalTime().Before(result.FinalArrivalTime()) {
+			result, found = it, true
+		}
+	}
+	return result, found
+}
+
+// forgetRoutes drops cached route candidates that use a port call.
+func (s *service) forgetRoutes(voyage shipping.VoyageNumber, locode shipping.UNLocode) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for key, itineraries := range s.routes {
+		var kept []shipping.Itinerary
+		for _, it := range itineraries {
+			if !it.UsesPortCall(voyage, locode) {
+				kept = append(kept, it)
+			}
+		}
+		s.routes[key] = kept
+	}
+}
+
+// routeFetch is a request to the routing service in flight, shared by the
+// callers asking for routes between the same locations.
+type routeFetch struct {
+	done        chan struct{}
+	itineraries []shipping.Itinerary
+}
+
+// fetchRoutes requests routes from the routing service within the latency
+// budget, falling back to cached routes. At most one request per origin and
+// destination is in flight at a time.
+func (s *service) fetchRoutes(ctx context.Context, spec shipping.RouteSpecification) RouteCandidates {
+	if s.routingTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.routingTimeout)
+		defer cancel()
+	}
+
+	key := routeKey{spec.Origin, spec.Destination}
+
+	s.mtx.Lock()
+	f, ok := s.fetches[key]
+	if !ok {
+		f = &routeFetch{done: make(chan struct{})}
+		s.fetches[key] = f
+
+		// The fetch outlives a caller that gives up, so that a late
+		// response may still be cached.
+		go func(rs shipping.RouteSpecification) {
+			itineraries := s.routingService.FetchRoutesForSpecification(rs)
+
+			s.mtx.Lock()
+			s.routes[key] = itineraries
+			delete(s.fetches, key)
+			f.itineraries = itineraries
+			s.mtx.Unlock()
+
+			close(f.done)
+		}(spec)
+	}
+	s.mtx.Unlock()
+
+	select {
+	case <-f.done:
+		return RouteCandidates{Itineraries: nonNil(f.itineraries)}
+	case <-ctx.Done():
+		s.mtx.RLock()
+		defer s.mtx.RUnlock()
+		return RouteCandidates{Itineraries: nonNil(s.routes[key]), Degraded: true}
+	}
+}
+
+// nonNil returns the itineraries, or an empty slice if there are none.
+func nonNil(itineraries []shipping.Itinerary) []shipping.Itinerary {
+	if itineraries == nil {
+		return []shipping.Itinerary{}
+	}
+	return itineraries
+}
+
+func (s *service) CheckDeadlineFeasibility(ctx context.Context, origin, destination shipping.UNLocode, deadline time.Time) (Feasibility, error) {
+	if origin == "" || destination == "" || deadline.IsZero() {
+		return Feasibility{}, ErrInvalidArgument
+	}
+
+	for _, l := range []shipping.UNLocode{origin, destination} {
+		if _, err := s.locations.Find(l); err != nil {
+			return Feasibility{}, err
+		}
+	}
+
+	rc := s.fetchRoutes(ctx, shipping.RouteSpecification{
+		Origin:          origin,
+		Destination:     destination,
+		ArrivalDeadline: deadline,
+	})
+
+	f := Feasibility{Degraded: rc.Degraded}
+	for _, i := range rc.Itineraries {
+		if i.IsEmpty() {
+			continue
+		}
+		arrival := i.FinalArrivalTime()
+		if f.EarliestArrival == nil || arrival.Before(*f.EarliestArrival) {
+			f.EarliestArrival = &arrival
+		}
+	}
+
+	f.Feasible = f.EarliestArrival != nil && !f.EarliestArrival.After(deadline)
+
+	return f, nil
+}
+
+func (s *service) Cargos(ctx context.Context) []Cargo {
+	return s.assembleCargos(s.queryCargos.FindAll())
+}
+
+func (s *service) SearchCargos(ctx context.Context, filter string) ([]Cargo, error) {
+	q, err := shipping.ParseCargoQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	cargos, err := s.queryCargos.FindMatching(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.assembleCargos(cargos), nil
+}
+
+// assembleCargos assembles the read models of cargos concurrently.
+func (s *service) assembleCargos(cargos []*shipping.Cargo) []Cargo {
+	if len(cargos) == 0 {
+		return nil
+	}
+
+	result := make([]Cargo, len(cargos))
+
+	workers := assemblyWorkers
+	if len(cargos) < workers {
+		workers = len(cargos)
+	}
+
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result[i] = s.assembleCargo(cargos[i])
+			}
+		}()
+	}
+
+	for i := range cargos {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	return result
+}
+
+func (s *service) StreamCargos(ctx context.Context, fn func(Cargo) error) error {
+	return s.queryCargos.ForEach(func(c *shipping.Cargo) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(s.assembleCargo(c))
+	})
+}
+
+func (s *service) Locations(ctx context.Context) []Location {
+	var result []Location
+	for _, v := range s.locations.FindAll() {
+		result = append(result, Location{
+			UNLocode: string(v.UNLocode),
+			Name:     v.Name,
+		})
+	}
+	return result
+}
+
+func (s *service) RecalculateDelivery(ctx context.Context, id shipping.TrackingID) (Cargo, error) {
+	if id == "" {
+		return Cargo{}, ErrInvalidArgument
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return Cargo{}, err
+	}
+
+	h := s.handlingEvents.QueryHandlingHistory(id)
+
+	c.DeriveDeliveryProgressWith(s.policy, h)
+
+	if err := s.cargos.Store(c); err != nil {
+		return Cargo{}, err
+	}
+
+	return s.assembleCargo(c), nil
+}
+
+func (s *service) RecalculateAllDeliveries(ctx context.Context, progress ProgressFunc) (RecalculationReport, error) {
+	cargos := s.cargos.FindAll()
+
+	report := RecalculationReport{Total: len(cargos)}
+
+	for i, c := range cargos {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		if err := s.recalculate(c); err != nil {
+			report.Failed = append(report.Failed, string(c.TrackingID))
+		} else {
+			report.Recalculated++
+		}
+
+		if progress != nil {
+			progress(i+1, len(cargos))
+		}
+	}
+
+	return report, nil
+}
+
+func (s *service) recalculate(c *shipping.Cargo) error {
+	c.DeriveDeliveryProgressWith(s.policy, s.handlingEvents.QueryHandlingHistory(c.TrackingID))
+	return s.cargos.Store(c)
+}
+
+// Option configures the booking service.
+type Option func(*service)
+
+// WithRoutingTimeout sets the latency budget for requesting possible routes.
+// A zero duration means that the routing service is waited for indefinitely,
+// unless the request context says otherwise.
+func WithRoutingTimeout(d time.Duration) Option {
+	return func(s *service) {
+		s.routingTimeout = d
+	}
+}
+
+// WithDuplicateDetection detects a customer booking the same route
+// specification twice within the window, likely an accidental double
+// submission, and handles the repeated booking according to the policy.
+// Recent bookings are remembered by each service instance only.
+func WithDuplicateDetection(window time.Duration, p DuplicatePolicy) Option {
+	return func(s *service) {
+		s.duplicateWindow = window
+		s.duplicatePolicy = p
+	}
+}
+
+// WithEmissions estimates the emissions of route candidates and routed
+// cargos using the given calculator.
+func WithEmissions(c shipping.EmissionsCalculator) Option {
+	return func(s *service) {
+		s.emissions = &c
+	}
+}
+
+// WithRoutePerformance annotates route candidates with how their lanes
+// performed for the cargos that sailed them before: their average delay,
+// and how often they were on time or misrouted. Lanes are told apart by
+// service string, so that later voyages of a service are judged by the
+// earlier ones. The performance is read from the read model kept by the
+// lane performance projection.
+func WithRoutePerformance(lanes shipping.LanePerformanceRepository) Option {
+	return func(s *service) {
+		s.lanes = lanes
+	}
+}
+
+// WithDeliveryPolicy sets the policy the handling of cargos is interpreted
+// with when recalculating their deliveries. It should be the policy the
+// inspection service is configured with. Defaults to
+// shipping.DefaultDeliveryPolicy.
+func WithDeliveryPolicy(p shipping.DeliveryPolicy) Option {
+	return func(s *service) {
+		s.policy = p
+	}
+}
+
+// WithEventHandler sets the handler notified when a cargo has been routed.
+func WithEventHandler(h EventHandler) Option {
+	return func(s *service) {
+		s.handler = h
+	}
+}
+
+// WithConnectionTimes sets the minimum connection times that itineraries
+// must leave at transshipment ports to be assigned to a cargo.
+func WithConnectionTimes(c shipping.ConnectionTimes) Option {
+	return func(s *service) {
+		s.connections = c
+	}
+}
+
+// WithScheduleValidation refuses itineraries with legs timed more than the
+// tolerance off the schedules of their voyages, with
+// shipping.ErrLegOffSchedule, and fills in the times left out of legs from
+// the schedules. It requires the voyages to be set with WithVoyages. A
+// negative tolerance leaves leg times unvalidated.
+func WithScheduleValidation(tolerance time.Duration) Option {
+	return func(s *service) {
+		if tolerance < 0 {
+			s.schedules = nil
+			return
+		}
+		s.schedules = &tolerance
+	}
+}
+
+// WithServiceStrings sets the repository used to resolve the service strings
+// that routes may be restricted to. Without it, restricting routes to service
+// strings leaves no candidates.
+func WithServiceStrings(r shipping.ServiceStringRepository) Option {
+	return func(s *service) {
+		s.serviceStrings = r
+	}
+}
+
+// WithVoyages sets the repository of voyages whose port calls may be
+// omitted and whose capacity routes are checked against. Without it, no
+// voyage is known to the service and capacity is not limited.
+func WithVoyages(r shipping.VoyageRepository) Option {
+	return func(s *service) {
+		s.voyages = r
+	}
+}
+
+// WithCalendars sets the repository of working calendars that the expected
+// handling windows of cargos are narrowed to. Without it, every location
+// works around the clock.
+func WithCalendars(r shipping.WorkingCalendarRepository) Option {
+	return func(s *service) {
+		s.calendars = r
+	}
+}
+
+// WithScreening screens new bookings and changes of destination against
+// sanctioned parties and embargoed destinations. The customers are looked up
+// to screen them by name as well as ID.
+func WithScreening(sc shipping.Screener, customers shipping.CustomerRepository) Option {
+	return func(s *service) {
+		s.screener = sc
+		s.customers = customers
+	}
+}
+
+// WithImportRestrictions sets the import restrictions, by country, that the
+// declarations of cargos are checked against.
+func WithImportRestrictions(r shipping.ImportRestrictions) Option {
+	return func(s *service) {
+		s.restrictions = r
+	}
+}
+
+// WithOverbookingPolicy sets how far beyond their physical capacity voyages
+// may be booked. Without it, voyages are not overbooked.
+func WithOverbookingPolicy(p shipping.OverbookingPolicy) Option {
+	return func(s *service) {
+		s.overbooking = p
+	}
+}
+
+// WithDrafts sets the repository booking drafts are saved in. Without it,
+// drafts cannot be saved.
+func WithDrafts(r shipping.BookingDraftRepository) Option {
+	return func(s *service) {
+		s.drafts = r
+	}
+}
+
+// WithReleaseCodes sets the repository release codes are issued to. Without
+// it, release codes cannot be issued.
+func WithReleaseCodes(r shipping.ReleaseCodeRepository) Option {
+	return func(s *service) {
+		s.releaseCodes = r
+	}
+}
+
+// WithTimeline sets the audit log, change log and tasks that the timelines
+// of cargos are assembled from, besides their handling histories and the
+// voyages they are routed on. Nil repositories are left out.
+func WithTimeline(audit shipping.AuditRepository, changes shipping.CargoChangeRepository, tasks shipping.TaskRepository) Option {
+	return func(s *service) {
+		s.auditLog = audit
+		s.changes = changes
+		s.tasks = tasks
+	}
+}
+
+// WithQueryRepositories sets the repositories used for listing and loading
+// cargos, e.g. to direct read traffic to replicas. Commands always use the
+// repositories given to NewService.
+func WithQueryRepositories(cargos shipping.CargoRepository, events shipping.HandlingEventRepository) Option {
+	return func(s *service) {
+		s.queryCargos = cargos
+		s.queryHandlingEvents = events
+	}
+}
+
+func (s *service) EmissionsReport(ctx context.Context, from, to time.Time) (EmissionsReport, error) {
+	if from.IsZero() || to.IsZero() || !from.Before(to) {
+		return EmissionsReport{}, ErrInvalidArgument
+	}
+
+	r := EmissionsReport{From: from, To: to}
+
+	if s.emissions == nil {
+		return r, nil
+	}
+
+	err := s.queryCargos.ForEach(func(c *shipping.Cargo) error {
+		if c.Itinerary.IsEmpty() {
+			return nil
+		}
+
+		departure := c.Itinerary.InitialDepartureTime()
+		if departure.Before(from) || !departure.Before(to) {
+			return nil
+		}
+
+		r.Cargos++
+
+		e := s.estimate(c.Itinerary)
+		if e == nil {
+			r.Unknown = append(r.Unknown, string(c.TrackingID))
+			return nil
+		}
+		r.Total += e.Total
+
+		return nil
+	})
+
+	return r, err
+}
+
+func (s *service) CapacityForecast(ctx context.Context, from, to time.Time) ([]VoyageUtilization, error) {
+	if from.IsZero() || to.IsZero() || !from.Before(to) {
+		return nil, ErrInvalidArgument
+	}
+
+	result := []VoyageUtilization{}
+
+	voyages := s.departingVoyages(from, to)
+	if len(voyages) == 0 {
+		return result, nil
+	}
+
+	booked := make(shipping.Utilization)
+	if err := s.queryCargos.ForEach(func(c *shipping.Cargo) error {
+		booked.Book(c)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	services := s.allServiceStrings()
+
+	for _, v := range voyages {
+		u := VoyageUtilization{
+			VoyageNumber: string(v.VoyageNumber),
+			Departure:    v.Schedule.CarrierMovements[0].DepartureTime,
+		}
+		for _, c := range v.Capacity {
+			u.Equipment = append(u.Equipment, s.utilization(v, c, booked[v.VoyageNumber][c.Equipment], services))
+		}
+		result = append(result, u)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Departure.Before(result[j].Departure)
+	})
+
+	return result, nil
+}
+
+func (s *service) OverbookingReport(ctx context.Context, from, to time.Time) ([]OverbookedVoyage, error) {
+	if from.IsZero() || to.IsZero() || !from.Before(to) {
+		return nil, ErrInvalidArgument
+	}
+
+	result := []OverbookedVoyage{}
+
+	voyages := s.departingVoyages(from, to)
+	if len(voyages) == 0 {
+		return result, nil
+	}
+
+	// The cargos confirmed on each voyage, by equipment type.
+	cargos := make(map[shipping.VoyageNumber]map[shipping.EquipmentType][]string)
+	if err := s.queryCargos.ForEach(func(c *shipping.Cargo) error {
+		u := make(shipping.Utilization)
+		u.BookConfirmed(c)
+
+		for n, units := range u {
+			if cargos[n] == nil {
+				cargos[n] = make(map[shipping.EquipmentType][]string)
+			}
+			for e := range units {
+				cargos[n][e] = append(cargos[n][e], string(c.TrackingID))
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	services := s.allServiceStrings()
+
+	for _, v := range voyages {
+		o := OverbookedVoyage{
+			VoyageNumber: string(v.VoyageNumber),
+			Departure:    v.Schedule.CarrierMovements[0].DepartureTime,
+			Cargos:       []string{},
+		}
+		for _, c := range v.Capacity {
+			ids := cargos[v.VoyageNumber][c.Equipment]
+			if len(ids) <= c.Units {
+				continue
+			}
+			o.Equipment = append(o.Equipment, s.utilization(v, c, len(ids), services))
+			o.Cargos = append(o.Cargos, ids...)
+		}
+		if len(o.Equipment) == 0 {
+			continue
+		}
+		sort.Strings(o.Cargos)
+		result = append(result, o)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Departure.Before(result[j].Departure)
+	})
+
+	return result, nil
+}
+
+// departingVoyages returns the voyages with capacity declared that depart
+// within a period.
+func (s *service) departingVoyages(from, to time.Time) []*shipping.Voyage {
+	if s.voyages == nil {
+		return nil
+	}
+
+	var result []*shipping.Voyage
+	for _, v := range s.voyages.FindAll() {
+		if len(v.Capacity) == 0 || len(v.Schedule.CarrierMovements) == 0 {
+			continue
+		}
+		departure := v.Schedule.CarrierMovements[0].DepartureTime
+		if departure.Before(from) || !departure.Before(to) {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+// utilization returns the utilization of the capacity of a voyage for an
+// equipment type, with a number of units booked.
+func (s *service) utilization(v *shipping.Voyage, c shipping.Capacity, booked int, services shipping.ServiceStrings) EquipmentUtilization {
+	e := EquipmentUtilization{
+		Type:     c.Equipment.String(),
+		Capacity: c.Units,
+		Limit:    s.overbooking.Limit(c.Units, v.VoyageNumber, services),
+		Booked:   booked,
+	}
+	if c.Units > 0 {
+		e.Percent = math.Round(1000*float64(e.Booked)/float64(c.Units)) / 10
+	}
+	return e
+}
+
+// allServiceStrings returns the service strings voyages are operated by, if
+// known.
+func (s *service) allServiceStrings() shipping.ServiceStrings {
+	if s.serviceStrings == nil {
+		return nil
+	}
+	return shipping.ServiceStrings(s.serviceStrings.FindAll())
+}
+
+// checkCapacity returns ErrCapacityExceeded if a voyage of the itinerary has
+// no room left for the equipment of the cargo.
+func (s *service) checkCapacity(c *shipping.Cargo, itinerary shipping.Itinerary) error {
+	if s.voyages == nil {
+		return nil
+	}
+
+	services := s.allServiceStrings()
+
+	limits := make(map[shipping.VoyageNumber]int)
+	for _, l := range itinerary.Legs {
+		v, err := s.voyages.Find(l.VoyageNumber)
+		if err != nil {
+			return err
+		}
+		if units, ok := v.CapacityFor(c.Equipment); ok {
+			limits[v.VoyageNumber] = s.overbooking.Limit(units, v.VoyageNumber, services)
+		}
+	}
+
+	if len(limits) == 0 {
+		return nil
+	}
+
+	// The cargo may already be routed on some of the voyages.
+	booked := make(shipping.Utilization)
+	if err := s.cargos.ForEach(func(other *shipping.Cargo) error {
+		if other.TrackingID != c.TrackingID {
+			booked.Book(other)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for n, units := range limits {
+		if booked[n][c.Equipment] >= units {
+			return shipping.ErrCapacityExceeded
+		}
+	}
+
+	return nil
+}
+
+// estimate returns the estimated emissions of an itinerary, or nil if they
+// cannot be estimated.
+func (s *service) estimate(i shipping.Itinerary) *Emissions {
+	if s.emissions == nil || i.IsEmpty() {
+		return nil
+	}
+
+	e, err := s.emissions.Estimate(i)
+	if err != nil {
+		return nil
+	}
+
+	return &Emissions{Total: e.Total, Legs: e.Legs}
+}
+
+// assembleCargo returns a read model of a cargo, including its estimated
+// emissions.
+func (s *service) assembleCargo(c *shipping.Cargo) Cargo {
+	result := assemble(c, s.now())
+	result.Emissions = s.estimate(c.Itinerary)
+	if a := result.NextActivity; a != nil {
+		a.narrow(s.calendar(shipping.UNLocode(a.Location)))
+	}
+	return result
+}
+
+// calendar returns the working calendar of a location, or nil if it works
+// around the clock.
+func (s *service) calendar(locode shipping.UNLocode) *shipping.WorkingCalendar {
+	if s.calendars == nil {
+		return nil
+	}
+	cal, err := s.calendars.Find(locode)
+	if err != nil {
+		return nil
+	}
+	return cal
+}
+
+// findServiceStrings returns the service strings for the codes, skipping
+// unknown ones.
+func (s *service) findServiceStrings(codes []shipping.ServiceCode) shipping.ServiceStrings {
+	if s.serviceStrings == nil {
+		return nil
+	}
+
+	var result shipping.ServiceStrings
+	for _, code := range codes {
+		if ss, err := s.serviceStrings.Find(code); err == nil {
+			result = append(result, ss)
+		}
+	}
+	return result
+}
+
+// permitted returns the itineraries sailing exclusively on the service
+// strings.
+func permitted(itineraries []shipping.Itinerary, services shipping.ServiceStrings) []shipping.Itinerary {
+	result := make([]shipping.Itinerary, 0, len(itineraries))
+	for _, i := range itineraries {
+		if services.Permits(i) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// Authorizer decides whether the caller may call a method of the booking
+// service, named as in the logs and metrics, returning an error if not.
+type Authorizer func(ctx context.Context, method string) error
+
+// WithAuthorizer refuses the calls the authorizer returns an error for.
+func WithAuthorizer(a Authorizer) Option {
+	return func(s *service) {
+		s.authorizer = a
+	}
+}
+
+// WithLogger logs the calls to the service.
+func WithLogger(l log.Logger) Option {
+	return func(s *service) {
+		s.logger = l
+	}
+}
+
+// WithMetrics counts the calls to the service and records their latency.
+func WithMetrics(counter metrics.Counter, latency metrics.Histogram) Option {
+	return func(s *service) {
+		s.requestCount = counter
+		s.requestLatency = latency
+	}
+}
+
+// WithClock sets the clock the service tells the time by, rather than the
+// system clock.
+func WithClock(now func() time.Time) Option {
+	return func(s *service) {
+		s.now = now
+	}
+}
+
+// NewService creates a booking service with necessary dependencies.
+// Calls are authorized, then logged, then counted, as configured by the
+// options, so that refused calls show in the logs and metrics.
+func NewService(cargos shipping.CargoRepository, locations shipping.LocationRepository, events shipping.HandlingEventRepository, rs shipping.RoutingService, opts ...Option) Service {
+	s := &service{
+		cargos:         cargos,
+		locations:      locations,
+		handlingEvents: events,
+		routingService: rs,
+		routes:         make(map[routeKey][]shipping.Itinerary),
+		fetches:        make(map[routeKey]*routeFetch),
+		recent:         make(map[bookingKey]recentBooking),
+		policy:         shipping.DefaultDeliveryPolicy,
+
+		queryCargos:         cargos,
+		queryHandlingEvents: events,
+
+		now: time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	var svc Service = s
+	if s.authorizer != nil {
+		svc = NewAuthorizingService(s.authorizer, svc)
+	}
+	if s.logger != nil {
+		svc = NewLoggingService(s.logger, svc)
+	}
+	if s.requestCount != nil {
+		svc = NewInstrumentingService(s.requestCount, s.requestLatency, svc)
+	}
+	return svc
+}
+
+// Location is a read model for booking views.
+type Location struct {
+	UNLocode string `json:"locode"`
+	Name     string `json:"name"`
+}
+
+// Draft is a read model for booking drafts. Missing lists the fields the
+// draft needs before it can be booked.
+type Draft struct {
+	ID              string     `json:"id"`
+	Customer        string     `json:"customer,omitempty"`
+	Origin          string     `json:"origin,omitempty"`
+	Destination     string     `json:"destination,omitempty"`
+	ArrivalDeadline *time.Time `json:"arrival_deadline,omitempty"`
+	Equipment       string     `json:"equipment"`
+	Missing         []string   `json:"missing,omitempty"`
+	Saved           time.Time  `json:"saved"`
+}
+
+func assembleDraft(d *shipping.BookingDraft) Draft {
+	result := Draft{
+		ID:          string(d.ID),
+		Customer:    string(d.Customer),
+		Origin:      string(d.Origin),
+		Destination: string(d.Destination),
+		Equipment:   d.Equipment.String(),
+		Missing:     d.Missing(),
+		Saved:       d.Saved,
+	}
+	if !d.ArrivalDeadline.IsZero() {
+		deadline := d.ArrivalDeadline
+		result.ArrivalDeadline = &deadline
+	}
+	return result
+}
+
+// TimelineEntry is a read model for something that happened to a cargo.
+// Source is where it was found: handling, audit, inspection, voyage or
+// task.
+type TimelineEntry struct {
+	Time         time.Time `json:"time"`
+	Source       string    `json:"source"`
+	Type         string    `json:"type"`
+	Location     string    `json:"location,omitempty"`
+	VoyageNumber string    `json:"voyage_number,omitempty"`
+	Actor        string    `json:"actor,omitempty"`
+	Description  string    `json:"description,omitempty"`
+}
+
+// Cargo is a read model for booking views.
+type Cargo struct {
+	ArrivalDeadline  time.Time      `json:"arrival_deadline"`
+	DeadlineType     string         `json:"deadline_type"`
+	Destination      string         `json:"destination"`
+	Legs             []shipping.Leg `json:"legs,omitempty"`
+	Misrouted        bool           `json:"misrouted"`
+	Origin           string         `json:"origin"`
+	Equipment        string         `json:"equipment"`
+	Routed           bool           `json:"routed"`
+	TrackingID       string         `json:"tracking_id"`
+	TransportStatus  string         `json:"transport_status"`
+	MasterID         string         `json:"master_id,omitempty"`
+	Consolidated     []string       `json:"consolidated,omitempty"`
+	ReturnOf         string         `json:"return_of,omitempty"`
+	ReturnedBy       string         `json:"returned_by,omitempty"`
+	Customer         string         `json:"customer,omitempty"`
+	DuplicateOf      string         `json:"duplicate_of,omitempty"`
+	Screening        string         `json:"screening,omitempty"`
+	Commodities      []string       `json:"commodities,omitempty"`
+	Documents        []string       `json:"documents,omitempty"`
+	ServiceLevel     string         `json:"service_level,omitempty"`
+	SLABreached      bool           `json:"sla_breached,omitempty"`
+	Rollovers        int            `json:"rollovers,omitempty"`
+	Emissions        *Emissions     `json:"emissions,omitempty"`
+	NextActivity     *Activity      `json:"next_activity,omitempty"`
+	ProjectedArrival *time.Time     `json:"projected_arrival,omitempty"`
+	DeadlineAtRisk   bool           `json:"deadline_at_risk,omitempty"`
+	DeadlineSlip     string         `json:"deadline_slip,omitempty"`
+	Progress         *Progress      `json:"progress,omitempty"`
+}
+
+func trackingIDs(ids []shipping.TrackingID) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = string(id)
+	}
+	return result
+}
+
+func assemble(c *shipping.Cargo, now time.Time) Cargo {
+	d := c.Delivery
+
+	result := Cargo{
+		TrackingID:      string(c.TrackingID),
+		Origin:          string(c.Origin),
+		Equipment:       c.Equipment.String(),
+		Destination:     string(c.RouteSpecification.Destination),
+		Misrouted:       d.RoutingStatus == shipping.Misrouted,
+		Routed:          !c.Itinerary.IsEmpty(),
+		ArrivalDeadline: c.RouteSpecification.ArrivalDeadline,
+		DeadlineType:    c.RouteSpecification.DeadlineType.String(),
+		Legs:            c.Itinerary.Legs,
+		TransportStatus: d.TransportStatus.String(),
+		MasterID:        string(c.MasterID),
+		Consolidated:    trackingIDs(c.Consolidated),
+		ReturnOf:        string(c.ReturnOf),
+		ReturnedBy:      string(c.ReturnedBy),
+		Customer:        string(c.Customer),
+		DuplicateOf:     string(c.DuplicateOf),
+		ServiceLevel:    string(c.ServiceLevel),
+		SLABreached:     len(c.SLABreaches) > 0,
+		Rollovers:       c.Rollovers,
+		NextActivity:    assembleActivity(d),
+		Progress:        assembleProgress(d, now),
+	}
+
+	if t := d.ProjectedArrival(); !t.IsZero() {
+		result.ProjectedArrival = &t
+	}
+	if slip := d.DeadlineSlip(); slip > 0 {
+		result.DeadlineAtRisk = true
+		result.DeadlineSlip = slip.String()
+	}
+	if c.Screening.IsFlagged() {
+		result.Screening = c.Screening.String()
+	}
+	for _, cc := range c.Declaration.Commodities {
+		result.Commodities = append(result.Commodities, string(cc))
+	}
+	for _, doc := range c.Declaration.Documents {
+		result.Documents = append(result.Documents, string(doc))
+	}
+
+	return result
+}
+
+// Activity is a read model for an expected handling activity. From and To
+// bound when it is expected to take place, and are left out when open.
+type Activity struct {
+	Type     string     `json:"type"`
+	Location string     `json:"location"`
+	Voyage   string     `json:"voyage_number,omitempty"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+}
+
+// assembleActivity returns a read model of the next expected activity of a
+// delivery, or nil if no activity is expected.
+func assembleActivity(d shipping.Delivery) *Activity {
+	a := d.NextExpectedActivity
+	if a.Type == shipping.NotHandled {
+		return nil
+	}
+
+	result := &Activity{
+		Type:     a.Type.String(),
+		Location: string(a.Location),
+		Voyage:   string(a.VoyageNumber),
+	}
+
+	from, to := d.Itinerary.ExpectedWindow(a)
+	if !from.IsZero() {
+		result.From = &from
+	}
+	if !to.IsZero() {
+		result.To = &to
+	}
+
+	return result
+}
+
+// narrow narrows the window of the activity to the working hours of its
+// location. A nil calendar leaves the window as is.
+func (a *Activity) narrow(cal *shipping.WorkingCalendar) {
+	if a == nil || cal == nil {
+		return
+	}
+
+	var from, to time.Time
+	if a.From != nil {
+		from = *a.From
+	}
+	if a.To != nil {
+		to = *a.To
+	}
+
+	from, to = cal.AdjustWindow(from, to)
+	if !from.IsZero() {
+		a.From = &from
+	}
+	if !to.IsZero() {
+		a.To = &to
+	}
+}
+
+// Progress is a read model for the progress of a cargo along its itinerary.
+type Progress struct {
+	Percent       float64     `json:"percent"`
+	CompletedLegs int         `json:"completed_legs"`
+	TotalLegs     int         `json:"total_legs"`
+	Milestones    []Milestone `json:"milestones"`
+}
+
+// Milestone is a read model for a planned departure or arrival.
+type Milestone struct {
+	Type     string    `json:"type"`
+	Location string    `json:"location"`
+	Voyage   string    `json:"voyage_number"`
+	Planned  time.Time `json:"planned"`
+	Reached  bool      `json:"reached"`
+}
+
+// assembleProgress returns a read model of the progress of a delivery, or
+// nil if the cargo has not been routed.
+func assembleProgress(d shipping.Delivery, now time.Time) *Progress {
+	if d.Itinerary.IsEmpty() {
+		return nil
+	}
+
+	p := d.Progress(now)
+
+	result := &Progress{
+		Percent:       math.Round(p.Percent*10) / 10,
+		CompletedLegs: p.CompletedLegs,
+		TotalLegs:     p.TotalLegs,
+	}
+	for _, m := range p.Milestones {
+		result.Milestones = append(result.Milestones, Milestone{
+			Type:     m.Activity.Type.String(),
+			Location: string(m.Activity.Location),
+			Voyage:   string(m.Activity.VoyageNumber),
+			Planned:  m.Planned,
+			Reached:  m.Reached,
+		})
+	}
+	return result
 }
@@ -1,10 +1,21 @@
 package booking
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/go-kit/kit/log"
+
 	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/builders"
+	"github.com/marcusolsson/goddd/geo"
 	"github.com/marcusolsson/goddd/mock"
 )
 
@@ -15,15 +26,22 @@ func TestBookNewCargo(t *testing.T) {
 		deadline    = time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
 	)
 
-	var cargos mockCargoRepository
+	var (
+		cargos  mockCargoRepository
+		handler recordingHandler
+	)
 
-	s := NewService(&cargos, nil, nil, nil)
+	s := NewService(&cargos, nil, nil, nil, WithEventHandler(&handler))
 
-	id, err := s.BookNewCargo(origin, destination, deadline)
+	id, err := s.BookNewCargo(context.Background(), "", origin, destination, deadline, shipping.Reefer)
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	if want := []shipping.TrackingID{id}; !reflect.DeepEqual(handler.booked, want) {
+		t.Errorf("booked = %v; want = %v", handler.booked, want)
+	}
+
 	c, err := cargos.Find(id)
 	if err != nil {
 		t.Fatal(err)
@@ -32,6 +50,9 @@ func TestBookNewCargo(t *testing.T) {
 	if c.TrackingID != id {
 		t.Errorf("c.TrackingID = %s; want = %s", c.TrackingID, id)
 	}
+	if c.Equipment != shipping.Reefer {
+		t.Errorf("c.Equipment = %v; want = %v", c.Equipment, shipping.Reefer)
+	}
 	if c.Origin != origin {
 		t.Errorf("c.Origin = %s; want = %s", c.Origin, origin)
 	}
@@ -45,6 +66,208 @@ func TestBookNewCargo(t *testing.T) {
 	}
 }
 
+func TestWithAuthorizer(t *testing.T) {
+	errForbidden := errors.New("forbidden")
+
+	var methods []string
+	authorize := func(ctx context.Context, method string) error {
+		methods = append(methods, method)
+		if method == "book" {
+			return errForbidden
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	var cargos mockCargoRepository
+
+	var locations mock.LocationRepository
+	locations.FindAllFn = func() []*shipping.Location {
+		return nil
+	}
+
+	s := NewService(&cargos, &locations, nil, nil,
+		WithAuthorizer(authorize),
+		WithLogger(log.NewLogfmtLogger(&buf)),
+	)
+
+	deadline := time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
+	if _, err := s.BookNewCargo(context.Background(), "", shipping.SESTO, shipping.AUMEL, deadline, shipping.Reefer); err != errForbidden {
+		t.Errorf("err = %v; want = %v", err, errForbidden)
+	}
+	if cargos.cargo != nil {
+		t.Errorf("refused booking was stored")
+	}
+	if !strings.Contains(buf.String(), "err=forbidden") {
+		t.Errorf("log = %q; want the refused call logged", buf.String())
+	}
+
+	s.Locations(context.Background())
+
+	if want := []string{"book", "list_locations"}; !reflect.DeepEqual(methods, want) {
+		t.Errorf("methods = %v; want = %v", methods, want)
+	}
+}
+
+func TestBookNewCargoDuplicate(t *testing.T) {
+	var (
+		customer = shipping.CustomerID("ACME")
+		deadline = time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
+	)
+
+	t.Run("warn", func(t *testing.T) {
+		var cargos mockCargoRepository
+
+		s := NewService(&cargos, nil, nil, nil, WithDuplicateDetection(time.Minute, DuplicateWarn))
+
+		first, err := s.BookNewCargo(context.Background(), customer, shipping.SESTO, shipping.AUMEL, deadline, shipping.Container20)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		second, err := s.BookNewCargo(context.Background(), customer, shipping.SESTO, shipping.AUMEL, deadline, shipping.Container20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if second == first {
+			t.Fatalf("second = %s; want a new cargo", second)
+		}
+
+		c, err := cargos.Find(second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.DuplicateOf != first {
+			t.Errorf("c.DuplicateOf = %s; want = %s", c.DuplicateOf, first)
+		}
+		if c.Customer != customer {
+			t.Errorf("c.Customer = %s; want = %s", c.Customer, customer)
+		}
+
+		// Another customer booking the same route is no duplicate.
+		other, err := s.BookNewCargo(context.Background(), "INITECH", shipping.SESTO, shipping.AUMEL, deadline, shipping.Container20)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c, err = cargos.Find(other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.DuplicateOf != "" {
+			t.Errorf("c.DuplicateOf = %s; want none", c.DuplicateOf)
+		}
+	})
+
+	t.Run("block", func(t *testing.T) {
+		var cargos mockCargoRepository
+
+		s := NewService(&cargos, nil, nil, nil, WithDuplicateDetection(time.Minute, DuplicateBlock))
+
+		first, err := s.BookNewCargo(context.Background(), customer, shipping.SESTO, shipping.AUMEL, deadline, shipping.Container20)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id, err := s.BookNewCargo(context.Background(), customer, shipping.SESTO, shipping.AUMEL, deadline, shipping.Container20)
+		if err != ErrDuplicateBooking {
+			t.Fatalf("err = %v; want = %v", err, ErrDuplicateBooking)
+		}
+		if id != first {
+			t.Errorf("id = %s; want = %s", id, first)
+		}
+
+		if n := len(cargos.FindAll()); n != 1 {
+			t.Errorf("len(cargos) = %d; want = %d", n, 1)
+		}
+
+		// A different deadline is a different booking.
+		if _, err := s.BookNewCargo(context.Background(), customer, shipping.SESTO, shipping.AUMEL, deadline.AddDate(0, 0, 1), shipping.Container20); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("store failed", func(t *testing.T) {
+		errStore := errors.New("store failed")
+
+		var cargos mock.CargoRepository
+		cargos.StoreFn = func(c *shipping.Cargo) error {
+			return errStore
+		}
+
+		s := NewService(&cargos, nil, nil, nil, WithDuplicateDetection(time.Minute, DuplicateBlock))
+
+		if _, err := s.BookNewCargo(context.Background(), customer, shipping.SESTO, shipping.AUMEL, deadline, shipping.Container20); err != errStore {
+			t.Fatalf("err = %v; want = %v", err, errStore)
+		}
+
+		// The booking that failed is not remembered, so a retry is no duplicate.
+		cargos.StoreFn = func(c *shipping.Cargo) error {
+			return nil
+		}
+
+		if _, err := s.BookNewCargo(context.Background(), customer, shipping.SESTO, shipping.AUMEL, deadline, shipping.Container20); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+type stubScreener struct {
+	result shipping.ScreeningResult
+	err    error
+	got    shipping.ScreeningRequest
+}
+
+func (s *stubScreener) Screen(ctx context.Context, r shipping.ScreeningRequest) (shipping.ScreeningResult, error) {
+	s.got = r
+	return s.result, s.err
+}
+
+func TestBookNewCargoScreening(t *testing.T) {
+	deadline := time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
+
+	var customers mock.CustomerRepository
+	customers.FindFn = func(id shipping.CustomerID) (*shipping.Customer, error) {
+		return &shipping.Customer{ID: id, Name: "Acme Corp"}, nil
+	}
+
+	var (
+		cargos   mockCargoRepository
+		screener stubScreener
+	)
+
+	s := NewService(&cargos, nil, nil, nil, WithScreening(&screener, &customers))
+
+	screener.result = shipping.ScreeningResult{Decision: shipping.ScreeningBlocked, Reasons: []string{"embargoed country AU"}}
+	if _, err := s.BookNewCargo(context.Background(), "ACME", shipping.SESTO, shipping.AUMEL, deadline, shipping.Container20); err != shipping.ErrScreeningBlocked {
+		t.Fatalf("err = %v; want = %v", err, shipping.ErrScreeningBlocked)
+	}
+	if cargos.cargo != nil {
+		t.Errorf("blocked cargo should not be stored")
+	}
+	if screener.got.CustomerName != "Acme Corp" || len(screener.got.Locations) != 2 {
+		t.Errorf("got = %+v; want the name of the customer and both locations", screener.got)
+	}
+
+	screener.result = shipping.ScreeningResult{Decision: shipping.ScreeningFlagged, Reasons: []string{"possible match"}}
+	if _, err := s.BookNewCargo(context.Background(), "ACME", shipping.SESTO, shipping.AUMEL, deadline, shipping.Container20); err != nil {
+		t.Fatal(err)
+	}
+	c := assemble(cargos.cargo, time.Now())
+	if c.Screening != "flagged: possible match" {
+		t.Errorf("c.Screening = %q; want = %q", c.Screening, "flagged: possible match")
+	}
+
+	// Screening that fails flags the booking rather than refusing it.
+	screener.result, screener.err = shipping.ScreeningResult{}, errors.New("unavailable")
+	if _, err := s.BookNewCargo(context.Background(), "ACME", shipping.SESTO, shipping.AUMEL, deadline, shipping.Container20); err != nil {
+		t.Fatal(err)
+	}
+	if !cargos.cargo.Screening.IsFlagged() {
+		t.Errorf("cargo should be flagged when screening fails")
+	}
+}
+
 type stubRoutingService struct{}
 
 func (s *stubRoutingService) FetchRoutesForSpecification(rs shipping.RouteSpecification) []shipping.Itinerary {
@@ -57,6 +280,76 @@ func (s *stubRoutingService) FetchRoutesForSpecification(rs shipping.RouteSpecif
 	}
 }
 
+func TestBookDraft(t *testing.T) {
+	stored := make(map[shipping.DraftID]*shipping.BookingDraft)
+
+	var drafts mock.BookingDraftRepository
+	drafts.StoreFn = func(d *shipping.BookingDraft) error {
+		stored[d.ID] = d
+		return nil
+	}
+	drafts.FindFn = func(id shipping.DraftID) (*shipping.BookingDraft, error) {
+		if d, ok := stored[id]; ok {
+			return d, nil
+		}
+		return nil, shipping.ErrUnknownDraft
+	}
+	drafts.RemoveFn = func(id shipping.DraftID) error {
+		delete(stored, id)
+		return nil
+	}
+
+	var cargos mockCargoRepository
+
+	s := NewService(&cargos, nil, nil, nil, WithDrafts(&drafts))
+
+	ctx := context.Background()
+
+	id, err := s.SaveDraft(ctx, shipping.BookingDraft{Customer: "ACME", Origin: shipping.SESTO})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.BookDraft(ctx, id); err != shipping.ErrIncompleteDraft {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrIncompleteDraft)
+	}
+	if cargos.cargo != nil {
+		t.Errorf("incomplete draft was booked")
+	}
+
+	if _, err := s.SaveDraft(ctx, shipping.BookingDraft{ID: "no_such_id"}); err != shipping.ErrUnknownDraft {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownDraft)
+	}
+
+	deadline := time.Date(2016, time.March, 24, 23, 0, 0, 0, time.UTC)
+	if _, err := s.SaveDraft(ctx, shipping.BookingDraft{
+		ID:              id,
+		Customer:        "ACME",
+		Origin:          shipping.SESTO,
+		Destination:     shipping.DEHAM,
+		ArrivalDeadline: deadline,
+		Equipment:       shipping.Reefer,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	trackingID, err := s.BookDraft(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := cargos.Find(trackingID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Customer != "ACME" || c.RouteSpecification.Destination != shipping.DEHAM || c.Equipment != shipping.Reefer {
+		t.Errorf("c = %+v; want the cargo of the draft", c)
+	}
+	if _, ok := stored[id]; ok {
+		t.Errorf("the draft should be removed once booked")
+	}
+}
+
 func TestRequestPossibleRoutesForCargo(t *testing.T) {
 	var (
 		origin      = shipping.SESTO
@@ -70,172 +363,1762 @@ func TestRequestPossibleRoutesForCargo(t *testing.T) {
 
 	s := NewService(&cargos, nil, nil, &rs)
 
-	r := s.RequestPossibleRoutesForCargo("no_such_id")
+	r := s.RequestPossibleRoutesForCargo(context.Background(), "no_such_id").Itineraries
 
 	if len(r) != 0 {
 		t.Errorf("len(r) = %d; want = %d", len(r), 0)
 	}
 
-	id, err := s.BookNewCargo(origin, destination, deadline)
+	id, err := s.BookNewCargo(context.Background(), "", origin, destination, deadline, shipping.Container20)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	i := s.RequestPossibleRoutesForCargo(id)
+	i := s.RequestPossibleRoutesForCargo(context.Background(), id).Itineraries
 
 	if len(i) != 1 {
 		t.Errorf("len(i) = %d; want = %d", len(i), 1)
 	}
 }
 
-func TestAssignCargoToRoute(t *testing.T) {
-	var cargos mockCargoRepository
+func TestRequestPossibleRoutesForCargo_Deadline(t *testing.T) {
+	deadline := time.Date(2016, time.March, 20, 0, 0, 0, 0, time.UTC)
 
-	var rs stubRoutingService
+	var (
+		early = shipping.Itinerary{Legs: []shipping.Leg{shipping.NewLeg("V100", shipping.CNHKG, shipping.SESTO, deadline.AddDate(0, 0, -10), deadline.AddDate(0, 0, -1))}}
+		late  = shipping.Itinerary{Legs: []shipping.Leg{shipping.NewLeg("V200", shipping.CNHKG, shipping.SESTO, deadline.AddDate(0, 0, -8), deadline.AddDate(0, 0, 1))}}
+	)
+
+	var rs mock.RoutingService
+	rs.FetchRoutesFn = func(spec shipping.RouteSpecification) []shipping.Itinerary {
+		return []shipping.Itinerary{late, early}
+	}
+
+	var cargos mockCargoRepository
 
 	s := NewService(&cargos, nil, nil, &rs)
 
-	var (
-		origin      = shipping.SESTO
-		destination = shipping.AUMEL
-		deadline    = time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
-	)
+	ctx := context.Background()
 
-	id, err := s.BookNewCargo(origin, destination, deadline)
+	id, err := s.BookNewCargo(ctx, "", shipping.CNHKG, shipping.SESTO, deadline, shipping.Container20)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	i := s.RequestPossibleRoutesForCargo(id)
+	rc := s.RequestPossibleRoutesForCargo(ctx, id)
+	if len(rc.Itineraries) != 2 || !reflect.DeepEqual(rc.Late, []bool{true, false}) {
+		t.Errorf("soft deadline: %d routes, late = %v; want 2 routes, late = [true false]", len(rc.Itineraries), rc.Late)
+	}
 
-	if len(i) != 1 {
-		t.Errorf("len(i) = %d; want = %d", len(i), 1)
+	if err := s.ChangeDeadlineType(ctx, id, shipping.HardDeadline); err != nil {
+		t.Fatal(err)
+	}
+	if dt := cargos.cargo.RouteSpecification.DeadlineType; dt != shipping.HardDeadline {
+		t.Fatalf("deadline type = %s; want = %s", dt, shipping.HardDeadline)
+	}
+
+	rc = s.RequestPossibleRoutesForCargo(ctx, id)
+	if len(rc.Itineraries) != 1 || rc.Itineraries[0].Legs[0].VoyageNumber != "V100" || rc.Late != nil {
+		t.Errorf("hard deadline: routes = %+v, late = %v; want the early route only", rc.Itineraries, rc.Late)
 	}
 
-	if err := s.AssignCargoToRoute(id, i[0]); err != nil {
+	if err := s.AssignCargoToRoute(ctx, id, late); err != shipping.ErrArrivalDeadlineMissed {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrArrivalDeadlineMissed)
+	}
+	if err := s.AssignCargoToRoute(ctx, id, early); err != nil {
 		t.Fatal(err)
 	}
 
-	if err := s.AssignCargoToRoute("no_such_id", shipping.Itinerary{}); err != ErrInvalidArgument {
-		t.Errorf("err = %s; want = %s", err, ErrInvalidArgument)
+	// Extending the voyage past the deadline misroutes the cargo, unless the
+	// deadline may be missed.
+	cargos.cargo.AssignToRoute(late)
+	if cargos.cargo.Delivery.RoutingStatus != shipping.Misrouted {
+		t.Errorf("routing status = %s; want = %s", cargos.cargo.Delivery.RoutingStatus, shipping.Misrouted)
+	}
+	if err := s.ChangeDeadlineType(ctx, id, shipping.SoftDeadline); err != nil {
+		t.Fatal(err)
+	}
+	if cargos.cargo.Delivery.RoutingStatus != shipping.Routed {
+		t.Errorf("routing status = %s; want = %s", cargos.cargo.Delivery.RoutingStatus, shipping.Routed)
 	}
 }
 
-func TestChangeCargoDestination(t *testing.T) {
-	var cargos mockCargoRepository
-	var locations mock.LocationRepository
+func TestEmissions(t *testing.T) {
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(n shipping.VoyageNumber) (*shipping.Voyage, error) {
+		return &shipping.Voyage{VoyageNumber: n}, nil
+	}
 
-	locations.FindFn = func(loc shipping.UNLocode) (*shipping.Location, error) {
-		if loc != shipping.AUMEL {
-			return nil, shipping.ErrUnknownLocation
+	var locations mock.LocationRepository
+	locations.FindFn = func(l shipping.UNLocode) (*shipping.Location, error) {
+		switch l {
+		case shipping.DEHAM:
+			return shipping.Hamburg, nil
+		case shipping.NLRTM:
+			return shipping.Rotterdam, nil
 		}
-		return shipping.Melbourne, nil
+		return nil, shipping.ErrUnknownLocation
 	}
 
-	var rs stubRoutingService
+	var (
+		cargos mockCargoRepository
+		rs     stubRoutingService
+	)
 
-	s := NewService(&cargos, &locations, nil, &rs)
+	s := NewService(&cargos, &locations, nil, &rs, WithEmissions(shipping.EmissionsCalculator{
+		Voyages:   &voyages,
+		Distances: geo.NewMatrix(&locations),
+		Factors:   shipping.EmissionFactors{shipping.Sea: 10},
+	}))
 
 	c := shipping.NewCargo("ABC", shipping.RouteSpecification{
-		Origin:          shipping.SESTO,
-		Destination:     shipping.CNHKG,
-		ArrivalDeadline: time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC),
+		Origin:      shipping.DEHAM,
+		Destination: shipping.NLRTM,
 	})
+	cargos.Store(c)
 
-	if err := s.ChangeDestination("no_such_id", shipping.SESTO); err != shipping.ErrUnknownCargo {
-		t.Errorf("err = %s; want = %s", err, shipping.ErrUnknownCargo)
-	}
-
-	if err := cargos.Store(c); err != nil {
-		t.Fatal(err)
+	rc := s.RequestPossibleRoutesForCargo(context.Background(), c.TrackingID)
+	if len(rc.Emissions) != len(rc.Itineraries) {
+		t.Fatalf("len(rc.Emissions) = %d; want = %d", len(rc.Emissions), len(rc.Itineraries))
 	}
-
-	if err := s.ChangeDestination(c.TrackingID, "no_such_unlocode"); err != shipping.ErrUnknownLocation {
-		t.Errorf("err = %s; want = %s", err, shipping.ErrUnknownLocation)
+	if e := rc.Emissions[0]; e == nil || e.Total < 4 || e.Total > 4.2 {
+		t.Errorf("rc.Emissions[0] = %+v; want ~4.1 kg", e)
 	}
 
-	if c.RouteSpecification.Destination != shipping.CNHKG {
-		t.Errorf("c.RouteSpecification.Destination = %s; want = %s",
-			c.RouteSpecification.Destination, shipping.CNHKG)
-	}
+	departure := time.Date(2016, time.March, 10, 0, 0, 0, 0, time.UTC)
+	c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+		{VoyageNumber: "V100", LoadLocation: shipping.DEHAM, UnloadLocation: shipping.NLRTM, LoadTime: departure},
+	}})
 
-	if err := s.ChangeDestination(c.TrackingID, shipping.AUMEL); err != nil {
+	r, err := s.EmissionsReport(context.Background(), departure.AddDate(0, 0, -1), departure.AddDate(0, 0, 1))
+	if err != nil {
 		t.Fatal(err)
 	}
+	if r.Cargos != 1 || r.Total < 4 || r.Total > 4.2 {
+		t.Errorf("r = %+v; want 1 cargo and ~4.1 kg", r)
+	}
 
-	uc, err := cargos.Find(c.TrackingID)
+	r, err = s.EmissionsReport(context.Background(), departure.AddDate(0, 0, 1), departure.AddDate(0, 0, 2))
 	if err != nil {
 		t.Fatal(err)
 	}
+	if r.Cargos != 0 {
+		t.Errorf("r.Cargos = %d; want = %d", r.Cargos, 0)
+	}
 
-	if uc.RouteSpecification.Destination != shipping.AUMEL {
-		t.Errorf("uc.RouteSpecification.Destination = %s; want = %s",
-			uc.RouteSpecification.Destination, shipping.AUMEL)
+	if _, err := s.EmissionsReport(context.Background(), departure, departure); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
 	}
 }
 
-func TestLoadCargo(t *testing.T) {
-	deadline := time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
+func TestRequestPossibleRoutesForCargo_Performance(t *testing.T) {
+	t0 := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	var rs mock.RoutingService
+	rs.FetchRoutesFn = func(spec shipping.RouteSpecification) []shipping.Itinerary {
+		return []shipping.Itinerary{
+			{Legs: []shipping.Leg{shipping.NewLeg("V101", spec.Origin, spec.Destination, t0.Add(30*24*time.Hour), t0.Add(31*24*time.Hour))}},
+			{Legs: []shipping.Leg{shipping.NewLeg("V200", spec.Origin, spec.Destination, t0.Add(30*24*time.Hour), t0.Add(32*24*time.Hour))}},
+		}
+	}
+
+	// V100 and V101 sail the same service, which was late before.
+	var serviceStrings mock.ServiceStringRepository
+	serviceStrings.FindAllFn = func() []*shipping.ServiceString {
+		return []*shipping.ServiceString{{Code: "AE1", Voyages: []shipping.VoyageNumber{"V100", "V101"}}}
+	}
+
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{Origin: shipping.DEHAM, Destination: shipping.NLRTM})
 
 	var cargos mock.CargoRepository
 	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
-		return &shipping.Cargo{
-			TrackingID: "test_id",
-			Origin:     shipping.SESTO,
-			RouteSpecification: shipping.RouteSpecification{
-				Origin:          shipping.SESTO,
-				Destination:     shipping.AUMEL,
-				ArrivalDeadline: deadline,
-			},
-		}, nil
+		return c, nil
 	}
 
-	s := NewService(&cargos, nil, nil, nil)
+	var lanes mock.LanePerformanceRepository
+	lanes.FindAllFn = func() map[shipping.Lane]shipping.LanePerformance {
+		return map[shipping.Lane]shipping.LanePerformance{
+			{Service: "AE1", From: shipping.DEHAM, To: shipping.NLRTM}: {Sailed: 1, TotalDelay: 12 * time.Hour},
+		}
+	}
 
-	c, err := s.LoadCargo("test_id")
-	if err != nil {
-		t.Fatal(err)
+	s := NewService(&cargos, nil, nil, &rs, WithServiceStrings(&serviceStrings), WithRoutePerformance(&lanes))
+
+	rc := s.RequestPossibleRoutesForCargo(context.Background(), c.TrackingID)
+	if len(rc.Performance) != 2 {
+		t.Fatalf("len(rc.Performance) = %d; want = %d", len(rc.Performance), 2)
 	}
 
-	if c.TrackingID != "test_id" {
-		t.Errorf("c.TrackingID = %s; want = %s", c.TrackingID, "test_id")
+	want := Performance{Sailed: 1, AverageDelayHours: 12}
+	if p := rc.Performance[0]; p == nil || p.Total != want || len(p.Legs) != 1 || p.Legs[0] != want {
+		t.Errorf("rc.Performance[0] = %+v; want = %+v", p, want)
 	}
-	if c.Origin != "SESTO" {
-		t.Errorf("c.Origin = %s; want = %s", c.Origin, "SESTO")
+	if p := rc.Performance[1]; p != nil {
+		t.Errorf("rc.Performance[1] = %+v; want none for a lane never sailed", p)
 	}
-	if c.Destination != "AUMEL" {
-		t.Errorf("c.Destination = %s; want = %s", c.Origin, "AUMEL")
+}
+
+func TestRequestPossibleRoutesForCargo_ServiceStrings(t *testing.T) {
+	var cargos mockCargoRepository
+
+	var rs mock.RoutingService
+	rs.FetchRoutesFn = func(spec shipping.RouteSpecification) []shipping.Itinerary {
+		return []shipping.Itinerary{
+			{Legs: []shipping.Leg{
+				{VoyageNumber: "V300", LoadLocation: spec.Origin, UnloadLocation: shipping.DEHAM},
+				{VoyageNumber: "V400", LoadLocation: shipping.DEHAM, UnloadLocation: spec.Destination},
+			}},
+			{Legs: []shipping.Leg{
+				{VoyageNumber: "V400", LoadLocation: spec.Origin, UnloadLocation: spec.Destination},
+			}},
+		}
 	}
-	if c.ArrivalDeadline != deadline {
-		t.Errorf("c.ArrivalDeadline = %s; want = %s", c.ArrivalDeadline, deadline)
+
+	var services mock.ServiceStringRepository
+	services.FindFn = func(code shipping.ServiceCode) (*shipping.ServiceString, error) {
+		switch code {
+		case shipping.NE1.Code:
+			return shipping.NE1, nil
+		case shipping.AE1.Code:
+			return shipping.AE1, nil
+		}
+		return nil, shipping.ErrUnknownServiceString
 	}
-	if c.Misrouted {
-		t.Errorf("cargo should not be misrouted")
+
+	s := NewService(&cargos, nil, nil, &rs, WithServiceStrings(&services))
+
+	id, err := s.BookNewCargo(context.Background(), "", shipping.DEHAM, shipping.SESTO, time.Now().AddDate(0, 0, 7), shipping.Container20)
+	if err != nil {
+		t.Fatal(err)
 	}
-	if c.Routed {
-		t.Errorf("cargo should not have been routed")
+
+	if got := s.RequestPossibleRoutesForCargo(context.Background(), id).Itineraries; len(got) != 2 {
+		t.Errorf("len(got) = %d; want = %d", len(got), 2)
 	}
-	if len(c.Legs) != 0 {
-		t.Errorf("len(c.Legs) = %d; want = %d", len(c.Legs), 0)
+	if got := s.RequestPossibleRoutesForCargo(context.Background(), id, shipping.NE1.Code).Itineraries; len(got) != 1 {
+		t.Errorf("len(got) = %d; want = %d", len(got), 1)
+	}
+	if got := s.RequestPossibleRoutesForCargo(context.Background(), id, shipping.NE1.Code, shipping.AE1.Code).Itineraries; len(got) != 2 {
+		t.Errorf("len(got) = %d; want = %d", len(got), 2)
+	}
+	if got := s.RequestPossibleRoutesForCargo(context.Background(), id, "XX9").Itineraries; len(got) != 0 {
+		t.Errorf("len(got) = %d; want = %d", len(got), 0)
 	}
 }
 
-type mockCargoRepository struct {
-	cargo *shipping.Cargo
-}
-
-func (r *mockCargoRepository) Store(c *shipping.Cargo) error {
-	r.cargo = c
-	return nil
-}
+func TestCheckDeadlineFeasibility(t *testing.T) {
+	arrival := time.Date(2016, time.March, 20, 12, 0, 0, 0, time.UTC)
 
-func (r *mockCargoRepository) Find(id shipping.TrackingID) (*shipping.Cargo, error) {
-	if r.cargo != nil {
-		return r.cargo, nil
+	var rs mock.RoutingService
+	rs.FetchRoutesFn = func(spec shipping.RouteSpecification) []shipping.Itinerary {
+		return []shipping.Itinerary{
+			{Legs: []shipping.Leg{
+				shipping.NewLeg("V400", spec.Origin, spec.Destination, arrival.Add(-72*time.Hour), arrival.Add(48*time.Hour)),
+			}},
+			{Legs: []shipping.Leg{
+				shipping.NewLeg("V300", spec.Origin, spec.Destination, arrival.Add(-48*time.Hour), arrival),
+			}},
+		}
 	}
-	return nil, shipping.ErrUnknownCargo
-}
+
+	var locations mock.LocationRepository
+	locations.FindFn = func(l shipping.UNLocode) (*shipping.Location, error) {
+		if l == shipping.DEHAM || l == shipping.SESTO {
+			return &shipping.Location{UNLocode: l}, nil
+		}
+		return nil, shipping.ErrUnknownLocation
+	}
+
+	s := NewService(nil, &locations, nil, &rs)
+
+	tests := []struct {
+		deadline time.Time
+		feasible bool
+	}{
+		{deadline: arrival.Add(time.Hour), feasible: true},
+		{deadline: arrival, feasible: true},
+		{deadline: arrival.Add(-time.Hour), feasible: false},
+	}
+
+	for _, tt := range tests {
+		f, err := s.CheckDeadlineFeasibility(context.Background(), shipping.DEHAM, shipping.SESTO, tt.deadline)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Feasible != tt.feasible {
+			t.Errorf("deadline %v: f.Feasible = %v; want = %v", tt.deadline, f.Feasible, tt.feasible)
+		}
+		if f.EarliestArrival == nil || !f.EarliestArrival.Equal(arrival) {
+			t.Errorf("deadline %v: f.EarliestArrival = %v; want = %v", tt.deadline, f.EarliestArrival, arrival)
+		}
+	}
+
+	if _, err := s.CheckDeadlineFeasibility(context.Background(), shipping.DEHAM, "XXXXX", arrival); err != shipping.ErrUnknownLocation {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownLocation)
+	}
+}
+
+func TestAssignCargoToRoute(t *testing.T) {
+	var cargos mockCargoRepository
+
+	var rs stubRoutingService
+
+	s := NewService(&cargos, nil, nil, &rs)
+
+	var (
+		origin      = shipping.SESTO
+		destination = shipping.AUMEL
+		deadline    = time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
+	)
+
+	id, err := s.BookNewCargo(context.Background(), "", origin, destination, deadline, shipping.Container20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i := s.RequestPossibleRoutesForCargo(context.Background(), id).Itineraries
+
+	if len(i) != 1 {
+		t.Errorf("len(i) = %d; want = %d", len(i), 1)
+	}
+
+	if err := s.AssignCargoToRoute(context.Background(), id, i[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.AssignCargoToRoute(context.Background(), "no_such_id", shipping.Itinerary{}); err != ErrInvalidArgument {
+		t.Errorf("err = %s; want = %s", err, ErrInvalidArgument)
+	}
+}
+
+func TestReplaceLeg(t *testing.T) {
+	var (
+		t0  = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		day = 24 * time.Hour
+	)
+
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{
+		Origin:          shipping.CNHKG,
+		Destination:     shipping.SESTO,
+		ArrivalDeadline: t0.Add(30 * day),
+		DeadlineType:    shipping.HardDeadline,
+	})
+	c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+		shipping.NewLeg("V100", shipping.CNHKG, shipping.DEHAM, t0, t0.Add(20*day)),
+		shipping.NewLeg("V200", shipping.DEHAM, shipping.SESTO, t0.Add(22*day), t0.Add(24*day)),
+	}})
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return c, nil
+	}
+	cargos.StoreFn = func(c *shipping.Cargo) error {
+		return nil
+	}
+
+	s := NewService(&cargos, nil, nil, nil)
+
+	ctx := context.Background()
+
+	if err := s.ReplaceLeg(ctx, c.TrackingID, 1, shipping.NewLeg("V250", shipping.DEHAM, shipping.SESTO, t0.Add(21*day), t0.Add(31*day))); err != shipping.ErrArrivalDeadlineMissed {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrArrivalDeadlineMissed)
+	}
+	if cargos.StoreInvoked {
+		t.Error("cargo stored although the substitute is late")
+	}
+
+	// A soft deadline may be missed.
+	c.RouteSpecification.DeadlineType = shipping.SoftDeadline
+
+	if err := s.ReplaceLeg(ctx, c.TrackingID, 1, shipping.NewLeg("V250", shipping.DEHAM, shipping.SESTO, t0.Add(21*day), t0.Add(31*day))); err != nil {
+		t.Fatal(err)
+	}
+
+	c.RouteSpecification.DeadlineType = shipping.HardDeadline
+
+	if err := s.ReplaceLeg(ctx, c.TrackingID, 1, shipping.NewLeg("V250", shipping.DEHAM, shipping.SESTO, t0.Add(21*day), t0.Add(25*day))); err != nil {
+		t.Fatal(err)
+	}
+	if l := c.Itinerary.Legs[1]; l.VoyageNumber != "V250" || l.Status != shipping.LegPending {
+		t.Errorf("c.Itinerary.Legs[1] = %+v; want V250 pending confirmation", l)
+	}
+	if c.Itinerary.Legs[0].VoyageNumber != "V100" {
+		t.Errorf("c.Itinerary.Legs[0] = %+v; want it left in place", c.Itinerary.Legs[0])
+	}
+
+	// Once loaded, the first leg can no longer be replaced.
+	c.DeriveDeliveryProgress(shipping.HandlingHistory{HandlingEvents: []shipping.HandlingEvent{
+		{Activity: shipping.HandlingActivity{Type: shipping.Load, Location: shipping.CNHKG, VoyageNumber: "V100"}, Completed: t0},
+	}})
+
+	if err := s.ReplaceLeg(ctx, c.TrackingID, 0, shipping.NewLeg("V150", shipping.CNHKG, shipping.DEHAM, t0, t0.Add(19*day))); err != shipping.ErrLegHandled {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrLegHandled)
+	}
+}
+
+// recordingHandler records the cargos booked, rolled and transferred.
+type recordingHandler struct {
+	booked      []shipping.TrackingID
+	rolled      []shipping.VoyageNumber
+	transferred []shipping.CustomerID
+}
+
+func (h *recordingHandler) CargoWasBooked(ctx context.Context, c *shipping.Cargo) {
+	h.booked = append(h.booked, c.TrackingID)
+}
+
+func (h *recordingHandler) CargoWasRouted(context.Context, *shipping.Cargo) {}
+
+func (h *recordingHandler) CargoWasRolled(ctx context.Context, c *shipping.Cargo, from shipping.VoyageNumber) {
+	h.rolled = append(h.rolled, from)
+}
+
+func (h *recordingHandler) CargoWasTransferred(ctx context.Context, c *shipping.Cargo, from shipping.CustomerID) {
+	h.transferred = append(h.transferred, from)
+}
+
+func TestRollCargo(t *testing.T) {
+	var (
+		t0  = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		day = 24 * time.Hour
+	)
+
+	voyage := func(n shipping.VoyageNumber, from, to shipping.UNLocode, depart, arrive time.Time) *shipping.Voyage {
+		return shipping.NewVoyage(n, shipping.Schedule{CarrierMovements: []shipping.CarrierMovement{
+			{DepartureLocation: from, ArrivalLocation: to, DepartureTime: depart, ArrivalTime: arrive},
+		}})
+	}
+
+	all := []*shipping.Voyage{
+		voyage("V100", shipping.CNHKG, shipping.DEHAM, t0, t0.Add(20*day)),
+		voyage("V101", shipping.CNHKG, shipping.DEHAM, t0.Add(7*day), t0.Add(27*day)),
+		voyage("V200", shipping.DEHAM, shipping.SESTO, t0.Add(22*day), t0.Add(24*day)),
+		voyage("V201", shipping.DEHAM, shipping.SESTO, t0.Add(29*day), t0.Add(31*day)),
+	}
+
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(n shipping.VoyageNumber) (*shipping.Voyage, error) {
+		for _, v := range all {
+			if v.VoyageNumber == n {
+				return v, nil
+			}
+		}
+		return nil, shipping.ErrUnknownVoyage
+	}
+
+	var services mock.ServiceStringRepository
+	services.FindAllFn = func() []*shipping.ServiceString {
+		return []*shipping.ServiceString{
+			{Code: "AE1", Voyages: []shipping.VoyageNumber{"V100", "V101"}},
+			{Code: "NE1", Voyages: []shipping.VoyageNumber{"V200", "V201"}},
+		}
+	}
+
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{
+		Origin:          shipping.CNHKG,
+		Destination:     shipping.SESTO,
+		ArrivalDeadline: t0.Add(40 * day),
+	})
+	c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+		shipping.NewLeg("V100", shipping.CNHKG, shipping.DEHAM, t0, t0.Add(20*day)),
+		shipping.NewLeg("V200", shipping.DEHAM, shipping.SESTO, t0.Add(22*day), t0.Add(24*day)),
+	}})
+
+	var stored *shipping.Cargo
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return c, nil
+	}
+	cargos.ForEachFn = func(fn func(*shipping.Cargo) error) error {
+		return nil
+	}
+	cargos.StoreFn = func(c *shipping.Cargo) error {
+		stored = c
+		return nil
+	}
+
+	var handler recordingHandler
+
+	s := NewService(&cargos, nil, nil, nil,
+		WithVoyages(&voyages),
+		WithServiceStrings(&services),
+		WithEventHandler(&handler),
+		WithConnectionTimes(shipping.ConnectionTimes{Default: 12 * time.Hour}),
+	)
+
+	ctx := context.Background()
+
+	if err := s.RollCargo(ctx, c.TrackingID, "V999"); err != shipping.ErrUnknownVoyage {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownVoyage)
+	}
+
+	if err := s.RollCargo(ctx, c.TrackingID, "V101"); err != nil {
+		t.Fatal(err)
+	}
+	if stored == nil {
+		t.Fatal("rolled cargo should be stored")
+	}
+
+	legs := stored.Itinerary.Legs
+	if legs[0].VoyageNumber != "V101" || legs[1].VoyageNumber != "V201" {
+		t.Errorf("legs = %+v; want V101, V201", legs)
+	}
+	if stored.Rollovers != 1 {
+		t.Errorf("Rollovers = %d; want = %d", stored.Rollovers, 1)
+	}
+	if c.Itinerary.Legs[0].VoyageNumber != "V100" {
+		t.Errorf("cargo should be rolled as a copy")
+	}
+	if want := []shipping.VoyageNumber{"V100"}; !reflect.DeepEqual(handler.rolled, want) {
+		t.Errorf("rolled = %v; want = %v", handler.rolled, want)
+	}
+
+	// Rolled onto V101, the cargo misses a hard deadline.
+	stored = nil
+	c.RouteSpecification.ArrivalDeadline = t0.Add(30 * day)
+	c.RouteSpecification.DeadlineType = shipping.HardDeadline
+
+	if err := s.RollCargo(ctx, c.TrackingID, "V101"); err != shipping.ErrArrivalDeadlineMissed {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrArrivalDeadlineMissed)
+	}
+	if stored != nil {
+		t.Error("cargo stored although it arrives late")
+	}
+
+	// No sailing of V200's service string is left to connect with.
+	all = all[:3]
+
+	if err := s.RollCargo(ctx, c.TrackingID, "V101"); err != shipping.ErrNoLaterSailing {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrNoLaterSailing)
+	}
+}
+
+func TestTransferCargo(t *testing.T) {
+	var customers mock.CustomerRepository
+	customers.FindFn = func(id shipping.CustomerID) (*shipping.Customer, error) {
+		if id != "INITECH" {
+			return nil, shipping.ErrUnknownCustomer
+		}
+		return &shipping.Customer{ID: id, Name: "Initech"}, nil
+	}
+
+	var (
+		cargos   mockCargoRepository
+		screener stubScreener
+		handler  recordingHandler
+	)
+
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{
+		Origin:      shipping.SESTO,
+		Destination: shipping.AUMEL,
+	})
+	c.Customer = "ACME"
+	cargos.Store(c)
+
+	s := NewService(&cargos, nil, nil, nil, WithScreening(&screener, &customers), WithEventHandler(&handler))
+
+	ctx := context.Background()
+
+	if err := s.TransferCargo(ctx, "ABC", "ACME", "ACME"); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+	if err := s.TransferCargo(ctx, "ABC", "GLOBEX", "INITECH"); err != shipping.ErrNotCargoOwner {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrNotCargoOwner)
+	}
+	if err := s.TransferCargo(ctx, "ABC", "ACME", "GLOBEX"); err != shipping.ErrUnknownCustomer {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownCustomer)
+	}
+
+	screener.result = shipping.ScreeningResult{Decision: shipping.ScreeningBlocked}
+	if err := s.TransferCargo(ctx, "ABC", "ACME", "INITECH"); err != shipping.ErrScreeningBlocked {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrScreeningBlocked)
+	}
+	if len(handler.transferred) != 0 {
+		t.Errorf("blocked transfer should not be handled")
+	}
+
+	if c.Customer != "ACME" {
+		t.Errorf("blocked transfer should leave the cargo untouched")
+	}
+
+	screener.result = shipping.ScreeningResult{Decision: shipping.ScreeningClear}
+	if err := s.TransferCargo(ctx, "ABC", "ACME", "INITECH"); err != nil {
+		t.Fatal(err)
+	}
+	if cargos.cargo.Customer != "INITECH" {
+		t.Errorf("Customer = %s; want = %s", cargos.cargo.Customer, "INITECH")
+	}
+	if screener.got.CustomerName != "Initech" {
+		t.Errorf("screened %q; want the new owner", screener.got.CustomerName)
+	}
+	if len(handler.transferred) != 1 || handler.transferred[0] != "ACME" {
+		t.Errorf("transferred = %v; want = [ACME]", handler.transferred)
+	}
+}
+
+func TestRerouteAllVia(t *testing.T) {
+	var (
+		t0  = time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+		day = 24 * time.Hour
+	)
+
+	viaHamburg := []shipping.Leg{
+		shipping.NewLeg("V100", shipping.CNHKG, shipping.DEHAM, t0, t0.Add(20*day)),
+		shipping.NewLeg("V200", shipping.DEHAM, shipping.SESTO, t0.Add(21*day), t0.Add(22*day)),
+	}
+	viaRotterdam := []shipping.Leg{
+		shipping.NewLeg("V300", shipping.CNHKG, shipping.NLRTM, t0, t0.Add(19*day)),
+		shipping.NewLeg("V400", shipping.NLRTM, shipping.SESTO, t0.Add(20*day), t0.Add(23*day)),
+	}
+
+	cargo := func(id shipping.TrackingID, destination shipping.UNLocode, legs []shipping.Leg) *shipping.Cargo {
+		c := shipping.NewCargo(id, shipping.RouteSpecification{
+			Origin:          shipping.CNHKG,
+			Destination:     destination,
+			ArrivalDeadline: t0.Add(30 * day),
+		})
+		c.AssignToRoute(shipping.Itinerary{Legs: legs})
+		return c
+	}
+
+	var (
+		pending    = cargo("AAA", shipping.SESTO, viaHamburg)
+		onboard    = cargo("BBB", shipping.SESTO, viaHamburg)
+		stranded   = cargo("CCC", shipping.DEHAM, viaHamburg[:1])
+		unaffected = cargo("DDD", shipping.SESTO, viaRotterdam)
+	)
+
+	onboard.DeriveDeliveryProgress(shipping.HandlingHistory{HandlingEvents: []shipping.HandlingEvent{
+		{Activity: shipping.HandlingActivity{Type: shipping.Load, Location: shipping.CNHKG, VoyageNumber: "V100"}, Completed: t0},
+	}})
+
+	all := []*shipping.Cargo{pending, onboard, stranded, unaffected}
+
+	stored := make(map[shipping.TrackingID]*shipping.Cargo)
+
+	var cargos mock.CargoRepository
+	cargos.ForEachFn = func(fn func(*shipping.Cargo) error) error {
+		for _, c := range all {
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	cargos.StoreFn = func(c *shipping.Cargo) error {
+		stored[c.TrackingID] = c
+		return nil
+	}
+
+	var locations mock.LocationRepository
+	locations.FindFn = func(l shipping.UNLocode) (*shipping.Location, error) {
+		if l != shipping.DEHAM {
+			return nil, shipping.ErrUnknownLocation
+		}
+		return shipping.Hamburg, nil
+	}
+
+	var rs mock.RoutingService
+	rs.FetchRoutesFn = func(spec shipping.RouteSpecification) []shipping.Itinerary {
+		if spec.Destination == shipping.DEHAM {
+			return []shipping.Itinerary{{Legs: viaHamburg[:1]}}
+		}
+		return []shipping.Itinerary{{Legs: viaHamburg}, {Legs: viaRotterdam}}
+	}
+
+	s := NewService(&cargos, &locations, nil, &rs)
+
+	ctx := context.Background()
+
+	if _, err := s.RerouteAllVia(ctx, shipping.DEHAM, t0.Add(day), t0); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+	if _, err := s.RerouteAllVia(ctx, shipping.FIHEL, t0, t0.Add(day)); err != shipping.ErrUnknownLocation {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownLocation)
+	}
+
+	// The port is closed while the cargos are to be unloaded there.
+	r, err := s.RerouteAllVia(ctx, shipping.DEHAM, t0.Add(19*day), t0.Add(25*day))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Rerouted) != 1 || r.Rerouted[0].TrackingID != "AAA" || r.Rerouted[0].Legs[0].VoyageNumber != "V300" {
+		t.Errorf("rerouted = %+v; want AAA via Rotterdam", r.Rerouted)
+	}
+	if len(r.NeedsReview) != 1 || r.NeedsReview[0].TrackingID != "BBB" {
+		t.Errorf("needs review = %+v; want BBB, which is in transit", r.NeedsReview)
+	}
+	if len(r.Infeasible) != 1 || r.Infeasible[0].TrackingID != "CCC" {
+		t.Errorf("infeasible = %+v; want CCC, which is bound for the port", r.Infeasible)
+	}
+
+	if len(stored) != 1 || stored["AAA"] == nil {
+		t.Fatalf("stored = %v; want only AAA", stored)
+	}
+	if legs := stored["AAA"].Itinerary.Legs; legs[0].Status != shipping.LegPending {
+		t.Errorf("new route should await confirmation by the carriers")
+	}
+}
+
+func TestAssignCargoToRoute_ConnectionTooShort(t *testing.T) {
+	var cargos mockCargoRepository
+
+	s := NewService(&cargos, nil, nil, nil, WithConnectionTimes(shipping.ConnectionTimes{Default: 2 * time.Hour}))
+
+	id, err := s.BookNewCargo(context.Background(), "", shipping.CNHKG, shipping.SESTO, time.Now().AddDate(0, 0, 30), shipping.Container20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unload := time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	itinerary := shipping.Itinerary{Legs: []shipping.Leg{
+		shipping.NewLeg("V300", shipping.CNHKG, shipping.DEHAM, unload.Add(-240*time.Hour), unload),
+		shipping.NewLeg("V400", shipping.DEHAM, shipping.SESTO, unload.Add(time.Hour), unload.Add(24*time.Hour)),
+	}}
+
+	if err := s.AssignCargoToRoute(context.Background(), id, itinerary); err != shipping.ErrConnectionTooShort {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrConnectionTooShort)
+	}
+	if !cargos.cargo.Itinerary.IsEmpty() {
+		t.Errorf("itinerary should not have been assigned")
+	}
+}
+
+func TestAssignCargoToRoute_OffSchedule(t *testing.T) {
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(n shipping.VoyageNumber) (*shipping.Voyage, error) {
+		if n != shipping.V100.VoyageNumber {
+			return nil, shipping.ErrUnknownVoyage
+		}
+		return shipping.V100, nil
+	}
+
+	var cargos mockCargoRepository
+
+	s := NewService(&cargos, nil, nil, nil, WithVoyages(&voyages), WithScheduleValidation(time.Hour))
+
+	id, err := s.BookNewCargo(context.Background(), "", shipping.CNHKG, shipping.USNYC, time.Now().AddDate(0, 0, 30), shipping.Container20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheduled, err := shipping.V100.Leg(shipping.CNHKG, shipping.USNYC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	late := scheduled
+	late.LoadTime = late.LoadTime.Add(24 * time.Hour)
+
+	for _, tt := range []struct {
+		leg  shipping.Leg
+		want error
+	}{
+		{leg: late, want: shipping.ErrLegOffSchedule},
+		{leg: shipping.NewLeg("V100", shipping.CNHKG, shipping.SESTO, scheduled.LoadTime, scheduled.UnloadTime), want: shipping.ErrPortNotCalled},
+	} {
+		if err := s.AssignCargoToRoute(context.Background(), id, shipping.Itinerary{Legs: []shipping.Leg{tt.leg}}); err != tt.want {
+			t.Errorf("err = %v; want = %v", err, tt.want)
+		}
+	}
+	if !cargos.cargo.Itinerary.IsEmpty() {
+		t.Fatalf("itinerary should not have been assigned")
+	}
+
+	// Times left out are taken from the schedule.
+	untimed := shipping.Leg{VoyageNumber: "V100", LoadLocation: shipping.CNHKG, UnloadLocation: shipping.USNYC}
+	if err := s.AssignCargoToRoute(context.Background(), id, shipping.Itinerary{Legs: []shipping.Leg{untimed}}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := cargos.cargo.Itinerary.Legs[0]
+	if !got.LoadTime.Equal(scheduled.LoadTime) || !got.UnloadTime.Equal(scheduled.UnloadTime) {
+		t.Errorf("leg = %s-%s; want = %s-%s", got.LoadTime, got.UnloadTime, scheduled.LoadTime, scheduled.UnloadTime)
+	}
+}
+
+func TestAssignCargoToRoute_CapacityExceeded(t *testing.T) {
+	voyage := &shipping.Voyage{
+		VoyageNumber: "V100",
+		Capacity:     []shipping.Capacity{{Equipment: shipping.Reefer, Units: 1}},
+	}
+
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(n shipping.VoyageNumber) (*shipping.Voyage, error) {
+		return voyage, nil
+	}
+
+	itinerary := shipping.Itinerary{Legs: []shipping.Leg{
+		{VoyageNumber: "V100", LoadLocation: shipping.CNHKG, UnloadLocation: shipping.SESTO},
+	}}
+
+	rs := shipping.RouteSpecification{Origin: shipping.CNHKG, Destination: shipping.SESTO}
+
+	booked := shipping.NewCargo("ABC", rs)
+	booked.Equipment = shipping.Reefer
+	booked.AssignToRoute(itinerary)
+
+	reefer := shipping.NewCargo("DEF", rs)
+	reefer.Equipment = shipping.Reefer
+
+	dry := shipping.NewCargo("GHI", rs)
+
+	all := []*shipping.Cargo{booked, reefer, dry}
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		for _, c := range all {
+			if c.TrackingID == id {
+				return c, nil
+			}
+		}
+		return nil, shipping.ErrUnknownCargo
+	}
+	cargos.ForEachFn = func(fn func(*shipping.Cargo) error) error {
+		for _, c := range all {
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	cargos.StoreFn = func(c *shipping.Cargo) error {
+		return nil
+	}
+
+	s := NewService(&cargos, nil, nil, nil, WithVoyages(&voyages))
+
+	if err := s.AssignCargoToRoute(context.Background(), reefer.TrackingID, itinerary); err != shipping.ErrCapacityExceeded {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrCapacityExceeded)
+	}
+	if err := s.AssignCargoToRoute(context.Background(), dry.TrackingID, itinerary); err != shipping.ErrCapacityExceeded {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrCapacityExceeded)
+	}
+
+	// Rerouting a cargo onto the same voyage does not count it twice.
+	if err := s.AssignCargoToRoute(context.Background(), booked.TrackingID, itinerary); err != nil {
+		t.Errorf("err = %v; want = nil", err)
+	}
+
+	// Overbooking the service string of the voyage makes room for another
+	// reefer.
+	var services mock.ServiceStringRepository
+	services.FindAllFn = func() []*shipping.ServiceString {
+		return []*shipping.ServiceString{{Code: "TP1", Voyages: []shipping.VoyageNumber{"V100"}}}
+	}
+
+	s = NewService(&cargos, nil, nil, nil,
+		WithVoyages(&voyages),
+		WithServiceStrings(&services),
+		WithOverbookingPolicy(shipping.OverbookingPolicy{
+			Services: map[shipping.ServiceCode]float64{"TP1": 100},
+		}),
+	)
+
+	if err := s.AssignCargoToRoute(context.Background(), reefer.TrackingID, itinerary); err != nil {
+		t.Errorf("err = %v; want = nil", err)
+	}
+}
+
+func TestCapacityForecast(t *testing.T) {
+	departure := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule := shipping.Schedule{CarrierMovements: []shipping.CarrierMovement{
+		{DepartureLocation: shipping.CNHKG, ArrivalLocation: shipping.SESTO, DepartureTime: departure},
+	}}
+
+	var voyages mock.VoyageRepository
+	voyages.FindAllFn = func() []*shipping.Voyage {
+		return []*shipping.Voyage{
+			{VoyageNumber: "V100", Schedule: schedule, Capacity: []shipping.Capacity{
+				{Equipment: shipping.Container20, Units: 4},
+				{Equipment: shipping.Reefer, Units: 2},
+			}},
+			{VoyageNumber: "V200", Schedule: schedule},
+		}
+	}
+
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{Origin: shipping.CNHKG, Destination: shipping.SESTO})
+	c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+		{VoyageNumber: "V100", LoadLocation: shipping.CNHKG, UnloadLocation: shipping.SESTO},
+	}})
+
+	var cargos mock.CargoRepository
+	cargos.ForEachFn = func(fn func(*shipping.Cargo) error) error {
+		return fn(c)
+	}
+
+	s := NewService(&cargos, nil, nil, nil, WithVoyages(&voyages))
+
+	got, err := s.CapacityForecast(context.Background(), departure.AddDate(0, 0, -1), departure.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0].VoyageNumber != "V100" {
+		t.Fatalf("got = %v; want the utilization of V100 only", got)
+	}
+
+	want := []EquipmentUtilization{
+		{Type: "20ft", Capacity: 4, Limit: 4, Booked: 1, Percent: 25},
+		{Type: "Reefer", Capacity: 2, Limit: 2, Booked: 0, Percent: 0},
+	}
+	if !reflect.DeepEqual(got[0].Equipment, want) {
+		t.Errorf("Equipment = %v; want = %v", got[0].Equipment, want)
+	}
+
+	if _, err := s.CapacityForecast(context.Background(), departure, departure); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestOverbookingReport(t *testing.T) {
+	departure := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule := shipping.Schedule{CarrierMovements: []shipping.CarrierMovement{
+		{DepartureLocation: shipping.CNHKG, ArrivalLocation: shipping.SESTO, DepartureTime: departure},
+	}}
+
+	var voyages mock.VoyageRepository
+	voyages.FindAllFn = func() []*shipping.Voyage {
+		return []*shipping.Voyage{
+			{VoyageNumber: "V100", Schedule: schedule, Capacity: []shipping.Capacity{
+				{Equipment: shipping.Container20, Units: 4},
+				{Equipment: shipping.Reefer, Units: 1},
+			}},
+			{VoyageNumber: "V200", Schedule: schedule, Capacity: []shipping.Capacity{
+				{Equipment: shipping.Reefer, Units: 1},
+			}},
+		}
+	}
+
+	leg := func(n shipping.VoyageNumber, status shipping.LegStatus) shipping.Leg {
+		return shipping.Leg{VoyageNumber: n, LoadLocation: shipping.CNHKG, UnloadLocation: shipping.SESTO, Status: status}
+	}
+
+	var all []*shipping.Cargo
+	for _, tt := range []struct {
+		id  shipping.TrackingID
+		leg shipping.Leg
+	}{
+		{id: "ABC", leg: leg("V100", shipping.LegConfirmed)},
+		{id: "DEF", leg: leg("V100", shipping.LegConfirmed)},
+		{id: "GHI", leg: leg("V200", shipping.LegConfirmed)},
+		{id: "JKL", leg: leg("V200", shipping.LegPending)},
+	} {
+		c := shipping.NewCargo(tt.id, shipping.RouteSpecification{Origin: shipping.CNHKG, Destination: shipping.SESTO})
+		c.Equipment = shipping.Reefer
+		c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{tt.leg}})
+		all = append(all, c)
+	}
+
+	var cargos mock.CargoRepository
+	cargos.ForEachFn = func(fn func(*shipping.Cargo) error) error {
+		for _, c := range all {
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	s := NewService(&cargos, nil, nil, nil,
+		WithVoyages(&voyages),
+		WithOverbookingPolicy(shipping.OverbookingPolicy{Default: 100}),
+	)
+
+	got, err := s.OverbookingReport(context.Background(), departure.AddDate(0, 0, -1), departure.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0].VoyageNumber != "V100" {
+		t.Fatalf("got = %v; want V100 only, as the booking on V200 is not confirmed", got)
+	}
+
+	want := []EquipmentUtilization{
+		{Type: "Reefer", Capacity: 1, Limit: 2, Booked: 2, Percent: 200},
+	}
+	if !reflect.DeepEqual(got[0].Equipment, want) {
+		t.Errorf("Equipment = %v; want = %v", got[0].Equipment, want)
+	}
+	if want := []string{"ABC", "DEF"}; !reflect.DeepEqual(got[0].Cargos, want) {
+		t.Errorf("Cargos = %v; want = %v", got[0].Cargos, want)
+	}
+
+	if _, err := s.OverbookingReport(context.Background(), departure, departure); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestChangeCargoDestination(t *testing.T) {
+	var cargos mockCargoRepository
+	var locations mock.LocationRepository
+
+	locations.FindFn = func(loc shipping.UNLocode) (*shipping.Location, error) {
+		if loc != shipping.AUMEL {
+			return nil, shipping.ErrUnknownLocation
+		}
+		return shipping.Melbourne, nil
+	}
+
+	var rs stubRoutingService
+
+	s := NewService(&cargos, &locations, nil, &rs)
+
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{
+		Origin:          shipping.SESTO,
+		Destination:     shipping.CNHKG,
+		ArrivalDeadline: time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC),
+	})
+
+	if err := s.ChangeDestination(context.Background(), "no_such_id", shipping.SESTO); err != shipping.ErrUnknownCargo {
+		t.Errorf("err = %s; want = %s", err, shipping.ErrUnknownCargo)
+	}
+
+	if err := cargos.Store(c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.ChangeDestination(context.Background(), c.TrackingID, "no_such_unlocode"); err != shipping.ErrUnknownLocation {
+		t.Errorf("err = %s; want = %s", err, shipping.ErrUnknownLocation)
+	}
+
+	if c.RouteSpecification.Destination != shipping.CNHKG {
+		t.Errorf("c.RouteSpecification.Destination = %s; want = %s",
+			c.RouteSpecification.Destination, shipping.CNHKG)
+	}
+
+	if err := s.ChangeDestination(context.Background(), c.TrackingID, shipping.AUMEL); err != nil {
+		t.Fatal(err)
+	}
+
+	uc, err := cargos.Find(c.TrackingID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uc.RouteSpecification.Destination != shipping.AUMEL {
+		t.Errorf("uc.RouteSpecification.Destination = %s; want = %s",
+			uc.RouteSpecification.Destination, shipping.AUMEL)
+	}
+}
+
+func TestChangeLoadedCargoDestination(t *testing.T) {
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{
+		Origin:      shipping.SESTO,
+		Destination: shipping.CNHKG,
+	})
+	c.Delivery.TransportStatus = shipping.OnboardCarrier
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return c, nil
+	}
+
+	var locations mock.LocationRepository
+	locations.FindFn = func(loc shipping.UNLocode) (*shipping.Location, error) {
+		return shipping.Melbourne, nil
+	}
+
+	s := NewService(&cargos, &locations, nil, nil)
+
+	if err := s.ChangeDestination(context.Background(), c.TrackingID, shipping.AUMEL); err != shipping.ErrApprovalRequired {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrApprovalRequired)
+	}
+
+	if cargos.StoreInvoked {
+		t.Error("loaded cargo changed without approval")
+	}
+}
+
+func TestDeclareCargo(t *testing.T) {
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{
+		Origin:      shipping.SESTO,
+		Destination: shipping.AUMEL,
+	})
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return c, nil
+	}
+	cargos.StoreFn = func(c *shipping.Cargo) error {
+		return nil
+	}
+
+	var locations mock.LocationRepository
+	locations.FindFn = func(loc shipping.UNLocode) (*shipping.Location, error) {
+		return &shipping.Location{UNLocode: loc}, nil
+	}
+
+	restrictions := shipping.ImportRestrictions{
+		"AU": {
+			Country:  "AU",
+			Required: []shipping.DocumentRequirement{{Commodity: "06", Document: "phytosanitary_certificate"}},
+		},
+	}
+
+	s := NewService(&cargos, &locations, nil, nil, WithImportRestrictions(restrictions))
+
+	flowers := shipping.CargoDeclaration{Commodities: []shipping.CommodityCode{"060311"}}
+
+	err := s.DeclareCargo(context.Background(), c.TrackingID, flowers)
+	if vs, ok := err.(shipping.ImportViolations); !ok || len(vs) != 1 {
+		t.Fatalf("err = %v; want one violation", err)
+	}
+	if cargos.StoreInvoked {
+		t.Error("cargo stored with violations")
+	}
+
+	flowers.Documents = []shipping.DocumentType{"phytosanitary_certificate"}
+	if err := s.DeclareCargo(context.Background(), c.TrackingID, flowers); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Declaration.Documents) != 1 {
+		t.Errorf("c.Declaration = %+v; want = %+v", c.Declaration, flowers)
+	}
+
+	// Changing the destination back to Australia is checked against the
+	// declaration as well.
+	c.RouteSpecification.Destination = shipping.SESTO
+	c.Declaration = shipping.CargoDeclaration{Commodities: []shipping.CommodityCode{"060311"}}
+	cargos.StoreInvoked = false
+
+	if _, ok := s.ChangeDestination(context.Background(), c.TrackingID, shipping.AUMEL).(shipping.ImportViolations); !ok {
+		t.Error("destination changed in violation of import restrictions")
+	}
+	if cargos.StoreInvoked {
+		t.Error("cargo stored with violations")
+	}
+}
+
+func TestRejectCargo(t *testing.T) {
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{
+		Origin:      shipping.SESTO,
+		Destination: shipping.CNHKG,
+	})
+
+	stored := make(map[shipping.TrackingID]*shipping.Cargo)
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return c, nil
+	}
+	cargos.StoreFn = func(c *shipping.Cargo) error {
+		stored[c.TrackingID] = c
+		return nil
+	}
+
+	s := NewService(&cargos, nil, nil, nil)
+
+	deadline := time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
+
+	if _, err := s.RejectCargo(context.Background(), c.TrackingID, deadline); err != shipping.ErrNotDelivered {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrNotDelivered)
+	}
+
+	c.Delivery.IsUnloadedAtDestination = true
+
+	id, err := s.RejectCargo(context.Background(), c.TrackingID, deadline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, ok := stored[id]
+	if !ok {
+		t.Fatalf("return cargo %s was not stored", id)
+	}
+	if r.Origin != shipping.CNHKG || r.RouteSpecification.Destination != shipping.SESTO {
+		t.Errorf("return route = %s-%s; want = %s-%s", r.Origin, r.RouteSpecification.Destination, shipping.CNHKG, shipping.SESTO)
+	}
+	if r.ReturnOf != c.TrackingID {
+		t.Errorf("r.ReturnOf = %s; want = %s", r.ReturnOf, c.TrackingID)
+	}
+	if stored[c.TrackingID].ReturnedBy != id {
+		t.Errorf("c.ReturnedBy = %s; want = %s", stored[c.TrackingID].ReturnedBy, id)
+	}
+
+	if _, err := s.RejectCargo(context.Background(), c.TrackingID, deadline); err != shipping.ErrAlreadyRejected {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrAlreadyRejected)
+	}
+}
+
+func TestIssueReleaseCode(t *testing.T) {
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{
+		Origin:      shipping.SESTO,
+		Destination: shipping.CNHKG,
+	})
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return c, nil
+	}
+
+	var stored *shipping.ReleaseCode
+
+	var releaseCodes mock.ReleaseCodeRepository
+	releaseCodes.StoreFn = func(rc *shipping.ReleaseCode) error {
+		stored = rc
+		return nil
+	}
+
+	if _, err := NewService(&cargos, nil, nil, nil).IssueReleaseCode(context.Background(), c.TrackingID); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+
+	s := NewService(&cargos, nil, nil, nil, WithReleaseCodes(&releaseCodes))
+
+	pin, err := s.IssueReleaseCode(context.Background(), c.TrackingID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored == nil || stored.TrackingID != c.TrackingID {
+		t.Fatalf("stored = %+v; want the release code of %s", stored, c.TrackingID)
+	}
+	if err := stored.Verify(pin); err != nil {
+		t.Errorf("Verify(%q) = %v", pin, err)
+	}
+
+	// Issuing the code again replaces a locked one.
+	stored.Attempts = shipping.MaxReleaseAttempts
+	if _, err := s.IssueReleaseCode(context.Background(), c.TrackingID); err != nil {
+		t.Fatal(err)
+	}
+	if stored.IsLocked() {
+		t.Errorf("the release code should no longer be locked")
+	}
+
+	c.Delivery.TransportStatus = shipping.Claimed
+
+	if _, err := s.IssueReleaseCode(context.Background(), c.TrackingID); err != shipping.ErrAlreadyClaimed {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrAlreadyClaimed)
+	}
+}
+
+func TestOmitPortCall(t *testing.T) {
+	voyage := &shipping.Voyage{
+		VoyageNumber: "V100",
+		Schedule: shipping.Schedule{CarrierMovements: []shipping.CarrierMovement{
+			{DepartureLocation: shipping.CNHKG, ArrivalLocation: shipping.DEHAM},
+			{DepartureLocation: shipping.DEHAM, ArrivalLocation: shipping.SESTO},
+		}},
+	}
+
+	var stored *shipping.Voyage
+
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(n shipping.VoyageNumber) (*shipping.Voyage, error) {
+		if n != voyage.VoyageNumber {
+			return nil, shipping.ErrUnknownVoyage
+		}
+		return voyage, nil
+	}
+	voyages.StoreFn = func(v *shipping.Voyage) error {
+		stored = v
+		return nil
+	}
+
+	rs := shipping.RouteSpecification{Origin: shipping.CNHKG, Destination: shipping.SESTO}
+
+	affected := shipping.NewCargo("ABC", rs)
+	affected.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+		{VoyageNumber: "V100", LoadLocation: shipping.CNHKG, UnloadLocation: shipping.DEHAM},
+		{VoyageNumber: "V200", LoadLocation: shipping.DEHAM, UnloadLocation: shipping.SESTO},
+	}})
+
+	unaffected := shipping.NewCargo("DEF", rs)
+	unaffected.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+		{VoyageNumber: "V100", LoadLocation: shipping.CNHKG, UnloadLocation: shipping.SESTO},
+	}})
+
+	var cargos mock.CargoRepository
+	cargos.ForEachFn = func(fn func(*shipping.Cargo) error) error {
+		for _, c := range []*shipping.Cargo{affected, unaffected} {
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	cargos.StoreFn = func(c *shipping.Cargo) error {
+		return nil
+	}
+
+	var routing mock.RoutingService
+	routing.FetchRoutesFn = func(spec shipping.RouteSpecification) []shipping.Itinerary {
+		if spec != rs {
+			t.Errorf("spec = %v; want = %v", spec, rs)
+		}
+		return []shipping.Itinerary{}
+	}
+
+	s := NewService(&cargos, nil, nil, &routing, WithVoyages(&voyages))
+
+	ids, err := s.OmitPortCall(context.Background(), "V100", shipping.DEHAM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ids) != 1 || ids[0] != affected.TrackingID {
+		t.Errorf("ids = %v; want = [%s]", ids, affected.TrackingID)
+	}
+	if affected.Delivery.RoutingStatus != shipping.Misrouted {
+		t.Errorf("RoutingStatus = %v; want = %v", affected.Delivery.RoutingStatus, shipping.Misrouted)
+	}
+	if unaffected.Delivery.RoutingStatus != shipping.Routed {
+		t.Errorf("RoutingStatus = %v; want = %v", unaffected.Delivery.RoutingStatus, shipping.Routed)
+	}
+
+	if stored == nil || stored.Schedule.CallsAt(shipping.DEHAM) {
+		t.Errorf("voyage was not stored without the port call")
+	}
+	if !voyage.Schedule.CallsAt(shipping.DEHAM) {
+		t.Errorf("the voyage found was modified")
+	}
+
+	if !routing.FetchRoutesInvoked {
+		t.Errorf("expected routes to be requested for the affected cargo")
+	}
+
+	if _, err := s.OmitPortCall(context.Background(), "V100", shipping.AUMEL); err != shipping.ErrPortNotCalled {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrPortNotCalled)
+	}
+	if _, err := s.OmitPortCall(context.Background(), "V999", shipping.DEHAM); err != shipping.ErrUnknownVoyage {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownVoyage)
+	}
+}
+
+func TestLoadCargo(t *testing.T) {
+	deadline := time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return &shipping.Cargo{
+			TrackingID: "test_id",
+			Origin:     shipping.SESTO,
+			RouteSpecification: shipping.RouteSpecification{
+				Origin:          shipping.SESTO,
+				Destination:     shipping.AUMEL,
+				ArrivalDeadline: deadline,
+			},
+		}, nil
+	}
+
+	s := NewService(&cargos, nil, nil, nil)
+
+	c, err := s.LoadCargo(context.Background(), "test_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.TrackingID != "test_id" {
+		t.Errorf("c.TrackingID = %s; want = %s", c.TrackingID, "test_id")
+	}
+	if c.Origin != "SESTO" {
+		t.Errorf("c.Origin = %s; want = %s", c.Origin, "SESTO")
+	}
+	if c.Destination != "AUMEL" {
+		t.Errorf("c.Destination = %s; want = %s", c.Origin, "AUMEL")
+	}
+	if c.ArrivalDeadline != deadline {
+		t.Errorf("c.ArrivalDeadline = %s; want = %s", c.ArrivalDeadline, deadline)
+	}
+	if c.Misrouted {
+		t.Errorf("cargo should not be misrouted")
+	}
+	if c.Routed {
+		t.Errorf("cargo should not have been routed")
+	}
+	if len(c.Legs) != 0 {
+		t.Errorf("len(c.Legs) = %d; want = %d", len(c.Legs), 0)
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	day := func(d, h int) time.Time {
+		return time.Date(2016, time.March, d, h, 0, 0, 0, time.UTC)
+	}
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return &shipping.Cargo{
+			TrackingID: id,
+			Itinerary: shipping.Itinerary{Legs: []shipping.Leg{
+				{VoyageNumber: "V100", LoadLocation: shipping.CNHKG, UnloadLocation: shipping.SESTO, LoadTime: day(6, 12), UnloadTime: day(8, 12)},
+			}},
+		}, nil
+	}
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryFn = func(id shipping.TrackingID) shipping.HandlingHistory {
+		return shipping.HandlingHistory{HandlingEvents: []shipping.HandlingEvent{
+			{TrackingID: id, Activity: shipping.HandlingActivity{Type: shipping.Load, Location: shipping.CNHKG, VoyageNumber: "V100"}, Completed: day(6, 13)},
+		}}
+	}
+
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(n shipping.VoyageNumber) (*shipping.Voyage, error) {
+		return shipping.NewVoyage(n, shipping.Schedule{CarrierMovements: []shipping.CarrierMovement{
+			{
+				DepartureLocation:   shipping.CNHKG,
+				ArrivalLocation:     shipping.SESTO,
+				DepartureTime:       day(6, 12),
+				ArrivalTime:         day(8, 12),
+				ActualDepartureTime: day(6, 14),
+			},
+		}}), nil
+	}
+
+	var audit mock.AuditRepository
+	audit.FindFn = func(q shipping.AuditQuery) []*shipping.AuditEntry {
+		return []*shipping.AuditEntry{
+			{Time: day(1, 9), Action: shipping.AuditCargoBooked, TrackingID: q.TrackingID},
+			{Time: day(6, 15), Action: shipping.AuditHandlingEventRegistered, TrackingID: q.TrackingID},
+		}
+	}
+
+	var changes mock.CargoChangeRepository
+	changes.FindByCargoFn = func(id shipping.TrackingID) []*shipping.CargoChange {
+		return []*shipping.CargoChange{
+			{TrackingID: id, Type: shipping.CargoHandledChange, Time: day(6, 15)},
+			{TrackingID: id, Type: shipping.CargoDeadlineAtRiskChange, Time: day(7, 0), Slip: 6 * time.Hour},
+		}
+	}
+
+	var tasks mock.TaskRepository
+	tasks.FindByCargoFn = func(id shipping.TrackingID) []*shipping.Task {
+		return []*shipping.Task{shipping.NewTask(shipping.SLABreachTask, id, "breached", day(7, 1))}
+	}
+
+	s := NewService(&cargos, nil, &events, nil,
+		WithVoyages(&voyages),
+		WithTimeline(&audit, &changes, &tasks),
+	)
+
+	got, err := s.Timeline(context.Background(), "ABC123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		source, typ string
+		time        time.Time
+	}{
+		{"audit", "cargo.booked", day(1, 9)},
+		{"handling", "Load", day(6, 13)},
+		{"voyage", "departure_delayed", day(6, 14)},
+		{"inspection", "deadline_at_risk", day(7, 0)},
+		{"task", "SLA breach", day(7, 1)},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got = %+v; want %d entries", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Source != w.source || got[i].Type != w.typ || !got[i].Time.Equal(w.time) {
+			t.Errorf("got[%d] = %+v; want %s %s at %s", i, got[i], w.source, w.typ, w.time)
+		}
+	}
+
+	if _, err := s.Timeline(context.Background(), ""); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestQueryRepositories(t *testing.T) {
+	var cargos, replicaCargos mock.CargoRepository
+	replicaCargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return shipping.NewCargo(id, shipping.RouteSpecification{
+			Origin:      shipping.SESTO,
+			Destination: shipping.AUMEL,
+		}), nil
+	}
+
+	var events, replicaEvents mock.HandlingEventRepository
+	replicaEvents.QueryHandlingHistoryFn = func(id shipping.TrackingID) shipping.HandlingHistory {
+		return shipping.HandlingHistory{}
+	}
+
+	s := NewService(&cargos, nil, &events, nil, WithQueryRepositories(&replicaCargos, &replicaEvents))
+
+	if _, err := s.LoadCargo(context.Background(), "test_id"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Timeline(context.Background(), "test_id"); err != nil {
+		t.Fatal(err)
+	}
+
+	if cargos.FindInvoked {
+		t.Errorf("cargo should not be loaded from the primary repository")
+	}
+	if events.QueryHandlingHistoryInvoked {
+		t.Errorf("handling history should not be queried from the primary repository")
+	}
+	if !replicaCargos.FindInvoked || !replicaEvents.QueryHandlingHistoryInvoked {
+		t.Errorf("cargo should be loaded from the query repositories")
+	}
+}
+
+func TestCargos(t *testing.T) {
+	var ids []shipping.TrackingID
+	for i := 0; i < 3*assemblyWorkers; i++ {
+		ids = append(ids, shipping.TrackingID(fmt.Sprintf("C%02d", i)))
+	}
+
+	var cargos mock.CargoRepository
+	cargos.FindAllFn = func() []*shipping.Cargo {
+		var result []*shipping.Cargo
+		for _, id := range ids {
+			c, _ := builders.Cargo(id).Received().Build()
+			result = append(result, c)
+		}
+		return result
+	}
+
+	s := NewService(&cargos, nil, nil, nil)
+
+	result := s.Cargos(context.Background())
+
+	if len(result) != len(ids) {
+		t.Fatalf("len(result) = %d; want = %d", len(result), len(ids))
+	}
+
+	for i, c := range result {
+		if c.TrackingID != string(ids[i]) {
+			t.Errorf("result[%d].TrackingID = %s; want = %s", i, c.TrackingID, ids[i])
+		}
+		if c.TransportStatus != shipping.InPort.String() {
+			t.Errorf("result[%d].TransportStatus = %s; want = %s", i, c.TransportStatus, shipping.InPort)
+		}
+	}
+}
+
+type mockCargoRepository struct {
+	cargo *shipping.Cargo
+}
+
+func (r *mockCargoRepository) Store(c *shipping.Cargo) error {
+	r.cargo = c
+	return nil
+}
+
+func (r *mockCargoRepository) Find(id shipping.TrackingID) (*shipping.Cargo, error) {
+	if r.cargo != nil {
+		return r.cargo, nil
+	}
+	return nil, shipping.ErrUnknownCargo
+}
 
 func (r *mockCargoRepository) FindAll() []*shipping.Cargo {
 	return []*shipping.Cargo{r.cargo}
 }
+
+func (r *mockCargoRepository) ForEach(fn func(*shipping.Cargo) error) error {
+	if r.cargo == nil {
+		return nil
+	}
+	return fn(r.cargo)
+}
+
+func (r *mockCargoRepository) FindMatching(q shipping.CargoQuery) ([]*shipping.Cargo, error) {
+	if r.cargo == nil || !q.Matches(r.cargo) {
+		return nil, nil
+	}
+	return []*shipping.Cargo{r.cargo}, nil
+}
+
+func TestRecalculateDelivery(t *testing.T) {
+	// The cargo is stored as booked, although it has since been loaded.
+	c, _ := builders.Cargo("ABC").Build()
+	_, loaded := builders.Cargo("ABC").Loaded(0).Build()
+
+	cargos := mockCargoRepository{cargo: c}
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryFn = func(id shipping.TrackingID) shipping.HandlingHistory {
+		return loaded
+	}
+
+	s := NewService(&cargos, nil, &events, nil)
+
+	if _, err := s.RecalculateDelivery(context.Background(), ""); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+
+	if _, err := s.RecalculateDelivery(context.Background(), c.TrackingID); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := cargos.cargo.Delivery.TransportStatus; got != shipping.OnboardCarrier {
+		t.Errorf("TransportStatus = %v; want = %v", got, shipping.OnboardCarrier)
+	}
+	if got := cargos.cargo.Delivery.CurrentVoyage; got != "V100" {
+		t.Errorf("CurrentVoyage = %v; want = %v", got, "V100")
+	}
+
+	var calls int
+	report, err := s.RecalculateAllDeliveries(context.Background(), func(done, total int) {
+		calls++
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Total != 1 || report.Recalculated != 1 {
+		t.Errorf("report = %+v; want 1 of 1 recalculated", report)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d; want = %d", calls, 1)
+	}
+}
+
+type slowRoutingService struct {
+	delay time.Duration
+	calls int
+}
+
+func (s *slowRoutingService) FetchRoutesForSpecification(rs shipping.RouteSpecification) []shipping.Itinerary {
+	s.calls++
+	if s.calls > 1 {
+		time.Sleep(s.delay)
+	}
+	return []shipping.Itinerary{
+		{Legs: []shipping.Leg{{LoadLocation: rs.Origin, UnloadLocation: rs.Destination}}},
+	}
+}
+
+func TestRequestPossibleRoutesForCargo_Timeout(t *testing.T) {
+	var cargos mockCargoRepository
+
+	rs := &slowRoutingService{delay: 100 * time.Millisecond}
+
+	s := NewService(&cargos, nil, nil, rs, WithRoutingTimeout(10*time.Millisecond))
+
+	id, err := s.BookNewCargo(context.Background(), "", shipping.SESTO, shipping.AUMEL, time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC), shipping.Container20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first request responds in time and populates the cache.
+	rc := s.RequestPossibleRoutesForCargo(context.Background(), id)
+	if rc.Degraded {
+		t.Errorf("rc.Degraded = %v; want = %v", rc.Degraded, false)
+	}
+
+	rc = s.RequestPossibleRoutesForCargo(context.Background(), id)
+	if !rc.Degraded {
+		t.Errorf("rc.Degraded = %v; want = %v", rc.Degraded, true)
+	}
+	if len(rc.Itineraries) != 1 {
+		t.Errorf("len(rc.Itineraries) = %d; want = %d", len(rc.Itineraries), 1)
+	}
+}
+
+type blockingRoutingService struct {
+	release chan struct{}
+	calls   int32
+}
+
+func (s *blockingRoutingService) FetchRoutesForSpecification(rs shipping.RouteSpecification) []shipping.Itinerary {
+	atomic.AddInt32(&s.calls, 1)
+	<-s.release
+	return nil
+}
+
+func TestRequestPossibleRoutesForCargo_InFlight(t *testing.T) {
+	var cargos mockCargoRepository
+
+	rs := &blockingRoutingService{release: make(chan struct{})}
+	defer close(rs.release)
+
+	s := NewService(&cargos, nil, nil, rs, WithRoutingTimeout(10*time.Millisecond))
+
+	id, err := s.BookNewCargo(context.Background(), "", shipping.SESTO, shipping.AUMEL, time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC), shipping.Container20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		rc := s.RequestPossibleRoutesForCargo(context.Background(), id)
+		if !rc.Degraded {
+			t.Errorf("rc.Degraded = %v; want = %v", rc.Degraded, true)
+		}
+		if rc.Itineraries == nil || len(rc.Itineraries) != 0 {
+			t.Errorf("rc.Itineraries = %#v; want an empty slice", rc.Itineraries)
+		}
+	}
+
+	if got := atomic.LoadInt32(&rs.calls); got != 1 {
+		t.Errorf("calls = %d; want = %d", got, 1)
+	}
+}
+
+func TestStreamCargos(t *testing.T) {
+	n := 3
+
+	var cargos mock.CargoRepository
+	cargos.ForEachFn = func(fn func(*shipping.Cargo) error) error {
+		for i := 0; i < n; i++ {
+			c := shipping.NewCargo(shipping.TrackingID(fmt.Sprintf("C%03d", i)), shipping.RouteSpecification{})
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	s := NewService(&cargos, nil, nil, nil)
+
+	var streamed int
+	if err := s.StreamCargos(context.Background(), func(c Cargo) error {
+		streamed++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if streamed != n {
+		t.Errorf("streamed = %d; want = %d", streamed, n)
+	}
+
+	errStop := errors.New("stop")
+	if err := s.StreamCargos(context.Background(), func(c Cargo) error {
+		return errStop
+	}); err != errStop {
+		t.Errorf("err = %v; want = %v", err, errStop)
+	}
+}
+
+func BenchmarkCargos(b *testing.B) {
+	var cs []*shipping.Cargo
+	for i := 0; i < 1000; i++ {
+		c := shipping.NewCargo(shipping.TrackingID(fmt.Sprintf("C%04d", i)), shipping.RouteSpecification{
+			Origin:      shipping.SESTO,
+			Destination: shipping.AUMEL,
+		})
+		c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+			{VoyageNumber: "V100", LoadLocation: shipping.SESTO, UnloadLocation: shipping.AUMEL},
+		}})
+		c.DeriveDeliveryProgress(shipping.HandlingHistory{HandlingEvents: []shipping.HandlingEvent{
+			{TrackingID: c.TrackingID, Activity: shipping.HandlingActivity{Type: shipping.Receive, Location: shipping.SESTO}},
+			{TrackingID: c.TrackingID, Activity: shipping.HandlingActivity{Type: shipping.Load, Location: shipping.SESTO, VoyageNumber: "V100"}},
+		}})
+		cs = append(cs, c)
+	}
+
+	var cargos mock.CargoRepository
+	cargos.FindAllFn = func() []*shipping.Cargo {
+		return cs
+	}
+
+	s := NewService(&cargos, nil, nil, nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Cargos(context.Background())
+	}
+}
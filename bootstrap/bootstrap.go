@@ -0,0 +1,279 @@
+// Package bootstrap provides the means of starting out with the shipments
+// already in flight in the transport management system of a new adopter,
+// by importing them from an export of that system.
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// ErrInvalidFile is returned when an export is malformed.
+var ErrInvalidFile = errors.New("invalid file")
+
+// Export is an export of the shipments in flight in a transport management
+// system, e.g.
+//
+//	{
+//	    "shipments": [{
+//	        "tracking_id": "TMS0001",
+//	        "customer": "ACME",
+//	        "origin": "CNHKG",
+//	        "destination": "SESTO",
+//	        "arrival_deadline": "2016-04-01T00:00:00Z",
+//	        "equipment": "40ft",
+//	        "legs": [
+//	            {"voyage_number": "V100", "from": "CNHKG", "to": "SESTO", "load_time": "2016-03-03T00:00:00Z", "unload_time": "2016-03-20T00:00:00Z"}
+//	        ],
+//	        "events": [
+//	            {"type": "receive", "location": "CNHKG", "completed": "2016-03-01T08:00:00Z"}
+//	        ]
+//	    }]
+//	}
+//
+// The handling history of a shipment may be partial, such as only its most
+// recent events.
+type Export struct {
+	Shipments []Shipment `json:"shipments"`
+}
+
+// Shipment is a shipment in flight, as exported.
+type Shipment struct {
+	TrackingID      shipping.TrackingID `json:"tracking_id"`
+	Customer        shipping.CustomerID `json:"customer"`
+	Origin          shipping.UNLocode   `json:"origin"`
+	Destination     shipping.UNLocode   `json:"destination"`
+	ArrivalDeadline time.Time           `json:"arrival_deadline"`
+	Equipment       string              `json:"equipment"`
+	Legs            []Leg               `json:"legs"`
+	Events          []Event             `json:"events"`
+}
+
+// Leg is a leg of the route of an exported shipment. Legs are taken to have
+// been booked with, and confirmed by, their carriers.
+type Leg struct {
+	VoyageNumber shipping.VoyageNumber `json:"voyage_number"`
+	From         shipping.UNLocode     `json:"from"`
+	To           shipping.UNLocode     `json:"to"`
+	LoadTime     time.Time             `json:"load_time"`
+	UnloadTime   time.Time             `json:"unload_time"`
+}
+
+// Event is a handling event of an exported shipment.
+type Event struct {
+	Type         string                `json:"type"`
+	Location     shipping.UNLocode     `json:"location"`
+	VoyageNumber shipping.VoyageNumber `json:"voyage_number"`
+	Completed    time.Time             `json:"completed"`
+}
+
+// Report tells how an import went.
+type Report struct {
+	Total    int `json:"total"`
+	Imported int `json:"imported"`
+
+	// Unreconciled lists the shipments that could not be imported, in the
+	// order they were exported.
+	Unreconciled []Unreconciled `json:"unreconciled,omitempty"`
+}
+
+// Unreconciled is a shipment that could not be imported, and why.
+type Unreconciled struct {
+	Index      int    `json:"index"`
+	TrackingID string `json:"tracking_id"`
+	Reason     string `json:"reason"`
+}
+
+// Importer creates cargos for the shipments of an export.
+type Importer struct {
+	cargos    shipping.CargoRepository
+	events    shipping.HandlingEventRepository
+	locations shipping.LocationRepository
+	voyages   shipping.VoyageRepository
+
+	policy shipping.DeliveryPolicy
+}
+
+// Option configures an importer.
+type Option func(*Importer)
+
+// WithDeliveryPolicy sets the policy the delivery of the imported cargos is
+// derived with, rather than the default one.
+func WithDeliveryPolicy(p shipping.DeliveryPolicy) Option {
+	return func(im *Importer) {
+		im.policy = p
+	}
+}
+
+// NewImporter returns a new instance of an Importer.
+func NewImporter(cargos shipping.CargoRepository, events shipping.HandlingEventRepository, locations shipping.LocationRepository, voyages shipping.VoyageRepository, opts ...Option) *Importer {
+	im := &Importer{
+		cargos:    cargos,
+		events:    events,
+		locations: locations,
+		voyages:   voyages,
+		policy:    shipping.DefaultDeliveryPolicy,
+	}
+	for _, opt := range opts {
+		opt(im)
+	}
+	return im
+}
+
+// Import creates a cargo for each shipment of an export, routed on its legs
+// and with its delivery derived from its handling history.
+//
+// Shipments referring to locations or voyages that are not known, with legs
+// that do not connect, or with tracking IDs already booked are not imported
+// but reported as unreconciled, as are malformed shipments. A shipment is
+// either imported in full or not at all. Cargos are imported as they are,
+// which may leave them misrouted or misdirected.
+func (im *Importer) Import(ctx context.Context, r io.Reader) (Report, error) {
+	var export Export
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return Report{}, ErrInvalidFile
+	}
+
+	report := Report{Total: len(export.Shipments)}
+
+	seen := make(map[shipping.TrackingID]bool)
+	for i, s := range export.Shipments {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		if err := im.importShipment(s, seen); err != nil {
+			report.Unreconciled = append(report.Unreconciled, Unreconciled{
+				Index:      i,
+				TrackingID: string(s.TrackingID),
+				Reason:     err.Error(),
+			})
+			continue
+		}
+		seen[s.TrackingID] = true
+		report.Imported++
+	}
+
+	return report, nil
+}
+
+func (im *Importer) importShipment(s Shipment, seen map[shipping.TrackingID]bool) error {
+	c, events, err := im.reconcile(s, seen)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		im.events.Store(e)
+	}
+
+	c.DeriveDeliveryProgressWith(im.policy, shipping.HandlingHistory{HandlingEvents: events})
+
+	return im.cargos.Store(c)
+}
+
+// reconcile returns the cargo and handling events of a shipment, or why
+// they cannot be imported.
+func (im *Importer) reconcile(s Shipment, seen map[shipping.TrackingID]bool) (*shipping.Cargo, []shipping.HandlingEvent, error) {
+	if s.TrackingID == "" || s.Origin == "" || s.Destination == "" {
+		return nil, nil, errors.New("tracking ID, origin and destination are required")
+	}
+
+	if seen[s.TrackingID] {
+		return nil, nil, errors.New("exported more than once")
+	}
+	if _, err := im.cargos.Find(s.TrackingID); err == nil {
+		return nil, nil, errors.New("already booked")
+	}
+
+	for _, l := range []shipping.UNLocode{s.Origin, s.Destination} {
+		if err := im.knownLocation(l); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var equipment shipping.EquipmentType
+	if s.Equipment != "" {
+		var err error
+		if equipment, err = shipping.ParseEquipmentType(s.Equipment); err != nil {
+			return nil, nil, fmt.Errorf("unknown equipment type %q", s.Equipment)
+		}
+	}
+
+	legs := make([]shipping.Leg, len(s.Legs))
+	for k, l := range s.Legs {
+		if k > 0 && l.From != s.Legs[k-1].To {
+			return nil, nil, fmt.Errorf("leg %d does not connect with leg %d", k+1, k)
+		}
+		if err := im.knownVoyage(l.VoyageNumber); err != nil {
+			return nil, nil, err
+		}
+		for _, loc := range []shipping.UNLocode{l.From, l.To} {
+			if err := im.knownLocation(loc); err != nil {
+				return nil, nil, err
+			}
+		}
+		legs[k] = shipping.NewLeg(l.VoyageNumber, l.From, l.To, l.LoadTime, l.UnloadTime)
+	}
+
+	events := make([]shipping.HandlingEvent, len(s.Events))
+	for k, e := range s.Events {
+		typ, err := shipping.ParseHandlingEventType(e.Type)
+		if err != nil {
+			return nil, nil, fmt.Errorf("event %d: unknown type %q", k+1, e.Type)
+		}
+		if e.Completed.IsZero() {
+			return nil, nil, fmt.Errorf("event %d: completion time is required", k+1)
+		}
+		if err := im.knownLocation(e.Location); err != nil {
+			return nil, nil, fmt.Errorf("event %d: %v", k+1, err)
+		}
+		if e.VoyageNumber != "" {
+			if err := im.knownVoyage(e.VoyageNumber); err != nil {
+				return nil, nil, fmt.Errorf("event %d: %v", k+1, err)
+			}
+		}
+		events[k] = shipping.HandlingEvent{
+			TrackingID: s.TrackingID,
+			Activity: shipping.HandlingActivity{
+				Type:         typ,
+				Location:     e.Location,
+				VoyageNumber: e.VoyageNumber,
+			},
+			Completed: e.Completed,
+		}
+	}
+
+	c := shipping.NewCargo(s.TrackingID, shipping.RouteSpecification{
+		Origin:          s.Origin,
+		Destination:     s.Destination,
+		ArrivalDeadline: s.ArrivalDeadline,
+	})
+	c.Customer = s.Customer
+	c.Equipment = equipment
+	if len(legs) > 0 {
+		c.AssignToRoute(shipping.Itinerary{Legs: legs})
+	}
+
+	return c, events, nil
+}
+
+func (im *Importer) knownLocation(l shipping.UNLocode) error {
+	if _, err := im.locations.Find(l); err != nil {
+		return fmt.Errorf("unknown location %s", l)
+	}
+	return nil
+}
+
+func (im *Importer) knownVoyage(v shipping.VoyageNumber) error {
+	if _, err := im.voyages.Find(v); err != nil {
+		return fmt.Errorf("unknown voyage %s", v)
+	}
+	return nil
+}
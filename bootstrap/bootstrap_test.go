@@ -0,0 +1,126 @@
+package bootstrap
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/inmem"
+)
+
+const export = `{
+	"shipments": [{
+		"tracking_id": "TMS0001",
+		"customer": "ACME",
+		"origin": "CNHKG",
+		"destination": "SESTO",
+		"arrival_deadline": "2016-04-01T00:00:00Z",
+		"equipment": "reefer",
+		"legs": [
+			{"voyage_number": "V100", "from": "CNHKG", "to": "JNTKO", "load_time": "2016-03-01T12:00:00Z", "unload_time": "2016-03-04T08:00:00Z"},
+			{"voyage_number": "V300", "from": "JNTKO", "to": "DEHAM", "load_time": "2016-03-06T08:00:00Z", "unload_time": "2016-03-30T14:00:00Z"},
+			{"voyage_number": "V400", "from": "DEHAM", "to": "SESTO", "load_time": "2016-03-31T10:00:00Z", "unload_time": "2016-04-01T18:00:00Z"}
+		],
+		"events": [
+			{"type": "load", "location": "CNHKG", "voyage_number": "V100", "completed": "2016-03-01T12:00:00Z"},
+			{"type": "unload", "location": "JNTKO", "voyage_number": "V100", "completed": "2016-03-04T08:00:00Z"},
+			{"type": "load", "location": "JNTKO", "voyage_number": "V300", "completed": "2016-03-06T08:00:00Z"}
+		]
+	}, {
+		"tracking_id": "TMS0002",
+		"origin": "CNHKG",
+		"destination": "XXXXX"
+	}, {
+		"tracking_id": "TMS0003",
+		"origin": "CNHKG",
+		"destination": "SESTO",
+		"legs": [
+			{"voyage_number": "V100", "from": "CNHKG", "to": "JNTKO"},
+			{"voyage_number": "V400", "from": "DEHAM", "to": "SESTO"}
+		]
+	}, {
+		"tracking_id": "TMS0004",
+		"origin": "CNHKG",
+		"destination": "SESTO",
+		"events": [
+			{"type": "teleport", "location": "CNHKG", "completed": "2016-03-01T12:00:00Z"}
+		]
+	}, {
+		"tracking_id": "TMS0001",
+		"origin": "CNHKG",
+		"destination": "SESTO"
+	}, {
+		"tracking_id": "ABC123",
+		"origin": "CNHKG",
+		"destination": "SESTO"
+	}]
+}`
+
+func TestImport(t *testing.T) {
+	var (
+		cargos = inmem.NewCargoRepository()
+		events = inmem.NewHandlingEventRepository()
+	)
+
+	cargos.Store(shipping.NewCargo("ABC123", shipping.RouteSpecification{
+		Origin:      shipping.SESTO,
+		Destination: shipping.AUMEL,
+	}))
+
+	im := NewImporter(cargos, events, inmem.NewLocationRepository(), inmem.NewVoyageRepository())
+
+	report, err := im.Import(context.Background(), strings.NewReader(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Total != 6 || report.Imported != 1 {
+		t.Errorf("report = %+v; want 1 of 6 imported", report)
+	}
+
+	want := []string{"TMS0002", "TMS0003", "TMS0004", "TMS0001", "ABC123"}
+	if len(report.Unreconciled) != len(want) {
+		t.Fatalf("len(unreconciled) = %d; want = %d", len(report.Unreconciled), len(want))
+	}
+	for i, u := range report.Unreconciled {
+		if u.TrackingID != want[i] || u.Index != i+1 {
+			t.Errorf("unreconciled[%d] = %+v; want %s at %d", i, u, want[i], i+1)
+		}
+		if u.Reason == "" {
+			t.Errorf("unreconciled[%d] has no reason", i)
+		}
+	}
+
+	c, err := cargos.Find("TMS0001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Customer != "ACME" || c.Equipment != shipping.Reefer {
+		t.Errorf("cargo = %+v", c)
+	}
+	if len(c.Itinerary.Legs) != 3 {
+		t.Errorf("len(legs) = %d; want = %d", len(c.Itinerary.Legs), 3)
+	}
+	if c.Delivery.TransportStatus != shipping.OnboardCarrier || c.Delivery.CurrentVoyage != "V300" {
+		t.Errorf("delivery = %+v; want onboard V300", c.Delivery)
+	}
+	if c.Delivery.RoutingStatus != shipping.Routed || c.Delivery.IsMisdirected {
+		t.Errorf("delivery = %+v; want routed and not misdirected", c.Delivery)
+	}
+
+	if h := events.QueryHandlingHistory("TMS0001"); len(h.HandlingEvents) != 3 {
+		t.Errorf("len(history) = %d; want = %d", len(h.HandlingEvents), 3)
+	}
+	if _, err := cargos.Find("TMS0004"); err != shipping.ErrUnknownCargo {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownCargo)
+	}
+}
+
+func TestImport_InvalidFile(t *testing.T) {
+	im := NewImporter(inmem.NewCargoRepository(), inmem.NewHandlingEventRepository(), inmem.NewLocationRepository(), inmem.NewVoyageRepository())
+
+	if _, err := im.Import(context.Background(), strings.NewReader("not json")); err != ErrInvalidFile {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidFile)
+	}
+}
@@ -0,0 +1,300 @@
+// Package briefing compiles the daily operations briefing: what was booked
+// the day before, what is expected to arrive and depart during the day, the
+// cargos that have gone off course or are running late, and the tasks left
+// open. Every customer is briefed on its own cargos, and the operator on all
+// of them.
+package briefing
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// Briefing is a read model of the operations of a day.
+type Briefing struct {
+	// Customer is who the briefing is for, or empty for the operator.
+	Customer string    `json:"customer,omitempty"`
+	Date     time.Time `json:"date"`
+
+	// Bookings are the cargos booked the day before.
+	Bookings []Cargo `json:"bookings"`
+
+	// Arrivals and Departures are the cargos expected to arrive at their
+	// destinations, and to be loaded onto a voyage, during the day.
+	Arrivals   []Cargo `json:"arrivals"`
+	Departures []Cargo `json:"departures"`
+
+	// Misrouted are the cargos misdirected or routed in a way that does not
+	// satisfy their route specifications.
+	Misrouted []Cargo `json:"misrouted"`
+
+	// Delayed are the cargos projected to arrive after their deadlines.
+	Delayed []Cargo `json:"delayed"`
+
+	OpenTasks []Task `json:"open_tasks"`
+}
+
+// Cargo is a read model of a cargo in a briefing.
+type Cargo struct {
+	TrackingID  string    `json:"tracking_id"`
+	Origin      string    `json:"origin"`
+	Destination string    `json:"destination"`
+	Location    string    `json:"location,omitempty"`
+	Voyage      string    `json:"voyage,omitempty"`
+	ETA         time.Time `json:"eta"`
+	Deadline    time.Time `json:"deadline"`
+	Delay       string    `json:"delay,omitempty"`
+}
+
+// Task is a read model of an open task in a briefing.
+type Task struct {
+	ID          string    `json:"id"`
+	Kind        string    `json:"kind"`
+	TrackingID  string    `json:"tracking_id,omitempty"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	Assignee    string    `json:"assignee,omitempty"`
+	Opened      time.Time `json:"opened"`
+}
+
+// IsEmpty returns whether there is nothing to brief on.
+func (b Briefing) IsEmpty() bool {
+	return len(b.Bookings)+len(b.Arrivals)+len(b.Departures)+len(b.Misrouted)+len(b.Delayed)+len(b.OpenTasks) == 0
+}
+
+// Summary returns a one-line summary of the briefing.
+func (b Briefing) Summary() string {
+	return fmt.Sprintf("Operations briefing for %s: %d booked yesterday, %d arriving, %d departing, %d misrouted, %d delayed, %d open tasks.",
+		b.Date.Format("2006-01-02"), len(b.Bookings), len(b.Arrivals), len(b.Departures), len(b.Misrouted), len(b.Delayed), len(b.OpenTasks))
+}
+
+// WriteJSON writes the briefing as a JSON document.
+func (b Briefing) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// WriteHTML writes the briefing as an HTML page.
+func (b Briefing) WriteHTML(w io.Writer) error {
+	return page.Execute(w, b)
+}
+
+var page = template.Must(template.New("briefing").Funcs(template.FuncMap{
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format("2006-01-02 15:04 MST")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Operations briefing {{.Date.Format "2006-01-02"}}</title>
+</head>
+<body>
+<h1>Operations briefing {{.Date.Format "2006-01-02"}}{{with .Customer}} for {{.}}{{end}}</h1>
+{{define "cargos"}}{{if .}}<table>
+<tr><th>Tracking ID</th><th>Origin</th><th>Destination</th><th>Location</th><th>Voyage</th><th>ETA</th><th>Deadline</th><th>Delay</th></tr>
+{{range .}}<tr><td>{{.TrackingID}}</td><td>{{.Origin}}</td><td>{{.Destination}}</td><td>{{.Location}}</td><td>{{.Voyage}}</td><td>{{date .ETA}}</td><td>{{date .Deadline}}</td><td>{{.Delay}}</td></tr>
+{{end}}</table>{{else}}<p>None.</p>{{end}}{{end}}
+<h2>Booked yesterday</h2>
+{{template "cargos" .Bookings}}
+<h2>Arriving today</h2>
+{{template "cargos" .Arrivals}}
+<h2>Departing today</h2>
+{{template "cargos" .Departures}}
+<h2>Misrouted</h2>
+{{template "cargos" .Misrouted}}
+<h2>Delayed</h2>
+{{template "cargos" .Delayed}}
+<h2>Open tasks</h2>
+{{if .OpenTasks}}<table>
+<tr><th>ID</th><th>Kind</th><th>Tracking ID</th><th>Description</th><th>Status</th><th>Assignee</th><th>Opened</th></tr>
+{{range .OpenTasks}}<tr><td>{{.ID}}</td><td>{{.Kind}}</td><td>{{.TrackingID}}</td><td>{{.Description}}</td><td>{{.Status}}</td><td>{{.Assignee}}</td><td>{{date .Opened}}</td></tr>
+{{end}}</table>{{else}}<p>None.</p>{{end}}
+</body>
+</html>
+`))
+
+// Compiler compiles the briefings of a day.
+type Compiler struct {
+	cargos       shipping.CargoRepository
+	tasks        shipping.TaskRepository
+	auditEntries shipping.AuditRepository
+	location     *time.Location
+}
+
+// NewCompiler returns a compiler of briefings, with days starting at
+// midnight in the given location.
+func NewCompiler(cargos shipping.CargoRepository, tasks shipping.TaskRepository, auditEntries shipping.AuditRepository, loc *time.Location) *Compiler {
+	return &Compiler{
+		cargos:       cargos,
+		tasks:        tasks,
+		auditEntries: auditEntries,
+		location:     loc,
+	}
+}
+
+// Compile returns the briefings of the day the given time falls on: one per
+// customer with cargos, and one on all cargos for the operator, under the
+// empty customer ID.
+func (c *Compiler) Compile(at time.Time) (map[shipping.CustomerID]*Briefing, error) {
+	var (
+		y, m, d  = at.In(c.location).Date()
+		today    = time.Date(y, m, d, 0, 0, 0, 0, c.location)
+		tomorrow = today.AddDate(0, 0, 1)
+	)
+
+	briefings := map[shipping.CustomerID]*Briefing{
+		"": newBriefing("", today),
+	}
+	briefingsOf := func(id shipping.CustomerID) []*Briefing {
+		if id == "" {
+			return []*Briefing{briefings[""]}
+		}
+		b, ok := briefings[id]
+		if !ok {
+			b = newBriefing(id, today)
+			briefings[id] = b
+		}
+		return []*Briefing{briefings[""], b}
+	}
+
+	booked := make(map[shipping.TrackingID]bool)
+	for _, e := range c.auditEntries.Find(shipping.AuditQuery{
+		Action: shipping.AuditCargoBooked,
+		From:   today.AddDate(0, 0, -1),
+		To:     today,
+	}) {
+		booked[e.TrackingID] = true
+	}
+
+	owners := make(map[shipping.TrackingID]shipping.CustomerID)
+
+	err := c.cargos.ForEach(func(cargo *shipping.Cargo) error {
+		owners[cargo.TrackingID] = cargo.Customer
+
+		var (
+			d       = cargo.Delivery
+			arrival = d.ProjectedArrival()
+			done    = d.TransportStatus == shipping.Claimed || d.IsUnloadedAtDestination
+		)
+
+		for _, b := range briefingsOf(cargo.Customer) {
+			if booked[cargo.TrackingID] {
+				b.Bookings = append(b.Bookings, assembleCargo(cargo))
+			}
+			if !done && within(arrival, today, tomorrow) {
+				b.Arrivals = append(b.Arrivals, assembleCargo(cargo))
+			}
+			if l, ok := departure(cargo); ok && within(l.LoadTime, today, tomorrow) {
+				rc := assembleCargo(cargo)
+				rc.Voyage = string(l.VoyageNumber)
+				b.Departures = append(b.Departures, rc)
+			}
+			if !done && (d.IsMisdirected || d.RoutingStatus == shipping.Misrouted) {
+				b.Misrouted = append(b.Misrouted, assembleCargo(cargo))
+			}
+			if !done && d.IsDeadlineAtRisk() {
+				b.Delayed = append(b.Delayed, assembleCargo(cargo))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range c.tasks.FindAll() {
+		if t.IsDone() {
+			continue
+		}
+		for _, b := range briefingsOf(owners[t.TrackingID]) {
+			b.OpenTasks = append(b.OpenTasks, assembleTask(t))
+		}
+	}
+
+	for _, b := range briefings {
+		b.sort()
+	}
+
+	return briefings, nil
+}
+
+func newBriefing(id shipping.CustomerID, day time.Time) *Briefing {
+	return &Briefing{
+		Customer:   string(id),
+		Date:       day,
+		Bookings:   []Cargo{},
+		Arrivals:   []Cargo{},
+		Departures: []Cargo{},
+		Misrouted:  []Cargo{},
+		Delayed:    []Cargo{},
+		OpenTasks:  []Task{},
+	}
+}
+
+// sort orders the cargos by tracking ID, and the tasks by when they were
+// opened.
+func (b *Briefing) sort() {
+	for _, cs := range [][]Cargo{b.Bookings, b.Arrivals, b.Departures, b.Misrouted, b.Delayed} {
+		cs := cs
+		sort.Slice(cs, func(i, j int) bool { return cs[i].TrackingID < cs[j].TrackingID })
+	}
+	sort.SliceStable(b.OpenTasks, func(i, j int) bool { return b.OpenTasks[i].Opened.Before(b.OpenTasks[j].Opened) })
+}
+
+// departure returns the leg the cargo is next expected to be loaded onto,
+// if any.
+func departure(c *shipping.Cargo) (shipping.Leg, bool) {
+	next := c.Delivery.NextExpectedActivity
+	if next.Type != shipping.Load {
+		return shipping.Leg{}, false
+	}
+	for _, l := range c.Itinerary.Legs {
+		if l.LoadLocation == next.Location && l.VoyageNumber == next.VoyageNumber {
+			return l, true
+		}
+	}
+	return shipping.Leg{}, false
+}
+
+func within(t, from, to time.Time) bool {
+	return !t.IsZero() && !t.Before(from) && t.Before(to)
+}
+
+func assembleCargo(c *shipping.Cargo) Cargo {
+	result := Cargo{
+		TrackingID:  string(c.TrackingID),
+		Origin:      string(c.Origin),
+		Destination: string(c.RouteSpecification.Destination),
+		Location:    string(c.Delivery.LastKnownLocation),
+		Voyage:      string(c.Delivery.CurrentVoyage),
+		ETA:         c.Delivery.ProjectedArrival(),
+		Deadline:    c.RouteSpecification.ArrivalDeadline,
+	}
+	if slip := c.Delivery.DeadlineSlip(); slip > 0 {
+		result.Delay = slip.String()
+	}
+	return result
+}
+
+func assembleTask(t *shipping.Task) Task {
+	return Task{
+		ID:          string(t.ID),
+		Kind:        t.Kind.String(),
+		TrackingID:  string(t.TrackingID),
+		Description: t.Description,
+		Status:      string(t.Status),
+		Assignee:    string(t.Assignee),
+		Opened:      t.Opened,
+	}
+}
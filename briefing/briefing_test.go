@@ -0,0 +1,225 @@
+package briefing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/inmem"
+	"github.com/marcusolsson/goddd/notification"
+)
+
+var today = time.Date(2016, 3, 8, 0, 0, 0, 0, time.UTC)
+
+func newCompiler(t *testing.T) *Compiler {
+	var (
+		cargos       = inmem.NewCargoRepository()
+		tasks        = inmem.NewTaskRepository()
+		auditEntries = inmem.NewAuditRepository()
+	)
+
+	cargo := func(id shipping.TrackingID, customer shipping.CustomerID) *shipping.Cargo {
+		c := shipping.NewCargo(id, shipping.RouteSpecification{Origin: shipping.CNHKG, Destination: shipping.SESTO})
+		c.Customer = customer
+		c.Delivery.RoutingStatus = shipping.Routed
+		c.Delivery.TransportStatus = shipping.InPort
+		return c
+	}
+
+	booked := cargo("BOOKED1", "ACME")
+	booked.Delivery.RoutingStatus = shipping.NotRouted
+
+	arriving := cargo("ARRIVE1", "ACME")
+	arriving.Delivery.ETA = today.Add(14 * time.Hour)
+
+	departing := cargo("DEPART1", "GLOBEX")
+	departing.Itinerary = shipping.Itinerary{Legs: []shipping.Leg{
+		shipping.NewLeg("V100", shipping.CNHKG, shipping.SESTO, today.Add(10*time.Hour), today.Add(20*24*time.Hour)),
+	}}
+	departing.Delivery.NextExpectedActivity = shipping.HandlingActivity{Type: shipping.Load, Location: shipping.CNHKG, VoyageNumber: "V100"}
+
+	lost := cargo("LOST1", "ACME")
+	lost.Delivery.IsMisdirected = true
+
+	late := cargo("LATE1", "")
+	late.RouteSpecification.ArrivalDeadline = today.Add(24 * time.Hour)
+	late.Delivery.RouteSpecification = late.RouteSpecification
+	late.Delivery.ETA = today.Add(72 * time.Hour)
+
+	claimed := cargo("CLAIMED1", "ACME")
+	claimed.Delivery.TransportStatus = shipping.Claimed
+	claimed.Delivery.IsMisdirected = true
+	claimed.Delivery.ETA = today.Add(time.Hour)
+
+	for _, c := range []*shipping.Cargo{booked, arriving, departing, lost, late, claimed} {
+		if err := cargos.Store(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, e := range []shipping.AuditEntry{
+		{Action: shipping.AuditCargoBooked, TrackingID: "ARRIVE1", Time: today.AddDate(0, 0, -10)},
+		{Action: shipping.AuditCargoBooked, TrackingID: "BOOKED1", Time: today.Add(-2 * time.Hour)},
+	} {
+		e := e
+		e.Sequence = uint64(i + 1)
+		if err := auditEntries.Append(&e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reroute := shipping.NewTask(shipping.RerouteTask, "LOST1", "Reroute LOST1", today.Add(-time.Hour))
+	done := shipping.NewTask(shipping.RerouteTask, "ARRIVE1", "Reroute ARRIVE1", today.Add(-48*time.Hour))
+	done.Status = shipping.TaskDone
+	ingestion := shipping.NewTask(shipping.IngestionTask, "", "Import failed", today.Add(-3*time.Hour))
+	for _, task := range []*shipping.Task{reroute, done, ingestion} {
+		if err := tasks.Store(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return NewCompiler(cargos, tasks, auditEntries, time.UTC)
+}
+
+func trackingIDs(cs []Cargo) string {
+	var ids []string
+	for _, c := range cs {
+		ids = append(ids, c.TrackingID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestCompile(t *testing.T) {
+	briefings, err := newCompiler(t).Compile(today.Add(7 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(briefings) != 3 {
+		t.Fatalf("len(briefings) = %d; want = %d", len(briefings), 3)
+	}
+
+	tests := []struct {
+		customer shipping.CustomerID
+		section  string
+		got      []Cargo
+		want     string
+	}{
+		{"", "bookings", briefings[""].Bookings, "BOOKED1"},
+		{"", "arrivals", briefings[""].Arrivals, "ARRIVE1"},
+		{"", "departures", briefings[""].Departures, "DEPART1"},
+		{"", "misrouted", briefings[""].Misrouted, "LOST1"},
+		{"", "delayed", briefings[""].Delayed, "LATE1"},
+		{"ACME", "bookings", briefings["ACME"].Bookings, "BOOKED1"},
+		{"ACME", "arrivals", briefings["ACME"].Arrivals, "ARRIVE1"},
+		{"ACME", "departures", briefings["ACME"].Departures, ""},
+		{"ACME", "misrouted", briefings["ACME"].Misrouted, "LOST1"},
+		{"GLOBEX", "departures", briefings["GLOBEX"].Departures, "DEPART1"},
+		{"GLOBEX", "misrouted", briefings["GLOBEX"].Misrouted, ""},
+	}
+	for _, tt := range tests {
+		if got := trackingIDs(tt.got); got != tt.want {
+			t.Errorf("%q %s = %s; want = %s", tt.customer, tt.section, got, tt.want)
+		}
+	}
+
+	if v := briefings["GLOBEX"].Departures[0].Voyage; v != "V100" {
+		t.Errorf("departure voyage = %s; want = V100", v)
+	}
+	if d := briefings[""].Delayed[0].Delay; d != "48h0m0s" {
+		t.Errorf("delay = %s; want = 48h0m0s", d)
+	}
+	if n := len(briefings[""].OpenTasks); n != 2 {
+		t.Errorf("len(operator tasks) = %d; want = %d", n, 2)
+	}
+	if ts := briefings["ACME"].OpenTasks; len(ts) != 1 || ts[0].TrackingID != "LOST1" {
+		t.Errorf("ACME tasks = %+v", ts)
+	}
+}
+
+func TestBriefing_Write(t *testing.T) {
+	briefings, err := newCompiler(t).Compile(today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := briefings["ACME"]
+
+	var js bytes.Buffer
+	if err := b.WriteJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	var got Briefing
+	if err := json.Unmarshal(js.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Customer != "ACME" || trackingIDs(got.Misrouted) != "LOST1" {
+		t.Errorf("decoded = %+v", got)
+	}
+
+	var html bytes.Buffer
+	if err := b.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"Operations briefing 2016-03-08 for ACME", "LOST1", "Reroute LOST1"} {
+		if !strings.Contains(html.String(), s) {
+			t.Errorf("html does not contain %q", s)
+		}
+	}
+}
+
+type recordingSender struct {
+	sent map[shipping.CustomerID][]notification.Notification
+}
+
+func (s *recordingSender) Send(ctx context.Context, c *shipping.Customer, ch shipping.NotificationChannel, ns []notification.Notification) error {
+	s.sent[c.ID] = append(s.sent[c.ID], ns...)
+	return nil
+}
+
+func TestScheduler_Deliver(t *testing.T) {
+	customers := inmem.NewCustomerRepository()
+	subscribed := shipping.NotificationPreferences{shipping.DailyBriefingNotification: {shipping.EmailChannel}}
+	customers.Store(&shipping.Customer{ID: "ACME", Notifications: subscribed})
+	customers.Store(&shipping.Customer{ID: "GLOBEX"})
+	customers.Store(&shipping.Customer{ID: "OPS", Notifications: subscribed})
+
+	sender := &recordingSender{sent: make(map[shipping.CustomerID][]notification.Notification)}
+	s := NewScheduler(newCompiler(t), notification.NewNotifier(customers, sender), WithDeliveryHour(6), WithOperator("OPS"))
+
+	ctx := context.Background()
+
+	if err := s.Deliver(ctx, today.Add(5*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("briefings delivered before the hour of delivery")
+	}
+
+	for _, h := range []time.Duration{7, 8} {
+		if err := s.Deliver(ctx, today.Add(h*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(sender.sent) != 2 || len(sender.sent["ACME"]) != 1 || len(sender.sent["OPS"]) != 1 {
+		t.Fatalf("sent = %v; want one briefing each to ACME and OPS", sender.sent)
+	}
+
+	n := sender.sent["OPS"][0]
+	if n.Type != shipping.DailyBriefingNotification || len(n.Attachments) != 2 {
+		t.Errorf("notification = %+v", n)
+	}
+	if !strings.Contains(n.Message, "1 misrouted") || n.Attachments[0].Name != "briefing-2016-03-08.json" {
+		t.Errorf("message = %q, attachment = %s", n.Message, n.Attachments[0].Name)
+	}
+
+	if err := s.Deliver(ctx, today.Add(30*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.sent["ACME"]) != 2 {
+		t.Errorf("briefing of the next day not delivered")
+	}
+}
@@ -0,0 +1,140 @@
+package briefing
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/notification"
+)
+
+// DefaultDeliveryHour is the hour of the day briefings are delivered at,
+// unless configured otherwise.
+const DefaultDeliveryHour = 6
+
+// Scheduler delivers the briefings of every day through the notification
+// channels of the customers subscribed to them, as JSON and HTML
+// attachments.
+//
+// The day last delivered is kept in memory, so a restart later the same day
+// delivers its briefings again.
+type Scheduler struct {
+	compiler *Compiler
+	notifier *notification.Notifier
+	hour     int
+	operator shipping.CustomerID
+
+	mtx  sync.Mutex
+	last time.Time
+}
+
+// SchedulerOption configures a scheduler.
+type SchedulerOption func(*Scheduler)
+
+// WithDeliveryHour sets the hour of the day briefings are delivered at,
+// rather than DefaultDeliveryHour.
+func WithDeliveryHour(h int) SchedulerOption {
+	return func(s *Scheduler) {
+		s.hour = h
+	}
+}
+
+// WithOperator sets the customer the briefing on all cargos is delivered
+// to, such as an account of the operations team. It is otherwise not
+// delivered.
+func WithOperator(id shipping.CustomerID) SchedulerOption {
+	return func(s *Scheduler) {
+		s.operator = id
+	}
+}
+
+// NewScheduler returns a scheduler delivering the briefings compiled by c.
+func NewScheduler(c *Compiler, n *notification.Notifier, opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{
+		compiler: c,
+		notifier: n,
+		hour:     DefaultDeliveryHour,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Deliver delivers the briefings of the day once it is past the hour of
+// delivery, unless already delivered. Customers with nothing to be briefed
+// on are left out. It is meant to be called periodically, more often than
+// once an hour.
+func (s *Scheduler) Deliver(ctx context.Context, now time.Time) error {
+	local := now.In(s.compiler.location)
+	if local.Hour() < s.hour {
+		return nil
+	}
+
+	y, m, d := local.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, s.compiler.location)
+
+	s.mtx.Lock()
+	if !s.last.Before(today) {
+		s.mtx.Unlock()
+		return nil
+	}
+	s.last = today
+	s.mtx.Unlock()
+
+	briefings, err := s.compiler.Compile(now)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]shipping.CustomerID, 0, len(briefings))
+	for id := range briefings {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var firstErr error
+	for _, id := range ids {
+		b := briefings[id]
+
+		to := id
+		if id == "" {
+			if s.operator == "" {
+				continue
+			}
+			to = s.operator
+		} else if b.IsEmpty() {
+			continue
+		}
+
+		if err := s.send(ctx, to, b); err != nil && err != shipping.ErrUnknownCustomer && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (s *Scheduler) send(ctx context.Context, to shipping.CustomerID, b *Briefing) error {
+	var js, html bytes.Buffer
+	if err := b.WriteJSON(&js); err != nil {
+		return err
+	}
+	if err := b.WriteHTML(&html); err != nil {
+		return err
+	}
+
+	name := "briefing-" + b.Date.Format("2006-01-02")
+
+	return s.notifier.Notify(ctx, notification.Notification{
+		Customer: to,
+		Type:     shipping.DailyBriefingNotification,
+		Message:  b.Summary(),
+		Attachments: []notification.Attachment{
+			{Name: name + ".json", ContentType: "application/json", Data: js.Bytes()},
+			{Name: name + ".html", ContentType: "text/html; charset=utf-8", Data: html.Bytes()},
+		},
+	})
+}
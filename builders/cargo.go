@@ -0,0 +1,206 @@
+package builders
+
+import (
+	"fmt"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// CargoBuilder builds a cargo at a stage of its lifecycle, together with the
+// handling history that brought it there. Cargos are handled as their
+// itineraries say: received a day before the first leg is loaded, loaded and
+// unloaded as the voyages are scheduled and claimed a day after the last leg
+// is unloaded.
+type CargoBuilder struct {
+	id          shipping.TrackingID
+	customer    shipping.CustomerID
+	equipment   shipping.EquipmentType
+	origin      shipping.UNLocode
+	destination shipping.UNLocode
+	deadline    time.Time
+	itinerary   shipping.Itinerary
+
+	stage       stage
+	leg         int
+	misdirected shipping.UNLocode
+}
+
+// stage is how far a cargo has been handled along its itinerary.
+type stage int
+
+const (
+	booked stage = iota
+	received
+	loaded
+	unloaded
+	arrived
+	claimed
+)
+
+// Cargo returns a builder of a cargo from CNHKG to DEHAM, routed over V100
+// and V300 but not yet received.
+func Cargo(id shipping.TrackingID) *CargoBuilder {
+	return (&CargoBuilder{id: id}).Route(Itinerary().
+		Leg("V100", shipping.CNHKG, shipping.JNTKO).
+		Leg("V300", shipping.JNTKO, shipping.DEHAM).
+		Build())
+}
+
+// Customer sets the customer who booked the cargo.
+func (b *CargoBuilder) Customer(id shipping.CustomerID) *CargoBuilder {
+	b.customer = id
+	return b
+}
+
+// Equipment sets the equipment the cargo is booked with.
+func (b *CargoBuilder) Equipment(e shipping.EquipmentType) *CargoBuilder {
+	b.equipment = e
+	return b
+}
+
+// Deadline sets the arrival deadline of the cargo, which is otherwise a week
+// after the last leg of its itinerary is unloaded.
+func (b *CargoBuilder) Deadline(t time.Time) *CargoBuilder {
+	b.deadline = t
+	return b
+}
+
+// Route routes the cargo over the itinerary, from the first location it
+// loads at to the last location it unloads at.
+func (b *CargoBuilder) Route(i shipping.Itinerary) *CargoBuilder {
+	b.itinerary = i
+	if len(i.Legs) > 0 {
+		b.origin = i.Legs[0].LoadLocation
+		b.destination = i.Legs[len(i.Legs)-1].UnloadLocation
+	}
+	return b
+}
+
+// Unrouted leaves the cargo without an itinerary, keeping its origin and
+// destination.
+func (b *CargoBuilder) Unrouted() *CargoBuilder {
+	b.itinerary = shipping.Itinerary{}
+	return b
+}
+
+// Received has the cargo received at its origin.
+func (b *CargoBuilder) Received() *CargoBuilder {
+	return b.handled(received, 0)
+}
+
+// Loaded has the cargo loaded onto the leg of its itinerary with the given
+// index, having been carried along the legs before it.
+func (b *CargoBuilder) Loaded(leg int) *CargoBuilder {
+	return b.handled(loaded, leg)
+}
+
+// Unloaded has the cargo unloaded from the leg of its itinerary with the
+// given index.
+func (b *CargoBuilder) Unloaded(leg int) *CargoBuilder {
+	return b.handled(unloaded, leg)
+}
+
+// Arrived has the cargo unloaded at its destination.
+func (b *CargoBuilder) Arrived() *CargoBuilder {
+	return b.handled(arrived, 0)
+}
+
+// Claimed has the cargo claimed at its destination.
+func (b *CargoBuilder) Claimed() *CargoBuilder {
+	return b.handled(claimed, 0)
+}
+
+// Misdirected has the cargo loaded onto the leg of its itinerary with the
+// given index, then unloaded at a location off the itinerary when it was to
+// be unloaded.
+func (b *CargoBuilder) Misdirected(leg int, loc shipping.UNLocode) *CargoBuilder {
+	b.handled(loaded, leg)
+	b.misdirected = loc
+	return b
+}
+
+func (b *CargoBuilder) handled(s stage, leg int) *CargoBuilder {
+	b.stage = s
+	b.leg = leg
+	b.misdirected = ""
+	return b
+}
+
+// steps returns the number of handling events the cargo has gone through:
+// receiving it, loading and unloading it for each leg, and claiming it.
+func (b *CargoBuilder) steps() int {
+	legs := len(b.itinerary.Legs)
+	if b.stage != booked && legs == 0 {
+		panic(fmt.Sprintf("builders: cargo %s is handled but not routed", b.id))
+	}
+	if (b.stage == loaded || b.stage == unloaded) && (b.leg < 0 || b.leg >= legs) {
+		panic(fmt.Sprintf("builders: cargo %s has no leg %d", b.id, b.leg))
+	}
+
+	switch b.stage {
+	case received:
+		return 1
+	case loaded:
+		return 2 + 2*b.leg
+	case unloaded:
+		return 3 + 2*b.leg
+	case arrived:
+		return 1 + 2*legs
+	case claimed:
+		return 2 + 2*legs
+	}
+	return 0
+}
+
+// Build returns the cargo and its handling history, with the delivery of the
+// cargo derived from it.
+func (b *CargoBuilder) Build() (*shipping.Cargo, shipping.HandlingHistory) {
+	legs := b.itinerary.Legs
+
+	deadline := b.deadline
+	if deadline.IsZero() {
+		deadline = Epoch.AddDate(0, 2, 0)
+		if len(legs) > 0 {
+			deadline = legs[len(legs)-1].UnloadTime.AddDate(0, 0, 7)
+		}
+	}
+
+	c := shipping.NewCargo(b.id, shipping.RouteSpecification{
+		Origin:          b.origin,
+		Destination:     b.destination,
+		ArrivalDeadline: deadline,
+	})
+	c.Customer = b.customer
+	c.Equipment = b.equipment
+	if len(legs) > 0 {
+		c.AssignToRoute(b.itinerary)
+	}
+
+	steps := b.steps()
+
+	h := History(b.id)
+	for i := 0; i < steps; i++ {
+		switch {
+		case i == 0:
+			h.Receive(b.origin, legs[0].LoadTime.AddDate(0, 0, -1))
+		case i == 1+2*len(legs):
+			h.Claim(b.destination, legs[len(legs)-1].UnloadTime.AddDate(0, 0, 1))
+		case i%2 == 1:
+			l := legs[i/2]
+			h.Load(l.VoyageNumber, l.LoadLocation, l.LoadTime)
+		default:
+			l := legs[i/2-1]
+			h.Unload(l.VoyageNumber, l.UnloadLocation, l.UnloadTime)
+		}
+	}
+	if b.misdirected != "" {
+		l := legs[b.leg]
+		h.Unload(l.VoyageNumber, b.misdirected, l.UnloadTime)
+	}
+
+	history := h.Build()
+	c.DeriveDeliveryProgress(history)
+
+	return c, history
+}
@@ -0,0 +1,87 @@
+package builders
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+func TestCargo(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		b        *CargoBuilder
+		events   int
+		status   shipping.TransportStatus
+		location shipping.UNLocode
+	}{
+		{"booked", Cargo("ABC"), 0, shipping.NotReceived, ""},
+		{"received", Cargo("ABC").Received(), 1, shipping.InPort, shipping.CNHKG},
+		{"loaded", Cargo("ABC").Loaded(1), 4, shipping.OnboardCarrier, shipping.JNTKO},
+		{"unloaded", Cargo("ABC").Unloaded(0), 3, shipping.InPort, shipping.JNTKO},
+		{"arrived", Cargo("ABC").Arrived(), 5, shipping.InPort, shipping.DEHAM},
+		{"claimed", Cargo("ABC").Claimed(), 6, shipping.Claimed, shipping.DEHAM},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c, h := tt.b.Build()
+
+			if len(h.HandlingEvents) != tt.events {
+				t.Errorf("len(h.HandlingEvents) = %d; want = %d", len(h.HandlingEvents), tt.events)
+			}
+			if c.Delivery.TransportStatus != tt.status {
+				t.Errorf("c.Delivery.TransportStatus = %v; want = %v", c.Delivery.TransportStatus, tt.status)
+			}
+			if c.Delivery.LastKnownLocation != tt.location {
+				t.Errorf("c.Delivery.LastKnownLocation = %v; want = %v", c.Delivery.LastKnownLocation, tt.location)
+			}
+			if c.Delivery.IsMisdirected {
+				t.Errorf("c.Delivery.IsMisdirected = true")
+			}
+		})
+	}
+}
+
+func TestCargoMisdirected(t *testing.T) {
+	c, _ := Cargo("ABC").Misdirected(0, shipping.USNYC).Build()
+
+	if !c.Delivery.IsMisdirected {
+		t.Errorf("c.Delivery.IsMisdirected = false")
+	}
+	if c.Delivery.LastKnownLocation != shipping.USNYC {
+		t.Errorf("c.Delivery.LastKnownLocation = %v; want = %v", c.Delivery.LastKnownLocation, shipping.USNYC)
+	}
+}
+
+func TestCargoDeterministic(t *testing.T) {
+	c1, h1 := Cargo("ABC").Loaded(0).Build()
+	c2, h2 := Cargo("ABC").Loaded(0).Build()
+
+	if !reflect.DeepEqual(c1, c2) || !reflect.DeepEqual(h1, h2) {
+		t.Errorf("builds differ")
+	}
+
+	want := Epoch.Add(12 * time.Hour)
+	if got := c1.Itinerary.Legs[0].LoadTime; !got.Equal(want) {
+		t.Errorf("LoadTime = %v; want = %v", got, want)
+	}
+}
+
+func TestItineraryLeg(t *testing.T) {
+	i := Itinerary().Leg("V300", shipping.JNTKO, shipping.DEHAM).Build()
+
+	v := Voyage("V300")
+	if i.Legs[0].LoadTime != v.Schedule.CarrierMovements[0].DepartureTime {
+		t.Errorf("LoadTime = %v; want the departure from JNTKO", i.Legs[0].LoadTime)
+	}
+	if i.Legs[0].UnloadTime != v.Schedule.CarrierMovements[1].ArrivalTime {
+		t.Errorf("UnloadTime = %v; want the arrival in DEHAM", i.Legs[0].UnloadTime)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("a leg against the rotation of the voyage should panic")
+		}
+	}()
+	Itinerary().Leg("V300", shipping.DEHAM, shipping.NLRTM)
+}
@@ -0,0 +1,65 @@
+package builders
+
+import (
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// HistoryBuilder builds the handling history of a cargo. Events are
+// registered when they are completed.
+type HistoryBuilder struct {
+	id     shipping.TrackingID
+	events []shipping.HandlingEvent
+}
+
+// History returns a builder of an empty handling history of the cargo.
+func History(id shipping.TrackingID) *HistoryBuilder {
+	return &HistoryBuilder{id: id}
+}
+
+// Receive adds the cargo being received at a location.
+func (b *HistoryBuilder) Receive(loc shipping.UNLocode, completed time.Time) *HistoryBuilder {
+	return b.add(shipping.Receive, loc, "", completed)
+}
+
+// Load adds the cargo being loaded onto a voyage at a location.
+func (b *HistoryBuilder) Load(n shipping.VoyageNumber, loc shipping.UNLocode, completed time.Time) *HistoryBuilder {
+	return b.add(shipping.Load, loc, n, completed)
+}
+
+// Unload adds the cargo being unloaded from a voyage at a location.
+func (b *HistoryBuilder) Unload(n shipping.VoyageNumber, loc shipping.UNLocode, completed time.Time) *HistoryBuilder {
+	return b.add(shipping.Unload, loc, n, completed)
+}
+
+// Customs adds the cargo clearing customs at a location.
+func (b *HistoryBuilder) Customs(loc shipping.UNLocode, completed time.Time) *HistoryBuilder {
+	return b.add(shipping.Customs, loc, "", completed)
+}
+
+// Claim adds the cargo being claimed at a location.
+func (b *HistoryBuilder) Claim(loc shipping.UNLocode, completed time.Time) *HistoryBuilder {
+	return b.add(shipping.Claim, loc, "", completed)
+}
+
+func (b *HistoryBuilder) add(t shipping.HandlingEventType, loc shipping.UNLocode, n shipping.VoyageNumber, completed time.Time) *HistoryBuilder {
+	b.events = append(b.events, shipping.HandlingEvent{
+		TrackingID: b.id,
+		Activity: shipping.HandlingActivity{
+			Type:         t,
+			Location:     loc,
+			VoyageNumber: n,
+		},
+		Completed:  completed,
+		Registered: completed,
+	})
+	return b
+}
+
+// Build returns the handling history.
+func (b *HistoryBuilder) Build() shipping.HandlingHistory {
+	events := make([]shipping.HandlingEvent, len(b.events))
+	copy(events, b.events)
+	return shipping.HandlingHistory{HandlingEvents: events}
+}
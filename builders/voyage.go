@@ -0,0 +1,102 @@
+// Package builders provides deterministic fixtures for tests: cargos at
+// given stages of their lifecycle, itineraries over the sample voyages and
+// the handling histories that brought the cargos there.
+//
+// The builders go through the constructors and methods of the domain rather
+// than literal structs, so that the fixtures stay valid as the domain model
+// grows.
+package builders
+
+import (
+	"fmt"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// Epoch is the day the schedules of the voyages built are anchored to.
+var Epoch = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+var sampleVoyages = []*shipping.Voyage{
+	shipping.V100,
+	shipping.V300,
+	shipping.V400,
+}
+
+// Voyages returns copies of the scheduled sample voyages, moved so that the
+// first of them departs on the day of Epoch. The voyages connect as they do
+// in the sample.
+func Voyages() []*shipping.Voyage {
+	var first time.Time
+	for _, v := range sampleVoyages {
+		for _, m := range v.Schedule.CarrierMovements {
+			if first.IsZero() || m.DepartureTime.Before(first) {
+				first = m.DepartureTime
+			}
+		}
+	}
+	shift := Epoch.Sub(first.Truncate(24 * time.Hour))
+
+	result := make([]*shipping.Voyage, 0, len(sampleVoyages))
+	for _, v := range sampleVoyages {
+		movements := make([]shipping.CarrierMovement, len(v.Schedule.CarrierMovements))
+		for i, m := range v.Schedule.CarrierMovements {
+			m.DepartureTime = m.DepartureTime.Add(shift)
+			m.ArrivalTime = m.ArrivalTime.Add(shift)
+			movements[i] = m
+		}
+
+		c := *v
+		c.Schedule = shipping.Schedule{CarrierMovements: movements}
+		result = append(result, &c)
+	}
+	return result
+}
+
+// Voyage returns the sample voyage with the given number, as returned by
+// Voyages. It panics if there is no such voyage.
+func Voyage(n shipping.VoyageNumber) *shipping.Voyage {
+	for _, v := range Voyages() {
+		if v.VoyageNumber == n {
+			return v
+		}
+	}
+	panic(fmt.Sprintf("builders: no sample voyage %s", n))
+}
+
+// ItineraryBuilder builds itineraries over the sample voyages.
+type ItineraryBuilder struct {
+	legs []shipping.Leg
+}
+
+// Itinerary returns a builder of an itinerary without legs.
+func Itinerary() *ItineraryBuilder {
+	return &ItineraryBuilder{}
+}
+
+// Leg adds a leg on the voyage from one of its port calls to a later one,
+// loading and unloading as scheduled. It panics if the voyage does not call
+// at the locations in that order.
+func (b *ItineraryBuilder) Leg(n shipping.VoyageNumber, from, to shipping.UNLocode) *ItineraryBuilder {
+	var (
+		load    time.Time
+		loading bool
+	)
+	for _, m := range Voyage(n).Schedule.CarrierMovements {
+		if !loading && m.DepartureLocation == from {
+			load, loading = m.DepartureTime, true
+		}
+		if loading && m.ArrivalLocation == to {
+			b.legs = append(b.legs, shipping.NewLeg(n, from, to, load, m.ArrivalTime))
+			return b
+		}
+	}
+	panic(fmt.Sprintf("builders: voyage %s does not call at %s and then %s", n, from, to))
+}
+
+// Build returns the itinerary.
+func (b *ItineraryBuilder) Build() shipping.Itinerary {
+	legs := make([]shipping.Leg, len(b.legs))
+	copy(legs, b.legs)
+	return shipping.Itinerary{Legs: legs}
+}
@@ -0,0 +1,247 @@
+package shipping
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// OpeningHours is a period of a day during which a location works, given as
+// offsets from local midnight.
+type OpeningHours struct {
+	Open  time.Duration
+	Close time.Duration
+}
+
+// WorkingCalendar holds the working hours and public holidays of a location.
+// Cargo is only handled at a location while it works; locations without a
+// calendar work around the clock.
+type WorkingCalendar struct {
+	Location UNLocode
+
+	// TimeZone is the IANA time zone name the hours and holidays are given
+	// in, such as "Europe/Stockholm".
+	TimeZone string
+
+	// Hours holds the opening hours of each day of the week, indexed by
+	// time.Weekday. Days without opening hours are closed.
+	Hours [7][]OpeningHours
+
+	// Holidays are the dates the location is closed. Only the date of each
+	// is used.
+	Holidays []time.Time
+}
+
+var (
+	// ErrUnknownCalendar is used when a location has no working calendar.
+	ErrUnknownCalendar = errors.New("unknown working calendar")
+
+	// ErrInvalidCalendar is used when a working calendar has an unknown time
+	// zone, or opening hours outside of the day or never open.
+	ErrInvalidCalendar = errors.New("invalid working calendar")
+)
+
+// searchDays bounds how far ahead or back the next or last working time is
+// searched for, so that a calendar closed for a long time is not searched
+// forever.
+const searchDays = 400
+
+// Validate returns ErrInvalidCalendar unless the calendar has a known time
+// zone and at least one period of opening hours, all within the day.
+func (c *WorkingCalendar) Validate() error {
+	if _, err := time.LoadLocation(c.TimeZone); err != nil {
+		return ErrInvalidCalendar
+	}
+
+	var open bool
+	for _, hours := range c.Hours {
+		for _, h := range hours {
+			if h.Open < 0 || h.Close > 24*time.Hour || h.Open >= h.Close {
+				return ErrInvalidCalendar
+			}
+			open = true
+		}
+	}
+	if !open {
+		return ErrInvalidCalendar
+	}
+
+	return nil
+}
+
+// period is a span of time during which a location works.
+type period struct {
+	from, to time.Time
+}
+
+// periods returns the periods the location works on the local date of t, in
+// order.
+func (c *WorkingCalendar) periods(t time.Time, loc *time.Location) []period {
+	t = t.In(loc)
+
+	y, m, d := t.Date()
+	for _, h := range c.Holidays {
+		if hy, hm, hd := h.Date(); hy == y && hm == m && hd == d {
+			return nil
+		}
+	}
+
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, loc)
+
+	var result []period
+	for _, h := range c.Hours[t.Weekday()] {
+		result = append(result, period{from: midnight.Add(h.Open), to: midnight.Add(h.Close)})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].from.Before(result[j].from)
+	})
+	return result
+}
+
+func (c *WorkingCalendar) location() *time.Location {
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// IsOpen returns whether the location works at t. A nil calendar is always
+// open.
+func (c *WorkingCalendar) IsOpen(t time.Time) bool {
+	if c == nil {
+		return true
+	}
+	for _, p := range c.periods(t, c.location()) {
+		if !t.Before(p.from) && t.Before(p.to) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns the earliest time at or after t that the location works.
+func (c *WorkingCalendar) NextOpen(t time.Time) time.Time {
+	if c == nil {
+		return t
+	}
+
+	loc := c.location()
+
+	day := t
+	for i := 0; i < searchDays; i++ {
+		for _, p := range c.periods(day, loc) {
+			if t.Before(p.to) {
+				if t.Before(p.from) {
+					return p.from
+				}
+				return t
+			}
+		}
+		day = nextDay(day, loc)
+	}
+	return t
+}
+
+// LastOpen returns the latest time at or before t that the location works,
+// i.e. t itself or the close of the last opening hours before it.
+func (c *WorkingCalendar) LastOpen(t time.Time) time.Time {
+	if c == nil {
+		return t
+	}
+
+	loc := c.location()
+
+	day := t
+	for i := 0; i < searchDays; i++ {
+		ps := c.periods(day, loc)
+		for k := len(ps) - 1; k >= 0; k-- {
+			p := ps[k]
+			if p.from.Before(t) {
+				if p.to.Before(t) {
+					return p.to
+				}
+				return t
+			}
+		}
+		day = prevDay(day, loc)
+	}
+	return t
+}
+
+// WorkingTime returns how much of the time between from and to the location
+// works.
+func (c *WorkingCalendar) WorkingTime(from, to time.Time) time.Duration {
+	if c == nil || !from.Before(to) {
+		return to.Sub(from)
+	}
+
+	loc := c.location()
+
+	var d time.Duration
+	for day := from; day.Before(to); day = nextDay(day, loc) {
+		for _, p := range c.periods(day, loc) {
+			start, end := p.from, p.to
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if start.Before(end) {
+				d += end.Sub(start)
+			}
+		}
+	}
+	return d
+}
+
+// AdjustWindow narrows the window during which an activity is expected to
+// take place to the hours the location works. A zero time leaves that end
+// of the window open.
+func (c *WorkingCalendar) AdjustWindow(from, to time.Time) (time.Time, time.Time) {
+	if !from.IsZero() {
+		from = c.NextOpen(from)
+	}
+	if !to.IsZero() {
+		to = c.LastOpen(to)
+	}
+	return from, to
+}
+
+// nextDay returns local midnight of the day after t.
+func nextDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+}
+
+// prevDay returns the last moment of the day before t.
+func prevDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc).Add(-time.Nanosecond)
+}
+
+// WorkingCalendars holds working calendars by location.
+type WorkingCalendars map[UNLocode]*WorkingCalendar
+
+// NewWorkingCalendars returns the calendars by location.
+func NewWorkingCalendars(cs []*WorkingCalendar) WorkingCalendars {
+	result := make(WorkingCalendars)
+	for _, c := range cs {
+		result[c.Location] = c
+	}
+	return result
+}
+
+// WorkingTime returns how much of the time between from and to a location
+// works.
+func (w WorkingCalendars) WorkingTime(locode UNLocode, from, to time.Time) time.Duration {
+	return w[locode].WorkingTime(from, to)
+}
+
+// WorkingCalendarRepository provides access a working calendar store.
+type WorkingCalendarRepository interface {
+	Store(c *WorkingCalendar) error
+	Find(locode UNLocode) (*WorkingCalendar, error)
+	FindAll() []*WorkingCalendar
+}
@@ -0,0 +1,106 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+// weekdays returns a calendar working 08:00 to 17:00 on weekdays in UTC.
+func weekdays() *WorkingCalendar {
+	c := &WorkingCalendar{Location: SESTO, TimeZone: "UTC"}
+	for d := time.Monday; d <= time.Friday; d++ {
+		c.Hours[d] = []OpeningHours{{Open: 8 * time.Hour, Close: 17 * time.Hour}}
+	}
+	return c
+}
+
+func TestWorkingCalendar(t *testing.T) {
+	var (
+		c = weekdays()
+
+		// Friday, 1 April 2016.
+		friday = time.Date(2016, time.April, 1, 0, 0, 0, 0, time.UTC)
+		monday = friday.Add(3 * 24 * time.Hour)
+	)
+
+	c.Holidays = []time.Time{monday}
+
+	tuesday := monday.Add(24 * time.Hour)
+
+	if !c.IsOpen(friday.Add(10 * time.Hour)) {
+		t.Errorf("IsOpen(Friday 10:00) = false; want = true")
+	}
+	if c.IsOpen(friday.Add(17 * time.Hour)) {
+		t.Errorf("IsOpen(Friday 17:00) = true; want = false")
+	}
+	if c.IsOpen(monday.Add(10 * time.Hour)) {
+		t.Errorf("IsOpen(holiday) = true; want = false")
+	}
+
+	if got, want := c.NextOpen(friday.Add(18*time.Hour)), tuesday.Add(8*time.Hour); !got.Equal(want) {
+		t.Errorf("NextOpen(Friday 18:00) = %v; want = %v", got, want)
+	}
+	if got, want := c.NextOpen(friday.Add(9*time.Hour)), friday.Add(9*time.Hour); !got.Equal(want) {
+		t.Errorf("NextOpen(Friday 09:00) = %v; want = %v", got, want)
+	}
+
+	if got, want := c.LastOpen(tuesday.Add(6*time.Hour)), friday.Add(17*time.Hour); !got.Equal(want) {
+		t.Errorf("LastOpen(Tuesday 06:00) = %v; want = %v", got, want)
+	}
+
+	// Friday 16:00 to Tuesday 09:00 is one hour on Friday and one on
+	// Tuesday.
+	if got, want := c.WorkingTime(friday.Add(16*time.Hour), tuesday.Add(9*time.Hour)), 2*time.Hour; got != want {
+		t.Errorf("WorkingTime() = %v; want = %v", got, want)
+	}
+}
+
+func TestWorkingCalendar_TimeZone(t *testing.T) {
+	c := weekdays()
+	c.TimeZone = "Asia/Hong_Kong"
+
+	// 08:00 in Hong Kong is midnight UTC.
+	at := time.Date(2016, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	if !c.IsOpen(at) {
+		t.Errorf("IsOpen(%v) = false; want = true", at)
+	}
+	if c.IsOpen(at.Add(-time.Minute)) {
+		t.Errorf("IsOpen(%v) = true; want = false", at.Add(-time.Minute))
+	}
+}
+
+func TestWorkingCalendar_Nil(t *testing.T) {
+	var (
+		c  *WorkingCalendar
+		at = time.Date(2016, time.April, 2, 3, 0, 0, 0, time.UTC)
+	)
+
+	if !c.IsOpen(at) {
+		t.Errorf("IsOpen() = false; want a missing calendar to be always open")
+	}
+	if got := c.WorkingTime(at, at.Add(time.Hour)); got != time.Hour {
+		t.Errorf("WorkingTime() = %v; want = %v", got, time.Hour)
+	}
+	if from, to := c.AdjustWindow(at, time.Time{}); !from.Equal(at) || !to.IsZero() {
+		t.Errorf("AdjustWindow() = %v, %v; want = %v, %v", from, to, at, time.Time{})
+	}
+}
+
+func TestWorkingCalendar_Validate(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		c     WorkingCalendar
+		valid bool
+	}{
+		{"weekdays", *weekdays(), true},
+		{"never open", WorkingCalendar{TimeZone: "UTC"}, false},
+		{"unknown time zone", WorkingCalendar{TimeZone: "Mars/Olympus_Mons", Hours: weekdays().Hours}, false},
+		{"closes before opening", WorkingCalendar{Hours: [7][]OpeningHours{{{Open: 17 * time.Hour, Close: 8 * time.Hour}}}}, false},
+		{"past midnight", WorkingCalendar{Hours: [7][]OpeningHours{{{Open: 20 * time.Hour, Close: 26 * time.Hour}}}}, false},
+	} {
+		if err := tt.c.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: err = %v", tt.name, err)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/pborman/uuid"
 )
@@ -15,10 +16,54 @@ type TrackingID string
 // Cargo is the central class in the domain model.
 type Cargo struct {
 	TrackingID         TrackingID
+	Customer           CustomerID
 	Origin             UNLocode
+	Equipment          EquipmentType
 	RouteSpecification RouteSpecification
 	Itinerary          Itinerary
 	Delivery           Delivery
+
+	// MasterID identifies the master shipment the cargo is consolidated
+	// into, if any.
+	MasterID TrackingID
+
+	// Consolidated holds the cargos consolidated into this cargo, if it is
+	// a master shipment.
+	Consolidated []TrackingID
+
+	// ReturnOf identifies the rejected cargo that this cargo returns to its
+	// origin, if any.
+	ReturnOf TrackingID
+
+	// ReturnedBy identifies the cargo returning this cargo to its origin,
+	// if it was rejected at its destination.
+	ReturnedBy TrackingID
+
+	// DuplicateOf identifies a cargo booked shortly before with the same
+	// customer and route specification, if this cargo was let through as a
+	// possible duplicate.
+	DuplicateOf TrackingID
+
+	// ServiceLevel is the level of service agreed for the cargo, if any, and
+	// SLABreaches the targets of it that the cargo has failed to meet.
+	ServiceLevel ServiceLevel
+	SLABreaches  []SLABreach
+
+	// SensorLimits holds the ranges the sensors traveling with the cargo
+	// are required to read within, such as for refrigerated cargo.
+	SensorLimits SensorLimits
+
+	// Screening is the outcome of screening the booking against sanctions
+	// and embargoes, if it was flagged for review.
+	Screening ScreeningResult
+
+	// Declaration holds what the cargo contains and the documents that come
+	// with it, checked against the import restrictions of its destination.
+	Declaration CargoDeclaration
+
+	// Rollovers counts the times the cargo has been rolled to a later
+	// sailing, for service level reporting.
+	Rollovers int
 }
 
 // SpecifyNewRoute specifies a new route for this cargo.
@@ -33,10 +78,47 @@ func (c *Cargo) AssignToRoute(itinerary Itinerary) {
 	c.Delivery = c.Delivery.UpdateOnRouting(c.RouteSpecification, c.Itinerary)
 }
 
+// ConfirmLeg records that the carrier has confirmed the booking of a leg of
+// the itinerary of this cargo.
+func (c *Cargo) ConfirmLeg(voyage VoyageNumber, from UNLocode, reference string) error {
+	itinerary, err := c.Itinerary.ConfirmLeg(voyage, from, reference)
+	if err != nil {
+		return err
+	}
+	c.AssignToRoute(itinerary)
+	return nil
+}
+
+// RejectLeg records that the carrier has refused to book a leg of the
+// itinerary of this cargo, which disrupts the route.
+func (c *Cargo) RejectLeg(voyage VoyageNumber, from UNLocode) error {
+	itinerary, err := c.Itinerary.RejectLeg(voyage, from)
+	if err != nil {
+		return err
+	}
+	itinerary.Disrupted = true
+	c.AssignToRoute(itinerary)
+	return nil
+}
+
+// DisruptRoute flags the itinerary of this cargo as disrupted, leaving the
+// cargo misrouted until it is assigned to a new route.
+func (c *Cargo) DisruptRoute() {
+	itinerary := c.Itinerary
+	itinerary.Disrupted = true
+	c.AssignToRoute(itinerary)
+}
+
 // DeriveDeliveryProgress updates all aspects of the cargo aggregate status
 // based on the current route specification, itinerary and handling of the cargo.
 func (c *Cargo) DeriveDeliveryProgress(history HandlingHistory) {
-	c.Delivery = DeriveDeliveryFrom(c.RouteSpecification, c.Itinerary, history)
+	c.DeriveDeliveryProgressWith(DefaultDeliveryPolicy, history)
+}
+
+// DeriveDeliveryProgressWith updates the delivery of the cargo like
+// DeriveDeliveryProgress, interpreting its handling according to a policy.
+func (c *Cargo) DeriveDeliveryProgressWith(p DeliveryPolicy, history HandlingHistory) {
+	c.Delivery = DeriveDeliveryWith(p, c.RouteSpecification, c.Itinerary, history).keepEstimate(c.Delivery)
 }
 
 // NewCargo creates a new, unrouted cargo.
@@ -57,6 +139,14 @@ type CargoRepository interface {
 	Store(cargo *Cargo) error
 	Find(id TrackingID) (*Cargo, error)
 	FindAll() []*Cargo
+
+	// ForEach calls fn for every stored cargo without loading all of them
+	// into memory at once. Iteration stops at the first error returned by
+	// fn, which is then returned.
+	ForEach(fn func(*Cargo) error) error
+
+	// FindMatching returns the cargos matching a query.
+	FindMatching(q CargoQuery) ([]*Cargo, error)
 }
 
 // ErrUnknownCargo is used when a cargo could not be found.
@@ -68,20 +158,81 @@ func NextTrackingID() TrackingID {
 	return TrackingID(strings.Split(strings.ToUpper(uuid.New()), "-")[0])
 }
 
+// NormalizeTrackingID returns a tracking ID as typed or pasted by a person
+// in the form tracking IDs are generated in: upper case, without whitespace
+// or dashes.
+func NormalizeTrackingID(s string) TrackingID {
+	return TrackingID(strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, s))
+}
+
 // RouteSpecification Contains information about a route: its origin,
 // destination and arrival deadline.
 type RouteSpecification struct {
 	Origin          UNLocode
 	Destination     UNLocode
 	ArrivalDeadline time.Time
+
+	// DeadlineType is how strictly the arrival deadline holds.
+	DeadlineType DeadlineType
 }
 
 // IsSatisfiedBy checks whether provided itinerary satisfies this
-// specification.
+// specification. An itinerary arriving after a hard deadline does not.
 func (s RouteSpecification) IsSatisfiedBy(itinerary Itinerary) bool {
 	return itinerary.Legs != nil &&
 		s.Origin == itinerary.InitialDepartureLocation() &&
-		s.Destination == itinerary.FinalArrivalLocation()
+		s.Destination == itinerary.FinalArrivalLocation() &&
+		!(s.DeadlineType == HardDeadline && s.IsLate(itinerary))
+}
+
+// IsLate checks whether provided itinerary arrives after the arrival
+// deadline. Itineraries arriving at unknown times are not late.
+func (s RouteSpecification) IsLate(itinerary Itinerary) bool {
+	arrival := itinerary.FinalArrivalTime()
+	return !s.ArrivalDeadline.IsZero() && !arrival.IsZero() && arrival.After(s.ArrivalDeadline)
+}
+
+// DeadlineType describes how strictly an arrival deadline holds.
+type DeadlineType int
+
+// Valid deadline types.
+const (
+	// SoftDeadline may be missed: routes arriving late are offered, flagged
+	// as late, and cargos projected to arrive late are at risk.
+	SoftDeadline DeadlineType = iota
+
+	// HardDeadline must be met: routes arriving late are neither offered
+	// nor assigned, cargos routed to arrive late are misrouted, and
+	// arriving late breaches the service level of the cargo.
+	HardDeadline
+)
+
+func (t DeadlineType) String() string {
+	switch t {
+	case SoftDeadline:
+		return "Soft"
+	case HardDeadline:
+		return "Hard"
+	}
+	return ""
+}
+
+// ErrUnknownDeadlineType is used when parsing an unsupported deadline type.
+var ErrUnknownDeadlineType = errors.New("unknown deadline type")
+
+// ParseDeadlineType returns the deadline type with a name, ignoring case.
+func ParseDeadlineType(s string) (DeadlineType, error) {
+	for _, t := range []DeadlineType{SoftDeadline, HardDeadline} {
+		if strings.EqualFold(s, t.String()) {
+			return t, nil
+		}
+	}
+	return SoftDeadline, ErrUnknownDeadlineType
 }
 
 // RoutingStatus describes status of cargo routing.
@@ -0,0 +1,408 @@
+package shipping
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CargoField is a field cargos can be searched by.
+type CargoField string
+
+// Searchable fields.
+const (
+	FieldStatus      CargoField = "status"      // transport status, e.g. IN_PORT
+	FieldRouting     CargoField = "routing"     // routing status, e.g. MISROUTED
+	FieldOrigin      CargoField = "origin"      // UN/LOCODE
+	FieldDestination CargoField = "destination" // UN/LOCODE
+	FieldLocation    CargoField = "location"    // last known location
+	FieldVoyage      CargoField = "voyage"      // current voyage
+	FieldCustomer    CargoField = "customer"
+	FieldEquipment   CargoField = "equipment"
+	FieldETA         CargoField = "eta"      // RFC 3339 or date
+	FieldDeadline    CargoField = "deadline" // arrival deadline
+	FieldMisdirected CargoField = "misdirected"
+)
+
+// Operator compares a field of a cargo to values.
+type Operator string
+
+// Valid operators. Fields holding times support every operator, the others
+// equality only.
+const (
+	OpEqual          Operator = "="
+	OpNotEqual       Operator = "!="
+	OpLess           Operator = "<"
+	OpLessOrEqual    Operator = "<="
+	OpGreater        Operator = ">"
+	OpGreaterOrEqual Operator = ">="
+	OpIn             Operator = "IN"
+)
+
+// CargoCondition is a comparison of a field of cargos to values, of the type
+// of the field, such as TransportStatus or time.Time. All operators but IN
+// compare to one value.
+type CargoCondition struct {
+	Field  CargoField
+	Op     Operator
+	Values []interface{}
+}
+
+// CargoQuery selects cargos by conditions, all of which must hold. The empty
+// query selects every cargo.
+type CargoQuery struct {
+	Conditions []CargoCondition
+}
+
+// Matches returns whether a cargo satisfies every condition of the query.
+func (q CargoQuery) Matches(c *Cargo) bool {
+	for _, cond := range q.Conditions {
+		if !cond.Matches(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches returns whether a cargo satisfies the condition.
+func (cond CargoCondition) Matches(c *Cargo) bool {
+	v := cond.Field.value(c)
+
+	switch cond.Op {
+	case OpIn:
+		for _, w := range cond.Values {
+			if equal(v, w) {
+				return true
+			}
+		}
+		return false
+	case OpEqual:
+		return equal(v, cond.Values[0])
+	case OpNotEqual:
+		return !equal(v, cond.Values[0])
+	}
+
+	t, ok := v.(time.Time)
+	if !ok || t.IsZero() {
+		return false
+	}
+	w := cond.Values[0].(time.Time)
+
+	switch cond.Op {
+	case OpLess:
+		return t.Before(w)
+	case OpLessOrEqual:
+		return !t.After(w)
+	case OpGreater:
+		return t.After(w)
+	case OpGreaterOrEqual:
+		return !t.Before(w)
+	}
+	return false
+}
+
+func equal(v, w interface{}) bool {
+	if t, ok := v.(time.Time); ok {
+		return t.Equal(w.(time.Time))
+	}
+	return v == w
+}
+
+// value returns the value of the field of a cargo.
+func (f CargoField) value(c *Cargo) interface{} {
+	switch f {
+	case FieldStatus:
+		return c.Delivery.TransportStatus
+	case FieldRouting:
+		return c.Delivery.RoutingStatus
+	case FieldOrigin:
+		return c.Origin
+	case FieldDestination:
+		return c.RouteSpecification.Destination
+	case FieldLocation:
+		return c.Delivery.LastKnownLocation
+	case FieldVoyage:
+		return c.Delivery.CurrentVoyage
+	case FieldCustomer:
+		return c.Customer
+	case FieldEquipment:
+		return c.Equipment
+	case FieldETA:
+		return c.Delivery.ETA
+	case FieldDeadline:
+		return c.RouteSpecification.ArrivalDeadline
+	case FieldMisdirected:
+		return c.Delivery.IsMisdirected
+	}
+	return nil
+}
+
+// parse parses a value of the field.
+func (f CargoField) parse(s string) (interface{}, error) {
+	switch f {
+	case FieldStatus:
+		for _, ts := range []TransportStatus{NotReceived, InPort, OnboardCarrier, Claimed, Unknown} {
+			if matchesConstant(s, ts.String()) {
+				return ts, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown transport status %q", s)
+	case FieldRouting:
+		for _, rs := range []RoutingStatus{NotRouted, Misrouted, Routed} {
+			if matchesConstant(s, rs.String()) {
+				return rs, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown routing status %q", s)
+	case FieldOrigin, FieldDestination, FieldLocation:
+		return UNLocode(strings.ToUpper(s)), nil
+	case FieldVoyage:
+		return VoyageNumber(s), nil
+	case FieldCustomer:
+		return CustomerID(s), nil
+	case FieldEquipment:
+		e, err := ParseEquipmentType(s)
+		if err != nil {
+			return nil, fmt.Errorf("unknown equipment type %q", s)
+		}
+		return e, nil
+	case FieldETA, FieldDeadline:
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t, nil
+		}
+		if t, err := time.Parse("2006-01-02", s); err == nil {
+			return t, nil
+		}
+		return nil, fmt.Errorf("invalid time %q, want RFC 3339 or YYYY-MM-DD", s)
+	case FieldMisdirected:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", s)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("unknown field %q", f)
+}
+
+// ordered returns whether the values of the field can be compared by order.
+func (f CargoField) ordered() bool {
+	return f == FieldETA || f == FieldDeadline
+}
+
+// matchesConstant returns whether s names a status, written either as shown,
+// e.g. "In port", or as a constant, e.g. IN_PORT.
+func matchesConstant(s, name string) bool {
+	return strings.EqualFold(strings.Replace(s, "_", " ", -1), name)
+}
+
+// parseCargoField parses the name of a searchable field.
+func parseCargoField(s string) (CargoField, bool) {
+	for _, f := range []CargoField{FieldStatus, FieldRouting, FieldOrigin, FieldDestination, FieldLocation,
+		FieldVoyage, FieldCustomer, FieldEquipment, FieldETA, FieldDeadline, FieldMisdirected} {
+		if strings.EqualFold(s, string(f)) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// CargoQueryError is used when a cargo query fails to parse.
+type CargoQueryError struct {
+	// Offset is the byte offset into the query the error was found at.
+	Offset int
+	Msg    string
+}
+
+func (e *CargoQueryError) Error() string {
+	return fmt.Sprintf("invalid query at offset %d: %s", e.Offset, e.Msg)
+}
+
+// ParseCargoQuery parses a filter expression, such as
+//
+//	status=IN_PORT AND destination=SESTO AND eta<2016-07-01
+//
+// Conditions compare a field to a value with =, !=, <, <=, > or >=, or to a
+// list of values with IN, e.g. destination IN (SESTO, FIHEL). Conditions are
+// joined by AND, and keywords are matched ignoring case. Values containing
+// spaces or punctuation are quoted, e.g. customer="ACME Ltd".
+func ParseCargoQuery(s string) (CargoQuery, error) {
+	toks, err := tokenize(s)
+	if err != nil {
+		return CargoQuery{}, err
+	}
+
+	p := &queryParser{toks: toks, end: len(s)}
+
+	var q CargoQuery
+	if len(toks) == 0 {
+		return q, nil
+	}
+
+	for {
+		cond, err := p.condition()
+		if err != nil {
+			return CargoQuery{}, err
+		}
+		q.Conditions = append(q.Conditions, cond)
+
+		if p.done() {
+			return q, nil
+		}
+		if t := p.next(); t.kind != tokWord || !strings.EqualFold(t.text, "AND") {
+			return CargoQuery{}, p.errorf(t, "expected AND, got %s", t)
+		}
+	}
+}
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokString
+	tokOp
+	tokPunct
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+// tokenize splits a query into words, quoted strings, operators and
+// parentheses and commas.
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		switch ch := s[i]; {
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			i++
+		case ch == '(' || ch == ')' || ch == ',':
+			toks = append(toks, token{tokPunct, string(ch), i})
+			i++
+		case ch == '=' || ch == '!' || ch == '<' || ch == '>':
+			j := i + 1
+			if j < len(s) && s[j] == '=' {
+				j++
+			}
+			op := s[i:j]
+			if op == "!" {
+				return nil, &CargoQueryError{Offset: i, Msg: `expected "!="`}
+			}
+			toks = append(toks, token{tokOp, op, i})
+			i = j
+		case ch == '"':
+			j := strings.IndexByte(s[i+1:], '"')
+			if j < 0 {
+				return nil, &CargoQueryError{Offset: i, Msg: "unterminated string"}
+			}
+			toks = append(toks, token{tokString, s[i+1 : i+1+j], i})
+			i += j + 2
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\n(),=!<>\"", rune(s[j])) {
+				j++
+			}
+			toks = append(toks, token{tokWord, s[i:j], i})
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+func (t token) String() string {
+	if t.kind == tokPunct && t.text == "" {
+		return "end of query"
+	}
+	return strconv.Quote(t.text)
+}
+
+type queryParser struct {
+	toks []token
+	pos  int
+	end  int
+}
+
+func (p *queryParser) done() bool {
+	return p.pos == len(p.toks)
+}
+
+// next returns the next token, or an empty one at the end of the query.
+func (p *queryParser) next() token {
+	if p.done() {
+		return token{kind: tokPunct, offset: p.end}
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	return t
+}
+
+func (p *queryParser) errorf(t token, format string, args ...interface{}) error {
+	return &CargoQueryError{Offset: t.offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+// condition parses a field, an operator and its values.
+func (p *queryParser) condition() (CargoCondition, error) {
+	t := p.next()
+	if t.kind != tokWord {
+		return CargoCondition{}, p.errorf(t, "expected field, got %s", t)
+	}
+	field, ok := parseCargoField(t.text)
+	if !ok {
+		return CargoCondition{}, p.errorf(t, "unknown field %q", t.text)
+	}
+
+	cond := CargoCondition{Field: field}
+
+	t = p.next()
+	switch {
+	case t.kind == tokOp:
+		cond.Op = Operator(t.text)
+		if cond.Op != OpEqual && cond.Op != OpNotEqual && !field.ordered() {
+			return CargoCondition{}, p.errorf(t, "%s can only be compared with = or !=", field)
+		}
+		v, err := p.value(field)
+		if err != nil {
+			return CargoCondition{}, err
+		}
+		cond.Values = []interface{}{v}
+	case t.kind == tokWord && strings.EqualFold(t.text, "IN"):
+		cond.Op = OpIn
+		if t := p.next(); t.text != "(" {
+			return CargoCondition{}, p.errorf(t, "expected (, got %s", t)
+		}
+		for {
+			v, err := p.value(field)
+			if err != nil {
+				return CargoCondition{}, err
+			}
+			cond.Values = append(cond.Values, v)
+
+			t := p.next()
+			if t.text == ")" && t.kind == tokPunct {
+				break
+			}
+			if t.text != "," || t.kind != tokPunct {
+				return CargoCondition{}, p.errorf(t, "expected , or ), got %s", t)
+			}
+		}
+	default:
+		return CargoCondition{}, p.errorf(t, "expected operator, got %s", t)
+	}
+
+	return cond, nil
+}
+
+// value parses a value of a field.
+func (p *queryParser) value(field CargoField) (interface{}, error) {
+	t := p.next()
+	if t.kind != tokWord && t.kind != tokString {
+		return nil, p.errorf(t, "expected value, got %s", t)
+	}
+	v, err := field.parse(t.text)
+	if err != nil {
+		return nil, &CargoQueryError{Offset: t.offset, Msg: err.Error()}
+	}
+	return v, nil
+}
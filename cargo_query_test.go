@@ -0,0 +1,107 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCargoQuery(t *testing.T) {
+	q, err := ParseCargoQuery(`status=IN_PORT and destination IN (sesto, FIHEL) AND eta<2016-07-01 AND customer="ACME Ltd"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []CargoCondition{
+		{FieldStatus, OpEqual, []interface{}{InPort}},
+		{FieldDestination, OpIn, []interface{}{SESTO, FIHEL}},
+		{FieldETA, OpLess, []interface{}{time.Date(2016, 7, 1, 0, 0, 0, 0, time.UTC)}},
+		{FieldCustomer, OpEqual, []interface{}{CustomerID("ACME Ltd")}},
+	}
+	if len(q.Conditions) != len(want) {
+		t.Fatalf("len(conditions) = %d; want = %d", len(q.Conditions), len(want))
+	}
+	for i, cond := range q.Conditions {
+		if cond.Field != want[i].Field || cond.Op != want[i].Op || len(cond.Values) != len(want[i].Values) {
+			t.Errorf("conditions[%d] = %+v; want = %+v", i, cond, want[i])
+			continue
+		}
+		for j, v := range cond.Values {
+			if !equal(v, want[i].Values[j]) {
+				t.Errorf("conditions[%d].Values[%d] = %v; want = %v", i, j, v, want[i].Values[j])
+			}
+		}
+	}
+
+	if q, err := ParseCargoQuery("  "); err != nil || len(q.Conditions) != 0 {
+		t.Errorf("empty query = %+v, %v; want no conditions", q, err)
+	}
+}
+
+func TestParseCargoQuery_Errors(t *testing.T) {
+	tests := []struct {
+		in     string
+		offset int
+	}{
+		{"weight=10", 0},
+		{"destination SESTO", 12},
+		{"destination<SESTO", 11},
+		{"status=ASHORE", 7},
+		{"eta>tomorrow", 4},
+		{"status=IN_PORT OR status=CLAIMED", 15},
+		{"destination IN (SESTO", 21},
+		{`customer="ACME`, 9},
+		{"status=", 7},
+		{"status!IN_PORT", 6},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseCargoQuery(tt.in)
+		e, ok := err.(*CargoQueryError)
+		if !ok {
+			t.Errorf("%q: err = %v; want a CargoQueryError", tt.in, err)
+			continue
+		}
+		if e.Offset != tt.offset {
+			t.Errorf("%q: offset = %d; want = %d (%v)", tt.in, e.Offset, tt.offset, e)
+		}
+	}
+}
+
+func TestCargoQuery_Matches(t *testing.T) {
+	c := NewCargo("ABC123", RouteSpecification{
+		Origin:          CNHKG,
+		Destination:     SESTO,
+		ArrivalDeadline: time.Date(2016, 7, 1, 0, 0, 0, 0, time.UTC),
+	})
+	c.Delivery.TransportStatus = InPort
+	c.Delivery.ETA = time.Date(2016, 6, 20, 0, 0, 0, 0, time.UTC)
+
+	unrouted := NewCargo("XYZ789", RouteSpecification{Origin: CNHKG, Destination: SESTO})
+
+	tests := []struct {
+		in       string
+		c        *Cargo
+		expected bool
+	}{
+		{"status=IN_PORT AND destination=SESTO AND eta<2016-07-01", c, true},
+		{"status=\"in port\"", c, true},
+		{"status!=IN_PORT", c, false},
+		{"destination IN (FIHEL, SESTO)", c, true},
+		{"destination IN (FIHEL, DEHAM)", c, false},
+		{"eta>=2016-06-20T00:00:00Z AND deadline<=2016-07-01", c, true},
+		{"eta<2016-07-01", unrouted, false},
+		{"misdirected=false AND equipment=20ft", c, true},
+		{"", unrouted, true},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseCargoQuery(tt.in)
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+			continue
+		}
+		if got := q.Matches(tt.c); got != tt.expected {
+			t.Errorf("%q matches %s = %v; want = %v", tt.in, tt.c.TrackingID, got, tt.expected)
+		}
+	}
+}
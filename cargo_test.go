@@ -66,6 +66,18 @@ func TestRoutingStatus(t *testing.T) {
 		t.Errorf("RoutingStatus = %v; want = %v",
 			c.Delivery.RoutingStatus, Routed)
 	}
+
+	c.DisruptRoute()
+	if c.Delivery.RoutingStatus != Misrouted {
+		t.Errorf("RoutingStatus = %v; want = %v",
+			c.Delivery.RoutingStatus, Misrouted)
+	}
+
+	c.AssignToRoute(good)
+	if c.Delivery.RoutingStatus != Routed {
+		t.Errorf("RoutingStatus = %v; want = %v",
+			c.Delivery.RoutingStatus, Routed)
+	}
 }
 
 func TestLastKnownLocation_WhenNoEvents(t *testing.T) {
@@ -182,3 +194,50 @@ func populateCargoClaimedInMelbourne() *Cargo {
 
 	return c
 }
+
+func TestNormalizeTrackingID(t *testing.T) {
+	for in, want := range map[string]TrackingID{
+		"ABC123":    "ABC123",
+		" abc123\n": "ABC123",
+		"abc 123":   "ABC123",
+		"Abc-123\t": "ABC123",
+		" FTL456":   "FTL456",
+	} {
+		if got := NormalizeTrackingID(in); got != want {
+			t.Errorf("NormalizeTrackingID(%q) = %q; want = %q", in, got, want)
+		}
+	}
+}
+
+func TestRouteSpecification_IsSatisfiedBy_Deadline(t *testing.T) {
+	deadline := time.Date(2016, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	itinerary := func(arrival time.Time) Itinerary {
+		return Itinerary{Legs: []Leg{NewLeg("V1", CNHKG, SESTO, deadline.AddDate(0, 0, -20), arrival)}}
+	}
+
+	tests := []struct {
+		typ     DeadlineType
+		arrival time.Time
+		late    bool
+		want    bool
+	}{
+		{SoftDeadline, deadline.Add(-time.Hour), false, true},
+		{SoftDeadline, deadline.Add(time.Hour), true, true},
+		{HardDeadline, deadline.Add(-time.Hour), false, true},
+		{HardDeadline, deadline.Add(time.Hour), true, false},
+		{HardDeadline, time.Time{}, false, true},
+	}
+
+	for _, tt := range tests {
+		rs := RouteSpecification{Origin: CNHKG, Destination: SESTO, ArrivalDeadline: deadline, DeadlineType: tt.typ}
+		i := itinerary(tt.arrival)
+
+		if got := rs.IsLate(i); got != tt.late {
+			t.Errorf("%s deadline, arrival %s: IsLate = %v; want = %v", tt.typ, tt.arrival, got, tt.late)
+		}
+		if got := rs.IsSatisfiedBy(i); got != tt.want {
+			t.Errorf("%s deadline, arrival %s: IsSatisfiedBy = %v; want = %v", tt.typ, tt.arrival, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package carrier
+
+import (
+	"context"
+	"fmt"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/booking"
+)
+
+// Adapter requests the bookings of legs from carriers that confirm them
+// synchronously, rather than through the carrier API.
+type Adapter interface {
+	// BookLeg requests the booking of a leg of a cargo, and returns the
+	// booking reference of the carrier.
+	BookLeg(ctx context.Context, id shipping.TrackingID, l shipping.Leg) (string, error)
+}
+
+type mockAdapter struct{}
+
+func (mockAdapter) BookLeg(ctx context.Context, id shipping.TrackingID, l shipping.Leg) (string, error) {
+	return fmt.Sprintf("%s-%s-%s", l.VoyageNumber, l.LoadLocation, id), nil
+}
+
+// NewMockAdapter returns an adapter confirming every leg, standing in for
+// carriers that have not been integrated.
+func NewMockAdapter() Adapter {
+	return mockAdapter{}
+}
+
+type bookingEventHandler struct {
+	cargos  shipping.CargoRepository
+	adapter Adapter
+}
+
+// CargoWasRouted requests the bookings of the legs of a newly routed cargo
+// through the adapter. Legs the adapter fails to book are left pending, for
+// the carrier to confirm through the carrier API.
+func (h *bookingEventHandler) CargoWasRouted(ctx context.Context, c *shipping.Cargo) {
+	if c.IsConsolidated() {
+		return
+	}
+
+	for _, l := range c.Itinerary.Legs {
+		if l.Status != shipping.LegPending {
+			continue
+		}
+
+		ref, err := h.adapter.BookLeg(ctx, c.TrackingID, l)
+		if err != nil || ref == "" {
+			continue
+		}
+
+		decide(h.cargos, c.TrackingID, l.VoyageNumber, l.LoadLocation, func(c *shipping.Cargo) error {
+			return c.ConfirmLeg(l.VoyageNumber, l.LoadLocation, ref)
+		})
+	}
+}
+
+// CargoWasRolled requests the bookings of the legs rebuilt when rolling a
+// cargo, like CargoWasRouted.
+func (h *bookingEventHandler) CargoWasRolled(ctx context.Context, c *shipping.Cargo, from shipping.VoyageNumber) {
+	h.CargoWasRouted(ctx, c)
+}
+
+// CargoWasBooked does nothing; legs are booked once the cargo is routed.
+func (h *bookingEventHandler) CargoWasBooked(ctx context.Context, c *shipping.Cargo) {
+}
+
+// CargoWasTransferred does nothing; the carriers book legs for the shipper
+// of record, whoever owns the cargo.
+func (h *bookingEventHandler) CargoWasTransferred(ctx context.Context, c *shipping.Cargo, from shipping.CustomerID) {
+}
+
+// NewBookingEventHandler returns a booking event handler requesting the
+// bookings of the legs of routed cargos through an adapter.
+func NewBookingEventHandler(cargos shipping.CargoRepository, adapter Adapter) booking.EventHandler {
+	return &bookingEventHandler{
+		cargos:  cargos,
+		adapter: adapter,
+	}
+}
@@ -0,0 +1,52 @@
+package carrier
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) PendingLegs(ctx context.Context, token string, voyage shipping.VoyageNumber) ([]Leg, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "pending_legs").Add(1)
+		s.requestLatency.With("method", "pending_legs").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.PendingLegs(ctx, token, voyage)
+}
+
+func (s *instrumentingService) ConfirmLeg(ctx context.Context, token string, id shipping.TrackingID, voyage shipping.VoyageNumber, from shipping.UNLocode, reference string) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "confirm_leg").Add(1)
+		s.requestLatency.With("method", "confirm_leg").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.ConfirmLeg(ctx, token, id, voyage, from, reference)
+}
+
+func (s *instrumentingService) RejectLeg(ctx context.Context, token string, id shipping.TrackingID, voyage shipping.VoyageNumber, from shipping.UNLocode) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "reject_leg").Add(1)
+		s.requestLatency.With("method", "reject_leg").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.RejectLeg(ctx, token, id, voyage, from)
+}
@@ -0,0 +1,65 @@
+package carrier
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) PendingLegs(ctx context.Context, token string, voyage shipping.VoyageNumber) (legs []Leg, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "pending_legs",
+			"request_id", correlation.FromContext(ctx),
+			"voyage", voyage,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.PendingLegs(ctx, token, voyage)
+}
+
+func (s *loggingService) ConfirmLeg(ctx context.Context, token string, id shipping.TrackingID, voyage shipping.VoyageNumber, from shipping.UNLocode, reference string) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "confirm_leg",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"voyage", voyage,
+			"from", from,
+			"reference", reference,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.ConfirmLeg(ctx, token, id, voyage, from, reference)
+}
+
+func (s *loggingService) RejectLeg(ctx context.Context, token string, id shipping.TrackingID, voyage shipping.VoyageNumber, from shipping.UNLocode) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "reject_leg",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"voyage", voyage,
+			"from", from,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.RejectLeg(ctx, token, id, voyage, from)
+}
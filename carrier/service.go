@@ -0,0 +1,170 @@
+// Package carrier provides the use-case of carriers confirming the bookings
+// of the legs of itineraries. Until the carrier of a leg has confirmed it,
+// the cargo is not expected to be loaded onto the voyage.
+package carrier
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// ErrInvalidToken is returned when the token does not match the one shared
+// with carriers.
+var ErrInvalidToken = errors.New("invalid carrier token")
+
+// Service is the interface that provides carrier methods. The token
+// authenticates the carrier.
+type Service interface {
+	// PendingLegs returns the legs on a voyage awaiting confirmation.
+	PendingLegs(ctx context.Context, token string, voyage shipping.VoyageNumber) ([]Leg, error)
+
+	// ConfirmLeg confirms the booking of the leg of a cargo loading at a
+	// location onto a voyage, with the booking reference of the carrier.
+	ConfirmLeg(ctx context.Context, token string, id shipping.TrackingID, voyage shipping.VoyageNumber, from shipping.UNLocode, reference string) error
+
+	// RejectLeg refuses the booking of the leg of a cargo loading at a
+	// location onto a voyage, which leaves the cargo misrouted.
+	RejectLeg(ctx context.Context, token string, id shipping.TrackingID, voyage shipping.VoyageNumber, from shipping.UNLocode) error
+}
+
+type service struct {
+	cargos shipping.CargoRepository
+	token  string
+}
+
+func (s *service) PendingLegs(ctx context.Context, token string, voyage shipping.VoyageNumber) ([]Leg, error) {
+	if err := s.authenticate(token); err != nil {
+		return nil, err
+	}
+
+	if voyage == "" {
+		return nil, ErrInvalidArgument
+	}
+
+	result := []Leg{}
+	err := s.cargos.ForEach(func(c *shipping.Cargo) error {
+		// Consolidated cargos travel on the bookings of their master
+		// shipment.
+		if c.IsConsolidated() {
+			return nil
+		}
+		for _, l := range c.Itinerary.Legs {
+			if l.VoyageNumber == voyage && l.Status == shipping.LegPending {
+				result = append(result, assemble(c.TrackingID, l))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *service) ConfirmLeg(ctx context.Context, token string, id shipping.TrackingID, voyage shipping.VoyageNumber, from shipping.UNLocode, reference string) error {
+	if err := s.authenticate(token); err != nil {
+		return err
+	}
+
+	if reference == "" {
+		return ErrInvalidArgument
+	}
+
+	return decide(s.cargos, id, voyage, from, func(c *shipping.Cargo) error {
+		return c.ConfirmLeg(voyage, from, reference)
+	})
+}
+
+func (s *service) RejectLeg(ctx context.Context, token string, id shipping.TrackingID, voyage shipping.VoyageNumber, from shipping.UNLocode) error {
+	if err := s.authenticate(token); err != nil {
+		return err
+	}
+
+	return decide(s.cargos, id, voyage, from, func(c *shipping.Cargo) error {
+		return c.RejectLeg(voyage, from)
+	})
+}
+
+func (s *service) authenticate(token string) error {
+	if s.token == "" || !hmac.Equal([]byte(token), []byte(s.token)) {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// decide confirms or rejects a leg of a cargo, and passes the itinerary on
+// to the cargos consolidated into it.
+func decide(cargos shipping.CargoRepository, id shipping.TrackingID, voyage shipping.VoyageNumber, from shipping.UNLocode, fn func(*shipping.Cargo) error) error {
+	if id == "" || voyage == "" || from == "" {
+		return ErrInvalidArgument
+	}
+
+	c, err := cargos.Find(id)
+	if err != nil {
+		return err
+	}
+
+	if c.IsConsolidated() {
+		return shipping.ErrConsolidated
+	}
+
+	if err := fn(c); err != nil {
+		return err
+	}
+
+	if err := cargos.Store(c); err != nil {
+		return err
+	}
+
+	for _, mid := range c.Consolidated {
+		m, err := cargos.Find(mid)
+		if err != nil {
+			continue
+		}
+
+		m.AssignToRoute(c.Itinerary)
+		cargos.Store(m)
+	}
+
+	return nil
+}
+
+// NewService creates a carrier service with necessary dependencies. The
+// token is shared with carriers; requests are refused if it is empty.
+func NewService(cargos shipping.CargoRepository, token string) Service {
+	return &service{
+		cargos: cargos,
+		token:  token,
+	}
+}
+
+// Leg is a read model for carrier views.
+type Leg struct {
+	TrackingID   string    `json:"tracking_id"`
+	VoyageNumber string    `json:"voyage_number"`
+	From         string    `json:"from"`
+	To           string    `json:"to"`
+	LoadTime     time.Time `json:"load_time"`
+	UnloadTime   time.Time `json:"unload_time"`
+	Status       string    `json:"status"`
+}
+
+func assemble(id shipping.TrackingID, l shipping.Leg) Leg {
+	return Leg{
+		TrackingID:   string(id),
+		VoyageNumber: string(l.VoyageNumber),
+		From:         string(l.LoadLocation),
+		To:           string(l.UnloadLocation),
+		LoadTime:     l.LoadTime,
+		UnloadTime:   l.UnloadTime,
+		Status:       string(l.Status),
+	}
+}
@@ -0,0 +1,146 @@
+package carrier
+
+import (
+	"context"
+	"testing"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+func newCargoRepository(cargos ...*shipping.Cargo) *mock.CargoRepository {
+	byID := make(map[shipping.TrackingID]*shipping.Cargo)
+	for _, c := range cargos {
+		byID[c.TrackingID] = c
+	}
+
+	var r mock.CargoRepository
+	r.StoreFn = func(c *shipping.Cargo) error {
+		byID[c.TrackingID] = c
+		return nil
+	}
+	r.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		if c, ok := byID[id]; ok {
+			return c, nil
+		}
+		return nil, shipping.ErrUnknownCargo
+	}
+	r.ForEachFn = func(fn func(*shipping.Cargo) error) error {
+		for _, c := range cargos {
+			if err := fn(byID[c.TrackingID]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return &r
+}
+
+func newRoutedCargo(id shipping.TrackingID) *shipping.Cargo {
+	c := shipping.NewCargo(id, shipping.RouteSpecification{
+		Origin:      shipping.CNHKG,
+		Destination: shipping.SESTO,
+	})
+	c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+		{VoyageNumber: "V100", LoadLocation: shipping.CNHKG, UnloadLocation: shipping.DEHAM},
+		{VoyageNumber: "V200", LoadLocation: shipping.DEHAM, UnloadLocation: shipping.SESTO},
+	}}.PendingConfirmation())
+	return c
+}
+
+func TestConfirmLeg(t *testing.T) {
+	var (
+		master = newRoutedCargo("MASTER")
+		member = newRoutedCargo("MEMBER")
+	)
+	master.Consolidated = []shipping.TrackingID{member.TrackingID}
+	member.MasterID = master.TrackingID
+
+	cargos := newCargoRepository(master, member)
+
+	s := NewService(cargos, "secret")
+
+	ctx := context.Background()
+
+	if err := s.ConfirmLeg(ctx, "wrong", "MASTER", "V100", shipping.CNHKG, "REF-1"); err != ErrInvalidToken {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidToken)
+	}
+	if err := s.ConfirmLeg(ctx, "secret", "MEMBER", "V100", shipping.CNHKG, "REF-1"); err != shipping.ErrConsolidated {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrConsolidated)
+	}
+
+	if err := s.ConfirmLeg(ctx, "secret", "MASTER", "V100", shipping.CNHKG, "REF-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []shipping.TrackingID{"MASTER", "MEMBER"} {
+		c, _ := cargos.Find(id)
+		if l := c.Itinerary.Legs[0]; l.Status != shipping.LegConfirmed || l.CarrierReference != "REF-1" {
+			t.Errorf("%s: leg = %s %q; want = %s %q", id, l.Status, l.CarrierReference, shipping.LegConfirmed, "REF-1")
+		}
+	}
+
+	legs, err := s.PendingLegs(ctx, "secret", "V200")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(legs) != 1 {
+		t.Fatalf("len(legs) = %d; want = %d", len(legs), 1)
+	}
+	if legs[0].TrackingID != "MASTER" {
+		t.Errorf("legs[0].TrackingID = %q; want = %q", legs[0].TrackingID, "MASTER")
+	}
+
+	if legs, _ := s.PendingLegs(ctx, "secret", "V100"); len(legs) != 0 {
+		t.Errorf("len(legs) = %d; want = %d", len(legs), 0)
+	}
+}
+
+func TestRejectLeg(t *testing.T) {
+	cargos := newCargoRepository(newRoutedCargo("ABC"))
+
+	s := NewService(cargos, "secret")
+
+	if err := s.RejectLeg(context.Background(), "secret", "ABC", "V200", shipping.DEHAM); err != nil {
+		t.Fatal(err)
+	}
+
+	c, _ := cargos.Find("ABC")
+
+	if c.Itinerary.Legs[1].Status != shipping.LegRejected {
+		t.Errorf("Status = %q; want = %q", c.Itinerary.Legs[1].Status, shipping.LegRejected)
+	}
+	if c.Delivery.RoutingStatus != shipping.Misrouted {
+		t.Errorf("RoutingStatus = %v; want = %v", c.Delivery.RoutingStatus, shipping.Misrouted)
+	}
+}
+
+func TestServiceWithoutToken(t *testing.T) {
+	s := NewService(newCargoRepository(), "")
+
+	if _, err := s.PendingLegs(context.Background(), "", "V100"); err != ErrInvalidToken {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidToken)
+	}
+}
+
+func TestBookingEventHandler(t *testing.T) {
+	c := newRoutedCargo("ABC")
+
+	cargos := newCargoRepository(c)
+
+	h := NewBookingEventHandler(cargos, NewMockAdapter())
+	h.CargoWasRouted(context.Background(), c)
+
+	c, _ = cargos.Find("ABC")
+
+	for _, l := range c.Itinerary.Legs {
+		if !l.IsConfirmed() || l.CarrierReference == "" {
+			t.Errorf("leg %s from %s: status = %s, reference = %q; want confirmed", l.VoyageNumber, l.LoadLocation, l.Status, l.CarrierReference)
+		}
+	}
+
+	if want := (shipping.HandlingActivity{Type: shipping.Receive, Location: shipping.CNHKG}); c.Delivery.NextExpectedActivity != want {
+		t.Errorf("NextExpectedActivity = %+v; want = %+v", c.Delivery.NextExpectedActivity, want)
+	}
+}
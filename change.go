@@ -0,0 +1,73 @@
+package shipping
+
+import (
+	"time"
+)
+
+// CargoChangeType describes how the state of a cargo changed.
+type CargoChangeType string
+
+// Cargo change types.
+const (
+	CargoBookedChange         CargoChangeType = "booked"
+	CargoRoutedChange         CargoChangeType = "routed"
+	CargoHandledChange        CargoChangeType = "handled"
+	CargoMisdirectedChange    CargoChangeType = "misdirected"
+	CargoArrivedChange        CargoChangeType = "arrived"
+	CargoDeadlineAtRiskChange CargoChangeType = "deadline_at_risk"
+	CargoRolledChange         CargoChangeType = "rolled"
+	CargoTransferredChange    CargoChangeType = "transferred"
+)
+
+// CargoChange is an entry in the log of changes to the state of cargos,
+// recorded as domain events are published. Changes are numbered in the order
+// they were recorded, so that readers can pick up where they left off.
+type CargoChange struct {
+	Sequence   uint64
+	TrackingID TrackingID
+	Type       CargoChangeType
+	Time       time.Time
+
+	// Location and VoyageNumber are where the cargo was handled, or where it
+	// was last known to be for changes found by inspection.
+	Location     UNLocode
+	VoyageNumber VoyageNumber
+
+	// HandlingEvent is the type of handling for handled cargos.
+	HandlingEvent HandlingEventType
+
+	// Slip is how late a cargo at risk of missing its deadline is projected
+	// to arrive.
+	Slip time.Duration
+
+	// CorrelationID is the correlation ID of the request that caused the
+	// change, if any.
+	CorrelationID string
+}
+
+// CargoChangeRepository provides access to an append-only log of cargo
+// changes.
+type CargoChangeRepository interface {
+	// Append numbers the change with the next sequence number and adds it
+	// to the log.
+	Append(c *CargoChange) error
+
+	// FindSince returns at most limit changes recorded after the change
+	// with the given sequence number, in order.
+	FindSince(seq uint64, limit int) []*CargoChange
+
+	// FindByCargo returns the changes to a cargo, in order.
+	FindByCargo(id TrackingID) []*CargoChange
+}
+
+// ProjectionCheckpointRepository provides access to how far read-model
+// projections have got in the log of cargo changes.
+type ProjectionCheckpointRepository interface {
+	// Store records the sequence number of the last change applied by the
+	// named projection.
+	Store(projection string, seq uint64) error
+
+	// Find returns the sequence number of the last change applied by the
+	// named projection, or zero if it has applied none.
+	Find(projection string) (uint64, error)
+}
@@ -0,0 +1,95 @@
+package changefeed
+
+import (
+	"context"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/booking"
+	"github.com/marcusolsson/goddd/handling"
+	"github.com/marcusolsson/goddd/inspection"
+)
+
+// Failing to record a change does not fail the event that caused it; such
+// failures are left to be reported by the logging Service.
+
+type bookingEventHandler struct {
+	s Service
+}
+
+func (h *bookingEventHandler) CargoWasBooked(ctx context.Context, c *shipping.Cargo) {
+	h.s.Record(ctx, fromCargo(c, shipping.CargoBookedChange))
+}
+
+func (h *bookingEventHandler) CargoWasRouted(ctx context.Context, c *shipping.Cargo) {
+	h.s.Record(ctx, fromCargo(c, shipping.CargoRoutedChange))
+}
+
+func (h *bookingEventHandler) CargoWasRolled(ctx context.Context, c *shipping.Cargo, from shipping.VoyageNumber) {
+	h.s.Record(ctx, fromCargo(c, shipping.CargoRolledChange))
+}
+
+func (h *bookingEventHandler) CargoWasTransferred(ctx context.Context, c *shipping.Cargo, from shipping.CustomerID) {
+	h.s.Record(ctx, fromCargo(c, shipping.CargoTransferredChange))
+}
+
+// NewBookingEventHandler returns a booking event handler recording booked,
+// routed, rolled and transferred cargos in the change feed.
+func NewBookingEventHandler(s Service) booking.EventHandler {
+	return &bookingEventHandler{s: s}
+}
+
+type handlingEventHandler struct {
+	s Service
+}
+
+func (h *handlingEventHandler) CargoWasHandled(ctx context.Context, e shipping.HandlingEvent) {
+	h.s.Record(ctx, shipping.CargoChange{
+		TrackingID:    e.TrackingID,
+		Type:          shipping.CargoHandledChange,
+		Time:          e.Completed,
+		Location:      e.Activity.Location,
+		VoyageNumber:  e.Activity.VoyageNumber,
+		HandlingEvent: e.Activity.Type,
+		CorrelationID: e.CorrelationID,
+	})
+}
+
+// NewHandlingEventHandler returns a handling event handler recording handled
+// cargos in the change feed.
+func NewHandlingEventHandler(s Service) handling.EventHandler {
+	return &handlingEventHandler{s: s}
+}
+
+type inspectionEventHandler struct {
+	s Service
+}
+
+func (h *inspectionEventHandler) CargoWasMisdirected(ctx context.Context, c *shipping.Cargo) {
+	h.s.Record(ctx, fromCargo(c, shipping.CargoMisdirectedChange))
+}
+
+func (h *inspectionEventHandler) CargoHasArrived(ctx context.Context, c *shipping.Cargo) {
+	h.s.Record(ctx, fromCargo(c, shipping.CargoArrivedChange))
+}
+
+func (h *inspectionEventHandler) CargoDeadlineAtRisk(ctx context.Context, c *shipping.Cargo, slip time.Duration) {
+	change := fromCargo(c, shipping.CargoDeadlineAtRiskChange)
+	change.Slip = slip
+	h.s.Record(ctx, change)
+}
+
+// NewInspectionEventHandler returns an inspection event handler recording
+// misdirected, arrived and delayed cargos in the change feed.
+func NewInspectionEventHandler(s Service) inspection.EventHandler {
+	return &inspectionEventHandler{s: s}
+}
+
+func fromCargo(c *shipping.Cargo, t shipping.CargoChangeType) shipping.CargoChange {
+	return shipping.CargoChange{
+		TrackingID:   c.TrackingID,
+		Type:         t,
+		Location:     c.Delivery.LastKnownLocation,
+		VoyageNumber: c.Delivery.CurrentVoyage,
+	}
+}
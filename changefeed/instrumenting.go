@@ -0,0 +1,43 @@
+package changefeed
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) Record(ctx context.Context, c shipping.CargoChange) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "record").Add(1)
+		s.requestLatency.With("method", "record").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Record(ctx, c)
+}
+
+func (s *instrumentingService) Changes(ctx context.Context, since string, limit int, wait time.Duration) (Page, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "changes").Add(1)
+		s.requestLatency.With("method", "changes").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Changes(ctx, since, limit, wait)
+}
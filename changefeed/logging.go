@@ -0,0 +1,51 @@
+package changefeed
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) Record(ctx context.Context, c shipping.CargoChange) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "record",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", c.TrackingID,
+			"type", c.Type,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Record(ctx, c)
+}
+
+func (s *loggingService) Changes(ctx context.Context, since string, limit int, wait time.Duration) (p Page, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "changes",
+			"request_id", correlation.FromContext(ctx),
+			"since", since,
+			"limit", limit,
+			"wait", wait,
+			"changes", len(p.Changes),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Changes(ctx, since, limit, wait)
+}
@@ -0,0 +1,194 @@
+// Package changefeed provides the use-case of following changes to the
+// state of cargos, as recorded from domain events. Used by integrators
+// keeping their own systems in sync, without polling every cargo.
+package changefeed
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// Service is the interface that provides change feed methods.
+type Service interface {
+	// Record appends a change to the log, waking up readers waiting for
+	// changes.
+	Record(ctx context.Context, c shipping.CargoChange) error
+
+	// Changes returns the changes recorded after the cursor, which is empty
+	// to read from the start of the log. If there are none, it waits up to
+	// wait for changes to be recorded before returning an empty page.
+	Changes(ctx context.Context, since string, limit int, wait time.Duration) (Page, error)
+}
+
+const (
+	// defaultLimit is the number of changes in a page unless a limit is
+	// given.
+	defaultLimit = 100
+
+	// maxLimit caps the number of changes in a page.
+	maxLimit = 1000
+
+	// MaxWait caps how long a reader may wait for changes.
+	MaxWait = time.Minute
+
+	// pollInterval is how often waiting readers check for changes recorded
+	// by other instances of the service.
+	pollInterval = time.Second
+)
+
+type service struct {
+	changes shipping.CargoChangeRepository
+
+	mtx sync.Mutex
+
+	// recorded is closed, and replaced, whenever a change is recorded.
+	recorded chan struct{}
+}
+
+func (s *service) Record(ctx context.Context, c shipping.CargoChange) error {
+	if c.TrackingID == "" || c.Type == "" {
+		return ErrInvalidArgument
+	}
+
+	if c.Time.IsZero() {
+		c.Time = time.Now()
+	}
+	if c.CorrelationID == "" {
+		c.CorrelationID = correlation.FromContext(ctx)
+	}
+
+	if err := s.changes.Append(&c); err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	close(s.recorded)
+	s.recorded = make(chan struct{})
+	s.mtx.Unlock()
+
+	return nil
+}
+
+func (s *service) Changes(ctx context.Context, since string, limit int, wait time.Duration) (Page, error) {
+	seq, err := parseCursor(since)
+	if err != nil {
+		return Page{}, err
+	}
+
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	if wait > MaxWait {
+		wait = MaxWait
+	}
+
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+
+	for {
+		// Get hold of the signal before reading, so that changes recorded
+		// in between are not missed.
+		s.mtx.Lock()
+		recorded := s.recorded
+		s.mtx.Unlock()
+
+		if changes := s.changes.FindSince(seq, limit); len(changes) > 0 || wait <= 0 {
+			return assemblePage(since, changes), nil
+		}
+
+		select {
+		case <-recorded:
+		case <-poll.C:
+		case <-timeout.C:
+			return assemblePage(since, nil), nil
+		case <-ctx.Done():
+			return assemblePage(since, nil), nil
+		}
+	}
+}
+
+// NewService creates a change feed service with necessary dependencies.
+func NewService(changes shipping.CargoChangeRepository) Service {
+	return &service{
+		changes:  changes,
+		recorded: make(chan struct{}),
+	}
+}
+
+// parseCursor returns the sequence number of the last change read.
+func parseCursor(cursor string) (uint64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	seq, err := strconv.ParseUint(cursor, 10, 64)
+	if err != nil {
+		return 0, shipping.ErrInvalidCursor
+	}
+	return seq, nil
+}
+
+// Page is a read model for a page of changes.
+type Page struct {
+	Changes []Change `json:"changes"`
+
+	// Cursor is passed to read the changes recorded after this page. It is
+	// the cursor read from if the page is empty.
+	Cursor string `json:"cursor"`
+}
+
+// Change is a read model for change feed views.
+type Change struct {
+	TrackingID    string    `json:"tracking_id"`
+	Type          string    `json:"type"`
+	Time          time.Time `json:"time"`
+	Location      string    `json:"location,omitempty"`
+	VoyageNumber  string    `json:"voyage_number,omitempty"`
+	HandlingEvent string    `json:"handling_event,omitempty"`
+	Slip          string    `json:"slip,omitempty"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+}
+
+func assemblePage(since string, changes []*shipping.CargoChange) Page {
+	p := Page{
+		Changes: make([]Change, 0, len(changes)),
+		Cursor:  since,
+	}
+	for _, c := range changes {
+		p.Changes = append(p.Changes, assemble(c))
+		p.Cursor = strconv.FormatUint(c.Sequence, 10)
+	}
+	return p
+}
+
+func assemble(c *shipping.CargoChange) Change {
+	result := Change{
+		TrackingID:    string(c.TrackingID),
+		Type:          string(c.Type),
+		Time:          c.Time,
+		Location:      string(c.Location),
+		VoyageNumber:  string(c.VoyageNumber),
+		CorrelationID: c.CorrelationID,
+	}
+	if c.Type == shipping.CargoHandledChange {
+		result.HandlingEvent = c.HandlingEvent.String()
+	}
+	if c.Slip > 0 {
+		result.Slip = c.Slip.String()
+	}
+	return result
+}
@@ -0,0 +1,174 @@
+package changefeed
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+func newCargoChangeRepository() *mock.CargoChangeRepository {
+	var (
+		mtx     sync.Mutex
+		changes []*shipping.CargoChange
+	)
+
+	var r mock.CargoChangeRepository
+	r.AppendFn = func(c *shipping.CargoChange) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		c.Sequence = uint64(len(changes) + 1)
+		changes = append(changes, c)
+		return nil
+	}
+	r.FindSinceFn = func(seq uint64, limit int) []*shipping.CargoChange {
+		mtx.Lock()
+		defer mtx.Unlock()
+		var result []*shipping.CargoChange
+		for _, c := range changes {
+			if c.Sequence > seq && len(result) < limit {
+				result = append(result, c)
+			}
+		}
+		return result
+	}
+	return &r
+}
+
+func TestChanges(t *testing.T) {
+	s := NewService(newCargoChangeRepository())
+
+	ctx := context.Background()
+
+	for _, c := range []shipping.CargoChange{
+		{TrackingID: "ABC", Type: shipping.CargoRoutedChange},
+		{TrackingID: "ABC", Type: shipping.CargoHandledChange, Location: "SESTO", VoyageNumber: "V100", HandlingEvent: shipping.Load},
+		{TrackingID: "DEF", Type: shipping.CargoDeadlineAtRiskChange, Slip: 48 * time.Hour},
+	} {
+		if err := s.Record(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, err := s.Changes(ctx, "", 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(first.Changes) != 2 {
+		t.Fatalf("len(first.Changes) = %d; want = %d", len(first.Changes), 2)
+	}
+	if first.Changes[1].HandlingEvent != "Load" {
+		t.Errorf("first.Changes[1].HandlingEvent = %q; want = %q", first.Changes[1].HandlingEvent, "Load")
+	}
+	if first.Cursor != "2" {
+		t.Errorf("first.Cursor = %q; want = %q", first.Cursor, "2")
+	}
+
+	second, err := s.Changes(ctx, first.Cursor, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(second.Changes) != 1 {
+		t.Fatalf("len(second.Changes) = %d; want = %d", len(second.Changes), 1)
+	}
+	if second.Changes[0].TrackingID != "DEF" {
+		t.Errorf("second.Changes[0].TrackingID = %q; want = %q", second.Changes[0].TrackingID, "DEF")
+	}
+	if second.Changes[0].Slip != "48h0m0s" {
+		t.Errorf("second.Changes[0].Slip = %q; want = %q", second.Changes[0].Slip, "48h0m0s")
+	}
+
+	// Reading past the end keeps the cursor where it was.
+	empty, err := s.Changes(ctx, second.Cursor, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(empty.Changes) != 0 {
+		t.Errorf("len(empty.Changes) = %d; want = %d", len(empty.Changes), 0)
+	}
+	if empty.Cursor != second.Cursor {
+		t.Errorf("empty.Cursor = %q; want = %q", empty.Cursor, second.Cursor)
+	}
+}
+
+func TestRecordCorrelationID(t *testing.T) {
+	s := NewService(newCargoChangeRepository())
+
+	ctx := correlation.NewContext(context.Background(), "req-1")
+
+	if err := s.Record(ctx, shipping.CargoChange{TrackingID: "ABC", Type: shipping.CargoRoutedChange}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A change that carries the correlation ID of its event keeps it.
+	if err := s.Record(ctx, shipping.CargoChange{TrackingID: "ABC", Type: shipping.CargoHandledChange, CorrelationID: "req-0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := s.Changes(ctx, "", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, want := range []string{"req-1", "req-0"} {
+		if got := page.Changes[i].CorrelationID; got != want {
+			t.Errorf("page.Changes[%d].CorrelationID = %q; want = %q", i, got, want)
+		}
+	}
+}
+
+func TestChangesInvalidCursor(t *testing.T) {
+	s := NewService(newCargoChangeRepository())
+
+	if _, err := s.Changes(context.Background(), "abc", 0, 0); err != shipping.ErrInvalidCursor {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrInvalidCursor)
+	}
+}
+
+func TestChangesWaitsForRecord(t *testing.T) {
+	s := NewService(newCargoChangeRepository())
+
+	ctx := context.Background()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		s.Record(ctx, shipping.CargoChange{TrackingID: "ABC", Type: shipping.CargoArrivedChange})
+	}()
+
+	begin := time.Now()
+
+	page, err := s.Changes(ctx, "", 0, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.Changes) != 1 {
+		t.Fatalf("len(page.Changes) = %d; want = %d", len(page.Changes), 1)
+	}
+	if page.Changes[0].Type != string(shipping.CargoArrivedChange) {
+		t.Errorf("page.Changes[0].Type = %q; want = %q", page.Changes[0].Type, shipping.CargoArrivedChange)
+	}
+	if took := time.Since(begin); took > 5*time.Second {
+		t.Errorf("took %s; want the reader to be woken by the change", took)
+	}
+}
+
+func TestChangesWaitTimeout(t *testing.T) {
+	s := NewService(newCargoChangeRepository())
+
+	page, err := s.Changes(context.Background(), "", 0, 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.Changes) != 0 {
+		t.Errorf("len(page.Changes) = %d; want = %d", len(page.Changes), 0)
+	}
+}
@@ -0,0 +1,64 @@
+// Package chaos provides decorators that inject latency and errors into
+// repositories and HTTP clients, for exercising timeouts, retries and
+// circuit breakers under failure. Repository methods that cannot fail are
+// only delayed. It is not meant for production.
+package chaos
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// ErrInjected is returned by calls chosen to fail.
+var ErrInjected = errors.New("injected fault")
+
+// Faults describes the faults to inject.
+type Faults struct {
+	// Latency is the longest delay added to a call. Each call is delayed by a
+	// random duration up to Latency.
+	Latency time.Duration
+
+	// ErrorRate is the share of calls, between 0 and 1, that fail with
+	// ErrInjected.
+	ErrorRate float64
+}
+
+// delay sleeps for a random duration up to the latency.
+func (f Faults) delay() {
+	if f.Latency > 0 {
+		time.Sleep(time.Duration(rand.Int63n(int64(f.Latency))))
+	}
+}
+
+// inject delays the call and returns ErrInjected if the call is chosen to
+// fail.
+func (f Faults) inject() error {
+	f.delay()
+	if f.ErrorRate > 0 && rand.Float64() < f.ErrorRate {
+		return ErrInjected
+	}
+	return nil
+}
+
+type transport struct {
+	faults Faults
+	next   http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.faults.inject(); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// NewTransport returns an HTTP transport that injects faults into the
+// requests made through next. Failing requests are not sent.
+func NewTransport(f Faults, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{faults: f, next: next}
+}
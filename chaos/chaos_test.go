@@ -0,0 +1,82 @@
+package chaos
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+func TestFaults_ErrorRate(t *testing.T) {
+	for _, tt := range []struct {
+		rate float64
+		want error
+	}{
+		{0, nil},
+		{1, ErrInjected},
+	} {
+		f := Faults{ErrorRate: tt.rate}
+		for i := 0; i < 100; i++ {
+			if err := f.inject(); err != tt.want {
+				t.Fatalf("rate %v: err = %v; want = %v", tt.rate, err, tt.want)
+			}
+		}
+	}
+}
+
+func TestFaults_Latency(t *testing.T) {
+	f := Faults{Latency: 20 * time.Millisecond}
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		f.delay()
+	}
+	if elapsed := time.Since(start); elapsed > 10*f.Latency {
+		t.Errorf("elapsed = %v; want at most %v", elapsed, 10*f.Latency)
+	}
+}
+
+func TestCargoRepository(t *testing.T) {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return shipping.NewCargo(id, shipping.RouteSpecification{}), nil
+	}
+
+	r := NewCargoRepository(Faults{ErrorRate: 1}, &cargos)
+
+	if _, err := r.Find("ABC123"); err != ErrInjected {
+		t.Errorf("err = %v; want = %v", err, ErrInjected)
+	}
+	if cargos.FindInvoked {
+		t.Errorf("failing calls should not reach the repository")
+	}
+
+	r = NewCargoRepository(Faults{}, &cargos)
+
+	if _, err := r.Find("ABC123"); err != nil {
+		t.Fatal(err)
+	}
+	if !cargos.FindInvoked {
+		t.Errorf("calls should reach the repository")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer srv.Close()
+
+	c := &http.Client{Transport: NewTransport(Faults{ErrorRate: 1}, nil)}
+
+	if _, err := c.Get(srv.URL); err == nil {
+		t.Errorf("err = nil; want = %v", ErrInjected)
+	}
+	if requests != 0 {
+		t.Errorf("requests = %d; want = %d", requests, 0)
+	}
+}
@@ -0,0 +1,723 @@
+package chaos
+
+import (
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type cargoRepository struct {
+	faults Faults
+	next   shipping.CargoRepository
+}
+
+func (r *cargoRepository) Store(cargo *shipping.Cargo) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(cargo)
+}
+
+func (r *cargoRepository) Find(id shipping.TrackingID) (*shipping.Cargo, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.Find(id)
+}
+
+func (r *cargoRepository) FindAll() []*shipping.Cargo {
+	r.faults.delay()
+	return r.next.FindAll()
+}
+
+func (r *cargoRepository) ForEach(fn func(*shipping.Cargo) error) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.ForEach(fn)
+}
+
+func (r *cargoRepository) FindMatching(q shipping.CargoQuery) ([]*shipping.Cargo, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.FindMatching(q)
+}
+
+// NewCargoRepository returns a cargo repository that injects faults into the
+// calls to next.
+func NewCargoRepository(f Faults, next shipping.CargoRepository) shipping.CargoRepository {
+	return &cargoRepository{faults: f, next: next}
+}
+
+type locationRepository struct {
+	faults Faults
+	next   shipping.LocationRepository
+}
+
+func (r *locationRepository) Store(l *shipping.Location) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(l)
+}
+
+func (r *locationRepository) Find(locode shipping.UNLocode) (*shipping.Location, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.Find(locode)
+}
+
+func (r *locationRepository) FindAll() []*shipping.Location {
+	r.faults.delay()
+	return r.next.FindAll()
+}
+
+// NewLocationRepository returns a location repository that injects faults
+// into the calls to next.
+func NewLocationRepository(f Faults, next shipping.LocationRepository) shipping.LocationRepository {
+	return &locationRepository{faults: f, next: next}
+}
+
+type voyageRepository struct {
+	faults Faults
+	next   shipping.VoyageRepository
+}
+
+func (r *voyageRepository) Store(v *shipping.Voyage) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(v)
+}
+
+func (r *voyageRepository) Find(number shipping.VoyageNumber) (*shipping.Voyage, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.Find(number)
+}
+
+func (r *voyageRepository) FindAll() []*shipping.Voyage {
+	r.faults.delay()
+	return r.next.FindAll()
+}
+
+func (r *voyageRepository) FindCallingAt(locode shipping.UNLocode) []*shipping.Voyage {
+	r.faults.delay()
+	return r.next.FindCallingAt(locode)
+}
+
+// NewVoyageRepository returns a voyage repository that injects faults into
+// the calls to next.
+func NewVoyageRepository(f Faults, next shipping.VoyageRepository) shipping.VoyageRepository {
+	return &voyageRepository{faults: f, next: next}
+}
+
+type serviceStringRepository struct {
+	faults Faults
+	next   shipping.ServiceStringRepository
+}
+
+func (r *serviceStringRepository) Store(s *shipping.ServiceString) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(s)
+}
+
+func (r *serviceStringRepository) Find(code shipping.ServiceCode) (*shipping.ServiceString, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.Find(code)
+}
+
+func (r *serviceStringRepository) FindAll() []*shipping.ServiceString {
+	r.faults.delay()
+	return r.next.FindAll()
+}
+
+// NewServiceStringRepository returns a service string repository that injects
+// faults into the calls to next.
+func NewServiceStringRepository(f Faults, next shipping.ServiceStringRepository) shipping.ServiceStringRepository {
+	return &serviceStringRepository{faults: f, next: next}
+}
+
+type portStatusRepository struct {
+	faults Faults
+	next   shipping.PortStatusRepository
+}
+
+func (r *portStatusRepository) Store(s shipping.PortStatus) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(s)
+}
+
+func (r *portStatusRepository) FindAll() []shipping.PortStatus {
+	r.faults.delay()
+	return r.next.FindAll()
+}
+
+// NewPortStatusRepository returns a port status repository that injects
+// faults into the calls to next.
+func NewPortStatusRepository(f Faults, next shipping.PortStatusRepository) shipping.PortStatusRepository {
+	return &portStatusRepository{faults: f, next: next}
+}
+
+type terminalRepository struct {
+	faults Faults
+	next   shipping.TerminalRepository
+}
+
+func (r *terminalRepository) Store(t *shipping.Terminal) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(t)
+}
+
+func (r *terminalRepository) Find(id shipping.TerminalID) (*shipping.Terminal, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.Find(id)
+}
+
+func (r *terminalRepository) FindAll() []*shipping.Terminal {
+	r.faults.delay()
+	return r.next.FindAll()
+}
+
+// NewTerminalRepository returns a terminal repository that injects faults
+// into the calls to next.
+func NewTerminalRepository(f Faults, next shipping.TerminalRepository) shipping.TerminalRepository {
+	return &terminalRepository{faults: f, next: next}
+}
+
+type customerRepository struct {
+	faults Faults
+	next   shipping.CustomerRepository
+}
+
+func (r *customerRepository) Store(c *shipping.Customer) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(c)
+}
+
+func (r *customerRepository) Find(id shipping.CustomerID) (*shipping.Customer, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.Find(id)
+}
+
+// NewCustomerRepository returns a customer repository that injects faults
+// into the calls to next.
+func NewCustomerRepository(f Faults, next shipping.CustomerRepository) shipping.CustomerRepository {
+	return &customerRepository{faults: f, next: next}
+}
+
+type slaRepository struct {
+	faults Faults
+	next   shipping.SLARepository
+}
+
+func (r *slaRepository) Store(s *shipping.SLA) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(s)
+}
+
+func (r *slaRepository) Find(l shipping.ServiceLevel) (*shipping.SLA, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.Find(l)
+}
+
+func (r *slaRepository) FindAll() []*shipping.SLA {
+	r.faults.delay()
+	return r.next.FindAll()
+}
+
+// NewSLARepository returns an SLA repository that injects faults into the
+// calls to next.
+func NewSLARepository(f Faults, next shipping.SLARepository) shipping.SLARepository {
+	return &slaRepository{faults: f, next: next}
+}
+
+type taskRepository struct {
+	faults Faults
+	next   shipping.TaskRepository
+}
+
+func (r *taskRepository) Store(t *shipping.Task) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(t)
+}
+
+func (r *taskRepository) Find(id shipping.TaskID) (*shipping.Task, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.Find(id)
+}
+
+func (r *taskRepository) FindByCargo(id shipping.TrackingID) []*shipping.Task {
+	r.faults.delay()
+	return r.next.FindByCargo(id)
+}
+
+func (r *taskRepository) FindAll() []*shipping.Task {
+	r.faults.delay()
+	return r.next.FindAll()
+}
+
+// NewTaskRepository returns a task repository that injects faults into the
+// calls to next.
+func NewTaskRepository(f Faults, next shipping.TaskRepository) shipping.TaskRepository {
+	return &taskRepository{faults: f, next: next}
+}
+
+type auditRepository struct {
+	faults Faults
+	next   shipping.AuditRepository
+}
+
+func (r *auditRepository) Append(e *shipping.AuditEntry) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Append(e)
+}
+
+func (r *auditRepository) Last() (*shipping.AuditEntry, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.Last()
+}
+
+func (r *auditRepository) Find(q shipping.AuditQuery) []*shipping.AuditEntry {
+	r.faults.delay()
+	return r.next.Find(q)
+}
+
+// NewAuditRepository returns an audit repository that injects faults into
+// the calls to next.
+func NewAuditRepository(f Faults, next shipping.AuditRepository) shipping.AuditRepository {
+	return &auditRepository{faults: f, next: next}
+}
+
+type cargoChangeRepository struct {
+	faults Faults
+	next   shipping.CargoChangeRepository
+}
+
+func (r *cargoChangeRepository) Append(c *shipping.CargoChange) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Append(c)
+}
+
+func (r *cargoChangeRepository) FindSince(seq uint64, limit int) []*shipping.CargoChange {
+	r.faults.delay()
+	return r.next.FindSince(seq, limit)
+}
+
+func (r *cargoChangeRepository) FindByCargo(id shipping.TrackingID) []*shipping.CargoChange {
+	r.faults.delay()
+	return r.next.FindByCargo(id)
+}
+
+// NewCargoChangeRepository returns a cargo change repository that injects
+// faults into the calls to next.
+func NewCargoChangeRepository(f Faults, next shipping.CargoChangeRepository) shipping.CargoChangeRepository {
+	return &cargoChangeRepository{faults: f, next: next}
+}
+
+type projectionCheckpointRepository struct {
+	faults Faults
+	next   shipping.ProjectionCheckpointRepository
+}
+
+func (r *projectionCheckpointRepository) Store(projection string, seq uint64) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(projection, seq)
+}
+
+func (r *projectionCheckpointRepository) Find(projection string) (uint64, error) {
+	if err := r.faults.inject(); err != nil {
+		return 0, err
+	}
+	return r.next.Find(projection)
+}
+
+// NewProjectionCheckpointRepository returns a projection checkpoint
+// repository that injects faults into the calls to next.
+func NewProjectionCheckpointRepository(f Faults, next shipping.ProjectionCheckpointRepository) shipping.ProjectionCheckpointRepository {
+	return &projectionCheckpointRepository{faults: f, next: next}
+}
+
+type lanePerformanceRepository struct {
+	faults Faults
+	next   shipping.LanePerformanceRepository
+}
+
+func (r *lanePerformanceRepository) Store(id shipping.TrackingID, lanes map[shipping.Lane]shipping.LanePerformance) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(id, lanes)
+}
+
+func (r *lanePerformanceRepository) FindAll() map[shipping.Lane]shipping.LanePerformance {
+	r.faults.delay()
+	return r.next.FindAll()
+}
+
+func (r *lanePerformanceRepository) Clear() error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Clear()
+}
+
+// NewLanePerformanceRepository returns a lane performance repository that
+// injects faults into the calls to next.
+func NewLanePerformanceRepository(f Faults, next shipping.LanePerformanceRepository) shipping.LanePerformanceRepository {
+	return &lanePerformanceRepository{faults: f, next: next}
+}
+
+type workingCalendarRepository struct {
+	faults Faults
+	next   shipping.WorkingCalendarRepository
+}
+
+func (r *workingCalendarRepository) Store(c *shipping.WorkingCalendar) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(c)
+}
+
+func (r *workingCalendarRepository) Find(locode shipping.UNLocode) (*shipping.WorkingCalendar, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.Find(locode)
+}
+
+func (r *workingCalendarRepository) FindAll() []*shipping.WorkingCalendar {
+	r.faults.delay()
+	return r.next.FindAll()
+}
+
+// NewWorkingCalendarRepository returns a working calendar repository that
+// injects faults into the calls to next.
+func NewWorkingCalendarRepository(f Faults, next shipping.WorkingCalendarRepository) shipping.WorkingCalendarRepository {
+	return &workingCalendarRepository{faults: f, next: next}
+}
+
+type vesselPositionRepository struct {
+	faults Faults
+	next   shipping.VesselPositionRepository
+}
+
+func (r *vesselPositionRepository) Store(p shipping.VesselPosition) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(p)
+}
+
+func (r *vesselPositionRepository) FindByVoyage(v shipping.VoyageNumber, limit int) []shipping.VesselPosition {
+	r.faults.delay()
+	return r.next.FindByVoyage(v, limit)
+}
+
+// NewVesselPositionRepository returns a vessel position repository that
+// injects faults into the calls to next.
+func NewVesselPositionRepository(f Faults, next shipping.VesselPositionRepository) shipping.VesselPositionRepository {
+	return &vesselPositionRepository{faults: f, next: next}
+}
+
+type telemetryRepository struct {
+	faults Faults
+	next   shipping.TelemetryRepository
+}
+
+func (r *telemetryRepository) Store(sr shipping.SensorReading) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(sr)
+}
+
+func (r *telemetryRepository) Find(id shipping.TrackingID, sensor shipping.SensorType, from, to time.Time) []shipping.SensorReading {
+	r.faults.delay()
+	return r.next.Find(id, sensor, from, to)
+}
+
+// NewTelemetryRepository returns a telemetry repository that injects faults
+// into the calls to next.
+func NewTelemetryRepository(f Faults, next shipping.TelemetryRepository) shipping.TelemetryRepository {
+	return &telemetryRepository{faults: f, next: next}
+}
+
+type bookingDraftRepository struct {
+	faults Faults
+	next   shipping.BookingDraftRepository
+}
+
+func (r *bookingDraftRepository) Store(d *shipping.BookingDraft) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(d)
+}
+
+func (r *bookingDraftRepository) Find(id shipping.DraftID) (*shipping.BookingDraft, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.Find(id)
+}
+
+func (r *bookingDraftRepository) FindByCustomer(id shipping.CustomerID) []*shipping.BookingDraft {
+	r.faults.delay()
+	return r.next.FindByCustomer(id)
+}
+
+func (r *bookingDraftRepository) Remove(id shipping.DraftID) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Remove(id)
+}
+
+// NewBookingDraftRepository returns a booking draft repository that injects
+// faults into the calls to next.
+func NewBookingDraftRepository(f Faults, next shipping.BookingDraftRepository) shipping.BookingDraftRepository {
+	return &bookingDraftRepository{faults: f, next: next}
+}
+
+type handlingEventRepository struct {
+	faults Faults
+	next   shipping.HandlingEventRepository
+}
+
+func (r *handlingEventRepository) Store(e shipping.HandlingEvent) {
+	r.faults.delay()
+	r.next.Store(e)
+}
+
+func (r *handlingEventRepository) QueryHandlingHistory(id shipping.TrackingID) shipping.HandlingHistory {
+	r.faults.delay()
+	return r.next.QueryHandlingHistory(id)
+}
+
+func (r *handlingEventRepository) QueryHandlingHistoryPage(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+	if err := r.faults.inject(); err != nil {
+		return shipping.HandlingHistoryPage{}, err
+	}
+	return r.next.QueryHandlingHistoryPage(id, q)
+}
+
+func (r *handlingEventRepository) QueryHandlingHistories(ids []shipping.TrackingID) map[shipping.TrackingID]shipping.HandlingHistory {
+	r.faults.delay()
+	return r.next.QueryHandlingHistories(ids)
+}
+
+func (r *handlingEventRepository) ForEach(fn func(shipping.HandlingEvent) error) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.ForEach(fn)
+}
+
+// NewHandlingEventRepository returns a handling event repository that injects
+// faults into the calls to next.
+func NewHandlingEventRepository(f Faults, next shipping.HandlingEventRepository) shipping.HandlingEventRepository {
+	return &handlingEventRepository{faults: f, next: next}
+}
+
+type userRepository struct {
+	faults Faults
+	next   shipping.UserRepository
+}
+
+func (r *userRepository) Store(u *shipping.User) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(u)
+}
+
+func (r *userRepository) Find(id shipping.UserID) (*shipping.User, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.Find(id)
+}
+
+func (r *userRepository) FindAll() []*shipping.User {
+	r.faults.delay()
+	return r.next.FindAll()
+}
+
+// NewUserRepository returns a user repository that injects faults into the
+// calls to next.
+func NewUserRepository(f Faults, next shipping.UserRepository) shipping.UserRepository {
+	return &userRepository{faults: f, next: next}
+}
+
+type amendmentRepository struct {
+	faults Faults
+	next   shipping.AmendmentRepository
+}
+
+func (r *amendmentRepository) Store(a *shipping.Amendment) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(a)
+}
+
+func (r *amendmentRepository) Find(id shipping.AmendmentID) (*shipping.Amendment, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.Find(id)
+}
+
+func (r *amendmentRepository) FindByCargo(id shipping.TrackingID) []*shipping.Amendment {
+	r.faults.delay()
+	return r.next.FindByCargo(id)
+}
+
+func (r *amendmentRepository) FindPending() []*shipping.Amendment {
+	r.faults.delay()
+	return r.next.FindPending()
+}
+
+// NewAmendmentRepository returns an amendment repository that injects faults
+// into the calls to next.
+func NewAmendmentRepository(f Faults, next shipping.AmendmentRepository) shipping.AmendmentRepository {
+	return &amendmentRepository{faults: f, next: next}
+}
+
+type handlingVarianceRepository struct {
+	faults Faults
+	next   shipping.HandlingVarianceRepository
+}
+
+func (r *handlingVarianceRepository) Store(v shipping.HandlingVariance) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(v)
+}
+
+func (r *handlingVarianceRepository) FindSince(t time.Time) []shipping.HandlingVariance {
+	r.faults.delay()
+	return r.next.FindSince(t)
+}
+
+// NewHandlingVarianceRepository returns a handling variance repository that
+// injects faults into the calls to next.
+func NewHandlingVarianceRepository(f Faults, next shipping.HandlingVarianceRepository) shipping.HandlingVarianceRepository {
+	return &handlingVarianceRepository{faults: f, next: next}
+}
+
+type releaseCodeRepository struct {
+	faults Faults
+	next   shipping.ReleaseCodeRepository
+}
+
+func (r *releaseCodeRepository) Store(c *shipping.ReleaseCode) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Store(c)
+}
+
+func (r *releaseCodeRepository) Find(id shipping.TrackingID) (*shipping.ReleaseCode, error) {
+	if err := r.faults.inject(); err != nil {
+		return nil, err
+	}
+	return r.next.Find(id)
+}
+
+// NewReleaseCodeRepository returns a release code repository that injects
+// faults into the calls to next.
+func NewReleaseCodeRepository(f Faults, next shipping.ReleaseCodeRepository) shipping.ReleaseCodeRepository {
+	return &releaseCodeRepository{faults: f, next: next}
+}
+
+type replicationLogRepository struct {
+	faults Faults
+	next   shipping.ReplicationLogRepository
+}
+
+func (r *replicationLogRepository) Append(e *shipping.ReplicationEntry) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Append(e)
+}
+
+func (r *replicationLogRepository) FindSince(seq uint64, limit int) []*shipping.ReplicationEntry {
+	r.faults.delay()
+	return r.next.FindSince(seq, limit)
+}
+
+func (r *replicationLogRepository) Last() (uint64, error) {
+	if err := r.faults.inject(); err != nil {
+		return 0, err
+	}
+	return r.next.Last()
+}
+
+// NewReplicationLogRepository returns a replication log repository that
+// injects faults into the calls to next.
+func NewReplicationLogRepository(f Faults, next shipping.ReplicationLogRepository) shipping.ReplicationLogRepository {
+	return &replicationLogRepository{faults: f, next: next}
+}
+
+type reportNonceRepository struct {
+	faults Faults
+	next   shipping.ReportNonceRepository
+}
+
+func (r *reportNonceRepository) Add(nonce string, now, expires time.Time) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Add(nonce, now, expires)
+}
+
+func (r *reportNonceRepository) Remove(nonce string) error {
+	if err := r.faults.inject(); err != nil {
+		return err
+	}
+	return r.next.Remove(nonce)
+}
+
+// NewReportNonceRepository returns a report nonce repository that injects
+// faults into the calls to next.
+func NewReportNonceRepository(f Faults, next shipping.ReportNonceRepository) shipping.ReportNonceRepository {
+	return &reportNonceRepository{faults: f, next: next}
+}
@@ -0,0 +1,358 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/booking"
+)
+
+// bookingErrors are the errors the booking API reports.
+var bookingErrors = []error{
+	booking.ErrInvalidArgument,
+	booking.ErrDuplicateBooking,
+	shipping.ErrUnknownCargo,
+	shipping.ErrUnknownLocation,
+	shipping.ErrUnknownVoyage,
+	shipping.ErrUnknownEquipmentType,
+	shipping.ErrUnknownDeadlineType,
+	shipping.ErrConsolidated,
+	shipping.ErrNotDelivered,
+	shipping.ErrAlreadyRejected,
+	shipping.ErrPortNotCalled,
+	shipping.ErrCapacityExceeded,
+	shipping.ErrScreeningBlocked,
+	shipping.ErrUnknownLeg,
+	shipping.ErrLegNotContiguous,
+	shipping.ErrLegHandled,
+	shipping.ErrArrivalDeadlineMissed,
+	shipping.ErrConnectionTooShort,
+	shipping.ErrLegOffSchedule,
+	shipping.ErrNothingToRoll,
+	shipping.ErrAlreadyOnVoyage,
+	shipping.ErrNoLaterSailing,
+	shipping.ErrUnknownCustomer,
+	shipping.ErrNotCargoOwner,
+	shipping.ErrAlreadyClaimed,
+}
+
+// BookingClient calls the booking API.
+type BookingClient struct {
+	c *Client
+}
+
+// BookNewCargo registers a new cargo in the tracking system, not yet routed.
+// If the booking is refused as a duplicate, the tracking id of the cargo it
+// duplicates is returned along with booking.ErrDuplicateBooking.
+func (b *BookingClient) BookNewCargo(ctx context.Context, customer shipping.CustomerID, origin, destination shipping.UNLocode, deadline time.Time, equipment shipping.EquipmentType) (shipping.TrackingID, error) {
+	var response struct {
+		ID shipping.TrackingID `json:"tracking_id"`
+	}
+
+	err := b.c.do(ctx, request{
+		method: "POST",
+		path:   "/booking/v1/cargos",
+		body: struct {
+			Customer        shipping.CustomerID
+			Origin          shipping.UNLocode
+			Destination     shipping.UNLocode
+			ArrivalDeadline time.Time
+			Equipment       string
+		}{customer, origin, destination, deadline, equipment.String()},
+		known: bookingErrors,
+		errv:  &response,
+	}, &response)
+
+	return response.ID, err
+}
+
+// LoadCargo returns a read model of a cargo.
+func (b *BookingClient) LoadCargo(ctx context.Context, id shipping.TrackingID) (booking.Cargo, error) {
+	var response struct {
+		Cargo booking.Cargo `json:"cargo"`
+	}
+
+	err := b.c.do(ctx, request{
+		method: "GET",
+		path:   "/booking/v1/cargos/" + url.PathEscape(string(id)),
+		known:  bookingErrors,
+	}, &response)
+
+	return response.Cargo, err
+}
+
+// Timeline returns what has happened to a cargo, oldest first.
+func (b *BookingClient) Timeline(ctx context.Context, id shipping.TrackingID) ([]booking.TimelineEntry, error) {
+	var response struct {
+		Timeline []booking.TimelineEntry `json:"timeline"`
+	}
+
+	err := b.c.do(ctx, request{
+		method: "GET",
+		path:   "/booking/v1/cargos/" + url.PathEscape(string(id)) + "/timeline",
+		known:  bookingErrors,
+	}, &response)
+
+	return response.Timeline, err
+}
+
+// RequestPossibleRoutesForCargo requests a list of itineraries describing
+// possible routes for this cargo, optionally restricted to the given
+// service strings.
+func (b *BookingClient) RequestPossibleRoutesForCargo(ctx context.Context, id shipping.TrackingID, services ...shipping.ServiceCode) (booking.RouteCandidates, error) {
+	q := url.Values{}
+	if len(services) > 0 {
+		codes := make([]string, len(services))
+		for i, s := range services {
+			codes[i] = string(s)
+		}
+		q.Set("services", strings.Join(codes, ","))
+	}
+
+	var response booking.RouteCandidates
+
+	err := b.c.do(ctx, request{
+		method: "GET",
+		path:   "/booking/v1/cargos/" + url.PathEscape(string(id)) + "/request_routes",
+		query:  q,
+		known:  bookingErrors,
+	}, &response)
+
+	return response, err
+}
+
+// CheckDeadlineFeasibility checks whether a cargo could be routed from
+// origin to destination in time for the deadline, without booking it.
+func (b *BookingClient) CheckDeadlineFeasibility(ctx context.Context, origin, destination shipping.UNLocode, deadline time.Time) (booking.Feasibility, error) {
+	q := url.Values{}
+	q.Set("origin", string(origin))
+	q.Set("destination", string(destination))
+	q.Set("arrival_deadline", deadline.Format(time.RFC3339))
+
+	var response booking.Feasibility
+
+	err := b.c.do(ctx, request{
+		method: "GET",
+		path:   "/booking/v1/feasibility",
+		query:  q,
+		known:  bookingErrors,
+	}, &response)
+
+	return response, err
+}
+
+// AssignCargoToRoute assigns a cargo to the route specified by the
+// itinerary.
+func (b *BookingClient) AssignCargoToRoute(ctx context.Context, id shipping.TrackingID, itinerary shipping.Itinerary) error {
+	return b.c.do(ctx, request{
+		method: "POST",
+		path:   "/booking/v1/cargos/" + url.PathEscape(string(id)) + "/assign_to_route",
+		body: struct {
+			Itinerary shipping.Itinerary `json:"route"`
+		}{itinerary},
+		known: bookingErrors,
+	}, nil)
+}
+
+// ReplaceLeg substitutes the leg at an index of the itinerary of a cargo.
+func (b *BookingClient) ReplaceLeg(ctx context.Context, id shipping.TrackingID, index int, leg shipping.Leg) error {
+	return b.c.do(ctx, request{
+		method: "POST",
+		path:   "/booking/v1/cargos/" + url.PathEscape(string(id)) + "/replace_leg",
+		body: struct {
+			Index int          `json:"index"`
+			Leg   shipping.Leg `json:"leg"`
+		}{index, leg},
+		known: bookingErrors,
+	}, nil)
+}
+
+// RollCargo rolls a cargo from the next voyage it is to be loaded onto to
+// another sailing.
+func (b *BookingClient) RollCargo(ctx context.Context, id shipping.TrackingID, voyage shipping.VoyageNumber) error {
+	return b.c.do(ctx, request{
+		method: "POST",
+		path:   "/booking/v1/cargos/" + url.PathEscape(string(id)) + "/roll",
+		body: struct {
+			Voyage shipping.VoyageNumber `json:"voyage"`
+		}{voyage},
+		known: bookingErrors,
+	}, nil)
+}
+
+// ChangeDestination changes the destination of a cargo.
+func (b *BookingClient) ChangeDestination(ctx context.Context, id shipping.TrackingID, destination shipping.UNLocode) error {
+	return b.c.do(ctx, request{
+		method: "POST",
+		path:   "/booking/v1/cargos/" + url.PathEscape(string(id)) + "/change_destination",
+		body: struct {
+			Destination shipping.UNLocode `json:"destination"`
+		}{destination},
+		known: bookingErrors,
+	}, nil)
+}
+
+// ChangeDeadlineType changes how strictly the arrival deadline of a cargo
+// holds.
+func (b *BookingClient) ChangeDeadlineType(ctx context.Context, id shipping.TrackingID, t shipping.DeadlineType) error {
+	return b.c.do(ctx, request{
+		method: "POST",
+		path:   "/booking/v1/cargos/" + url.PathEscape(string(id)) + "/change_deadline_type",
+		body: struct {
+			DeadlineType string `json:"deadline_type"`
+		}{strings.ToLower(t.String())},
+		known: bookingErrors,
+	}, nil)
+}
+
+// TransferCargo transfers a cargo from the customer owning it to another
+// customer.
+func (b *BookingClient) TransferCargo(ctx context.Context, id shipping.TrackingID, from, to shipping.CustomerID) error {
+	return b.c.do(ctx, request{
+		method: "POST",
+		path:   "/booking/v1/cargos/" + url.PathEscape(string(id)) + "/transfer",
+		body: struct {
+			From shipping.CustomerID `json:"from"`
+			To   shipping.CustomerID `json:"to"`
+		}{from, to},
+		known: bookingErrors,
+	}, nil)
+}
+
+// DeclareCargo declares the commodities of a cargo and the documents that
+// come with it. A declaration violating the import restrictions of the
+// destination is refused with an *Error listing the violations.
+func (b *BookingClient) DeclareCargo(ctx context.Context, id shipping.TrackingID, d shipping.CargoDeclaration) error {
+	return b.c.do(ctx, request{
+		method: "PUT",
+		path:   "/booking/v1/cargos/" + url.PathEscape(string(id)) + "/declaration",
+		body: struct {
+			Commodities []shipping.CommodityCode `json:"commodities"`
+			Documents   []shipping.DocumentType  `json:"documents"`
+		}{d.Commodities, d.Documents},
+		known: bookingErrors,
+	}, nil)
+}
+
+// RejectCargo books the return of a delivered cargo to its origin, and
+// returns the tracking id of the return.
+func (b *BookingClient) RejectCargo(ctx context.Context, id shipping.TrackingID, deadline time.Time) (shipping.TrackingID, error) {
+	var response struct {
+		ID shipping.TrackingID `json:"return_id"`
+	}
+
+	err := b.c.do(ctx, request{
+		method: "POST",
+		path:   "/booking/v1/cargos/" + url.PathEscape(string(id)) + "/reject",
+		body: struct {
+			ArrivalDeadline time.Time `json:"arrival_deadline"`
+		}{deadline},
+		known: bookingErrors,
+	}, &response)
+
+	return response.ID, err
+}
+
+// IssueReleaseCode issues the release code of a cargo to its consignee, and
+// returns its PIN.
+func (b *BookingClient) IssueReleaseCode(ctx context.Context, id shipping.TrackingID) (string, error) {
+	var response struct {
+		PIN string `json:"pin"`
+	}
+
+	err := b.c.do(ctx, request{
+		method: "POST",
+		path:   "/booking/v1/cargos/" + url.PathEscape(string(id)) + "/release_code",
+		known:  bookingErrors,
+	}, &response)
+
+	return response.PIN, err
+}
+
+// OmitPortCall removes a port call from a voyage and returns the cargos that
+// need to be rerouted.
+func (b *BookingClient) OmitPortCall(ctx context.Context, voyage shipping.VoyageNumber, locode shipping.UNLocode) ([]shipping.TrackingID, error) {
+	var response struct {
+		Rerouting []shipping.TrackingID `json:"rerouting"`
+	}
+
+	err := b.c.do(ctx, request{
+		method: "POST",
+		path:   "/booking/v1/voyages/" + url.PathEscape(string(voyage)) + "/omit_port_call",
+		body: struct {
+			Location shipping.UNLocode `json:"location"`
+		}{locode},
+		known: bookingErrors,
+	}, &response)
+
+	return response.Rerouting, err
+}
+
+// RerouteAllVia reroutes the cargos calling at a disrupted port between two
+// times.
+func (b *BookingClient) RerouteAllVia(ctx context.Context, locode shipping.UNLocode, from, to time.Time) (booking.RerouteResult, error) {
+	var response booking.RerouteResult
+
+	err := b.c.do(ctx, request{
+		method: "POST",
+		path:   "/booking/v1/locations/" + url.PathEscape(string(locode)) + "/reroute",
+		body: struct {
+			From time.Time `json:"from"`
+			To   time.Time `json:"to"`
+		}{from, to},
+		known: bookingErrors,
+	}, &response)
+
+	return response, err
+}
+
+// Cargos returns a list of all cargos that have been booked.
+func (b *BookingClient) Cargos(ctx context.Context) ([]booking.Cargo, error) {
+	var response struct {
+		Cargos []booking.Cargo `json:"cargos"`
+	}
+
+	err := b.c.do(ctx, request{
+		method: "GET",
+		path:   "/booking/v1/cargos",
+		known:  bookingErrors,
+	}, &response)
+
+	return response.Cargos, err
+}
+
+// SearchCargos returns the booked cargos matching a filter expression, such
+// as "status=IN_PORT AND destination=SESTO".
+func (b *BookingClient) SearchCargos(ctx context.Context, filter string) ([]booking.Cargo, error) {
+	var response struct {
+		Cargos []booking.Cargo `json:"cargos"`
+	}
+
+	err := b.c.do(ctx, request{
+		method: "GET",
+		path:   "/booking/v1/cargos",
+		query:  url.Values{"q": {filter}},
+		known:  bookingErrors,
+	}, &response)
+
+	return response.Cargos, err
+}
+
+// Locations returns a list of registered locations.
+func (b *BookingClient) Locations(ctx context.Context) ([]booking.Location, error) {
+	// The locations are listed under "cargos" in the response.
+	var response struct {
+		Locations []booking.Location `json:"cargos"`
+	}
+
+	err := b.c.do(ctx, request{
+		method: "GET",
+		path:   "/booking/v1/locations",
+		known:  bookingErrors,
+	}, &response)
+
+	return response.Locations, err
+}
@@ -0,0 +1,267 @@
+// Package client provides a client for the HTTP APIs of the shipping
+// service, with typed methods mirroring the booking, handling and tracking
+// services.
+//
+// Errors reported by the API are returned as the errors of the domain and
+// service packages, so that callers can compare them just as they would
+// when calling the services directly. Other errors are returned as an
+// *Error holding the status code.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+// RetryPolicy describes how idempotent requests failing with transient
+// errors are retried.
+type RetryPolicy struct {
+	// Attempts is the total number of attempts, including the first one.
+	Attempts int
+
+	// Backoff is the delay before the first retry. It doubles for every
+	// subsequent retry.
+	Backoff time.Duration
+}
+
+// DefaultRetryPolicy is used by clients unless configured otherwise.
+var DefaultRetryPolicy = RetryPolicy{Attempts: 3, Backoff: 100 * time.Millisecond}
+
+// Option configures a client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client requests are sent with.
+func WithHTTPClient(c *http.Client) Option {
+	return func(cl *Client) {
+		cl.http = c
+	}
+}
+
+// WithRetryPolicy sets the retry policy for idempotent requests.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(cl *Client) {
+		cl.retry = p
+	}
+}
+
+// Client is a client for the shipping service.
+type Client struct {
+	base  *url.URL
+	http  *http.Client
+	retry RetryPolicy
+
+	// Booking, Handling and Tracking call the APIs of the respective
+	// services.
+	Booking  *BookingClient
+	Handling *HandlingClient
+	Tracking *TrackingClient
+}
+
+// New returns a client for the shipping service at the given base URL,
+// e.g. http://localhost:8080.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("client: base URL %q must be absolute", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	c := &Client{
+		base:  u,
+		http:  http.DefaultClient,
+		retry: DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.Booking = &BookingClient{c}
+	c.Handling = &HandlingClient{c}
+	c.Tracking = &TrackingClient{c}
+
+	return c, nil
+}
+
+// Error is returned for responses with an error status that does not
+// correspond to a known error.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("client: %s", http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("client: %s: %s", http.StatusText(e.StatusCode), e.Message)
+}
+
+// request describes a call to the API.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   interface{}
+
+	// sign, if set, signs the body by adding headers, every time it is
+	// sent.
+	sign func(body []byte, header http.Header) error
+
+	// known holds the errors the call may return, which are matched by
+	// their message.
+	known []error
+
+	// errv, if set, is decoded from error responses carrying more than the
+	// error message.
+	errv interface{}
+}
+
+// do sends the request and decodes the response into v, unless v is nil.
+// Idempotent requests are retried according to the retry policy.
+func (c *Client) do(ctx context.Context, r request, v interface{}) error {
+	var body []byte
+	if r.body != nil {
+		var err error
+		if body, err = json.Marshal(r.body); err != nil {
+			return err
+		}
+	}
+
+	attempts := 1
+	if idempotent(r.method) && c.retry.Attempts > 1 {
+		attempts = c.retry.Attempts
+	}
+
+	backoff := c.retry.Backoff
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+
+		var resp *http.Response
+		resp, err = c.send(ctx, r, body)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+
+		err = decode(resp, r, v)
+		if !isTransient(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (c *Client) send(ctx context.Context, r request, body []byte) (*http.Response, error) {
+	u := *c.base
+	u.Path += r.path
+	u.RawQuery = r.query.Encode()
+
+	var rd io.Reader
+	if body != nil {
+		rd = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(r.method, u.String(), rd)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, vv := range r.header {
+		req.Header[k] = vv
+	}
+	if r.sign != nil {
+		if err := r.sign(body, req.Header); err != nil {
+			return nil, err
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	// Let the request be traced through the service along with the one it
+	// was made for.
+	if id := correlation.FromContext(ctx); id != "" {
+		req.Header.Set(correlation.Header, id)
+	}
+
+	return c.http.Do(req.WithContext(ctx))
+}
+
+// decode reads the response, returning the error it reports, if any.
+func decode(resp *http.Response, r request, v interface{}) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		json.Unmarshal(b, &e)
+		if r.errv != nil {
+			json.Unmarshal(b, r.errv)
+		}
+
+		for _, err := range r.known {
+			if e.Error == err.Error() {
+				return err
+			}
+		}
+		return &Error{StatusCode: resp.StatusCode, Message: e.Error}
+	}
+
+	if v == nil {
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// isTransient returns whether a request failing with err may succeed if
+// sent again.
+func isTransient(err error) bool {
+	e, ok := err.(*Error)
+	if !ok {
+		return false
+	}
+	switch e.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	return false
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/booking"
+	"github.com/marcusolsson/goddd/correlation"
+	"github.com/marcusolsson/goddd/handling"
+	"github.com/marcusolsson/goddd/server"
+	"github.com/marcusolsson/goddd/servicetest"
+	"github.com/marcusolsson/goddd/tracking"
+)
+
+func newTestClient(t *testing.T, bs booking.Service, hs handling.Service, ts tracking.Service) *Client {
+	srv := httptest.NewServer(server.New(server.Services{Booking: bs, Tracking: ts, Handling: hs}, log.NewLogfmtLogger(ioutil.Discard)))
+	t.Cleanup(srv.Close)
+
+	c, err := New(srv.URL, WithRetryPolicy(RetryPolicy{Attempts: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestBookNewCargo(t *testing.T) {
+	deadline := time.Date(2016, time.March, 21, 0, 0, 0, 0, time.UTC)
+
+	var bs servicetest.BookingService
+	bs.BookNewCargoFn = func(ctx context.Context, customer shipping.CustomerID, origin, destination shipping.UNLocode, d time.Time, equipment shipping.EquipmentType) (shipping.TrackingID, error) {
+		if origin != shipping.SESTO || destination != shipping.CNHKG || !d.Equal(deadline) || equipment != shipping.Reefer {
+			t.Errorf("BookNewCargo(%s, %s, %s, %v, %s); want the booked cargo", customer, origin, destination, d, equipment)
+		}
+		if customer == "ACME" {
+			return "ABC123", booking.ErrDuplicateBooking
+		}
+		return "DEF456", nil
+	}
+
+	c := newTestClient(t, &bs, nil, nil)
+
+	id, err := c.Booking.BookNewCargo(context.Background(), "INITECH", shipping.SESTO, shipping.CNHKG, deadline, shipping.Reefer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "DEF456" {
+		t.Errorf("id = %s; want = %s", id, "DEF456")
+	}
+
+	id, err = c.Booking.BookNewCargo(context.Background(), "ACME", shipping.SESTO, shipping.CNHKG, deadline, shipping.Reefer)
+	if err != booking.ErrDuplicateBooking {
+		t.Errorf("err = %v; want = %v", err, booking.ErrDuplicateBooking)
+	}
+	if id != "ABC123" {
+		t.Errorf("id = %s; want the duplicated cargo %s", id, "ABC123")
+	}
+}
+
+func TestLoadCargo(t *testing.T) {
+	var bs servicetest.BookingService
+	bs.LoadCargoFn = func(ctx context.Context, id shipping.TrackingID) (booking.Cargo, error) {
+		if correlation.FromContext(ctx) != "req-1" {
+			t.Errorf("request id = %q; want = %q", correlation.FromContext(ctx), "req-1")
+		}
+		if id != "ABC123" {
+			return booking.Cargo{}, shipping.ErrUnknownCargo
+		}
+		return booking.Cargo{TrackingID: "ABC123", Origin: "SESTO"}, nil
+	}
+
+	c := newTestClient(t, &bs, nil, nil)
+
+	ctx := correlation.NewContext(context.Background(), "req-1")
+
+	cargo, err := c.Booking.LoadCargo(ctx, "ABC123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cargo.TrackingID != "ABC123" || cargo.Origin != "SESTO" {
+		t.Errorf("cargo = %+v; want ABC123 from SESTO", cargo)
+	}
+
+	if _, err := c.Booking.LoadCargo(ctx, "NOPE"); err != shipping.ErrUnknownCargo {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownCargo)
+	}
+}
+
+func TestRegisterHandlingEvent(t *testing.T) {
+	var hs servicetest.HandlingService
+	hs.RegisterHandlingEventFn = func(ctx context.Context, source handling.Credentials, completed time.Time, id shipping.TrackingID, voyage shipping.VoyageNumber, loc shipping.UNLocode, typ shipping.HandlingEventType) error {
+		if source.Terminal != "SESTO-1" || source.Key != "secret" {
+			return shipping.ErrUnauthorizedTerminal
+		}
+		if id != "ABC123" || voyage != "V100" || loc != shipping.SESTO || typ != shipping.Load {
+			t.Errorf("RegisterHandlingEvent(%s, %s, %s, %s); want the load of ABC123", id, voyage, loc, typ)
+		}
+		return nil
+	}
+
+	c := newTestClient(t, nil, &hs, nil)
+
+	completed := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	source := handling.Credentials{Terminal: "SESTO-1", Key: "secret"}
+	if err := c.Handling.RegisterHandlingEvent(context.Background(), source, completed, "ABC123", "V100", shipping.SESTO, shipping.Load); err != nil {
+		t.Fatal(err)
+	}
+
+	source.Key = "wrong"
+	if err := c.Handling.RegisterHandlingEvent(context.Background(), source, completed, "ABC123", "V100", shipping.SESTO, shipping.Load); err != shipping.ErrUnauthorizedTerminal {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnauthorizedTerminal)
+	}
+}
+
+func TestRegisterHandlingEventClaim(t *testing.T) {
+	var hs servicetest.HandlingService
+	hs.RegisterHandlingEventFn = func(ctx context.Context, source handling.Credentials, completed time.Time, id shipping.TrackingID, voyage shipping.VoyageNumber, loc shipping.UNLocode, typ shipping.HandlingEventType) error {
+		if source.ReleaseCode != "048213" {
+			return shipping.ErrInvalidReleaseCode
+		}
+		return nil
+	}
+
+	c := newTestClient(t, nil, &hs, nil)
+
+	completed := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	source := handling.Credentials{Terminal: "SESTO-1", Key: "secret", ReleaseCode: "048213"}
+	if err := c.Handling.RegisterHandlingEvent(context.Background(), source, completed, "ABC123", "", shipping.SESTO, shipping.Claim); err != nil {
+		t.Fatal(err)
+	}
+
+	source.ReleaseCode = "000000"
+	if err := c.Handling.RegisterHandlingEvent(context.Background(), source, completed, "ABC123", "", shipping.SESTO, shipping.Claim); err != shipping.ErrInvalidReleaseCode {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrInvalidReleaseCode)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	from := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	var ts servicetest.TrackingService
+	ts.EventsFn = func(ctx context.Context, id string, q shipping.HandlingHistoryQuery) (tracking.EventPage, error) {
+		if !q.From.Equal(from) || q.Limit != 10 || q.Cursor != "abc" || len(q.Types) != 2 || q.Types[1] != shipping.Unload {
+			t.Errorf("q = %+v; want the query sent", q)
+		}
+		return tracking.EventPage{NextCursor: "def"}, nil
+	}
+
+	c := newTestClient(t, nil, nil, &ts)
+
+	page, err := c.Tracking.Events(context.Background(), "ABC123", shipping.HandlingHistoryQuery{
+		From:   from,
+		Cursor: "abc",
+		Limit:  10,
+		Types:  []shipping.HandlingEventType{shipping.Load, shipping.Unload},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.NextCursor != "def" {
+		t.Errorf("NextCursor = %s; want = %s", page.NextCursor, "def")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"terminals": [{"id": "SESTO-1"}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithRetryPolicy(RetryPolicy{Attempts: 3, Backoff: time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	terminals, err := c.Handling.Terminals(context.Background(), "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(terminals) != 1 || calls != 3 {
+		t.Errorf("len(terminals) = %d after %d calls; want = 1 after 3", len(terminals), calls)
+	}
+
+	// Requests that are not idempotent are never retried.
+	calls = 0
+	err = c.Handling.RegisterHandlingEvent(context.Background(), handling.Credentials{}, time.Now(), "ABC123", "", shipping.SESTO, shipping.Receive)
+	if e, ok := err.(*Error); !ok || e.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("err = %v; want a service unavailable error", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d; want = %d", calls, 1)
+	}
+}
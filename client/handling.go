@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/handling"
+)
+
+// handlingErrors are the errors the handling API reports.
+var handlingErrors = []error{
+	handling.ErrInvalidArgument,
+	handling.ErrInvalidToken,
+	shipping.ErrUnknownCargo,
+	shipping.ErrUnknownLocation,
+	shipping.ErrUnknownVoyage,
+	shipping.ErrUnauthorizedTerminal,
+	shipping.ErrTerminalLocation,
+	handling.ErrBusy,
+	handling.ErrUnsignedReport,
+	handling.ErrInvalidSignature,
+	handling.ErrReplayedReport,
+	shipping.ErrNoReleaseCode,
+	shipping.ErrReleaseCodeRequired,
+	shipping.ErrInvalidReleaseCode,
+	shipping.ErrReleaseCodeLocked,
+}
+
+// HandlingClient calls the handling API.
+type HandlingClient struct {
+	c *Client
+}
+
+// RegisterHandlingEvent registers a handling event reported by a terminal.
+// The report is signed if the credentials hold the signing secret of the
+// terminal. Claims give the release code held by the credentials.
+func (h *HandlingClient) RegisterHandlingEvent(ctx context.Context, source handling.Credentials, completed time.Time, id shipping.TrackingID, voyageNumber shipping.VoyageNumber, unLocode shipping.UNLocode, eventType shipping.HandlingEventType) error {
+	// Terminals authenticate with their id and key as basic auth
+	// credentials.
+	header := http.Header{}
+	if source.Terminal != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte(string(source.Terminal) + ":" + source.Key))
+		header.Set("Authorization", "Basic "+auth)
+	}
+
+	return h.c.do(ctx, request{
+		method: "POST",
+		path:   "/handling/v1/incidents",
+		header: header,
+		body: struct {
+			CompletionTime time.Time `json:"completion_time"`
+			TrackingID     string    `json:"tracking_id"`
+			VoyageNumber   string    `json:"voyage"`
+			Location       string    `json:"location"`
+			EventType      string    `json:"event_type"`
+			ReleaseCode    string    `json:"release_code,omitempty"`
+		}{completed, string(id), string(voyageNumber), string(unLocode), eventType.String(), source.ReleaseCode},
+		sign:  signer(source.Secret),
+		known: handlingErrors,
+	}, nil)
+}
+
+// RegisterTerminal registers a terminal that authenticates with the given
+// key, and signs its reports with the secret unless empty. Registering an
+// existing terminal replaces its name, location, key and secret. The token
+// is the admin token of the handling API.
+func (h *HandlingClient) RegisterTerminal(ctx context.Context, token string, id shipping.TerminalID, name string, loc shipping.UNLocode, key, secret string) error {
+	return h.c.do(ctx, request{
+		method: "PUT",
+		path:   "/handling/v1/terminals/" + url.PathEscape(string(id)),
+		header: http.Header{"Authorization": {"Bearer " + token}},
+		body: struct {
+			Name          string `json:"name"`
+			Location      string `json:"location"`
+			Key           string `json:"key"`
+			SigningSecret string `json:"signing_secret,omitempty"`
+		}{name, string(loc), key, secret},
+		known: handlingErrors,
+	}, nil)
+}
+
+// Terminals returns all registered terminals. The token is the admin token
+// of the handling API.
+func (h *HandlingClient) Terminals(ctx context.Context, token string) ([]handling.Terminal, error) {
+	var response struct {
+		Terminals []handling.Terminal `json:"terminals"`
+	}
+
+	err := h.c.do(ctx, request{
+		method: "GET",
+		path:   "/handling/v1/terminals",
+		header: http.Header{"Authorization": {"Bearer " + token}},
+		known:  handlingErrors,
+	}, &response)
+
+	return response.Terminals, err
+}
+
+// signer returns a function signing reports with the secret of a terminal,
+// or nil if the terminal does not sign its reports.
+func signer(secret string) func(body []byte, header http.Header) error {
+	if secret == "" {
+		return nil
+	}
+	return func(body []byte, header http.Header) error {
+		var nonce [16]byte
+		if _, err := rand.Read(nonce[:]); err != nil {
+			return err
+		}
+		sig := handling.Sign(secret, time.Now(), hex.EncodeToString(nonce[:]), body)
+		header.Set(handling.TimestampHeader, strconv.FormatInt(sig.Timestamp.Unix(), 10))
+		header.Set(handling.NonceHeader, sig.Nonce)
+		header.Set(handling.SignatureHeader, hex.EncodeToString(sig.MAC))
+		return nil
+	}
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/tracking"
+)
+
+// trackingErrors are the errors the tracking API reports.
+var trackingErrors = []error{
+	tracking.ErrInvalidArgument,
+	shipping.ErrUnknownCargo,
+	shipping.ErrInvalidCursor,
+}
+
+// TrackingClient calls the tracking API.
+type TrackingClient struct {
+	c *Client
+}
+
+// Track returns a cargo matching a tracking ID, along with the first page of
+// its handling events. If the cargo is not known but the tracking ID is
+// close to known ones, they are suggested in a tracking.UnknownCargoError.
+func (t *TrackingClient) Track(ctx context.Context, id string) (tracking.Cargo, error) {
+	var response struct {
+		Cargo       tracking.Cargo        `json:"cargo"`
+		Suggestions []shipping.TrackingID `json:"suggestions"`
+	}
+
+	err := t.c.do(ctx, request{
+		method: "GET",
+		path:   "/tracking/v1/cargos/" + url.PathEscape(id),
+		known:  trackingErrors,
+		errv:   &response,
+	}, &response)
+
+	if err == shipping.ErrUnknownCargo && len(response.Suggestions) > 0 {
+		return tracking.Cargo{}, &tracking.UnknownCargoError{Suggestions: response.Suggestions}
+	}
+
+	return response.Cargo, err
+}
+
+// Events returns a page of the handling events of a cargo, optionally
+// restricted to some types of events.
+func (t *TrackingClient) Events(ctx context.Context, id string, q shipping.HandlingHistoryQuery) (tracking.EventPage, error) {
+	v := url.Values{}
+	if !q.From.IsZero() {
+		v.Set("from", q.From.Format(time.RFC3339))
+	}
+	if !q.To.IsZero() {
+		v.Set("to", q.To.Format(time.RFC3339))
+	}
+	if q.Cursor != "" {
+		v.Set("cursor", q.Cursor)
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if len(q.Types) > 0 {
+		types := make([]string, len(q.Types))
+		for i, typ := range q.Types {
+			types[i] = typ.String()
+		}
+		v.Set("type", strings.Join(types, ","))
+	}
+
+	var response tracking.EventPage
+
+	err := t.c.do(ctx, request{
+		method: "GET",
+		path:   "/tracking/v1/cargos/" + url.PathEscape(id) + "/events",
+		query:  v,
+		known:  trackingErrors,
+	}, &response)
+
+	return response, err
+}
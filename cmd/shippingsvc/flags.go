@@ -0,0 +1,173 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/marcusolsson/goddd/briefing"
+	"github.com/marcusolsson/goddd/fixtures"
+	"github.com/marcusolsson/goddd/handling"
+	"github.com/marcusolsson/goddd/mongo"
+	"github.com/marcusolsson/goddd/portstatus"
+	"github.com/marcusolsson/goddd/position"
+	"github.com/marcusolsson/goddd/server"
+)
+
+// config holds the command line flags of the application.
+type config struct {
+	environment       *string
+	httpAddr          *string
+	routingServiceURL *string
+	localRouting      *bool
+	routingTimeout    *time.Duration
+	connectionTime    *time.Duration
+	portConnections   *string
+	scheduleTolerance *time.Duration
+	mongoDBURL        *string
+	databaseName      *string
+	dbPoolLimit       *int
+	dbTimeout         *time.Duration
+	dbOpTimeout       *time.Duration
+	dbRetries         *int
+	dbReadPreference  *string
+	duplicateWindow   *time.Duration
+	idempotencyWindow *time.Duration
+	trackingFuzziness *int
+	duplicateBlock    *bool
+	portalSecret      *string
+	briefingHour      *int
+	briefingZone      *string
+	briefingOperator  *string
+	varianceThreshold *time.Duration
+	varianceWindow    *time.Duration
+	seaLanes          *bool
+	geofence          *float64
+	inmemory          *bool
+	dropDir           *string
+	dropInterval      *time.Duration
+	dropTerminal      *string
+	handlingQueue     *int
+	handlingReplay    *time.Duration
+	requireRelease    *bool
+	projectionPoll    *time.Duration
+	dropKey           *string
+	mailTemplates     *string
+	mailToken         *string
+	piiKeys           *string
+	idKey             *string
+	carrierToken      *string
+	handlingToken     *string
+	carrierMock       *bool
+	deniedParties     *string
+	deniedCountries   *string
+	screeningFlagOnly *bool
+	screeningURL      *string
+	restrictionsFile  *string
+	overbookingFile   *string
+	strictCustoms     *bool
+	arrivalOnCustoms  *bool
+	unconfirmedLoads  *bool
+	customActivities  *string
+	publicInterval    *time.Duration
+	publicBurst       *int
+	replicationToken  *string
+	replicationRole   *string
+	webUI             *bool
+	publicProxy       *bool
+	bootstrapFile     *string
+	datasetName       *string
+	volumeInterval    *time.Duration
+	repositoryDebug   *bool
+	chaosEnabled      *bool
+	chaosLatency      *time.Duration
+	chaosErrors       *float64
+}
+
+// registerFlags registers the flags of the application with a flag set,
+// defaulting to the environment variables that some of them have.
+func registerFlags(fs *flag.FlagSet) *config {
+	var (
+		addr   = envString("PORT", defaultPort)
+		rsurl  = envString("ROUTINGSERVICE_URL", defaultRoutingServiceURL)
+		dburl  = envString("MONGODB_URL", defaultMongoDBURL)
+		dbname = envString("DB_NAME", defaultDBName)
+		secret = envString("PORTAL_SECRET", "")
+		mailtk = envString("INBOUND_MAIL_TOKEN", "")
+		piikey = envString("PII_KEYS", "")
+		carrtk = envString("CARRIER_TOKEN", "")
+		termtk = envString("HANDLING_TOKEN", "")
+		repltk = envString("REPLICATION_TOKEN", "")
+		appenv = envString("ENVIRONMENT", "")
+		idskey = envString("ID_KEY", "")
+	)
+
+	return &config{
+		environment:       fs.String("env", appenv, "environment presetting the flags not given, one of "+strings.Join(presetNames(), ", ")+" (empty for none)"),
+		httpAddr:          fs.String("http.addr", ":"+addr, "HTTP listen address"),
+		routingServiceURL: fs.String("service.routing", rsurl, "routing service URL"),
+		localRouting:      fs.Bool("routing.local", false, "route cargos on the voyages in the repository, in place of the routing service"),
+		routingTimeout:    fs.Duration("service.routing.timeout", defaultRoutingTimeout, "latency budget for routing requests"),
+		connectionTime:    fs.Duration("routing.connection", defaultConnectionTime, "minimum connection time at transshipment ports"),
+		portConnections:   fs.String("routing.connection.ports", "", "minimum connection times for specific ports, e.g. DEHAM=12h,SESTO=4h"),
+		scheduleTolerance: fs.Duration("routing.schedule.tolerance", -1, "how far leg times may be off the schedules of their voyages, with the times left out of legs taken from them (negative to not validate them)"),
+		mongoDBURL:        fs.String("db.url", dburl, "MongoDB URL"),
+		databaseName:      fs.String("db.name", dbname, "MongoDB database name"),
+		dbPoolLimit:       fs.Int("db.pool", 0, "maximum number of MongoDB sockets per server (0 for driver default)"),
+		dbTimeout:         fs.Duration("db.timeout", 10*time.Second, "MongoDB server selection timeout"),
+		dbOpTimeout:       fs.Duration("db.op.timeout", time.Minute, "MongoDB operation timeout"),
+		dbRetries:         fs.Int("db.retries", mongo.DefaultRetryPolicy.Attempts, "attempts for MongoDB writes failing with transient errors"),
+		dbReadPreference:  fs.String("db.read", "primary", "MongoDB read preference for queries, e.g. secondaryPreferred"),
+		duplicateWindow:   fs.Duration("booking.duplicates.window", 10*time.Minute, "window for detecting repeated bookings (0 to disable)"),
+		idempotencyWindow: fs.Duration("command.idempotency", 24*time.Hour, "how long the idempotency keys of commands are remembered"),
+		trackingFuzziness: fs.Int("tracking.fuzziness", 2, "largest number of typos in a tracking ID that known tracking IDs are suggested for (0 to disable)"),
+		duplicateBlock:    fs.Bool("booking.duplicates.block", false, "refuse repeated bookings instead of flagging them"),
+		portalSecret:      fs.String("portal.secret", secret, "secret signing unsubscribe links (random if empty)"),
+		briefingHour:      fs.Int("briefing.hour", briefing.DefaultDeliveryHour, "hour of the day the daily operations briefings are delivered at"),
+		briefingZone:      fs.String("briefing.zone", "UTC", "time zone the days of the daily operations briefings and the operator dashboard start in, e.g. Europe/Stockholm"),
+		briefingOperator:  fs.String("briefing.operator", "", "customer the daily operations briefing on all cargos is delivered to (empty to not deliver it)"),
+		varianceThreshold: fs.Duration("port.variance.threshold", portstatus.DefaultVarianceThreshold, "how much later than planned ports may load and unload cargos on average before operations are alerted"),
+		varianceWindow:    fs.Duration("port.variance.window", portstatus.DefaultVarianceWindow, "how far back the average variance of ports is taken over"),
+		seaLanes:          fs.Bool("geo.sealanes", true, "route sea distances through common canals and straits"),
+		geofence:          fs.Float64("position.geofence", position.DefaultGeofenceRadius, "distance from a port in kilometres within which vessels are taken to have arrived there"),
+		inmemory:          fs.Bool("inmem", false, "use in-memory repositories"),
+		dropDir:           fs.String("drop.dir", "", "directory of partner drop folders, e.g. mounted SFTP folders (empty to disable)"),
+		dropInterval:      fs.Duration("drop.interval", time.Minute, "how often drop folders are polled"),
+		dropTerminal:      fs.String("drop.terminal", "", "terminal reporting the files of the handling drop folder"),
+		handlingQueue:     fs.Int("handling.queue", 10000, "largest number of handling events queued for processing, refusing more while full (0 to process them as they are registered)"),
+		handlingReplay:    fs.Duration("handling.replay", handling.DefaultReplayWindow, "how far the time signed handling reports were signed at may be off, and how long their nonces are remembered"),
+		requireRelease:    fs.Bool("handling.release", true, "require claims to present the release code issued to the consignee of the cargo"),
+		projectionPoll:    fs.Duration("projections.interval", time.Second, "how often read-model projections catch up with cargo changes"),
+		dropKey:           fs.String("drop.key", "", "key of the terminal reporting the files of the handling drop folder"),
+		mailTemplates:     fs.String("inbound.templates", "", "JSON file of templates recognizing the handling reports agents mail"),
+		mailToken:         fs.String("inbound.token", mailtk, "token the mail provider posts inbound mail with (empty to refuse all)"),
+		piiKeys:           fs.String("pii.keys", piikey, "keys encrypting personal data at rest, e.g. k2=base64,k1=base64 with the current key first (empty to disable)"),
+		idKey:             fs.String("ids.key", idskey, "key of the deployment encoding sequence numbers shown to clients, such as change feed cursors (empty to show them as stored)"),
+		carrierToken:      fs.String("carrier.token", carrtk, "token carriers confirm leg bookings with (empty to refuse all)"),
+		handlingToken:     fs.String("handling.token", termtk, "token administrators register handling terminals with (empty to refuse all)"),
+		carrierMock:       fs.Bool("carrier.mock", true, "confirm leg bookings with a mock carrier adapter, in place of carriers"),
+		deniedParties:     fs.String("screening.parties", "", "comma-separated IDs or names of sanctioned customers"),
+		deniedCountries:   fs.String("screening.countries", "", "comma-separated ISO codes of embargoed countries"),
+		screeningFlagOnly: fs.Bool("screening.flag", false, "flag bookings matching the deny list for review instead of refusing them"),
+		screeningURL:      fs.String("screening.url", "", "URL of an external screening API (empty to disable)"),
+		restrictionsFile:  fs.String("customs.restrictions", "", "JSON file of import restrictions by destination country"),
+		overbookingFile:   fs.String("booking.overbooking", "", "JSON file of the percentages voyages may be overbooked by, by service string and voyage"),
+		strictCustoms:     fs.Bool("delivery.customs.strict", false, "misdirect cargos clearing customs anywhere but where their itineraries unload them"),
+		arrivalOnCustoms:  fs.Bool("delivery.customs.arrival", false, "have cargos arrive once they clear customs at their destinations, rather than when unloaded"),
+		unconfirmedLoads:  fs.Bool("delivery.unconfirmed", false, "accept cargos loaded onto legs whose bookings carriers have yet to confirm"),
+		customActivities:  fs.String("delivery.activities", "", "handling activities beyond the standard ones, taking place in port, e.g. Inspection=100,Fumigation=101 (types from 100 up)"),
+		publicInterval:    fs.Duration("public.interval", server.DefaultPublicTracking.Interval, "how often each client may look up a cargo on the public tracking page (0 for no limit)"),
+		publicBurst:       fs.Int("public.burst", server.DefaultPublicTracking.Burst, "number of lookups each client may make at once on the public tracking page"),
+		replicationToken:  fs.String("replication.token", repltk, "token deployments and operators replicate, fail over and compare digests with (empty to refuse all)"),
+		replicationRole:   fs.String("replication.role", "", "role of the deployment in replication to a standby in another region, primary or standby (empty to not replicate)"),
+		webUI:             fs.Bool("webui", false, "serve a web interface for booking and tracking cargos at /ui, for demos"),
+		publicProxy:       fs.Bool("public.proxy", false, "identify public tracking clients by the X-Forwarded-For header of a reverse proxy"),
+		bootstrapFile:     fs.String("bootstrap", "", "JSON export of the shipments in flight in a transport management system to import on startup (empty for none)"),
+		datasetName:       fs.String("fixtures", "sample", "dataset of locations, voyages and service strings to seed, one of "+strings.Join(fixtures.Names(), ", ")+" or a JSON file"),
+		volumeInterval:    fs.Duration("repository.volume.interval", 5*time.Minute, "how often to sample the number of records and storage size of each repository"),
+		repositoryDebug:   fs.Bool("repository.debug", false, "log every call to the cargo, location, voyage and handling event repositories"),
+		chaosEnabled:      fs.Bool("chaos", false, "inject faults into repositories and the routing client (never in production)"),
+		chaosLatency:      fs.Duration("chaos.latency", 0, "longest latency injected into each call"),
+		chaosErrors:       fs.Float64("chaos.errors", 0, "share of calls failing with injected errors, between 0 and 1"),
+	}
+}
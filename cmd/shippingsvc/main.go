@@ -2,55 +2,143 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/go-kit/kit/log"
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	kithttp "github.com/go-kit/kit/transport/http"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	"gopkg.in/mgo.v2"
 
 	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/amendment"
+	"github.com/marcusolsson/goddd/audit"
 	"github.com/marcusolsson/goddd/booking"
+	"github.com/marcusolsson/goddd/bootstrap"
+	"github.com/marcusolsson/goddd/briefing"
+	"github.com/marcusolsson/goddd/carrier"
+	"github.com/marcusolsson/goddd/changefeed"
+	"github.com/marcusolsson/goddd/chaos"
+	"github.com/marcusolsson/goddd/command"
+	"github.com/marcusolsson/goddd/consolidation"
+	"github.com/marcusolsson/goddd/dashboard"
+	"github.com/marcusolsson/goddd/fixtures"
+	"github.com/marcusolsson/goddd/geo"
 	"github.com/marcusolsson/goddd/handling"
+	"github.com/marcusolsson/goddd/inbound"
+	"github.com/marcusolsson/goddd/ingest"
 	"github.com/marcusolsson/goddd/inmem"
 	"github.com/marcusolsson/goddd/inspection"
+	"github.com/marcusolsson/goddd/instrumenting"
 	"github.com/marcusolsson/goddd/mongo"
+	"github.com/marcusolsson/goddd/notification"
+	"github.com/marcusolsson/goddd/opaque"
+	"github.com/marcusolsson/goddd/pii"
+	"github.com/marcusolsson/goddd/portal"
+	"github.com/marcusolsson/goddd/portstatus"
+	"github.com/marcusolsson/goddd/position"
+	"github.com/marcusolsson/goddd/privacy"
+	"github.com/marcusolsson/goddd/projections"
+	"github.com/marcusolsson/goddd/reconciliation"
+	"github.com/marcusolsson/goddd/replication"
 	"github.com/marcusolsson/goddd/routing"
+	"github.com/marcusolsson/goddd/scheduling"
+	"github.com/marcusolsson/goddd/screening"
 	"github.com/marcusolsson/goddd/server"
+	"github.com/marcusolsson/goddd/sla"
+	"github.com/marcusolsson/goddd/task"
+	"github.com/marcusolsson/goddd/telemetry"
 	"github.com/marcusolsson/goddd/tracking"
 )
 
 const (
-	defaultPort              = "8080"
-	defaultRoutingServiceURL = "http://localhost:7878"
-	defaultMongoDBURL        = "127.0.0.1"
-	defaultDBName            = "dddsample"
+	defaultPort                 = "8080"
+	defaultRoutingServiceURL    = "http://localhost:7878"
+	defaultMongoDBURL           = "127.0.0.1"
+	defaultDBName               = "dddsample"
+	defaultRoutingTimeout       = 2 * time.Second
+	defaultRoutingClientTimeout = 30 * time.Second
+	defaultConnectionTime       = 2 * time.Hour
 )
 
 func main() {
 	var (
-		addr   = envString("PORT", defaultPort)
-		rsurl  = envString("ROUTINGSERVICE_URL", defaultRoutingServiceURL)
-		dburl  = envString("MONGODB_URL", defaultMongoDBURL)
-		dbname = envString("DB_NAME", defaultDBName)
-
-		httpAddr          = flag.String("http.addr", ":"+addr, "HTTP listen address")
-		routingServiceURL = flag.String("service.routing", rsurl, "routing service URL")
-		mongoDBURL        = flag.String("db.url", dburl, "MongoDB URL")
-		databaseName      = flag.String("db.name", dbname, "MongoDB database name")
-		inmemory          = flag.Bool("inmem", false, "use in-memory repositories")
-
+		cfg = registerFlags(flag.CommandLine)
 		ctx = context.Background()
 	)
 
 	flag.Parse()
 
+	if err := applyPreset(flag.CommandLine, *cfg.environment); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	connections, err := parseConnectionTimes(*cfg.connectionTime, *cfg.portConnections)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var restrictions shipping.ImportRestrictions
+	if *cfg.restrictionsFile != "" {
+		f, err := os.Open(*cfg.restrictionsFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		restrictions, err = shipping.LoadImportRestrictions(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	var overbooking shipping.OverbookingPolicy
+	if *cfg.overbookingFile != "" {
+		f, err := os.Open(*cfg.overbookingFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		overbooking, err = shipping.LoadOverbookingPolicy(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	activities, err := parseActivityTypes(*cfg.customActivities)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	deliveryPolicy := shipping.StandardDeliveryPolicy{
+		StrictCustoms:    *cfg.strictCustoms,
+		ArrivalOnCustoms: *cfg.arrivalOnCustoms,
+		UnconfirmedLoads: *cfg.unconfirmedLoads,
+		Activities:       activities,
+	}
+
+	dataset, err := fixtures.Open(*cfg.datasetName, time.Now())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
 	var logger log.Logger
 	logger = log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
 	logger = log.With(logger, "ts", log.DefaultTimestampUTC)
@@ -60,16 +148,81 @@ func main() {
 		cargos         shipping.CargoRepository
 		locations      shipping.LocationRepository
 		voyages        shipping.VoyageRepository
+		serviceStrings shipping.ServiceStringRepository
+		portStatuses   shipping.PortStatusRepository
+		terminals      shipping.TerminalRepository
+		slas           shipping.SLARepository
+		customers      shipping.CustomerRepository
 		handlingEvents shipping.HandlingEventRepository
+		users          shipping.UserRepository
+		amendments     shipping.AmendmentRepository
+		tasks          shipping.TaskRepository
+		auditEntries   shipping.AuditRepository
+		cargoChanges   shipping.CargoChangeRepository
+		calendars      shipping.WorkingCalendarRepository
+		positions      shipping.VesselPositionRepository
+		telemetryLog   shipping.TelemetryRepository
+		drafts         shipping.BookingDraftRepository
+		checkpoints    shipping.ProjectionCheckpointRepository
+		performance    shipping.LanePerformanceRepository
+		variances      shipping.HandlingVarianceRepository
+		releaseCodes   shipping.ReleaseCodeRepository
+		replicationLog shipping.ReplicationLogRepository
+		reportNonces   shipping.ReportNonceRepository
+
+		// Repositories serving the query side, which may lag behind the
+		// ones above when reading from replicas.
+		queryCargos         shipping.CargoRepository
+		queryHandlingEvents shipping.HandlingEventRepository
+
+		volumes shipping.VolumeReporter
 	)
 
-	if *inmemory {
+	if *cfg.inmemory {
 		cargos = inmem.NewCargoRepository()
 		locations = inmem.NewLocationRepository()
 		voyages = inmem.NewVoyageRepository()
+		serviceStrings = inmem.NewServiceStringRepository()
+		portStatuses = inmem.NewPortStatusRepository()
+		terminals = inmem.NewTerminalRepository()
+		slas = inmem.NewSLARepository()
+		customers = inmem.NewCustomerRepository()
 		handlingEvents = inmem.NewHandlingEventRepository()
+		users = inmem.NewUserRepository()
+		amendments = inmem.NewAmendmentRepository()
+		tasks = inmem.NewTaskRepository()
+		auditEntries = inmem.NewAuditRepository()
+		cargoChanges = inmem.NewCargoChangeRepository()
+		calendars = inmem.NewWorkingCalendarRepository()
+		positions = inmem.NewVesselPositionRepository()
+		telemetryLog = inmem.NewTelemetryRepository()
+		drafts = inmem.NewBookingDraftRepository()
+		checkpoints = inmem.NewProjectionCheckpointRepository()
+		performance = inmem.NewLanePerformanceRepository()
+		variances = inmem.NewHandlingVarianceRepository()
+		releaseCodes = inmem.NewReleaseCodeRepository()
+		replicationLog = inmem.NewReplicationLogRepository()
+		reportNonces = inmem.NewReportNonceRepository()
+
+		queryCargos = cargos
+		queryHandlingEvents = handlingEvents
+
+		volumes = inmem.NewVolumeReporter(map[string]interface{}{
+			"cargo":          cargos,
+			"handling_event": handlingEvents,
+			"task":           tasks,
+			"audit":          auditEntries,
+			"cargo_change":   cargoChanges,
+			"telemetry":      telemetryLog,
+			"variance":       variances,
+			"replication":    replicationLog,
+		})
 	} else {
-		session, err := mgo.Dial(*mongoDBURL)
+		session, err := mongo.Dial(*cfg.mongoDBURL, mongo.DialOptions{
+			PoolLimit:              *cfg.dbPoolLimit,
+			ServerSelectionTimeout: *cfg.dbTimeout,
+			OperationTimeout:       *cfg.dbOpTimeout,
+		})
 		if err != nil {
 			panic(err)
 		}
@@ -77,95 +230,908 @@ func main() {
 
 		session.SetMode(mgo.Monotonic, true)
 
-		cargos, _ = mongo.NewCargoRepository(*databaseName, session)
-		locations, _ = mongo.NewLocationRepository(*databaseName, session)
-		voyages, _ = mongo.NewVoyageRepository(*databaseName, session)
-		handlingEvents = mongo.NewHandlingEventRepository(*databaseName, session)
+		retry := mongo.WithRetryPolicy(mongo.RetryPolicy{
+			Attempts: *cfg.dbRetries,
+			Backoff:  mongo.DefaultRetryPolicy.Backoff,
+		})
+
+		cargos, _ = mongo.NewCargoRepository(*cfg.databaseName, session, retry)
+		locations, _ = mongo.NewLocationRepository(*cfg.databaseName, session, retry)
+		voyages, _ = mongo.NewVoyageRepository(*cfg.databaseName, session, retry)
+		serviceStrings, _ = mongo.NewServiceStringRepository(*cfg.databaseName, session, retry)
+		portStatuses, _ = mongo.NewPortStatusRepository(*cfg.databaseName, session, retry)
+		terminals, _ = mongo.NewTerminalRepository(*cfg.databaseName, session, retry)
+		slas, _ = mongo.NewSLARepository(*cfg.databaseName, session, retry)
+		customers, _ = mongo.NewCustomerRepository(*cfg.databaseName, session, retry)
+		handlingEvents = mongo.NewHandlingEventRepository(*cfg.databaseName, session, retry)
+		users, _ = mongo.NewUserRepository(*cfg.databaseName, session, retry)
+		amendments, _ = mongo.NewAmendmentRepository(*cfg.databaseName, session, retry)
+		tasks, _ = mongo.NewTaskRepository(*cfg.databaseName, session, retry)
+		auditEntries, _ = mongo.NewAuditRepository(*cfg.databaseName, session, retry)
+		cargoChanges, _ = mongo.NewCargoChangeRepository(*cfg.databaseName, session, retry)
+		calendars, _ = mongo.NewWorkingCalendarRepository(*cfg.databaseName, session, retry)
+		positions, _ = mongo.NewVesselPositionRepository(*cfg.databaseName, session, retry)
+		telemetryLog, _ = mongo.NewTelemetryRepository(*cfg.databaseName, session, retry)
+		drafts, _ = mongo.NewBookingDraftRepository(*cfg.databaseName, session, retry)
+		checkpoints, _ = mongo.NewProjectionCheckpointRepository(*cfg.databaseName, session, retry)
+		performance, _ = mongo.NewLanePerformanceRepository(*cfg.databaseName, session, retry)
+		variances, _ = mongo.NewHandlingVarianceRepository(*cfg.databaseName, session, retry)
+		releaseCodes, _ = mongo.NewReleaseCodeRepository(*cfg.databaseName, session, retry)
+		replicationLog, _ = mongo.NewReplicationLogRepository(*cfg.databaseName, session, retry)
+		reportNonces, _ = mongo.NewReportNonceRepository(*cfg.databaseName, session, retry)
+
+		mode, err := mongo.ParseReadPreference(*cfg.dbReadPreference)
+		if err != nil {
+			panic(err)
+		}
+		read := mongo.WithReadPreference(mode)
+
+		queryCargos, _ = mongo.NewCargoRepository(*cfg.databaseName, session, retry, read)
+		queryHandlingEvents = mongo.NewHandlingEventRepository(*cfg.databaseName, session, retry, read)
+
+		volumes = mongo.NewVolumeReporter(*cfg.databaseName, session)
+	}
+
+	if *cfg.piiKeys != "" {
+		keys, err := pii.ParseStaticKeys(*cfg.piiKeys)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		customers = pii.NewCustomerRepository(pii.NewCipher(keys), customers)
+	} else {
+		logger.Log("msg", "personal data is stored unencrypted")
+	}
+
+	if err := dataset.Seed(locations, voyages, serviceStrings); err != nil {
+		panic(err)
+	}
+
+	logger.Log("msg", "seeded fixtures", "dataset", *cfg.datasetName, "locations", len(dataset.Locations), "voyages", len(dataset.Voyages))
+
+	// Route fetches outlive the requests that start them, so the client
+	// bounds how long one may take.
+	routingHTTPClient := &http.Client{Timeout: defaultRoutingClientTimeout}
+
+	if *cfg.chaosEnabled {
+		faults := chaos.Faults{Latency: *cfg.chaosLatency, ErrorRate: *cfg.chaosErrors}
+
+		logger.Log("msg", "injecting faults", "latency", faults.Latency, "errors", faults.ErrorRate)
+
+		cargos = chaos.NewCargoRepository(faults, cargos)
+		locations = chaos.NewLocationRepository(faults, locations)
+		voyages = chaos.NewVoyageRepository(faults, voyages)
+		serviceStrings = chaos.NewServiceStringRepository(faults, serviceStrings)
+		portStatuses = chaos.NewPortStatusRepository(faults, portStatuses)
+		terminals = chaos.NewTerminalRepository(faults, terminals)
+		slas = chaos.NewSLARepository(faults, slas)
+		customers = chaos.NewCustomerRepository(faults, customers)
+		handlingEvents = chaos.NewHandlingEventRepository(faults, handlingEvents)
+		users = chaos.NewUserRepository(faults, users)
+		amendments = chaos.NewAmendmentRepository(faults, amendments)
+		tasks = chaos.NewTaskRepository(faults, tasks)
+		auditEntries = chaos.NewAuditRepository(faults, auditEntries)
+		cargoChanges = chaos.NewCargoChangeRepository(faults, cargoChanges)
+		calendars = chaos.NewWorkingCalendarRepository(faults, calendars)
+		positions = chaos.NewVesselPositionRepository(faults, positions)
+		telemetryLog = chaos.NewTelemetryRepository(faults, telemetryLog)
+		drafts = chaos.NewBookingDraftRepository(faults, drafts)
+		checkpoints = chaos.NewProjectionCheckpointRepository(faults, checkpoints)
+		performance = chaos.NewLanePerformanceRepository(faults, performance)
+		variances = chaos.NewHandlingVarianceRepository(faults, variances)
+		releaseCodes = chaos.NewReleaseCodeRepository(faults, releaseCodes)
+		replicationLog = chaos.NewReplicationLogRepository(faults, replicationLog)
+		reportNonces = chaos.NewReportNonceRepository(faults, reportNonces)
+
+		queryCargos = chaos.NewCargoRepository(faults, queryCargos)
+		queryHandlingEvents = chaos.NewHandlingEventRepository(faults, queryHandlingEvents)
+
+		routingHTTPClient.Transport = chaos.NewTransport(faults, http.DefaultTransport)
+	}
+
+	fence := replication.NewFence(replication.Primary)
+	if *cfg.replicationRole != "" {
+		role, err := replication.ParseRole(*cfg.replicationRole)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		fence = replication.NewFence(role)
+
+		// Log the cargos and voyages stored, for standbys to follow.
+		cargos = replication.NewCargoRepository(replicationLog, cargos)
+		voyages = replication.NewVoyageRepository(replicationLog, voyages)
+		handlingEvents = replication.NewHandlingEventRepository(replicationLog, handlingEvents)
+
+		logger.Log("msg", "replicating", "role", role)
 	}
 
+	repositoryKeys := []string{"repository", "method"}
+	instruments := instrumenting.Instruments{
+		Calls: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "repository",
+			Name:      "call_count",
+			Help:      "Number of repository calls.",
+		}, repositoryKeys),
+		Errors: kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "repository",
+			Name:      "error_count",
+			Help:      "Number of failed repository calls.",
+		}, repositoryKeys),
+		Latency: kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "repository",
+			Name:      "call_latency_seconds",
+			Help:      "Total duration of repository calls in seconds.",
+		}, repositoryKeys),
+		Results: kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "repository",
+			Name:      "result_size",
+			Help:      "Number of entities returned by repository calls.",
+		}, repositoryKeys),
+	}
+	if *cfg.repositoryDebug {
+		instruments.Logger = log.With(logger, "component", "repository")
+	}
+
+	cargos = instrumenting.NewCargoRepository(instruments, cargos)
+	locations = instrumenting.NewLocationRepository(instruments, locations)
+	voyages = instrumenting.NewVoyageRepository(instruments, voyages)
+	handlingEvents = instrumenting.NewHandlingEventRepository(instruments, handlingEvents)
+	queryCargos = instrumenting.NewCargoRepository(instruments, queryCargos)
+	queryHandlingEvents = instrumenting.NewHandlingEventRepository(instruments, queryHandlingEvents)
+
+	volumeMonitor := instrumenting.NewVolumeMonitor(volumes, instrumenting.VolumeGauges{
+		Records: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: "api",
+			Subsystem: "repository",
+			Name:      "records",
+			Help:      "Number of records held by a repository.",
+		}, []string{"repository"}),
+		Bytes: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: "api",
+			Subsystem: "repository",
+			Name:      "storage_bytes",
+			Help:      "Storage taken up by the records of a repository in bytes.",
+		}, []string{"repository"}),
+		OldestAge: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: "api",
+			Subsystem: "repository",
+			Name:      "oldest_record_age_seconds",
+			Help:      "Age of the oldest record held by a repository in seconds.",
+		}, []string{"repository"}),
+	}, log.With(logger, "component", "repository"))
+	go volumeMonitor.Run(ctx, *cfg.volumeInterval)
+
+	fieldKeys := []string{"method"}
+
+	var cfs changefeed.Service
+	cfs = changefeed.NewService(cargoChanges)
+	cfs = changefeed.NewLoggingService(log.With(logger, "component", "changefeed"), cfs)
+	cfs = changefeed.NewInstrumentingService(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "changefeed_service",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "changefeed_service",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, fieldKeys),
+		cfs,
+	)
+
 	// Configure some questionable dependencies.
 	var (
 		handlingEventFactory = shipping.HandlingEventFactory{
 			CargoRepository:    cargos,
 			VoyageRepository:   voyages,
 			LocationRepository: locations,
+			ImportRestrictions: restrictions,
 		}
-		handlingEventHandler = handling.NewEventHandler(
-			inspection.NewService(cargos, handlingEvents, nil),
+		consolidationEventHandler = consolidation.NewEventHandler(cargos, handlingEvents, consolidation.WithDeliveryPolicy(deliveryPolicy))
+		taskService               = task.NewService(tasks, users)
+		notifier                  = notification.NewNotifier(customers, notification.NewLoggingSender(log.With(logger, "component", "notification")))
+		slaService                = sla.NewService(cargos, handlingEvents, slas,
+			sla.EventHandlers{
+				sla.NewLoggingEventHandler(log.With(logger, "component", "sla")),
+				task.NewSLAEventHandler(taskService),
+				notification.NewSLAEventHandler(notifier),
+			},
+			sla.WithCalendars(calendars),
+		)
+		portStatusService = portstatus.NewService(portStatuses, calendars, locations,
+			portstatus.WithVariances(cargos, variances,
+				portstatus.EventHandlers{
+					portstatus.NewLoggingEventHandler(log.With(logger, "component", "portstatus")),
+					task.NewPortStatusEventHandler(taskService),
+				},
+			),
+			portstatus.WithVarianceThreshold(*cfg.varianceThreshold, *cfg.varianceWindow),
 		)
+		inspectionEventHandler = inspection.EventHandlers{
+			inspection.NewLoggingEventHandler(log.With(logger, "component", "inspection")),
+			task.NewInspectionEventHandler(taskService),
+			notification.NewInspectionEventHandler(notifier),
+			changefeed.NewInspectionEventHandler(cfs),
+		}
+		handlingEventHandler = handling.EventHandlers{
+			consolidationEventHandler,
+			handling.NewEventHandler(
+				inspection.NewService(cargos, handlingEvents, inspectionEventHandler, inspection.WithDeliveryPolicy(deliveryPolicy)),
+			),
+			sla.NewHandlingEventHandler(slaService),
+			portstatus.NewHandlingEventHandler(portStatusService),
+			notification.NewHandlingEventHandler(notifier, cargos),
+			changefeed.NewHandlingEventHandler(cfs),
+		}
 	)
 
 	// Facilitate testing by adding some cargos.
 	storeTestData(cargos)
 
-	fieldKeys := []string{"method"}
+	if *cfg.bootstrapFile != "" {
+		f, err := os.Open(*cfg.bootstrapFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		im := bootstrap.NewImporter(cargos, handlingEvents, locations, voyages, bootstrap.WithDeliveryPolicy(deliveryPolicy))
+		report, err := im.Import(ctx, f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		logger.Log("msg", "bootstrapped shipments", "file", *cfg.bootstrapFile, "total", report.Total, "imported", report.Imported)
+		for _, u := range report.Unreconciled {
+			logger.Log("msg", "unreconciled shipment", "index", u.Index, "tracking_id", u.TrackingID, "reason", u.Reason)
+		}
+	}
 
 	var rs shipping.RoutingService
-	rs = routing.NewProxyingMiddleware(ctx, *routingServiceURL)(rs)
+	var lanes []geo.SeaLane
+	if *cfg.seaLanes {
+		lanes = geo.CommonSeaLanes
+	}
+	distances := geo.NewMatrix(locations, lanes...)
 
-	var bs booking.Service
-	bs = booking.NewService(cargos, locations, handlingEvents, rs)
-	bs = booking.NewLoggingService(log.With(logger, "component", "booking"), bs)
-	bs = booking.NewInstrumentingService(
+	if *cfg.localRouting {
+		rs = routing.NewLocalService(voyages)
+	} else {
+		rs = routing.NewProxyingMiddleware(ctx, *cfg.routingServiceURL, kithttp.SetClient(routingHTTPClient))(rs)
+	}
+	rs = routing.NewConnectionMiddleware(connections)(rs)
+	rs = routing.NewPortStatusMiddleware(portStatuses)(rs)
+
+	duplicatePolicy := booking.DuplicateWarn
+	if *cfg.duplicateBlock {
+		duplicatePolicy = booking.DuplicateBlock
+	}
+
+	var aus audit.Service
+	aus = audit.NewService(auditEntries)
+	aus = audit.NewLoggingService(log.With(logger, "component", "audit"), aus)
+	aus = audit.NewInstrumentingService(
 		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
 			Namespace: "api",
-			Subsystem: "booking_service",
+			Subsystem: "audit_service",
 			Name:      "request_count",
 			Help:      "Number of requests received.",
 		}, fieldKeys),
 		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
 			Namespace: "api",
-			Subsystem: "booking_service",
+			Subsystem: "audit_service",
 			Name:      "request_latency_microseconds",
 			Help:      "Total duration of requests in microseconds.",
 		}, fieldKeys),
-		bs,
+		aus,
+	)
+
+	denyList := screening.DenyList{
+		Parties:   screening.ParseList(*cfg.deniedParties),
+		Countries: screening.ParseList(*cfg.deniedCountries),
+	}
+	if *cfg.screeningFlagOnly {
+		denyList.Decision = shipping.ScreeningFlagged
+	}
+
+	screeners := screening.Screeners{denyList}
+	if *cfg.screeningURL != "" {
+		remote, err := screening.NewRemoteScreener(*cfg.screeningURL)
+		if err != nil {
+			panic(err)
+		}
+		screeners = append(screeners, remote)
+	}
+
+	screener := audit.NewScreener(aus, screeners)
+
+	routingEventHandler := booking.EventHandlers{consolidationEventHandler}
+	if *cfg.carrierMock {
+		routingEventHandler = append(routingEventHandler, carrier.NewBookingEventHandler(cargos, carrier.NewMockAdapter()))
+	}
+	routingEventHandler = append(routingEventHandler,
+		changefeed.NewBookingEventHandler(cfs),
+		notification.NewBookingEventHandler(notifier),
 	)
 
+	var bs booking.Service
+	bs = booking.NewService(cargos, locations, handlingEvents, rs,
+		booking.WithRoutingTimeout(*cfg.routingTimeout),
+		booking.WithQueryRepositories(queryCargos, queryHandlingEvents),
+		booking.WithServiceStrings(serviceStrings),
+		booking.WithVoyages(voyages),
+		booking.WithCalendars(calendars),
+		booking.WithConnectionTimes(connections),
+		booking.WithScheduleValidation(*cfg.scheduleTolerance),
+		booking.WithEventHandler(routingEventHandler),
+		booking.WithDuplicateDetection(*cfg.duplicateWindow, duplicatePolicy),
+		booking.WithScreening(screener, customers),
+		booking.WithImportRestrictions(restrictions),
+		booking.WithOverbookingPolicy(overbooking),
+		booking.WithDrafts(drafts),
+		booking.WithReleaseCodes(releaseCodes),
+		booking.WithTimeline(auditEntries, cargoChanges, tasks),
+		booking.WithEmissions(shipping.EmissionsCalculator{
+			Voyages:   voyages,
+			Distances: distances,
+			Factors:   shipping.DefaultEmissionFactors,
+		}),
+		booking.WithRoutePerformance(performance),
+		booking.WithDeliveryPolicy(deliveryPolicy),
+		booking.WithLogger(log.With(logger, "component", "booking")),
+		booking.WithMetrics(
+			kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+				Namespace: "api",
+				Subsystem: "booking_service",
+				Name:      "request_count",
+				Help:      "Number of requests received.",
+			}, fieldKeys),
+			kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+				Namespace: "api",
+				Subsystem: "booking_service",
+				Name:      "request_latency_microseconds",
+				Help:      "Total duration of requests in microseconds.",
+			}, fieldKeys),
+		),
+	)
+
+	// Commands changing the state of cargos are dispatched through the bus,
+	// which handles them with the service above.
+	bus := command.NewBus(
+		command.Validating(),
+		command.Idempotent(*cfg.idempotencyWindow),
+		audit.NewCommandMiddleware(aus),
+	)
+	booking.RegisterCommands(bus, bs)
+	bs = booking.NewCommandService(bus, bs)
+
+	// Suggest tracking IDs for mistyped ones from the cargos booked. The
+	// index is kept up to date by a projection.
+	trackingIndex := tracking.NewIndex()
+	if *cfg.trackingFuzziness > 0 {
+		if err := trackingIndex.Rebuild(queryCargos); err != nil {
+			logger.Log("component", "tracking", "err", err)
+		}
+	}
+
 	var ts tracking.Service
-	ts = tracking.NewService(cargos, handlingEvents)
-	ts = tracking.NewLoggingService(log.With(logger, "component", "tracking"), ts)
-	ts = tracking.NewInstrumentingService(
+	ts = tracking.NewService(queryCargos, queryHandlingEvents,
+		tracking.WithPortStatuses(portStatuses),
+		tracking.WithCalendars(calendars),
+		tracking.WithSuggestions(trackingIndex, *cfg.trackingFuzziness),
+		tracking.WithLogger(log.With(logger, "component", "tracking")),
+		tracking.WithMetrics(
+			kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+				Namespace: "api",
+				Subsystem: "tracking_service",
+				Name:      "request_count",
+				Help:      "Number of requests received.",
+			}, fieldKeys),
+			kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+				Namespace: "api",
+				Subsystem: "tracking_service",
+				Name:      "request_latency_microseconds",
+				Help:      "Total duration of requests in microseconds.",
+			}, fieldKeys),
+		),
+	)
+
+	handlingOpts := []handling.Option{
+		handling.WithReplayWindow(*cfg.handlingReplay),
+		handling.WithReportNonces(reportNonces),
+		handling.WithAdminToken(*cfg.handlingToken),
+	}
+	if *cfg.requireRelease {
+		handlingOpts = append(handlingOpts, handling.WithReleaseCodes(releaseCodes))
+	}
+	if *cfg.handlingQueue > 0 {
+		queue := handling.NewQueue(*cfg.handlingQueue,
+			handling.WithQueueLogger(log.With(logger, "component", "handling")),
+			handling.WithQueueMetrics(
+				kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+					Namespace: "api",
+					Subsystem: "handling_queue",
+					Name:      "depth",
+					Help:      "Number of handling events queued for processing.",
+				}, nil),
+				kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+					Namespace: "api",
+					Subsystem: "handling_queue",
+					Name:      "rejected_count",
+					Help:      "Number of handling events refused while the queue was full.",
+				}, nil),
+			),
+			handling.WithQueueWait(
+				kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+					Namespace: "api",
+					Subsystem: "handling_queue",
+					Name:      "wait_seconds",
+					Help:      "Seconds handling events waited in the queue, by priority class.",
+				}, []string{"priority"}),
+			),
+		)
+		go queue.Run(ctx)
+
+		handlingOpts = append(handlingOpts, handling.WithQueue(queue))
+	}
+
+	handlingOpts = append(handlingOpts,
+		handling.WithLogger(log.With(logger, "component", "handling")),
+		handling.WithMetrics(
+			kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+				Namespace: "api",
+				Subsystem: "handling_service",
+				Name:      "request_count",
+				Help:      "Number of requests received.",
+			}, fieldKeys),
+			kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+				Namespace: "api",
+				Subsystem: "handling_service",
+				Name:      "request_latency_microseconds",
+				Help:      "Total duration of requests in microseconds.",
+			}, fieldKeys),
+		),
+	)
+
+	var hs handling.Service
+	hs = handling.NewService(handlingEvents, handlingEventFactory, handlingEventHandler, terminals, handlingOpts...)
+	hs = audit.NewHandlingService(aus, hs)
+
+	var ss scheduling.Service
+	ss = scheduling.NewService(voyages, locations, serviceStrings)
+	ss = scheduling.NewLoggingService(log.With(logger, "component", "scheduling"), ss)
+	ss = scheduling.NewInstrumentingService(
 		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
 			Namespace: "api",
-			Subsystem: "tracking_service",
+			Subsystem: "scheduling_service",
 			Name:      "request_count",
 			Help:      "Number of requests received.",
 		}, fieldKeys),
 		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
 			Namespace: "api",
-			Subsystem: "tracking_service",
+			Subsystem: "scheduling_service",
 			Name:      "request_latency_microseconds",
 			Help:      "Total duration of requests in microseconds.",
 		}, fieldKeys),
-		ts,
+		ss,
 	)
 
-	var hs handling.Service
-	hs = handling.NewService(handlingEvents, handlingEventFactory, handlingEventHandler)
-	hs = handling.NewLoggingService(log.With(logger, "component", "handling"), hs)
-	hs = handling.NewInstrumentingService(
+	var cs consolidation.Service
+	cs = consolidation.NewService(cargos)
+	cs = consolidation.NewLoggingService(log.With(logger, "component", "consolidation"), cs)
+	cs = consolidation.NewInstrumentingService(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "consolidation_service",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "consolidation_service",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, fieldKeys),
+		cs,
+	)
+
+	var ps portstatus.Service
+	ps = portstatus.NewLoggingService(log.With(logger, "component", "portstatus"), portStatusService)
+	ps = portstatus.NewInstrumentingService(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "portstatus_service",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "portstatus_service",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, fieldKeys),
+		ps,
+	)
+
+	var sls sla.Service
+	sls = sla.NewLoggingService(log.With(logger, "component", "sla"), slaService)
+	sls = sla.NewInstrumentingService(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "sla_service",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "sla_service",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, fieldKeys),
+		sls,
+	)
+
+	unsubscribeSecret := []byte(*cfg.portalSecret)
+	if len(unsubscribeSecret) == 0 {
+		// Unsubscribe links will not survive a restart.
+		unsubscribeSecret = make([]byte, 32)
+		if _, err := rand.Read(unsubscribeSecret); err != nil {
+			panic(err)
+		}
+	}
+
+	var pts portal.Service
+	pts = portal.NewService(customers, queryCargos, queryHandlingEvents, unsubscribeSecret, portal.WithScreening(screener))
+	pts = portal.NewLoggingService(log.With(logger, "component", "portal"), pts)
+	pts = portal.NewInstrumentingService(
 		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
 			Namespace: "api",
-			Subsystem: "handling_service",
+			Subsystem: "portal_service",
 			Name:      "request_count",
 			Help:      "Number of requests received.",
 		}, fieldKeys),
 		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
 			Namespace: "api",
-			Subsystem: "handling_service",
+			Subsystem: "portal_service",
 			Name:      "request_latency_microseconds",
 			Help:      "Total duration of requests in microseconds.",
 		}, fieldKeys),
-		hs,
+		pts,
 	)
 
-	srv := server.New(bs, ts, hs, log.With(logger, "component", "http"))
+	var as amendment.Service
+	as = amendment.NewService(amendments, cargos, locations, users)
+	as = amendment.NewLoggingService(log.With(logger, "component", "amendment"), as)
+	as = amendment.NewInstrumentingService(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "amendment_service",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "amendment_service",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, fieldKeys),
+		as,
+	)
+	as = audit.NewAmendmentService(aus, as)
+
+	var pvs privacy.Service
+	pvs = privacy.NewService(customers, cargos, handlingEvents, auditEntries, users)
+	pvs = privacy.NewLoggingService(log.With(logger, "component", "privacy"), pvs)
+	pvs = privacy.NewInstrumentingService(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "privacy_service",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "privacy_service",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, fieldKeys),
+		pvs,
+	)
+	pvs = audit.NewPrivacyService(aus, pvs)
+
+	var tks task.Service
+	tks = task.NewLoggingService(log.With(logger, "component", "task"), taskService)
+	tks = task.NewInstrumentingService(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "task_service",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "task_service",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, fieldKeys),
+		tks,
+	)
+
+	// Keep read models up to date with the changes to cargos.
+	runner := projections.NewRunner(log.With(logger, "component", "projections"), cargoChanges, checkpoints,
+		projections.NewLanePerformance(performance, queryCargos, queryHandlingEvents, serviceStrings),
+		projections.NewTrackingIndex(trackingIndex, queryCargos),
+	)
+	go runner.Run(ctx, *cfg.projectionPoll)
+
+	// Send notification digests as they fall due.
+	go func() {
+		for range time.Tick(time.Minute) {
+			if err := notifier.Flush(context.Background()); err != nil {
+				logger.Log("component", "notification", "err", err)
+			}
+		}
+	}()
+
+	// Deliver the daily operations briefings once the hour has come.
+	briefingLocation, err := time.LoadLocation(*cfg.briefingZone)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	briefings := briefing.NewScheduler(
+		briefing.NewCompiler(cargos, tasks, auditEntries, briefingLocation),
+		notifier,
+		briefing.WithDeliveryHour(*cfg.briefingHour),
+		briefing.WithOperator(shipping.CustomerID(*cfg.briefingOperator)),
+	)
+	go func() {
+		for now := range time.Tick(time.Minute) {
+			if err := briefings.Deliver(context.Background(), now); err != nil {
+				logger.Log("component", "briefing", "err", err)
+			}
+		}
+	}()
+
+	var templates []inbound.Template
+	if *cfg.mailTemplates != "" {
+		f, err := os.Open(*cfg.mailTemplates)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		templates, err = inbound.LoadTemplates(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	var ib inbound.Service
+	ib = inbound.NewService(hs, templates, *cfg.mailToken, task.NewMailReporter(taskService))
+	ib = inbound.NewLoggingService(log.With(logger, "component", "inbound"), ib)
+	ib = inbound.NewInstrumentingService(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "inbound_service",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "inbound_service",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, fieldKeys),
+		ib,
+	)
+
+	var crs carrier.Service
+	crs = carrier.NewService(cargos, *cfg.carrierToken)
+	crs = carrier.NewLoggingService(log.With(logger, "component", "carrier"), crs)
+	crs = carrier.NewInstrumentingService(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "carrier_service",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "carrier_service",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, fieldKeys),
+		crs,
+	)
+
+	var rcs reconciliation.Service
+	rcs = reconciliation.NewService(voyages, cargos, handlingEvents,
+		reconciliation.EventHandlers{
+			reconciliation.NewLoggingEventHandler(log.With(logger, "component", "reconciliation")),
+			task.NewReconciliationEventHandler(taskService),
+		},
+	)
+	rcs = reconciliation.NewLoggingService(log.With(logger, "component", "reconciliation"), rcs)
+	rcs = reconciliation.NewInstrumentingService(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "reconciliation_service",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "reconciliation_service",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, fieldKeys),
+		rcs,
+	)
+
+	var rps replication.Service
+	rps = replication.NewService(fence, replicationLog, cargos, handlingEvents, voyages, checkpoints, *cfg.replicationToken)
+	rps = replication.NewLoggingService(log.With(logger, "component", "replication"), rps)
+	rps = replication.NewInstrumentingService(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "replication_service",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "replication_service",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, fieldKeys),
+		rps,
+	)
+
+	var vps position.Service
+	vps = position.NewService(positions, voyages, cargos, locations, lanes, inspectionEventHandler,
+		position.WithGeofence(*cfg.geofence),
+		position.WithEventHandler(reconciliation.NewPositionEventHandler(rcs)),
+	)
+	vps = position.NewLoggingService(log.With(logger, "component", "position"), vps)
+	vps = position.NewInstrumentingService(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "position_service",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "position_service",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, fieldKeys),
+		vps,
+	)
+
+	var tls telemetry.Service
+	tls = telemetry.NewService(telemetryLog, cargos,
+		telemetry.EventHandlers{
+			telemetry.NewLoggingEventHandler(log.With(logger, "component", "telemetry")),
+			notification.NewTelemetryEventHandler(notifier),
+		},
+	)
+	tls = telemetry.NewLoggingService(log.With(logger, "component", "telemetry"), tls)
+	tls = telemetry.NewInstrumentingService(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "telemetry_service",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "telemetry_service",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, fieldKeys),
+		tls,
+	)
+
+	if *cfg.dropDir != "" {
+		sources := []ingest.Source{{
+			Name:   "portstatus",
+			Folder: ingest.NewDirFolder(filepath.Join(*cfg.dropDir, "portstatus")),
+			Rules:  []ingest.Rule{{Pattern: "*.csv", Importer: ingest.NewPortStatusImporter(ps)}},
+		}}
+		if *cfg.dropTerminal != "" {
+			source := handling.Credentials{Terminal: shipping.TerminalID(*cfg.dropTerminal), Key: *cfg.dropKey}
+			sources = append(sources, ingest.Source{
+				Name:   "handling",
+				Folder: ingest.NewDirFolder(filepath.Join(*cfg.dropDir, "handling")),
+				Rules:  []ingest.Rule{{Pattern: "*.csv", Importer: ingest.NewHandlingImporter(hs, source)}},
+			})
+		}
+
+		poller := ingest.NewPoller(log.With(logger, "component", "ingest"), task.NewIngestionReporter(taskService), sources...)
+		go poller.Run(ctx, *cfg.dropInterval)
+	}
+
+	var dbs dashboard.Service
+	dbs = dashboard.NewService(bs, tks, dashboard.WithLocation(briefingLocation))
+	dbs = dashboard.NewLoggingService(log.With(logger, "component", "dashboard"), dbs)
+	dbs = dashboard.NewInstrumentingService(
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "api",
+			Subsystem: "dashboard_service",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, fieldKeys),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "api",
+			Subsystem: "dashboard_service",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, fieldKeys),
+		dbs,
+	)
+
+	ids := opaque.Decimal
+	if *cfg.idKey != "" {
+		ids = opaque.NewKeyed([]byte(*cfg.idKey))
+	}
+
+	serverOpts := []server.Option{
+		server.WithActivityTypes(activities),
+		server.WithIDCodec(ids),
+		server.WithPublicTracking(server.PublicTracking{
+			Interval:   *cfg.publicInterval,
+			Burst:      *cfg.publicBurst,
+			TrustProxy: *cfg.publicProxy,
+		}),
+		server.WithVolumes(volumeMonitor),
+	}
+	if *cfg.webUI {
+		serverOpts = append(serverOpts, server.WithWebUI())
+	}
+	if *cfg.replicationRole != "" {
+		serverOpts = append(serverOpts, server.WithFence(fence))
+	}
+
+	srv := server.New(server.Services{
+		Booking:        bs,
+		Tracking:       ts,
+		Handling:       hs,
+		Scheduling:     ss,
+		Consolidation:  cs,
+		PortStatus:     ps,
+		SLA:            sls,
+		Portal:         pts,
+		Amendment:      as,
+		Task:           tks,
+		Inbound:        ib,
+		Audit:          aus,
+		ChangeFeed:     cfs,
+		Carrier:        crs,
+		Position:       vps,
+		Telemetry:      tls,
+		Privacy:        pvs,
+		Dashboard:      dbs,
+		Reconciliation: rcs,
+		Replication:    rps,
+	}, log.With(logger, "component", "http"), serverOpts...)
 
 	errs := make(chan error, 2)
 	go func() {
-		logger.Log("transport", "http", "address", *httpAddr, "msg", "listening")
-		errs <- http.ListenAndServe(*httpAddr, srv)
+		logger.Log("transport", "http", "address", *cfg.httpAddr, "msg", "listening")
+		errs <- http.ListenAndServe(*cfg.httpAddr, srv)
 	}()
 	go func() {
 		c := make(chan os.Signal, 1)
@@ -184,6 +1150,63 @@ func envString(env, fallback string) string {
 	return e
 }
 
+// parseConnectionTimes parses a comma-separated list of per-port connection
+// times, such as "DEHAM=12h,SESTO=4h".
+func parseConnectionTimes(def time.Duration, s string) (shipping.ConnectionTimes, error) {
+	c := shipping.ConnectionTimes{
+		Default:    def,
+		ByLocation: make(map[shipping.UNLocode]time.Duration),
+	}
+
+	if s == "" {
+		return c, nil
+	}
+
+	for _, kv := range strings.Split(s, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return c, fmt.Errorf("invalid connection time %q", kv)
+		}
+
+		d, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return c, fmt.Errorf("invalid connection time %q: %v", kv, err)
+		}
+
+		c.ByLocation[shipping.UNLocode(strings.TrimSpace(parts[0]))] = d
+	}
+
+	return c, nil
+}
+
+// parseActivityTypes parses a comma-separated list of custom handling
+// activities and their types, such as "Inspection=100,Fumigation=101".
+func parseActivityTypes(s string) (shipping.ActivityTypes, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var ts shipping.ActivityTypes
+	for _, kv := range strings.Split(s, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid activity %q", kv)
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid activity %q: %v", kv, err)
+		}
+
+		ts = append(ts, shipping.ActivityType{
+			Type: shipping.HandlingEventType(n),
+			Name: strings.TrimSpace(parts[0]),
+		})
+	}
+
+	return ts, ts.Validate()
+}
+
 func storeTestData(r shipping.CargoRepository) {
 	test1 := shipping.NewCargo("FTL456", shipping.RouteSpecification{
 		Origin:          shipping.AUMEL,
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -43,9 +44,18 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 
 	var (
 		bookingService       = booking.NewService(cargoRepository, locationRepository, handlingEventRepository, routingService)
-		handlingEventService = handling.NewService(handlingEventRepository, handlingEventFactory, handlingEventHandler)
+		handlingEventService = handling.NewService(handlingEventRepository, handlingEventFactory, handlingEventHandler, inmem.NewTerminalRepository(), handling.WithAdminToken("admin"))
 	)
 
+	// Register a terminal at every location, named after it.
+	for _, l := range locationRepository.FindAll() {
+		err := handlingEventService.RegisterTerminal(context.Background(), "admin", shipping.TerminalID(l.UNLocode), l.Name, l.UNLocode, "key", "")
+		chk.Assert(err, IsNil)
+	}
+	terminal := func(l shipping.UNLocode) handling.Credentials {
+		return handling.Credentials{Terminal: shipping.TerminalID(l), Key: "key"}
+	}
+
 	var (
 		origin      = shipping.CNHKG // Hongkong
 		destination = shipping.SESTO // Stockholm
@@ -56,7 +66,7 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	// Use case 1: booking
 	//
 
-	id, err := bookingService.BookNewCargo(origin, destination, deadline)
+	id, err := bookingService.BookNewCargo(context.Background(), "", origin, destination, deadline, shipping.Container20)
 
 	chk.Assert(err, IsNil)
 
@@ -74,7 +84,7 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	// Use case 2: routing
 	//
 
-	itineraries := bookingService.RequestPossibleRoutesForCargo(id)
+	itineraries := bookingService.RequestPossibleRoutesForCargo(context.Background(), id).Itineraries
 	itinerary := selectPreferredItinerary(itineraries)
 
 	c.AssignToRoute(itinerary)
@@ -92,7 +102,7 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	// Use case 3: handling
 	//
 
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 1), id, "", shipping.CNHKG, shipping.Receive)
+	err = handlingEventService.RegisterHandlingEvent(context.Background(), terminal(shipping.CNHKG), toDate(2009, time.March, 1), id, "", shipping.CNHKG, shipping.Receive)
 	chk.Check(err, IsNil)
 
 	// Ensure we're not working with stale shipping.
@@ -102,7 +112,7 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	chk.Check(c.Delivery.LastKnownLocation, Equals, shipping.CNHKG)
 	chk.Check(c.Delivery.Itinerary.IsEmpty(), Equals, false)
 
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 3), id, shipping.V100.VoyageNumber, shipping.CNHKG, shipping.Load)
+	err = handlingEventService.RegisterHandlingEvent(context.Background(), terminal(shipping.CNHKG), toDate(2009, time.March, 3), id, shipping.V100.VoyageNumber, shipping.CNHKG, shipping.Load)
 	chk.Check(err, IsNil)
 
 	c, err = cargoRepository.Find(id)
@@ -122,14 +132,14 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 
 	noSuchVoyageNumber := shipping.VoyageNumber("XX000")
 	noSuchUNLocode := shipping.UNLocode("ZZZZZ")
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 5), id, noSuchVoyageNumber, noSuchUNLocode, shipping.Load)
+	err = handlingEventService.RegisterHandlingEvent(context.Background(), terminal(noSuchUNLocode), toDate(2009, time.March, 5), id, noSuchVoyageNumber, noSuchUNLocode, shipping.Load)
 	chk.Check(err, NotNil)
 
 	//
 	// Cargo is incorrectly unloaded in Tokyo
 	//
 
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 5), id, shipping.V100.VoyageNumber, shipping.JNTKO, shipping.Unload)
+	err = handlingEventService.RegisterHandlingEvent(context.Background(), terminal(shipping.JNTKO), toDate(2009, time.March, 5), id, shipping.V100.VoyageNumber, shipping.JNTKO, shipping.Unload)
 	chk.Check(err, IsNil)
 
 	c, err = cargoRepository.Find(id)
@@ -162,7 +172,7 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	chk.Check(c.Delivery.NextExpectedActivity, Equals, shipping.HandlingActivity{})
 
 	// Repeat procedure of selecting one out of a number of possible routes satisfying the route spec
-	newItineraries := bookingService.RequestPossibleRoutesForCargo(id)
+	newItineraries := bookingService.RequestPossibleRoutesForCargo(context.Background(), id).Itineraries
 	newItinerary := selectPreferredItinerary(newItineraries)
 
 	c.AssignToRoute(newItinerary)
@@ -176,7 +186,7 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	//
 
 	// Load in Tokyo
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 8), id, shipping.V300.VoyageNumber, shipping.JNTKO, shipping.Load)
+	err = handlingEventService.RegisterHandlingEvent(context.Background(), terminal(shipping.JNTKO), toDate(2009, time.March, 8), id, shipping.V300.VoyageNumber, shipping.JNTKO, shipping.Load)
 	chk.Check(err, IsNil)
 
 	c, err = cargoRepository.Find(id)
@@ -188,7 +198,7 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	chk.Check(c.Delivery.NextExpectedActivity, Equals, shipping.HandlingActivity{Type: shipping.Unload, Location: shipping.DEHAM, VoyageNumber: shipping.V300.VoyageNumber})
 
 	// Unload in Hamburg
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 12), id, shipping.V300.VoyageNumber, shipping.DEHAM, shipping.Unload)
+	err = handlingEventService.RegisterHandlingEvent(context.Background(), terminal(shipping.DEHAM), toDate(2009, time.March, 12), id, shipping.V300.VoyageNumber, shipping.DEHAM, shipping.Unload)
 	chk.Check(err, IsNil)
 
 	c, err = cargoRepository.Find(id)
@@ -200,7 +210,7 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	chk.Check(c.Delivery.NextExpectedActivity, Equals, shipping.HandlingActivity{Type: shipping.Load, Location: shipping.DEHAM, VoyageNumber: shipping.V400.VoyageNumber})
 
 	// Load in Hamburg
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 14), id, shipping.V400.VoyageNumber, shipping.DEHAM, shipping.Load)
+	err = handlingEventService.RegisterHandlingEvent(context.Background(), terminal(shipping.DEHAM), toDate(2009, time.March, 14), id, shipping.V400.VoyageNumber, shipping.DEHAM, shipping.Load)
 	chk.Check(err, IsNil)
 
 	c, err = cargoRepository.Find(id)
@@ -212,7 +222,7 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	chk.Check(c.Delivery.NextExpectedActivity, Equals, shipping.HandlingActivity{Type: shipping.Unload, Location: shipping.SESTO, VoyageNumber: shipping.V400.VoyageNumber})
 
 	// Unload in Stockholm
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 15), id, shipping.V400.VoyageNumber, shipping.SESTO, shipping.Unload)
+	err = handlingEventService.RegisterHandlingEvent(context.Background(), terminal(shipping.SESTO), toDate(2009, time.March, 15), id, shipping.V400.VoyageNumber, shipping.SESTO, shipping.Unload)
 	chk.Check(err, IsNil)
 
 	c, err = cargoRepository.Find(id)
@@ -224,7 +234,7 @@ func (s *S) TestCargoFromHongkongToStockholm(chk *C) {
 	chk.Check(c.Delivery.NextExpectedActivity, Equals, shipping.HandlingActivity{Type: shipping.Claim, Location: shipping.SESTO})
 
 	// Finally, cargo is claimed in Stockholm. This ends the cargo lifecycle from our perspective.
-	err = handlingEventService.RegisterHandlingEvent(toDate(2009, time.March, 16), id, shipping.V400.VoyageNumber, shipping.SESTO, shipping.Claim)
+	err = handlingEventService.RegisterHandlingEvent(context.Background(), terminal(shipping.SESTO), toDate(2009, time.March, 16), id, shipping.V400.VoyageNumber, shipping.SESTO, shipping.Claim)
 	chk.Check(err, IsNil)
 
 	c, _ = cargoRepository.Find(id)
@@ -271,16 +281,19 @@ type stubHandlingEventHandler struct {
 	InspectionService inspection.Service
 }
 
-func (h *stubHandlingEventHandler) CargoWasHandled(event shipping.HandlingEvent) {
-	h.InspectionService.InspectCargo(event.TrackingID)
+func (h *stubHandlingEventHandler) CargoWasHandled(ctx context.Context, event shipping.HandlingEvent) {
+	h.InspectionService.InspectCargo(ctx, event.TrackingID)
 }
 
 // Stub CargoEventHandler
 type stubCargoEventHandler struct {
 }
 
-func (h *stubCargoEventHandler) CargoWasMisdirected(c *shipping.Cargo) {
+func (h *stubCargoEventHandler) CargoWasMisdirected(ctx context.Context, c *shipping.Cargo) {
+}
+
+func (h *stubCargoEventHandler) CargoHasArrived(ctx context.Context, c *shipping.Cargo) {
 }
 
-func (h *stubCargoEventHandler) CargoHasArrived(c *shipping.Cargo) {
+func (h *stubCargoEventHandler) CargoDeadlineAtRisk(ctx context.Context, c *shipping.Cargo, slip time.Duration) {
 }
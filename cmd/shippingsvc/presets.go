@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// preset configures the application for an environment, as the values of
+// its flags.
+type preset struct {
+	// flags holds the values of the flags, by name.
+	flags map[string]string
+
+	// required lists the flags that must not be empty, such as the secrets
+	// authenticating carriers and customers.
+	required []string
+}
+
+// presets are the environments the application may be started in with the
+// -env flag.
+var presets = map[string]preset{
+	// dev runs on its own: in memory, routing on the voyages of the
+	// fixtures in place of the routing service, with carriers confirming
+	// every leg booked and the web interface served for demos.
+	"dev": {
+		flags: map[string]string{
+			"inmem":         "true",
+			"routing.local": "true",
+			"carrier.mock":  "true",
+			"fixtures":      "world",
+			"webui":         "true",
+		},
+	},
+
+	// mongo-debug runs against MongoDB and the routing service, logging
+	// every repository call. It is no staging environment: there is no
+	// tracing to wire yet.
+	"mongo-debug": {
+		flags: map[string]string{
+			"inmem":            "false",
+			"routing.local":    "false",
+			"carrier.mock":     "true",
+			"repository.debug": "true",
+		},
+	},
+
+	// mongo-secure runs against MongoDB and the routing service, with
+	// carriers confirming legs themselves. It refuses to start without the
+	// secrets authenticating carriers, terminal administrators, customers
+	// and inbound mail, or the keys encrypting personal data. It is no
+	// production environment: there are no PostgreSQL repositories, Kafka
+	// publisher or authentication service to wire yet.
+	"mongo-secure": {
+		flags: map[string]string{
+			"inmem":         "false",
+			"routing.local": "false",
+			"carrier.mock":  "false",
+			"chaos":         "false",
+		},
+		required: []string{"carrier.token", "handling.token", "portal.secret", "inbound.token", "pii.keys"},
+	},
+}
+
+// presetNames returns the names of the presets, sorted.
+func presetNames() []string {
+	var names []string
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyPreset sets the flags of a parsed flag set to the values of a preset.
+// Flags given on the command line take precedence. An empty name applies no
+// preset.
+func applyPreset(fs *flag.FlagSet, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	p, ok := presets[name]
+	if !ok {
+		return fmt.Errorf("unknown environment %q, want one of %s", name, strings.Join(presetNames(), ", "))
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	for k, v := range p.flags {
+		if explicit[k] {
+			continue
+		}
+		if err := fs.Set(k, v); err != nil {
+			return fmt.Errorf("environment %s: %v", name, err)
+		}
+	}
+
+	for _, k := range p.required {
+		f := fs.Lookup(k)
+		if f == nil || f.Value.String() == "" {
+			return fmt.Errorf("environment %s requires -%s", name, k)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+)
+
+func newFlagSet() (*flag.FlagSet, *config) {
+	fs := flag.NewFlagSet("shippingsvc", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	return fs, registerFlags(fs)
+}
+
+func (s *S) TestApplyPreset(chk *C) {
+	fs, cfg := newFlagSet()
+
+	chk.Assert(fs.Parse([]string{"-fixtures", "sample"}), IsNil)
+	chk.Assert(applyPreset(fs, "dev"), IsNil)
+
+	chk.Check(*cfg.inmemory, Equals, true)
+	chk.Check(*cfg.localRouting, Equals, true)
+	chk.Check(*cfg.carrierMock, Equals, true)
+	chk.Check(*cfg.webUI, Equals, true)
+
+	// Flags given on the command line take precedence.
+	chk.Check(*cfg.datasetName, Equals, "sample")
+
+	chk.Check(applyPreset(fs, "mongo-secure"), ErrorMatches, "environment mongo-secure requires -.*")
+	chk.Check(applyPreset(fs, "atlantis"), ErrorMatches, "unknown environment .*")
+}
+
+func (s *S) TestPresetFlags(chk *C) {
+	fs, _ := newFlagSet()
+
+	for name, p := range presets {
+		for k := range p.flags {
+			chk.Check(fs.Lookup(k), NotNil, Commentf("environment %s sets unknown flag -%s", name, k))
+		}
+		for _, k := range p.required {
+			chk.Check(fs.Lookup(k), NotNil, Commentf("environment %s requires unknown flag -%s", name, k))
+		}
+	}
+}
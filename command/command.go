@@ -0,0 +1,93 @@
+// Package command provides a bus dispatching the commands that change state
+// through a pipeline of middleware, so that behavior cutting across
+// use-cases, such as validation, authorization, auditing and idempotency, is
+// written once rather than in every service method.
+package command
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-kit/kit/endpoint"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// ErrUnknownCommand is returned when dispatching a command no handler has
+// been registered for.
+var ErrUnknownCommand = errors.New("unknown command")
+
+// Command is a request to change state.
+type Command interface {
+	// CommandName identifies the type of command, e.g. book_new_cargo.
+	CommandName() string
+}
+
+// Validator is implemented by commands that can be checked before they are
+// handled.
+type Validator interface {
+	Validate() error
+}
+
+// Audited is implemented by commands that are recorded in the audit log once
+// they have been handled.
+type Audited interface {
+	// AuditEntries returns the entries to record for the response to the
+	// command. Only the actor, action, tracking id and detail are used.
+	AuditEntries(response interface{}) []shipping.AuditEntry
+}
+
+// Bus dispatches commands to the handlers registered for them. Handlers are
+// registered as endpoints receiving the command as request, and the
+// middleware is applied to every one of them.
+type Bus struct {
+	middleware endpoint.Middleware
+	handlers   map[string]endpoint.Endpoint
+}
+
+// NewBus returns a new instance of a Bus. Commands pass through the
+// middleware in the order given.
+func NewBus(mw ...endpoint.Middleware) *Bus {
+	b := &Bus{
+		middleware: func(next endpoint.Endpoint) endpoint.Endpoint { return next },
+		handlers:   make(map[string]endpoint.Endpoint),
+	}
+	if len(mw) > 0 {
+		b.middleware = endpoint.Chain(mw[0], mw[1:]...)
+	}
+	return b
+}
+
+// Handle registers the handler for the named command. Handlers are
+// registered while setting up, before commands are dispatched.
+func (b *Bus) Handle(name string, h endpoint.Endpoint) {
+	b.handlers[name] = b.middleware(h)
+}
+
+// Dispatch passes the command through the middleware to its handler, and
+// returns the response.
+func (b *Bus) Dispatch(ctx context.Context, cmd Command) (interface{}, error) {
+	h, ok := b.handlers[cmd.CommandName()]
+	if !ok {
+		return nil, ErrUnknownCommand
+	}
+	return h(ctx, cmd)
+}
+
+// IdempotencyHeader is the HTTP header clients send an idempotency key in,
+// to have a command that is retried handled only once.
+const IdempotencyHeader = "Idempotency-Key"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the given idempotency key.
+func NewContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key carried by ctx, or
+// an empty string if there is none.
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(contextKey{}).(string)
+	return key
+}
@@ -0,0 +1,121 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errInvalid = errors.New("invalid")
+
+type testCommand struct {
+	valid bool
+}
+
+func (testCommand) CommandName() string { return "test" }
+
+func (c testCommand) Validate() error {
+	if !c.valid {
+		return errInvalid
+	}
+	return nil
+}
+
+func TestDispatch(t *testing.T) {
+	var handled int
+
+	b := NewBus(Validating())
+	b.Handle("test", func(ctx context.Context, request interface{}) (interface{}, error) {
+		handled++
+		return "ok", nil
+	})
+
+	ctx := context.Background()
+
+	if resp, err := b.Dispatch(ctx, testCommand{valid: true}); err != nil || resp != "ok" {
+		t.Errorf("resp, err = %v, %v; want = ok, nil", resp, err)
+	}
+	if _, err := b.Dispatch(ctx, testCommand{}); err != errInvalid {
+		t.Errorf("err = %v; want = %v", err, errInvalid)
+	}
+	if handled != 1 {
+		t.Errorf("handled = %d; want = %d", handled, 1)
+	}
+
+	if _, err := NewBus().Dispatch(ctx, testCommand{valid: true}); err != ErrUnknownCommand {
+		t.Errorf("err = %v; want = %v", err, ErrUnknownCommand)
+	}
+}
+
+func TestAuthorizing(t *testing.T) {
+	errForbidden := errors.New("forbidden")
+
+	b := NewBus(Authorizing(func(ctx context.Context, cmd Command) error {
+		if IdempotencyKeyFromContext(ctx) == "" {
+			return errForbidden
+		}
+		return nil
+	}))
+	b.Handle("test", func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, nil
+	})
+
+	if _, err := b.Dispatch(context.Background(), testCommand{}); err != errForbidden {
+		t.Errorf("err = %v; want = %v", err, errForbidden)
+	}
+	if _, err := b.Dispatch(NewContext(context.Background(), "k"), testCommand{}); err != nil {
+		t.Errorf("err = %v; want none", err)
+	}
+}
+
+func TestIdempotent(t *testing.T) {
+	var (
+		mtx     sync.Mutex
+		handled int
+		fail    = true
+	)
+
+	b := NewBus(Idempotent(time.Hour))
+	b.Handle("test", func(ctx context.Context, request interface{}) (interface{}, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		handled++
+		if fail {
+			return nil, errors.New("failed")
+		}
+		return handled, nil
+	})
+
+	ctx := NewContext(context.Background(), "abc")
+
+	// Failed commands are handled again when retried.
+	if _, err := b.Dispatch(ctx, testCommand{}); err == nil {
+		t.Fatal("err = nil; want an error")
+	}
+	fail = false
+
+	var wg sync.WaitGroup
+	resps := make([]interface{}, 5)
+	for i := range resps {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resps[i], _ = b.Dispatch(ctx, testCommand{})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, resp := range resps {
+		if resp != 2 {
+			t.Errorf("resps[%d] = %v; want the response to the first retry", i, resp)
+		}
+	}
+
+	// Commands without a key are always handled.
+	b.Dispatch(context.Background(), testCommand{})
+	if handled != 3 {
+		t.Errorf("handled = %d; want = %d", handled, 3)
+	}
+}
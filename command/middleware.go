@@ -0,0 +1,99 @@
+package command
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// Validating returns a middleware refusing the commands that fail to
+// validate, before they reach the rest of the pipeline.
+func Validating() endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			if v, ok := request.(Validator); ok {
+				if err := v.Validate(); err != nil {
+					return nil, err
+				}
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// Authorizing returns a middleware refusing the commands that the policy
+// returns an error for.
+func Authorizing(policy func(ctx context.Context, cmd Command) error) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			if err := policy(ctx, request.(Command)); err != nil {
+				return nil, err
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// outcome is the response to a command dispatched with an idempotency key.
+// done is closed once the command has been handled.
+type outcome struct {
+	done     chan struct{}
+	response interface{}
+	err      error
+	handled  time.Time
+}
+
+// Idempotent returns a middleware handling a command dispatched with the
+// same idempotency key more than once within the window only the first
+// time, and responding to the others as to the first. Commands that are
+// retried while the first is being handled wait for it. Commands that fail
+// are forgotten, so that they can be retried. Commands without a key are
+// always handled.
+func Idempotent(window time.Duration) endpoint.Middleware {
+	var (
+		mtx      sync.Mutex
+		outcomes = make(map[string]*outcome)
+	)
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			key := IdempotencyKeyFromContext(ctx)
+			if key == "" {
+				return next(ctx, request)
+			}
+			key = request.(Command).CommandName() + "/" + key
+
+			now := time.Now()
+
+			mtx.Lock()
+			for k, o := range outcomes {
+				if !o.handled.IsZero() && now.Sub(o.handled) > window {
+					delete(outcomes, k)
+				}
+			}
+			if o, ok := outcomes[key]; ok {
+				mtx.Unlock()
+				<-o.done
+				return o.response, o.err
+			}
+			o := &outcome{done: make(chan struct{})}
+			outcomes[key] = o
+			mtx.Unlock()
+
+			o.response, o.err = next(ctx, request)
+
+			mtx.Lock()
+			if o.err != nil {
+				delete(outcomes, key)
+			} else {
+				o.handled = time.Now()
+			}
+			mtx.Unlock()
+			close(o.done)
+
+			return o.response, o.err
+		}
+	}
+}
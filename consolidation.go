@@ -0,0 +1,75 @@
+package shipping
+
+import "errors"
+
+var (
+	// ErrConsolidated is used when a cargo cannot be changed on its own,
+	// since it is consolidated into a master shipment.
+	ErrConsolidated = errors.New("cargo is consolidated")
+
+	// ErrIncompatibleCargo is used when a cargo cannot be consolidated into
+	// a master shipment.
+	ErrIncompatibleCargo = errors.New("incompatible cargo")
+)
+
+// IsConsolidated returns whether the cargo is part of a master shipment.
+func (c *Cargo) IsConsolidated() bool {
+	return c.MasterID != ""
+}
+
+// IsMaster returns whether other cargos are consolidated into the cargo.
+func (c *Cargo) IsMaster() bool {
+	return len(c.Consolidated) > 0
+}
+
+// Consolidate adds a cargo to the master shipment. Only cargos with the same
+// origin, destination and equipment type that have yet to be received can be
+// consolidated.
+// The cargo is routed with the master shipment, whose arrival deadline is
+// tightened to that of the cargo, if earlier.
+func (c *Cargo) Consolidate(member *Cargo) error {
+	switch {
+	case member.TrackingID == c.TrackingID,
+		c.IsConsolidated(),
+		member.IsConsolidated(),
+		member.IsMaster(),
+		member.Origin != c.Origin,
+		member.RouteSpecification.Destination != c.RouteSpecification.Destination,
+		member.Equipment != c.Equipment,
+		member.Delivery.TransportStatus != NotReceived,
+		c.Delivery.TransportStatus != NotReceived:
+		return ErrIncompatibleCargo
+	}
+
+	c.Consolidated = append(c.Consolidated, member.TrackingID)
+	member.MasterID = c.TrackingID
+
+	rs := c.RouteSpecification
+	if d := member.RouteSpecification.ArrivalDeadline; rs.ArrivalDeadline.IsZero() || d.Before(rs.ArrivalDeadline) {
+		rs.ArrivalDeadline = d
+	}
+	c.SpecifyNewRoute(rs)
+
+	member.AssignToRoute(c.Itinerary)
+
+	return nil
+}
+
+// Deconsolidate removes a cargo from the master shipment, leaving it unrouted.
+func (c *Cargo) Deconsolidate(member *Cargo) error {
+	if member.MasterID != c.TrackingID {
+		return ErrIncompatibleCargo
+	}
+
+	for i, id := range c.Consolidated {
+		if id == member.TrackingID {
+			c.Consolidated = append(c.Consolidated[:i], c.Consolidated[i+1:]...)
+			break
+		}
+	}
+
+	member.MasterID = ""
+	member.AssignToRoute(Itinerary{})
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package consolidation
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) Consolidate(ctx context.Context, ids []shipping.TrackingID) (shipping.TrackingID, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "consolidate").Add(1)
+		s.requestLatency.With("method", "consolidate").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Consolidate(ctx, ids)
+}
+
+func (s *instrumentingService) Add(ctx context.Context, master, id shipping.TrackingID) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "add").Add(1)
+		s.requestLatency.With("method", "add").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Add(ctx, master, id)
+}
+
+func (s *instrumentingService) Remove(ctx context.Context, master, id shipping.TrackingID) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "remove").Add(1)
+		s.requestLatency.With("method", "remove").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Remove(ctx, master, id)
+}
+
+func (s *instrumentingService) Split(ctx context.Context, master shipping.TrackingID) ([]shipping.TrackingID, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "split").Add(1)
+		s.requestLatency.With("method", "split").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Split(ctx, master)
+}
@@ -0,0 +1,78 @@
+package consolidation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) Consolidate(ctx context.Context, ids []shipping.TrackingID) (id shipping.TrackingID, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "consolidate",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_ids", fmt.Sprint(ids),
+			"master_id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Consolidate(ctx, ids)
+}
+
+func (s *loggingService) Add(ctx context.Context, master, id shipping.TrackingID) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "add",
+			"request_id", correlation.FromContext(ctx),
+			"master_id", master,
+			"tracking_id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Add(ctx, master, id)
+}
+
+func (s *loggingService) Remove(ctx context.Context, master, id shipping.TrackingID) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "remove",
+			"request_id", correlation.FromContext(ctx),
+			"master_id", master,
+			"tracking_id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Remove(ctx, master, id)
+}
+
+func (s *loggingService) Split(ctx context.Context, master shipping.TrackingID) (released []shipping.TrackingID, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "split",
+			"request_id", correlation.FromContext(ctx),
+			"master_id", master,
+			"released", len(released),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Split(ctx, master)
+}
@@ -0,0 +1,275 @@
+// Package consolidation provides the use-case of consolidating small cargos
+// into master shipments (groupage), which are routed and handled once on
+// behalf of all their cargos.
+package consolidation
+
+import (
+	"context"
+	"errors"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+var (
+	// ErrInvalidArgument is returned when one or more arguments are invalid.
+	ErrInvalidArgument = errors.New("invalid argument")
+
+	// ErrNotMaster is returned when a cargo is not a master shipment.
+	ErrNotMaster = errors.New("cargo is not a master shipment")
+
+	// ErrNotAtDestination is returned when splitting a master shipment that
+	// has not been unloaded at its destination.
+	ErrNotAtDestination = errors.New("master shipment is not at destination")
+)
+
+// Service is the interface that provides consolidation methods.
+type Service interface {
+	// Consolidate books a master shipment for the given cargos, which must
+	// share origin and destination and not yet have been received.
+	Consolidate(ctx context.Context, ids []shipping.TrackingID) (shipping.TrackingID, error)
+
+	// Add consolidates another cargo into a master shipment.
+	Add(ctx context.Context, master, id shipping.TrackingID) error
+
+	// Remove takes a cargo out of a master shipment, leaving it unrouted.
+	Remove(ctx context.Context, master, id shipping.TrackingID) error
+
+	// Split releases the cargos of a master shipment once it has been
+	// unloaded at its destination, so that they can be claimed separately.
+	Split(ctx context.Context, master shipping.TrackingID) ([]shipping.TrackingID, error)
+}
+
+type service struct {
+	cargos shipping.CargoRepository
+}
+
+func (s *service) Consolidate(ctx context.Context, ids []shipping.TrackingID) (shipping.TrackingID, error) {
+	if len(ids) < 2 {
+		return "", ErrInvalidArgument
+	}
+
+	members := make([]*shipping.Cargo, 0, len(ids))
+	for _, id := range ids {
+		c, err := s.cargos.Find(id)
+		if err != nil {
+			return "", err
+		}
+		members = append(members, c)
+	}
+
+	master := shipping.NewCargo(shipping.NextTrackingID(), shipping.RouteSpecification{
+		Origin:          members[0].Origin,
+		Destination:     members[0].RouteSpecification.Destination,
+		ArrivalDeadline: members[0].RouteSpecification.ArrivalDeadline,
+	})
+
+	for _, c := range members {
+		if err := master.Consolidate(c); err != nil {
+			return "", err
+		}
+	}
+
+	for _, c := range members {
+		if err := s.cargos.Store(c); err != nil {
+			return "", err
+		}
+	}
+
+	if err := s.cargos.Store(master); err != nil {
+		return "", err
+	}
+
+	return master.TrackingID, nil
+}
+
+func (s *service) Add(ctx context.Context, masterID, id shipping.TrackingID) error {
+	if masterID == "" || id == "" {
+		return ErrInvalidArgument
+	}
+
+	master, c, err := s.find(masterID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := master.Consolidate(c); err != nil {
+		return err
+	}
+
+	if err := s.cargos.Store(c); err != nil {
+		return err
+	}
+
+	return s.cargos.Store(master)
+}
+
+func (s *service) Remove(ctx context.Context, masterID, id shipping.TrackingID) error {
+	if masterID == "" || id == "" {
+		return ErrInvalidArgument
+	}
+
+	master, c, err := s.find(masterID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := master.Deconsolidate(c); err != nil {
+		return err
+	}
+
+	if err := s.cargos.Store(c); err != nil {
+		return err
+	}
+
+	return s.cargos.Store(master)
+}
+
+func (s *service) Split(ctx context.Context, masterID shipping.TrackingID) ([]shipping.TrackingID, error) {
+	if masterID == "" {
+		return nil, ErrInvalidArgument
+	}
+
+	master, err := s.cargos.Find(masterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !master.IsMaster() {
+		return nil, ErrNotMaster
+	}
+
+	if !master.Delivery.IsUnloadedAtDestination {
+		return nil, ErrNotAtDestination
+	}
+
+	released := master.Consolidated
+
+	// The cargos keep the itinerary and handling history inherited from the
+	// master shipment.
+	for _, id := range released {
+		c, err := s.cargos.Find(id)
+		if err != nil {
+			return nil, err
+		}
+
+		c.MasterID = ""
+
+		if err := s.cargos.Store(c); err != nil {
+			return nil, err
+		}
+	}
+
+	master.Consolidated = nil
+
+	if err := s.cargos.Store(master); err != nil {
+		return nil, err
+	}
+
+	return released, nil
+}
+
+func (s *service) find(masterID, id shipping.TrackingID) (*shipping.Cargo, *shipping.Cargo, error) {
+	master, err := s.cargos.Find(masterID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return master, c, nil
+}
+
+// NewService creates a consolidation service with necessary dependencies.
+func NewService(cargos shipping.CargoRepository) Service {
+	return &service{
+		cargos: cargos,
+	}
+}
+
+// EventHandler passes on the routing and handling of master shipments to
+// the cargos consolidated into them.
+type EventHandler struct {
+	cargos shipping.CargoRepository
+	events shipping.HandlingEventRepository
+	policy shipping.DeliveryPolicy
+}
+
+// CargoWasRouted assigns the itinerary of a master shipment to its cargos.
+func (h *EventHandler) CargoWasRouted(ctx context.Context, master *shipping.Cargo) {
+	for _, id := range master.Consolidated {
+		c, err := h.cargos.Find(id)
+		if err != nil {
+			continue
+		}
+
+		c.AssignToRoute(master.Itinerary)
+		h.cargos.Store(c)
+	}
+}
+
+// CargoWasRolled assigns the itinerary of a master shipment rolled to a
+// later sailing to its cargos.
+func (h *EventHandler) CargoWasRolled(ctx context.Context, master *shipping.Cargo, from shipping.VoyageNumber) {
+	h.CargoWasRouted(ctx, master)
+}
+
+// CargoWasBooked does nothing; cargos are booked before they are
+// consolidated.
+func (h *EventHandler) CargoWasBooked(ctx context.Context, c *shipping.Cargo) {
+}
+
+// CargoWasTransferred does nothing; the cargos of a master shipment keep
+// their own owners.
+func (h *EventHandler) CargoWasTransferred(ctx context.Context, master *shipping.Cargo, from shipping.CustomerID) {
+}
+
+// CargoWasHandled registers a copy of a handling event of a master shipment
+// for each of its cargos.
+func (h *EventHandler) CargoWasHandled(ctx context.Context, e shipping.HandlingEvent) {
+	master, err := h.cargos.Find(e.TrackingID)
+	if err != nil || !master.IsMaster() {
+		return
+	}
+
+	for _, id := range master.Consolidated {
+		c, err := h.cargos.Find(id)
+		if err != nil {
+			continue
+		}
+
+		inherited := e
+		inherited.TrackingID = id
+		h.events.Store(inherited)
+
+		c.DeriveDeliveryProgressWith(h.policy, h.events.QueryHandlingHistory(id))
+		h.cargos.Store(c)
+	}
+}
+
+// EventHandlerOption configures the event handler.
+type EventHandlerOption func(*EventHandler)
+
+// WithDeliveryPolicy sets the policy the handling inherited by the cargos
+// of a master shipment is interpreted with. Defaults to
+// shipping.DefaultDeliveryPolicy.
+func WithDeliveryPolicy(p shipping.DeliveryPolicy) EventHandlerOption {
+	return func(h *EventHandler) {
+		h.policy = p
+	}
+}
+
+// NewEventHandler returns a new instance of a EventHandler.
+func NewEventHandler(cargos shipping.CargoRepository, events shipping.HandlingEventRepository, opts ...EventHandlerOption) *EventHandler {
+	h := &EventHandler{
+		cargos: cargos,
+		events: events,
+		policy: shipping.DefaultDeliveryPolicy,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
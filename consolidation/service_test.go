@@ -0,0 +1,170 @@
+package consolidation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+func newCargoRepository(cs ...*shipping.Cargo) *mock.CargoRepository {
+	m := make(map[shipping.TrackingID]*shipping.Cargo)
+	for _, c := range cs {
+		m[c.TrackingID] = c
+	}
+
+	var cargos mock.CargoRepository
+	cargos.StoreFn = func(c *shipping.Cargo) error {
+		m[c.TrackingID] = c
+		return nil
+	}
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		if c, ok := m[id]; ok {
+			return c, nil
+		}
+		return nil, shipping.ErrUnknownCargo
+	}
+	return &cargos
+}
+
+func TestConsolidate(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		spec = shipping.RouteSpecification{
+			Origin:          shipping.SESTO,
+			Destination:     shipping.AUMEL,
+			ArrivalDeadline: time.Date(2009, time.March, 13, 0, 0, 0, 0, time.UTC),
+		}
+		a = shipping.NewCargo("A", spec)
+		b = shipping.NewCargo("B", spec)
+		c = shipping.NewCargo("C", spec)
+	)
+
+	cargos := newCargoRepository(a, b, c)
+
+	s := NewService(cargos)
+
+	if _, err := s.Consolidate(ctx, []shipping.TrackingID{"A"}); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+
+	id, err := s.Consolidate(ctx, []shipping.TrackingID{"A", "B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	master, err := cargos.Find(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(master.Consolidated) != 2 {
+		t.Errorf("len(master.Consolidated) = %d; want = %d", len(master.Consolidated), 2)
+	}
+	if a.MasterID != id || b.MasterID != id {
+		t.Errorf("MasterID = %s, %s; want = %s", a.MasterID, b.MasterID, id)
+	}
+
+	if err := s.Add(ctx, id, "C"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Remove(ctx, id, "A"); err != nil {
+		t.Fatal(err)
+	}
+	if a.IsConsolidated() {
+		t.Errorf("a.IsConsolidated() = true; want = false")
+	}
+	if len(master.Consolidated) != 2 {
+		t.Errorf("len(master.Consolidated) = %d; want = %d", len(master.Consolidated), 2)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		spec = shipping.RouteSpecification{
+			Origin:      shipping.SESTO,
+			Destination: shipping.AUMEL,
+		}
+		master = shipping.NewCargo("MASTER", spec)
+		a      = shipping.NewCargo("A", spec)
+	)
+
+	master.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+		{VoyageNumber: "V100", LoadLocation: shipping.SESTO, UnloadLocation: shipping.AUMEL},
+	}})
+	master.Consolidate(a)
+
+	s := NewService(newCargoRepository(master, a))
+
+	if _, err := s.Split(ctx, "A"); err != ErrNotMaster {
+		t.Errorf("err = %v; want = %v", err, ErrNotMaster)
+	}
+	if _, err := s.Split(ctx, "MASTER"); err != ErrNotAtDestination {
+		t.Errorf("err = %v; want = %v", err, ErrNotAtDestination)
+	}
+
+	master.Delivery.IsUnloadedAtDestination = true
+
+	released, err := s.Split(ctx, "MASTER")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(released) != 1 || released[0] != "A" {
+		t.Errorf("released = %v; want = %v", released, []shipping.TrackingID{"A"})
+	}
+	if a.IsConsolidated() {
+		t.Errorf("a.IsConsolidated() = true; want = false")
+	}
+	if a.Delivery.RoutingStatus != shipping.Routed {
+		t.Errorf("RoutingStatus = %v; want = %v", a.Delivery.RoutingStatus, shipping.Routed)
+	}
+}
+
+func TestEventHandler(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		spec = shipping.RouteSpecification{
+			Origin:      shipping.SESTO,
+			Destination: shipping.AUMEL,
+		}
+		master = shipping.NewCargo("MASTER", spec)
+		a      = shipping.NewCargo("A", spec)
+	)
+
+	master.Consolidate(a)
+
+	stored := make(map[shipping.TrackingID][]shipping.HandlingEvent)
+
+	var events mock.HandlingEventRepository
+	events.StoreFn = func(e shipping.HandlingEvent) {
+		stored[e.TrackingID] = append(stored[e.TrackingID], e)
+	}
+	events.QueryHandlingHistoryFn = func(id shipping.TrackingID) shipping.HandlingHistory {
+		return shipping.HandlingHistory{HandlingEvents: stored[id]}
+	}
+
+	h := NewEventHandler(newCargoRepository(master, a), &events)
+
+	master.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+		{VoyageNumber: "V100", LoadLocation: shipping.SESTO, UnloadLocation: shipping.AUMEL},
+	}})
+	h.CargoWasRouted(ctx, master)
+
+	if a.Delivery.RoutingStatus != shipping.Routed {
+		t.Errorf("RoutingStatus = %v; want = %v", a.Delivery.RoutingStatus, shipping.Routed)
+	}
+
+	h.CargoWasHandled(ctx, shipping.HandlingEvent{
+		TrackingID: "MASTER",
+		Activity:   shipping.HandlingActivity{Type: shipping.Receive, Location: shipping.SESTO},
+	})
+
+	if len(stored["A"]) != 1 {
+		t.Fatalf("len(stored[A]) = %d; want = %d", len(stored["A"]), 1)
+	}
+	if a.Delivery.TransportStatus != shipping.InPort {
+		t.Errorf("TransportStatus = %v; want = %v", a.Delivery.TransportStatus, shipping.InPort)
+	}
+}
@@ -0,0 +1,83 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConsolidate(t *testing.T) {
+	var (
+		early = time.Date(2009, time.March, 13, 0, 0, 0, 0, time.UTC)
+		late  = early.AddDate(0, 0, 7)
+	)
+
+	master := NewCargo("MASTER", RouteSpecification{Origin: SESTO, Destination: AUMEL, ArrivalDeadline: late})
+	master.AssignToRoute(Itinerary{Legs: []Leg{{LoadLocation: SESTO, UnloadLocation: AUMEL}}})
+
+	a := NewCargo("A", RouteSpecification{Origin: SESTO, Destination: AUMEL, ArrivalDeadline: early})
+
+	if err := master.Consolidate(a); err != nil {
+		t.Fatal(err)
+	}
+
+	if !master.IsMaster() {
+		t.Errorf("master.IsMaster() = false; want = true")
+	}
+	if a.MasterID != master.TrackingID {
+		t.Errorf("a.MasterID = %s; want = %s", a.MasterID, master.TrackingID)
+	}
+	if !master.RouteSpecification.ArrivalDeadline.Equal(early) {
+		t.Errorf("ArrivalDeadline = %v; want = %v", master.RouteSpecification.ArrivalDeadline, early)
+	}
+	if a.Delivery.RoutingStatus != Routed {
+		t.Errorf("a.Delivery.RoutingStatus = %v; want = %v", a.Delivery.RoutingStatus, Routed)
+	}
+
+	if err := master.Consolidate(a); err != ErrIncompatibleCargo {
+		t.Errorf("err = %v; want = %v", err, ErrIncompatibleCargo)
+	}
+
+	other := NewCargo("B", RouteSpecification{Origin: SESTO, Destination: CNHKG})
+	if err := master.Consolidate(other); err != ErrIncompatibleCargo {
+		t.Errorf("err = %v; want = %v", err, ErrIncompatibleCargo)
+	}
+
+	if err := a.Consolidate(NewCargo("C", master.RouteSpecification)); err != ErrIncompatibleCargo {
+		t.Errorf("err = %v; want = %v", err, ErrIncompatibleCargo)
+	}
+
+	reefer := NewCargo("D", RouteSpecification{Origin: SESTO, Destination: AUMEL})
+	reefer.Equipment = Reefer
+	if err := master.Consolidate(reefer); err != ErrIncompatibleCargo {
+		t.Errorf("err = %v; want = %v", err, ErrIncompatibleCargo)
+	}
+}
+
+func TestDeconsolidate(t *testing.T) {
+	master := NewCargo("MASTER", RouteSpecification{Origin: SESTO, Destination: AUMEL})
+	master.AssignToRoute(Itinerary{Legs: []Leg{{LoadLocation: SESTO, UnloadLocation: AUMEL}}})
+
+	a := NewCargo("A", master.RouteSpecification)
+	b := NewCargo("B", master.RouteSpecification)
+
+	if err := master.Deconsolidate(a); err != ErrIncompatibleCargo {
+		t.Errorf("err = %v; want = %v", err, ErrIncompatibleCargo)
+	}
+
+	master.Consolidate(a)
+	master.Consolidate(b)
+
+	if err := master.Deconsolidate(a); err != nil {
+		t.Fatal(err)
+	}
+
+	if a.IsConsolidated() {
+		t.Errorf("a.IsConsolidated() = true; want = false")
+	}
+	if a.Delivery.RoutingStatus != NotRouted {
+		t.Errorf("a.Delivery.RoutingStatus = %v; want = %v", a.Delivery.RoutingStatus, NotRouted)
+	}
+	if len(master.Consolidated) != 1 || master.Consolidated[0] != b.TrackingID {
+		t.Errorf("master.Consolidated = %v; want = %v", master.Consolidated, []TrackingID{b.TrackingID})
+	}
+}
@@ -0,0 +1,54 @@
+// Package correlation provides request-scoped correlation IDs that can be used
+// to trace a request from the transport layer through the services and the
+// domain events it results in.
+package correlation
+
+import (
+	"context"
+
+	"github.com/pborman/uuid"
+)
+
+// Header is the HTTP header used to propagate correlation IDs.
+const Header = "X-Request-ID"
+
+// maxLength caps the length of correlation IDs provided by clients.
+const maxLength = 128
+
+type contextKey struct{}
+
+// NewID generates a new correlation ID.
+func NewID() string {
+	return uuid.New()
+}
+
+// Valid reports whether a correlation ID provided by a client may be used
+// as is. Since correlation IDs end up in logs and in the audit trail, they
+// are limited to letters, digits, '-', '_', '.' and ':', and to 128
+// characters.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a copy of ctx carrying the given correlation ID.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the correlation ID carried by ctx, or an empty string if
+// there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,221 @@
+package shipping
+
+import (
+	"errors"
+	"time"
+)
+
+// CustomerID uniquely identifies a customer.
+type CustomerID string
+
+// Customer is a party booking cargos, who may follow them through the
+// customer portal.
+type Customer struct {
+	ID   CustomerID
+	Name string
+
+	// KeyHash is the hash of the key the customer authenticates with. The
+	// key itself is never stored.
+	KeyHash string
+
+	Notifications NotificationPreferences
+
+	// Digest and Throttling control how often the customer is notified.
+	Digest     NotificationDigest
+	Throttling NotificationThrottling
+
+	Contact ContactDetails
+
+	// Anonymized is when the personal data of the customer was scrubbed, if
+	// it has been.
+	Anonymized time.Time
+}
+
+// ContactDetails holds how a party can be reached. Like the name of a
+// customer, contact details are personal data and may be encrypted at rest.
+type ContactDetails struct {
+	Email string
+	Phone string
+}
+
+// HashCustomerKey returns the hash of a customer key to be stored.
+func HashCustomerKey(key string) string {
+	return hashKey(key)
+}
+
+// Authenticate returns whether the key is the one of the customer.
+func (c Customer) Authenticate(key string) bool {
+	return matchKey(key, c.KeyHash)
+}
+
+// Anonymize scrubs the personal data of the customer, i.e. its name and
+// contact details, at the request of the customer. Its key and notification
+// settings are cleared too, so that it can no longer sign in or be notified.
+// The ID is kept, for the cargos booked by the customer to still count
+// towards statistics.
+func (c *Customer) Anonymize(now time.Time) {
+	c.Name = ""
+	c.Contact = ContactDetails{}
+	c.KeyHash = ""
+	c.Notifications = nil
+	c.Digest = NoDigest
+	c.Throttling = nil
+	if c.Anonymized.IsZero() {
+		c.Anonymized = now
+	}
+}
+
+// IsAnonymized returns whether the personal data of the customer has been
+// scrubbed.
+func (c Customer) IsAnonymized() bool {
+	return !c.Anonymized.IsZero()
+}
+
+// NotificationType identifies a kind of notification sent to customers.
+type NotificationType string
+
+// Notification types.
+const (
+	CargoHandledNotification     NotificationType = "handled"
+	CargoMisdirectedNotification NotificationType = "misdirected"
+	CargoArrivedNotification     NotificationType = "arrived"
+	SLABreachNotification        NotificationType = "sla_breach"
+	DeadlineAtRiskNotification   NotificationType = "deadline_at_risk"
+	SensorAlertNotification      NotificationType = "sensor_alert"
+	CargoRolledNotification      NotificationType = "rolled"
+	CargoTransferredNotification NotificationType = "transferred"
+	DailyBriefingNotification    NotificationType = "daily_briefing"
+)
+
+// NotificationTypes are the known notification types.
+var NotificationTypes = []NotificationType{
+	CargoHandledNotification,
+	CargoMisdirectedNotification,
+	CargoArrivedNotification,
+	SLABreachNotification,
+	DeadlineAtRiskNotification,
+	SensorAlertNotification,
+	CargoRolledNotification,
+	CargoTransferredNotification,
+	DailyBriefingNotification,
+}
+
+// NotificationChannel is a means of reaching a customer, such as "email".
+type NotificationChannel string
+
+// Notification channels.
+const (
+	EmailChannel   NotificationChannel = "email"
+	SMSChannel     NotificationChannel = "sms"
+	WebhookChannel NotificationChannel = "webhook"
+)
+
+// IsValid returns whether the channel is a known one.
+func (c NotificationChannel) IsValid() bool {
+	switch c {
+	case EmailChannel, SMSChannel, WebhookChannel:
+		return true
+	}
+	return false
+}
+
+// NotificationPreferences holds the channels a customer wants to be notified
+// through, per notification type. A type without channels is not notified.
+type NotificationPreferences map[NotificationType][]NotificationChannel
+
+// Unsubscribe stops notifications of a type, or of every type if t is
+// empty.
+func (p NotificationPreferences) Unsubscribe(t NotificationType) {
+	if t == "" {
+		for k := range p {
+			delete(p, k)
+		}
+		return
+	}
+	delete(p, t)
+}
+
+// NotificationDigest is how often a customer is sent the notifications
+// collected since the last digest, rather than one at a time.
+type NotificationDigest string
+
+// Notification digests.
+const (
+	NoDigest     NotificationDigest = ""
+	HourlyDigest NotificationDigest = "hourly"
+	DailyDigest  NotificationDigest = "daily"
+)
+
+// IsValid returns whether the digest is a known one.
+func (d NotificationDigest) IsValid() bool {
+	switch d {
+	case NoDigest, HourlyDigest, DailyDigest:
+		return true
+	}
+	return false
+}
+
+// Interval returns the time covered by a digest, or zero if notifications
+// are sent one at a time.
+func (d NotificationDigest) Interval() time.Duration {
+	switch d {
+	case HourlyDigest:
+		return time.Hour
+	case DailyDigest:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// IsDue returns whether a digest holding notifications since first should be
+// sent. Digests cover whole hours or days, in UTC.
+func (d NotificationDigest) IsDue(first, now time.Time) bool {
+	i := d.Interval()
+	if i == 0 {
+		return true
+	}
+	return now.UTC().Truncate(i).After(first.UTC())
+}
+
+// NotificationThrottling holds the least time between two notifications of a
+// type about the same cargo. Notifications arriving sooner are dropped.
+type NotificationThrottling map[NotificationType]time.Duration
+
+// IsThrottled returns whether a notification of type t, following one sent
+// at last, should be dropped.
+func (th NotificationThrottling) IsThrottled(t NotificationType, last, now time.Time) bool {
+	d, ok := th[t]
+	if !ok || last.IsZero() {
+		return false
+	}
+	return now.Sub(last) < d
+}
+
+// IsValidNotificationType returns whether t is a known notification type.
+func IsValidNotificationType(t NotificationType) bool {
+	for _, nt := range NotificationTypes {
+		if nt == t {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// ErrUnknownCustomer is used when a customer could not be found.
+	ErrUnknownCustomer = errors.New("unknown customer")
+
+	// ErrUnauthorizedCustomer is used when a customer fails to
+	// authenticate.
+	ErrUnauthorizedCustomer = errors.New("unauthorized customer")
+
+	// ErrNotCargoOwner is used when a customer acts on a cargo booked by, or
+	// transferred to, another customer.
+	ErrNotCargoOwner = errors.New("customer does not own cargo")
+)
+
+// CustomerRepository provides access a customer store.
+type CustomerRepository interface {
+	Store(c *Customer) error
+	Find(id CustomerID) (*Customer, error)
+}
@@ -0,0 +1,81 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCustomerAuthenticate(t *testing.T) {
+	c := Customer{ID: "ACME", KeyHash: HashCustomerKey("secret")}
+
+	if !c.Authenticate("secret") {
+		t.Errorf("c.Authenticate(%q) = false; want = true", "secret")
+	}
+	if c.Authenticate("wrong") {
+		t.Errorf("c.Authenticate(%q) = true; want = false", "wrong")
+	}
+}
+
+func TestNotificationPreferencesUnsubscribe(t *testing.T) {
+	p := NotificationPreferences{
+		CargoArrivedNotification: {EmailChannel},
+		CargoHandledNotification: {EmailChannel, SMSChannel},
+	}
+
+	p.Unsubscribe(CargoHandledNotification)
+
+	if _, ok := p[CargoHandledNotification]; ok {
+		t.Errorf("still subscribed to %s", CargoHandledNotification)
+	}
+	if len(p) != 1 {
+		t.Errorf("len(p) = %d; want = %d", len(p), 1)
+	}
+
+	p.Unsubscribe("")
+
+	if len(p) != 0 {
+		t.Errorf("len(p) = %d; want = %d", len(p), 0)
+	}
+}
+
+func TestNotificationDigestIsDue(t *testing.T) {
+	first := time.Date(2016, time.March, 1, 10, 20, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		digest NotificationDigest
+		now    time.Time
+		want   bool
+	}{
+		{NoDigest, first, true},
+		{HourlyDigest, first.Add(30 * time.Minute), false},
+		{HourlyDigest, first.Add(40 * time.Minute), true},
+		{DailyDigest, first.Add(12 * time.Hour), false},
+		{DailyDigest, first.Add(14 * time.Hour), true},
+	} {
+		if got := tt.digest.IsDue(first, tt.now); got != tt.want {
+			t.Errorf("%q.IsDue(%v, %v) = %v; want = %v", tt.digest, first, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestNotificationThrottlingIsThrottled(t *testing.T) {
+	last := time.Date(2016, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	th := NotificationThrottling{CargoHandledNotification: 6 * time.Hour}
+
+	for _, tt := range []struct {
+		typ  NotificationType
+		last time.Time
+		now  time.Time
+		want bool
+	}{
+		{CargoHandledNotification, last, last.Add(time.Hour), true},
+		{CargoHandledNotification, last, last.Add(6 * time.Hour), false},
+		{CargoHandledNotification, time.Time{}, last, false},
+		{CargoArrivedNotification, last, last.Add(time.Hour), false},
+	} {
+		if got := th.IsThrottled(tt.typ, tt.last, tt.now); got != tt.want {
+			t.Errorf("IsThrottled(%s, %v, %v) = %v; want = %v", tt.typ, tt.last, tt.now, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package dashboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) Overview(ctx context.Context) Overview {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "overview").Add(1)
+		s.requestLatency.With("method", "overview").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Overview(ctx)
+}
+
+func (s *instrumentingService) Cargo(ctx context.Context, id shipping.TrackingID) (Cargo, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "cargo").Add(1)
+		s.requestLatency.With("method", "cargo").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Cargo(ctx, id)
+}
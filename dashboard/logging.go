@@ -0,0 +1,47 @@
+package dashboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) Overview(ctx context.Context) (o Overview) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "overview",
+			"request_id", correlation.FromContext(ctx),
+			"cargos", o.Counts.Total,
+			"at_risk", o.Counts.AtRisk,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.Overview(ctx)
+}
+
+func (s *loggingService) Cargo(ctx context.Context, id shipping.TrackingID) (c Cargo, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "cargo",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Cargo(ctx, id)
+}
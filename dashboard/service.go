@@ -0,0 +1,219 @@
+// Package dashboard provides the views of the operator dashboard, each
+// composed server-side from the booking and task services, so that the
+// dashboard loads a page in a single call rather than one per service.
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/booking"
+	"github.com/marcusolsson/goddd/task"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// Service is the interface that provides dashboard methods.
+type Service interface {
+	// Overview returns the state of operations as of now: the number of
+	// cargos by status, the cargos at risk, the arrivals of the day and the
+	// tasks left open.
+	Overview(ctx context.Context) Overview
+
+	// Cargo returns a cargo along with its timeline and the tasks raised
+	// about it.
+	Cargo(ctx context.Context, id shipping.TrackingID) (Cargo, error)
+}
+
+type service struct {
+	booking  booking.Service
+	tasks    task.Service
+	location *time.Location
+	now      func() time.Time
+}
+
+func (s *service) Overview(ctx context.Context) Overview {
+	var (
+		cargos []booking.Cargo
+		tasks  []task.Task
+		wg     sync.WaitGroup
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		cargos = s.booking.Cargos(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		tasks = s.tasks.Tasks(ctx, "", "")
+	}()
+	wg.Wait()
+
+	var (
+		y, m, d  = s.now().In(s.location).Date()
+		today    = time.Date(y, m, d, 0, 0, 0, 0, s.location)
+		tomorrow = today.AddDate(0, 0, 1)
+	)
+
+	result := Overview{
+		Date: today,
+		Counts: Counts{
+			Total:             len(cargos),
+			ByTransportStatus: make(map[string]int),
+		},
+		AtRisk:    []booking.Cargo{},
+		Arrivals:  []booking.Cargo{},
+		OpenTasks: []task.Task{},
+	}
+
+	for _, c := range cargos {
+		result.Counts.ByTransportStatus[c.TransportStatus]++
+
+		if !c.Routed {
+			result.Counts.NotRouted++
+		}
+		if c.Misrouted {
+			result.Counts.Misrouted++
+		}
+
+		if c.TransportStatus == shipping.Claimed.String() {
+			continue
+		}
+		if c.Misrouted || c.DeadlineAtRisk {
+			result.AtRisk = append(result.AtRisk, c)
+		}
+		if t := c.ProjectedArrival; t != nil && !t.Before(today) && t.Before(tomorrow) {
+			result.Arrivals = append(result.Arrivals, c)
+		}
+	}
+	result.Counts.AtRisk = len(result.AtRisk)
+
+	for _, t := range tasks {
+		if t.Status != string(shipping.TaskDone) {
+			result.OpenTasks = append(result.OpenTasks, t)
+		}
+	}
+	result.Counts.OpenTasks = len(result.OpenTasks)
+
+	sort.Slice(result.AtRisk, func(i, j int) bool {
+		return result.AtRisk[i].TrackingID < result.AtRisk[j].TrackingID
+	})
+	sort.SliceStable(result.Arrivals, func(i, j int) bool {
+		return result.Arrivals[i].ProjectedArrival.Before(*result.Arrivals[j].ProjectedArrival)
+	})
+
+	return result
+}
+
+func (s *service) Cargo(ctx context.Context, id shipping.TrackingID) (Cargo, error) {
+	if id == "" {
+		return Cargo{}, ErrInvalidArgument
+	}
+
+	c, err := s.booking.LoadCargo(ctx, id)
+	if err != nil {
+		return Cargo{}, err
+	}
+
+	var (
+		timeline    []booking.TimelineEntry
+		timelineErr error
+		tasks       []task.Task
+		wg          sync.WaitGroup
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		timeline, timelineErr = s.booking.Timeline(ctx, id)
+	}()
+	go func() {
+		defer wg.Done()
+		tasks = s.tasks.Tasks(ctx, "", "")
+	}()
+	wg.Wait()
+
+	if timelineErr != nil {
+		return Cargo{}, timelineErr
+	}
+
+	result := Cargo{
+		Cargo:    c,
+		Timeline: timeline,
+		Tasks:    []task.Task{},
+	}
+	for _, t := range tasks {
+		if t.TrackingID == string(id) {
+			result.Tasks = append(result.Tasks, t)
+		}
+	}
+
+	return result, nil
+}
+
+// Option configures the dashboard service.
+type Option func(*service)
+
+// WithLocation sets the location the days of the dashboard start at
+// midnight in, rather than UTC.
+func WithLocation(loc *time.Location) Option {
+	return func(s *service) {
+		s.location = loc
+	}
+}
+
+// NewService creates a dashboard service composing the views of the booking
+// and task services.
+func NewService(bs booking.Service, ts task.Service, opts ...Option) Service {
+	s := &service{
+		booking:  bs,
+		tasks:    ts,
+		location: time.UTC,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Overview is a read model of the state of operations.
+type Overview struct {
+	// Date is the start of the day that arrivals are expected during.
+	Date   time.Time `json:"date"`
+	Counts Counts    `json:"counts"`
+
+	// AtRisk are the cargos not yet claimed that are misrouted or projected
+	// to arrive after their deadlines.
+	AtRisk []booking.Cargo `json:"at_risk"`
+
+	// Arrivals are the cargos not yet claimed projected to arrive during
+	// the day, earliest first.
+	Arrivals []booking.Cargo `json:"arrivals"`
+
+	// OpenTasks are the tasks not yet done, oldest first.
+	OpenTasks []task.Task `json:"open_tasks"`
+}
+
+// Counts is a read model of the number of cargos by status.
+type Counts struct {
+	Total             int            `json:"total"`
+	ByTransportStatus map[string]int `json:"by_transport_status"`
+	NotRouted         int            `json:"not_routed"`
+	Misrouted         int            `json:"misrouted"`
+	AtRisk            int            `json:"at_risk"`
+	OpenTasks         int            `json:"open_tasks"`
+}
+
+// Cargo is a read model of a cargo along with its timeline and tasks.
+type Cargo struct {
+	Cargo    booking.Cargo           `json:"cargo"`
+	Timeline []booking.TimelineEntry `json:"timeline"`
+	Tasks    []task.Task             `json:"tasks"`
+}
@@ -0,0 +1,136 @@
+package dashboard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/booking"
+	"github.com/marcusolsson/goddd/task"
+)
+
+var now = time.Date(2016, time.March, 8, 9, 0, 0, 0, time.UTC)
+
+// stubBookingService serves a fixed set of cargos.
+type stubBookingService struct {
+	booking.Service
+	cargos []booking.Cargo
+}
+
+func (s *stubBookingService) Cargos(ctx context.Context) []booking.Cargo {
+	return s.cargos
+}
+
+func (s *stubBookingService) LoadCargo(ctx context.Context, id shipping.TrackingID) (booking.Cargo, error) {
+	for _, c := range s.cargos {
+		if c.TrackingID == string(id) {
+			return c, nil
+		}
+	}
+	return booking.Cargo{}, shipping.ErrUnknownCargo
+}
+
+func (s *stubBookingService) Timeline(ctx context.Context, id shipping.TrackingID) ([]booking.TimelineEntry, error) {
+	return []booking.TimelineEntry{{Time: now, Source: "audit", Type: "cargo.booked"}}, nil
+}
+
+// stubTaskService serves a fixed set of tasks.
+type stubTaskService struct {
+	task.Service
+	tasks []task.Task
+}
+
+func (s *stubTaskService) Tasks(ctx context.Context, status shipping.TaskStatus, assignee shipping.UserID) []task.Task {
+	return s.tasks
+}
+
+func newService() Service {
+	at := func(h time.Duration) *time.Time {
+		t := now.Add(h * time.Hour)
+		return &t
+	}
+
+	bs := &stubBookingService{cargos: []booking.Cargo{
+		{TrackingID: "NEW", TransportStatus: "Not received"},
+		{TrackingID: "ARRIVING", TransportStatus: "Onboard carrier", Routed: true, ProjectedArrival: at(10)},
+		{TrackingID: "EARLY", TransportStatus: "Onboard carrier", Routed: true, ProjectedArrival: at(2)},
+		{TrackingID: "LATE", TransportStatus: "Onboard carrier", Routed: true, ProjectedArrival: at(48), DeadlineAtRisk: true},
+		{TrackingID: "LOST", TransportStatus: "In port", Routed: true, Misrouted: true},
+		{TrackingID: "DONE", TransportStatus: "Claimed", Routed: true, Misrouted: true, ProjectedArrival: at(1)},
+	}}
+	ts := &stubTaskService{tasks: []task.Task{
+		{ID: "T1", TrackingID: "LOST", Status: "open"},
+		{ID: "T2", TrackingID: "LATE", Status: "done"},
+		{ID: "T3", Reference: "drop/file.csv", Status: "in-progress"},
+	}}
+
+	s := NewService(bs, ts).(*service)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func trackingIDs(cs []booking.Cargo) []string {
+	var ids []string
+	for _, c := range cs {
+		ids = append(ids, c.TrackingID)
+	}
+	return ids
+}
+
+func TestOverview(t *testing.T) {
+	o := newService().Overview(context.Background())
+
+	want := Counts{
+		Total:             6,
+		ByTransportStatus: map[string]int{"Not received": 1, "Onboard carrier": 3, "In port": 1, "Claimed": 1},
+		NotRouted:         1,
+		Misrouted:         2,
+		AtRisk:            2,
+		OpenTasks:         2,
+	}
+	if o.Counts.Total != want.Total || o.Counts.NotRouted != want.NotRouted || o.Counts.Misrouted != want.Misrouted ||
+		o.Counts.AtRisk != want.AtRisk || o.Counts.OpenTasks != want.OpenTasks {
+		t.Errorf("counts = %+v; want = %+v", o.Counts, want)
+	}
+	for status, n := range want.ByTransportStatus {
+		if o.Counts.ByTransportStatus[status] != n {
+			t.Errorf("%s = %d; want = %d", status, o.Counts.ByTransportStatus[status], n)
+		}
+	}
+
+	if got := trackingIDs(o.AtRisk); len(got) != 2 || got[0] != "LATE" || got[1] != "LOST" {
+		t.Errorf("at risk = %v; want = [LATE LOST]", got)
+	}
+	if got := trackingIDs(o.Arrivals); len(got) != 2 || got[0] != "EARLY" || got[1] != "ARRIVING" {
+		t.Errorf("arrivals = %v; want = [EARLY ARRIVING]", got)
+	}
+	if len(o.OpenTasks) != 2 || o.OpenTasks[0].ID != "T1" || o.OpenTasks[1].ID != "T3" {
+		t.Errorf("open tasks = %+v", o.OpenTasks)
+	}
+	if !o.Date.Equal(time.Date(2016, time.March, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("date = %s", o.Date)
+	}
+}
+
+func TestCargo(t *testing.T) {
+	s := newService()
+
+	if _, err := s.Cargo(context.Background(), ""); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+	if _, err := s.Cargo(context.Background(), "NO_SUCH"); err != shipping.ErrUnknownCargo {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownCargo)
+	}
+
+	c, err := s.Cargo(context.Background(), "LOST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Cargo.TrackingID != "LOST" || len(c.Timeline) != 1 {
+		t.Errorf("cargo = %+v", c)
+	}
+	if len(c.Tasks) != 1 || c.Tasks[0].ID != "T1" {
+		t.Errorf("tasks = %+v; want T1 only", c.Tasks)
+	}
+}
@@ -18,13 +18,74 @@ type Delivery struct {
 	ETA                     time.Time
 	IsMisdirected           bool
 	IsUnloadedAtDestination bool
+
+	// EstimatedUnload is when the voyage the cargo is onboard is estimated
+	// to reach the unload location of the cargo, from the reported position
+	// of the vessel. It is zero unless a position has been reported since
+	// the cargo was loaded.
+	EstimatedUnload time.Time
+
+	// ProvisionalArrival is when the vessel of the voyage the cargo is
+	// onboard entered the geofence of the unload location of the cargo. It
+	// marks the cargo as arrived there until the unload is reported, and is
+	// zero unless the vessel has entered the geofence since the cargo was
+	// loaded.
+	ProvisionalArrival time.Time
 }
 
 // UpdateOnRouting creates a new delivery snapshot to reflect changes in
 // routing, i.e. when the route specification or the itinerary has changed but
 // no additional handling of the cargo has been performed.
 func (d Delivery) UpdateOnRouting(rs RouteSpecification, itinerary Itinerary) Delivery {
-	return newDelivery(d.LastEvent, itinerary, rs)
+	return newDelivery(DefaultDeliveryPolicy, d.LastEvent, itinerary, rs).keepEstimate(d)
+}
+
+// UpdateOnPosition creates a new delivery snapshot with the time the voyage
+// the cargo is onboard is estimated to reach the unload location of the
+// cargo.
+func (d Delivery) UpdateOnPosition(estimatedUnload time.Time) Delivery {
+	d.EstimatedUnload = estimatedUnload
+	return d
+}
+
+// UpdateOnGeofence creates a new delivery snapshot with the time the vessel
+// of the voyage the cargo is onboard entered the geofence of the unload
+// location of the cargo. The first time is kept if the vessel is reported
+// within the geofence again.
+func (d Delivery) UpdateOnGeofence(entered time.Time) Delivery {
+	if d.ProvisionalArrival.IsZero() {
+		d.ProvisionalArrival = entered
+	}
+	return d
+}
+
+// IsProvisionallyArrived returns whether the vessel the cargo is onboard has
+// reached the unload location of the cargo, before the unload is reported.
+func (d Delivery) IsProvisionallyArrived() bool {
+	return d.TransportStatus == OnboardCarrier && !d.ProvisionalArrival.IsZero()
+}
+
+// keepEstimate carries the estimated unload and provisional arrival of a
+// previous snapshot over, as long as the cargo has not been handled since.
+func (d Delivery) keepEstimate(prev Delivery) Delivery {
+	if d.TransportStatus == OnboardCarrier && d.LastEvent.Activity == prev.LastEvent.Activity && d.LastEvent.Completed.Equal(prev.LastEvent.Completed) {
+		d.EstimatedUnload = prev.EstimatedUnload
+		d.ProvisionalArrival = prev.ProvisionalArrival
+	}
+	return d
+}
+
+// CurrentLeg returns the leg of the itinerary the cargo is onboard, if any.
+func (d Delivery) CurrentLeg() (Leg, bool) {
+	if d.TransportStatus != OnboardCarrier {
+		return Leg{}, false
+	}
+	for _, l := range d.Itinerary.Legs {
+		if l.VoyageNumber == d.CurrentVoyage && l.LoadLocation == d.LastEvent.Activity.Location {
+			return l, true
+		}
+	}
+	return Leg{}, false
 }
 
 // IsOnTrack checks if the delivery is on track.
@@ -32,26 +93,107 @@ func (d Delivery) IsOnTrack() bool {
 	return d.RoutingStatus == Routed && !d.IsMisdirected
 }
 
+// ProjectedArrival returns when the cargo is projected to arrive at its
+// destination: the ETA pushed back by how late the voyage the cargo is
+// onboard is estimated to arrive, or else by how late the cargo was handled
+// at its last event, or the actual arrival once unloaded at the
+// destination. It is zero when there is no ETA.
+func (d Delivery) ProjectedArrival() time.Time {
+	if d.IsUnloadedAtDestination {
+		return d.LastEvent.Completed
+	}
+	if d.ETA.IsZero() {
+		return time.Time{}
+	}
+	return d.ETA.Add(d.lateness())
+}
+
+// lateness returns how much later than planned the voyage the cargo is
+// onboard is estimated to unload it, or else how much later than planned the
+// cargo was handled at its last event. Cargo ahead of plan is not projected
+// to arrive early, since the later voyages keep to their schedules.
+func (d Delivery) lateness() time.Duration {
+	if l, ok := d.CurrentLeg(); ok && !d.EstimatedUnload.IsZero() && !l.UnloadTime.IsZero() {
+		if d.EstimatedUnload.After(l.UnloadTime) {
+			return d.EstimatedUnload.Sub(l.UnloadTime)
+		}
+		return 0
+	}
+
+	e := d.LastEvent
+	if e.Completed.IsZero() {
+		return 0
+	}
+
+	from, to := d.Itinerary.ExpectedWindow(e.Activity)
+
+	var planned time.Time
+	switch e.Activity.Type {
+	case Receive, Load:
+		planned = to
+	case Unload:
+		planned = from
+	}
+
+	if planned.IsZero() || !e.Completed.After(planned) {
+		return 0
+	}
+	return e.Completed.Sub(planned)
+}
+
+// DeadlineSlip returns how much the projected arrival exceeds the arrival
+// deadline, or zero if the cargo is projected to arrive in time or either is
+// unknown.
+func (d Delivery) DeadlineSlip() time.Duration {
+	var (
+		projected = d.ProjectedArrival()
+		deadline  = d.RouteSpecification.ArrivalDeadline
+	)
+	if projected.IsZero() || deadline.IsZero() || !projected.After(deadline) {
+		return 0
+	}
+	return projected.Sub(deadline)
+}
+
+// IsDeadlineAtRisk checks if the cargo is projected to arrive after its
+// arrival deadline.
+func (d Delivery) IsDeadlineAtRisk() bool {
+	return d.DeadlineSlip() > 0
+}
+
 // DeriveDeliveryFrom creates a new delivery snapshot based on the complete
 // handling history of a cargo, as well as its route specification and
 // itinerary.
 func DeriveDeliveryFrom(rs RouteSpecification, itinerary Itinerary, history HandlingHistory) Delivery {
+	return DeriveDeliveryWith(DefaultDeliveryPolicy, rs, itinerary, history)
+}
+
+// DeriveDeliveryWith creates a new delivery snapshot like DeriveDeliveryFrom,
+// interpreting the handling of the cargo according to a policy.
+func DeriveDeliveryWith(p DeliveryPolicy, rs RouteSpecification, itinerary Itinerary, history HandlingHistory) Delivery {
 	lastEvent, _ := history.MostRecentlyCompletedEvent()
-	return newDelivery(lastEvent, itinerary, rs)
+	return newDelivery(p, lastEvent, itinerary, rs)
 }
 
 // newDelivery creates a up-to-date delivery based on an handling event,
 // itinerary and a route specification.
-func newDelivery(lastEvent HandlingEvent, itinerary Itinerary, rs RouteSpecification) Delivery {
+func newDelivery(p DeliveryPolicy, lastEvent HandlingEvent, itinerary Itinerary, rs RouteSpecification) Delivery {
 	var (
 		routingStatus           = calculateRoutingStatus(itinerary, rs)
-		transportStatus         = calculateTransportStatus(lastEvent)
+		transportStatus         = NotReceived
 		lastKnownLocation       = calculateLastKnownLocation(lastEvent)
-		isMisdirected           = calculateMisdirectedStatus(lastEvent, itinerary)
-		isUnloadedAtDestination = calculateUnloadedAtDestination(lastEvent, rs)
-		currentVoyage           = calculateCurrentVoyage(transportStatus, lastEvent)
+		isMisdirected           bool
+		isUnloadedAtDestination bool
 	)
 
+	if lastEvent.Activity.Type != NotHandled {
+		transportStatus = p.TransportStatus(lastEvent)
+		isMisdirected = p.IsMisdirected(lastEvent, itinerary)
+		isUnloadedAtDestination = p.IsUnloadedAtDestination(lastEvent, rs)
+	}
+
+	currentVoyage := calculateCurrentVoyage(transportStatus, lastEvent)
+
 	d := Delivery{
 		LastEvent:               lastEvent,
 		Itinerary:               itinerary,
@@ -64,7 +206,7 @@ func newDelivery(lastEvent HandlingEvent, itinerary Itinerary, rs RouteSpecifica
 		CurrentVoyage:           currentVoyage,
 	}
 
-	d.NextExpectedActivity = calculateNextExpectedActivity(d)
+	d.NextExpectedActivity = calculateNextExpectedActivity(p, d)
 	d.ETA = calculateETA(d)
 
 	return d
@@ -77,52 +219,18 @@ func calculateRoutingStatus(itinerary Itinerary, rs RouteSpecification) RoutingS
 		return NotRouted
 	}
 
-	if rs.IsSatisfiedBy(itinerary) {
+	if !itinerary.Disrupted && rs.IsSatisfiedBy(itinerary) {
 		return Routed
 	}
 
 	return Misrouted
 }
 
-func calculateMisdirectedStatus(event HandlingEvent, itinerary Itinerary) bool {
-	if event.Activity.Type == NotHandled {
-		return false
-	}
-
-	return !itinerary.IsExpected(event)
-}
-
-func calculateUnloadedAtDestination(event HandlingEvent, rs RouteSpecification) bool {
-	if event.Activity.Type == NotHandled {
-		return false
-	}
-
-	return event.Activity.Type == Unload && rs.Destination == event.Activity.Location
-}
-
-func calculateTransportStatus(event HandlingEvent) TransportStatus {
-	switch event.Activity.Type {
-	case NotHandled:
-		return NotReceived
-	case Load:
-		return OnboardCarrier
-	case Unload:
-		return InPort
-	case Receive:
-		return InPort
-	case Customs:
-		return InPort
-	case Claim:
-		return Claimed
-	}
-	return Unknown
-}
-
 func calculateLastKnownLocation(event HandlingEvent) UNLocode {
 	return event.Activity.Location
 }
 
-func calculateNextExpectedActivity(d Delivery) HandlingActivity {
+func calculateNextExpectedActivity(p DeliveryPolicy, d Delivery) HandlingActivity {
 	if !d.IsOnTrack() {
 		return HandlingActivity{}
 	}
@@ -131,8 +239,7 @@ func calculateNextExpectedActivity(d Delivery) HandlingActivity {
 	case NotHandled:
 		return HandlingActivity{Type: Receive, Location: d.RouteSpecification.Origin}
 	case Receive:
-		l := d.Itinerary.Legs[0]
-		return HandlingActivity{Type: Load, Location: l.LoadLocation, VoyageNumber: l.VoyageNumber}
+		return expectLoad(d.Itinerary.Legs[0])
 	case Load:
 		for _, l := range d.Itinerary.Legs {
 			if l.LoadLocation == d.LastEvent.Activity.Location {
@@ -143,17 +250,30 @@ func calculateNextExpectedActivity(d Delivery) HandlingActivity {
 		for i, l := range d.Itinerary.Legs {
 			if l.UnloadLocation == d.LastEvent.Activity.Location {
 				if i < len(d.Itinerary.Legs)-1 {
-					return HandlingActivity{Type: Load, Location: d.Itinerary.Legs[i+1].LoadLocation, VoyageNumber: d.Itinerary.Legs[i+1].VoyageNumber}
+					return expectLoad(d.Itinerary.Legs[i+1])
 				}
 
 				return HandlingActivity{Type: Claim, Location: l.UnloadLocation}
 			}
 		}
+	default:
+		if d.LastEvent.Activity.Type.IsCustom() {
+			return p.NextExpectedActivity(d.LastEvent, d.Itinerary)
+		}
 	}
 
 	return HandlingActivity{}
 }
 
+// expectLoad returns the activity of loading the cargo onto a leg, or no
+// activity until the carrier has confirmed the booking of the leg.
+func expectLoad(l Leg) HandlingActivity {
+	if !l.IsConfirmed() {
+		return HandlingActivity{}
+	}
+	return HandlingActivity{Type: Load, Location: l.LoadLocation, VoyageNumber: l.VoyageNumber}
+}
+
 func calculateCurrentVoyage(transportStatus TransportStatus, event HandlingEvent) VoyageNumber {
 	if transportStatus == OnboardCarrier && event.Activity.Type != NotHandled {
 		return event.Activity.VoyageNumber
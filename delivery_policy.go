@@ -0,0 +1,123 @@
+package shipping
+
+// DeliveryPolicy decides how the handling of a cargo is interpreted when
+// deriving its delivery. Operators differ on some of these decisions, such
+// as whether clearing customs before the cargo is unloaded misdirects it.
+//
+// The methods are only called for cargos that have been handled.
+type DeliveryPolicy interface {
+	// IsMisdirected returns whether the last handling event of a cargo is
+	// one its itinerary does not expect.
+	IsMisdirected(last HandlingEvent, itinerary Itinerary) bool
+
+	// IsUnloadedAtDestination returns whether the last handling event of a
+	// cargo leaves it at its final destination.
+	IsUnloadedAtDestination(last HandlingEvent, rs RouteSpecification) bool
+
+	// TransportStatus returns where a cargo is after its last handling
+	// event.
+	TransportStatus(last HandlingEvent) TransportStatus
+
+	// NextExpectedActivity returns the activity expected to follow the last
+	// handling event of a cargo on track, if that event is a custom
+	// activity. The standard activities are followed as the itinerary
+	// prescribes.
+	NextExpectedActivity(last HandlingEvent, itinerary Itinerary) HandlingActivity
+}
+
+// DefaultDeliveryPolicy is the policy deliveries are derived with unless
+// another is given.
+var DefaultDeliveryPolicy DeliveryPolicy = StandardDeliveryPolicy{}
+
+// StandardDeliveryPolicy interprets handling as expected by the itinerary
+// of a cargo. Its zero value is the default policy; the fields are the
+// points where operators commonly differ.
+type StandardDeliveryPolicy struct {
+	// StrictCustoms misdirects a cargo clearing customs anywhere but where
+	// its itinerary unloads it, e.g. at its origin before it has been
+	// loaded. By default, customs may be cleared anywhere.
+	StrictCustoms bool
+
+	// UnconfirmedLoads accepts a cargo loaded onto a leg whose booking the
+	// carrier has yet to confirm. By default, such a load misdirects it.
+	UnconfirmedLoads bool
+
+	// ArrivalOnCustoms has a cargo arrive at its destination once it has
+	// cleared customs there, rather than when it is unloaded there.
+	ArrivalOnCustoms bool
+
+	// Activities are the custom activities cargos may be handled with, such
+	// as inspections. Custom activities not defined here leave the
+	// transport status unknown and are expected anywhere.
+	Activities ActivityTypes
+}
+
+// IsMisdirected implements DeliveryPolicy.
+func (p StandardDeliveryPolicy) IsMisdirected(last HandlingEvent, itinerary Itinerary) bool {
+	if itinerary.IsEmpty() {
+		return false
+	}
+
+	switch last.Activity.Type {
+	case Customs:
+		if !p.StrictCustoms {
+			return false
+		}
+		for _, l := range itinerary.Legs {
+			if l.UnloadLocation == last.Activity.Location {
+				return false
+			}
+		}
+		return true
+	case Load:
+		for _, l := range itinerary.Legs {
+			if l.LoadLocation == last.Activity.Location && l.VoyageNumber == last.Activity.VoyageNumber {
+				return !l.IsConfirmed() && !p.UnconfirmedLoads
+			}
+		}
+		return true
+	}
+
+	if at, ok := p.Activities.Find(last.Activity.Type); ok {
+		return !at.isExpected(last.Activity, itinerary)
+	}
+
+	return !itinerary.IsExpected(last)
+}
+
+// IsUnloadedAtDestination implements DeliveryPolicy.
+func (p StandardDeliveryPolicy) IsUnloadedAtDestination(last HandlingEvent, rs RouteSpecification) bool {
+	arrival := Unload
+	if p.ArrivalOnCustoms {
+		arrival = Customs
+	}
+	return last.Activity.Type == arrival && rs.Destination == last.Activity.Location
+}
+
+// TransportStatus implements DeliveryPolicy.
+func (p StandardDeliveryPolicy) TransportStatus(last HandlingEvent) TransportStatus {
+	switch last.Activity.Type {
+	case Load:
+		return OnboardCarrier
+	case Unload:
+		return InPort
+	case Receive:
+		return InPort
+	case Customs:
+		return InPort
+	case Claim:
+		return Claimed
+	}
+	if at, ok := p.Activities.Find(last.Activity.Type); ok {
+		return at.transportStatus()
+	}
+	return Unknown
+}
+
+// NextExpectedActivity implements DeliveryPolicy.
+func (p StandardDeliveryPolicy) NextExpectedActivity(last HandlingEvent, itinerary Itinerary) HandlingActivity {
+	if at, ok := p.Activities.Find(last.Activity.Type); ok {
+		return at.next(last.Activity, itinerary)
+	}
+	return HandlingActivity{}
+}
@@ -0,0 +1,90 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStandardDeliveryPolicy(t *testing.T) {
+	var (
+		rs = RouteSpecification{Origin: CNHKG, Destination: SESTO}
+		i  = Itinerary{Legs: []Leg{
+			NewLeg("V100", CNHKG, DEHAM, time.Time{}, time.Time{}),
+			NewLeg("V200", DEHAM, SESTO, time.Time{}, time.Time{}),
+		}}
+	)
+	i, _ = i.ConfirmLeg("V100", CNHKG, "REF-1")
+
+	// lenient is the default; strict is an operator holding cargos to the
+	// ports their itineraries unload them at, and counting them arrived
+	// only once they have cleared customs there.
+	var (
+		lenient = DefaultDeliveryPolicy
+		strict  = StandardDeliveryPolicy{StrictCustoms: true, ArrivalOnCustoms: true}
+	)
+
+	event := func(typ HandlingEventType, loc UNLocode, v VoyageNumber) HandlingHistory {
+		return HandlingHistory{HandlingEvents: []HandlingEvent{
+			{Activity: HandlingActivity{Type: typ, Location: loc, VoyageNumber: v}},
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		policy  DeliveryPolicy
+		history HandlingHistory
+
+		misdirected bool
+		arrived     bool
+	}{
+		{"customs at origin, lenient", lenient, event(Customs, CNHKG, ""), false, false},
+		{"customs at origin, strict", strict, event(Customs, CNHKG, ""), true, false},
+		{"customs at transshipment, strict", strict, event(Customs, DEHAM, ""), false, false},
+		{"unloaded at destination, lenient", lenient, event(Unload, SESTO, "V200"), false, true},
+		{"unloaded at destination, strict", strict, event(Unload, SESTO, "V200"), false, false},
+		{"customs at destination, lenient", lenient, event(Customs, SESTO, ""), false, false},
+		{"customs at destination, strict", strict, event(Customs, SESTO, ""), false, true},
+		{"unloaded elsewhere, lenient", lenient, event(Unload, NLRTM, "V100"), true, false},
+		{"unloaded elsewhere, strict", strict, event(Unload, NLRTM, "V100"), true, false},
+	}
+
+	for _, tt := range tests {
+		d := DeriveDeliveryWith(tt.policy, rs, i, tt.history)
+
+		if d.IsMisdirected != tt.misdirected {
+			t.Errorf("%s: IsMisdirected = %v; want = %v", tt.name, d.IsMisdirected, tt.misdirected)
+		}
+		if d.IsUnloadedAtDestination != tt.arrived {
+			t.Errorf("%s: IsUnloadedAtDestination = %v; want = %v", tt.name, d.IsUnloadedAtDestination, tt.arrived)
+		}
+	}
+}
+
+func TestStandardDeliveryPolicy_UnconfirmedLoads(t *testing.T) {
+	var (
+		rs = RouteSpecification{Origin: CNHKG, Destination: SESTO}
+		i  = Itinerary{Legs: []Leg{
+			NewLeg("V100", CNHKG, SESTO, time.Time{}, time.Time{}),
+		}}.PendingConfirmation()
+	)
+
+	loaded := HandlingHistory{HandlingEvents: []HandlingEvent{
+		{Activity: HandlingActivity{Type: Load, Location: CNHKG, VoyageNumber: "V100"}},
+	}}
+
+	if d := DeriveDeliveryFrom(rs, i, loaded); !d.IsMisdirected {
+		t.Errorf("load onto unconfirmed leg should misdirect by default")
+	}
+
+	p := StandardDeliveryPolicy{UnconfirmedLoads: true}
+	if d := DeriveDeliveryWith(p, rs, i, loaded); d.IsMisdirected || d.TransportStatus != OnboardCarrier {
+		t.Errorf("d = %+v; want onboard and not misdirected", d)
+	}
+
+	elsewhere := HandlingHistory{HandlingEvents: []HandlingEvent{
+		{Activity: HandlingActivity{Type: Load, Location: CNHKG, VoyageNumber: "V999"}},
+	}}
+	if d := DeriveDeliveryWith(p, rs, i, elsewhere); !d.IsMisdirected {
+		t.Errorf("load onto another voyage should misdirect")
+	}
+}
@@ -0,0 +1,359 @@
+package shipping
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+// scenario is a cargo booked for a route and the handling events of carrying
+// it along that route. Scenarios are generated by testing/quick.
+type scenario struct {
+	Spec      RouteSpecification
+	Itinerary Itinerary
+
+	// Plan holds the events of carrying the cargo from receipt to claim.
+	Plan []HandlingEvent
+}
+
+var scenarioLocations = []UNLocode{SESTO, AUMEL, CNHKG, USNYC, USCHI, JNTKO, DEHAM, NLRTM, FIHEL}
+
+// Generate returns a scenario with an itinerary visiting distinct locations.
+// One in five scenarios is not routed and one in five is misrouted.
+func (scenario) Generate(r *rand.Rand, size int) reflect.Value {
+	var (
+		perm = r.Perm(len(scenarioLocations))
+		n    = 1 + r.Intn(len(scenarioLocations)-1)
+		at   = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(r.Intn(1000)) * time.Hour)
+		legs []Leg
+	)
+
+	next := func() time.Time {
+		at = at.Add(time.Duration(1+r.Intn(72)) * time.Hour)
+		return at
+	}
+
+	for i := 0; i < n; i++ {
+		legs = append(legs, Leg{
+			VoyageNumber:   VoyageNumber(fmt.Sprintf("V%d%03d", i, r.Intn(1000))),
+			LoadLocation:   scenarioLocations[perm[i]],
+			UnloadLocation: scenarioLocations[perm[i+1]],
+			LoadTime:       next(),
+			UnloadTime:     next(),
+		})
+	}
+
+	s := scenario{
+		Spec: RouteSpecification{
+			Origin:          legs[0].LoadLocation,
+			Destination:     legs[n-1].UnloadLocation,
+			ArrivalDeadline: legs[n-1].UnloadTime.Add(time.Duration(r.Intn(240)) * time.Hour),
+		},
+		Itinerary: Itinerary{Legs: legs},
+	}
+
+	event := func(t HandlingEventType, loc UNLocode, v VoyageNumber, completed time.Time) HandlingEvent {
+		return HandlingEvent{
+			TrackingID: "ABC123",
+			Activity:   HandlingActivity{Type: t, Location: loc, VoyageNumber: v},
+			Completed:  completed,
+		}
+	}
+
+	s.Plan = append(s.Plan, event(Receive, legs[0].LoadLocation, "", legs[0].LoadTime.Add(-time.Hour)))
+	for _, l := range legs {
+		s.Plan = append(s.Plan,
+			event(Load, l.LoadLocation, l.VoyageNumber, l.LoadTime),
+			event(Unload, l.UnloadLocation, l.VoyageNumber, l.UnloadTime),
+		)
+	}
+	s.Plan = append(s.Plan, event(Claim, legs[n-1].UnloadLocation, "", legs[n-1].UnloadTime.Add(time.Hour)))
+
+	switch r.Intn(5) {
+	case 0:
+		s.Itinerary = Itinerary{}
+	case 1:
+		s.Spec.Destination = "XXXXX"
+	}
+
+	return reflect.ValueOf(s)
+}
+
+// prefix returns the history after the first k events of the plan.
+func (s scenario) prefix(k int) HandlingHistory {
+	return HandlingHistory{HandlingEvents: s.Plan[:k]}
+}
+
+func (s scenario) routed() bool {
+	return !s.Itinerary.IsEmpty() && s.Spec.Destination != "XXXXX"
+}
+
+var quickConfig = &quick.Config{MaxCount: 500}
+
+func TestDelivery_UnroutedHasNoETA(t *testing.T) {
+	f := func(s scenario) bool {
+		if !s.Itinerary.IsEmpty() {
+			return true
+		}
+		for k := 0; k <= len(s.Plan); k++ {
+			d := DeriveDeliveryFrom(s.Spec, s.Itinerary, s.prefix(k))
+			if d.RoutingStatus != NotRouted || !d.ETA.IsZero() || d.NextExpectedActivity != (HandlingActivity{}) {
+				t.Logf("after %d events: %+v", k, d)
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDelivery_ETA(t *testing.T) {
+	f := func(s scenario) bool {
+		for k := 0; k <= len(s.Plan); k++ {
+			d := DeriveDeliveryFrom(s.Spec, s.Itinerary, s.prefix(k))
+
+			var want time.Time
+			if d.IsOnTrack() {
+				want = s.Itinerary.FinalArrivalTime()
+			}
+			if !d.ETA.Equal(want) {
+				t.Logf("after %d events: ETA = %v; want = %v", k, d.ETA, want)
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDelivery_MisdirectedIffUnexpected(t *testing.T) {
+	types := []HandlingEventType{Receive, Load, Unload, Claim, Customs}
+
+	f := func(s scenario, k uint8, typ uint8, loc uint8, voyage uint8) bool {
+		// Follow the plan for a while, then handle the cargo at random.
+		h := s.prefix(int(k) % (len(s.Plan) + 1))
+
+		e := HandlingEvent{Activity: HandlingActivity{
+			Type:     types[int(typ)%len(types)],
+			Location: scenarioLocations[int(loc)%len(scenarioLocations)],
+		}}
+		if l := s.Plan[int(voyage)%len(s.Plan)]; e.Activity.Type == Load || e.Activity.Type == Unload {
+			e.Activity.VoyageNumber = l.Activity.VoyageNumber
+		}
+
+		h.HandlingEvents = append(h.HandlingEvents, e)
+
+		d := DeriveDeliveryFrom(s.Spec, s.Itinerary, h)
+		if want := !s.Itinerary.IsExpected(e); d.IsMisdirected != want {
+			t.Logf("after %+v: IsMisdirected = %v; want = %v", e.Activity, d.IsMisdirected, want)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(f, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDelivery_FollowingPlan(t *testing.T) {
+	f := func(s scenario) bool {
+		for k := 0; k <= len(s.Plan); k++ {
+			d := DeriveDeliveryFrom(s.Spec, s.Itinerary, s.prefix(k))
+
+			if d.IsMisdirected {
+				t.Logf("after %d events: misdirected", k)
+				return false
+			}
+
+			if !s.routed() {
+				continue
+			}
+
+			var want HandlingActivity
+			if k < len(s.Plan) {
+				want = s.Plan[k].Activity
+			}
+			if d.NextExpectedActivity != want {
+				t.Logf("after %d events: NextExpectedActivity = %+v; want = %+v", k, d.NextExpectedActivity, want)
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDelivery_TransportStatusTransitions(t *testing.T) {
+	allowed := map[TransportStatus][]TransportStatus{
+		NotReceived:    {InPort},
+		InPort:         {OnboardCarrier, Claimed},
+		OnboardCarrier: {InPort},
+		Claimed:        {},
+	}
+
+	f := func(s scenario) bool {
+		prev := DeriveDeliveryFrom(s.Spec, s.Itinerary, s.prefix(0)).TransportStatus
+		if prev != NotReceived {
+			t.Logf("before handling: TransportStatus = %v; want = %v", prev, NotReceived)
+			return false
+		}
+
+		for k := 1; k <= len(s.Plan); k++ {
+			cur := DeriveDeliveryFrom(s.Spec, s.Itinerary, s.prefix(k)).TransportStatus
+
+			ok := false
+			for _, st := range allowed[prev] {
+				ok = ok || st == cur
+			}
+			if !ok {
+				t.Logf("after %d events: %v -> %v", k, prev, cur)
+				return false
+			}
+			prev = cur
+		}
+
+		return prev == Claimed
+	}
+	if err := quick.Check(f, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDelivery_DeadlineSlip(t *testing.T) {
+	var (
+		depart  = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		arrival = depart.Add(10 * 24 * time.Hour)
+	)
+
+	rs := RouteSpecification{Origin: SESTO, Destination: CNHKG, ArrivalDeadline: arrival.Add(24 * time.Hour)}
+	itinerary := Itinerary{Legs: []Leg{NewLeg("V100", SESTO, CNHKG, depart, arrival)}}
+
+	event := func(typ HandlingEventType, loc UNLocode, completed time.Time) HandlingHistory {
+		return HandlingHistory{HandlingEvents: []HandlingEvent{
+			{Activity: HandlingActivity{Type: typ, Location: loc, VoyageNumber: "V100"}, Completed: completed},
+		}}
+	}
+
+	tests := []struct {
+		name      string
+		history   HandlingHistory
+		projected time.Time
+		slip      time.Duration
+	}{
+		{"not received", HandlingHistory{}, arrival, 0},
+		{"loaded early", event(Load, SESTO, depart.Add(-time.Hour)), arrival, 0},
+		{"loaded late", event(Load, SESTO, depart.Add(12*time.Hour)), arrival.Add(12 * time.Hour), 0},
+		{"loaded too late", event(Load, SESTO, depart.Add(36*time.Hour)), arrival.Add(36 * time.Hour), 12 * time.Hour},
+		{"arrived late", event(Unload, CNHKG, arrival.Add(48*time.Hour)), arrival.Add(48 * time.Hour), 24 * time.Hour},
+		{"misdirected", event(Unload, DEHAM, arrival), time.Time{}, 0},
+	}
+
+	for _, tt := range tests {
+		d := DeriveDeliveryFrom(rs, itinerary, tt.history)
+
+		if got := d.ProjectedArrival(); !got.Equal(tt.projected) {
+			t.Errorf("%s: ProjectedArrival() = %v; want = %v", tt.name, got, tt.projected)
+		}
+		if got := d.DeadlineSlip(); got != tt.slip {
+			t.Errorf("%s: DeadlineSlip() = %v; want = %v", tt.name, got, tt.slip)
+		}
+		if got := d.IsDeadlineAtRisk(); got != (tt.slip > 0) {
+			t.Errorf("%s: IsDeadlineAtRisk() = %v; want = %v", tt.name, got, tt.slip > 0)
+		}
+	}
+}
+
+func TestDelivery_UnconfirmedLegNotExpected(t *testing.T) {
+	var (
+		rs = RouteSpecification{Origin: CNHKG, Destination: SESTO}
+		i  = Itinerary{Legs: []Leg{
+			NewLeg("V100", CNHKG, DEHAM, time.Time{}, time.Time{}),
+			NewLeg("V200", DEHAM, SESTO, time.Time{}, time.Time{}),
+		}}.PendingConfirmation()
+	)
+
+	received := HandlingEvent{Activity: HandlingActivity{Type: Receive, Location: CNHKG}}
+	history := HandlingHistory{HandlingEvents: []HandlingEvent{received}}
+
+	d := DeriveDeliveryFrom(rs, i, history)
+	if d.NextExpectedActivity != (HandlingActivity{}) {
+		t.Errorf("NextExpectedActivity = %+v; want none until the leg is confirmed", d.NextExpectedActivity)
+	}
+
+	i, _ = i.ConfirmLeg("V100", CNHKG, "REF-1")
+
+	d = DeriveDeliveryFrom(rs, i, history)
+	if want := (HandlingActivity{Type: Load, Location: CNHKG, VoyageNumber: "V100"}); d.NextExpectedActivity != want {
+		t.Errorf("NextExpectedActivity = %+v; want = %+v", d.NextExpectedActivity, want)
+	}
+}
+
+func TestDelivery_EstimatedUnload(t *testing.T) {
+	var (
+		depart  = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		arrival = depart.Add(10 * 24 * time.Hour)
+	)
+
+	c := NewCargo("ABC", RouteSpecification{Origin: SESTO, Destination: CNHKG, ArrivalDeadline: arrival.Add(24 * time.Hour)})
+	c.AssignToRoute(Itinerary{Legs: []Leg{NewLeg("V100", SESTO, CNHKG, depart, arrival)}})
+
+	loaded := HandlingEvent{Activity: HandlingActivity{Type: Load, Location: SESTO, VoyageNumber: "V100"}, Completed: depart}
+	c.DeriveDeliveryProgress(HandlingHistory{HandlingEvents: []HandlingEvent{loaded}})
+
+	c.Delivery = c.Delivery.UpdateOnPosition(arrival.Add(-time.Hour))
+	if got := c.Delivery.ProjectedArrival(); !got.Equal(arrival) {
+		t.Errorf("ProjectedArrival() = %v; want = %v", got, arrival)
+	}
+
+	c.Delivery = c.Delivery.UpdateOnPosition(arrival.Add(36 * time.Hour))
+	if got := c.Delivery.DeadlineSlip(); got != 12*time.Hour {
+		t.Errorf("DeadlineSlip() = %v; want = %v", got, 12*time.Hour)
+	}
+
+	// The estimate is kept until the cargo is handled again.
+	c.DeriveDeliveryProgress(HandlingHistory{HandlingEvents: []HandlingEvent{loaded}})
+	if c.Delivery.EstimatedUnload.IsZero() {
+		t.Errorf("EstimatedUnload should be kept while onboard")
+	}
+
+	unloaded := HandlingEvent{Activity: HandlingActivity{Type: Unload, Location: CNHKG, VoyageNumber: "V100"}, Completed: arrival}
+	c.DeriveDeliveryProgress(HandlingHistory{HandlingEvents: []HandlingEvent{loaded, unloaded}})
+	if !c.Delivery.EstimatedUnload.IsZero() {
+		t.Errorf("EstimatedUnload = %v; want it cleared once unloaded", c.Delivery.EstimatedUnload)
+	}
+}
+
+func TestDelivery_ProvisionalArrival(t *testing.T) {
+	var (
+		depart  = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		arrival = depart.Add(10 * 24 * time.Hour)
+	)
+
+	c := NewCargo("ABC", RouteSpecification{Origin: SESTO, Destination: CNHKG})
+	c.AssignToRoute(Itinerary{Legs: []Leg{NewLeg("V100", SESTO, CNHKG, depart, arrival)}})
+
+	loaded := HandlingEvent{Activity: HandlingActivity{Type: Load, Location: SESTO, VoyageNumber: "V100"}, Completed: depart}
+	c.DeriveDeliveryProgress(HandlingHistory{HandlingEvents: []HandlingEvent{loaded}})
+
+	c.Delivery = c.Delivery.UpdateOnGeofence(arrival)
+	c.Delivery = c.Delivery.UpdateOnGeofence(arrival.Add(time.Hour))
+	if !c.Delivery.IsProvisionallyArrived() || !c.Delivery.ProvisionalArrival.Equal(arrival) {
+		t.Errorf("ProvisionalArrival = %v; want = %v", c.Delivery.ProvisionalArrival, arrival)
+	}
+
+	// The unload confirms the arrival.
+	unloaded := HandlingEvent{Activity: HandlingActivity{Type: Unload, Location: CNHKG, VoyageNumber: "V100"}, Completed: arrival.Add(2 * time.Hour)}
+	c.DeriveDeliveryProgress(HandlingHistory{HandlingEvents: []HandlingEvent{loaded, unloaded}})
+	if c.Delivery.IsProvisionallyArrived() || !c.Delivery.ProvisionalArrival.IsZero() {
+		t.Errorf("ProvisionalArrival = %v; want it cleared once unloaded", c.Delivery.ProvisionalArrival)
+	}
+}
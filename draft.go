@@ -0,0 +1,73 @@
+package shipping
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pborman/uuid"
+)
+
+// DraftID uniquely identifies a booking draft.
+type DraftID string
+
+// NextDraftID generates a new draft ID.
+func NextDraftID() DraftID {
+	return DraftID(strings.Split(strings.ToUpper(uuid.New()), "-")[0])
+}
+
+// BookingDraft is a booking request saved before it was complete, to be
+// resumed later. A draft is not a cargo, and is given no tracking id until
+// it is booked.
+type BookingDraft struct {
+	ID              DraftID
+	Customer        CustomerID
+	Origin          UNLocode
+	Destination     UNLocode
+	ArrivalDeadline time.Time
+	Equipment       EquipmentType
+	Saved           time.Time
+}
+
+// Missing returns the names of the fields the draft needs before it can be
+// booked, if any.
+func (d BookingDraft) Missing() []string {
+	var result []string
+	if d.Origin == "" {
+		result = append(result, "origin")
+	}
+	if d.Destination == "" {
+		result = append(result, "destination")
+	}
+	if d.ArrivalDeadline.IsZero() {
+		result = append(result, "arrival_deadline")
+	}
+	return result
+}
+
+// IsComplete returns whether the draft holds everything needed to book it.
+func (d BookingDraft) IsComplete() bool {
+	return len(d.Missing()) == 0
+}
+
+var (
+	// ErrUnknownDraft is used when a booking draft could not be found.
+	ErrUnknownDraft = errors.New("unknown draft")
+
+	// ErrIncompleteDraft is used when booking a draft that is missing
+	// required fields.
+	ErrIncompleteDraft = errors.New("draft is incomplete")
+)
+
+// BookingDraftRepository provides access a booking draft store.
+type BookingDraftRepository interface {
+	Store(d *BookingDraft) error
+	Find(id DraftID) (*BookingDraft, error)
+
+	// FindByCustomer returns the drafts saved for a customer, most recently
+	// saved first.
+	FindByCustomer(id CustomerID) []*BookingDraft
+
+	// Remove removes a draft, e.g. once it has been booked.
+	Remove(id DraftID) error
+}
@@ -0,0 +1,25 @@
+package shipping
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBookingDraftMissing(t *testing.T) {
+	d := BookingDraft{Customer: "ACME", Origin: SESTO}
+
+	if want := []string{"destination", "arrival_deadline"}; !reflect.DeepEqual(d.Missing(), want) {
+		t.Errorf("Missing() = %v; want = %v", d.Missing(), want)
+	}
+	if d.IsComplete() {
+		t.Errorf("IsComplete() = true; want = false")
+	}
+
+	d.Destination = DEHAM
+	d.ArrivalDeadline = time.Date(2016, time.March, 24, 23, 0, 0, 0, time.UTC)
+
+	if !d.IsComplete() {
+		t.Errorf("IsComplete() = false; want = true; missing %v", d.Missing())
+	}
+}
@@ -0,0 +1,64 @@
+package shipping
+
+import "errors"
+
+// ErrUnknownDistance is used when the distance between two locations is not
+// known.
+var ErrUnknownDistance = errors.New("unknown distance")
+
+// Distances provides transport distances between locations, in kilometres.
+type Distances interface {
+	Distance(from, to UNLocode) (float64, error)
+}
+
+// EmissionFactors are the greenhouse gas emissions of moving a tonne of cargo
+// a kilometre, in grams of CO2 equivalents, by transport mode.
+type EmissionFactors map[TransportMode]float64
+
+// DefaultEmissionFactors are typical well-to-wheel intensities of
+// containerized freight.
+var DefaultEmissionFactors = EmissionFactors{
+	Sea:  16,
+	Rail: 22,
+	Road: 75,
+	Air:  602,
+}
+
+// Emissions is an estimate of the emissions of moving a tonne of cargo along
+// an itinerary, in kilograms of CO2 equivalents.
+type Emissions struct {
+	Legs  []float64
+	Total float64
+}
+
+// EmissionsCalculator estimates the emissions of itineraries from the
+// distance and transport mode of each leg.
+type EmissionsCalculator struct {
+	Voyages   VoyageRepository
+	Distances Distances
+	Factors   EmissionFactors
+}
+
+// Estimate returns the emissions of an itinerary.
+func (c EmissionsCalculator) Estimate(i Itinerary) (Emissions, error) {
+	e := Emissions{Legs: make([]float64, 0, len(i.Legs))}
+
+	for _, l := range i.Legs {
+		v, err := c.Voyages.Find(l.VoyageNumber)
+		if err != nil {
+			return Emissions{}, err
+		}
+
+		d, err := c.Distances.Distance(l.LoadLocation, l.UnloadLocation)
+		if err != nil {
+			return Emissions{}, err
+		}
+
+		kg := d * c.Factors[v.Mode] / 1000
+
+		e.Legs = append(e.Legs, kg)
+		e.Total += kg
+	}
+
+	return e, nil
+}
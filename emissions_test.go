@@ -0,0 +1,63 @@
+package shipping
+
+import (
+	"math"
+	"testing"
+)
+
+type stubDistances map[UNLocode]float64
+
+func (d stubDistances) Distance(from, to UNLocode) (float64, error) {
+	if km, ok := d[from+to]; ok {
+		return km, nil
+	}
+	return 0, ErrUnknownDistance
+}
+
+type stubVoyageRepository map[VoyageNumber]*Voyage
+
+func (r stubVoyageRepository) Find(n VoyageNumber) (*Voyage, error) {
+	if v, ok := r[n]; ok {
+		return v, nil
+	}
+	return nil, ErrUnknownVoyage
+}
+
+func (r stubVoyageRepository) Store(v *Voyage) error            { r[v.VoyageNumber] = v; return nil }
+func (r stubVoyageRepository) FindAll() []*Voyage               { return nil }
+func (r stubVoyageRepository) FindCallingAt(UNLocode) []*Voyage { return nil }
+
+func TestEstimate(t *testing.T) {
+	c := EmissionsCalculator{
+		Voyages: stubVoyageRepository{
+			"V1": &Voyage{VoyageNumber: "V1", Mode: Sea},
+			"V2": &Voyage{VoyageNumber: "V2", Mode: Rail},
+		},
+		Distances: stubDistances{
+			CNHKG + DEHAM: 10000,
+			DEHAM + SESTO: 500,
+		},
+		Factors: EmissionFactors{Sea: 10, Rail: 20},
+	}
+
+	e, err := c.Estimate(Itinerary{Legs: []Leg{
+		{VoyageNumber: "V1", LoadLocation: CNHKG, UnloadLocation: DEHAM},
+		{VoyageNumber: "V2", LoadLocation: DEHAM, UnloadLocation: SESTO},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(e.Legs) != 2 || e.Legs[0] != 100 || e.Legs[1] != 10 {
+		t.Errorf("e.Legs = %v; want = %v", e.Legs, []float64{100, 10})
+	}
+	if math.Abs(e.Total-110) > 1e-9 {
+		t.Errorf("e.Total = %f; want = %f", e.Total, 110.0)
+	}
+
+	if _, err := c.Estimate(Itinerary{Legs: []Leg{
+		{VoyageNumber: "V1", LoadLocation: SESTO, UnloadLocation: FIHEL},
+	}}); err != ErrUnknownDistance {
+		t.Errorf("err = %v; want = %v", err, ErrUnknownDistance)
+	}
+}
@@ -0,0 +1,105 @@
+package shipping
+
+import (
+	"errors"
+	"strings"
+)
+
+// EquipmentType is the kind of container a cargo is shipped in.
+type EquipmentType int
+
+// Valid equipment types.
+const (
+	Container20 EquipmentType = iota
+	Container40
+	Reefer
+	FlatRack
+)
+
+func (e EquipmentType) String() string {
+	switch e {
+	case Container20:
+		return "20ft"
+	case Container40:
+		return "40ft"
+	case Reefer:
+		return "Reefer"
+	case FlatRack:
+		return "FlatRack"
+	}
+	return ""
+}
+
+// ErrUnknownEquipmentType is used when parsing an unsupported equipment type.
+var ErrUnknownEquipmentType = errors.New("unknown equipment type")
+
+// ParseEquipmentType parses an equipment type name, such as "reefer".
+func ParseEquipmentType(s string) (EquipmentType, error) {
+	for _, e := range []EquipmentType{Container20, Container40, Reefer, FlatRack} {
+		if strings.EqualFold(s, e.String()) {
+			return e, nil
+		}
+	}
+	return Container20, ErrUnknownEquipmentType
+}
+
+// Capacity is the number of units of an equipment type that a voyage can
+// carry.
+type Capacity struct {
+	Equipment EquipmentType
+	Units     int
+}
+
+// ErrCapacityExceeded is used when a voyage has no room left for the
+// equipment of a cargo.
+var ErrCapacityExceeded = errors.New("voyage capacity exceeded")
+
+// CapacityFor returns the number of units of an equipment type the voyage can
+// carry. A voyage without any capacity declared is not limited, in which case
+// false is returned.
+func (v *Voyage) CapacityFor(e EquipmentType) (int, bool) {
+	if len(v.Capacity) == 0 {
+		return 0, false
+	}
+	for _, c := range v.Capacity {
+		if c.Equipment == e {
+			return c.Units, true
+		}
+	}
+	return 0, true
+}
+
+// Utilization counts the units of each equipment type booked on voyages.
+type Utilization map[VoyageNumber]map[EquipmentType]int
+
+// Book counts the cargo against every voyage of its itinerary. Consolidated
+// cargos travel in the equipment of their master shipment and take up no
+// units of their own.
+func (u Utilization) Book(c *Cargo) {
+	u.book(c, false)
+}
+
+// BookConfirmed counts the cargo against the voyages of its itinerary whose
+// legs the carrier has confirmed.
+func (u Utilization) BookConfirmed(c *Cargo) {
+	u.book(c, true)
+}
+
+func (u Utilization) book(c *Cargo, confirmed bool) {
+	if c.IsConsolidated() {
+		return
+	}
+
+	seen := make(map[VoyageNumber]bool)
+	for _, l := range c.Itinerary.Legs {
+		if seen[l.VoyageNumber] || (confirmed && !l.IsConfirmed()) {
+			continue
+		}
+		seen[l.VoyageNumber] = true
+
+		if u[l.VoyageNumber] == nil {
+			u[l.VoyageNumber] = make(map[EquipmentType]int)
+		}
+		u[l.VoyageNumber][c.Equipment]++
+	}
+}
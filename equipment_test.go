@@ -0,0 +1,99 @@
+package shipping
+
+import (
+	"testing"
+)
+
+func TestParseEquipmentType(t *testing.T) {
+	for _, e := range []EquipmentType{Container20, Container40, Reefer, FlatRack} {
+		got, err := ParseEquipmentType(e.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != e {
+			t.Errorf("ParseEquipmentType(%q) = %v; want = %v", e.String(), got, e)
+		}
+	}
+
+	if got, err := ParseEquipmentType("reefer"); err != nil || got != Reefer {
+		t.Errorf("ParseEquipmentType(%q) = %v, %v; want = %v", "reefer", got, err, Reefer)
+	}
+
+	if _, err := ParseEquipmentType("tank"); err != ErrUnknownEquipmentType {
+		t.Errorf("err = %v; want = %v", err, ErrUnknownEquipmentType)
+	}
+}
+
+func TestVoyage_CapacityFor(t *testing.T) {
+	v := &Voyage{VoyageNumber: "V100"}
+
+	if _, ok := v.CapacityFor(Reefer); ok {
+		t.Errorf("voyage without capacity declared should not be limited")
+	}
+
+	v.Capacity = []Capacity{
+		{Equipment: Container20, Units: 200},
+		{Equipment: Reefer, Units: 20},
+	}
+
+	tests := []struct {
+		e     EquipmentType
+		units int
+	}{
+		{Container20, 200},
+		{Reefer, 20},
+		{FlatRack, 0},
+	}
+
+	for _, tt := range tests {
+		units, ok := v.CapacityFor(tt.e)
+		if !ok || units != tt.units {
+			t.Errorf("CapacityFor(%v) = %d, %v; want = %d, true", tt.e, units, ok, tt.units)
+		}
+	}
+}
+
+func TestUtilization_Book(t *testing.T) {
+	itinerary := Itinerary{Legs: []Leg{
+		{VoyageNumber: "V100", LoadLocation: CNHKG, UnloadLocation: DEHAM},
+		{VoyageNumber: "V200", LoadLocation: DEHAM, UnloadLocation: NLRTM},
+		{VoyageNumber: "V100", LoadLocation: NLRTM, UnloadLocation: SESTO},
+	}}
+
+	reefer := &Cargo{TrackingID: "A", Equipment: Reefer, Itinerary: itinerary}
+	dry := &Cargo{TrackingID: "B", Equipment: Container20, Itinerary: itinerary}
+	consolidated := &Cargo{TrackingID: "C", Equipment: Reefer, Itinerary: itinerary, MasterID: "A"}
+	unrouted := &Cargo{TrackingID: "D", Equipment: Reefer}
+
+	u := make(Utilization)
+	for _, c := range []*Cargo{reefer, dry, consolidated, unrouted} {
+		u.Book(c)
+	}
+
+	if got := u["V100"][Reefer]; got != 1 {
+		t.Errorf("V100 reefers = %d; want = %d", got, 1)
+	}
+	if got := u["V200"][Reefer]; got != 1 {
+		t.Errorf("V200 reefers = %d; want = %d", got, 1)
+	}
+	if got := u["V100"][Container20]; got != 1 {
+		t.Errorf("V100 20ft = %d; want = %d", got, 1)
+	}
+}
+
+func TestUtilization_BookConfirmed(t *testing.T) {
+	c := &Cargo{TrackingID: "A", Equipment: Reefer, Itinerary: Itinerary{Legs: []Leg{
+		{VoyageNumber: "V100", LoadLocation: CNHKG, UnloadLocation: DEHAM, Status: LegConfirmed},
+		{VoyageNumber: "V200", LoadLocation: DEHAM, UnloadLocation: NLRTM, Status: LegPending},
+	}}}
+
+	u := make(Utilization)
+	u.BookConfirmed(c)
+
+	if got := u["V100"][Reefer]; got != 1 {
+		t.Errorf("V100 reefers = %d; want = %d", got, 1)
+	}
+	if got := u["V200"][Reefer]; got != 0 {
+		t.Errorf("V200 reefers = %d; want = %d", got, 0)
+	}
+}
@@ -0,0 +1,271 @@
+// Package fixtures provides datasets of locations, voyages and service
+// strings to seed the repositories with, so that demos and load tests can run
+// against a network larger than the built-in sample.
+package fixtures
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+//go:embed data/*.json
+var data embed.FS
+
+// Dataset is a set of locations, voyages and service strings.
+type Dataset struct {
+	Locations      []*shipping.Location
+	Voyages        []*shipping.Voyage
+	ServiceStrings []*shipping.ServiceString
+}
+
+// Seed stores the dataset in the repositories, replacing the locations,
+// voyages and service strings already stored with the same identities.
+func (d Dataset) Seed(locations shipping.LocationRepository, voyages shipping.VoyageRepository, services shipping.ServiceStringRepository) error {
+	for _, l := range d.Locations {
+		if err := locations.Store(l); err != nil {
+			return err
+		}
+	}
+	for _, v := range d.Voyages {
+		if err := voyages.Store(v); err != nil {
+			return err
+		}
+	}
+	for _, s := range d.ServiceStrings {
+		if err := services.Store(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sample returns the sample dataset of the shipping package, which the
+// repositories are seeded with when they are created. Its schedules are
+// anchored to the day the application started.
+func Sample() Dataset {
+	return Dataset{
+		Locations: []*shipping.Location{
+			shipping.Stockholm,
+			shipping.Melbourne,
+			shipping.Hongkong,
+			shipping.Tokyo,
+			shipping.Rotterdam,
+			shipping.Hamburg,
+		},
+		Voyages: []*shipping.Voyage{
+			shipping.V100,
+			shipping.V300,
+			shipping.V400,
+			shipping.V0100S,
+			shipping.V0200T,
+			shipping.V0300A,
+			shipping.V0301S,
+			shipping.V0400S,
+		},
+		ServiceStrings: []*shipping.ServiceString{
+			shipping.TP1,
+			shipping.AE1,
+			shipping.NE1,
+		},
+	}
+}
+
+// sample is the name of the dataset returned by Sample.
+const sample = "sample"
+
+// Names returns the names of the datasets embedded in the application.
+func Names() []string {
+	result := []string{sample}
+
+	entries, _ := data.ReadDir("data")
+	for _, e := range entries {
+		result = append(result, strings.TrimSuffix(e.Name(), ".json"))
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// Open returns a dataset by name, which is either one of the embedded
+// datasets or the path to a file in the format read by Load. The schedules
+// of the voyages begin at start.
+func Open(name string, start time.Time) (Dataset, error) {
+	if name == sample {
+		return Sample(), nil
+	}
+
+	f, err := data.Open("data/" + name + ".json")
+	if err != nil {
+		osf, oserr := os.Open(name)
+		if oserr != nil {
+			return Dataset{}, fmt.Errorf("fixtures: %q is neither an embedded dataset nor a readable file: %v", name, oserr)
+		}
+		defer osf.Close()
+		return Load(osf, start)
+	}
+	defer f.Close()
+
+	return Load(f, start)
+}
+
+// Load reads a dataset from JSON. Voyages are given as service loops: a
+// rotation of port calls, timed in days after the first departure, which is
+// sailed a number of times at a fixed frequency. For example:
+//
+//	{
+//	    "locations": [
+//	        {"unlocode": "CNSHA", "name": "Shanghai", "time_zone": "Asia/Shanghai", "coordinates": [31.23, 121.47]},
+//	        {"unlocode": "NLRTM", "name": "Rotterdam", "time_zone": "Europe/Amsterdam", "coordinates": [51.95, 4.14]}
+//	    ],
+//	    "services": [{
+//	        "code": "AEX",
+//	        "name": "Asia-North Europe Express",
+//	        "trade_lane": "Asia-Europe",
+//	        "frequency": 7,
+//	        "sailings": 8,
+//	        "capacity": {"40ft": 6000, "reefer": 800},
+//	        "rotation": [
+//	            {"port": "CNSHA", "depart": 1},
+//	            {"port": "NLRTM", "arrive": 32, "depart": 33.5},
+//	            {"port": "CNSHA", "arrive": 68}
+//	        ]
+//	    }]
+//	}
+//
+// Each sailing becomes a voyage numbered after the service, such as AEX001,
+// departing frequency days after the one before it, the first departing on
+// the day of start.
+func Load(r io.Reader, start time.Time) (Dataset, error) {
+	var v struct {
+		Locations []struct {
+			UNLocode    shipping.UNLocode `json:"unlocode"`
+			Name        string            `json:"name"`
+			TimeZone    string            `json:"time_zone"`
+			Coordinates [2]float64        `json:"coordinates"`
+		} `json:"locations"`
+		Services []struct {
+			Code      shipping.ServiceCode `json:"code"`
+			Name      string               `json:"name"`
+			TradeLane shipping.TradeLane   `json:"trade_lane"`
+			Frequency float64              `json:"frequency"`
+			Sailings  int                  `json:"sailings"`
+			Capacity  map[string]int       `json:"capacity"`
+			Rotation  []struct {
+				Port   shipping.UNLocode `json:"port"`
+				Arrive float64           `json:"arrive"`
+				Depart float64           `json:"depart"`
+			} `json:"rotation"`
+		} `json:"services"`
+	}
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		return Dataset{}, err
+	}
+
+	var d Dataset
+
+	known := make(map[shipping.UNLocode]bool)
+	for _, l := range v.Locations {
+		if len(l.UNLocode) != 5 {
+			return Dataset{}, fmt.Errorf("fixtures: invalid UN/LOCODE %q", l.UNLocode)
+		}
+		if _, err := time.LoadLocation(l.TimeZone); err != nil {
+			return Dataset{}, fmt.Errorf("fixtures: location %s: %v", l.UNLocode, err)
+		}
+		known[l.UNLocode] = true
+
+		d.Locations = append(d.Locations, &shipping.Location{
+			UNLocode:    l.UNLocode,
+			Name:        l.Name,
+			TimeZone:    l.TimeZone,
+			Coordinates: shipping.Coordinates{Latitude: l.Coordinates[0], Longitude: l.Coordinates[1]},
+		})
+	}
+
+	day := func(t time.Time, days float64) time.Time {
+		return t.Add(time.Duration(days * float64(24*time.Hour)))
+	}
+
+	start = start.UTC().Truncate(24 * time.Hour)
+
+	for _, s := range v.Services {
+		if s.Code == "" {
+			return Dataset{}, fmt.Errorf("fixtures: service without code")
+		}
+		if len(s.Rotation) < 2 {
+			return Dataset{}, fmt.Errorf("fixtures: service %s: rotation needs at least two ports", s.Code)
+		}
+		if s.Sailings < 1 {
+			s.Sailings = 1
+		}
+		if s.Sailings > 1 && s.Frequency <= 0 {
+			return Dataset{}, fmt.Errorf("fixtures: service %s: frequency must be positive", s.Code)
+		}
+
+		var capacity []shipping.Capacity
+		for name, units := range s.Capacity {
+			e, err := shipping.ParseEquipmentType(name)
+			if err != nil {
+				return Dataset{}, fmt.Errorf("fixtures: service %s: %v", s.Code, err)
+			}
+			capacity = append(capacity, shipping.Capacity{Equipment: e, Units: units})
+		}
+		sort.Slice(capacity, func(i, j int) bool {
+			return capacity[i].Equipment < capacity[j].Equipment
+		})
+
+		last := s.Rotation[0].Depart
+		for i, c := range s.Rotation {
+			if !known[c.Port] {
+				return Dataset{}, fmt.Errorf("fixtures: service %s: unknown port %s", s.Code, c.Port)
+			}
+			if i == 0 {
+				continue
+			}
+			if c.Arrive <= last || (i < len(s.Rotation)-1 && c.Depart <= c.Arrive) {
+				return Dataset{}, fmt.Errorf("fixtures: service %s: port call %d at %s is out of order", s.Code, i+1, c.Port)
+			}
+			last = c.Depart
+		}
+
+		ss := &shipping.ServiceString{
+			Code:      s.Code,
+			Name:      s.Name,
+			TradeLane: s.TradeLane,
+		}
+
+		for n := 0; n < s.Sailings; n++ {
+			first := day(start, float64(n)*s.Frequency)
+
+			var movements []shipping.CarrierMovement
+			for i := 1; i < len(s.Rotation); i++ {
+				from, to := s.Rotation[i-1], s.Rotation[i]
+				movements = append(movements, shipping.CarrierMovement{
+					DepartureLocation: from.Port,
+					ArrivalLocation:   to.Port,
+					DepartureTime:     day(first, from.Depart),
+					ArrivalTime:       day(first, to.Arrive),
+				})
+			}
+
+			number := shipping.VoyageNumber(fmt.Sprintf("%s%03d", s.Code, n+1))
+
+			voyage := shipping.NewVoyage(number, shipping.Schedule{CarrierMovements: movements})
+			voyage.Capacity = capacity
+
+			d.Voyages = append(d.Voyages, voyage)
+			ss.Voyages = append(ss.Voyages, number)
+		}
+
+		d.ServiceStrings = append(d.ServiceStrings, ss)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,140 @@
+package fixtures
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/inmem"
+)
+
+func TestLoad(t *testing.T) {
+	start := time.Date(2018, 9, 3, 15, 0, 0, 0, time.UTC)
+
+	d, err := Load(strings.NewReader(`{
+		"locations": [
+			{"unlocode": "CNSHA", "name": "Shanghai", "time_zone": "Asia/Shanghai", "coordinates": [31.23, 121.47]},
+			{"unlocode": "NLRTM", "name": "Rotterdam", "time_zone": "Europe/Amsterdam", "coordinates": [51.95, 4.14]}
+		],
+		"services": [{
+			"code": "AEX",
+			"name": "Asia-North Europe Express",
+			"trade_lane": "Asia-Europe",
+			"frequency": 7,
+			"sailings": 2,
+			"capacity": {"40ft": 6000, "reefer": 800},
+			"rotation": [
+				{"port": "CNSHA", "depart": 1},
+				{"port": "NLRTM", "arrive": 32, "depart": 33.5},
+				{"port": "CNSHA", "arrive": 68}
+			]
+		}]
+	}`), start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(d.Locations) != 2 || d.Locations[1].Coordinates.Longitude != 4.14 {
+		t.Errorf("locations = %+v", d.Locations)
+	}
+
+	if len(d.Voyages) != 2 {
+		t.Fatalf("len(voyages) = %d; want = %d", len(d.Voyages), 2)
+	}
+
+	v := d.Voyages[1]
+	if v.VoyageNumber != "AEX002" {
+		t.Errorf("voyage number = %s; want = %s", v.VoyageNumber, "AEX002")
+	}
+	if len(v.Capacity) != 2 || v.Capacity[0] != (shipping.Capacity{Equipment: shipping.Container40, Units: 6000}) {
+		t.Errorf("capacity = %+v", v.Capacity)
+	}
+
+	ms := v.Schedule.CarrierMovements
+	if len(ms) != 2 {
+		t.Fatalf("len(movements) = %d; want = %d", len(ms), 2)
+	}
+	if want := time.Date(2018, 9, 11, 0, 0, 0, 0, time.UTC); !ms[0].DepartureTime.Equal(want) {
+		t.Errorf("departure = %v; want = %v", ms[0].DepartureTime, want)
+	}
+	if want := time.Date(2018, 10, 13, 12, 0, 0, 0, time.UTC); !ms[1].DepartureTime.Equal(want) {
+		t.Errorf("departure = %v; want = %v", ms[1].DepartureTime, want)
+	}
+	if ms[1].ArrivalLocation != "CNSHA" {
+		t.Errorf("arrival location = %s; want = %s", ms[1].ArrivalLocation, "CNSHA")
+	}
+
+	if len(d.ServiceStrings) != 1 || !d.ServiceStrings[0].Operates("AEX001") || !d.ServiceStrings[0].Operates("AEX002") {
+		t.Errorf("service strings = %+v", d.ServiceStrings)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	locations := `"locations": [
+		{"unlocode": "CNSHA", "time_zone": "Asia/Shanghai"},
+		{"unlocode": "NLRTM", "time_zone": "Europe/Amsterdam"}
+	]`
+
+	for _, in := range []string{
+		`{"locations": [{"unlocode": "SHA", "time_zone": "Asia/Shanghai"}]}`,
+		`{"locations": [{"unlocode": "CNSHA", "time_zone": "Asia/Atlantis"}]}`,
+		`{` + locations + `, "services": [{"code": "X", "rotation": [{"port": "CNSHA"}]}]}`,
+		`{` + locations + `, "services": [{"code": "X", "rotation": [{"port": "CNSHA"}, {"port": "SESTO", "arrive": 2}]}]}`,
+		`{` + locations + `, "services": [{"code": "X", "rotation": [{"port": "CNSHA", "depart": 3}, {"port": "NLRTM", "arrive": 2}]}]}`,
+		`{` + locations + `, "services": [{"code": "X", "sailings": 2, "rotation": [{"port": "CNSHA"}, {"port": "NLRTM", "arrive": 2}]}]}`,
+		`{` + locations + `, "services": [{"code": "X", "capacity": {"53ft": 1}, "rotation": [{"port": "CNSHA"}, {"port": "NLRTM", "arrive": 2}]}]}`,
+	} {
+		if _, err := Load(strings.NewReader(in), time.Now()); err == nil {
+			t.Errorf("Load(%q) should fail", in)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			d, err := Open(name, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(d.Locations) == 0 || len(d.Voyages) == 0 || len(d.ServiceStrings) == 0 {
+				t.Errorf("dataset %s is empty", name)
+			}
+		})
+	}
+
+	if _, err := Open("atlantis", time.Now()); err == nil {
+		t.Errorf("Open should fail for unknown datasets")
+	}
+}
+
+func TestSeed(t *testing.T) {
+	d, err := Open("world", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		locations = inmem.NewLocationRepository()
+		voyages   = inmem.NewVoyageRepository()
+		services  = inmem.NewServiceStringRepository()
+	)
+
+	if err := d.Seed(locations, voyages, services); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := locations.Find("SGSIN"); err != nil {
+		t.Errorf("locations should hold the dataset: %v", err)
+	}
+	if _, err := voyages.Find("AEX001"); err != nil {
+		t.Errorf("voyages should hold the dataset: %v", err)
+	}
+	if _, err := services.Find("AEX"); err != nil {
+		t.Errorf("service strings should hold the dataset: %v", err)
+	}
+	if _, err := voyages.Find(shipping.V100.VoyageNumber); err != nil {
+		t.Errorf("sample voyages should be kept: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+// Package geo provides distance computations between locations, such as the
+// ones needed to estimate emissions or sailing times.
+package geo
+
+import (
+	"math"
+	"sync"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// earthRadius is the mean radius of the Earth in kilometres.
+const earthRadius = 6371.0
+
+// GreatCircle returns the shortest distance over the surface of the Earth
+// between two positions, in kilometres.
+func GreatCircle(a, b shipping.Coordinates) float64 {
+	var (
+		lat1 = a.Latitude * math.Pi / 180
+		lat2 = b.Latitude * math.Pi / 180
+		dlat = lat2 - lat1
+		dlon = (b.Longitude - a.Longitude) * math.Pi / 180
+	)
+
+	h := math.Sin(dlat/2)*math.Sin(dlat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dlon/2)*math.Sin(dlon/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// Path returns the great-circle distance along a sequence of positions.
+func Path(ps ...shipping.Coordinates) float64 {
+	var d float64
+	for i := 1; i < len(ps); i++ {
+		d += GreatCircle(ps[i-1], ps[i])
+	}
+	return d
+}
+
+// Region is an area bounded by latitudes and longitudes.
+type Region struct {
+	South, North float64
+	West, East   float64
+}
+
+// Contains returns whether a position lies within the region.
+func (r Region) Contains(c shipping.Coordinates) bool {
+	return c.Latitude >= r.South && c.Latitude <= r.North &&
+		c.Longitude >= r.West && c.Longitude <= r.East
+}
+
+// SeaLane corrects the distance between ports in two regions, where the
+// great circle between them would cross land, by sailing through waypoints
+// such as canals and straits.
+type SeaLane struct {
+	Name      string
+	From, To  Region
+	Waypoints []shipping.Coordinates
+}
+
+// route returns the waypoints to sail through from a to b, if the lane
+// connects them.
+func (l SeaLane) route(a, b shipping.Coordinates) ([]shipping.Coordinates, bool) {
+	switch {
+	case l.From.Contains(a) && l.To.Contains(b):
+		return l.Waypoints, true
+	case l.To.Contains(a) && l.From.Contains(b):
+		ws := make([]shipping.Coordinates, len(l.Waypoints))
+		for i, w := range l.Waypoints {
+			ws[len(ws)-1-i] = w
+		}
+		return ws, true
+	}
+	return nil, false
+}
+
+// Sample regions.
+var (
+	NorthSea    = Region{South: 48, North: 62, West: -12, East: 10.5}
+	Baltic      = Region{South: 53, North: 66, West: 10.5, East: 32}
+	Europe      = Region{South: 43, North: 72, West: -12, East: 32}
+	EastAsia    = Region{South: -12, North: 50, West: 95, East: 150}
+	USEastCoast = Region{South: 25, North: 47, West: -82, East: -65}
+)
+
+// CommonSeaLanes are the canals and straits of the major east-west trades.
+var CommonSeaLanes = []SeaLane{
+	{
+		Name: "Kiel Canal",
+		From: NorthSea,
+		To:   Baltic,
+		Waypoints: []shipping.Coordinates{
+			{Latitude: 53.89, Longitude: 9.14},
+			{Latitude: 54.37, Longitude: 10.15},
+		},
+	},
+	{
+		Name: "Suez Canal",
+		From: EastAsia,
+		To:   Europe,
+		Waypoints: []shipping.Coordinates{
+			{Latitude: 1.25, Longitude: 103.8},
+			{Latitude: 5.9, Longitude: 80.6},
+			{Latitude: 12.6, Longitude: 43.3},
+			{Latitude: 29.9, Longitude: 32.55},
+			{Latitude: 31.3, Longitude: 32.3},
+			{Latitude: 35.95, Longitude: -5.6},
+			{Latitude: 48.5, Longitude: -5.5},
+		},
+	},
+	{
+		Name: "Panama Canal",
+		From: EastAsia,
+		To:   USEastCoast,
+		Waypoints: []shipping.Coordinates{
+			{Latitude: 8.9, Longitude: -79.5},
+			{Latitude: 9.35, Longitude: -79.9},
+		},
+	},
+}
+
+// Distance returns the sailing distance between two positions, through the
+// first sea lane connecting them, if any.
+func Distance(a, b shipping.Coordinates, lanes []SeaLane) float64 {
+	for _, l := range lanes {
+		if ws, ok := l.route(a, b); ok {
+			ps := append([]shipping.Coordinates{a}, ws...)
+			return Path(append(ps, b)...)
+		}
+	}
+	return GreatCircle(a, b)
+}
+
+type pair struct {
+	from, to shipping.UNLocode
+}
+
+// Matrix provides distances between locations, computed once per pair of
+// locations. It implements shipping.Distances.
+type Matrix struct {
+	locations shipping.LocationRepository
+	lanes     []SeaLane
+
+	mtx       sync.RWMutex
+	distances map[pair]float64
+}
+
+// NewMatrix returns a distance matrix for the given locations, correcting the
+// distances of the given sea lanes. Without sea lanes, distances are plain
+// great-circle distances.
+func NewMatrix(locations shipping.LocationRepository, lanes ...SeaLane) *Matrix {
+	return &Matrix{
+		locations: locations,
+		lanes:     lanes,
+		distances: make(map[pair]float64),
+	}
+}
+
+// Distance returns the distance between two locations, in kilometres.
+func (m *Matrix) Distance(from, to shipping.UNLocode) (float64, error) {
+	key := pair{from, to}
+
+	m.mtx.RLock()
+	d, ok := m.distances[key]
+	m.mtx.RUnlock()
+
+	if ok {
+		return d, nil
+	}
+
+	a, err := m.locations.Find(from)
+	if err != nil {
+		return 0, err
+	}
+	b, err := m.locations.Find(to)
+	if err != nil {
+		return 0, err
+	}
+
+	d = Distance(a.Coordinates, b.Coordinates, m.lanes)
+
+	m.mtx.Lock()
+	m.distances[key] = d
+	m.mtx.Unlock()
+
+	return d, nil
+}
+
+// Length returns the distance covered by an itinerary, in kilometres.
+func Length(d shipping.Distances, i shipping.Itinerary) (float64, error) {
+	var total float64
+	for _, l := range i.Legs {
+		km, err := d.Distance(l.LoadLocation, l.UnloadLocation)
+		if err != nil {
+			return 0, err
+		}
+		total += km
+	}
+	return total, nil
+}
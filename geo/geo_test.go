@@ -0,0 +1,98 @@
+package geo
+
+import (
+	"testing"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+func TestGreatCircle(t *testing.T) {
+	for _, tt := range []struct {
+		a, b     *shipping.Location
+		min, max float64
+	}{
+		{shipping.Hamburg, shipping.Rotterdam, 400, 420},
+		{shipping.Stockholm, shipping.Helsinki, 390, 410},
+		{shipping.Hamburg, shipping.Hamburg, 0, 0},
+	} {
+		d := GreatCircle(tt.a.Coordinates, tt.b.Coordinates)
+		if d < tt.min || d > tt.max {
+			t.Errorf("GreatCircle(%s, %s) = %f; want within [%f, %f]", tt.a.UNLocode, tt.b.UNLocode, d, tt.min, tt.max)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	var (
+		hkg = shipping.Hongkong.Coordinates
+		rtm = shipping.Rotterdam.Coordinates
+		ham = shipping.Hamburg.Coordinates
+		sto = shipping.Stockholm.Coordinates
+		mel = shipping.Melbourne.Coordinates
+	)
+
+	// Hongkong to Rotterdam is roughly 9,300 km as the crow flies, but
+	// around 18,000 km through the Suez Canal.
+	if d := Distance(hkg, rtm, CommonSeaLanes); d < 17000 || d > 19500 {
+		t.Errorf("Distance(CNHKG, NLRTM) = %f; want ~18000", d)
+	}
+	if d := Distance(rtm, hkg, CommonSeaLanes); d < 17000 || d > 19500 {
+		t.Errorf("Distance(NLRTM, CNHKG) = %f; want ~18000", d)
+	}
+	if d := Distance(hkg, rtm, nil); d > 9500 {
+		t.Errorf("Distance(CNHKG, NLRTM) = %f; want ~9300 without sea lanes", d)
+	}
+
+	if Distance(ham, sto, CommonSeaLanes) <= GreatCircle(ham, sto) {
+		t.Errorf("Hamburg to Stockholm should be routed through the Kiel Canal")
+	}
+
+	if Distance(hkg, mel, CommonSeaLanes) != GreatCircle(hkg, mel) {
+		t.Errorf("Hongkong to Melbourne should not be corrected")
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	var locations mock.LocationRepository
+	locations.FindFn = func(l shipping.UNLocode) (*shipping.Location, error) {
+		switch l {
+		case shipping.DEHAM:
+			return shipping.Hamburg, nil
+		case shipping.NLRTM:
+			return shipping.Rotterdam, nil
+		}
+		return nil, shipping.ErrUnknownLocation
+	}
+
+	m := NewMatrix(&locations)
+
+	d, err := m.Distance(shipping.DEHAM, shipping.NLRTM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locations.FindInvoked = false
+
+	if d2, _ := m.Distance(shipping.DEHAM, shipping.NLRTM); d2 != d {
+		t.Errorf("d = %f; want = %f", d2, d)
+	}
+	if locations.FindInvoked {
+		t.Errorf("distances should be computed once")
+	}
+
+	if _, err := m.Distance(shipping.DEHAM, shipping.SESTO); err != shipping.ErrUnknownLocation {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownLocation)
+	}
+
+	n, err := Length(m, shipping.Itinerary{Legs: []shipping.Leg{
+		{LoadLocation: shipping.DEHAM, UnloadLocation: shipping.NLRTM},
+		{LoadLocation: shipping.NLRTM, UnloadLocation: shipping.DEHAM},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2*d {
+		t.Errorf("Length = %f; want = %f", n, 2*d)
+	}
+}
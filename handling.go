@@ -10,6 +10,8 @@ package shipping
 
 import (
 	"errors"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -27,6 +29,18 @@ type HandlingActivity struct {
 type HandlingEvent struct {
 	TrackingID TrackingID
 	Activity   HandlingActivity
+
+	// Completed is when the activity took place, and Registered when it was
+	// reported.
+	Completed  time.Time
+	Registered time.Time
+
+	// Source is the terminal that reported the event.
+	Source TerminalID
+
+	// CorrelationID is the correlation ID of the request that reported the
+	// event, if any.
+	CorrelationID string
 }
 
 // HandlingEventType describes type of a handling event.
@@ -58,9 +72,39 @@ func (t HandlingEventType) String() string {
 		return "Customs"
 	}
 
+	// Custom activities are named by their operator, see ActivityTypes.
+	// Lacking that, they are named by number so that they survive being
+	// reported and parsed back.
+	if t.IsCustom() {
+		return customActivityPrefix + strconv.Itoa(int(t))
+	}
+
 	return ""
 }
 
+const customActivityPrefix = "Activity "
+
+// ErrUnknownHandlingEventType is used when parsing an unsupported handling
+// event type.
+var ErrUnknownHandlingEventType = errors.New("unknown handling event type")
+
+// ParseHandlingEventType parses the name of a handling event type, such as
+// "load". Custom types are parsed by number, such as "activity 101".
+func ParseHandlingEventType(s string) (HandlingEventType, error) {
+	if len(s) > len(customActivityPrefix) && strings.EqualFold(s[:len(customActivityPrefix)], customActivityPrefix) {
+		if n, err := strconv.Atoi(s[len(customActivityPrefix):]); err == nil && HandlingEventType(n).IsCustom() {
+			return HandlingEventType(n), nil
+		}
+		return NotHandled, ErrUnknownHandlingEventType
+	}
+	for _, t := range []HandlingEventType{Load, Unload, Receive, Claim, Customs} {
+		if strings.EqualFold(s, t.String()) {
+			return t, nil
+		}
+	}
+	return NotHandled, ErrUnknownHandlingEventType
+}
+
 // HandlingHistory is the handling history of a cargo.
 type HandlingHistory struct {
 	HandlingEvents []HandlingEvent
@@ -75,10 +119,79 @@ func (h HandlingHistory) MostRecentlyCompletedEvent() (HandlingEvent, error) {
 	return h.HandlingEvents[len(h.HandlingEvents)-1], nil
 }
 
+// ErrInvalidCursor is used when a handling history cursor is malformed.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// HandlingHistoryQuery selects a page of the handling history of a cargo.
+type HandlingHistoryQuery struct {
+	// From and To restrict the events to those completed within [From, To).
+	// A zero value leaves that end of the range open.
+	From time.Time
+	To   time.Time
+
+	// Cursor continues from where a previous page ended. It is empty for the
+	// first page.
+	Cursor string
+
+	// Limit is the maximum number of events in the page. Zero means no limit.
+	Limit int
+
+	// Types restricts the events to those of the given types. Empty means
+	// every type.
+	Types []HandlingEventType
+}
+
+// Includes returns whether the completion time of e is within the time range
+// of the query, and e is of a type asked for.
+func (q HandlingHistoryQuery) Includes(e HandlingEvent) bool {
+	if !q.From.IsZero() && e.Completed.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !e.Completed.Before(q.To) {
+		return false
+	}
+	if len(q.Types) == 0 {
+		return true
+	}
+	for _, t := range q.Types {
+		if e.Activity.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// HandlingHistoryPage is a page of the handling history of a cargo, in the
+// order the events were registered.
+type HandlingHistoryPage struct {
+	HandlingEvents []HandlingEvent
+
+	// NextCursor is used to request the following page. It is empty when
+	// there are no more events.
+	NextCursor string
+}
+
 // HandlingEventRepository provides access a handling event store.
 type HandlingEventRepository interface {
 	Store(e HandlingEvent)
+
+	// QueryHandlingHistory returns the complete handling history of a cargo.
+	// It is meant for deriving delivery progress; views should use
+	// QueryHandlingHistoryPage instead.
 	QueryHandlingHistory(TrackingID) HandlingHistory
+
+	// QueryHandlingHistoryPage returns a page of the handling history of a
+	// cargo.
+	QueryHandlingHistoryPage(TrackingID, HandlingHistoryQuery) (HandlingHistoryPage, error)
+
+	// QueryHandlingHistories returns the handling histories for several
+	// cargos in a single round-trip. Cargos without any handling events are
+	// included with an empty history.
+	QueryHandlingHistories([]TrackingID) map[TrackingID]HandlingHistory
+
+	// ForEach calls fn for every stored handling event. Iteration stops at
+	// the first error returned by fn, which is then returned.
+	ForEach(fn func(HandlingEvent) error) error
 }
 
 // HandlingEventFactory creates handling events.
@@ -86,13 +199,17 @@ type HandlingEventFactory struct {
 	CargoRepository    CargoRepository
 	VoyageRepository   VoyageRepository
 	LocationRepository LocationRepository
+
+	// ImportRestrictions are checked before a cargo clears customs, if any.
+	ImportRestrictions ImportRestrictions
 }
 
 // CreateHandlingEvent creates a validated handling event.
 func (f *HandlingEventFactory) CreateHandlingEvent(registered time.Time, completed time.Time, id TrackingID,
 	voyageNumber VoyageNumber, unLocode UNLocode, eventType HandlingEventType) (HandlingEvent, error) {
 
-	if _, err := f.CargoRepository.Find(id); err != nil {
+	c, err := f.CargoRepository.Find(id)
+	if err != nil {
 		return HandlingEvent{}, err
 	}
 
@@ -107,6 +224,12 @@ func (f *HandlingEventFactory) CreateHandlingEvent(registered time.Time, complet
 		return HandlingEvent{}, err
 	}
 
+	if eventType == Customs {
+		if err := f.ImportRestrictions.Check(unLocode, c.Declaration); err != nil {
+			return HandlingEvent{}, err
+		}
+	}
+
 	return HandlingEvent{
 		TrackingID: id,
 		Activity: HandlingActivity{
@@ -114,5 +237,7 @@ func (f *HandlingEventFactory) CreateHandlingEvent(registered time.Time, complet
 			Location:     unLocode,
 			VoyageNumber: voyageNumber,
 		},
+		Completed:  completed,
+		Registered: registered,
 	}, nil
 }
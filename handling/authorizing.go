@@ -0,0 +1,48 @@
+package handling
+
+import (
+	"context"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type authorizingService struct {
+	authorize Authorizer
+	next      Service
+}
+
+// NewAuthorizingService returns a new instance of an authorizing Service,
+// refusing the calls the authorizer returns an error for. Methods that cannot
+// fail return nothing when refused.
+func NewAuthorizingService(a Authorizer, s Service) Service {
+	return &authorizingService{authorize: a, next: s}
+}
+
+func (s *authorizingService) RegisterHandlingEvent(ctx context.Context, source Credentials, completed time.Time, id shipping.TrackingID, voyageNumber shipping.VoyageNumber, loc shipping.UNLocode, eventType shipping.HandlingEventType) error {
+	if err := s.authorize(ctx, "register_incident"); err != nil {
+		return err
+	}
+	return s.next.RegisterHandlingEvent(ctx, source, completed, id, voyageNumber, loc, eventType)
+}
+
+func (s *authorizingService) StreamHandlingEvents(ctx context.Context, fn func(shipping.HandlingEvent) error) error {
+	if err := s.authorize(ctx, "stream_handling_events"); err != nil {
+		return err
+	}
+	return s.next.StreamHandlingEvents(ctx, fn)
+}
+
+func (s *authorizingService) RegisterTerminal(ctx context.Context, token string, id shipping.TerminalID, name string, loc shipping.UNLocode, key, secret string) error {
+	if err := s.authorize(ctx, "register_terminal"); err != nil {
+		return err
+	}
+	return s.next.RegisterTerminal(ctx, token, id, name, loc, key, secret)
+}
+
+func (s *authorizingService) Terminals(ctx context.Context, token string) ([]Terminal, error) {
+	if err := s.authorize(ctx, "list_terminals"); err != nil {
+		return nil, err
+	}
+	return s.next.Terminals(ctx, token)
+}
@@ -1,6 +1,7 @@
 package handling
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-kit/kit/metrics"
@@ -23,7 +24,7 @@ func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram,
 	}
 }
 
-func (s *instrumentingService) RegisterHandlingEvent(completed time.Time, id shipping.TrackingID, voyageNumber shipping.VoyageNumber,
+func (s *instrumentingService) RegisterHandlingEvent(ctx context.Context, source Credentials, completed time.Time, id shipping.TrackingID, voyageNumber shipping.VoyageNumber,
 	loc shipping.UNLocode, eventType shipping.HandlingEventType) error {
 
 	defer func(begin time.Time) {
@@ -31,5 +32,32 @@ func (s *instrumentingService) RegisterHandlingEvent(completed time.Time, id shi
 		s.requestLatency.With("method", "register_incident").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.next.RegisterHandlingEvent(completed, id, voyageNumber, loc, eventType)
+	return s.next.RegisterHandlingEvent(ctx, source, completed, id, voyageNumber, loc, eventType)
+}
+
+func (s *instrumentingService) StreamHandlingEvents(ctx context.Context, fn func(shipping.HandlingEvent) error) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "stream_handling_events").Add(1)
+		s.requestLatency.With("method", "stream_handling_events").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.StreamHandlingEvents(ctx, fn)
+}
+
+func (s *instrumentingService) RegisterTerminal(ctx context.Context, token string, id shipping.TerminalID, name string, loc shipping.UNLocode, key, secret string) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "register_terminal").Add(1)
+		s.requestLatency.With("method", "register_terminal").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.RegisterTerminal(ctx, token, id, name, loc, key, secret)
+}
+
+func (s *instrumentingService) Terminals(ctx context.Context, token string) ([]Terminal, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "list_terminals").Add(1)
+		s.requestLatency.With("method", "list_terminals").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Terminals(ctx, token)
 }
@@ -1,11 +1,13 @@
 package handling
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-kit/kit/log"
 
 	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
 )
 
 type loggingService struct {
@@ -18,11 +20,13 @@ func NewLoggingService(logger log.Logger, s Service) Service {
 	return &loggingService{logger, s}
 }
 
-func (s *loggingService) RegisterHandlingEvent(completed time.Time, id shipping.TrackingID, voyageNumber shipping.VoyageNumber,
+func (s *loggingService) RegisterHandlingEvent(ctx context.Context, source Credentials, completed time.Time, id shipping.TrackingID, voyageNumber shipping.VoyageNumber,
 	unLocode shipping.UNLocode, eventType shipping.HandlingEventType) (err error) {
 	defer func(begin time.Time) {
 		s.logger.Log(
 			"method", "register_incident",
+			"request_id", correlation.FromContext(ctx),
+			"terminal", source.Terminal,
 			"tracking_id", id,
 			"location", unLocode,
 			"voyage", voyageNumber,
@@ -32,5 +36,44 @@ func (s *loggingService) RegisterHandlingEvent(completed time.Time, id shipping.
 			"err", err,
 		)
 	}(time.Now())
-	return s.next.RegisterHandlingEvent(completed, id, voyageNumber, unLocode, eventType)
+	return s.next.RegisterHandlingEvent(ctx, source, completed, id, voyageNumber, unLocode, eventType)
+}
+
+func (s *loggingService) StreamHandlingEvents(ctx context.Context, fn func(shipping.HandlingEvent) error) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "stream_handling_events",
+			"request_id", correlation.FromContext(ctx),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.StreamHandlingEvents(ctx, fn)
+}
+
+func (s *loggingService) RegisterTerminal(ctx context.Context, token string, id shipping.TerminalID, name string, loc shipping.UNLocode, key, secret string) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "register_terminal",
+			"request_id", correlation.FromContext(ctx),
+			"terminal", id,
+			"location", loc,
+			"signed", secret != "",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.RegisterTerminal(ctx, token, id, name, loc, key, secret)
+}
+
+func (s *loggingService) Terminals(ctx context.Context, token string) (terminals []Terminal, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_terminals",
+			"request_id", correlation.FromContext(ctx),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Terminals(ctx, token)
 }
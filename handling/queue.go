@@ -0,0 +1,294 @@
+package handling
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// ErrBusy is returned when a handling event cannot be queued, because the
+// queue is full. Reporting the event again once the queue has drained will
+// succeed.
+var ErrBusy = errors.New("too many handling events queued, try again later")
+
+// Priority is the class of urgency of a queued handling event, by how much
+// slack the cargo has before its arrival deadline.
+type Priority int
+
+// Priority classes, most urgent first.
+const (
+	// PriorityCritical is of cargos projected to arrive less than a day
+	// before their deadlines, or after them.
+	PriorityCritical Priority = iota
+
+	// PriorityUrgent is of cargos projected to arrive less than three days
+	// before their deadlines.
+	PriorityUrgent
+
+	// PriorityNormal is of all other cargos, including those without a
+	// deadline.
+	PriorityNormal
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityCritical:
+		return "critical"
+	case PriorityUrgent:
+		return "urgent"
+	case PriorityNormal:
+		return "normal"
+	}
+	return ""
+}
+
+// The least slack of cargos of the less urgent priority classes.
+const (
+	urgentSlack = 24 * time.Hour
+	normalSlack = 72 * time.Hour
+)
+
+// PriorityOf returns the priority class of the handling events of a cargo.
+// The slack is measured from the projected arrival of the cargo, or from
+// now if the arrival cannot be projected.
+func PriorityOf(c *shipping.Cargo, now time.Time) Priority {
+	deadline := c.RouteSpecification.ArrivalDeadline
+	if deadline.IsZero() {
+		return PriorityNormal
+	}
+
+	from := c.Delivery.ProjectedArrival()
+	if from.IsZero() {
+		from = now
+	}
+
+	switch slack := deadline.Sub(from); {
+	case slack < urgentSlack:
+		return PriorityCritical
+	case slack < normalSlack:
+		return PriorityUrgent
+	}
+	return PriorityNormal
+}
+
+// Queue holds the handling events registered but not yet stored and
+// dispatched to the event handler, so that terminals are not held up by the
+// handlers. It holds at most a fixed number of events, which bounds the
+// memory a terminal replaying a backlog of events may take up: registering
+// events fails with ErrBusy while the queue is full.
+//
+// Events are processed one at a time, the most urgent first, and in the
+// order they were registered within a priority class. Events of a cargo are
+// always processed in the order they were registered: an event of a cargo
+// with events already queued takes the priority of those.
+type Queue struct {
+	size  int
+	ready chan struct{}
+
+	depth    metrics.Gauge
+	rejected metrics.Counter
+	wait     metrics.Histogram
+	logger   log.Logger
+
+	mu        sync.Mutex
+	jobs      jobHeap
+	seq       uint64
+	pending   map[shipping.TrackingID]pendingJobs
+	saturated bool
+}
+
+// pendingJobs are the jobs of a cargo in the queue.
+type pendingJobs struct {
+	n        int
+	priority Priority
+}
+
+// QueueOption configures a queue.
+type QueueOption func(*Queue)
+
+// WithQueueMetrics sets the gauge the number of events queued is reported
+// to and the counter of the events refused while the queue was full.
+func WithQueueMetrics(depth metrics.Gauge, rejected metrics.Counter) QueueOption {
+	return func(q *Queue) {
+		q.depth = depth
+		q.rejected = rejected
+	}
+}
+
+// WithQueueWait sets the histogram the seconds events wait in the queue are
+// observed in, labelled by "priority" class.
+func WithQueueWait(h metrics.Histogram) QueueOption {
+	return func(q *Queue) {
+		q.wait = h
+	}
+}
+
+// WithQueueLogger sets the logger warned when the queue is about to fill up,
+// and told once it has drained again.
+func WithQueueLogger(l log.Logger) QueueOption {
+	return func(q *Queue) {
+		q.logger = l
+	}
+}
+
+// NewQueue returns a queue holding at most size events. Run must be called
+// for the events to be processed.
+func NewQueue(size int, opts ...QueueOption) *Queue {
+	q := &Queue{
+		size:    size,
+		ready:   make(chan struct{}, 1),
+		logger:  log.NewNopLogger(),
+		pending: make(map[shipping.TrackingID]pendingJobs),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// Run processes the queued events until the context is cancelled.
+func (q *Queue) Run(ctx context.Context) {
+	for {
+		if j, ok := q.pop(); ok {
+			if q.wait != nil {
+				q.wait.With("priority", j.priority.String()).Observe(time.Since(j.queued).Seconds())
+			}
+			j.fn()
+			continue
+		}
+
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Len returns the number of events queued.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
+
+// Cap returns the largest number of events the queue holds.
+func (q *Queue) Cap() int {
+	return q.size
+}
+
+// push queues a job handling an event of a cargo, returning ErrBusy if the
+// queue is full.
+func (q *Queue) push(id shipping.TrackingID, p Priority, fn func()) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.jobs) >= q.size {
+		if q.rejected != nil {
+			q.rejected.Add(1)
+		}
+		q.observe()
+		return ErrBusy
+	}
+
+	pend := q.pending[id]
+	if pend.n > 0 {
+		p = pend.priority
+	}
+	q.pending[id] = pendingJobs{n: pend.n + 1, priority: p}
+
+	q.seq++
+	heap.Push(&q.jobs, &job{
+		id:       id,
+		priority: p,
+		seq:      q.seq,
+		queued:   time.Now(),
+		fn:       fn,
+	})
+	q.observe()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// pop removes the most urgent job from the queue, if any.
+func (q *Queue) pop() (*job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.jobs) == 0 {
+		return nil, false
+	}
+
+	j := heap.Pop(&q.jobs).(*job)
+	if pend := q.pending[j.id]; pend.n > 1 {
+		q.pending[j.id] = pendingJobs{n: pend.n - 1, priority: pend.priority}
+	} else {
+		delete(q.pending, j.id)
+	}
+	q.observe()
+
+	return j, true
+}
+
+// observe reports the depth of the queue, warning when it passes 80% of its
+// capacity and once it has drained below half of it. The lock must be held.
+func (q *Queue) observe() {
+	n, size := len(q.jobs), q.size
+
+	if q.depth != nil {
+		q.depth.Set(float64(n))
+	}
+
+	switch {
+	case !q.saturated && n*5 >= size*4:
+		q.saturated = true
+		q.logger.Log("msg", "handling queue saturated", "depth", n, "size", size)
+	case q.saturated && n*2 < size:
+		q.saturated = false
+		q.logger.Log("msg", "handling queue drained", "depth", n, "size", size)
+	}
+}
+
+type job struct {
+	id       shipping.TrackingID
+	priority Priority
+	seq      uint64
+	queued   time.Time
+	fn       func()
+}
+
+// jobHeap orders jobs by priority, and by the order they were queued in.
+type jobHeap []*job
+
+func (h jobHeap) Len() int { return len(h) }
+
+func (h jobHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority < h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h jobHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *jobHeap) Push(x interface{}) { *h = append(*h, x.(*job)) }
+
+func (h *jobHeap) Pop() interface{} {
+	old := *h
+	j := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return j
+}
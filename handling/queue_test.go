@@ -0,0 +1,193 @@
+package handling
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+func TestRegisterHandlingEvent_Queued(t *testing.T) {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return new(shipping.Cargo), nil
+	}
+
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(n shipping.VoyageNumber) (*shipping.Voyage, error) {
+		return new(shipping.Voyage), nil
+	}
+
+	var locations mock.LocationRepository
+	locations.FindFn = func(l shipping.UNLocode) (*shipping.Location, error) {
+		return nil, nil
+	}
+
+	var stored []shipping.HandlingEventType
+
+	var events mock.HandlingEventRepository
+	events.StoreFn = func(e shipping.HandlingEvent) {
+		stored = append(stored, e.Activity.Type)
+	}
+
+	var terminals mock.TerminalRepository
+	terminals.FindFn = func(id shipping.TerminalID) (*shipping.Terminal, error) {
+		return &shipping.Terminal{ID: id, Location: shipping.SESTO, KeyHash: shipping.HashTerminalKey("secret")}, nil
+	}
+
+	var warnings []interface{}
+
+	q := NewQueue(2, WithQueueLogger(log.LoggerFunc(func(kv ...interface{}) error {
+		warnings = append(warnings, kv...)
+		return nil
+	})))
+
+	eh := &stubEventHandler{}
+	ef := shipping.HandlingEventFactory{
+		CargoRepository:    &cargos,
+		VoyageRepository:   &voyages,
+		LocationRepository: &locations,
+	}
+
+	s := NewService(&events, ef, eh, &terminals, WithQueue(q))
+
+	var (
+		ctx       = context.Background()
+		source    = Credentials{Terminal: "sesto-1", Key: "secret"}
+		completed = time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
+	)
+
+	for _, typ := range []shipping.HandlingEventType{shipping.Receive, shipping.Load} {
+		if err := s.RegisterHandlingEvent(ctx, source, completed, "ABC123", "V100", shipping.SESTO, typ); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(stored) != 0 {
+		t.Errorf("events should not be stored before the queue is run")
+	}
+	if len(warnings) == 0 {
+		t.Errorf("a full queue should be warned about")
+	}
+
+	if err := s.RegisterHandlingEvent(ctx, source, completed, "ABC123", "V100", shipping.SESTO, shipping.Unload); err != ErrBusy {
+		t.Errorf("err = %v; want = %v", err, ErrBusy)
+	}
+
+	// Invalid events are refused before being queued.
+	if err := s.RegisterHandlingEvent(ctx, Credentials{}, completed, "ABC123", "V100", shipping.SESTO, shipping.Unload); err != shipping.ErrUnauthorizedTerminal {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnauthorizedTerminal)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+
+	for q.Len() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if len(stored) != 2 || stored[0] != shipping.Receive || stored[1] != shipping.Load {
+		t.Errorf("stored = %v; want = [Receive Load]", stored)
+	}
+	if len(eh.events) != 2 {
+		t.Errorf("len(eh.events) = %d; want = %d", len(eh.events), 2)
+	}
+}
+
+// labelHistogram records the labels of the observations.
+type labelHistogram struct {
+	labels []string
+	seen   *[]string
+}
+
+func (h labelHistogram) With(labelValues ...string) metrics.Histogram {
+	return labelHistogram{labels: append(append([]string{}, h.labels...), labelValues...), seen: h.seen}
+}
+
+func (h labelHistogram) Observe(value float64) {
+	*h.seen = append(*h.seen, h.labels[len(h.labels)-1])
+}
+
+func TestQueue_Priority(t *testing.T) {
+	var observed []string
+	q := NewQueue(10, WithQueueWait(labelHistogram{seen: &observed}))
+
+	var handled []string
+	push := func(id shipping.TrackingID, p Priority, name string) {
+		if err := q.push(id, p, func() { handled = append(handled, name) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	push("A", PriorityNormal, "A1")
+	push("B", PriorityUrgent, "B1")
+	push("C", PriorityCritical, "C1")
+	push("D", PriorityNormal, "D1")
+	push("B", PriorityUrgent, "B2")
+	// The cargo has become critical, but its earlier event is handled
+	// first.
+	push("A", PriorityCritical, "A2")
+	push("E", PriorityCritical, "E1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+
+	for q.Len() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	want := []string{"C1", "E1", "B1", "B2", "A1", "D1", "A2"}
+	if !reflect.DeepEqual(handled, want) {
+		t.Errorf("handled = %v; want = %v", handled, want)
+	}
+	if len(observed) != len(want) || observed[0] != "critical" || observed[len(observed)-1] != "normal" {
+		t.Errorf("observed = %v", observed)
+	}
+}
+
+func TestPriorityOf(t *testing.T) {
+	now := time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	cargo := func(deadline, eta time.Time) *shipping.Cargo {
+		c := shipping.NewCargo("ABC123", shipping.RouteSpecification{ArrivalDeadline: deadline})
+		c.Delivery.ETA = eta
+		return c
+	}
+
+	tests := []struct {
+		name string
+		c    *shipping.Cargo
+		want Priority
+	}{
+		{"no deadline", cargo(time.Time{}, now), PriorityNormal},
+		{"overdue", cargo(now.Add(-time.Hour), time.Time{}), PriorityCritical},
+		{"hours left", cargo(now.Add(12*time.Hour), time.Time{}), PriorityCritical},
+		{"days left", cargo(now.Add(48*time.Hour), time.Time{}), PriorityUrgent},
+		{"week left", cargo(now.Add(7*24*time.Hour), time.Time{}), PriorityNormal},
+		{"projected late", cargo(now.Add(7*24*time.Hour), now.Add(8*24*time.Hour)), PriorityCritical},
+		{"projected close", cargo(now.Add(7*24*time.Hour), now.Add(5*24*time.Hour)), PriorityUrgent},
+	}
+
+	for _, tt := range tests {
+		if got := PriorityOf(tt.c, now); got != tt.want {
+			t.Errorf("%s: PriorityOf = %v; want = %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -3,67 +3,417 @@
 package handling
 
 import (
+	"context"
+	"crypto/hmac"
 	"errors"
 	"time"
 
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+
 	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
 	"github.com/marcusolsson/goddd/inspection"
 )
 
 // ErrInvalidArgument is returned when one or more arguments are invalid.
 var ErrInvalidArgument = errors.New("invalid argument")
 
+// ErrInvalidToken is returned when registering or listing terminals without
+// the admin token.
+var ErrInvalidToken = errors.New("invalid admin token")
+
 // EventHandler provides a means of subscribing to registered handling events.
 type EventHandler interface {
-	CargoWasHandled(shipping.HandlingEvent)
+	CargoWasHandled(context.Context, shipping.HandlingEvent)
+}
+
+// Credentials identify and authenticate the terminal reporting a handling
+// event, and authorize the release of the cargo if it is claimed.
+type Credentials struct {
+	Terminal shipping.TerminalID
+	Key      string
+
+	// Signature signs the report the credentials come with. Terminals
+	// sharing a signing secret must sign every report.
+	Signature *Signature
+
+	// Secret is the signing secret of the terminal, which clients sign
+	// reports with rather than send.
+	Secret string
+
+	// ReleaseCode is the PIN presented by whoever collects the cargo, when
+	// claiming it.
+	ReleaseCode string
 }
 
 // Service provides handling operations.
 type Service interface {
 	// RegisterHandlingEvent registers a handling event in the system, and
-	// notifies interested parties that a cargo has been handled.
-	RegisterHandlingEvent(completed time.Time, id shipping.TrackingID, voyageNumber shipping.VoyageNumber,
+	// notifies interested parties that a cargo has been handled. The event
+	// must be reported by a registered terminal at the location of the
+	// event, and signed if the terminal signs its reports. If release codes
+	// are required, claims must present the release code issued for the
+	// cargo.
+	RegisterHandlingEvent(ctx context.Context, source Credentials, completed time.Time, id shipping.TrackingID, voyageNumber shipping.VoyageNumber,
 		unLocode shipping.UNLocode, eventType shipping.HandlingEventType) error
+
+	// StreamHandlingEvents calls fn for every registered handling event.
+	// Streaming stops at the first error returned by fn.
+	StreamHandlingEvents(ctx context.Context, fn func(shipping.HandlingEvent) error) error
+
+	// RegisterTerminal authorizes a terminal to report handling events at a
+	// location, authenticating with the given key. Terminals given a signing
+	// secret must sign their reports with it. Registering an existing
+	// terminal replaces its location, key and secret. The token
+	// authenticates the administrator registering the terminal.
+	RegisterTerminal(ctx context.Context, token string, id shipping.TerminalID, name string, loc shipping.UNLocode, key, secret string) error
+
+	// Terminals returns all registered terminals. The token authenticates
+	// the administrator listing them.
+	Terminals(ctx context.Context, token string) ([]Terminal, error)
 }
 
 type service struct {
 	handlingEventRepository shipping.HandlingEventRepository
 	handlingEventFactory    shipping.HandlingEventFactory
 	handlingEventHandler    EventHandler
+	terminals               shipping.TerminalRepository
+	queue                   *Queue
+	replays                 *replayGuard
+	releaseCodes            shipping.ReleaseCodeRepository
+	adminToken              string
+
+	now func() time.Time
+
+	// Decorators assembled around the service by NewService.
+	authorizer     Authorizer
+	logger         log.Logger
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
 }
 
-func (s *service) RegisterHandlingEvent(completed time.Time, id shipping.TrackingID, voyageNumber shipping.VoyageNumber,
+func (s *service) RegisterHandlingEvent(ctx context.Context, source Credentials, completed time.Time, id shipping.TrackingID, voyageNumber shipping.VoyageNumber,
 	loc shipping.UNLocode, eventType shipping.HandlingEventType) error {
 	if completed.IsZero() || id == "" || loc == "" || eventType == shipping.NotHandled {
 		return ErrInvalidArgument
 	}
 
-	e, err := s.handlingEventFactory.CreateHandlingEvent(time.Now(), completed, id, voyageNumber, loc, eventType)
+	if err := s.authorize(source, loc); err != nil {
+		return err
+	}
+
+	err := s.register(ctx, source, completed, id, voyageNumber, loc, eventType)
+	if err != nil && source.Signature != nil {
+		// The report was not accepted, so the terminal may send it again,
+		// e.g. when the queue is busy.
+		s.replays.forget(source.Terminal, *source.Signature)
+	}
+	return err
+}
+
+// register creates the handling event of an authorized report, and handles
+// it or queues it for handling.
+func (s *service) register(ctx context.Context, source Credentials, completed time.Time, id shipping.TrackingID, voyageNumber shipping.VoyageNumber,
+	loc shipping.UNLocode, eventType shipping.HandlingEventType) error {
+	e, err := s.handlingEventFactory.CreateHandlingEvent(s.now(), completed, id, voyageNumber, loc, eventType)
 	if err != nil {
 		return err
 	}
+	e.Source = source.Terminal
+	e.CorrelationID = correlation.FromContext(ctx)
+
+	if eventType == shipping.Claim && s.releaseCodes != nil {
+		if err := s.release(id, source.ReleaseCode); err != nil {
+			return err
+		}
+	}
+
+	if s.queue == nil {
+		s.handle(ctx, e)
+		return nil
+	}
 
+	// Events of cargos nearest their arrival deadlines are handled first.
+	c, err := s.handlingEventFactory.CargoRepository.Find(id)
+	if err != nil {
+		return err
+	}
+	p := PriorityOf(c, s.now())
+
+	// The event outlives the request, but keeps its values, such as the
+	// request ID.
+	ctx = context.WithoutCancel(ctx)
+
+	return s.queue.push(id, p, func() {
+		s.handle(ctx, e)
+	})
+}
+
+// handle stores the event and notifies the event handler.
+func (s *service) handle(ctx context.Context, e shipping.HandlingEvent) {
 	s.handlingEventRepository.Store(e)
-	s.handlingEventHandler.CargoWasHandled(e)
+	s.handlingEventHandler.CargoWasHandled(ctx, e)
+}
+
+func (s *service) StreamHandlingEvents(ctx context.Context, fn func(shipping.HandlingEvent) error) error {
+	return s.handlingEventRepository.ForEach(func(e shipping.HandlingEvent) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(e)
+	})
+}
+
+// authorize checks that the terminal is registered, authenticates, signs
+// the report if it signs its reports, and reports for the location.
+func (s *service) authorize(source Credentials, loc shipping.UNLocode) error {
+	if source.Terminal == "" {
+		return shipping.ErrUnauthorizedTerminal
+	}
+
+	t, err := s.terminals.Find(source.Terminal)
+	if err == shipping.ErrUnknownTerminal {
+		return shipping.ErrUnauthorizedTerminal
+	}
+	if err != nil {
+		return err
+	}
+
+	if !t.Authenticate(source.Key) {
+		return shipping.ErrUnauthorizedTerminal
+	}
+
+	if t.SignsReports() {
+		sig := source.Signature
+		if sig == nil {
+			return ErrUnsignedReport
+		}
+		if !t.VerifySignature(sig.message(), sig.MAC) {
+			return ErrInvalidSignature
+		}
+	}
+
+	if !t.Reports(loc) {
+		return shipping.ErrTerminalLocation
+	}
+
+	// The nonce is checked last, so that reports refused for other reasons
+	// may be sent again.
+	if t.SignsReports() {
+		if err := s.replays.check(t.ID, *source.Signature, s.now()); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// release verifies the release code presented for claiming a cargo. Wrong
+// codes are counted towards locking the code.
+func (s *service) release(id shipping.TrackingID, pin string) error {
+	if pin == "" {
+		return shipping.ErrReleaseCodeRequired
+	}
+
+	rc, err := s.releaseCodes.Find(id)
+	if err != nil {
+		return err
+	}
+
+	if err := rc.Verify(pin); err != nil {
+		if err == shipping.ErrInvalidReleaseCode {
+			if err := s.releaseCodes.Store(rc); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) RegisterTerminal(ctx context.Context, token string, id shipping.TerminalID, name string, loc shipping.UNLocode, key, secret string) error {
+	if err := s.authenticateAdmin(token); err != nil {
+		return err
+	}
+
+	if id == "" || loc == "" || key == "" {
+		return ErrInvalidArgument
+	}
+
+	if _, err := s.handlingEventFactory.LocationRepository.Find(loc); err != nil {
+		return err
+	}
+
+	return s.terminals.Store(&shipping.Terminal{
+		ID:       id,
+		Name:     name,
+		Location: loc,
+		KeyHash:  shipping.HashTerminalKey(key),
+
+		SigningSecret: secret,
+	})
+}
+
+func (s *service) Terminals(ctx context.Context, token string) ([]Terminal, error) {
+	if err := s.authenticateAdmin(token); err != nil {
+		return nil, err
+	}
+
+	result := make([]Terminal, 0)
+	for _, t := range s.terminals.FindAll() {
+		result = append(result, Terminal{
+			ID:       string(t.ID),
+			Name:     t.Name,
+			Location: string(t.Location),
+			Signed:   t.SignsReports(),
+		})
+	}
+	return result, nil
+}
+
+// authenticateAdmin checks the token of an administrator managing terminals.
+func (s *service) authenticateAdmin(token string) error {
+	if s.adminToken == "" || !hmac.Equal([]byte(token), []byte(s.adminToken)) {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// Option configures a handling service.
+type Option func(*service)
+
+// WithQueue sets the queue handling events are stored and dispatched to the
+// event handler from, once they have been validated. Events are otherwise
+// stored and dispatched before registering returns.
+func WithQueue(q *Queue) Option {
+	return func(s *service) {
+		s.queue = q
+	}
+}
+
+// WithReplayWindow sets how far the time a signed report was signed at may
+// be off, rather than DefaultReplayWindow. The nonces of reports are
+// remembered as long.
+func WithReplayWindow(d time.Duration) Option {
+	return func(s *service) {
+		s.replays.window = d
+	}
+}
+
+// WithReportNonces sets the repository the nonces of signed reports are
+// remembered in, so that they cannot be sent again. Signed reports are
+// otherwise refused, as replays could not be told apart.
+func WithReportNonces(r shipping.ReportNonceRepository) Option {
+	return func(s *service) {
+		s.replays.nonces = r
+	}
+}
+
+// WithReleaseCodes requires claims to present the release code issued for
+// the cargo, as stored in r. Cargos are otherwise claimed without one.
+func WithReleaseCodes(r shipping.ReleaseCodeRepository) Option {
+	return func(s *service) {
+		s.releaseCodes = r
+	}
+}
+
+// WithAdminToken sets the token administrators register terminals with.
+// Terminals cannot be registered or replaced otherwise.
+func WithAdminToken(token string) Option {
+	return func(s *service) {
+		s.adminToken = token
+	}
+}
+
+// Authorizer decides whether the caller may call a method of the handling
+// service, named as in the logs and metrics, returning an error if not.
+type Authorizer func(ctx context.Context, method string) error
+
+// WithAuthorizer refuses the calls the authorizer returns an error for.
+func WithAuthorizer(a Authorizer) Option {
+	return func(s *service) {
+		s.authorizer = a
+	}
+}
+
+// WithLogger logs the calls to the service.
+func WithLogger(l log.Logger) Option {
+	return func(s *service) {
+		s.logger = l
+	}
+}
+
+// WithMetrics counts the calls to the service and records their latency.
+func WithMetrics(counter metrics.Counter, latency metrics.Histogram) Option {
+	return func(s *service) {
+		s.requestCount = counter
+		s.requestLatency = latency
+	}
+}
+
+// WithClock sets the clock the service tells the time by, rather than the
+// system clock.
+func WithClock(now func() time.Time) Option {
+	return func(s *service) {
+		s.now = now
+	}
+}
+
 // NewService creates a handling event service with necessary dependencies.
-func NewService(r shipping.HandlingEventRepository, f shipping.HandlingEventFactory, h EventHandler) Service {
-	return &service{
+// Calls are authorized, then logged, then counted, as configured by the
+// options, so that refused calls show in the logs and metrics.
+func NewService(r shipping.HandlingEventRepository, f shipping.HandlingEventFactory, h EventHandler, terminals shipping.TerminalRepository, opts ...Option) Service {
+	s := &service{
 		handlingEventRepository: r,
 		handlingEventFactory:    f,
 		handlingEventHandler:    h,
+		terminals:               terminals,
+		replays:                 &replayGuard{window: DefaultReplayWindow},
+		now:                     time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	var svc Service = s
+	if s.authorizer != nil {
+		svc = NewAuthorizingService(s.authorizer, svc)
+	}
+	if s.logger != nil {
+		svc = NewLoggingService(s.logger, svc)
 	}
+	if s.requestCount != nil {
+		svc = NewInstrumentingService(s.requestCount, s.requestLatency, svc)
+	}
+	return svc
+}
+
+// Terminal is a read model for terminal views.
+type Terminal struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Location string `json:"location"`
+	Signed   bool   `json:"signed,omitempty"`
 }
 
 type handlingEventHandler struct {
 	InspectionService inspection.Service
 }
 
-func (h *handlingEventHandler) CargoWasHandled(event shipping.HandlingEvent) {
-	h.InspectionService.InspectCargo(event.TrackingID)
+func (h *handlingEventHandler) CargoWasHandled(ctx context.Context, event shipping.HandlingEvent) {
+	h.InspectionService.InspectCargo(ctx, event.TrackingID)
+}
+
+// EventHandlers notifies several event handlers in order.
+type EventHandlers []EventHandler
+
+// CargoWasHandled notifies every handler.
+func (hs EventHandlers) CargoWasHandled(ctx context.Context, event shipping.HandlingEvent) {
+	for _, h := range hs {
+		h.CargoWasHandled(ctx, event)
+	}
 }
 
 // NewEventHandler returns a new instance of a EventHandler.
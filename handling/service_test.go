@@ -1,10 +1,14 @@
 package handling
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
 	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+	"github.com/marcusolsson/goddd/inmem"
 	"github.com/marcusolsson/goddd/mock"
 )
 
@@ -12,7 +16,7 @@ type stubEventHandler struct {
 	events []interface{}
 }
 
-func (h *stubEventHandler) CargoWasHandled(e shipping.HandlingEvent) {
+func (h *stubEventHandler) CargoWasHandled(ctx context.Context, e shipping.HandlingEvent) {
 	h.events = append(h.events, e)
 }
 
@@ -48,7 +52,17 @@ func TestRegisterHandlingEvent(t *testing.T) {
 		LocationRepository: &locations,
 	}
 
-	s := NewService(&events, ef, eh)
+	var terminals mock.TerminalRepository
+	terminals.FindFn = func(id shipping.TerminalID) (*shipping.Terminal, error) {
+		if id != "sesto-1" {
+			return nil, shipping.ErrUnknownTerminal
+		}
+		return &shipping.Terminal{ID: id, Location: shipping.SESTO, KeyHash: shipping.HashTerminalKey("secret")}, nil
+	}
+
+	registered := time.Date(2015, time.November, 11, 8, 0, 0, 0, time.UTC)
+
+	s := NewService(&events, ef, eh, &terminals, WithClock(func() time.Time { return registered }))
 
 	var (
 		completed = time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
@@ -63,17 +77,371 @@ func TestRegisterHandlingEvent(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = s.RegisterHandlingEvent(completed, id, voyage, shipping.SESTO, shipping.Load)
+	source := Credentials{Terminal: "sesto-1", Key: "secret"}
+
+	err = s.RegisterHandlingEvent(correlation.NewContext(context.Background(), "req-1"), source, completed, id, voyage, shipping.SESTO, shipping.Load)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = s.RegisterHandlingEvent(completed, "no_such_id", voyage, shipping.SESTO, shipping.Load)
+	err = s.RegisterHandlingEvent(context.Background(), source, completed, "no_such_id", voyage, shipping.SESTO, shipping.Load)
 	if err != shipping.ErrUnknownCargo {
 		t.Errorf("err = %s; want = %s", err, shipping.ErrUnknownCargo)
 	}
 
+	if len(eh.events) != 1 {
+		t.Fatalf("len(eh.events) = %d; want = %d", len(eh.events), 1)
+	}
+	e := eh.events[0].(shipping.HandlingEvent)
+	if !e.Registered.Equal(registered) {
+		t.Errorf("e.Registered = %v; want = %v", e.Registered, registered)
+	}
+	if e.CorrelationID != "req-1" {
+		t.Errorf("e.CorrelationID = %q; want = %q", e.CorrelationID, "req-1")
+	}
+}
+
+func TestRegisterHandlingEventUnauthorized(t *testing.T) {
+	var events mock.HandlingEventRepository
+	events.StoreFn = func(e shipping.HandlingEvent) {
+		t.Error("event stored")
+	}
+
+	var terminals mock.TerminalRepository
+	terminals.FindFn = func(id shipping.TerminalID) (*shipping.Terminal, error) {
+		if id != "sesto-1" {
+			return nil, shipping.ErrUnknownTerminal
+		}
+		return &shipping.Terminal{ID: id, Location: shipping.SESTO, KeyHash: shipping.HashTerminalKey("secret")}, nil
+	}
+
+	eh := &stubEventHandler{events: make([]interface{}, 0)}
+
+	s := NewService(&events, shipping.HandlingEventFactory{}, eh, &terminals)
+
+	var (
+		completed = time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
+		id        = shipping.TrackingID("ABC123")
+	)
+
+	for _, tt := range []struct {
+		source Credentials
+		loc    shipping.UNLocode
+		want   error
+	}{
+		{source: Credentials{}, loc: shipping.SESTO, want: shipping.ErrUnauthorizedTerminal},
+		{source: Credentials{Terminal: "no_such_terminal", Key: "secret"}, loc: shipping.SESTO, want: shipping.ErrUnauthorizedTerminal},
+		{source: Credentials{Terminal: "sesto-1", Key: "wrong"}, loc: shipping.SESTO, want: shipping.ErrUnauthorizedTerminal},
+		{source: Credentials{Terminal: "sesto-1", Key: "secret"}, loc: shipping.DEHAM, want: shipping.ErrTerminalLocation},
+	} {
+		err := s.RegisterHandlingEvent(context.Background(), tt.source, completed, id, "V100", tt.loc, shipping.Load)
+		if err != tt.want {
+			t.Errorf("RegisterHandlingEvent(%v, %s) = %v; want = %v", tt.source.Terminal, tt.loc, err, tt.want)
+		}
+	}
+
+	if len(eh.events) != 0 {
+		t.Errorf("len(eh.events) = %d; want = %d", len(eh.events), 0)
+	}
+}
+
+func TestRegisterHandlingEventClaim(t *testing.T) {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return new(shipping.Cargo), nil
+	}
+
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(n shipping.VoyageNumber) (*shipping.Voyage, error) {
+		return nil, shipping.ErrUnknownVoyage
+	}
+
+	var locations mock.LocationRepository
+	locations.FindFn = func(l shipping.UNLocode) (*shipping.Location, error) {
+		return nil, nil
+	}
+
+	var events mock.HandlingEventRepository
+	events.StoreFn = func(e shipping.HandlingEvent) {}
+
+	var terminals mock.TerminalRepository
+	terminals.FindFn = func(id shipping.TerminalID) (*shipping.Terminal, error) {
+		return &shipping.Terminal{ID: id, Location: shipping.SESTO, KeyHash: shipping.HashTerminalKey("secret")}, nil
+	}
+
+	codes := make(map[shipping.TrackingID]shipping.ReleaseCode)
+
+	var releaseCodes mock.ReleaseCodeRepository
+	releaseCodes.StoreFn = func(c *shipping.ReleaseCode) error {
+		codes[c.TrackingID] = *c
+		return nil
+	}
+	releaseCodes.FindFn = func(id shipping.TrackingID) (*shipping.ReleaseCode, error) {
+		c, ok := codes[id]
+		if !ok {
+			return nil, shipping.ErrNoReleaseCode
+		}
+		return &c, nil
+	}
+
+	eh := &stubEventHandler{events: make([]interface{}, 0)}
+	ef := shipping.HandlingEventFactory{
+		CargoRepository:    &cargos,
+		VoyageRepository:   &voyages,
+		LocationRepository: &locations,
+	}
+
+	s := NewService(&events, ef, eh, &terminals, WithReleaseCodes(&releaseCodes))
+
+	var (
+		ctx       = context.Background()
+		completed = time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
+		id        = shipping.TrackingID("ABC123")
+		source    = Credentials{Terminal: "sesto-1", Key: "secret"}
+	)
+
+	claim := func(pin string) error {
+		source.ReleaseCode = pin
+		return s.RegisterHandlingEvent(ctx, source, completed, id, "", shipping.SESTO, shipping.Claim)
+	}
+
+	if err := claim("123456"); err != shipping.ErrNoReleaseCode {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrNoReleaseCode)
+	}
+
+	rc, pin, err := shipping.NewReleaseCode(id, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	releaseCodes.Store(rc)
+
+	if err := claim(""); err != shipping.ErrReleaseCodeRequired {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrReleaseCodeRequired)
+	}
+	if err := claim("x"); err != shipping.ErrInvalidReleaseCode {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrInvalidReleaseCode)
+	}
+	if n := codes[id].Attempts; n != 1 {
+		t.Errorf("Attempts = %d; want the wrong code counted", n)
+	}
+	if len(eh.events) != 0 {
+		t.Fatalf("cargo claimed without its release code")
+	}
+
+	if err := claim(pin); err != nil {
+		t.Fatal(err)
+	}
 	if len(eh.events) != 1 {
 		t.Errorf("len(eh.events) = %d; want = %d", len(eh.events), 1)
 	}
+
+	// Other events do not need the release code.
+	source.ReleaseCode = ""
+	if err := s.RegisterHandlingEvent(ctx, source, completed, id, "", shipping.SESTO, shipping.Receive); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRegisterHandlingEventSigned(t *testing.T) {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		if id == "no_such_id" {
+			return nil, shipping.ErrUnknownCargo
+		}
+		return new(shipping.Cargo), nil
+	}
+
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(n shipping.VoyageNumber) (*shipping.Voyage, error) {
+		return new(shipping.Voyage), nil
+	}
+
+	var locations mock.LocationRepository
+	locations.FindFn = func(l shipping.UNLocode) (*shipping.Location, error) {
+		return nil, nil
+	}
+
+	var events mock.HandlingEventRepository
+	events.StoreFn = func(e shipping.HandlingEvent) {}
+
+	var terminals mock.TerminalRepository
+	terminals.FindFn = func(id shipping.TerminalID) (*shipping.Terminal, error) {
+		return &shipping.Terminal{ID: id, Location: shipping.SESTO, KeyHash: shipping.HashTerminalKey("secret"), SigningSecret: "signing"}, nil
+	}
+
+	eh := &stubEventHandler{events: make([]interface{}, 0)}
+	ef := shipping.HandlingEventFactory{
+		CargoRepository:    &cargos,
+		VoyageRepository:   &voyages,
+		LocationRepository: &locations,
+	}
+
+	s := NewService(&events, ef, eh, &terminals, WithReplayWindow(time.Minute), WithReportNonces(inmem.NewReportNonceRepository()))
+
+	var (
+		completed = time.Date(2015, time.November, 10, 23, 0, 0, 0, time.UTC)
+		now       = time.Now()
+		report    = []byte(`{"tracking_id":"ABC123"}`)
+	)
+
+	signed := func(sig Signature) Credentials {
+		return Credentials{Terminal: "sesto-1", Key: "secret", Signature: &sig}
+	}
+	altered := Sign("signing", now, "n2", report)
+	altered.Report = []byte(`{"tracking_id":"XYZ789"}`)
+
+	for _, tt := range []struct {
+		name   string
+		source Credentials
+		want   error
+	}{
+		{"signed", signed(Sign("signing", now, "n1", report)), nil},
+		{"unsigned", Credentials{Terminal: "sesto-1", Key: "secret"}, ErrUnsignedReport},
+		{"wrong secret", signed(Sign("guess", now, "n2", report)), ErrInvalidSignature},
+		{"altered", signed(altered), ErrInvalidSignature},
+		{"replayed", signed(Sign("signing", now, "n1", report)), ErrReplayedReport},
+		{"expired", signed(Sign("signing", now.Add(-2*time.Minute), "n3", report)), ErrReplayedReport},
+		{"no nonce", signed(Sign("signing", now, "", report)), ErrReplayedReport},
+		{"new nonce", signed(Sign("signing", now, "n4", report)), nil},
+	} {
+		err := s.RegisterHandlingEvent(context.Background(), tt.source, completed, "ABC123", "V100", shipping.SESTO, shipping.Load)
+		if err != tt.want {
+			t.Errorf("%s: err = %v; want = %v", tt.name, err, tt.want)
+		}
+	}
+
+	if len(eh.events) != 2 {
+		t.Errorf("len(eh.events) = %d; want = %d", len(eh.events), 2)
+	}
+
+	// A report refused after its signature was checked may be sent again.
+	refused := signed(Sign("signing", now, "n5", report))
+	for i := 0; i < 2; i++ {
+		err := s.RegisterHandlingEvent(context.Background(), refused, completed, "no_such_id", "V100", shipping.SESTO, shipping.Load)
+		if err != shipping.ErrUnknownCargo {
+			t.Errorf("attempt %d: err = %v; want = %v", i+1, err, shipping.ErrUnknownCargo)
+		}
+	}
+}
+
+func TestRegisterTerminal(t *testing.T) {
+	var locations mock.LocationRepository
+	locations.FindFn = func(l shipping.UNLocode) (*shipping.Location, error) {
+		if l != shipping.SESTO {
+			return nil, shipping.ErrUnknownLocation
+		}
+		return &shipping.Location{UNLocode: l}, nil
+	}
+
+	stored := make(map[shipping.TerminalID]*shipping.Terminal)
+
+	var terminals mock.TerminalRepository
+	terminals.StoreFn = func(t *shipping.Terminal) error {
+		stored[t.ID] = t
+		return nil
+	}
+	terminals.FindAllFn = func() []*shipping.Terminal {
+		var ts []*shipping.Terminal
+		for _, t := range stored {
+			ts = append(ts, t)
+		}
+		return ts
+	}
+
+	ef := shipping.HandlingEventFactory{LocationRepository: &locations}
+
+	s := NewService(nil, ef, nil, &terminals, WithAdminToken("admin"))
+
+	if ts, err := s.Terminals(context.Background(), "admin"); err != nil || ts == nil || len(ts) != 0 {
+		t.Errorf("Terminals() = %v, %v; want an empty list", ts, err)
+	}
+
+	if err := s.RegisterTerminal(context.Background(), "admin", "sesto-1", "Stockholm Frihamnen", shipping.SESTO, "secret", "signing"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		if err := s.RegisterTerminal(context.Background(), token, "sesto-1", "", shipping.SESTO, "stolen", ""); err != ErrInvalidToken {
+			t.Errorf("err = %v; want = %v", err, ErrInvalidToken)
+		}
+	}
+	if !stored["sesto-1"].SignsReports() {
+		t.Error("signing turned off without the admin token")
+	}
+
+	if err := s.RegisterTerminal(context.Background(), "admin", "deham-1", "", shipping.DEHAM, "secret", ""); err != shipping.ErrUnknownLocation {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownLocation)
+	}
+
+	if err := s.RegisterTerminal(context.Background(), "admin", "sesto-2", "", shipping.SESTO, "", ""); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+
+	if !stored["sesto-1"].Authenticate("secret") {
+		t.Error("terminal does not authenticate with its key")
+	}
+
+	if _, err := s.Terminals(context.Background(), "wrong"); err != ErrInvalidToken {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidToken)
+	}
+
+	ts, err := s.Terminals(context.Background(), "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 1 {
+		t.Fatalf("len(ts) = %d; want = %d", len(ts), 1)
+	}
+	if ts[0].ID != "sesto-1" || ts[0].Location != "SESTO" || !ts[0].Signed {
+		t.Errorf("ts[0] = %+v", ts[0])
+	}
+}
+
+func TestReplayGuard(t *testing.T) {
+	var (
+		now = time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+		sig = Signature{Timestamp: now.Add(-10 * time.Second), Nonce: "n1"}
+	)
+
+	var expires time.Time
+	var nonces mock.ReportNonceRepository
+	nonces.AddFn = func(nonce string, _, e time.Time) error {
+		if nonce != "sesto-1\nn1" {
+			t.Errorf("nonce = %q; want = %q", nonce, "sesto-1\nn1")
+		}
+		expires = e
+		return nil
+	}
+
+	g := &replayGuard{window: 30 * time.Second, nonces: &nonces}
+
+	if err := g.check("sesto-1", sig, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// A nonce is remembered for as long as its report would be accepted.
+	if want := sig.Timestamp.Add(30 * time.Second); !expires.Equal(want) {
+		t.Errorf("expires = %v; want = %v", expires, want)
+	}
+
+	nonces.AddFn = func(string, time.Time, time.Time) error {
+		return shipping.ErrNonceUsed
+	}
+	if err := g.check("sesto-1", sig, now); err != ErrReplayedReport {
+		t.Errorf("err = %v; want = %v", err, ErrReplayedReport)
+	}
+
+	unavailable := errors.New("unavailable")
+	nonces.AddFn = func(string, time.Time, time.Time) error {
+		return unavailable
+	}
+	if err := g.check("sesto-1", sig, now); err != unavailable {
+		t.Errorf("err = %v; want = %v", err, unavailable)
+	}
+
+	// Without a repository, replays cannot be told apart.
+	g.nonces = nil
+	if err := g.check("sesto-1", sig, now); err != ErrReplayedReport {
+		t.Errorf("err = %v; want = %v", err, ErrReplayedReport)
+	}
 }
@@ -0,0 +1,108 @@
+package handling
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// The HTTP headers a report is signed in.
+const (
+	TimestampHeader = "X-Terminal-Timestamp"
+	NonceHeader     = "X-Terminal-Nonce"
+	SignatureHeader = "X-Terminal-Signature"
+)
+
+// DefaultReplayWindow is how far the time a report was signed at may be off,
+// unless configured otherwise.
+const DefaultReplayWindow = 5 * time.Minute
+
+var (
+	// ErrUnsignedReport is returned when a terminal that signs its reports
+	// reports handling without a signature.
+	ErrUnsignedReport = errors.New("report must be signed")
+
+	// ErrInvalidSignature is returned when the signature of a report does
+	// not match its content.
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrReplayedReport is returned when a report is signed too long ago,
+	// or has been received before.
+	ErrReplayedReport = errors.New("report expired or replayed")
+)
+
+// Signature signs a handling report with the secret shared with the
+// terminal, so that reports cannot be altered or sent again by others. A
+// terminal signs the time it sends the report at, a nonce unique to the
+// report and its content, with an HMAC-SHA256 of
+//
+//	timestamp + "\n" + nonce + "\n" + report
+//
+// where the timestamp is in seconds since the Unix epoch.
+type Signature struct {
+	Timestamp time.Time
+	Nonce     string
+	MAC       []byte
+
+	// Report is the content signed, i.e. the report as sent.
+	Report []byte
+}
+
+// Sign returns the signature of a report made at a time with a nonce.
+func Sign(secret string, timestamp time.Time, nonce string, report []byte) Signature {
+	sig := Signature{
+		Timestamp: timestamp,
+		Nonce:     nonce,
+		Report:    report,
+	}
+	sig.MAC = shipping.SignTerminalMessage(secret, sig.message())
+	return sig
+}
+
+func (sig Signature) message() []byte {
+	b := strconv.AppendInt(nil, sig.Timestamp.Unix(), 10)
+	b = append(b, '\n')
+	b = append(b, sig.Nonce...)
+	b = append(b, '\n')
+	return append(b, sig.Report...)
+}
+
+// replayGuard refuses reports signed outside of the window, or with a nonce
+// already seen. The nonces are remembered for as long as their reports would
+// be accepted. Without a repository to remember them in, no signed report is
+// accepted.
+type replayGuard struct {
+	window time.Duration
+	nonces shipping.ReportNonceRepository
+}
+
+func (g *replayGuard) check(id shipping.TerminalID, sig Signature, now time.Time) error {
+	if sig.Nonce == "" || sig.Timestamp.Before(now.Add(-g.window)) || sig.Timestamp.After(now.Add(g.window)) {
+		return ErrReplayedReport
+	}
+	if g.nonces == nil {
+		return ErrReplayedReport
+	}
+
+	err := g.nonces.Add(nonceKey(id, sig), now, sig.Timestamp.Add(g.window))
+	if err == shipping.ErrNonceUsed {
+		return ErrReplayedReport
+	}
+	return err
+}
+
+// forget forgets the nonce of a report that was not accepted after all, so
+// that it may be sent again. Should that fail, the report is refused as
+// replayed until the nonce expires.
+func (g *replayGuard) forget(id shipping.TerminalID, sig Signature) {
+	if g.nonces == nil {
+		return
+	}
+	g.nonces.Remove(nonceKey(id, sig))
+}
+
+func nonceKey(id shipping.TerminalID, sig Signature) string {
+	return string(id) + "\n" + sig.Nonce
+}
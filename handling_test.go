@@ -0,0 +1,55 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHandlingEventType(t *testing.T) {
+	tests := []struct {
+		in   string
+		want HandlingEventType
+		err  error
+	}{
+		{"load", Load, nil},
+		{"Unload", Unload, nil},
+		{"CUSTOMS", Customs, nil},
+		{"Not Handled", NotHandled, ErrUnknownHandlingEventType},
+		{"", NotHandled, ErrUnknownHandlingEventType},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseHandlingEventType(tt.in)
+		if err != tt.err {
+			t.Errorf("ParseHandlingEventType(%q) err = %v; want = %v", tt.in, err, tt.err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseHandlingEventType(%q) = %v; want = %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHandlingHistoryQuery_Includes(t *testing.T) {
+	completed := time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	load := HandlingEvent{Activity: HandlingActivity{Type: Load}, Completed: completed}
+	unload := HandlingEvent{Activity: HandlingActivity{Type: Unload}, Completed: completed}
+
+	tests := []struct {
+		q    HandlingHistoryQuery
+		e    HandlingEvent
+		want bool
+	}{
+		{HandlingHistoryQuery{}, load, true},
+		{HandlingHistoryQuery{Types: []HandlingEventType{Load}}, load, true},
+		{HandlingHistoryQuery{Types: []HandlingEventType{Load}}, unload, false},
+		{HandlingHistoryQuery{Types: []HandlingEventType{Load, Unload}}, unload, true},
+		{HandlingHistoryQuery{From: completed.Add(time.Hour), Types: []HandlingEventType{Load}}, load, false},
+	}
+
+	for i, tt := range tests {
+		if got := tt.q.Includes(tt.e); got != tt.want {
+			t.Errorf("%d: Includes() = %v; want = %v", i, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package inbound
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) Receive(ctx context.Context, token string, m Message) (int, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "receive").Add(1)
+		s.requestLatency.With("method", "receive").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Receive(ctx, token, m)
+}
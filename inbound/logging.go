@@ -0,0 +1,35 @@
+package inbound
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) Receive(ctx context.Context, token string, m Message) (n int, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "receive",
+			"request_id", correlation.FromContext(ctx),
+			"message_id", m.ID,
+			"from", m.From,
+			"registered", n,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Receive(ctx, token, m)
+}
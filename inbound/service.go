@@ -0,0 +1,111 @@
+// Package inbound provides the use-case of registering the handling events
+// agents report by mail. Mail is delivered by a mail provider, posting each
+// message received to a webhook.
+package inbound
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+
+	"github.com/marcusolsson/goddd/handling"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// ErrInvalidToken is returned when the token does not match the one shared
+// with the mail provider.
+var ErrInvalidToken = errors.New("invalid webhook token")
+
+// ErrUnrecognized is used when no template recognizes a mail.
+var ErrUnrecognized = errors.New("unrecognized mail")
+
+// Message is a mail, as delivered by the mail provider.
+type Message struct {
+	ID      string
+	From    string
+	Subject string
+	Body    string
+}
+
+// Reporter is told about the mail that was not recognized, or reported
+// events that could not be registered.
+type Reporter interface {
+	MailFailed(ctx context.Context, m Message, err error)
+}
+
+// Service is the interface that provides inbound mail methods.
+type Service interface {
+	// Receive registers the handling events reported in a mail, and returns
+	// how many were registered. The token authenticates the mail provider.
+	// Mail that is not recognized, or reports events that are refused, is
+	// passed on to the reporter rather than failing, as it has been
+	// received.
+	Receive(ctx context.Context, token string, m Message) (int, error)
+}
+
+type service struct {
+	handling  handling.Service
+	templates []Template
+	token     string
+	reporter  Reporter
+}
+
+func (s *service) Receive(ctx context.Context, token string, m Message) (int, error) {
+	if s.token == "" || !hmac.Equal([]byte(token), []byte(s.token)) {
+		return 0, ErrInvalidToken
+	}
+
+	if m.From == "" {
+		return 0, ErrInvalidArgument
+	}
+
+	t, events := s.recognize(m)
+	if len(events) == 0 {
+		s.report(ctx, m, ErrUnrecognized)
+		return 0, nil
+	}
+
+	for i, e := range events {
+		if err := s.handling.RegisterHandlingEvent(ctx, t.Source, e.Completed, e.TrackingID, e.VoyageNumber, e.Location, e.Type); err != nil {
+			s.report(ctx, m, fmt.Errorf("event %d of %d: %v", i+1, len(events), err))
+			return i, nil
+		}
+	}
+
+	return len(events), nil
+}
+
+// recognize returns the first template from the sender reporting events in
+// the mail, and the events.
+func (s *service) recognize(m Message) (Template, []Event) {
+	for _, t := range s.templates {
+		if !t.Matches(m.From) {
+			continue
+		}
+		if events, err := t.Parse(m.Body); err == nil && len(events) > 0 {
+			return t, events
+		}
+	}
+	return Template{}, nil
+}
+
+func (s *service) report(ctx context.Context, m Message, err error) {
+	if s.reporter != nil {
+		s.reporter.MailFailed(ctx, m, err)
+	}
+}
+
+// NewService creates an inbound mail service with necessary dependencies.
+// The token is shared with the mail provider; mail is refused if it is
+// empty. The reporter may be nil.
+func NewService(hs handling.Service, templates []Template, token string, reporter Reporter) Service {
+	return &service{
+		handling:  hs,
+		templates: templates,
+		token:     token,
+		reporter:  reporter,
+	}
+}
@@ -0,0 +1,159 @@
+package inbound
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/handling"
+)
+
+const templates = `[{
+	"name": "Gothenburg agent",
+	"from": "@agent.se",
+	"pattern": "(?m)^(?P<tracking_id>\\w+) (?P<type>arrived|departed) (?P<location>\\w{5}) on (?P<voyage>\\w+) at (?P<completed>.+)$",
+	"time_layout": "2006-01-02 15:04",
+	"types": {"arrived": "unload", "departed": "load"},
+	"terminal": "SEGOT-AGENT",
+	"key": "secret"
+}]`
+
+// stubHandlingService registers handling events through fn.
+type stubHandlingService struct {
+	handling.Service
+	fn func(context.Context, handling.Credentials, time.Time, shipping.TrackingID, shipping.VoyageNumber, shipping.UNLocode, shipping.HandlingEventType) error
+}
+
+func (s *stubHandlingService) RegisterHandlingEvent(ctx context.Context, source handling.Credentials, completed time.Time, id shipping.TrackingID, voyage shipping.VoyageNumber, loc shipping.UNLocode, typ shipping.HandlingEventType) error {
+	return s.fn(ctx, source, completed, id, voyage, loc, typ)
+}
+
+type recordingReporter struct {
+	failed []error
+}
+
+func (r *recordingReporter) MailFailed(ctx context.Context, m Message, err error) {
+	r.failed = append(r.failed, err)
+}
+
+func TestReceive(t *testing.T) {
+	tt, err := LoadTemplates(strings.NewReader(templates))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type registration struct {
+		source handling.Credentials
+		id     shipping.TrackingID
+		voyage shipping.VoyageNumber
+		loc    shipping.UNLocode
+		typ    shipping.HandlingEventType
+		at     time.Time
+	}
+
+	var registered []registration
+
+	var hs stubHandlingService
+	hs.fn = func(ctx context.Context, source handling.Credentials, completed time.Time, id shipping.TrackingID, voyage shipping.VoyageNumber, loc shipping.UNLocode, typ shipping.HandlingEventType) error {
+		if id == "UNKNOWN" {
+			return shipping.ErrUnknownCargo
+		}
+		registered = append(registered, registration{source, id, voyage, loc, typ, completed})
+		return nil
+	}
+
+	var reporter recordingReporter
+
+	s := NewService(&hs, tt, "token", &reporter)
+
+	m := Message{
+		From:    "Operations <ops@agent.se>",
+		Subject: "Movements",
+		Body:    "Hi,\n\nabc123 arrived SEGOT on V100 at 2016-03-02 08:00\nDEF456 departed SEGOT on V200 at 2016-03-02 17:30\n\nRegards",
+	}
+
+	if _, err := s.Receive(context.Background(), "wrong", m); err != ErrInvalidToken {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidToken)
+	}
+
+	n, err := s.Receive(context.Background(), "token", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("n = %d; want = %d", n, 2)
+	}
+
+	want := registration{
+		source: handling.Credentials{Terminal: "SEGOT-AGENT", Key: "secret"},
+		id:     "ABC123",
+		voyage: "V100",
+		loc:    "SEGOT",
+		typ:    shipping.Unload,
+		at:     time.Date(2016, time.March, 2, 8, 0, 0, 0, time.UTC),
+	}
+	if registered[0] != want {
+		t.Errorf("registered[0] = %+v; want = %+v", registered[0], want)
+	}
+	if registered[1].typ != shipping.Load {
+		t.Errorf("registered[1].typ = %s; want = %s", registered[1].typ, shipping.Load)
+	}
+	if len(reporter.failed) != 0 {
+		t.Errorf("failed = %v; want none", reporter.failed)
+	}
+
+	for _, m := range []Message{
+		{From: "someone@elsewhere.com", Body: m.Body},
+		{From: "ops@agent.se", Body: "Nothing to report today."},
+	} {
+		if n, err := s.Receive(context.Background(), "token", m); n != 0 || err != nil {
+			t.Errorf("Receive() = %d, %v; want = 0, nil", n, err)
+		}
+	}
+
+	if _, err := s.Receive(context.Background(), "token", Message{
+		From: "ops@agent.se",
+		Body: "UNKNOWN arrived SEGOT on V100 at 2016-03-02 08:00",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reporter.failed) != 3 {
+		t.Fatalf("len(failed) = %d; want = %d", len(reporter.failed), 3)
+	}
+	if reporter.failed[0] != ErrUnrecognized {
+		t.Errorf("failed[0] = %v; want = %v", reporter.failed[0], ErrUnrecognized)
+	}
+}
+
+func TestTemplateMatches(t *testing.T) {
+	for _, tt := range []struct {
+		template string
+		from     string
+		want     bool
+	}{
+		{"@agent.se", "ops@agent.se", true},
+		{"@agent.se", "Ops <OPS@Agent.se>", true},
+		{"@agent.se", "ops@notagent.se.example.com", false},
+		{"ops@agent.se", "ops@agent.se", true},
+		{"ops@agent.se", "sales@agent.se", false},
+	} {
+		if got := (Template{From: tt.template}).Matches(tt.from); got != tt.want {
+			t.Errorf("Matches(%q) with %q = %v; want = %v", tt.from, tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestLoadTemplatesInvalid(t *testing.T) {
+	for _, cfg := range []string{
+		`[{"from": "@agent.se", "pattern": "(", "time_layout": "2006"}]`,
+		`[{"from": "@agent.se", "pattern": ".", "time_layout": "2006", "types": {"arrived": "teleport"}}]`,
+		`[{"pattern": ".", "time_layout": "2006"}]`,
+	} {
+		if _, err := LoadTemplates(strings.NewReader(cfg)); err == nil {
+			t.Errorf("LoadTemplates(%s) should fail", cfg)
+		}
+	}
+}
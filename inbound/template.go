@@ -0,0 +1,177 @@
+package inbound
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/handling"
+)
+
+// Template recognizes the reports mailed by an agent. Every match of the
+// pattern in the body of a mail is a handling event, reported by the
+// terminal of the agent.
+type Template struct {
+	Name string
+
+	// From is the address of the agent, or a domain such as "@agent.com".
+	From string
+
+	// Pattern holds the named groups completed, tracking_id, location and
+	// type, and optionally voyage.
+	Pattern *regexp.Regexp
+
+	// TimeLayout is the layout of completion times, as understood by
+	// time.Parse.
+	TimeLayout string
+
+	// Types maps the words used by the agent, such as "arrived", to
+	// handling event types. Words not found are parsed as type names.
+	Types map[string]shipping.HandlingEventType
+
+	Source handling.Credentials
+}
+
+// Matches returns whether the template applies to mail from the address.
+func (t Template) Matches(from string) bool {
+	from = strings.ToLower(address(from))
+	want := strings.ToLower(t.From)
+	if strings.HasPrefix(want, "@") {
+		return strings.HasSuffix(from, want)
+	}
+	return from == want
+}
+
+// Event is a handling event, as reported in a mail.
+type Event struct {
+	Completed    time.Time
+	TrackingID   shipping.TrackingID
+	VoyageNumber shipping.VoyageNumber
+	Location     shipping.UNLocode
+	Type         shipping.HandlingEventType
+}
+
+// Parse returns the events reported in the body of a mail. Nothing is
+// returned if any match is malformed.
+func (t Template) Parse(body string) ([]Event, error) {
+	var events []Event
+	for _, m := range t.Pattern.FindAllStringSubmatch(body, -1) {
+		groups := make(map[string]string)
+		for i, name := range t.Pattern.SubexpNames() {
+			if name != "" {
+				groups[name] = strings.TrimSpace(m[i])
+			}
+		}
+
+		e, err := t.event(groups)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func (t Template) event(groups map[string]string) (Event, error) {
+	e := Event{
+		TrackingID:   shipping.TrackingID(strings.ToUpper(groups["tracking_id"])),
+		VoyageNumber: shipping.VoyageNumber(groups["voyage"]),
+		Location:     shipping.UNLocode(strings.ToUpper(groups["location"])),
+	}
+
+	if e.TrackingID == "" || e.Location == "" {
+		return e, ErrUnrecognized
+	}
+
+	var err error
+	if e.Completed, err = time.Parse(t.TimeLayout, groups["completed"]); err != nil {
+		return e, ErrUnrecognized
+	}
+
+	typ, ok := t.Types[strings.ToLower(groups["type"])]
+	if !ok {
+		if typ, err = shipping.ParseHandlingEventType(groups["type"]); err != nil {
+			return e, ErrUnrecognized
+		}
+	}
+	e.Type = typ
+
+	return e, nil
+}
+
+// address returns the address part of a sender such as
+// "Agent <ops@agent.com>".
+func address(from string) string {
+	if i := strings.LastIndex(from, "<"); i >= 0 {
+		if j := strings.Index(from[i:], ">"); j >= 0 {
+			return from[i+1 : i+j]
+		}
+	}
+	return strings.TrimSpace(from)
+}
+
+// templateConfig is a template, as configured in JSON.
+type templateConfig struct {
+	Name       string            `json:"name"`
+	From       string            `json:"from"`
+	Pattern    string            `json:"pattern"`
+	TimeLayout string            `json:"time_layout"`
+	Types      map[string]string `json:"types"`
+	Terminal   string            `json:"terminal"`
+	Key        string            `json:"key"`
+}
+
+// LoadTemplates reads templates configured as a JSON array, e.g.
+//
+//	[{
+//		"name": "Gothenburg agent",
+//		"from": "@agent.se",
+//		"pattern": "(?m)^(?P<tracking_id>\\w+) (?P<type>arrived|departed) (?P<location>\\w{5}) (?P<completed>.+)$",
+//		"time_layout": "2006-01-02 15:04",
+//		"types": {"arrived": "unload", "departed": "load"},
+//		"terminal": "SEGOT-AGENT",
+//		"key": "secret"
+//	}]
+func LoadTemplates(r io.Reader) ([]Template, error) {
+	var configs []templateConfig
+	if err := json.NewDecoder(r).Decode(&configs); err != nil {
+		return nil, err
+	}
+
+	var result []Template
+	for _, c := range configs {
+		if c.From == "" || c.Pattern == "" || c.TimeLayout == "" {
+			return nil, ErrInvalidArgument
+		}
+
+		pattern, err := regexp.Compile(c.Pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		types := make(map[string]shipping.HandlingEventType)
+		for word, name := range c.Types {
+			typ, err := shipping.ParseHandlingEventType(name)
+			if err != nil {
+				return nil, err
+			}
+			types[strings.ToLower(word)] = typ
+		}
+
+		result = append(result, Template{
+			Name:       c.Name,
+			From:       c.From,
+			Pattern:    pattern,
+			TimeLayout: c.TimeLayout,
+			Types:      types,
+			Source: handling.Credentials{
+				Terminal: shipping.TerminalID(c.Terminal),
+				Key:      c.Key,
+			},
+		})
+	}
+	return result, nil
+}
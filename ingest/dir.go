@@ -0,0 +1,68 @@
+package ingest
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Subfolders of a directory drop folder.
+const (
+	archiveDir  = "archive"
+	rejectedDir = "rejected"
+)
+
+type dirFolder struct {
+	dir string
+}
+
+func (f *dirFolder) List(ctx context.Context) ([]string, error) {
+	infos, err := ioutil.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, fi := range infos {
+		// Partners upload under a hidden name and rename the file once
+		// complete.
+		if !fi.Mode().IsRegular() || strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+		names = append(names, fi.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (f *dirFolder) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(f.dir, name))
+}
+
+func (f *dirFolder) Archive(ctx context.Context, name string) error {
+	return f.move(name, archiveDir)
+}
+
+func (f *dirFolder) Reject(ctx context.Context, name string) error {
+	return f.move(name, rejectedDir)
+}
+
+func (f *dirFolder) move(name, sub string) error {
+	dst := filepath.Join(f.dir, sub)
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join(f.dir, name), filepath.Join(dst, name))
+}
+
+// NewDirFolder returns a Folder reading the files in a local directory, such
+// as a partner's SFTP folder mounted or synced to the host. Imported files are
+// moved to the archive subdirectory, and those that failed to import to the
+// rejected subdirectory.
+func NewDirFolder(dir string) Folder {
+	return &dirFolder{dir: dir}
+}
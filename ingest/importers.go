@@ -0,0 +1,133 @@
+package ingest
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/handling"
+	"github.com/marcusolsson/goddd/portstatus"
+)
+
+// ErrInvalidFile is returned when a file is malformed.
+var ErrInvalidFile = errors.New("invalid file")
+
+// NewPortStatusImporter returns an Importer registering the port advisories
+// of a feed, as described by portstatus.Service.Import.
+func NewPortStatusImporter(s portstatus.Service) Importer {
+	return ImporterFunc(func(ctx context.Context, r io.Reader) error {
+		_, err := s.Import(ctx, r)
+		return err
+	})
+}
+
+// handlingRecord is a handling event, as reported on a line of a handling
+// file.
+type handlingRecord struct {
+	completed time.Time
+	id        shipping.TrackingID
+	voyage    shipping.VoyageNumber
+	location  shipping.UNLocode
+	eventType shipping.HandlingEventType
+}
+
+// NewHandlingImporter returns an Importer registering the handling events of
+// a file, as reported by the terminal identified by source. Every line holds
+// the completion time, tracking id, voyage, location and type of an event,
+// e.g.
+//
+//	2016-03-02T08:00:00Z,ABC123,V100,SESTO,load
+//
+// Nothing is registered if any line is malformed. Events are registered in
+// order, and registering stops at the first one refused. While the handling
+// queue is full, registering waits for it to drain, so that replaying a
+// large file does not flood it.
+func NewHandlingImporter(s handling.Service, source handling.Credentials) Importer {
+	return ImporterFunc(func(ctx context.Context, r io.Reader) error {
+		cr := csv.NewReader(r)
+		cr.Comment = '#'
+		cr.FieldsPerRecord = 5
+		cr.TrimLeadingSpace = true
+
+		rows, err := cr.ReadAll()
+		if err != nil {
+			return ErrInvalidFile
+		}
+
+		records := make([]handlingRecord, 0, len(rows))
+		for _, row := range rows {
+			rec, err := parseHandlingRecord(row)
+			if err != nil {
+				return err
+			}
+			records = append(records, rec)
+		}
+
+		for i, rec := range records {
+			if err := registerHandlingRecord(ctx, s, source, rec); err != nil {
+				return fmt.Errorf("event %d of %d: %v", i+1, len(records), err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// busyBackoff is how long registering a handling event is put off at first
+// while the handling queue is full. It doubles, up to maxBusyBackoff, for as
+// long as the queue stays full.
+var (
+	busyBackoff    = 100 * time.Millisecond
+	maxBusyBackoff = 10 * time.Second
+)
+
+// registerHandlingRecord registers a handling event, trying again for as
+// long as the handling queue is full.
+func registerHandlingRecord(ctx context.Context, s handling.Service, source handling.Credentials, rec handlingRecord) error {
+	backoff := busyBackoff
+	for {
+		err := s.RegisterHandlingEvent(ctx, source, rec.completed, rec.id, rec.voyage, rec.location, rec.eventType)
+		if err != handling.ErrBusy {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if backoff *= 2; backoff > maxBusyBackoff {
+			backoff = maxBusyBackoff
+		}
+	}
+}
+
+func parseHandlingRecord(row []string) (handlingRecord, error) {
+	var (
+		rec = handlingRecord{
+			id:       shipping.TrackingID(row[1]),
+			voyage:   shipping.VoyageNumber(row[2]),
+			location: shipping.UNLocode(row[3]),
+		}
+		err error
+	)
+
+	if rec.completed, err = time.Parse(time.RFC3339, row[0]); err != nil {
+		return rec, ErrInvalidFile
+	}
+
+	if rec.id == "" || rec.location == "" {
+		return rec, ErrInvalidFile
+	}
+
+	if rec.eventType, err = shipping.ParseHandlingEventType(row[4]); err != nil {
+		return rec, ErrInvalidFile
+	}
+
+	return rec, nil
+}
@@ -0,0 +1,107 @@
+package ingest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/handling"
+)
+
+// stubHandlingService registers handling events through fn.
+type stubHandlingService struct {
+	handling.Service
+	fn func(context.Context, handling.Credentials, time.Time, shipping.TrackingID, shipping.VoyageNumber, shipping.UNLocode, shipping.HandlingEventType) error
+}
+
+func (s *stubHandlingService) RegisterHandlingEvent(ctx context.Context, source handling.Credentials, completed time.Time, id shipping.TrackingID, voyage shipping.VoyageNumber, loc shipping.UNLocode, typ shipping.HandlingEventType) error {
+	return s.fn(ctx, source, completed, id, voyage, loc, typ)
+}
+
+func TestHandlingImporter(t *testing.T) {
+	source := handling.Credentials{Terminal: "SESTO-1", Key: "secret"}
+
+	var registered []shipping.HandlingEventType
+
+	var hs stubHandlingService
+	hs.fn = func(ctx context.Context, c handling.Credentials, completed time.Time, id shipping.TrackingID, voyage shipping.VoyageNumber, loc shipping.UNLocode, typ shipping.HandlingEventType) error {
+		if c != source {
+			t.Errorf("source = %v; want = %v", c, source)
+		}
+		if id == "UNKNOWN" {
+			return shipping.ErrUnknownCargo
+		}
+		registered = append(registered, typ)
+		return nil
+	}
+
+	imp := NewHandlingImporter(&hs, source)
+
+	file := `# completed,tracking_id,voyage,location,type
+2016-03-01T08:00:00Z,ABC123,,SESTO,receive
+2016-03-02T08:00:00Z,ABC123,V100,SESTO,load
+`
+
+	if err := imp.Import(context.Background(), strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	if len(registered) != 2 || registered[0] != shipping.Receive || registered[1] != shipping.Load {
+		t.Errorf("registered = %v; want = [Receive Load]", registered)
+	}
+
+	registered = nil
+
+	for _, file := range []string{
+		"yesterday,ABC123,V100,SESTO,load\n",
+		"2016-03-02T08:00:00Z,ABC123,V100,SESTO,teleport\n",
+		"2016-03-02T08:00:00Z,ABC123,V100,SESTO\n",
+		"2016-03-02T08:00:00Z,ABC123,V100,SESTO,load\n2016-03-02T08:00:00Z,,V100,SESTO,load\n",
+	} {
+		if err := imp.Import(context.Background(), strings.NewReader(file)); err != ErrInvalidFile {
+			t.Errorf("err = %v for %q; want = %v", err, file, ErrInvalidFile)
+		}
+	}
+	if len(registered) != 0 {
+		t.Errorf("registered = %v from malformed files; want none", registered)
+	}
+
+	if err := imp.Import(context.Background(), strings.NewReader("2016-03-02T08:00:00Z,UNKNOWN,V100,SESTO,load\n")); err == nil {
+		t.Error("expected an error for a refused event")
+	}
+}
+
+func TestHandlingImporter_Busy(t *testing.T) {
+	defer func(d time.Duration) { busyBackoff = d }(busyBackoff)
+	busyBackoff = time.Millisecond
+
+	var attempts int
+
+	var hs stubHandlingService
+	hs.fn = func(ctx context.Context, c handling.Credentials, completed time.Time, id shipping.TrackingID, voyage shipping.VoyageNumber, loc shipping.UNLocode, typ shipping.HandlingEventType) error {
+		attempts++
+		if attempts < 3 {
+			return handling.ErrBusy
+		}
+		return nil
+	}
+
+	imp := NewHandlingImporter(&hs, handling.Credentials{Terminal: "SESTO-1", Key: "secret"})
+
+	if err := imp.Import(context.Background(), strings.NewReader("2016-03-02T08:00:00Z,ABC123,V100,SESTO,load\n")); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d; want the event registered again until the queue has room", attempts)
+	}
+
+	attempts = -1000
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := imp.Import(ctx, strings.NewReader("2016-03-02T08:00:00Z,ABC123,V100,SESTO,load\n")); err == nil {
+		t.Error("expected an error once the context is done")
+	}
+}
@@ -0,0 +1,155 @@
+// Package ingest provides a poller pulling the files partners deliver to drop
+// folders and feeding them to the importers of the system.
+package ingest
+
+import (
+	"context"
+	"io"
+	"path"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+// Folder is a drop folder a partner delivers files to.
+type Folder interface {
+	// List returns the names of the files waiting in the folder.
+	List(ctx context.Context) ([]string, error)
+
+	// Open returns the contents of a file.
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+
+	// Archive moves a file that has been imported out of the folder.
+	Archive(ctx context.Context, name string) error
+
+	// Reject moves a file that failed to import out of the folder, so that
+	// it is not picked up again until delivered anew.
+	Reject(ctx context.Context, name string) error
+}
+
+// Importer imports the contents of a file.
+type Importer interface {
+	Import(ctx context.Context, r io.Reader) error
+}
+
+// ImporterFunc is an adapter allowing the use of an ordinary function as an
+// Importer.
+type ImporterFunc func(ctx context.Context, r io.Reader) error
+
+// Import calls f(ctx, r).
+func (f ImporterFunc) Import(ctx context.Context, r io.Reader) error {
+	return f(ctx, r)
+}
+
+// Rule feeds the files with names matching a pattern, as matched by
+// path.Match, to an importer.
+type Rule struct {
+	Pattern  string
+	Importer Importer
+}
+
+// Source is the drop folder of a partner. Each file is fed to the importer
+// of the first rule matching it. Files matching no rule are left in place.
+type Source struct {
+	Name   string
+	Folder Folder
+	Rules  []Rule
+}
+
+// Reporter is told about the files that failed to import.
+type Reporter interface {
+	FileFailed(ctx context.Context, source, name string, err error)
+}
+
+// Poller ingests the files waiting in a number of drop folders.
+type Poller struct {
+	sources  []Source
+	reporter Reporter
+	logger   log.Logger
+}
+
+// NewPoller returns a new instance of a Poller. The reporter may be nil.
+func NewPoller(logger log.Logger, reporter Reporter, sources ...Source) *Poller {
+	return &Poller{
+		sources:  sources,
+		reporter: reporter,
+		logger:   logger,
+	}
+}
+
+// Run polls the drop folders every interval until the context is done.
+func (p *Poller) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		p.Poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Poll ingests the files waiting in every drop folder once, and returns how
+// many were imported.
+func (p *Poller) Poll(ctx context.Context) int {
+	var n int
+	for _, src := range p.sources {
+		names, err := src.Folder.List(ctx)
+		if err != nil {
+			p.logger.Log("source", src.Name, "err", err)
+			continue
+		}
+
+		for _, name := range names {
+			imp := src.importer(name)
+			if imp == nil {
+				continue
+			}
+
+			if err := p.ingest(ctx, src, name, imp); err != nil {
+				p.logger.Log("source", src.Name, "file", name, "err", err)
+				if p.reporter != nil {
+					p.reporter.FileFailed(ctx, src.Name, name, err)
+				}
+				if err := src.Folder.Reject(ctx, name); err != nil {
+					p.logger.Log("source", src.Name, "file", name, "err", err)
+				}
+				continue
+			}
+
+			if err := src.Folder.Archive(ctx, name); err != nil {
+				p.logger.Log("source", src.Name, "file", name, "err", err)
+				continue
+			}
+
+			p.logger.Log("source", src.Name, "file", name, "msg", "imported")
+			n++
+		}
+	}
+	return n
+}
+
+func (p *Poller) ingest(ctx context.Context, src Source, name string, imp Importer) error {
+	rc, err := src.Folder.Open(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return imp.Import(ctx, rc)
+}
+
+// importer returns the importer of the first rule matching the file name, or
+// nil if none does.
+func (s Source) importer(name string) Importer {
+	for _, r := range s.Rules {
+		if ok, _ := path.Match(r.Pattern, name); ok {
+			return r.Importer
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+type recordingReporter struct {
+	failed []string
+}
+
+func (r *recordingReporter) FileFailed(ctx context.Context, source, name string, err error) {
+	r.failed = append(r.failed, source+"/"+name)
+}
+
+func TestPoll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ingest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, contents := range map[string]string{
+		"a.csv":            "good",
+		"b.csv":            "bad",
+		"notes.txt":        "ignored",
+		".c.csv.uploading": "partial",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var imported []string
+	imp := ImporterFunc(func(ctx context.Context, r io.Reader) error {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		if string(b) == "bad" {
+			return errors.New("bad file")
+		}
+		imported = append(imported, string(b))
+		return nil
+	})
+
+	var reporter recordingReporter
+
+	p := NewPoller(log.NewNopLogger(), &reporter, Source{
+		Name:   "partner",
+		Folder: NewDirFolder(dir),
+		Rules:  []Rule{{Pattern: "*.csv", Importer: imp}},
+	})
+
+	if n := p.Poll(context.Background()); n != 1 {
+		t.Errorf("Poll() = %d; want = %d", n, 1)
+	}
+
+	if want := []string{"good"}; !reflect.DeepEqual(imported, want) {
+		t.Errorf("imported = %v; want = %v", imported, want)
+	}
+	if want := []string{"partner/b.csv"}; !reflect.DeepEqual(reporter.failed, want) {
+		t.Errorf("failed = %v; want = %v", reporter.failed, want)
+	}
+
+	for _, name := range []string{
+		filepath.Join("archive", "a.csv"),
+		filepath.Join("rejected", "b.csv"),
+		"notes.txt",
+		".c.csv.uploading",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	// Nothing is left to import.
+	if n := p.Poll(context.Background()); n != 0 {
+		t.Errorf("Poll() = %d; want = %d", n, 0)
+	}
+}
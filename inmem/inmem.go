@@ -2,7 +2,10 @@
 package inmem
 
 import (
+	"sort"
+	"strconv"
 	"sync"
+	"time"
 
 	shipping "github.com/marcusolsson/goddd"
 )
@@ -38,6 +41,27 @@ func (r *cargoRepository) FindAll() []*shipping.Cargo {
 	return c
 }
 
+func (r *cargoRepository) ForEach(fn func(*shipping.Cargo) error) error {
+	for _, c := range r.FindAll() {
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *cargoRepository) FindMatching(q shipping.CargoQuery) ([]*shipping.Cargo, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var result []*shipping.Cargo
+	for _, c := range r.cargos {
+		if q.Matches(c) {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
 // NewCargoRepository returns a new instance of a in-memory cargo repository.
 func NewCargoRepository() shipping.CargoRepository {
 	return &cargoRepository{
@@ -46,10 +70,20 @@ func NewCargoRepository() shipping.CargoRepository {
 }
 
 type locationRepository struct {
+	mtx       sync.RWMutex
 	locations map[shipping.UNLocode]*shipping.Location
 }
 
+func (r *locationRepository) Store(l *shipping.Location) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.locations[l.UNLocode] = l
+	return nil
+}
+
 func (r *locationRepository) Find(locode shipping.UNLocode) (*shipping.Location, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
 	if l, ok := r.locations[locode]; ok {
 		return l, nil
 	}
@@ -57,6 +91,8 @@ func (r *locationRepository) Find(locode shipping.UNLocode) (*shipping.Location,
 }
 
 func (r *locationRepository) FindAll() []*shipping.Location {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
 	l := make([]*shipping.Location, 0, len(r.locations))
 	for _, val := range r.locations {
 		l = append(l, val)
@@ -81,10 +117,20 @@ func NewLocationRepository() shipping.LocationRepository {
 }
 
 type voyageRepository struct {
+	mtx     sync.RWMutex
 	voyages map[shipping.VoyageNumber]*shipping.Voyage
 }
 
+func (r *voyageRepository) Store(v *shipping.Voyage) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.voyages[v.VoyageNumber] = v
+	return nil
+}
+
 func (r *voyageRepository) Find(voyageNumber shipping.VoyageNumber) (*shipping.Voyage, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
 	if v, ok := r.voyages[voyageNumber]; ok {
 		return v, nil
 	}
@@ -92,6 +138,30 @@ func (r *voyageRepository) Find(voyageNumber shipping.VoyageNumber) (*shipping.V
 	return nil, shipping.ErrUnknownVoyage
 }
 
+func (r *voyageRepository) FindAll() []*shipping.Voyage {
+	return r.find(func(*shipping.Voyage) bool { return true })
+}
+
+func (r *voyageRepository) FindCallingAt(locode shipping.UNLocode) []*shipping.Voyage {
+	return r.find(func(v *shipping.Voyage) bool { return v.Schedule.CallsAt(locode) })
+}
+
+// find returns the voyages matching a predicate, ordered by voyage number.
+func (r *voyageRepository) find(match func(*shipping.Voyage) bool) []*shipping.Voyage {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var result []*shipping.Voyage
+	for _, v := range r.voyages {
+		if match(v) {
+			result = append(result, v)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].VoyageNumber < result[j].VoyageNumber
+	})
+	return result
+}
+
 // NewVoyageRepository returns a new instance of a in-memory voyage repository.
 func NewVoyageRepository() shipping.VoyageRepository {
 	r := &voyageRepository{
@@ -111,6 +181,834 @@ func NewVoyageRepository() shipping.VoyageRepository {
 	return r
 }
 
+type serviceStringRepository struct {
+	mtx      sync.RWMutex
+	services map[shipping.ServiceCode]*shipping.ServiceString
+}
+
+func (r *serviceStringRepository) Store(s *shipping.ServiceString) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.services[s.Code] = s
+	return nil
+}
+
+func (r *serviceStringRepository) Find(code shipping.ServiceCode) (*shipping.ServiceString, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if s, ok := r.services[code]; ok {
+		return s, nil
+	}
+	return nil, shipping.ErrUnknownServiceString
+}
+
+func (r *serviceStringRepository) FindAll() []*shipping.ServiceString {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	result := make([]*shipping.ServiceString, 0, len(r.services))
+	for _, s := range r.services {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code < result[j].Code
+	})
+	return result
+}
+
+// NewServiceStringRepository returns a new instance of a in-memory service
+// string repository.
+func NewServiceStringRepository() shipping.ServiceStringRepository {
+	r := &serviceStringRepository{
+		services: make(map[shipping.ServiceCode]*shipping.ServiceString),
+	}
+
+	r.services[shipping.TP1.Code] = shipping.TP1
+	r.services[shipping.AE1.Code] = shipping.AE1
+	r.services[shipping.NE1.Code] = shipping.NE1
+
+	return r
+}
+
+type portStatusRepository struct {
+	mtx      sync.RWMutex
+	statuses map[shipping.UNLocode]shipping.PortStatus
+}
+
+func (r *portStatusRepository) Store(s shipping.PortStatus) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.statuses[s.Location] = s
+	return nil
+}
+
+func (r *portStatusRepository) FindAll() []shipping.PortStatus {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	result := make([]shipping.PortStatus, 0, len(r.statuses))
+	for _, s := range r.statuses {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Location < result[j].Location
+	})
+	return result
+}
+
+// NewPortStatusRepository returns a new instance of a in-memory port status
+// repository.
+func NewPortStatusRepository() shipping.PortStatusRepository {
+	return &portStatusRepository{
+		statuses: make(map[shipping.UNLocode]shipping.PortStatus),
+	}
+}
+
+type terminalRepository struct {
+	mtx       sync.RWMutex
+	terminals map[shipping.TerminalID]*shipping.Terminal
+}
+
+func (r *terminalRepository) Store(t *shipping.Terminal) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.terminals[t.ID] = t
+	return nil
+}
+
+func (r *terminalRepository) Find(id shipping.TerminalID) (*shipping.Terminal, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if t, ok := r.terminals[id]; ok {
+		return t, nil
+	}
+	return nil, shipping.ErrUnknownTerminal
+}
+
+func (r *terminalRepository) FindAll() []*shipping.Terminal {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	result := make([]*shipping.Terminal, 0, len(r.terminals))
+	for _, t := range r.terminals {
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// NewTerminalRepository returns a new instance of a in-memory terminal
+// repository.
+func NewTerminalRepository() shipping.TerminalRepository {
+	return &terminalRepository{
+		terminals: make(map[shipping.TerminalID]*shipping.Terminal),
+	}
+}
+
+// nonceSweepInterval is how often expired nonces are forgotten.
+const nonceSweepInterval = time.Minute
+
+type reportNonceRepository struct {
+	mtx       sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+}
+
+func (r *reportNonceRepository) Add(nonce string, now, expires time.Time) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	// Expired nonces are forgotten now and then, so that the repository
+	// does not grow with every report ever received.
+	if now.Sub(r.lastSweep) >= nonceSweepInterval {
+		r.lastSweep = now
+		for n, t := range r.nonces {
+			if !t.After(now) {
+				delete(r.nonces, n)
+			}
+		}
+	}
+
+	if t, ok := r.nonces[nonce]; ok && t.After(now) {
+		return shipping.ErrNonceUsed
+	}
+	r.nonces[nonce] = expires
+	return nil
+}
+
+func (r *reportNonceRepository) Remove(nonce string) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	delete(r.nonces, nonce)
+	return nil
+}
+
+// NewReportNonceRepository returns a new instance of a in-memory report
+// nonce repository.
+func NewReportNonceRepository() shipping.ReportNonceRepository {
+	return &reportNonceRepository{
+		nonces: make(map[string]time.Time),
+	}
+}
+
+type customerRepository struct {
+	mtx       sync.RWMutex
+	customers map[shipping.CustomerID]*shipping.Customer
+}
+
+func (r *customerRepository) Store(c *shipping.Customer) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.customers[c.ID] = c
+	return nil
+}
+
+func (r *customerRepository) Find(id shipping.CustomerID) (*shipping.Customer, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if c, ok := r.customers[id]; ok {
+		return c, nil
+	}
+	return nil, shipping.ErrUnknownCustomer
+}
+
+// NewCustomerRepository returns a new instance of a in-memory customer
+// repository.
+func NewCustomerRepository() shipping.CustomerRepository {
+	return &customerRepository{
+		customers: make(map[shipping.CustomerID]*shipping.Customer),
+	}
+}
+
+type slaRepository struct {
+	mtx  sync.RWMutex
+	slas map[shipping.ServiceLevel]*shipping.SLA
+}
+
+func (r *slaRepository) Store(s *shipping.SLA) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.slas[s.Level] = s
+	return nil
+}
+
+func (r *slaRepository) Find(l shipping.ServiceLevel) (*shipping.SLA, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if s, ok := r.slas[l]; ok {
+		return s, nil
+	}
+	return nil, shipping.ErrUnknownServiceLevel
+}
+
+func (r *slaRepository) FindAll() []*shipping.SLA {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	result := make([]*shipping.SLA, 0, len(r.slas))
+	for _, s := range r.slas {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Level < result[j].Level
+	})
+	return result
+}
+
+// NewSLARepository returns a new instance of a in-memory SLA repository.
+func NewSLARepository() shipping.SLARepository {
+	return &slaRepository{
+		slas: make(map[shipping.ServiceLevel]*shipping.SLA),
+	}
+}
+
+type userRepository struct {
+	mtx   sync.RWMutex
+	users map[shipping.UserID]*shipping.User
+}
+
+func (r *userRepository) Store(u *shipping.User) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.users[u.ID] = u
+	return nil
+}
+
+func (r *userRepository) Find(id shipping.UserID) (*shipping.User, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, shipping.ErrUnknownUser
+}
+
+func (r *userRepository) FindAll() []*shipping.User {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	result := make([]*shipping.User, 0, len(r.users))
+	for _, u := range r.users {
+		result = append(result, u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// NewUserRepository returns a new instance of a in-memory user repository.
+func NewUserRepository() shipping.UserRepository {
+	return &userRepository{
+		users: make(map[shipping.UserID]*shipping.User),
+	}
+}
+
+type amendmentRepository struct {
+	mtx        sync.RWMutex
+	amendments map[shipping.AmendmentID]*shipping.Amendment
+}
+
+func (r *amendmentRepository) Store(a *shipping.Amendment) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.amendments[a.ID] = a
+	return nil
+}
+
+func (r *amendmentRepository) Find(id shipping.AmendmentID) (*shipping.Amendment, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if a, ok := r.amendments[id]; ok {
+		return a, nil
+	}
+	return nil, shipping.ErrUnknownAmendment
+}
+
+func (r *amendmentRepository) FindByCargo(id shipping.TrackingID) []*shipping.Amendment {
+	return r.find(func(a *shipping.Amendment) bool { return a.TrackingID == id })
+}
+
+func (r *amendmentRepository) FindPending() []*shipping.Amendment {
+	return r.find(func(a *shipping.Amendment) bool { return a.Status == shipping.AmendmentPending })
+}
+
+// find returns the amendments matching a predicate, in the order they were
+// requested.
+func (r *amendmentRepository) find(match func(*shipping.Amendment) bool) []*shipping.Amendment {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var result []*shipping.Amendment
+	for _, a := range r.amendments {
+		if match(a) {
+			result = append(result, a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Requested.Before(result[j].Requested)
+	})
+	return result
+}
+
+// NewAmendmentRepository returns a new instance of a in-memory amendment
+// repository.
+func NewAmendmentRepository() shipping.AmendmentRepository {
+	return &amendmentRepository{
+		amendments: make(map[shipping.AmendmentID]*shipping.Amendment),
+	}
+}
+
+type taskRepository struct {
+	mtx   sync.RWMutex
+	tasks map[shipping.TaskID]*shipping.Task
+}
+
+func (r *taskRepository) Store(t *shipping.Task) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.tasks[t.ID] = t
+	return nil
+}
+
+func (r *taskRepository) Find(id shipping.TaskID) (*shipping.Task, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if t, ok := r.tasks[id]; ok {
+		return t, nil
+	}
+	return nil, shipping.ErrUnknownTask
+}
+
+func (r *taskRepository) FindByCargo(id shipping.TrackingID) []*shipping.Task {
+	return r.find(func(t *shipping.Task) bool { return t.TrackingID == id })
+}
+
+func (r *taskRepository) FindAll() []*shipping.Task {
+	return r.find(func(t *shipping.Task) bool { return true })
+}
+
+// find returns the tasks matching a predicate, in the order they were
+// opened.
+func (r *taskRepository) find(match func(*shipping.Task) bool) []*shipping.Task {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var result []*shipping.Task
+	for _, t := range r.tasks {
+		if match(t) {
+			result = append(result, t)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Opened.Before(result[j].Opened)
+	})
+	return result
+}
+
+// NewTaskRepository returns a new instance of a in-memory task repository.
+func NewTaskRepository() shipping.TaskRepository {
+	return &taskRepository{
+		tasks: make(map[shipping.TaskID]*shipping.Task),
+	}
+}
+
+type auditRepository struct {
+	mtx     sync.RWMutex
+	entries []*shipping.AuditEntry
+}
+
+func (r *auditRepository) Append(e *shipping.AuditEntry) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if n := len(r.entries); n > 0 && r.entries[n-1].Sequence >= e.Sequence {
+		return shipping.ErrAuditConflict
+	}
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func (r *auditRepository) Last() (*shipping.AuditEntry, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if len(r.entries) == 0 {
+		return nil, nil
+	}
+	return r.entries[len(r.entries)-1], nil
+}
+
+func (r *auditRepository) Find(q shipping.AuditQuery) []*shipping.AuditEntry {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var result []*shipping.AuditEntry
+	for _, e := range r.entries {
+		if q.Matches(e) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// NewAuditRepository returns a new instance of a in-memory audit repository.
+func NewAuditRepository() shipping.AuditRepository {
+	return &auditRepository{}
+}
+
+type cargoChangeRepository struct {
+	mtx     sync.RWMutex
+	changes []*shipping.CargoChange
+}
+
+func (r *cargoChangeRepository) Append(c *shipping.CargoChange) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	c.Sequence = uint64(len(r.changes)) + 1
+	r.changes = append(r.changes, c)
+	return nil
+}
+
+func (r *cargoChangeRepository) FindSince(seq uint64, limit int) []*shipping.CargoChange {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if seq >= uint64(len(r.changes)) {
+		return []*shipping.CargoChange{}
+	}
+	result := r.changes[seq:]
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return append([]*shipping.CargoChange(nil), result...)
+}
+
+func (r *cargoChangeRepository) FindByCargo(id shipping.TrackingID) []*shipping.CargoChange {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	result := []*shipping.CargoChange{}
+	for _, c := range r.changes {
+		if c.TrackingID == id {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// NewCargoChangeRepository returns a new instance of a in-memory cargo
+// change repository.
+func NewCargoChangeRepository() shipping.CargoChangeRepository {
+	return &cargoChangeRepository{}
+}
+
+type lanePerformanceRepository struct {
+	mtx    sync.RWMutex
+	cargos map[shipping.TrackingID]map[shipping.Lane]shipping.LanePerformance
+	totals map[shipping.Lane]shipping.LanePerformance
+}
+
+func (r *lanePerformanceRepository) Store(id shipping.TrackingID, lanes map[shipping.Lane]shipping.LanePerformance) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	// Take back what the cargo added before, so that the totals need not
+	// be summed up again.
+	for k, p := range r.cargos[id] {
+		t := r.totals[k]
+		t.Sailed -= p.Sailed
+		t.OnTime -= p.OnTime
+		t.Misrouted -= p.Misrouted
+		t.TotalDelay -= p.TotalDelay
+		if t.Sailed == 0 {
+			delete(r.totals, k)
+		} else {
+			r.totals[k] = t
+		}
+	}
+
+	stored := make(map[shipping.Lane]shipping.LanePerformance, len(lanes))
+	for k, p := range lanes {
+		stored[k] = p
+		t := r.totals[k]
+		t.Merge(p)
+		r.totals[k] = t
+	}
+	r.cargos[id] = stored
+
+	return nil
+}
+
+func (r *lanePerformanceRepository) FindAll() map[shipping.Lane]shipping.LanePerformance {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	result := make(map[shipping.Lane]shipping.LanePerformance, len(r.totals))
+	for k, p := range r.totals {
+		result[k] = p
+	}
+	return result
+}
+
+func (r *lanePerformanceRepository) Clear() error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.cargos = make(map[shipping.TrackingID]map[shipping.Lane]shipping.LanePerformance)
+	r.totals = make(map[shipping.Lane]shipping.LanePerformance)
+	return nil
+}
+
+// NewLanePerformanceRepository returns a new instance of a in-memory lane
+// performance repository.
+func NewLanePerformanceRepository() shipping.LanePerformanceRepository {
+	return &lanePerformanceRepository{
+		cargos: make(map[shipping.TrackingID]map[shipping.Lane]shipping.LanePerformance),
+		totals: make(map[shipping.Lane]shipping.LanePerformance),
+	}
+}
+
+type projectionCheckpointRepository struct {
+	mtx         sync.RWMutex
+	checkpoints map[string]uint64
+}
+
+func (r *projectionCheckpointRepository) Store(projection string, seq uint64) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.checkpoints[projection] = seq
+	return nil
+}
+
+func (r *projectionCheckpointRepository) Find(projection string) (uint64, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.checkpoints[projection], nil
+}
+
+// NewProjectionCheckpointRepository returns a new instance of a in-memory
+// projection checkpoint repository.
+func NewProjectionCheckpointRepository() shipping.ProjectionCheckpointRepository {
+	return &projectionCheckpointRepository{checkpoints: make(map[string]uint64)}
+}
+
+type replicationLogRepository struct {
+	mtx     sync.RWMutex
+	entries []*shipping.ReplicationEntry
+}
+
+func (r *replicationLogRepository) Append(e *shipping.ReplicationEntry) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	e.Sequence = uint64(len(r.entries)) + 1
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func (r *replicationLogRepository) FindSince(seq uint64, limit int) []*shipping.ReplicationEntry {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if seq >= uint64(len(r.entries)) {
+		return []*shipping.ReplicationEntry{}
+	}
+	result := r.entries[seq:]
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return append([]*shipping.ReplicationEntry(nil), result...)
+}
+
+func (r *replicationLogRepository) Last() (uint64, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return uint64(len(r.entries)), nil
+}
+
+// NewReplicationLogRepository returns a new instance of a in-memory
+// replication log repository.
+func NewReplicationLogRepository() shipping.ReplicationLogRepository {
+	return &replicationLogRepository{}
+}
+
+type workingCalendarRepository struct {
+	mtx       sync.RWMutex
+	calendars map[shipping.UNLocode]*shipping.WorkingCalendar
+}
+
+func (r *workingCalendarRepository) Store(c *shipping.WorkingCalendar) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.calendars[c.Location] = c
+	return nil
+}
+
+func (r *workingCalendarRepository) Find(locode shipping.UNLocode) (*shipping.WorkingCalendar, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if c, ok := r.calendars[locode]; ok {
+		return c, nil
+	}
+	return nil, shipping.ErrUnknownCalendar
+}
+
+func (r *workingCalendarRepository) FindAll() []*shipping.WorkingCalendar {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	result := make([]*shipping.WorkingCalendar, 0, len(r.calendars))
+	for _, c := range r.calendars {
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Location < result[j].Location
+	})
+	return result
+}
+
+// NewWorkingCalendarRepository returns a new instance of a in-memory working
+// calendar repository.
+func NewWorkingCalendarRepository() shipping.WorkingCalendarRepository {
+	return &workingCalendarRepository{
+		calendars: make(map[shipping.UNLocode]*shipping.WorkingCalendar),
+	}
+}
+
+type vesselPositionRepository struct {
+	mtx       sync.RWMutex
+	positions map[shipping.VoyageNumber][]shipping.VesselPosition
+}
+
+func (r *vesselPositionRepository) Store(p shipping.VesselPosition) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	ps := append(r.positions[p.VoyageNumber], p)
+	sort.SliceStable(ps, func(i, j int) bool {
+		return ps[i].Reported.Before(ps[j].Reported)
+	})
+	r.positions[p.VoyageNumber] = ps
+	return nil
+}
+
+func (r *vesselPositionRepository) FindByVoyage(v shipping.VoyageNumber, limit int) []shipping.VesselPosition {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	ps := r.positions[v]
+	result := make([]shipping.VesselPosition, 0, limit)
+	for i := len(ps) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, ps[i])
+	}
+	return result
+}
+
+// NewVesselPositionRepository returns a new instance of a in-memory vessel
+// position repository.
+func NewVesselPositionRepository() shipping.VesselPositionRepository {
+	return &vesselPositionRepository{
+		positions: make(map[shipping.VoyageNumber][]shipping.VesselPosition),
+	}
+}
+
+type telemetryRepository struct {
+	mtx      sync.RWMutex
+	readings map[shipping.TrackingID][]shipping.SensorReading
+}
+
+func (r *telemetryRepository) Store(sr shipping.SensorReading) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	rs := append(r.readings[sr.TrackingID], sr)
+	sort.SliceStable(rs, func(i, j int) bool {
+		return rs[i].Recorded.Before(rs[j].Recorded)
+	})
+	r.readings[sr.TrackingID] = rs
+	return nil
+}
+
+func (r *telemetryRepository) Find(id shipping.TrackingID, sensor shipping.SensorType, from, to time.Time) []shipping.SensorReading {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	result := []shipping.SensorReading{}
+	for _, sr := range r.readings[id] {
+		if sensor != "" && sr.Sensor != sensor {
+			continue
+		}
+		if !from.IsZero() && sr.Recorded.Before(from) || !to.IsZero() && sr.Recorded.After(to) {
+			continue
+		}
+		result = append(result, sr)
+	}
+	return result
+}
+
+// NewTelemetryRepository returns a new instance of a in-memory telemetry
+// repository.
+func NewTelemetryRepository() shipping.TelemetryRepository {
+	return &telemetryRepository{
+		readings: make(map[shipping.TrackingID][]shipping.SensorReading),
+	}
+}
+
+type bookingDraftRepository struct {
+	mtx    sync.RWMutex
+	drafts map[shipping.DraftID]*shipping.BookingDraft
+}
+
+func (r *bookingDraftRepository) Store(d *shipping.BookingDraft) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.drafts[d.ID] = d
+	return nil
+}
+
+func (r *bookingDraftRepository) Find(id shipping.DraftID) (*shipping.BookingDraft, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if d, ok := r.drafts[id]; ok {
+		return d, nil
+	}
+	return nil, shipping.ErrUnknownDraft
+}
+
+func (r *bookingDraftRepository) FindByCustomer(id shipping.CustomerID) []*shipping.BookingDraft {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var result []*shipping.BookingDraft
+	for _, d := range r.drafts {
+		if d.Customer == id {
+			result = append(result, d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Saved.After(result[j].Saved)
+	})
+	return result
+}
+
+func (r *bookingDraftRepository) Remove(id shipping.DraftID) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, ok := r.drafts[id]; !ok {
+		return shipping.ErrUnknownDraft
+	}
+	delete(r.drafts, id)
+	return nil
+}
+
+// NewBookingDraftRepository returns a new instance of a in-memory booking
+// draft repository.
+func NewBookingDraftRepository() shipping.BookingDraftRepository {
+	return &bookingDraftRepository{
+		drafts: make(map[shipping.DraftID]*shipping.BookingDraft),
+	}
+}
+
+type handlingVarianceRepository struct {
+	mtx       sync.RWMutex
+	variances []shipping.HandlingVariance
+}
+
+func (r *handlingVarianceRepository) Store(v shipping.HandlingVariance) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.variances = append(r.variances, v)
+	return nil
+}
+
+func (r *handlingVarianceRepository) FindSince(t time.Time) []shipping.HandlingVariance {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	result := []shipping.HandlingVariance{}
+	for _, v := range r.variances {
+		if !v.Completed.Before(t) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// NewHandlingVarianceRepository returns a new instance of a in-memory
+// handling variance repository.
+func NewHandlingVarianceRepository() shipping.HandlingVarianceRepository {
+	return &handlingVarianceRepository{}
+}
+
+type releaseCodeRepository struct {
+	mtx   sync.RWMutex
+	codes map[shipping.TrackingID]shipping.ReleaseCode
+}
+
+func (r *releaseCodeRepository) Store(c *shipping.ReleaseCode) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.codes[c.TrackingID] = *c
+	return nil
+}
+
+func (r *releaseCodeRepository) Find(id shipping.TrackingID) (*shipping.ReleaseCode, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if c, ok := r.codes[id]; ok {
+		return &c, nil
+	}
+	return nil, shipping.ErrNoReleaseCode
+}
+
+// NewReleaseCodeRepository returns a new instance of a in-memory release
+// code repository.
+func NewReleaseCodeRepository() shipping.ReleaseCodeRepository {
+	return &releaseCodeRepository{
+		codes: make(map[shipping.TrackingID]shipping.ReleaseCode),
+	}
+}
+
 type handlingEventRepository struct {
 	mtx    sync.RWMutex
 	events map[shipping.TrackingID][]shipping.HandlingEvent
@@ -132,6 +1030,63 @@ func (r *handlingEventRepository) QueryHandlingHistory(id shipping.TrackingID) s
 	return shipping.HandlingHistory{HandlingEvents: r.events[id]}
 }
 
+// QueryHandlingHistoryPage uses the position within the history of the cargo
+// as cursor.
+func (r *handlingEventRepository) QueryHandlingHistoryPage(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+	start := 0
+	if q.Cursor != "" {
+		n, err := strconv.Atoi(q.Cursor)
+		if err != nil || n < 0 {
+			return shipping.HandlingHistoryPage{}, shipping.ErrInvalidCursor
+		}
+		start = n
+	}
+
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	events := r.events[id]
+
+	var page shipping.HandlingHistoryPage
+	for i := start; i < len(events); i++ {
+		if !q.Includes(events[i]) {
+			continue
+		}
+		if q.Limit > 0 && len(page.HandlingEvents) == q.Limit {
+			page.NextCursor = strconv.Itoa(i)
+			break
+		}
+		page.HandlingEvents = append(page.HandlingEvents, events[i])
+	}
+	return page, nil
+}
+
+func (r *handlingEventRepository) QueryHandlingHistories(ids []shipping.TrackingID) map[shipping.TrackingID]shipping.HandlingHistory {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	h := make(map[shipping.TrackingID]shipping.HandlingHistory, len(ids))
+	for _, id := range ids {
+		h[id] = shipping.HandlingHistory{HandlingEvents: r.events[id]}
+	}
+	return h
+}
+
+func (r *handlingEventRepository) ForEach(fn func(shipping.HandlingEvent) error) error {
+	r.mtx.RLock()
+	var events []shipping.HandlingEvent
+	for _, e := range r.events {
+		events = append(events, e...)
+	}
+	r.mtx.RUnlock()
+
+	for _, e := range events {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // NewHandlingEventRepository returns a new instance of a in-memory handling event repository.
 func NewHandlingEventRepository() shipping.HandlingEventRepository {
 	return &handlingEventRepository{
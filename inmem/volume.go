@@ -0,0 +1,142 @@
+package inmem
+
+import (
+	"sort"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// volumer is implemented by the repositories that grow with traffic.
+type volumer interface {
+	volume() (records int, oldest time.Time)
+}
+
+func (r *cargoRepository) volume() (int, time.Time) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return len(r.cargos), time.Time{}
+}
+
+func (r *handlingEventRepository) volume() (int, time.Time) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var (
+		n      int
+		oldest time.Time
+	)
+	for _, events := range r.events {
+		n += len(events)
+		for _, e := range events {
+			oldest = earliest(oldest, e.Registered)
+		}
+	}
+	return n, oldest
+}
+
+func (r *auditRepository) volume() (int, time.Time) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if len(r.entries) == 0 {
+		return 0, time.Time{}
+	}
+	return len(r.entries), r.entries[0].Time
+}
+
+func (r *cargoChangeRepository) volume() (int, time.Time) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if len(r.changes) == 0 {
+		return 0, time.Time{}
+	}
+	return len(r.changes), r.changes[0].Time
+}
+
+func (r *replicationLogRepository) volume() (int, time.Time) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if len(r.entries) == 0 {
+		return 0, time.Time{}
+	}
+	return len(r.entries), r.entries[0].Time
+}
+
+func (r *taskRepository) volume() (int, time.Time) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var oldest time.Time
+	for _, t := range r.tasks {
+		oldest = earliest(oldest, t.Opened)
+	}
+	return len(r.tasks), oldest
+}
+
+func (r *telemetryRepository) volume() (int, time.Time) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var (
+		n      int
+		oldest time.Time
+	)
+	for _, rs := range r.readings {
+		n += len(rs)
+		if len(rs) > 0 {
+			oldest = earliest(oldest, rs[0].Recorded)
+		}
+	}
+	return n, oldest
+}
+
+func (r *handlingVarianceRepository) volume() (int, time.Time) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var oldest time.Time
+	for _, v := range r.variances {
+		oldest = earliest(oldest, v.Completed)
+	}
+	return len(r.variances), oldest
+}
+
+// earliest returns the earlier of two times, ignoring zero times.
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || !b.IsZero() && b.Before(a) {
+		return b
+	}
+	return a
+}
+
+type volumeReporter struct {
+	repos map[string]volumer
+}
+
+// Volumes reports the number of records held by each repository, and when
+// the oldest was stored where the records are timestamped. Records held in
+// memory take up no storage to plan for, so no sizes are reported.
+func (r *volumeReporter) Volumes() ([]shipping.RepositoryVolume, error) {
+	result := make([]shipping.RepositoryVolume, 0, len(r.repos))
+	for name, repo := range r.repos {
+		n, oldest := repo.volume()
+		result = append(result, shipping.RepositoryVolume{
+			Repository: name,
+			Records:    n,
+			Oldest:     oldest,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Repository < result[j].Repository
+	})
+	return result, nil
+}
+
+// NewVolumeReporter returns a reporter of the volumes of in-memory
+// repositories, by name. Of the repositories given, only those growing with
+// traffic, such as the cargo and handling event repositories, are reported.
+func NewVolumeReporter(repos map[string]interface{}) shipping.VolumeReporter {
+	r := &volumeReporter{repos: make(map[string]volumer)}
+	for name, repo := range repos {
+		if v, ok := repo.(volumer); ok {
+			r.repos[name] = v
+		}
+	}
+	return r
+}
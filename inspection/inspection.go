@@ -2,31 +2,64 @@
 package inspection
 
 import (
+	"context"
+	"time"
+
 	shipping "github.com/marcusolsson/goddd"
 )
 
 // EventHandler provides means of subscribing to inspection events.
 type EventHandler interface {
-	CargoWasMisdirected(*shipping.Cargo)
-	CargoHasArrived(*shipping.Cargo)
+	CargoWasMisdirected(context.Context, *shipping.Cargo)
+	CargoHasArrived(context.Context, *shipping.Cargo)
+
+	// CargoDeadlineAtRisk is published when a cargo is first projected to
+	// arrive after its arrival deadline, along with the projected slip.
+	CargoDeadlineAtRisk(ctx context.Context, c *shipping.Cargo, slip time.Duration)
+}
+
+// EventHandlers notifies several event handlers in order.
+type EventHandlers []EventHandler
+
+// CargoWasMisdirected notifies every handler.
+func (hs EventHandlers) CargoWasMisdirected(ctx context.Context, c *shipping.Cargo) {
+	for _, h := range hs {
+		h.CargoWasMisdirected(ctx, c)
+	}
+}
+
+// CargoHasArrived notifies every handler.
+func (hs EventHandlers) CargoHasArrived(ctx context.Context, c *shipping.Cargo) {
+	for _, h := range hs {
+		h.CargoHasArrived(ctx, c)
+	}
+}
+
+// CargoDeadlineAtRisk notifies every handler.
+func (hs EventHandlers) CargoDeadlineAtRisk(ctx context.Context, c *shipping.Cargo, slip time.Duration) {
+	for _, h := range hs {
+		h.CargoDeadlineAtRisk(ctx, c, slip)
+	}
 }
 
 // Service provides cargo inspection operations.
 type Service interface {
 	// InspectCargo inspects cargo and send relevant notifications to
-	// interested parties, for example if a cargo has been misdirected, or
-	// unloaded at the final destination.
-	InspectCargo(id shipping.TrackingID)
+	// interested parties, for example if a cargo has been misdirected,
+	// unloaded at the final destination, or is projected to miss its
+	// arrival deadline.
+	InspectCargo(ctx context.Context, id shipping.TrackingID)
 }
 
 type service struct {
 	cargos  shipping.CargoRepository
 	events  shipping.HandlingEventRepository
 	handler EventHandler
+	policy  shipping.DeliveryPolicy
 }
 
 // TODO: Should be transactional
-func (s *service) InspectCargo(id shipping.TrackingID) {
+func (s *service) InspectCargo(ctx context.Context, id shipping.TrackingID) {
 	c, err := s.cargos.Find(id)
 	if err != nil {
 		return
@@ -34,20 +67,53 @@ func (s *service) InspectCargo(id shipping.TrackingID) {
 
 	h := s.events.QueryHandlingHistory(id)
 
-	c.DeriveDeliveryProgress(h)
+	wasAtRisk := c.Delivery.IsDeadlineAtRisk()
+
+	c.DeriveDeliveryProgressWith(s.policy, h)
+
+	if s.handler == nil {
+		s.cargos.Store(c)
+		return
+	}
 
 	if c.Delivery.IsMisdirected {
-		s.handler.CargoWasMisdirected(c)
+		s.handler.CargoWasMisdirected(ctx, c)
 	}
 
 	if c.Delivery.IsUnloadedAtDestination {
-		s.handler.CargoHasArrived(c)
+		s.handler.CargoHasArrived(ctx, c)
+	}
+
+	if slip := c.Delivery.DeadlineSlip(); slip > 0 && !wasAtRisk {
+		s.handler.CargoDeadlineAtRisk(ctx, c, slip)
 	}
 
 	s.cargos.Store(c)
 }
 
-// NewService creates a inspection service with necessary dependencies.
-func NewService(cargos shipping.CargoRepository, events shipping.HandlingEventRepository, handler EventHandler) Service {
-	return &service{cargos, events, handler}
+// Option configures the inspection service.
+type Option func(*service)
+
+// WithDeliveryPolicy sets the policy the handling of cargos is interpreted
+// with, e.g. whether they have been misdirected. Defaults to
+// shipping.DefaultDeliveryPolicy.
+func WithDeliveryPolicy(p shipping.DeliveryPolicy) Option {
+	return func(s *service) {
+		s.policy = p
+	}
+}
+
+// NewService creates a inspection service with necessary dependencies. The
+// handler may be nil.
+func NewService(cargos shipping.CargoRepository, events shipping.HandlingEventRepository, handler EventHandler, opts ...Option) Service {
+	s := &service{
+		cargos:  cargos,
+		events:  events,
+		handler: handler,
+		policy:  shipping.DefaultDeliveryPolicy,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
@@ -1,23 +1,30 @@
 package inspection
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/builders"
 )
 
 type stubEventHandler struct {
 	events []interface{}
 }
 
-func (h *stubEventHandler) CargoWasMisdirected(c *shipping.Cargo) {
+func (h *stubEventHandler) CargoWasMisdirected(ctx context.Context, c *shipping.Cargo) {
 	h.events = append(h.events, c)
 }
 
-func (h *stubEventHandler) CargoHasArrived(c *shipping.Cargo) {
+func (h *stubEventHandler) CargoHasArrived(ctx context.Context, c *shipping.Cargo) {
 	h.events = append(h.events, c)
 }
 
+func (h *stubEventHandler) CargoDeadlineAtRisk(ctx context.Context, c *shipping.Cargo, slip time.Duration) {
+	h.events = append(h.events, slip)
+}
+
 func TestInspectMisdirectedCargo(t *testing.T) {
 	var cargos mockCargoRepository
 
@@ -30,37 +37,26 @@ func TestInspectMisdirectedCargo(t *testing.T) {
 	s := NewService(&cargos, &events, &handler)
 
 	id := shipping.TrackingID("ABC123")
-	c := shipping.NewCargo(id, shipping.RouteSpecification{
-		Origin:      shipping.SESTO,
-		Destination: shipping.CNHKG,
-	})
-
-	voyage := shipping.VoyageNumber("001A")
-
-	c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
-		{VoyageNumber: voyage, LoadLocation: shipping.SESTO, UnloadLocation: shipping.AUMEL},
-		{VoyageNumber: voyage, LoadLocation: shipping.AUMEL, UnloadLocation: shipping.CNHKG},
-	}})
+	c, h := builders.Cargo(id).Misdirected(0, shipping.USNYC).Build()
 
 	if err := cargos.Store(c); err != nil {
 		t.Fatal(err)
 	}
-
-	storeEvent(&events, id, voyage, shipping.Receive, shipping.SESTO)
-	storeEvent(&events, id, voyage, shipping.Load, shipping.SESTO)
-	storeEvent(&events, id, voyage, shipping.Unload, shipping.USNYC)
+	for _, e := range h.HandlingEvents {
+		events.Store(e)
+	}
 
 	if len(handler.events) != 0 {
 		t.Errorf("no events should be handled")
 	}
 
-	s.InspectCargo(id)
+	s.InspectCargo(context.Background(), id)
 
 	if len(handler.events) != 1 {
 		t.Errorf("1 event should be handled")
 	}
 
-	s.InspectCargo("no_such_id")
+	s.InspectCargo(context.Background(), "no_such_id")
 
 	// no events was published
 	if len(handler.events) != 1 {
@@ -77,55 +73,87 @@ func TestInspectUnloadedCargo(t *testing.T) {
 
 	handler := stubEventHandler{make([]interface{}, 0)}
 
-	s := &service{
-		cargos:  &cargos,
-		events:  &events,
-		handler: &handler,
-	}
+	s := NewService(&cargos, &events, &handler)
 
 	id := shipping.TrackingID("ABC123")
-	unloadedCargo := shipping.NewCargo(id, shipping.RouteSpecification{
-		Origin:      shipping.SESTO,
-		Destination: shipping.CNHKG,
-	})
-
-	var voyage shipping.VoyageNumber = "001A"
-
-	unloadedCargo.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
-		{VoyageNumber: voyage, LoadLocation: shipping.SESTO, UnloadLocation: shipping.AUMEL},
-		{VoyageNumber: voyage, LoadLocation: shipping.AUMEL, UnloadLocation: shipping.CNHKG},
-	}})
+	c, h := builders.Cargo(id).Arrived().Build()
 
-	cargos.Store(unloadedCargo)
-
-	storeEvent(&events, id, voyage, shipping.Receive, shipping.SESTO)
-	storeEvent(&events, id, voyage, shipping.Load, shipping.SESTO)
-	storeEvent(&events, id, voyage, shipping.Unload, shipping.AUMEL)
-	storeEvent(&events, id, voyage, shipping.Load, shipping.AUMEL)
-	storeEvent(&events, id, voyage, shipping.Unload, shipping.CNHKG)
+	cargos.Store(c)
+	for _, e := range h.HandlingEvents {
+		events.Store(e)
+	}
 
 	if len(handler.events) != 0 {
 		t.Errorf("len(handler.events) = %d; want = %d", len(handler.events), 0)
 	}
 
-	s.InspectCargo(id)
+	s.InspectCargo(context.Background(), id)
 
 	if len(handler.events) != 1 {
 		t.Errorf("len(handler.events) = %d; want = %d", len(handler.events), 1)
 	}
 }
 
-func storeEvent(r shipping.HandlingEventRepository, id shipping.TrackingID, voyageNumber shipping.VoyageNumber, typ shipping.HandlingEventType, loc shipping.UNLocode) {
-	e := shipping.HandlingEvent{
-		TrackingID: id,
-		Activity: shipping.HandlingActivity{
-			VoyageNumber: voyageNumber,
-			Type:         typ,
-			Location:     loc,
-		},
+func TestInspectCargoDeadlineAtRisk(t *testing.T) {
+	var cargos mockCargoRepository
+
+	events := mockHandlingEventRepository{
+		events: make(map[shipping.TrackingID][]shipping.HandlingEvent),
 	}
 
-	r.Store(e)
+	handler := stubEventHandler{make([]interface{}, 0)}
+
+	s := NewService(&cargos, &events, &handler)
+
+	var (
+		id      = shipping.TrackingID("ABC123")
+		voyage  = shipping.VoyageNumber("001A")
+		depart  = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		arrival = depart.Add(10 * 24 * time.Hour)
+	)
+
+	c := shipping.NewCargo(id, shipping.RouteSpecification{
+		Origin:          shipping.SESTO,
+		Destination:     shipping.CNHKG,
+		ArrivalDeadline: arrival.Add(24 * time.Hour),
+	})
+	c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+		shipping.NewLeg(voyage, shipping.SESTO, shipping.CNHKG, depart, arrival),
+	}})
+
+	cargos.Store(c)
+
+	handle := func(typ shipping.HandlingEventType, completed time.Time) {
+		events.Store(shipping.HandlingEvent{
+			TrackingID: id,
+			Activity:   shipping.HandlingActivity{Type: typ, Location: shipping.SESTO, VoyageNumber: voyage},
+			Completed:  completed,
+		})
+		s.InspectCargo(context.Background(), id)
+	}
+
+	handle(shipping.Receive, depart.Add(-time.Hour))
+
+	if len(handler.events) != 0 {
+		t.Fatalf("len(handler.events) = %d; want = %d", len(handler.events), 0)
+	}
+
+	// Loaded a day and a half late, half a day past the deadline.
+	handle(shipping.Load, depart.Add(36*time.Hour))
+
+	if len(handler.events) != 1 {
+		t.Fatalf("len(handler.events) = %d; want = %d", len(handler.events), 1)
+	}
+	if slip := handler.events[0]; slip != 12*time.Hour {
+		t.Errorf("slip = %v; want = %v", slip, 12*time.Hour)
+	}
+
+	// The cargo remains at risk, which is not published again.
+	s.InspectCargo(context.Background(), id)
+
+	if len(handler.events) != 1 {
+		t.Errorf("len(handler.events) = %d; want = %d", len(handler.events), 1)
+	}
 }
 
 type mockCargoRepository struct {
@@ -148,6 +176,20 @@ func (r *mockCargoRepository) FindAll() []*shipping.Cargo {
 	return []*shipping.Cargo{r.cargo}
 }
 
+func (r *mockCargoRepository) ForEach(fn func(*shipping.Cargo) error) error {
+	if r.cargo == nil {
+		return nil
+	}
+	return fn(r.cargo)
+}
+
+func (r *mockCargoRepository) FindMatching(q shipping.CargoQuery) ([]*shipping.Cargo, error) {
+	if r.cargo == nil || !q.Matches(r.cargo) {
+		return nil, nil
+	}
+	return []*shipping.Cargo{r.cargo}, nil
+}
+
 type mockHandlingEventRepository struct {
 	events map[shipping.TrackingID][]shipping.HandlingEvent
 }
@@ -162,3 +204,26 @@ func (r *mockHandlingEventRepository) Store(e shipping.HandlingEvent) {
 func (r *mockHandlingEventRepository) QueryHandlingHistory(id shipping.TrackingID) shipping.HandlingHistory {
 	return shipping.HandlingHistory{HandlingEvents: r.events[id]}
 }
+
+func (r *mockHandlingEventRepository) QueryHandlingHistoryPage(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+	return shipping.HandlingHistoryPage{HandlingEvents: r.events[id]}, nil
+}
+
+func (r *mockHandlingEventRepository) QueryHandlingHistories(ids []shipping.TrackingID) map[shipping.TrackingID]shipping.HandlingHistory {
+	h := make(map[shipping.TrackingID]shipping.HandlingHistory)
+	for _, id := range ids {
+		h[id] = r.QueryHandlingHistory(id)
+	}
+	return h
+}
+
+func (r *mockHandlingEventRepository) ForEach(fn func(shipping.HandlingEvent) error) error {
+	for _, events := range r.events {
+		for _, e := range events {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package inspection
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingEventHandler struct {
+	logger log.Logger
+}
+
+// NewLoggingEventHandler returns an EventHandler that reports inspection
+// events through the log.
+func NewLoggingEventHandler(logger log.Logger) EventHandler {
+	return &loggingEventHandler{logger}
+}
+
+func (h *loggingEventHandler) CargoWasMisdirected(ctx context.Context, c *shipping.Cargo) {
+	h.logger.Log(
+		"msg", "cargo was misdirected",
+		"request_id", correlation.FromContext(ctx),
+		"tracking_id", c.TrackingID,
+		"location", c.Delivery.LastKnownLocation,
+	)
+}
+
+func (h *loggingEventHandler) CargoHasArrived(ctx context.Context, c *shipping.Cargo) {
+	h.logger.Log(
+		"msg", "cargo has arrived",
+		"request_id", correlation.FromContext(ctx),
+		"tracking_id", c.TrackingID,
+		"location", c.Delivery.LastKnownLocation,
+	)
+}
+
+func (h *loggingEventHandler) CargoDeadlineAtRisk(ctx context.Context, c *shipping.Cargo, slip time.Duration) {
+	h.logger.Log(
+		"msg", "cargo deadline at risk",
+		"request_id", correlation.FromContext(ctx),
+		"tracking_id", c.TrackingID,
+		"customer", c.Customer,
+		"deadline", c.RouteSpecification.ArrivalDeadline,
+		"projected_arrival", c.Delivery.ProjectedArrival(),
+		"slip", slip,
+	)
+}
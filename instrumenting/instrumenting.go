@@ -0,0 +1,60 @@
+// Package instrumenting provides decorators that record metrics and debug
+// logs for the calls to repositories, whichever backend stores them, to
+// tell where storage time is spent.
+package instrumenting
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+)
+
+// Instruments holds what the calls to repositories are recorded with. The
+// metrics are labeled by repository and method. Nil fields are left out.
+type Instruments struct {
+	// Calls counts every call, and Errors the calls that failed.
+	Calls  metrics.Counter
+	Errors metrics.Counter
+
+	// Latency observes how long calls take, in seconds. For ForEach, this
+	// includes the time spent in the callback.
+	Latency metrics.Histogram
+
+	// Results observes how many entities are returned by the calls that
+	// return several, such as FindAll.
+	Results metrics.Histogram
+
+	// Logger logs every call for debugging.
+	Logger log.Logger
+}
+
+// noResults is passed to observe for calls that do not return entities.
+const noResults = -1
+
+// observe records a call to a method of a repository that began at a time,
+// returned n entities and failed with err, if any.
+func (in Instruments) observe(repository, method string, begin time.Time, n int, err error) {
+	took := time.Since(begin)
+
+	if in.Calls != nil {
+		in.Calls.With("repository", repository, "method", method).Add(1)
+	}
+	if in.Errors != nil && err != nil {
+		in.Errors.With("repository", repository, "method", method).Add(1)
+	}
+	if in.Latency != nil {
+		in.Latency.With("repository", repository, "method", method).Observe(took.Seconds())
+	}
+	if in.Results != nil && n != noResults {
+		in.Results.With("repository", repository, "method", method).Observe(float64(n))
+	}
+
+	if in.Logger != nil {
+		kv := []interface{}{"repository", repository, "method", method, "took", took}
+		if n != noResults {
+			kv = append(kv, "results", n)
+		}
+		in.Logger.Log(append(kv, "err", err)...)
+	}
+}
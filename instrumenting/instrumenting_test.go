@@ -0,0 +1,101 @@
+package instrumenting
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+// metric sums up the values added or observed, by label values.
+type metric struct {
+	labels string
+	sums   map[string]float64
+}
+
+func newMetric() *metric {
+	return &metric{sums: make(map[string]float64)}
+}
+
+func (m *metric) with(labelValues ...string) *metric {
+	return &metric{labels: strings.Join(labelValues, ","), sums: m.sums}
+}
+
+func (m *metric) Add(delta float64)     { m.sums[m.labels] += delta }
+func (m *metric) Observe(value float64) { m.sums[m.labels] += value }
+
+type counter struct{ *metric }
+
+func (c counter) With(labelValues ...string) metrics.Counter { return counter{c.with(labelValues...)} }
+
+type histogram struct{ *metric }
+
+func (h histogram) With(labelValues ...string) metrics.Histogram {
+	return histogram{h.with(labelValues...)}
+}
+
+func TestCargoRepository(t *testing.T) {
+	errFind := errors.New("find failed")
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return nil, errFind
+	}
+	cargos.ForEachFn = func(fn func(*shipping.Cargo) error) error {
+		for _, id := range []shipping.TrackingID{"A", "B", "C"} {
+			if err := fn(&shipping.Cargo{TrackingID: id}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var (
+		calls   = newMetric()
+		errs    = newMetric()
+		results = newMetric()
+		logged  []interface{}
+	)
+
+	r := NewCargoRepository(Instruments{
+		Calls:   counter{calls},
+		Errors:  counter{errs},
+		Results: histogram{results},
+		Logger: log.LoggerFunc(func(kv ...interface{}) error {
+			logged = append(logged, kv...)
+			return nil
+		}),
+	}, &cargos)
+
+	if _, err := r.Find("A"); err != errFind {
+		t.Errorf("err = %v; want = %v", err, errFind)
+	}
+
+	var n int
+	if err := r.ForEach(func(*shipping.Cargo) error { n++; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("n = %d; want the callback called for every cargo", n)
+	}
+
+	want := map[string]float64{"repository,cargo,method,find": 1, "repository,cargo,method,for_each": 1}
+	if !reflect.DeepEqual(calls.sums, want) {
+		t.Errorf("calls = %v; want = %v", calls.sums, want)
+	}
+	if want := map[string]float64{"repository,cargo,method,find": 1}; !reflect.DeepEqual(errs.sums, want) {
+		t.Errorf("errors = %v; want = %v", errs.sums, want)
+	}
+	if want := map[string]float64{"repository,cargo,method,for_each": 3}; !reflect.DeepEqual(results.sums, want) {
+		t.Errorf("results = %v; want = %v", results.sums, want)
+	}
+	if len(logged) == 0 {
+		t.Errorf("calls should be logged")
+	}
+}
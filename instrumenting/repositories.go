@@ -0,0 +1,182 @@
+package instrumenting
+
+import (
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type cargoRepository struct {
+	in   Instruments
+	next shipping.CargoRepository
+}
+
+func (r *cargoRepository) Store(cargo *shipping.Cargo) (err error) {
+	defer func(begin time.Time) {
+		r.in.observe("cargo", "store", begin, noResults, err)
+	}(time.Now())
+	return r.next.Store(cargo)
+}
+
+func (r *cargoRepository) Find(id shipping.TrackingID) (c *shipping.Cargo, err error) {
+	defer func(begin time.Time) {
+		r.in.observe("cargo", "find", begin, noResults, err)
+	}(time.Now())
+	return r.next.Find(id)
+}
+
+func (r *cargoRepository) FindAll() (cs []*shipping.Cargo) {
+	defer func(begin time.Time) {
+		r.in.observe("cargo", "find_all", begin, len(cs), nil)
+	}(time.Now())
+	return r.next.FindAll()
+}
+
+func (r *cargoRepository) ForEach(fn func(*shipping.Cargo) error) (err error) {
+	var n int
+	defer func(begin time.Time) {
+		r.in.observe("cargo", "for_each", begin, n, err)
+	}(time.Now())
+	return r.next.ForEach(func(c *shipping.Cargo) error {
+		n++
+		return fn(c)
+	})
+}
+
+func (r *cargoRepository) FindMatching(q shipping.CargoQuery) (cs []*shipping.Cargo, err error) {
+	defer func(begin time.Time) {
+		r.in.observe("cargo", "find_matching", begin, len(cs), err)
+	}(time.Now())
+	return r.next.FindMatching(q)
+}
+
+// NewCargoRepository returns a cargo repository that records the calls to
+// next.
+func NewCargoRepository(in Instruments, next shipping.CargoRepository) shipping.CargoRepository {
+	return &cargoRepository{in: in, next: next}
+}
+
+type locationRepository struct {
+	in   Instruments
+	next shipping.LocationRepository
+}
+
+func (r *locationRepository) Store(l *shipping.Location) (err error) {
+	defer func(begin time.Time) {
+		r.in.observe("location", "store", begin, noResults, err)
+	}(time.Now())
+	return r.next.Store(l)
+}
+
+func (r *locationRepository) Find(locode shipping.UNLocode) (l *shipping.Location, err error) {
+	defer func(begin time.Time) {
+		r.in.observe("location", "find", begin, noResults, err)
+	}(time.Now())
+	return r.next.Find(locode)
+}
+
+func (r *locationRepository) FindAll() (ls []*shipping.Location) {
+	defer func(begin time.Time) {
+		r.in.observe("location", "find_all", begin, len(ls), nil)
+	}(time.Now())
+	return r.next.FindAll()
+}
+
+// NewLocationRepository returns a location repository that records the calls
+// to next.
+func NewLocationRepository(in Instruments, next shipping.LocationRepository) shipping.LocationRepository {
+	return &locationRepository{in: in, next: next}
+}
+
+type voyageRepository struct {
+	in   Instruments
+	next shipping.VoyageRepository
+}
+
+func (r *voyageRepository) Store(v *shipping.Voyage) (err error) {
+	defer func(begin time.Time) {
+		r.in.observe("voyage", "store", begin, noResults, err)
+	}(time.Now())
+	return r.next.Store(v)
+}
+
+func (r *voyageRepository) Find(number shipping.VoyageNumber) (v *shipping.Voyage, err error) {
+	defer func(begin time.Time) {
+		r.in.observe("voyage", "find", begin, noResults, err)
+	}(time.Now())
+	return r.next.Find(number)
+}
+
+func (r *voyageRepository) FindAll() (vs []*shipping.Voyage) {
+	defer func(begin time.Time) {
+		r.in.observe("voyage", "find_all", begin, len(vs), nil)
+	}(time.Now())
+	return r.next.FindAll()
+}
+
+func (r *voyageRepository) FindCallingAt(locode shipping.UNLocode) (vs []*shipping.Voyage) {
+	defer func(begin time.Time) {
+		r.in.observe("voyage", "find_calling_at", begin, len(vs), nil)
+	}(time.Now())
+	return r.next.FindCallingAt(locode)
+}
+
+// NewVoyageRepository returns a voyage repository that records the calls to
+// next.
+func NewVoyageRepository(in Instruments, next shipping.VoyageRepository) shipping.VoyageRepository {
+	return &voyageRepository{in: in, next: next}
+}
+
+type handlingEventRepository struct {
+	in   Instruments
+	next shipping.HandlingEventRepository
+}
+
+func (r *handlingEventRepository) Store(e shipping.HandlingEvent) {
+	defer func(begin time.Time) {
+		r.in.observe("handling_event", "store", begin, noResults, nil)
+	}(time.Now())
+	r.next.Store(e)
+}
+
+func (r *handlingEventRepository) QueryHandlingHistory(id shipping.TrackingID) (h shipping.HandlingHistory) {
+	defer func(begin time.Time) {
+		r.in.observe("handling_event", "query_handling_history", begin, len(h.HandlingEvents), nil)
+	}(time.Now())
+	return r.next.QueryHandlingHistory(id)
+}
+
+func (r *handlingEventRepository) QueryHandlingHistoryPage(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (p shipping.HandlingHistoryPage, err error) {
+	defer func(begin time.Time) {
+		r.in.observe("handling_event", "query_handling_history_page", begin, len(p.HandlingEvents), err)
+	}(time.Now())
+	return r.next.QueryHandlingHistoryPage(id, q)
+}
+
+func (r *handlingEventRepository) QueryHandlingHistories(ids []shipping.TrackingID) (hs map[shipping.TrackingID]shipping.HandlingHistory) {
+	defer func(begin time.Time) {
+		var n int
+		for _, h := range hs {
+			n += len(h.HandlingEvents)
+		}
+		r.in.observe("handling_event", "query_handling_histories", begin, n, nil)
+	}(time.Now())
+	return r.next.QueryHandlingHistories(ids)
+}
+
+func (r *handlingEventRepository) ForEach(fn func(shipping.HandlingEvent) error) (err error) {
+	var n int
+	defer func(begin time.Time) {
+		r.in.observe("handling_event", "for_each", begin, n, err)
+	}(time.Now())
+	return r.next.ForEach(func(e shipping.HandlingEvent) error {
+		n++
+		return fn(e)
+	})
+}
+
+// NewHandlingEventRepository returns a handling event repository that
+// records the calls to next.
+func NewHandlingEventRepository(in Instruments, next shipping.HandlingEventRepository) shipping.HandlingEventRepository {
+	return &handlingEventRepository{in: in, next: next}
+}
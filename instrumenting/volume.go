@@ -0,0 +1,153 @@
+package instrumenting
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// VolumeGauges holds the gauges the volumes of repositories are reported to,
+// labeled by repository. Nil fields are left out.
+type VolumeGauges struct {
+	Records metrics.Gauge
+	Bytes   metrics.Gauge
+
+	// OldestAge is the age of the oldest record, in seconds.
+	OldestAge metrics.Gauge
+}
+
+// Volume is how much data a repository holds, and how fast it grows.
+type Volume struct {
+	Repository   string     `json:"repository"`
+	Records      int        `json:"records"`
+	Bytes        int64      `json:"bytes,omitempty"`
+	Oldest       *time.Time `json:"oldest,omitempty"`
+	OldestAge    string     `json:"oldest_age,omitempty"`
+	RecordsDaily float64    `json:"records_per_day"`
+	BytesDaily   float64    `json:"bytes_per_day,omitempty"`
+}
+
+// VolumeReport is the latest sample of the volumes of the repositories.
+type VolumeReport struct {
+	Sampled      time.Time `json:"sampled"`
+	Repositories []Volume  `json:"repositories"`
+}
+
+// VolumeMonitor samples the volumes of repositories, so that capacity can be
+// planned for without access to the database. Growth is measured from the
+// first sample taken, i.e. since the service started.
+type VolumeMonitor struct {
+	reporter shipping.VolumeReporter
+	gauges   VolumeGauges
+	logger   log.Logger
+
+	mu      sync.Mutex
+	first   map[string]shipping.RepositoryVolume
+	began   time.Time
+	latest  []shipping.RepositoryVolume
+	sampled time.Time
+}
+
+// NewVolumeMonitor returns a monitor sampling the volumes reported.
+func NewVolumeMonitor(r shipping.VolumeReporter, g VolumeGauges, logger log.Logger) *VolumeMonitor {
+	return &VolumeMonitor{
+		reporter: r,
+		gauges:   g,
+		logger:   logger,
+	}
+}
+
+// Run samples the volumes at every interval until the context is cancelled,
+// starting right away.
+func (m *VolumeMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Sample(time.Now()); err != nil {
+			m.logger.Log("msg", "sampling repository volumes failed", "err", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Sample samples the volumes at a time, reporting them to the gauges.
+func (m *VolumeMonitor) Sample(now time.Time) error {
+	vs, err := m.reporter.Volumes()
+	if err != nil {
+		return err
+	}
+
+	for _, v := range vs {
+		if m.gauges.Records != nil {
+			m.gauges.Records.With("repository", v.Repository).Set(float64(v.Records))
+		}
+		if m.gauges.Bytes != nil && v.Bytes > 0 {
+			m.gauges.Bytes.With("repository", v.Repository).Set(float64(v.Bytes))
+		}
+		if m.gauges.OldestAge != nil && !v.Oldest.IsZero() {
+			m.gauges.OldestAge.With("repository", v.Repository).Set(now.Sub(v.Oldest).Seconds())
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.first == nil {
+		m.first = make(map[string]shipping.RepositoryVolume)
+		m.began = now
+	}
+	for _, v := range vs {
+		if _, ok := m.first[v.Repository]; !ok {
+			m.first[v.Repository] = v
+		}
+	}
+	m.latest = vs
+	m.sampled = now
+
+	return nil
+}
+
+// Report returns the latest sample, with the growth of each repository per
+// day since the first one.
+func (m *VolumeMonitor) Report() VolumeReport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	report := VolumeReport{
+		Sampled:      m.sampled,
+		Repositories: make([]Volume, len(m.latest)),
+	}
+
+	days := m.sampled.Sub(m.began).Hours() / 24
+
+	for i, v := range m.latest {
+		vol := Volume{
+			Repository: v.Repository,
+			Records:    v.Records,
+			Bytes:      v.Bytes,
+		}
+		if !v.Oldest.IsZero() {
+			oldest := v.Oldest
+			vol.Oldest = &oldest
+			vol.OldestAge = m.sampled.Sub(oldest).Truncate(time.Second).String()
+		}
+		if first, ok := m.first[v.Repository]; ok && days > 0 {
+			vol.RecordsDaily = float64(v.Records-first.Records) / days
+			vol.BytesDaily = float64(v.Bytes-first.Bytes) / days
+		}
+		report.Repositories[i] = vol
+	}
+
+	return report
+}
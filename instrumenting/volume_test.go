@@ -0,0 +1,85 @@
+package instrumenting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type gauge struct{ *metric }
+
+func (g gauge) With(labelValues ...string) metrics.Gauge { return gauge{g.with(labelValues...)} }
+func (g gauge) Set(value float64)                        { g.sums[g.labels] = value }
+
+type volumeReporterFunc func() ([]shipping.RepositoryVolume, error)
+
+func (f volumeReporterFunc) Volumes() ([]shipping.RepositoryVolume, error) { return f() }
+
+func TestVolumeMonitor(t *testing.T) {
+	var (
+		t0     = time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)
+		oldest = t0.Add(-48 * time.Hour)
+	)
+
+	volumes := []shipping.RepositoryVolume{
+		{Repository: "cargo", Records: 100, Bytes: 4096},
+		{Repository: "handling_event", Records: 1000, Bytes: 65536, Oldest: oldest},
+	}
+
+	var (
+		records = newMetric()
+		bytes   = newMetric()
+		age     = newMetric()
+	)
+
+	m := NewVolumeMonitor(volumeReporterFunc(func() ([]shipping.RepositoryVolume, error) {
+		return volumes, nil
+	}), VolumeGauges{
+		Records:   gauge{records},
+		Bytes:     gauge{bytes},
+		OldestAge: gauge{age},
+	}, log.NewNopLogger())
+
+	if err := m.Sample(t0); err != nil {
+		t.Fatal(err)
+	}
+
+	volumes = []shipping.RepositoryVolume{
+		{Repository: "cargo", Records: 110, Bytes: 4096},
+		{Repository: "handling_event", Records: 1200, Bytes: 131072, Oldest: oldest},
+	}
+
+	if err := m.Sample(t0.Add(12 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if records.sums["repository,handling_event"] != 1200 || bytes.sums["repository,handling_event"] != 131072 {
+		t.Errorf("records = %v, bytes = %v", records.sums, bytes.sums)
+	}
+	if want := (60 * time.Hour).Seconds(); age.sums["repository,handling_event"] != want {
+		t.Errorf("age = %v; want = %v", age.sums["repository,handling_event"], want)
+	}
+	if _, ok := age.sums["repository,cargo"]; ok {
+		t.Errorf("age reported for cargos without timestamps")
+	}
+
+	report := m.Report()
+	if len(report.Repositories) != 2 {
+		t.Fatalf("len(repositories) = %d; want = %d", len(report.Repositories), 2)
+	}
+
+	got := report.Repositories[1]
+	if got.RecordsDaily != 400 || got.BytesDaily != 131072 {
+		t.Errorf("growth = %v records, %v bytes per day; want 400, 131072", got.RecordsDaily, got.BytesDaily)
+	}
+	if got.OldestAge != "60h0m0s" {
+		t.Errorf("OldestAge = %q; want = %q", got.OldestAge, "60h0m0s")
+	}
+	if report.Repositories[0].Oldest != nil {
+		t.Errorf("oldest cargo reported")
+	}
+}
@@ -1,16 +1,23 @@
 package shipping
 
 import (
+	"errors"
 	"time"
 )
 
-// Leg describes the transportation between two locations on a voyage
+// Leg describes the transportation between two locations on a voyage. Its
+// wire encodings are defined in wire.go.
 type Leg struct {
-	VoyageNumber   VoyageNumber `json:"voyage_number"`
-	LoadLocation   UNLocode     `json:"from"`
-	UnloadLocation UNLocode     `json:"to"`
-	LoadTime       time.Time    `json:"load_time"`
-	UnloadTime     time.Time    `json:"unload_time"`
+	VoyageNumber   VoyageNumber
+	LoadLocation   UNLocode
+	UnloadLocation UNLocode
+	LoadTime       time.Time
+	UnloadTime     time.Time
+
+	// Status is whether the carrier has confirmed the booking of the leg,
+	// and CarrierReference the booking reference it confirmed it with.
+	Status           LegStatus
+	CarrierReference string
 }
 
 // NewLeg creates a new itinerary leg.
@@ -24,10 +31,53 @@ func NewLeg(voyageNumber VoyageNumber, loadLocation, unloadLocation UNLocode, lo
 	}
 }
 
+// LegStatus describes whether the carrier has confirmed the booking of a leg.
+type LegStatus string
+
+// Leg statuses. Legs assigned before carriers confirmed their bookings have
+// no status, and are treated as confirmed.
+const (
+	LegPending   LegStatus = "pending"
+	LegConfirmed LegStatus = "confirmed"
+	LegRejected  LegStatus = "rejected"
+)
+
+var (
+	// ErrUnknownLeg is used when an itinerary has no leg loading at a
+	// location onto a voyage.
+	ErrUnknownLeg = errors.New("unknown leg")
+
+	// ErrLegDecided is used when confirming or rejecting a leg that the
+	// carrier has already decided on otherwise.
+	ErrLegDecided = errors.New("leg already confirmed or rejected")
+
+	// ErrLegNotContiguous is used when a leg substituted into an itinerary
+	// does not connect with the legs before and after it.
+	ErrLegNotContiguous = errors.New("leg does not connect with itinerary")
+
+	// ErrLegHandled is used when substituting a leg the cargo has already
+	// been loaded onto.
+	ErrLegHandled = errors.New("cargo already loaded onto leg")
+
+	// ErrArrivalDeadlineMissed is used when an itinerary arrives after the
+	// arrival deadline of the cargo.
+	ErrArrivalDeadlineMissed = errors.New("arrival deadline missed")
+)
+
+// IsConfirmed returns whether the carrier has confirmed the booking of the
+// leg, so that the cargo may be loaded onto the voyage.
+func (l Leg) IsConfirmed() bool {
+	return l.Status == LegConfirmed || l.Status == ""
+}
+
 // Itinerary specifies steps required to transport a cargo from its origin to
-// destination.
+// destination. Its wire encodings are defined in wire.go.
 type Itinerary struct {
-	Legs []Leg `json:"legs"`
+	Legs []Leg
+
+	// Disrupted is set when the itinerary can no longer be executed as
+	// planned, e.g. because a port call along it was omitted.
+	Disrupted bool
 }
 
 // InitialDepartureLocation returns the start of the itinerary.
@@ -46,6 +96,12 @@ func (i Itinerary) FinalArrivalLocation() UNLocode {
 	return i.Legs[len(i.Legs)-1].UnloadLocation
 }
 
+// InitialDepartureTime returns the expected departure time from the start of
+// the itinerary.
+func (i Itinerary) InitialDepartureTime() time.Time {
+	return i.Legs[0].LoadTime
+}
+
 // FinalArrivalTime returns the expected arrival time at final destination.
 func (i Itinerary) FinalArrivalTime() time.Time {
 	return i.Legs[len(i.Legs)-1].UnloadTime
@@ -86,3 +142,292 @@ func (i Itinerary) IsExpected(event HandlingEvent) bool {
 
 	return true
 }
+
+// PendingConfirmation returns a copy of the itinerary with the booking of
+// every leg awaiting confirmation by its carrier.
+func (i Itinerary) PendingConfirmation() Itinerary {
+	legs := make([]Leg, len(i.Legs))
+	for k, l := range i.Legs {
+		l.Status = LegPending
+		l.CarrierReference = ""
+		legs[k] = l
+	}
+	i.Legs = legs
+	return i
+}
+
+// ConfirmLeg records that the carrier has confirmed the booking of the leg
+// loading at a location onto a voyage, with a booking reference.
+func (i Itinerary) ConfirmLeg(voyage VoyageNumber, from UNLocode, reference string) (Itinerary, error) {
+	return i.decideLeg(voyage, from, LegConfirmed, reference)
+}
+
+// RejectLeg records that the carrier has refused to book the leg loading at a
+// location onto a voyage.
+func (i Itinerary) RejectLeg(voyage VoyageNumber, from UNLocode) (Itinerary, error) {
+	return i.decideLeg(voyage, from, LegRejected, "")
+}
+
+func (i Itinerary) decideLeg(voyage VoyageNumber, from UNLocode, status LegStatus, reference string) (Itinerary, error) {
+	for k, l := range i.Legs {
+		if l.VoyageNumber != voyage || l.LoadLocation != from {
+			continue
+		}
+
+		if l.Status == status && l.CarrierReference == reference {
+			return i, nil
+		}
+		if l.Status != LegPending {
+			return i, ErrLegDecided
+		}
+
+		legs := append([]Leg(nil), i.Legs...)
+		legs[k].Status = status
+		legs[k].CarrierReference = reference
+		i.Legs = legs
+
+		return i, nil
+	}
+	return i, ErrUnknownLeg
+}
+
+// ReplaceLeg returns a copy of the itinerary with the leg at an index
+// substituted by another, awaiting confirmation by its carrier. The
+// substitute must load where the replaced leg loaded and unload where it
+// unloaded, after the previous leg unloads and before the next leg loads.
+func (i Itinerary) ReplaceLeg(k int, l Leg) (Itinerary, error) {
+	if k < 0 || k >= len(i.Legs) {
+		return i, ErrUnknownLeg
+	}
+
+	old := i.Legs[k]
+	if l.LoadLocation != old.LoadLocation || l.UnloadLocation != old.UnloadLocation {
+		return i, ErrLegNotContiguous
+	}
+	if !l.LoadTime.IsZero() && !l.UnloadTime.IsZero() && l.UnloadTime.Before(l.LoadTime) {
+		return i, ErrLegNotContiguous
+	}
+	if k > 0 && l.LoadTime.Before(i.Legs[k-1].UnloadTime) {
+		return i, ErrLegNotContiguous
+	}
+	if k < len(i.Legs)-1 && !i.Legs[k+1].LoadTime.IsZero() && l.UnloadTime.After(i.Legs[k+1].LoadTime) {
+		return i, ErrLegNotContiguous
+	}
+
+	l.Status = LegPending
+	l.CarrierReference = ""
+
+	legs := append([]Leg(nil), i.Legs...)
+	legs[k] = l
+	i.Legs = legs
+
+	return i, nil
+}
+
+// UsesPortCall returns whether the itinerary loads or unloads cargo at a
+// location on a voyage.
+func (i Itinerary) UsesPortCall(voyage VoyageNumber, locode UNLocode) bool {
+	for _, l := range i.Legs {
+		if l.VoyageNumber == voyage && (l.LoadLocation == locode || l.UnloadLocation == locode) {
+			return true
+		}
+	}
+	return false
+}
+
+// CallsAtDuring returns whether the itinerary loads or unloads cargo at a
+// location between two times, inclusive.
+func (i Itinerary) CallsAtDuring(locode UNLocode, from, to time.Time) bool {
+	during := func(t time.Time) bool {
+		return !t.Before(from) && !t.After(to)
+	}
+	for _, l := range i.Legs {
+		if (l.LoadLocation == locode && during(l.LoadTime)) || (l.UnloadLocation == locode && during(l.UnloadTime)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpectedWindow returns when an activity is expected to take place when
+// executing this itinerary, i.e. while the cargo is at the location of the
+// activity. A zero time leaves that end of the window open, as does an
+// activity not part of the itinerary.
+func (i Itinerary) ExpectedWindow(a HandlingActivity) (from, to time.Time) {
+	if i.IsEmpty() {
+		return time.Time{}, time.Time{}
+	}
+
+	switch a.Type {
+	case Receive:
+		if a.Location == i.InitialDepartureLocation() {
+			return time.Time{}, i.InitialDepartureTime()
+		}
+	case Load:
+		for k, l := range i.Legs {
+			if l.LoadLocation == a.Location && l.VoyageNumber == a.VoyageNumber {
+				if k > 0 {
+					from = i.Legs[k-1].UnloadTime
+				}
+				return from, l.LoadTime
+			}
+		}
+	case Unload:
+		for k, l := range i.Legs {
+			if l.UnloadLocation == a.Location && l.VoyageNumber == a.VoyageNumber {
+				if k < len(i.Legs)-1 {
+					to = i.Legs[k+1].LoadTime
+				}
+				return l.UnloadTime, to
+			}
+		}
+	case Claim:
+		if a.Location == i.FinalArrivalLocation() {
+			return i.FinalArrivalTime(), time.Time{}
+		}
+	}
+
+	return time.Time{}, time.Time{}
+}
+
+// ErrConnectionTooShort is used when an itinerary does not leave enough time
+// to transship cargo between two legs.
+var ErrConnectionTooShort = errors.New("connection time too short")
+
+// ConnectionTimes holds the minimum time needed to transship cargo from one
+// voyage to another at a location.
+type ConnectionTimes struct {
+	// Default applies to locations without a specific connection time.
+	Default time.Duration
+
+	ByLocation map[UNLocode]time.Duration
+}
+
+// At returns the minimum connection time at a location.
+func (c ConnectionTimes) At(locode UNLocode) time.Duration {
+	if d, ok := c.ByLocation[locode]; ok {
+		return d
+	}
+	return c.Default
+}
+
+// ValidateConnections returns ErrConnectionTooShort if the cargo is to be
+// loaded onto another voyage sooner after unloading than the minimum
+// connection time. Connections with unknown times are not checked.
+func (i Itinerary) ValidateConnections(c ConnectionTimes) error {
+	for k := 1; k < len(i.Legs); k++ {
+		prev, next := i.Legs[k-1], i.Legs[k]
+
+		if prev.VoyageNumber == next.VoyageNumber {
+			continue
+		}
+		if prev.UnloadTime.IsZero() || next.LoadTime.IsZero() {
+			continue
+		}
+
+		if next.LoadTime.Sub(prev.UnloadTime) < c.At(prev.UnloadLocation) {
+			return ErrConnectionTooShort
+		}
+	}
+	return nil
+}
+
+// ErrLegOffSchedule is used when the times of a leg contradict the schedule
+// of its voyage.
+var ErrLegOffSchedule = errors.New("leg times contradict voyage schedule")
+
+// Scheduled returns the leg with its times taken from the schedule of the
+// voyage. Of a voyage departing the load location more than once, the
+// sailing closest to the times already known is taken.
+func (l Leg) Scheduled(v *Voyage) (Leg, error) {
+	sailings, err := v.Sailings(l.LoadLocation, l.UnloadLocation)
+	if err != nil {
+		return l, err
+	}
+
+	var (
+		best    Leg
+		closest time.Duration
+	)
+	for k, s := range sailings {
+		load, unload := l.offset(s)
+		if k == 0 || load+unload < closest {
+			best, closest = s, load+unload
+		}
+	}
+
+	l.LoadTime = best.LoadTime
+	l.UnloadTime = best.UnloadTime
+
+	return l, nil
+}
+
+// ValidateSchedule returns ErrLegOffSchedule if the known times of the leg
+// are further than the tolerance from those of every sailing of the voyage
+// between its locations, and ErrPortNotCalled if there is no such sailing.
+func (l Leg) ValidateSchedule(v *Voyage, tolerance time.Duration) error {
+	sailings, err := v.Sailings(l.LoadLocation, l.UnloadLocation)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sailings {
+		if load, unload := l.offset(s); load <= tolerance && unload <= tolerance {
+			return nil
+		}
+	}
+	return ErrLegOffSchedule
+}
+
+// offset returns how far the known load and unload times of the leg are off
+// those of a sailing.
+func (l Leg) offset(s Leg) (load, unload time.Duration) {
+	if !l.LoadTime.IsZero() {
+		load = timeBetween(l.LoadTime, s.LoadTime)
+	}
+	if !l.UnloadTime.IsZero() {
+		unload = timeBetween(l.UnloadTime, s.UnloadTime)
+	}
+	return load, unload
+}
+
+// Scheduled returns the itinerary with the unknown times of its legs filled
+// in from the schedules of their voyages. Legs whose times are both known
+// are left as they are.
+func (i Itinerary) Scheduled(voyages VoyageRepository) (Itinerary, error) {
+	legs := make([]Leg, len(i.Legs))
+	for k, l := range i.Legs {
+		legs[k] = l
+		if !l.LoadTime.IsZero() && !l.UnloadTime.IsZero() {
+			continue
+		}
+
+		v, err := voyages.Find(l.VoyageNumber)
+		if err != nil {
+			return i, err
+		}
+		if legs[k], err = l.Scheduled(v); err != nil {
+			return i, err
+		}
+	}
+
+	i.Legs = legs
+	return i, nil
+}
+
+// ValidateSchedules returns ErrLegOffSchedule if the times of a leg of the
+// itinerary contradict the schedule of its voyage by more than the
+// tolerance, and ErrPortNotCalled if the voyage does not sail between the
+// locations of the leg.
+func (i Itinerary) ValidateSchedules(voyages VoyageRepository, tolerance time.Duration) error {
+	for _, l := range i.Legs {
+		v, err := voyages.Find(l.VoyageNumber)
+		if err != nil {
+			return err
+		}
+		if err := l.ValidateSchedule(v, tolerance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
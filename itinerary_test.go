@@ -3,6 +3,7 @@ package shipping
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestItinerary_CreateEmpty(t *testing.T) {
@@ -73,3 +74,231 @@ func TestItinerary_IsExpected(t *testing.T) {
 		}
 	}
 }
+
+func TestItinerary_ValidateConnections(t *testing.T) {
+	unload := time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	ct := ConnectionTimes{
+		Default:    2 * time.Hour,
+		ByLocation: map[UNLocode]time.Duration{DEHAM: 12 * time.Hour},
+	}
+
+	tests := []struct {
+		at   UNLocode
+		wait time.Duration
+		want error
+	}{
+		{at: SESTO, wait: 3 * time.Hour, want: nil},
+		{at: SESTO, wait: time.Hour, want: ErrConnectionTooShort},
+		{at: DEHAM, wait: 3 * time.Hour, want: ErrConnectionTooShort},
+		{at: DEHAM, wait: 12 * time.Hour, want: nil},
+		{at: SESTO, wait: -time.Hour, want: ErrConnectionTooShort},
+	}
+
+	for _, tt := range tests {
+		i := Itinerary{Legs: []Leg{
+			NewLeg("V1", CNHKG, tt.at, unload.Add(-48*time.Hour), unload),
+			NewLeg("V2", tt.at, FIHEL, unload.Add(tt.wait), unload.Add(tt.wait+24*time.Hour)),
+		}}
+
+		if got := i.ValidateConnections(ct); got != tt.want {
+			t.Errorf("connection of %v at %s: err = %v; want = %v", tt.wait, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestItinerary_ValidateConnections_SameVoyage(t *testing.T) {
+	t1 := time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	i := Itinerary{Legs: []Leg{
+		NewLeg("V1", CNHKG, JNTKO, t1, t1.Add(time.Hour)),
+		NewLeg("V1", JNTKO, USNYC, t1.Add(time.Hour), t1.Add(48*time.Hour)),
+	}}
+
+	if err := i.ValidateConnections(ConnectionTimes{Default: 2 * time.Hour}); err != nil {
+		t.Errorf("err = %v; want = nil", err)
+	}
+}
+
+func TestLeg_ValidateSchedule(t *testing.T) {
+	t1 := time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	// A voyage calling at Hong Kong twice, on its way out and back.
+	v := NewVoyage("V1", Schedule{[]CarrierMovement{
+		{DepartureLocation: CNHKG, ArrivalLocation: JNTKO, DepartureTime: t1, ArrivalTime: t1.Add(48 * time.Hour)},
+		{DepartureLocation: JNTKO, ArrivalLocation: CNHKG, DepartureTime: t1.Add(72 * time.Hour), ArrivalTime: t1.Add(120 * time.Hour)},
+		{DepartureLocation: CNHKG, ArrivalLocation: JNTKO, DepartureTime: t1.Add(144 * time.Hour), ArrivalTime: t1.Add(192 * time.Hour)},
+	}})
+
+	tests := []struct {
+		leg  Leg
+		want error
+	}{
+		{leg: NewLeg("V1", CNHKG, JNTKO, t1, t1.Add(48*time.Hour)), want: nil},
+		{leg: NewLeg("V1", CNHKG, JNTKO, t1.Add(144*time.Hour), t1.Add(192*time.Hour)), want: nil},
+		{leg: NewLeg("V1", CNHKG, JNTKO, t1.Add(time.Hour), t1.Add(49*time.Hour)), want: nil},
+		{leg: NewLeg("V1", CNHKG, JNTKO, t1.Add(3*time.Hour), t1.Add(48*time.Hour)), want: ErrLegOffSchedule},
+		{leg: NewLeg("V1", CNHKG, JNTKO, t1, t1.Add(192*time.Hour)), want: ErrLegOffSchedule},
+		{leg: NewLeg("V1", CNHKG, JNTKO, time.Time{}, t1.Add(192*time.Hour)), want: nil},
+		{leg: NewLeg("V1", CNHKG, USNYC, t1, t1.Add(48*time.Hour)), want: ErrPortNotCalled},
+	}
+
+	for _, tt := range tests {
+		if got := tt.leg.ValidateSchedule(v, time.Hour); got != tt.want {
+			t.Errorf("%s %s-%s: err = %v; want = %v", tt.leg.LoadTime, tt.leg.LoadLocation, tt.leg.UnloadLocation, got, tt.want)
+		}
+	}
+
+	l, err := NewLeg("V1", CNHKG, JNTKO, time.Time{}, t1.Add(190*time.Hour)).Scheduled(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.LoadTime.Equal(t1.Add(144*time.Hour)) || !l.UnloadTime.Equal(t1.Add(192*time.Hour)) {
+		t.Errorf("scheduled = %s-%s; want the second sailing", l.LoadTime, l.UnloadTime)
+	}
+
+	l, err = NewLeg("V1", JNTKO, CNHKG, time.Time{}, time.Time{}).Scheduled(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.LoadTime.Equal(t1.Add(72*time.Hour)) || !l.UnloadTime.Equal(t1.Add(120*time.Hour)) {
+		t.Errorf("scheduled = %s-%s; want = %s-%s", l.LoadTime, l.UnloadTime, t1.Add(72*time.Hour), t1.Add(120*time.Hour))
+	}
+}
+
+func TestItinerary_ExpectedWindow(t *testing.T) {
+	var (
+		t1 = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		t2 = t1.Add(24 * time.Hour)
+		t3 = t2.Add(12 * time.Hour)
+		t4 = t3.Add(24 * time.Hour)
+	)
+
+	i := Itinerary{Legs: []Leg{
+		NewLeg("V100", CNHKG, DEHAM, t1, t2),
+		NewLeg("V200", DEHAM, SESTO, t3, t4),
+	}}
+
+	tests := []struct {
+		activity HandlingActivity
+		from, to time.Time
+	}{
+		{HandlingActivity{Type: Receive, Location: CNHKG}, time.Time{}, t1},
+		{HandlingActivity{Type: Load, Location: CNHKG, VoyageNumber: "V100"}, time.Time{}, t1},
+		{HandlingActivity{Type: Unload, Location: DEHAM, VoyageNumber: "V100"}, t2, t3},
+		{HandlingActivity{Type: Load, Location: DEHAM, VoyageNumber: "V200"}, t2, t3},
+		{HandlingActivity{Type: Unload, Location: SESTO, VoyageNumber: "V200"}, t4, time.Time{}},
+		{HandlingActivity{Type: Claim, Location: SESTO}, t4, time.Time{}},
+		{HandlingActivity{Type: Load, Location: NLRTM, VoyageNumber: "V100"}, time.Time{}, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		from, to := i.ExpectedWindow(tt.activity)
+		if !from.Equal(tt.from) || !to.Equal(tt.to) {
+			t.Errorf("ExpectedWindow(%+v) = %v, %v; want = %v, %v", tt.activity, from, to, tt.from, tt.to)
+		}
+	}
+}
+
+func TestItinerary_ConfirmLeg(t *testing.T) {
+	i := Itinerary{Legs: []Leg{
+		NewLeg("V100", CNHKG, DEHAM, time.Time{}, time.Time{}),
+		NewLeg("V200", DEHAM, SESTO, time.Time{}, time.Time{}),
+	}}.PendingConfirmation()
+
+	// Loading onto a leg awaiting confirmation does not misdirect the cargo.
+	load := HandlingEvent{Activity: HandlingActivity{Type: Load, Location: DEHAM, VoyageNumber: "V200"}}
+	if !i.IsExpected(load) {
+		t.Errorf("IsExpected() = false; want = true for a pending leg")
+	}
+
+	confirmed, err := i.ConfirmLeg("V200", DEHAM, "REF-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := confirmed.Legs[1].CarrierReference; got != "REF-1" {
+		t.Errorf("CarrierReference = %q; want = %q", got, "REF-1")
+	}
+	if i.Legs[1].Status != LegPending {
+		t.Errorf("Status = %q; want the original itinerary to be left %q", i.Legs[1].Status, LegPending)
+	}
+
+	if _, err := confirmed.ConfirmLeg("V200", DEHAM, "REF-1"); err != nil {
+		t.Errorf("err = %v; want confirming again to have no effect", err)
+	}
+	if _, err := confirmed.ConfirmLeg("V200", DEHAM, "REF-2"); err != ErrLegDecided {
+		t.Errorf("err = %v; want = %v", err, ErrLegDecided)
+	}
+	if _, err := confirmed.RejectLeg("V200", DEHAM); err != ErrLegDecided {
+		t.Errorf("err = %v; want = %v", err, ErrLegDecided)
+	}
+	if _, err := confirmed.ConfirmLeg("V200", CNHKG, "REF-1"); err != ErrUnknownLeg {
+		t.Errorf("err = %v; want = %v", err, ErrUnknownLeg)
+	}
+}
+
+func TestItinerary_ReplaceLeg(t *testing.T) {
+	var (
+		t0  = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		day = 24 * time.Hour
+	)
+
+	i := Itinerary{Legs: []Leg{
+		NewLeg("V100", CNHKG, DEHAM, t0, t0.Add(20*day)),
+		NewLeg("V200", DEHAM, SESTO, t0.Add(22*day), t0.Add(24*day)),
+		NewLeg("V300", SESTO, FIHEL, t0.Add(26*day), t0.Add(27*day)),
+	}}
+
+	got, err := i.ReplaceLeg(1, NewLeg("V250", DEHAM, SESTO, t0.Add(21*day), t0.Add(25*day)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Legs[1].VoyageNumber != "V250" || got.Legs[1].Status != LegPending {
+		t.Errorf("got.Legs[1] = %+v; want V250 pending confirmation", got.Legs[1])
+	}
+	if i.Legs[1].VoyageNumber != "V200" {
+		t.Errorf("the original itinerary was modified")
+	}
+
+	tests := []struct {
+		k   int
+		l   Leg
+		err error
+	}{
+		{3, NewLeg("V250", DEHAM, SESTO, t0.Add(21*day), t0.Add(25*day)), ErrUnknownLeg},
+		{1, NewLeg("V250", DEHAM, USNYC, t0.Add(21*day), t0.Add(25*day)), ErrLegNotContiguous},
+		{1, NewLeg("V250", DEHAM, SESTO, t0.Add(19*day), t0.Add(25*day)), ErrLegNotContiguous},
+		{1, NewLeg("V250", DEHAM, SESTO, t0.Add(21*day), t0.Add(27*day)), ErrLegNotContiguous},
+		{1, NewLeg("V250", DEHAM, SESTO, t0.Add(23*day), t0.Add(22*day)), ErrLegNotContiguous},
+	}
+	for _, tt := range tests {
+		if _, err := i.ReplaceLeg(tt.k, tt.l); err != tt.err {
+			t.Errorf("ReplaceLeg(%d, %+v) err = %v; want = %v", tt.k, tt.l, err, tt.err)
+		}
+	}
+}
+
+func TestItinerary_CallsAtDuring(t *testing.T) {
+	t0 := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	i := Itinerary{Legs: []Leg{
+		NewLeg("V100", CNHKG, DEHAM, t0, t0.Add(240*time.Hour)),
+		NewLeg("V200", DEHAM, SESTO, t0.Add(260*time.Hour), t0.Add(280*time.Hour)),
+	}}
+
+	for _, tt := range []struct {
+		locode   UNLocode
+		from, to time.Time
+		want     bool
+	}{
+		{DEHAM, t0.Add(230 * time.Hour), t0.Add(250 * time.Hour), true},
+		{DEHAM, t0.Add(250 * time.Hour), t0.Add(270 * time.Hour), true},
+		{DEHAM, t0.Add(241 * time.Hour), t0.Add(259 * time.Hour), false},
+		{CNHKG, t0, t0, true},
+		{FIHEL, t0, t0.Add(300 * time.Hour), false},
+	} {
+		if got := i.CallsAtDuring(tt.locode, tt.from, tt.to); got != tt.want {
+			t.Errorf("CallsAtDuring(%s, %v, %v) = %v; want = %v", tt.locode, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
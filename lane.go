@@ -0,0 +1,129 @@
+package shipping
+
+import "time"
+
+// Lane is the stretch between two ports sailed by a service string. Legs on
+// voyages outside of any service string are told apart by their voyage.
+type Lane struct {
+	Service ServiceCode
+	Voyage  VoyageNumber
+	From    UNLocode
+	To      UNLocode
+}
+
+// LegOutcome is how a leg of an itinerary turned out for a cargo.
+type LegOutcome struct {
+	Leg Leg
+
+	// Delay is how much later than planned the cargo was unloaded, or
+	// negative if it was unloaded early. It is zero for misrouted legs.
+	Delay time.Duration
+
+	// Misrouted is set if the cargo was unloaded from the voyage of the
+	// leg somewhere other than planned.
+	Misrouted bool
+}
+
+// IsOnTime returns whether the cargo was unloaded as planned, no later than
+// the leg was scheduled to arrive.
+func (o LegOutcome) IsOnTime() bool {
+	return !o.Misrouted && o.Delay <= 0
+}
+
+// LegOutcomes returns the outcomes of the legs of the itinerary a cargo has
+// been unloaded from, according to its handling history. Legs not yet
+// sailed are left out.
+func (i Itinerary) LegOutcomes(history HandlingHistory) []LegOutcome {
+	var result []LegOutcome
+	for _, l := range i.Legs {
+		for _, e := range history.HandlingEvents {
+			if e.Activity.Type != Unload || e.Activity.VoyageNumber != l.VoyageNumber {
+				continue
+			}
+			if e.Activity.Location == l.UnloadLocation {
+				result = append(result, LegOutcome{Leg: l, Delay: e.Completed.Sub(l.UnloadTime)})
+				break
+			}
+			if !i.IsExpected(e) {
+				result = append(result, LegOutcome{Leg: l, Misrouted: true})
+				break
+			}
+		}
+	}
+	return result
+}
+
+// LanePerformance sums up the outcomes of the legs sailed on a lane.
+type LanePerformance struct {
+	Sailed    int
+	OnTime    int
+	Misrouted int
+
+	// TotalDelay sums up the delays of the legs unloaded as planned.
+	TotalDelay time.Duration
+}
+
+// Add records the outcome of a leg.
+func (p *LanePerformance) Add(o LegOutcome) {
+	p.Sailed++
+	if o.Misrouted {
+		p.Misrouted++
+		return
+	}
+	if o.IsOnTime() {
+		p.OnTime++
+	}
+	p.TotalDelay += o.Delay
+}
+
+// Merge adds the outcomes summed up by another performance.
+func (p *LanePerformance) Merge(q LanePerformance) {
+	p.Sailed += q.Sailed
+	p.OnTime += q.OnTime
+	p.Misrouted += q.Misrouted
+	p.TotalDelay += q.TotalDelay
+}
+
+// AverageDelay returns the average delay of the legs unloaded as planned.
+func (p LanePerformance) AverageDelay() time.Duration {
+	n := p.Sailed - p.Misrouted
+	if n == 0 {
+		return 0
+	}
+	return p.TotalDelay / time.Duration(n)
+}
+
+// OnTimeRate returns the share of the legs sailed unloaded on time, between
+// 0 and 1.
+func (p LanePerformance) OnTimeRate() float64 {
+	if p.Sailed == 0 {
+		return 0
+	}
+	return float64(p.OnTime) / float64(p.Sailed)
+}
+
+// MisrouteRate returns the share of the legs sailed that were misrouted,
+// between 0 and 1.
+func (p LanePerformance) MisrouteRate() float64 {
+	if p.Sailed == 0 {
+		return 0
+	}
+	return float64(p.Misrouted) / float64(p.Sailed)
+}
+
+// LanePerformanceRepository provides access to a read model of how lanes
+// performed, kept up to date as cargos are routed and handled. The
+// performance of every cargo is kept apart, so that storing it again
+// replaces it rather than counting it twice.
+type LanePerformanceRepository interface {
+	// Store replaces the performance of the legs sailed by a cargo, by
+	// lane.
+	Store(id TrackingID, lanes map[Lane]LanePerformance) error
+
+	// FindAll returns the performance of every lane sailed, summed up over
+	// all cargos.
+	FindAll() map[Lane]LanePerformance
+
+	// Clear removes the performance of every cargo.
+	Clear() error
+}
@@ -0,0 +1,50 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestItinerary_LegOutcomes(t *testing.T) {
+	t0 := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	i := Itinerary{Legs: []Leg{
+		NewLeg("V100", CNHKG, DEHAM, t0, t0.Add(20*24*time.Hour)),
+		NewLeg("V200", DEHAM, SESTO, t0.Add(21*24*time.Hour), t0.Add(22*24*time.Hour)),
+		NewLeg("V300", SESTO, FIHEL, t0.Add(23*24*time.Hour), t0.Add(24*24*time.Hour)),
+	}}
+
+	history := HandlingHistory{HandlingEvents: []HandlingEvent{
+		{Activity: HandlingActivity{Type: Load, Location: CNHKG, VoyageNumber: "V100"}, Completed: t0},
+		{Activity: HandlingActivity{Type: Unload, Location: DEHAM, VoyageNumber: "V100"}, Completed: t0.Add(20*24*time.Hour + 6*time.Hour)},
+		{Activity: HandlingActivity{Type: Load, Location: DEHAM, VoyageNumber: "V200"}, Completed: t0.Add(21 * 24 * time.Hour)},
+		{Activity: HandlingActivity{Type: Unload, Location: NLRTM, VoyageNumber: "V200"}, Completed: t0.Add(22 * 24 * time.Hour)},
+	}}
+
+	got := i.LegOutcomes(history)
+	if len(got) != 2 {
+		t.Fatalf("len(outcomes) = %d; want = %d", len(got), 2)
+	}
+	if got[0].Delay != 6*time.Hour || got[0].IsOnTime() {
+		t.Errorf("outcomes[0] = %+v; want late by 6h", got[0])
+	}
+	if !got[1].Misrouted {
+		t.Errorf("outcomes[1] = %+v; want misrouted", got[1])
+	}
+
+	var p LanePerformance
+	for _, o := range got {
+		p.Add(o)
+	}
+	p.Add(LegOutcome{Delay: -2 * time.Hour})
+
+	if p.Sailed != 3 || p.OnTime != 1 || p.Misrouted != 1 {
+		t.Errorf("performance = %+v", p)
+	}
+	if want := 2 * time.Hour; p.AverageDelay() != want {
+		t.Errorf("AverageDelay() = %v; want = %v", p.AverageDelay(), want)
+	}
+	if p.OnTimeRate() != 1.0/3 || p.MisrouteRate() != 1.0/3 {
+		t.Errorf("rates = %v, %v; want a third each", p.OnTimeRate(), p.MisrouteRate())
+	}
+}
@@ -0,0 +1,88 @@
+// Package locale provides request-scoped languages, negotiated from the
+// languages accepted by the client and the ones a view is translated to.
+package locale
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Header is the HTTP header listing the languages accepted by the client.
+const Header = "Accept-Language"
+
+// Default is the language used unless another one is negotiated.
+const Default = "en"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the given language.
+func NewContext(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext returns the language carried by ctx, or Default if there is
+// none.
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(contextKey{}).(string); ok && lang != "" {
+		return lang
+	}
+	return Default
+}
+
+// Negotiate picks the supported language preferred by the client, given the
+// value of an Accept-Language header such as "sv-SE,sv;q=0.9,en;q=0.8".
+// Regional variants match their primary language. The first supported
+// language is picked if none of them is accepted.
+func Negotiate(header string, supported []string) string {
+	if len(supported) == 0 {
+		return Default
+	}
+
+	type accepted struct {
+		lang    string
+		quality float64
+	}
+
+	var langs []accepted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+
+		a := accepted{lang: strings.ToLower(strings.TrimSpace(fields[0])), quality: 1}
+		if a.lang == "" {
+			continue
+		}
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				q, err := strconv.ParseFloat(f[2:], 64)
+				if err != nil {
+					q = 0
+				}
+				a.quality = q
+			}
+		}
+		if a.quality > 0 {
+			langs = append(langs, a)
+		}
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].quality > langs[j].quality
+	})
+
+	for _, a := range langs {
+		if a.lang == "*" {
+			return supported[0]
+		}
+		primary := strings.SplitN(a.lang, "-", 2)[0]
+		for _, s := range supported {
+			if s == a.lang || s == primary {
+				return s
+			}
+		}
+	}
+
+	return supported[0]
+}
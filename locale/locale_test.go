@@ -0,0 +1,37 @@
+package locale
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	supported := []string{"en", "sv"}
+
+	for _, tt := range []struct {
+		header string
+		want   string
+	}{
+		{"", "en"},
+		{"sv", "sv"},
+		{"sv-SE,sv;q=0.9,en;q=0.8", "sv"},
+		{"de-DE,de;q=0.9,sv;q=0.5,en;q=0.7", "en"},
+		{"en;q=0.2, SV;q=0.4", "sv"},
+		{"de", "en"},
+		{"sv;q=0, *;q=0.1", "en"},
+		{"fi, sv;q=bogus", "en"},
+	} {
+		if got := Negotiate(tt.header, supported); got != tt.want {
+			t.Errorf("Negotiate(%q) = %s; want = %s", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != Default {
+		t.Errorf("FromContext() = %s; want = %s", got, Default)
+	}
+	if got := FromContext(NewContext(context.Background(), "sv")); got != "sv" {
+		t.Errorf("FromContext() = %s; want = %s", got, "sv")
+	}
+}
@@ -1,6 +1,9 @@
 package shipping
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 // UNLocode is the United Nations location code that uniquely identifies a
 // particular location.
@@ -14,6 +17,18 @@ type UNLocode string
 type Location struct {
 	UNLocode UNLocode
 	Name     string
+
+	// TimeZone is the IANA time zone name of the location, such as
+	// "Europe/Stockholm".
+	TimeZone string
+
+	Coordinates Coordinates
+}
+
+// Coordinates is a geographic position in decimal degrees.
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
 }
 
 // ErrUnknownLocation is used when a location could not be found.
@@ -21,6 +36,16 @@ var ErrUnknownLocation = errors.New("unknown location")
 
 // LocationRepository provides access a location store.
 type LocationRepository interface {
+	Store(l *Location) error
 	Find(locode UNLocode) (*Location, error)
 	FindAll() []*Location
 }
+
+// Country returns the ISO 3166 country code of a location, which makes up
+// the first two letters of its UN/LOCODE.
+func (l UNLocode) Country() string {
+	if len(l) < 2 {
+		return ""
+	}
+	return strings.ToUpper(string(l[:2]))
+}
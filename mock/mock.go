@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"time"
+
 	shipping "github.com/marcusolsson/goddd"
 )
 
@@ -14,6 +16,12 @@ type CargoRepository struct {
 
 	FindAllFn      func() []*shipping.Cargo
 	FindAllInvoked bool
+
+	ForEachFn      func(func(*shipping.Cargo) error) error
+	ForEachInvoked bool
+
+	FindMatchingFn      func(q shipping.CargoQuery) ([]*shipping.Cargo, error)
+	FindMatchingInvoked bool
 }
 
 // Store calls the StoreFn.
@@ -34,8 +42,23 @@ func (r *CargoRepository) FindAll() []*shipping.Cargo {
 	return r.FindAllFn()
 }
 
+// ForEach calls the ForEachFn.
+func (r *CargoRepository) ForEach(fn func(*shipping.Cargo) error) error {
+	r.ForEachInvoked = true
+	return r.ForEachFn(fn)
+}
+
+// FindMatching calls the FindMatchingFn.
+func (r *CargoRepository) FindMatching(q shipping.CargoQuery) ([]*shipping.Cargo, error) {
+	r.FindMatchingInvoked = true
+	return r.FindMatchingFn(q)
+}
+
 // LocationRepository is a mock location repository.
 type LocationRepository struct {
+	StoreFn      func(*shipping.Location) error
+	StoreInvoked bool
+
 	FindFn      func(shipping.UNLocode) (*shipping.Location, error)
 	FindInvoked bool
 
@@ -43,6 +66,12 @@ type LocationRepository struct {
 	FindAllInvoked bool
 }
 
+// Store calls the StoreFn.
+func (r *LocationRepository) Store(l *shipping.Location) error {
+	r.StoreInvoked = true
+	return r.StoreFn(l)
+}
+
 // Find calls the FindFn.
 func (r *LocationRepository) Find(locode shipping.UNLocode) (*shipping.Location, error) {
 	r.FindInvoked = true
@@ -57,8 +86,23 @@ func (r *LocationRepository) FindAll() []*shipping.Location {
 
 // VoyageRepository is a mock voyage repository.
 type VoyageRepository struct {
+	StoreFn      func(*shipping.Voyage) error
+	StoreInvoked bool
+
 	FindFn      func(shipping.VoyageNumber) (*shipping.Voyage, error)
 	FindInvoked bool
+
+	FindAllFn      func() []*shipping.Voyage
+	FindAllInvoked bool
+
+	FindCallingAtFn      func(shipping.UNLocode) []*shipping.Voyage
+	FindCallingAtInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *VoyageRepository) Store(v *shipping.Voyage) error {
+	r.StoreInvoked = true
+	return r.StoreFn(v)
 }
 
 // Find calls the FindFn.
@@ -67,6 +111,213 @@ func (r *VoyageRepository) Find(number shipping.VoyageNumber) (*shipping.Voyage,
 	return r.FindFn(number)
 }
 
+// FindAll calls the FindAllFn.
+func (r *VoyageRepository) FindAll() []*shipping.Voyage {
+	r.FindAllInvoked = true
+	return r.FindAllFn()
+}
+
+// FindCallingAt calls the FindCallingAtFn.
+func (r *VoyageRepository) FindCallingAt(locode shipping.UNLocode) []*shipping.Voyage {
+	r.FindCallingAtInvoked = true
+	return r.FindCallingAtFn(locode)
+}
+
+// ServiceStringRepository is a mock service string repository.
+type ServiceStringRepository struct {
+	StoreFn      func(*shipping.ServiceString) error
+	StoreInvoked bool
+
+	FindFn      func(shipping.ServiceCode) (*shipping.ServiceString, error)
+	FindInvoked bool
+
+	FindAllFn      func() []*shipping.ServiceString
+	FindAllInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *ServiceStringRepository) Store(s *shipping.ServiceString) error {
+	r.StoreInvoked = true
+	return r.StoreFn(s)
+}
+
+// Find calls the FindFn.
+func (r *ServiceStringRepository) Find(code shipping.ServiceCode) (*shipping.ServiceString, error) {
+	r.FindInvoked = true
+	return r.FindFn(code)
+}
+
+// FindAll calls the FindAllFn.
+func (r *ServiceStringRepository) FindAll() []*shipping.ServiceString {
+	r.FindAllInvoked = true
+	return r.FindAllFn()
+}
+
+// PortStatusRepository is a mock port status repository.
+type PortStatusRepository struct {
+	StoreFn      func(shipping.PortStatus) error
+	StoreInvoked bool
+
+	FindAllFn      func() []shipping.PortStatus
+	FindAllInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *PortStatusRepository) Store(s shipping.PortStatus) error {
+	r.StoreInvoked = true
+	return r.StoreFn(s)
+}
+
+// FindAll calls the FindAllFn.
+func (r *PortStatusRepository) FindAll() []shipping.PortStatus {
+	r.FindAllInvoked = true
+	return r.FindAllFn()
+}
+
+// HandlingVarianceRepository is a mock handling variance repository.
+type HandlingVarianceRepository struct {
+	StoreFn      func(shipping.HandlingVariance) error
+	StoreInvoked bool
+
+	FindSinceFn      func(time.Time) []shipping.HandlingVariance
+	FindSinceInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *HandlingVarianceRepository) Store(v shipping.HandlingVariance) error {
+	r.StoreInvoked = true
+	return r.StoreFn(v)
+}
+
+// FindSince calls the FindSinceFn.
+func (r *HandlingVarianceRepository) FindSince(t time.Time) []shipping.HandlingVariance {
+	r.FindSinceInvoked = true
+	return r.FindSinceFn(t)
+}
+
+// ReleaseCodeRepository is a mock release code repository.
+type ReleaseCodeRepository struct {
+	StoreFn      func(*shipping.ReleaseCode) error
+	StoreInvoked bool
+
+	FindFn      func(shipping.TrackingID) (*shipping.ReleaseCode, error)
+	FindInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *ReleaseCodeRepository) Store(c *shipping.ReleaseCode) error {
+	r.StoreInvoked = true
+	return r.StoreFn(c)
+}
+
+// Find calls the FindFn.
+func (r *ReleaseCodeRepository) Find(id shipping.TrackingID) (*shipping.ReleaseCode, error) {
+	r.FindInvoked = true
+	return r.FindFn(id)
+}
+
+// ReportNonceRepository is a mock report nonce repository.
+type ReportNonceRepository struct {
+	AddFn      func(nonce string, now, expires time.Time) error
+	AddInvoked bool
+
+	RemoveFn      func(nonce string) error
+	RemoveInvoked bool
+}
+
+// Add calls the AddFn.
+func (r *ReportNonceRepository) Add(nonce string, now, expires time.Time) error {
+	r.AddInvoked = true
+	return r.AddFn(nonce, now, expires)
+}
+
+// Remove calls the RemoveFn.
+func (r *ReportNonceRepository) Remove(nonce string) error {
+	r.RemoveInvoked = true
+	return r.RemoveFn(nonce)
+}
+
+// TerminalRepository is a mock terminal repository.
+type TerminalRepository struct {
+	StoreFn      func(*shipping.Terminal) error
+	StoreInvoked bool
+
+	FindFn      func(shipping.TerminalID) (*shipping.Terminal, error)
+	FindInvoked bool
+
+	FindAllFn      func() []*shipping.Terminal
+	FindAllInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *TerminalRepository) Store(t *shipping.Terminal) error {
+	r.StoreInvoked = true
+	return r.StoreFn(t)
+}
+
+// Find calls the FindFn.
+func (r *TerminalRepository) Find(id shipping.TerminalID) (*shipping.Terminal, error) {
+	r.FindInvoked = true
+	return r.FindFn(id)
+}
+
+// FindAll calls the FindAllFn.
+func (r *TerminalRepository) FindAll() []*shipping.Terminal {
+	r.FindAllInvoked = true
+	return r.FindAllFn()
+}
+
+// CustomerRepository is a mock customer repository.
+type CustomerRepository struct {
+	StoreFn      func(*shipping.Customer) error
+	StoreInvoked bool
+
+	FindFn      func(shipping.CustomerID) (*shipping.Customer, error)
+	FindInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *CustomerRepository) Store(c *shipping.Customer) error {
+	r.StoreInvoked = true
+	return r.StoreFn(c)
+}
+
+// Find calls the FindFn.
+func (r *CustomerRepository) Find(id shipping.CustomerID) (*shipping.Customer, error) {
+	r.FindInvoked = true
+	return r.FindFn(id)
+}
+
+// SLARepository is a mock SLA repository.
+type SLARepository struct {
+	StoreFn      func(*shipping.SLA) error
+	StoreInvoked bool
+
+	FindFn      func(shipping.ServiceLevel) (*shipping.SLA, error)
+	FindInvoked bool
+
+	FindAllFn      func() []*shipping.SLA
+	FindAllInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *SLARepository) Store(s *shipping.SLA) error {
+	r.StoreInvoked = true
+	return r.StoreFn(s)
+}
+
+// Find calls the FindFn.
+func (r *SLARepository) Find(l shipping.ServiceLevel) (*shipping.SLA, error) {
+	r.FindInvoked = true
+	return r.FindFn(l)
+}
+
+// FindAll calls the FindAllFn.
+func (r *SLARepository) FindAll() []*shipping.SLA {
+	r.FindAllInvoked = true
+	return r.FindAllFn()
+}
+
 // HandlingEventRepository is a mock handling events repository.
 type HandlingEventRepository struct {
 	StoreFn      func(shipping.HandlingEvent)
@@ -74,6 +325,15 @@ type HandlingEventRepository struct {
 
 	QueryHandlingHistoryFn      func(shipping.TrackingID) shipping.HandlingHistory
 	QueryHandlingHistoryInvoked bool
+
+	QueryHandlingHistoryPageFn      func(shipping.TrackingID, shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error)
+	QueryHandlingHistoryPageInvoked bool
+
+	QueryHandlingHistoriesFn      func([]shipping.TrackingID) map[shipping.TrackingID]shipping.HandlingHistory
+	QueryHandlingHistoriesInvoked bool
+
+	ForEachFn      func(func(shipping.HandlingEvent) error) error
+	ForEachInvoked bool
 }
 
 // Store calls the StoreFn.
@@ -88,6 +348,24 @@ func (r *HandlingEventRepository) QueryHandlingHistory(id shipping.TrackingID) s
 	return r.QueryHandlingHistoryFn(id)
 }
 
+// QueryHandlingHistoryPage calls the QueryHandlingHistoryPageFn.
+func (r *HandlingEventRepository) QueryHandlingHistoryPage(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+	r.QueryHandlingHistoryPageInvoked = true
+	return r.QueryHandlingHistoryPageFn(id, q)
+}
+
+// QueryHandlingHistories calls the QueryHandlingHistoriesFn.
+func (r *HandlingEventRepository) QueryHandlingHistories(ids []shipping.TrackingID) map[shipping.TrackingID]shipping.HandlingHistory {
+	r.QueryHandlingHistoriesInvoked = true
+	return r.QueryHandlingHistoriesFn(ids)
+}
+
+// ForEach calls the ForEachFn.
+func (r *HandlingEventRepository) ForEach(fn func(shipping.HandlingEvent) error) error {
+	r.ForEachInvoked = true
+	return r.ForEachFn(fn)
+}
+
 // RoutingService provides a mock routing service.
 type RoutingService struct {
 	FetchRoutesFn      func(shipping.RouteSpecification) []shipping.Itinerary
@@ -99,3 +377,375 @@ func (s *RoutingService) FetchRoutesForSpecification(rs shipping.RouteSpecificat
 	s.FetchRoutesInvoked = true
 	return s.FetchRoutesFn(rs)
 }
+
+// Distances provides mock distances between locations.
+type Distances struct {
+	DistanceFn      func(from, to shipping.UNLocode) (float64, error)
+	DistanceInvoked bool
+}
+
+// Distance calls the DistanceFn.
+func (d *Distances) Distance(from, to shipping.UNLocode) (float64, error) {
+	d.DistanceInvoked = true
+	return d.DistanceFn(from, to)
+}
+
+// UserRepository is a mock user repository.
+type UserRepository struct {
+	StoreFn      func(*shipping.User) error
+	StoreInvoked bool
+
+	FindFn      func(shipping.UserID) (*shipping.User, error)
+	FindInvoked bool
+
+	FindAllFn      func() []*shipping.User
+	FindAllInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *UserRepository) Store(u *shipping.User) error {
+	r.StoreInvoked = true
+	return r.StoreFn(u)
+}
+
+// Find calls the FindFn.
+func (r *UserRepository) Find(id shipping.UserID) (*shipping.User, error) {
+	r.FindInvoked = true
+	return r.FindFn(id)
+}
+
+// FindAll calls the FindAllFn.
+func (r *UserRepository) FindAll() []*shipping.User {
+	r.FindAllInvoked = true
+	return r.FindAllFn()
+}
+
+// AmendmentRepository is a mock amendment repository.
+type AmendmentRepository struct {
+	StoreFn      func(*shipping.Amendment) error
+	StoreInvoked bool
+
+	FindFn      func(shipping.AmendmentID) (*shipping.Amendment, error)
+	FindInvoked bool
+
+	FindByCargoFn      func(shipping.TrackingID) []*shipping.Amendment
+	FindByCargoInvoked bool
+
+	FindPendingFn      func() []*shipping.Amendment
+	FindPendingInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *AmendmentRepository) Store(a *shipping.Amendment) error {
+	r.StoreInvoked = true
+	return r.StoreFn(a)
+}
+
+// Find calls the FindFn.
+func (r *AmendmentRepository) Find(id shipping.AmendmentID) (*shipping.Amendment, error) {
+	r.FindInvoked = true
+	return r.FindFn(id)
+}
+
+// FindByCargo calls the FindByCargoFn.
+func (r *AmendmentRepository) FindByCargo(id shipping.TrackingID) []*shipping.Amendment {
+	r.FindByCargoInvoked = true
+	return r.FindByCargoFn(id)
+}
+
+// FindPending calls the FindPendingFn.
+func (r *AmendmentRepository) FindPending() []*shipping.Amendment {
+	r.FindPendingInvoked = true
+	return r.FindPendingFn()
+}
+
+// TaskRepository is a mock task repository.
+type TaskRepository struct {
+	StoreFn      func(*shipping.Task) error
+	StoreInvoked bool
+
+	FindFn      func(shipping.TaskID) (*shipping.Task, error)
+	FindInvoked bool
+
+	FindByCargoFn      func(shipping.TrackingID) []*shipping.Task
+	FindByCargoInvoked bool
+
+	FindAllFn      func() []*shipping.Task
+	FindAllInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *TaskRepository) Store(t *shipping.Task) error {
+	r.StoreInvoked = true
+	return r.StoreFn(t)
+}
+
+// Find calls the FindFn.
+func (r *TaskRepository) Find(id shipping.TaskID) (*shipping.Task, error) {
+	r.FindInvoked = true
+	return r.FindFn(id)
+}
+
+// FindByCargo calls the FindByCargoFn.
+func (r *TaskRepository) FindByCargo(id shipping.TrackingID) []*shipping.Task {
+	r.FindByCargoInvoked = true
+	return r.FindByCargoFn(id)
+}
+
+// FindAll calls the FindAllFn.
+func (r *TaskRepository) FindAll() []*shipping.Task {
+	r.FindAllInvoked = true
+	return r.FindAllFn()
+}
+
+// AuditRepository is a mock audit repository.
+type AuditRepository struct {
+	AppendFn      func(*shipping.AuditEntry) error
+	AppendInvoked bool
+
+	LastFn      func() (*shipping.AuditEntry, error)
+	LastInvoked bool
+
+	FindFn      func(shipping.AuditQuery) []*shipping.AuditEntry
+	FindInvoked bool
+}
+
+// Append calls the AppendFn.
+func (r *AuditRepository) Append(e *shipping.AuditEntry) error {
+	r.AppendInvoked = true
+	return r.AppendFn(e)
+}
+
+// Last calls the LastFn.
+func (r *AuditRepository) Last() (*shipping.AuditEntry, error) {
+	r.LastInvoked = true
+	return r.LastFn()
+}
+
+// Find calls the FindFn.
+func (r *AuditRepository) Find(q shipping.AuditQuery) []*shipping.AuditEntry {
+	r.FindInvoked = true
+	return r.FindFn(q)
+}
+
+// CargoChangeRepository is a mock cargo change repository.
+type CargoChangeRepository struct {
+	AppendFn      func(*shipping.CargoChange) error
+	AppendInvoked bool
+
+	FindSinceFn      func(uint64, int) []*shipping.CargoChange
+	FindSinceInvoked bool
+
+	FindByCargoFn      func(shipping.TrackingID) []*shipping.CargoChange
+	FindByCargoInvoked bool
+}
+
+// Append calls the AppendFn.
+func (r *CargoChangeRepository) Append(c *shipping.CargoChange) error {
+	r.AppendInvoked = true
+	return r.AppendFn(c)
+}
+
+// FindSince calls the FindSinceFn.
+func (r *CargoChangeRepository) FindSince(seq uint64, limit int) []*shipping.CargoChange {
+	r.FindSinceInvoked = true
+	return r.FindSinceFn(seq, limit)
+}
+
+// FindByCargo calls the FindByCargoFn.
+func (r *CargoChangeRepository) FindByCargo(id shipping.TrackingID) []*shipping.CargoChange {
+	r.FindByCargoInvoked = true
+	return r.FindByCargoFn(id)
+}
+
+// LanePerformanceRepository is a mock lane performance repository.
+type LanePerformanceRepository struct {
+	StoreFn      func(shipping.TrackingID, map[shipping.Lane]shipping.LanePerformance) error
+	StoreInvoked bool
+
+	FindAllFn      func() map[shipping.Lane]shipping.LanePerformance
+	FindAllInvoked bool
+
+	ClearFn      func() error
+	ClearInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *LanePerformanceRepository) Store(id shipping.TrackingID, lanes map[shipping.Lane]shipping.LanePerformance) error {
+	r.StoreInvoked = true
+	return r.StoreFn(id, lanes)
+}
+
+// FindAll calls the FindAllFn.
+func (r *LanePerformanceRepository) FindAll() map[shipping.Lane]shipping.LanePerformance {
+	r.FindAllInvoked = true
+	return r.FindAllFn()
+}
+
+// Clear calls the ClearFn.
+func (r *LanePerformanceRepository) Clear() error {
+	r.ClearInvoked = true
+	return r.ClearFn()
+}
+
+// ProjectionCheckpointRepository is a mock projection checkpoint repository.
+type ProjectionCheckpointRepository struct {
+	StoreFn      func(string, uint64) error
+	StoreInvoked bool
+
+	FindFn      func(string) (uint64, error)
+	FindInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *ProjectionCheckpointRepository) Store(projection string, seq uint64) error {
+	r.StoreInvoked = true
+	return r.StoreFn(projection, seq)
+}
+
+// Find calls the FindFn.
+func (r *ProjectionCheckpointRepository) Find(projection string) (uint64, error) {
+	r.FindInvoked = true
+	return r.FindFn(projection)
+}
+
+// ReplicationLogRepository is a mock replication log repository.
+type ReplicationLogRepository struct {
+	AppendFn      func(*shipping.ReplicationEntry) error
+	AppendInvoked bool
+
+	FindSinceFn      func(uint64, int) []*shipping.ReplicationEntry
+	FindSinceInvoked bool
+
+	LastFn      func() (uint64, error)
+	LastInvoked bool
+}
+
+// Append calls the AppendFn.
+func (r *ReplicationLogRepository) Append(e *shipping.ReplicationEntry) error {
+	r.AppendInvoked = true
+	return r.AppendFn(e)
+}
+
+// FindSince calls the FindSinceFn.
+func (r *ReplicationLogRepository) FindSince(seq uint64, limit int) []*shipping.ReplicationEntry {
+	r.FindSinceInvoked = true
+	return r.FindSinceFn(seq, limit)
+}
+
+// Last calls the LastFn.
+func (r *ReplicationLogRepository) Last() (uint64, error) {
+	r.LastInvoked = true
+	return r.LastFn()
+}
+
+// WorkingCalendarRepository is a mock working calendar repository.
+type WorkingCalendarRepository struct {
+	StoreFn      func(*shipping.WorkingCalendar) error
+	StoreInvoked bool
+
+	FindFn      func(shipping.UNLocode) (*shipping.WorkingCalendar, error)
+	FindInvoked bool
+
+	FindAllFn      func() []*shipping.WorkingCalendar
+	FindAllInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *WorkingCalendarRepository) Store(c *shipping.WorkingCalendar) error {
+	r.StoreInvoked = true
+	return r.StoreFn(c)
+}
+
+// Find calls the FindFn.
+func (r *WorkingCalendarRepository) Find(locode shipping.UNLocode) (*shipping.WorkingCalendar, error) {
+	r.FindInvoked = true
+	return r.FindFn(locode)
+}
+
+// FindAll calls the FindAllFn.
+func (r *WorkingCalendarRepository) FindAll() []*shipping.WorkingCalendar {
+	r.FindAllInvoked = true
+	return r.FindAllFn()
+}
+
+// VesselPositionRepository is a mock vessel position repository.
+type VesselPositionRepository struct {
+	StoreFn      func(shipping.VesselPosition) error
+	StoreInvoked bool
+
+	FindByVoyageFn      func(shipping.VoyageNumber, int) []shipping.VesselPosition
+	FindByVoyageInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *VesselPositionRepository) Store(p shipping.VesselPosition) error {
+	r.StoreInvoked = true
+	return r.StoreFn(p)
+}
+
+// FindByVoyage calls the FindByVoyageFn.
+func (r *VesselPositionRepository) FindByVoyage(v shipping.VoyageNumber, limit int) []shipping.VesselPosition {
+	r.FindByVoyageInvoked = true
+	return r.FindByVoyageFn(v, limit)
+}
+
+// TelemetryRepository is a mock telemetry repository.
+type TelemetryRepository struct {
+	StoreFn      func(shipping.SensorReading) error
+	StoreInvoked bool
+
+	FindFn      func(shipping.TrackingID, shipping.SensorType, time.Time, time.Time) []shipping.SensorReading
+	FindInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *TelemetryRepository) Store(sr shipping.SensorReading) error {
+	r.StoreInvoked = true
+	return r.StoreFn(sr)
+}
+
+// Find calls the FindFn.
+func (r *TelemetryRepository) Find(id shipping.TrackingID, sensor shipping.SensorType, from, to time.Time) []shipping.SensorReading {
+	r.FindInvoked = true
+	return r.FindFn(id, sensor, from, to)
+}
+
+// BookingDraftRepository is a mock booking draft repository.
+type BookingDraftRepository struct {
+	StoreFn      func(*shipping.BookingDraft) error
+	StoreInvoked bool
+
+	FindFn      func(shipping.DraftID) (*shipping.BookingDraft, error)
+	FindInvoked bool
+
+	FindByCustomerFn      func(shipping.CustomerID) []*shipping.BookingDraft
+	FindByCustomerInvoked bool
+
+	RemoveFn      func(shipping.DraftID) error
+	RemoveInvoked bool
+}
+
+// Store calls the StoreFn.
+func (r *BookingDraftRepository) Store(d *shipping.BookingDraft) error {
+	r.StoreInvoked = true
+	return r.StoreFn(d)
+}
+
+// Find calls the FindFn.
+func (r *BookingDraftRepository) Find(id shipping.DraftID) (*shipping.BookingDraft, error) {
+	r.FindInvoked = true
+	return r.FindFn(id)
+}
+
+// FindByCustomer calls the FindByCustomerFn.
+func (r *BookingDraftRepository) FindByCustomer(id shipping.CustomerID) []*shipping.BookingDraft {
+	r.FindByCustomerInvoked = true
+	return r.FindByCustomerFn(id)
+}
+
+// Remove calls the RemoveFn.
+func (r *BookingDraftRepository) Remove(id shipping.DraftID) error {
+	r.RemoveInvoked = true
+	return r.RemoveFn(id)
+}
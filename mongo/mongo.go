@@ -1,6 +1,9 @@
 package mongo
 
 import (
+	"sort"
+	"time"
+
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 
@@ -10,17 +13,17 @@ import (
 type cargoRepository struct {
 	db      string
 	session *mgo.Session
+	retry   RetryPolicy
 }
 
 func (r *cargoRepository) Store(cargo *shipping.Cargo) error {
-	sess := r.session.Copy()
-	defer sess.Close()
-
-	c := sess.DB(r.db).C("cargo")
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("cargo")
 
-	_, err := c.Upsert(bson.M{"trackingid": cargo.TrackingID}, bson.M{"$set": cargo})
+		_, err := c.Upsert(bson.M{"trackingid": cargo.TrackingID}, bson.M{"$set": cargo})
 
-	return err
+		return err
+	})
 }
 
 func (r *cargoRepository) Find(id shipping.TrackingID) (*shipping.Cargo, error) {
@@ -54,11 +57,98 @@ func (r *cargoRepository) FindAll() []*shipping.Cargo {
 	return result
 }
 
+func (r *cargoRepository) ForEach(fn func(*shipping.Cargo) error) error {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("cargo")
+
+	iter := c.Find(bson.M{}).Iter()
+
+	var result shipping.Cargo
+	for iter.Next(&result) {
+		cargo := result
+		if err := fn(&cargo); err != nil {
+			iter.Close()
+			return err
+		}
+		result = shipping.Cargo{}
+	}
+
+	return iter.Close()
+}
+
+func (r *cargoRepository) FindMatching(q shipping.CargoQuery) ([]*shipping.Cargo, error) {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("cargo")
+
+	var result []*shipping.Cargo
+	if err := c.Find(cargoFilter(q)).All(&result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// cargoFields maps the searchable fields of cargos to the document paths
+// they are stored at.
+var cargoFields = map[shipping.CargoField]string{
+	shipping.FieldStatus:      "delivery.transportstatus",
+	shipping.FieldRouting:     "delivery.routingstatus",
+	shipping.FieldOrigin:      "origin",
+	shipping.FieldDestination: "routespecification.destination",
+	shipping.FieldLocation:    "delivery.lastknownlocation",
+	shipping.FieldVoyage:      "delivery.currentvoyage",
+	shipping.FieldCustomer:    "customer",
+	shipping.FieldEquipment:   "equipment",
+	shipping.FieldETA:         "delivery.eta",
+	shipping.FieldDeadline:    "routespecification.arrivaldeadline",
+	shipping.FieldMisdirected: "delivery.ismisdirected",
+}
+
+var cargoOperators = map[shipping.Operator]string{
+	shipping.OpNotEqual:       "$ne",
+	shipping.OpLess:           "$lt",
+	shipping.OpLessOrEqual:    "$lte",
+	shipping.OpGreater:        "$gt",
+	shipping.OpGreaterOrEqual: "$gte",
+}
+
+// cargoFilter translates a cargo query into a query document.
+func cargoFilter(q shipping.CargoQuery) bson.M {
+	if len(q.Conditions) == 0 {
+		return bson.M{}
+	}
+
+	conds := make([]bson.M, len(q.Conditions))
+	for i, cond := range q.Conditions {
+		path := cargoFields[cond.Field]
+		switch cond.Op {
+		case shipping.OpEqual:
+			conds[i] = bson.M{path: cond.Values[0]}
+		case shipping.OpIn:
+			conds[i] = bson.M{path: bson.M{"$in": cond.Values}}
+		case shipping.OpLess, shipping.OpLessOrEqual:
+			// Cargos not yet routed have no ETA, stored as the zero time.
+			conds[i] = bson.M{path: bson.M{cargoOperators[cond.Op]: cond.Values[0], "$gt": time.Time{}}}
+		default:
+			conds[i] = bson.M{path: bson.M{cargoOperators[cond.Op]: cond.Values[0]}}
+		}
+	}
+
+	return bson.M{"$and": conds}
+}
+
 // NewCargoRepository returns a new instance of a MongoDB cargo repository.
-func NewCargoRepository(db string, session *mgo.Session) (shipping.CargoRepository, error) {
+func NewCargoRepository(db string, session *mgo.Session, opts ...Option) (shipping.CargoRepository, error) {
+	cfg := newConfig(opts)
+
 	r := &cargoRepository{
 		db:      db,
-		session: session,
+		session: cfg.session(session),
+		retry:   cfg.retry,
 	}
 
 	index := mgo.Index{
@@ -84,6 +174,7 @@ func NewCargoRepository(db string, session *mgo.Session) (shipping.CargoReposito
 type locationRepository struct {
 	db      string
 	session *mgo.Session
+	retry   RetryPolicy
 }
 
 func (r *locationRepository) Find(locode shipping.UNLocode) (*shipping.Location, error) {
@@ -117,22 +208,24 @@ func (r *locationRepository) FindAll() []*shipping.Location {
 	return result
 }
 
-func (r *locationRepository) store(l *shipping.Location) error {
-	sess := r.session.Copy()
-	defer sess.Close()
-
-	c := sess.DB(r.db).C("location")
+func (r *locationRepository) Store(l *shipping.Location) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("location")
 
-	_, err := c.Upsert(bson.M{"unlocode": l.UNLocode}, bson.M{"$set": l})
+		_, err := c.Upsert(bson.M{"unlocode": l.UNLocode}, bson.M{"$set": l})
 
-	return err
+		return err
+	})
 }
 
 // NewLocationRepository returns a new instance of a MongoDB location repository.
-func NewLocationRepository(db string, session *mgo.Session) (shipping.LocationRepository, error) {
+func NewLocationRepository(db string, session *mgo.Session, opts ...Option) (shipping.LocationRepository, error) {
+	cfg := newConfig(opts)
+
 	r := &locationRepository{
 		db:      db,
-		session: session,
+		session: cfg.session(session),
+		retry:   cfg.retry,
 	}
 
 	sess := r.session.Copy()
@@ -162,7 +255,7 @@ func NewLocationRepository(db string, session *mgo.Session) (shipping.LocationRe
 	}
 
 	for _, l := range initial {
-		r.store(l)
+		r.Store(l)
 	}
 
 	return r, nil
@@ -171,6 +264,7 @@ func NewLocationRepository(db string, session *mgo.Session) (shipping.LocationRe
 type voyageRepository struct {
 	db      string
 	session *mgo.Session
+	retry   RetryPolicy
 }
 
 func (r *voyageRepository) Find(voyageNumber shipping.VoyageNumber) (*shipping.Voyage, error) {
@@ -190,22 +284,49 @@ func (r *voyageRepository) Find(voyageNumber shipping.VoyageNumber) (*shipping.V
 	return &result, nil
 }
 
-func (r *voyageRepository) store(v *shipping.Voyage) error {
+func (r *voyageRepository) FindAll() []*shipping.Voyage {
+	return r.find(bson.M{})
+}
+
+func (r *voyageRepository) FindCallingAt(locode shipping.UNLocode) []*shipping.Voyage {
+	return r.find(bson.M{"$or": []bson.M{
+		{"schedule.carriermovements.departurelocation": locode},
+		{"schedule.carriermovements.arrivallocation": locode},
+	}})
+}
+
+func (r *voyageRepository) find(filter bson.M) []*shipping.Voyage {
 	sess := r.session.Copy()
 	defer sess.Close()
 
 	c := sess.DB(r.db).C("voyage")
 
-	_, err := c.Upsert(bson.M{"number": v.VoyageNumber}, bson.M{"$set": v})
+	var result []*shipping.Voyage
+	if err := c.Find(filter).Sort("number").All(&result); err != nil {
+		return []*shipping.Voyage{}
+	}
+
+	return result
+}
+
+func (r *voyageRepository) Store(v *shipping.Voyage) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("voyage")
 
-	return err
+		_, err := c.Upsert(bson.M{"number": v.VoyageNumber}, bson.M{"$set": v})
+
+		return err
+	})
 }
 
 // NewVoyageRepository returns a new instance of a MongoDB voyage repository.
-func NewVoyageRepository(db string, session *mgo.Session) (shipping.VoyageRepository, error) {
+func NewVoyageRepository(db string, session *mgo.Session, opts ...Option) (shipping.VoyageRepository, error) {
+	cfg := newConfig(opts)
+
 	r := &voyageRepository{
 		db:      db,
-		session: session,
+		session: cfg.session(session),
+		retry:   cfg.retry,
 	}
 
 	sess := r.session.Copy()
@@ -237,42 +358,1735 @@ func NewVoyageRepository(db string, session *mgo.Session) (shipping.VoyageReposi
 	}
 
 	for _, v := range initial {
-		r.store(v)
+		r.Store(v)
 	}
 
 	return r, nil
 }
 
-type handlingEventRepository struct {
+type serviceStringRepository struct {
 	db      string
 	session *mgo.Session
+	retry   RetryPolicy
 }
 
-func (r *handlingEventRepository) Store(e shipping.HandlingEvent) {
+func (r *serviceStringRepository) Find(code shipping.ServiceCode) (*shipping.ServiceString, error) {
 	sess := r.session.Copy()
 	defer sess.Close()
 
-	c := sess.DB(r.db).C("handling_event")
+	c := sess.DB(r.db).C("service_string")
+
+	var result shipping.ServiceString
+	if err := c.Find(bson.M{"code": code}).One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, shipping.ErrUnknownServiceString
+		}
+		return nil, err
+	}
 
-	_ = c.Insert(e)
+	return &result, nil
 }
 
-func (r *handlingEventRepository) QueryHandlingHistory(id shipping.TrackingID) shipping.HandlingHistory {
+func (r *serviceStringRepository) FindAll() []*shipping.ServiceString {
 	sess := r.session.Copy()
 	defer sess.Close()
 
-	c := sess.DB(r.db).C("handling_event")
+	c := sess.DB(r.db).C("service_string")
 
-	var result []shipping.HandlingEvent
-	_ = c.Find(bson.M{"trackingid": id}).All(&result)
+	var result []*shipping.ServiceString
+	if err := c.Find(bson.M{}).Sort("code").All(&result); err != nil {
+		return []*shipping.ServiceString{}
+	}
 
-	return shipping.HandlingHistory{HandlingEvents: result}
+	return result
 }
 
-// NewHandlingEventRepository returns a new instance of a MongoDB handling event repository.
-func NewHandlingEventRepository(db string, session *mgo.Session) shipping.HandlingEventRepository {
+func (r *serviceStringRepository) Store(s *shipping.ServiceString) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("service_string")
+
+		_, err := c.Upsert(bson.M{"code": s.Code}, bson.M{"$set": s})
+
+		return err
+	})
+}
+
+// NewServiceStringRepository returns a new instance of a MongoDB service
+// string repository.
+func NewServiceStringRepository(db string, session *mgo.Session, opts ...Option) (shipping.ServiceStringRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &serviceStringRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("service_string")
+
+	index := mgo.Index{
+		Key:        []string{"code"},
+		Unique:     true,
+		DropDups:   true,
+		Background: true,
+		Sparse:     true,
+	}
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	initial := []*shipping.ServiceString{
+		shipping.TP1,
+		shipping.AE1,
+		shipping.NE1,
+	}
+
+	for _, s := range initial {
+		r.Store(s)
+	}
+
+	return r, nil
+}
+
+type portStatusRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *portStatusRepository) Store(s shipping.PortStatus) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("port_status")
+
+		_, err := c.Upsert(bson.M{"location": s.Location}, bson.M{"$set": s})
+
+		return err
+	})
+}
+
+func (r *portStatusRepository) FindAll() []shipping.PortStatus {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("port_status")
+
+	var result []shipping.PortStatus
+	if err := c.Find(bson.M{}).Sort("location").All(&result); err != nil {
+		return []shipping.PortStatus{}
+	}
+
+	return result
+}
+
+// NewPortStatusRepository returns a new instance of a MongoDB port status
+// repository.
+func NewPortStatusRepository(db string, session *mgo.Session, opts ...Option) (shipping.PortStatusRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &portStatusRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("port_status")
+
+	index := mgo.Index{
+		Key:        []string{"location"},
+		Unique:     true,
+		DropDups:   true,
+		Background: true,
+		Sparse:     true,
+	}
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+type terminalRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *terminalRepository) Store(t *shipping.Terminal) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("terminal")
+
+		_, err := c.Upsert(bson.M{"id": t.ID}, bson.M{"$set": t})
+
+		return err
+	})
+}
+
+func (r *terminalRepository) Find(id shipping.TerminalID) (*shipping.Terminal, error) {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("terminal")
+
+	var result shipping.Terminal
+	if err := c.Find(bson.M{"id": id}).One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, shipping.ErrUnknownTerminal
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *terminalRepository) FindAll() []*shipping.Terminal {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("terminal")
+
+	var result []*shipping.Terminal
+	if err := c.Find(bson.M{}).Sort("id").All(&result); err != nil {
+		return []*shipping.Terminal{}
+	}
+
+	return result
+}
+
+// NewTerminalRepository returns a new instance of a MongoDB terminal
+// repository.
+func NewTerminalRepository(db string, session *mgo.Session, opts ...Option) (shipping.TerminalRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &terminalRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("terminal")
+
+	index := mgo.Index{
+		Key:        []string{"id"},
+		Unique:     true,
+		DropDups:   true,
+		Background: true,
+		Sparse:     true,
+	}
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+type customerRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *customerRepository) Store(cu *shipping.Customer) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("customer")
+
+		_, err := c.Upsert(bson.M{"id": cu.ID}, bson.M{"$set": cu})
+
+		return err
+	})
+}
+
+func (r *customerRepository) Find(id shipping.CustomerID) (*shipping.Customer, error) {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("customer")
+
+	var result shipping.Customer
+	if err := c.Find(bson.M{"id": id}).One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, shipping.ErrUnknownCustomer
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// NewCustomerRepository returns a new instance of a MongoDB customer
+// repository.
+func NewCustomerRepository(db string, session *mgo.Session, opts ...Option) (shipping.CustomerRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &customerRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("customer")
+
+	index := mgo.Index{
+		Key:        []string{"id"},
+		Unique:     true,
+		DropDups:   true,
+		Background: true,
+		Sparse:     true,
+	}
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+type slaRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *slaRepository) Store(s *shipping.SLA) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("sla")
+
+		_, err := c.Upsert(bson.M{"level": s.Level}, bson.M{"$set": s})
+
+		return err
+	})
+}
+
+func (r *slaRepository) Find(l shipping.ServiceLevel) (*shipping.SLA, error) {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("sla")
+
+	var result shipping.SLA
+	if err := c.Find(bson.M{"level": l}).One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, shipping.ErrUnknownServiceLevel
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *slaRepository) FindAll() []*shipping.SLA {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("sla")
+
+	var result []*shipping.SLA
+	if err := c.Find(bson.M{}).Sort("level").All(&result); err != nil {
+		return []*shipping.SLA{}
+	}
+
+	return result
+}
+
+// NewSLARepository returns a new instance of a MongoDB SLA repository.
+func NewSLARepository(db string, session *mgo.Session, opts ...Option) (shipping.SLARepository, error) {
+	cfg := newConfig(opts)
+
+	r := &slaRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("sla")
+
+	index := mgo.Index{
+		Key:        []string{"level"},
+		Unique:     true,
+		DropDups:   true,
+		Background: true,
+		Sparse:     true,
+	}
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+type userRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *userRepository) Store(u *shipping.User) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("user")
+
+		_, err := c.Upsert(bson.M{"id": u.ID}, bson.M{"$set": u})
+
+		return err
+	})
+}
+
+func (r *userRepository) Find(id shipping.UserID) (*shipping.User, error) {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("user")
+
+	var result shipping.User
+	if err := c.Find(bson.M{"id": id}).One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, shipping.ErrUnknownUser
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *userRepository) FindAll() []*shipping.User {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("user")
+
+	var result []*shipping.User
+	if err := c.Find(bson.M{}).Sort("id").All(&result); err != nil {
+		return []*shipping.User{}
+	}
+
+	return result
+}
+
+// NewUserRepository returns a new instance of a MongoDB user repository.
+func NewUserRepository(db string, session *mgo.Session, opts ...Option) (shipping.UserRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &userRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("user")
+
+	index := mgo.Index{
+		Key:        []string{"id"},
+		Unique:     true,
+		DropDups:   true,
+		Background: true,
+		Sparse:     true,
+	}
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+type amendmentRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *amendmentRepository) Store(a *shipping.Amendment) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("amendment")
+
+		_, err := c.Upsert(bson.M{"id": a.ID}, bson.M{"$set": a})
+
+		return err
+	})
+}
+
+func (r *amendmentRepository) Find(id shipping.AmendmentID) (*shipping.Amendment, error) {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("amendment")
+
+	var result shipping.Amendment
+	if err := c.Find(bson.M{"id": id}).One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, shipping.ErrUnknownAmendment
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *amendmentRepository) FindByCargo(id shipping.TrackingID) []*shipping.Amendment {
+	return r.find(bson.M{"trackingid": id})
+}
+
+func (r *amendmentRepository) FindPending() []*shipping.Amendment {
+	return r.find(bson.M{"status": shipping.AmendmentPending})
+}
+
+func (r *amendmentRepository) find(filter bson.M) []*shipping.Amendment {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("amendment")
+
+	var result []*shipping.Amendment
+	if err := c.Find(filter).Sort("requested").All(&result); err != nil {
+		return []*shipping.Amendment{}
+	}
+
+	return result
+}
+
+// NewAmendmentRepository returns a new instance of a MongoDB amendment
+// repository.
+func NewAmendmentRepository(db string, session *mgo.Session, opts ...Option) (shipping.AmendmentRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &amendmentRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("amendment")
+
+	indexes := []mgo.Index{
+		{
+			Key:        []string{"id"},
+			Unique:     true,
+			DropDups:   true,
+			Background: true,
+			Sparse:     true,
+		},
+		{
+			Key:        []string{"trackingid", "requested"},
+			Background: true,
+		},
+		{
+			Key:        []string{"status", "requested"},
+			Background: true,
+		},
+	}
+
+	for _, index := range indexes {
+		if err := c.EnsureIndex(index); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+type taskRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *taskRepository) Store(t *shipping.Task) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("task")
+
+		_, err := c.Upsert(bson.M{"id": t.ID}, bson.M{"$set": t})
+
+		return err
+	})
+}
+
+func (r *taskRepository) Find(id shipping.TaskID) (*shipping.Task, error) {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("task")
+
+	var result shipping.Task
+	if err := c.Find(bson.M{"id": id}).One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, shipping.ErrUnknownTask
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *taskRepository) FindByCargo(id shipping.TrackingID) []*shipping.Task {
+	return r.find(bson.M{"trackingid": id})
+}
+
+func (r *taskRepository) FindAll() []*shipping.Task {
+	return r.find(bson.M{})
+}
+
+func (r *taskRepository) find(filter bson.M) []*shipping.Task {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("task")
+
+	var result []*shipping.Task
+	if err := c.Find(filter).Sort("opened").All(&result); err != nil {
+		return []*shipping.Task{}
+	}
+
+	return result
+}
+
+// NewTaskRepository returns a new instance of a MongoDB task repository.
+func NewTaskRepository(db string, session *mgo.Session, opts ...Option) (shipping.TaskRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &taskRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("task")
+
+	indexes := []mgo.Index{
+		{
+			Key:        []string{"id"},
+			Unique:     true,
+			DropDups:   true,
+			Background: true,
+			Sparse:     true,
+		},
+		{
+			Key:        []string{"trackingid", "opened"},
+			Background: true,
+		},
+		{
+			Key:        []string{"opened"},
+			Background: true,
+		},
+	}
+
+	for _, index := range indexes {
+		if err := c.EnsureIndex(index); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+type auditRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *auditRepository) Append(e *shipping.AuditEntry) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("audit")
+
+		err := c.Insert(e)
+		if mgo.IsDup(err) {
+			return shipping.ErrAuditConflict
+		}
+
+		return err
+	})
+}
+
+func (r *auditRepository) Last() (*shipping.AuditEntry, error) {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("audit")
+
+	var result shipping.AuditEntry
+	if err := c.Find(bson.M{}).Sort("-sequence").One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *auditRepository) Find(q shipping.AuditQuery) []*shipping.AuditEntry {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("audit")
+
+	filter := bson.M{}
+	if q.TrackingID != "" {
+		filter["trackingid"] = q.TrackingID
+	}
+	if q.Actor != "" {
+		filter["actor"] = q.Actor
+	}
+	if q.Action != "" {
+		filter["action"] = q.Action
+	}
+	if !q.From.IsZero() || !q.To.IsZero() {
+		t := bson.M{}
+		if !q.From.IsZero() {
+			t["$gte"] = q.From
+		}
+		if !q.To.IsZero() {
+			t["$lt"] = q.To
+		}
+		filter["time"] = t
+	}
+
+	var result []*shipping.AuditEntry
+	if err := c.Find(filter).Sort("sequence").All(&result); err != nil {
+		return []*shipping.AuditEntry{}
+	}
+
+	return result
+}
+
+// NewAuditRepository returns a new instance of a MongoDB audit repository.
+func NewAuditRepository(db string, session *mgo.Session, opts ...Option) (shipping.AuditRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &auditRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("audit")
+
+	indexes := []mgo.Index{
+		{
+			Key:        []string{"sequence"},
+			Unique:     true,
+			Background: true,
+		},
+		{
+			Key:        []string{"trackingid", "sequence"},
+			Background: true,
+		},
+		{
+			Key:        []string{"actor", "sequence"},
+			Background: true,
+		},
+		{
+			Key:        []string{"time"},
+			Background: true,
+		},
+	}
+
+	for _, index := range indexes {
+		if err := c.EnsureIndex(index); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+type cargoChangeRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *cargoChangeRepository) Append(ch *shipping.CargoChange) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		// Sequence numbers are handed out by a counter, so that every
+		// instance numbers changes from the same sequence. A failed insert
+		// leaves a gap, which readers skip.
+		var counter struct {
+			Seq uint64 `bson:"seq"`
+		}
+		if _, err := sess.DB(r.db).C("counter").FindId("cargochange").Apply(mgo.Change{
+			Update:    bson.M{"$inc": bson.M{"seq": 1}},
+			Upsert:    true,
+			ReturnNew: true,
+		}, &counter); err != nil {
+			return err
+		}
+
+		ch.Sequence = counter.Seq
+
+		return sess.DB(r.db).C("cargochange").Insert(ch)
+	})
+}
+
+func (r *cargoChangeRepository) FindSince(seq uint64, limit int) []*shipping.CargoChange {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("cargochange")
+
+	var result []*shipping.CargoChange
+	if err := c.Find(bson.M{"sequence": bson.M{"$gt": seq}}).Sort("sequence").Limit(limit).All(&result); err != nil {
+		return []*shipping.CargoChange{}
+	}
+
+	return result
+}
+
+func (r *cargoChangeRepository) FindByCargo(id shipping.TrackingID) []*shipping.CargoChange {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("cargochange")
+
+	var result []*shipping.CargoChange
+	if err := c.Find(bson.M{"trackingid": id}).Sort("sequence").All(&result); err != nil {
+		return []*shipping.CargoChange{}
+	}
+
+	return result
+}
+
+// NewCargoChangeRepository returns a new instance of a MongoDB cargo change
+// repository.
+func NewCargoChangeRepository(db string, session *mgo.Session, opts ...Option) (shipping.CargoChangeRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &cargoChangeRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("cargochange")
+
+	indexes := []mgo.Index{
+		{
+			Key:        []string{"sequence"},
+			Unique:     true,
+			Background: true,
+		},
+		{
+			Key:        []string{"trackingid", "sequence"},
+			Background: true,
+		},
+	}
+
+	for _, index := range indexes {
+		if err := c.EnsureIndex(index); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+type lanePerformanceRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+// lanePerformanceDocument is the performance of the legs sailed by a cargo,
+// with lanes listed rather than keyed since they are not strings.
+type lanePerformanceDocument struct {
+	TrackingID shipping.TrackingID `bson:"_id"`
+	Lanes      []lanePerformance   `bson:"lanes"`
+}
+
+type lanePerformance struct {
+	Lane                     shipping.Lane `bson:"lane"`
+	shipping.LanePerformance `bson:",inline"`
+}
+
+func (r *lanePerformanceRepository) Store(id shipping.TrackingID, lanes map[shipping.Lane]shipping.LanePerformance) error {
+	doc := lanePerformanceDocument{TrackingID: id, Lanes: make([]lanePerformance, 0, len(lanes))}
+	for k, p := range lanes {
+		doc.Lanes = append(doc.Lanes, lanePerformance{Lane: k, LanePerformance: p})
+	}
+
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		_, err := sess.DB(r.db).C("lane_performance").UpsertId(id, doc)
+		return err
+	})
+}
+
+func (r *lanePerformanceRepository) FindAll() map[shipping.Lane]shipping.LanePerformance {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("lane_performance")
+
+	// Sum up the performance of the cargos on the server.
+	pipeline := []bson.M{
+		{"$unwind": "$lanes"},
+		{"$group": bson.M{
+			"_id":        "$lanes.lane",
+			"sailed":     bson.M{"$sum": "$lanes.sailed"},
+			"ontime":     bson.M{"$sum": "$lanes.ontime"},
+			"misrouted":  bson.M{"$sum": "$lanes.misrouted"},
+			"totaldelay": bson.M{"$sum": "$lanes.totaldelay"},
+		}},
+	}
+
+	var totals []struct {
+		Lane                     shipping.Lane `bson:"_id"`
+		shipping.LanePerformance `bson:",inline"`
+	}
+	result := make(map[shipping.Lane]shipping.LanePerformance)
+	if err := c.Pipe(pipeline).All(&totals); err != nil {
+		return result
+	}
+
+	for _, t := range totals {
+		result[t.Lane] = t.LanePerformance
+	}
+	return result
+}
+
+func (r *lanePerformanceRepository) Clear() error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		_, err := sess.DB(r.db).C("lane_performance").RemoveAll(nil)
+		return err
+	})
+}
+
+// NewLanePerformanceRepository returns a new instance of a MongoDB lane
+// performance repository. The performance is kept by cargo and summed up by
+// lane when read.
+func NewLanePerformanceRepository(db string, session *mgo.Session, opts ...Option) (shipping.LanePerformanceRepository, error) {
+	cfg := newConfig(opts)
+
+	return &lanePerformanceRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}, nil
+}
+
+type projectionCheckpointRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *projectionCheckpointRepository) Store(projection string, seq uint64) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("checkpoint")
+
+		_, err := c.UpsertId(projection, bson.M{"$set": bson.M{"seq": seq}})
+
+		return err
+	})
+}
+
+func (r *projectionCheckpointRepository) Find(projection string) (uint64, error) {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("checkpoint")
+
+	var result struct {
+		Seq uint64 `bson:"seq"`
+	}
+	if err := c.FindId(projection).One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return result.Seq, nil
+}
+
+// NewProjectionCheckpointRepository returns a new instance of a MongoDB
+// projection checkpoint repository. Checkpoints are keyed by projection name.
+func NewProjectionCheckpointRepository(db string, session *mgo.Session, opts ...Option) (shipping.ProjectionCheckpointRepository, error) {
+	cfg := newConfig(opts)
+
+	return &projectionCheckpointRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}, nil
+}
+
+type replicationLogRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *replicationLogRepository) Append(e *shipping.ReplicationEntry) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		// Numbered from a counter as cargo changes are, for every instance
+		// to append to the same sequence.
+		var counter struct {
+			Seq uint64 `bson:"seq"`
+		}
+		if _, err := sess.DB(r.db).C("counter").FindId("replication").Apply(mgo.Change{
+			Update:    bson.M{"$inc": bson.M{"seq": 1}},
+			Upsert:    true,
+			ReturnNew: true,
+		}, &counter); err != nil {
+			return err
+		}
+
+		e.Sequence = counter.Seq
+
+		return sess.DB(r.db).C("replication").Insert(e)
+	})
+}
+
+func (r *replicationLogRepository) FindSince(seq uint64, limit int) []*shipping.ReplicationEntry {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("replication")
+
+	var result []*shipping.ReplicationEntry
+	if err := c.Find(bson.M{"sequence": bson.M{"$gt": seq}}).Sort("sequence").Limit(limit).All(&result); err != nil {
+		return []*shipping.ReplicationEntry{}
+	}
+
+	return result
+}
+
+func (r *replicationLogRepository) Last() (uint64, error) {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("replication")
+
+	var result shipping.ReplicationEntry
+	if err := c.Find(nil).Sort("-sequence").One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return result.Sequence, nil
+}
+
+// NewReplicationLogRepository returns a new instance of a MongoDB replication
+// log repository.
+func NewReplicationLogRepository(db string, session *mgo.Session, opts ...Option) (shipping.ReplicationLogRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &replicationLogRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("replication")
+
+	index := mgo.Index{
+		Key:        []string{"sequence"},
+		Unique:     true,
+		Background: true,
+	}
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+type workingCalendarRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *workingCalendarRepository) Store(cal *shipping.WorkingCalendar) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("calendar")
+
+		_, err := c.Upsert(bson.M{"location": cal.Location}, bson.M{"$set": cal})
+
+		return err
+	})
+}
+
+func (r *workingCalendarRepository) Find(locode shipping.UNLocode) (*shipping.WorkingCalendar, error) {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("calendar")
+
+	var result shipping.WorkingCalendar
+	if err := c.Find(bson.M{"location": locode}).One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, shipping.ErrUnknownCalendar
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *workingCalendarRepository) FindAll() []*shipping.WorkingCalendar {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("calendar")
+
+	var result []*shipping.WorkingCalendar
+	if err := c.Find(bson.M{}).Sort("location").All(&result); err != nil {
+		return []*shipping.WorkingCalendar{}
+	}
+
+	return result
+}
+
+// NewWorkingCalendarRepository returns a new instance of a MongoDB working
+// calendar repository.
+func NewWorkingCalendarRepository(db string, session *mgo.Session, opts ...Option) (shipping.WorkingCalendarRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &workingCalendarRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("calendar")
+
+	index := mgo.Index{
+		Key:        []string{"location"},
+		Unique:     true,
+		DropDups:   true,
+		Background: true,
+		Sparse:     true,
+	}
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+type vesselPositionRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *vesselPositionRepository) Store(p shipping.VesselPosition) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		return sess.DB(r.db).C("position").Insert(p)
+	})
+}
+
+func (r *vesselPositionRepository) FindByVoyage(v shipping.VoyageNumber, limit int) []shipping.VesselPosition {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("position")
+
+	var result []shipping.VesselPosition
+	if err := c.Find(bson.M{"voyagenumber": v}).Sort("-reported").Limit(limit).All(&result); err != nil {
+		return []shipping.VesselPosition{}
+	}
+
+	return result
+}
+
+// NewVesselPositionRepository returns a new instance of a MongoDB vessel
+// position repository.
+func NewVesselPositionRepository(db string, session *mgo.Session, opts ...Option) (shipping.VesselPositionRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &vesselPositionRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("position")
+
+	index := mgo.Index{
+		Key:        []string{"voyagenumber", "-reported"},
+		Background: true,
+	}
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// telemetryBucket holds the readings of a sensor type of a cargo recorded
+// within the same hour, so that a cargo reporting every few seconds takes a
+// document an hour rather than one a reading.
+type telemetryBucket struct {
+	TrackingID shipping.TrackingID
+	Sensor     shipping.SensorType
+	Hour       time.Time
+	Readings   []shipping.SensorReading
+}
+
+type telemetryRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *telemetryRepository) Store(sr shipping.SensorReading) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("telemetry")
+		_, err := c.Upsert(
+			bson.M{"trackingid": sr.TrackingID, "sensor": sr.Sensor, "hour": sr.Recorded.UTC().Truncate(time.Hour)},
+			bson.M{"$push": bson.M{"readings": sr}},
+		)
+		return err
+	})
+}
+
+func (r *telemetryRepository) Find(id shipping.TrackingID, sensor shipping.SensorType, from, to time.Time) []shipping.SensorReading {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("telemetry")
+
+	q := bson.M{"trackingid": id}
+	if sensor != "" {
+		q["sensor"] = sensor
+	}
+	hour := bson.M{}
+	if !from.IsZero() {
+		hour["$gte"] = from.UTC().Truncate(time.Hour)
+	}
+	if !to.IsZero() {
+		hour["$lte"] = to.UTC()
+	}
+	if len(hour) > 0 {
+		q["hour"] = hour
+	}
+
+	var buckets []telemetryBucket
+	if err := c.Find(q).Sort("hour").All(&buckets); err != nil {
+		return []shipping.SensorReading{}
+	}
+
+	result := []shipping.SensorReading{}
+	for _, b := range buckets {
+		for _, sr := range b.Readings {
+			if !from.IsZero() && sr.Recorded.Before(from) || !to.IsZero() && sr.Recorded.After(to) {
+				continue
+			}
+			result = append(result, sr)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Recorded.Before(result[j].Recorded)
+	})
+
+	return result
+}
+
+// NewTelemetryRepository returns a new instance of a MongoDB telemetry
+// repository.
+func NewTelemetryRepository(db string, session *mgo.Session, opts ...Option) (shipping.TelemetryRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &telemetryRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("telemetry")
+
+	index := mgo.Index{
+		Key:        []string{"trackingid", "sensor", "hour"},
+		Unique:     true,
+		Background: true,
+	}
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+type bookingDraftRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *bookingDraftRepository) Store(d *shipping.BookingDraft) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("draft")
+
+		_, err := c.Upsert(bson.M{"id": d.ID}, bson.M{"$set": d})
+
+		return err
+	})
+}
+
+func (r *bookingDraftRepository) Find(id shipping.DraftID) (*shipping.BookingDraft, error) {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("draft")
+
+	var result shipping.BookingDraft
+	if err := c.Find(bson.M{"id": id}).One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, shipping.ErrUnknownDraft
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *bookingDraftRepository) FindByCustomer(id shipping.CustomerID) []*shipping.BookingDraft {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("draft")
+
+	var result []*shipping.BookingDraft
+	if err := c.Find(bson.M{"customer": id}).Sort("-saved").All(&result); err != nil {
+		return []*shipping.BookingDraft{}
+	}
+
+	return result
+}
+
+func (r *bookingDraftRepository) Remove(id shipping.DraftID) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("draft")
+
+		if err := c.Remove(bson.M{"id": id}); err != nil {
+			if err == mgo.ErrNotFound {
+				return shipping.ErrUnknownDraft
+			}
+			return err
+		}
+
+		return nil
+	})
+}
+
+// NewBookingDraftRepository returns a new instance of a MongoDB booking
+// draft repository.
+func NewBookingDraftRepository(db string, session *mgo.Session, opts ...Option) (shipping.BookingDraftRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &bookingDraftRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("draft")
+
+	indexes := []mgo.Index{
+		{
+			Key:        []string{"id"},
+			Unique:     true,
+			DropDups:   true,
+			Background: true,
+			Sparse:     true,
+		},
+		{
+			Key:        []string{"customer", "-saved"},
+			Background: true,
+		},
+	}
+
+	for _, index := range indexes {
+		if err := c.EnsureIndex(index); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+type handlingVarianceRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *handlingVarianceRepository) Store(v shipping.HandlingVariance) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("handling_variance")
+		return c.Insert(v)
+	})
+}
+
+func (r *handlingVarianceRepository) FindSince(t time.Time) []shipping.HandlingVariance {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("handling_variance")
+
+	result := []shipping.HandlingVariance{}
+	if err := c.Find(bson.M{"completed": bson.M{"$gte": t}}).All(&result); err != nil {
+		return []shipping.HandlingVariance{}
+	}
+
+	return result
+}
+
+// NewHandlingVarianceRepository returns a new instance of a MongoDB handling
+// variance repository.
+func NewHandlingVarianceRepository(db string, session *mgo.Session, opts ...Option) (shipping.HandlingVarianceRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &handlingVarianceRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("handling_variance")
+
+	index := mgo.Index{
+		Key:        []string{"completed"},
+		Background: true,
+	}
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+type releaseCodeRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *releaseCodeRepository) Store(rc *shipping.ReleaseCode) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("release_code")
+
+		_, err := c.Upsert(bson.M{"trackingid": rc.TrackingID}, bson.M{"$set": rc})
+
+		return err
+	})
+}
+
+func (r *releaseCodeRepository) Find(id shipping.TrackingID) (*shipping.ReleaseCode, error) {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("release_code")
+
+	var result shipping.ReleaseCode
+	if err := c.Find(bson.M{"trackingid": id}).One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, shipping.ErrNoReleaseCode
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// NewReleaseCodeRepository returns a new instance of a MongoDB release code
+// repository.
+func NewReleaseCodeRepository(db string, session *mgo.Session, opts ...Option) (shipping.ReleaseCodeRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &releaseCodeRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("release_code")
+
+	index := mgo.Index{
+		Key:        []string{"trackingid"},
+		Unique:     true,
+		DropDups:   true,
+		Background: true,
+		Sparse:     true,
+	}
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+type reportNonceRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+func (r *reportNonceRepository) Add(nonce string, now, expires time.Time) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("report_nonce")
+
+		// Only an expired nonce is replaced. An unexpired one makes the
+		// upsert insert a second document with the same ID.
+		_, err := c.Upsert(bson.M{"_id": nonce, "expires": bson.M{"$lte": now}}, bson.M{"$set": bson.M{"expires": expires}})
+		if mgo.IsDup(err) {
+			return shipping.ErrNonceUsed
+		}
+
+		return err
+	})
+}
+
+func (r *reportNonceRepository) Remove(nonce string) error {
+	return r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("report_nonce")
+
+		err := c.RemoveId(nonce)
+		if err == mgo.ErrNotFound {
+			return nil
+		}
+
+		return err
+	})
+}
+
+// NewReportNonceRepository returns a new instance of a MongoDB report nonce
+// repository. Expired nonces are removed by MongoDB.
+func NewReportNonceRepository(db string, session *mgo.Session, opts ...Option) (shipping.ReportNonceRepository, error) {
+	cfg := newConfig(opts)
+
+	r := &reportNonceRepository{
+		db:      db,
+		session: cfg.session(session),
+		retry:   cfg.retry,
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("report_nonce")
+
+	// The TTL monitor removes nonces shortly after they expire, which
+	// leaves Add to replace the ones it has yet to get to.
+	index := mgo.Index{
+		Key:         []string{"expires"},
+		Background:  true,
+		ExpireAfter: time.Second,
+	}
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+type handlingEventRepository struct {
+	db      string
+	session *mgo.Session
+	retry   RetryPolicy
+}
+
+// handlingEventDocument assigns the document ID up front, so that an insert
+// can be retried without risking duplicate handling events.
+type handlingEventDocument struct {
+	ID                     bson.ObjectId `bson:"_id"`
+	shipping.HandlingEvent `bson:",inline"`
+}
+
+func (r *handlingEventRepository) Store(e shipping.HandlingEvent) {
+	doc := handlingEventDocument{ID: bson.NewObjectId(), HandlingEvent: e}
+
+	_ = r.retry.do(r.session, func(sess *mgo.Session) error {
+		c := sess.DB(r.db).C("handling_event")
+
+		// A duplicate means that a previous attempt did succeed.
+		if err := c.Insert(doc); err != nil && !mgo.IsDup(err) {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (r *handlingEventRepository) QueryHandlingHistory(id shipping.TrackingID) shipping.HandlingHistory {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("handling_event")
+
+	var result []shipping.HandlingEvent
+	_ = c.Find(bson.M{"trackingid": id}).All(&result)
+
+	return shipping.HandlingHistory{HandlingEvents: result}
+}
+
+// QueryHandlingHistoryPage uses the ID of the last event in a page as cursor.
+// Document IDs are assigned on registration, so ordering by ID keeps the
+// events in the order they were registered.
+func (r *handlingEventRepository) QueryHandlingHistoryPage(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+	filter := bson.M{"trackingid": id}
+
+	if q.Cursor != "" {
+		if !bson.IsObjectIdHex(q.Cursor) {
+			return shipping.HandlingHistoryPage{}, shipping.ErrInvalidCursor
+		}
+		filter["_id"] = bson.M{"$gt": bson.ObjectIdHex(q.Cursor)}
+	}
+
+	completed := bson.M{}
+	if !q.From.IsZero() {
+		completed["$gte"] = q.From
+	}
+	if !q.To.IsZero() {
+		completed["$lt"] = q.To
+	}
+	if len(completed) > 0 {
+		filter["completed"] = completed
+	}
+	if len(q.Types) > 0 {
+		filter["activity.type"] = bson.M{"$in": q.Types}
+	}
+
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("handling_event")
+
+	query := c.Find(filter).Sort("_id")
+	if q.Limit > 0 {
+		// Fetch one more event to tell whether there is a next page.
+		query = query.Limit(q.Limit + 1)
+	}
+
+	var docs []handlingEventDocument
+	if err := query.All(&docs); err != nil {
+		return shipping.HandlingHistoryPage{}, err
+	}
+
+	var page shipping.HandlingHistoryPage
+	if q.Limit > 0 && len(docs) > q.Limit {
+		docs = docs[:q.Limit]
+		page.NextCursor = docs[len(docs)-1].ID.Hex()
+	}
+	for _, d := range docs {
+		page.HandlingEvents = append(page.HandlingEvents, d.HandlingEvent)
+	}
+
+	return page, nil
+}
+
+func (r *handlingEventRepository) QueryHandlingHistories(ids []shipping.TrackingID) map[shipping.TrackingID]shipping.HandlingHistory {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("handling_event")
+
+	var result []shipping.HandlingEvent
+	_ = c.Find(bson.M{"trackingid": bson.M{"$in": ids}}).All(&result)
+
+	h := make(map[shipping.TrackingID]shipping.HandlingHistory, len(ids))
+	for _, id := range ids {
+		h[id] = shipping.HandlingHistory{}
+	}
+	for _, e := range result {
+		hh := h[e.TrackingID]
+		hh.HandlingEvents = append(hh.HandlingEvents, e)
+		h[e.TrackingID] = hh
+	}
+
+	return h
+}
+
+func (r *handlingEventRepository) ForEach(fn func(shipping.HandlingEvent) error) error {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(r.db).C("handling_event")
+
+	iter := c.Find(bson.M{}).Iter()
+
+	var result shipping.HandlingEvent
+	for iter.Next(&result) {
+		if err := fn(result); err != nil {
+			iter.Close()
+			return err
+		}
+	}
+
+	return iter.Close()
+}
+
+// NewHandlingEventRepository returns a new instance of a MongoDB handling event repository.
+func NewHandlingEventRepository(db string, session *mgo.Session, opts ...Option) shipping.HandlingEventRepository {
+	cfg := newConfig(opts)
+
 	return &handlingEventRepository{
 		db:      db,
-		session: session,
+		session: cfg.session(session),
+		retry:   cfg.retry,
 	}
 }
@@ -0,0 +1,198 @@
+package mongo
+
+import (
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"gopkg.in/mgo.v2"
+)
+
+// DialOptions configures the connection pool and timeouts of a session.
+type DialOptions struct {
+	// PoolLimit is the maximum number of sockets per server. Zero means the
+	// driver default.
+	PoolLimit int
+
+	// ServerSelectionTimeout is how long an operation waits for a usable
+	// server before giving up.
+	ServerSelectionTimeout time.Duration
+
+	// OperationTimeout is how long to wait for a non-responding socket
+	// before it is forcefully closed.
+	OperationTimeout time.Duration
+}
+
+// Dial establishes a new session to the cluster identified by url.
+func Dial(url string, opts DialOptions) (*mgo.Session, error) {
+	info, err := mgo.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.PoolLimit > 0 {
+		info.PoolLimit = opts.PoolLimit
+	}
+	if opts.ServerSelectionTimeout > 0 {
+		info.Timeout = opts.ServerSelectionTimeout
+	}
+
+	session, err := mgo.DialWithInfo(info)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.ServerSelectionTimeout > 0 {
+		session.SetSyncTimeout(opts.ServerSelectionTimeout)
+	}
+	if opts.OperationTimeout > 0 {
+		session.SetSocketTimeout(opts.OperationTimeout)
+	}
+
+	return session, nil
+}
+
+// RetryPolicy describes how writes failing with transient errors are retried.
+type RetryPolicy struct {
+	// Attempts is the total number of attempts, including the first one.
+	Attempts int
+
+	// Backoff is the delay before the first retry. It doubles for every
+	// subsequent retry.
+	Backoff time.Duration
+}
+
+// DefaultRetryPolicy is used by repositories unless configured otherwise.
+var DefaultRetryPolicy = RetryPolicy{Attempts: 3, Backoff: 100 * time.Millisecond}
+
+// Option configures a repository.
+type Option func(*config)
+
+type config struct {
+	retry    RetryPolicy
+	readMode *mgo.Mode
+}
+
+// WithRetryPolicy sets the retry policy for writes.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *config) {
+		c.retry = p
+	}
+}
+
+// WithReadPreference directs the reads of a repository to the replica set
+// members given by mode, e.g. mgo.SecondaryPreferred to offload query traffic
+// from the primary. Writes always go to the primary.
+func WithReadPreference(mode mgo.Mode) Option {
+	return func(c *config) {
+		c.readMode = &mode
+	}
+}
+
+// session returns the session to be used by a repository.
+func (c config) session(s *mgo.Session) *mgo.Session {
+	if c.readMode == nil {
+		return s
+	}
+	s = s.Clone()
+	s.SetMode(*c.readMode, true)
+	return s
+}
+
+var readPreferences = map[string]mgo.Mode{
+	"primary":            mgo.Primary,
+	"primaryPreferred":   mgo.PrimaryPreferred,
+	"secondary":          mgo.Secondary,
+	"secondaryPreferred": mgo.SecondaryPreferred,
+	"nearest":            mgo.Nearest,
+}
+
+// ErrUnknownReadPreference is returned when parsing an unsupported read
+// preference.
+var ErrUnknownReadPreference = errors.New("unknown read preference")
+
+// ParseReadPreference parses a read preference name, such as
+// "secondaryPreferred", as used in MongoDB connection strings.
+func ParseReadPreference(s string) (mgo.Mode, error) {
+	if m, ok := readPreferences[s]; ok {
+		return m, nil
+	}
+	return 0, ErrUnknownReadPreference
+}
+
+func newConfig(opts []Option) config {
+	c := config{retry: DefaultRetryPolicy}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// do runs fn until it succeeds, fails with a permanent error, or the policy
+// runs out of attempts. A fresh copy of the session is used for every attempt
+// so that a broken socket isn't reused.
+func (p RetryPolicy) do(session *mgo.Session, fn func(*mgo.Session) error) error {
+	return p.retry(func() error {
+		sess := session.Copy()
+		defer sess.Close()
+		return fn(sess)
+	})
+}
+
+// sleep waits between attempts. It is replaced in tests.
+var sleep = time.Sleep
+
+func (p RetryPolicy) retry(fn func() error) error {
+	backoff := p.Backoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+
+		if err == nil || !isTransient(err) || attempt >= p.Attempts {
+			return err
+		}
+
+		sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// transientCodes are server error codes after which an operation may be
+// retried, e.g. during a replica set election.
+var transientCodes = map[int]bool{
+	6:     true, // HostUnreachable
+	7:     true, // HostNotFound
+	89:    true, // NetworkTimeout
+	91:    true, // ShutdownInProgress
+	189:   true, // PrimarySteppedDown
+	9001:  true, // SocketException
+	10107: true, // NotMaster
+	11600: true, // InterruptedAtShutdown
+	11602: true, // InterruptedDueToReplStateChange
+	13435: true, // NotMasterNoSlaveOk
+	13436: true, // NotMasterOrSecondary
+}
+
+func isTransient(err error) bool {
+	if err == io.EOF {
+		return true
+	}
+
+	switch e := err.(type) {
+	case net.Error:
+		return true
+	case *mgo.LastError:
+		return transientCodes[e.Code]
+	case *mgo.QueryError:
+		return transientCodes[e.Code]
+	}
+
+	switch err.Error() {
+	case "no reachable servers", "Closed explicitly":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,122 @@
+package mongo
+
+import (
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2"
+)
+
+func TestIsTransient(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"eof", io.EOF, true},
+		{"network error", &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}, true},
+		{"dns error", &net.DNSError{Err: "no such host", Name: "mongo"}, true},
+		{"no reachable servers", errors.New("no reachable servers"), true},
+		{"closed explicitly", errors.New("Closed explicitly"), true},
+		{"not master", &mgo.LastError{Code: 10107}, true},
+		{"primary stepped down", &mgo.QueryError{Code: 189}, true},
+		{"interrupted by election", &mgo.LastError{Code: 11602}, true},
+		{"not found", mgo.ErrNotFound, false},
+		{"duplicate key", &mgo.LastError{Code: 11000, Err: "E11000 duplicate key error"}, false},
+		{"duplicate key query", &mgo.QueryError{Code: 11000}, false},
+		{"other", errors.New("bad value"), false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransient(tt.err); got != tt.want {
+				t.Errorf("isTransient(%v) = %v; want = %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	defer func(fn func(time.Duration)) { sleep = fn }(sleep)
+
+	transient := &mgo.LastError{Code: 10107}
+
+	for _, tt := range []struct {
+		name     string
+		policy   RetryPolicy
+		errs     []error
+		want     error
+		attempts int
+		backoffs []time.Duration
+	}{
+		{
+			name:     "success",
+			policy:   RetryPolicy{Attempts: 3, Backoff: time.Second},
+			errs:     []error{nil},
+			attempts: 1,
+		},
+		{
+			name:     "recovers",
+			policy:   RetryPolicy{Attempts: 3, Backoff: time.Second},
+			errs:     []error{transient, io.EOF, nil},
+			attempts: 3,
+			backoffs: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:     "runs out of attempts",
+			policy:   RetryPolicy{Attempts: 3, Backoff: time.Second},
+			errs:     []error{transient, transient, transient, nil},
+			want:     transient,
+			attempts: 3,
+			backoffs: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:     "permanent",
+			policy:   RetryPolicy{Attempts: 3, Backoff: time.Second},
+			errs:     []error{transient, mgo.ErrNotFound, nil},
+			want:     mgo.ErrNotFound,
+			attempts: 2,
+			backoffs: []time.Duration{time.Second},
+		},
+		{
+			name:     "no retries",
+			policy:   RetryPolicy{Attempts: 1, Backoff: time.Second},
+			errs:     []error{transient, nil},
+			want:     transient,
+			attempts: 1,
+		},
+		{
+			name:     "zero attempts",
+			policy:   RetryPolicy{},
+			errs:     []error{transient, nil},
+			want:     transient,
+			attempts: 1,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var backoffs []time.Duration
+			sleep = func(d time.Duration) {
+				backoffs = append(backoffs, d)
+			}
+
+			var attempts int
+			err := tt.policy.retry(func() error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+
+			if err != tt.want {
+				t.Errorf("err = %v; want = %v", err, tt.want)
+			}
+			if attempts != tt.attempts {
+				t.Errorf("attempts = %d; want = %d", attempts, tt.attempts)
+			}
+			if !reflect.DeepEqual(backoffs, tt.backoffs) {
+				t.Errorf("backoffs = %v; want = %v", backoffs, tt.backoffs)
+			}
+		})
+	}
+}
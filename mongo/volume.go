@@ -0,0 +1,75 @@
+package mongo
+
+import (
+	"strings"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type volumeReporter struct {
+	db      string
+	session *mgo.Session
+}
+
+// Volumes reports the documents and storage size of each collection of the
+// database. Documents are timestamped by their object IDs, which MongoDB
+// assigns on insert unless the repository assigns its own.
+func (r *volumeReporter) Volumes() ([]shipping.RepositoryVolume, error) {
+	sess := r.session.Copy()
+	defer sess.Close()
+
+	db := sess.DB(r.db)
+
+	names, err := db.CollectionNames()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []shipping.RepositoryVolume
+	for _, name := range names {
+		if strings.HasPrefix(name, "system.") {
+			continue
+		}
+
+		var stats struct {
+			Count       int   `bson:"count"`
+			StorageSize int64 `bson:"storageSize"`
+		}
+		if err := db.Run(bson.D{{Name: "collStats", Value: name}}, &stats); err != nil {
+			return nil, err
+		}
+
+		v := shipping.RepositoryVolume{
+			Repository: name,
+			Records:    stats.Count,
+			Bytes:      stats.StorageSize,
+		}
+
+		var oldest struct {
+			ID interface{} `bson:"_id"`
+		}
+		err := db.C(name).Find(nil).Sort("_id").Select(bson.M{"_id": 1}).One(&oldest)
+		if err != nil && err != mgo.ErrNotFound {
+			return nil, err
+		}
+		if id, ok := oldest.ID.(bson.ObjectId); ok {
+			v.Oldest = id.Time()
+		}
+
+		result = append(result, v)
+	}
+
+	return result, nil
+}
+
+// NewVolumeReporter returns a reporter of the volumes of the collections of
+// a database.
+func NewVolumeReporter(db string, session *mgo.Session) shipping.VolumeReporter {
+	return &volumeReporter{
+		db:      db,
+		session: session,
+	}
+}
@@ -0,0 +1,35 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingSender struct {
+	logger log.Logger
+}
+
+// NewLoggingSender returns a Sender that delivers notifications through the
+// log, for lack of an email, SMS or webhook gateway.
+func NewLoggingSender(logger log.Logger) Sender {
+	return &loggingSender{logger}
+}
+
+func (s *loggingSender) Send(ctx context.Context, c *shipping.Customer, ch shipping.NotificationChannel, ns []Notification) error {
+	for _, n := range ns {
+		s.logger.Log(
+			"msg", n.Message,
+			"request_id", correlation.FromContext(ctx),
+			"customer", c.ID,
+			"channel", ch,
+			"type", n.Type,
+			"tracking_id", n.TrackingID,
+			"attachments", len(n.Attachments),
+		)
+	}
+	return nil
+}
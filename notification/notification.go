@@ -0,0 +1,298 @@
+// Package notification provides means of notifying customers about their
+// cargos, through the channels they have subscribed to.
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/booking"
+	"github.com/marcusolsson/goddd/handling"
+	"github.com/marcusolsson/goddd/inspection"
+	"github.com/marcusolsson/goddd/sla"
+	"github.com/marcusolsson/goddd/telemetry"
+)
+
+// Notification tells a customer about something that happened to a cargo.
+type Notification struct {
+	Customer   shipping.CustomerID
+	Type       shipping.NotificationType
+	TrackingID shipping.TrackingID
+	Message    string
+	Created    time.Time
+
+	// Attachments are documents the message summarizes, such as a report.
+	Attachments []Attachment
+}
+
+// Attachment is a document sent along with a notification, for channels
+// able to carry it.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Sender delivers notifications to a customer through a channel. Several
+// notifications are sent together as a digest.
+type Sender interface {
+	Send(ctx context.Context, c *shipping.Customer, ch shipping.NotificationChannel, ns []Notification) error
+}
+
+// throttleKey identifies the notifications throttled together.
+type throttleKey struct {
+	customer shipping.CustomerID
+	typ      shipping.NotificationType
+	cargo    shipping.TrackingID
+}
+
+// Notifier passes notifications on to the sender according to the
+// preferences of each customer, dropping throttled notifications and
+// collecting the rest into digests for customers who asked for them.
+//
+// Throttling and pending digests are kept in memory, and do not survive a
+// restart.
+type Notifier struct {
+	customers shipping.CustomerRepository
+	sender    Sender
+
+	mtx     sync.Mutex
+	last    map[throttleKey]time.Time
+	pending map[shipping.CustomerID][]Notification
+
+	now func() time.Time
+}
+
+// NewNotifier returns a new instance of a Notifier.
+func NewNotifier(customers shipping.CustomerRepository, sender Sender) *Notifier {
+	return &Notifier{
+		customers: customers,
+		sender:    sender,
+		last:      make(map[throttleKey]time.Time),
+		pending:   make(map[shipping.CustomerID][]Notification),
+		now:       time.Now,
+	}
+}
+
+// Notify sends a notification right away, or adds it to the next digest of
+// the customer. Customers not subscribed to the type are not notified.
+func (n *Notifier) Notify(ctx context.Context, nt Notification) error {
+	c, err := n.customers.Find(nt.Customer)
+	if err != nil {
+		return err
+	}
+
+	if len(c.Notifications[nt.Type]) == 0 {
+		return nil
+	}
+
+	now := n.now()
+	if nt.Created.IsZero() {
+		nt.Created = now
+	}
+
+	n.mtx.Lock()
+	key := throttleKey{nt.Customer, nt.Type, nt.TrackingID}
+	if c.Throttling.IsThrottled(nt.Type, n.last[key], now) {
+		n.mtx.Unlock()
+		return nil
+	}
+	n.last[key] = now
+
+	if c.Digest != shipping.NoDigest {
+		n.pending[c.ID] = append(n.pending[c.ID], nt)
+		n.mtx.Unlock()
+		return nil
+	}
+	n.mtx.Unlock()
+
+	return n.send(ctx, c, []Notification{nt})
+}
+
+// Flush sends the digests that are due. It is meant to be called
+// periodically, more often than once an hour.
+func (n *Notifier) Flush(ctx context.Context) error {
+	now := n.now()
+
+	type digest struct {
+		customer      *shipping.Customer
+		notifications []Notification
+	}
+
+	var due []digest
+
+	n.mtx.Lock()
+	for id, ns := range n.pending {
+		c, err := n.customers.Find(id)
+		if err == shipping.ErrUnknownCustomer {
+			delete(n.pending, id)
+			continue
+		}
+		if err != nil || !c.Digest.IsDue(ns[0].Created, now) {
+			continue
+		}
+		due = append(due, digest{c, ns})
+		delete(n.pending, id)
+	}
+	n.mtx.Unlock()
+
+	var firstErr error
+	for _, d := range due {
+		if err := n.send(ctx, d.customer, d.notifications); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// send sends the notifications through every channel subscribed to any of
+// them, each channel receiving only the types it is subscribed to.
+func (n *Notifier) send(ctx context.Context, c *shipping.Customer, ns []Notification) error {
+	var channels []shipping.NotificationChannel
+	byChannel := make(map[shipping.NotificationChannel][]Notification)
+	for _, nt := range ns {
+		for _, ch := range c.Notifications[nt.Type] {
+			if _, ok := byChannel[ch]; !ok {
+				channels = append(channels, ch)
+			}
+			byChannel[ch] = append(byChannel[ch], nt)
+		}
+	}
+
+	for _, ch := range channels {
+		if err := n.sender.Send(ctx, c, ch, byChannel[ch]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyCargo notifies the customer that booked the cargo, if any.
+func (n *Notifier) notifyCargo(ctx context.Context, c *shipping.Cargo, t shipping.NotificationType, msg string) {
+	if c.Customer == "" {
+		return
+	}
+	n.Notify(ctx, Notification{
+		Customer:   c.Customer,
+		Type:       t,
+		TrackingID: c.TrackingID,
+		Message:    msg,
+	})
+}
+
+type bookingEventHandler struct {
+	n *Notifier
+}
+
+func (h *bookingEventHandler) CargoWasRouted(ctx context.Context, c *shipping.Cargo) {}
+
+func (h *bookingEventHandler) CargoWasRolled(ctx context.Context, c *shipping.Cargo, from shipping.VoyageNumber) {
+	msg := fmt.Sprintf("Cargo %s was rolled from voyage %s to a later sailing.", c.TrackingID, from)
+	if eta := c.Delivery.ProjectedArrival(); !eta.IsZero() {
+		msg = fmt.Sprintf("Cargo %s was rolled from voyage %s to a later sailing, and is now expected at %s on %s.",
+			c.TrackingID, from, c.RouteSpecification.Destination, eta.UTC().Format("2006-01-02 15:04 MST"))
+	}
+	h.n.notifyCargo(ctx, c, shipping.CargoRolledNotification, msg)
+}
+
+// CargoWasBooked does nothing; customers know the cargos they book.
+func (h *bookingEventHandler) CargoWasBooked(ctx context.Context, c *shipping.Cargo) {
+}
+
+// CargoWasTransferred notifies both the previous and the new owner of the
+// cargo.
+func (h *bookingEventHandler) CargoWasTransferred(ctx context.Context, c *shipping.Cargo, from shipping.CustomerID) {
+	h.n.Notify(ctx, Notification{
+		Customer:   from,
+		Type:       shipping.CargoTransferredNotification,
+		TrackingID: c.TrackingID,
+		Message:    fmt.Sprintf("Cargo %s was transferred to %s, and can no longer be tracked by you.", c.TrackingID, c.Customer),
+	})
+	h.n.notifyCargo(ctx, c, shipping.CargoTransferredNotification,
+		fmt.Sprintf("Cargo %s was transferred to you from %s.", c.TrackingID, from))
+}
+
+// NewBookingEventHandler returns a handler that notifies customers of cargos
+// rolled to a later sailing, with their new arrival, and of cargos
+// transferred to or from them.
+func NewBookingEventHandler(n *Notifier) booking.EventHandler {
+	return &bookingEventHandler{n: n}
+}
+
+type inspectionEventHandler struct {
+	n *Notifier
+}
+
+func (h *inspectionEventHandler) CargoWasMisdirected(ctx context.Context, c *shipping.Cargo) {
+	h.n.notifyCargo(ctx, c, shipping.CargoMisdirectedNotification,
+		fmt.Sprintf("Cargo %s was misdirected to %s.", c.TrackingID, c.Delivery.LastKnownLocation))
+}
+
+func (h *inspectionEventHandler) CargoHasArrived(ctx context.Context, c *shipping.Cargo) {
+	h.n.notifyCargo(ctx, c, shipping.CargoArrivedNotification,
+		fmt.Sprintf("Cargo %s has arrived at %s.", c.TrackingID, c.Delivery.LastKnownLocation))
+}
+
+func (h *inspectionEventHandler) CargoDeadlineAtRisk(ctx context.Context, c *shipping.Cargo, slip time.Duration) {
+	h.n.notifyCargo(ctx, c, shipping.DeadlineAtRiskNotification,
+		fmt.Sprintf("Cargo %s is projected to arrive %s after its deadline.", c.TrackingID, slip))
+}
+
+// NewInspectionEventHandler returns a handler that notifies customers of
+// misdirected and arrived cargos, and of deadlines at risk.
+func NewInspectionEventHandler(n *Notifier) inspection.EventHandler {
+	return &inspectionEventHandler{n: n}
+}
+
+type slaEventHandler struct {
+	n *Notifier
+}
+
+func (h *slaEventHandler) CargoBreachedSLA(ctx context.Context, c *shipping.Cargo, breaches []shipping.SLABreach) {
+	h.n.notifyCargo(ctx, c, shipping.SLABreachNotification,
+		fmt.Sprintf("Cargo %s breached its %s service level.", c.TrackingID, c.ServiceLevel))
+}
+
+// NewSLAEventHandler returns a handler that notifies customers of cargos
+// breaching their service level.
+func NewSLAEventHandler(n *Notifier) sla.EventHandler {
+	return &slaEventHandler{n: n}
+}
+
+type handlingEventHandler struct {
+	n      *Notifier
+	cargos shipping.CargoRepository
+}
+
+func (h *handlingEventHandler) CargoWasHandled(ctx context.Context, e shipping.HandlingEvent) {
+	c, err := h.cargos.Find(e.TrackingID)
+	if err != nil {
+		return
+	}
+	h.n.notifyCargo(ctx, c, shipping.CargoHandledNotification,
+		fmt.Sprintf("Cargo %s: %s in %s.", c.TrackingID, e.Activity.Type, e.Activity.Location))
+}
+
+// NewHandlingEventHandler returns a handler that notifies customers every
+// time their cargos are handled.
+func NewHandlingEventHandler(n *Notifier, cargos shipping.CargoRepository) handling.EventHandler {
+	return &handlingEventHandler{n: n, cargos: cargos}
+}
+
+type telemetryEventHandler struct {
+	n *Notifier
+}
+
+func (h *telemetryEventHandler) CargoSensorOutOfRange(ctx context.Context, c *shipping.Cargo, r shipping.SensorReading, limit shipping.SensorRange) {
+	h.n.notifyCargo(ctx, c, shipping.SensorAlertNotification,
+		fmt.Sprintf("Cargo %s: %s read %g, outside of %g to %g.", c.TrackingID, r.Sensor, r.Value, limit.Min, limit.Max))
+}
+
+// NewTelemetryEventHandler returns a handler that notifies customers of
+// sensor readings outside of the limits of their cargos.
+func NewTelemetryEventHandler(n *Notifier) telemetry.EventHandler {
+	return &telemetryEventHandler{n: n}
+}
@@ -0,0 +1,187 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+type sent struct {
+	channel       shipping.NotificationChannel
+	notifications []Notification
+}
+
+type recordingSender struct {
+	sent []sent
+}
+
+func (s *recordingSender) Send(ctx context.Context, c *shipping.Customer, ch shipping.NotificationChannel, ns []Notification) error {
+	s.sent = append(s.sent, sent{ch, ns})
+	return nil
+}
+
+func newCustomerRepository(c *shipping.Customer) *mock.CustomerRepository {
+	var customers mock.CustomerRepository
+	customers.FindFn = func(id shipping.CustomerID) (*shipping.Customer, error) {
+		if id != c.ID {
+			return nil, shipping.ErrUnknownCustomer
+		}
+		return c, nil
+	}
+	return &customers
+}
+
+func TestNotifyImmediately(t *testing.T) {
+	c := &shipping.Customer{
+		ID: "ACME",
+		Notifications: shipping.NotificationPreferences{
+			shipping.CargoArrivedNotification: {shipping.EmailChannel, shipping.SMSChannel},
+		},
+	}
+
+	var sender recordingSender
+
+	n := NewNotifier(newCustomerRepository(c), &sender)
+
+	if err := n.Notify(context.Background(), Notification{Customer: "ACME", Type: shipping.CargoArrivedNotification, TrackingID: "ABC"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(context.Background(), Notification{Customer: "ACME", Type: shipping.CargoHandledNotification, TrackingID: "ABC"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sender.sent) != 2 {
+		t.Fatalf("len(sent) = %d; want = %d", len(sender.sent), 2)
+	}
+	for _, s := range sender.sent {
+		if len(s.notifications) != 1 || s.notifications[0].Type != shipping.CargoArrivedNotification {
+			t.Errorf("sent %v through %s; want a single arrived notification", s.notifications, s.channel)
+		}
+	}
+}
+
+func TestNotifyThrottled(t *testing.T) {
+	c := &shipping.Customer{
+		ID: "ACME",
+		Notifications: shipping.NotificationPreferences{
+			shipping.CargoHandledNotification: {shipping.EmailChannel},
+		},
+		Throttling: shipping.NotificationThrottling{
+			shipping.CargoHandledNotification: 6 * time.Hour,
+		},
+	}
+
+	var sender recordingSender
+
+	now := time.Date(2016, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	n := NewNotifier(newCustomerRepository(c), &sender)
+	n.now = func() time.Time { return now }
+
+	for _, step := range []struct {
+		after time.Duration
+		cargo shipping.TrackingID
+		want  int
+	}{
+		{0, "ABC", 1},
+		{time.Hour, "ABC", 1},
+		{time.Hour, "DEF", 2},
+		{6 * time.Hour, "ABC", 3},
+	} {
+		now = now.Add(step.after)
+
+		if err := n.Notify(context.Background(), Notification{Customer: "ACME", Type: shipping.CargoHandledNotification, TrackingID: step.cargo}); err != nil {
+			t.Fatal(err)
+		}
+		if len(sender.sent) != step.want {
+			t.Errorf("len(sent) = %d after %s notified at %v; want = %d", len(sender.sent), step.cargo, now, step.want)
+		}
+	}
+}
+
+func TestNotifyDigest(t *testing.T) {
+	c := &shipping.Customer{
+		ID: "ACME",
+		Notifications: shipping.NotificationPreferences{
+			shipping.CargoHandledNotification: {shipping.EmailChannel},
+			shipping.CargoArrivedNotification: {shipping.EmailChannel, shipping.SMSChannel},
+		},
+		Digest: shipping.HourlyDigest,
+	}
+
+	var sender recordingSender
+
+	now := time.Date(2016, time.March, 1, 10, 20, 0, 0, time.UTC)
+
+	n := NewNotifier(newCustomerRepository(c), &sender)
+	n.now = func() time.Time { return now }
+
+	for _, nt := range []Notification{
+		{Customer: "ACME", Type: shipping.CargoHandledNotification, TrackingID: "ABC"},
+		{Customer: "ACME", Type: shipping.CargoArrivedNotification, TrackingID: "ABC"},
+	} {
+		if err := n.Notify(context.Background(), nt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := n.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("len(sent) = %d before the hour has passed; want = %d", len(sender.sent), 0)
+	}
+
+	now = now.Add(time.Hour)
+
+	if err := n.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[shipping.NotificationChannel]int)
+	for _, s := range sender.sent {
+		got[s.channel] += len(s.notifications)
+	}
+	if got[shipping.EmailChannel] != 2 || got[shipping.SMSChannel] != 1 || len(sender.sent) != 2 {
+		t.Errorf("sent = %v; want one email digest of 2 and one SMS digest of 1", got)
+	}
+
+	if err := n.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.sent) != 2 {
+		t.Errorf("len(sent) = %d after flushing again; want = %d", len(sender.sent), 2)
+	}
+}
+
+func TestCargoWasTransferred(t *testing.T) {
+	prefs := shipping.NotificationPreferences{
+		shipping.CargoTransferredNotification: {shipping.EmailChannel},
+	}
+
+	var customers mock.CustomerRepository
+	customers.FindFn = func(id shipping.CustomerID) (*shipping.Customer, error) {
+		return &shipping.Customer{ID: id, Notifications: prefs}, nil
+	}
+
+	var sender recordingSender
+
+	h := NewBookingEventHandler(NewNotifier(&customers, &sender))
+
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{})
+	c.Customer = "INITECH"
+
+	h.CargoWasTransferred(context.Background(), c, "ACME")
+
+	if len(sender.sent) != 2 {
+		t.Fatalf("len(sent) = %d; want = %d", len(sender.sent), 2)
+	}
+
+	got := []shipping.CustomerID{sender.sent[0].notifications[0].Customer, sender.sent[1].notifications[0].Customer}
+	if got[0] != "ACME" || got[1] != "INITECH" {
+		t.Errorf("notified %v; want both the previous and the new owner", got)
+	}
+}
@@ -0,0 +1,154 @@
+// Package opaque converts the sequential identifiers kept in storage to the
+// identifiers shown outside the service. Sequence numbers tell anyone who
+// sees two of them how much happened in between; a keyed codec shows them
+// as strings that cannot be ordered or subtracted without the key.
+package opaque
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// ErrMalformed is returned when an identifier could not have been encoded
+// by the codec decoding it.
+var ErrMalformed = errors.New("malformed identifier")
+
+// Codec converts numeric identifiers to their external form and back.
+type Codec interface {
+	Encode(n uint64) string
+	Decode(s string) (uint64, error)
+}
+
+// Decimal is the codec that shows identifiers as they are stored.
+var Decimal Codec = decimal{}
+
+type decimal struct{}
+
+func (decimal) Encode(n uint64) string {
+	return strconv.FormatUint(n, 10)
+}
+
+func (decimal) Decode(s string) (uint64, error) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, ErrMalformed
+	}
+	return n, nil
+}
+
+// alphabet leaves out the characters that are easily mistaken for one
+// another, such as 0 and O, or 1, l and I.
+const alphabet = "23456789abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+
+const (
+	// width is the number of characters needed for any uint64, so that
+	// every identifier has the same length.
+	width = 11
+
+	// rounds of the Feistel network permuting the identifiers.
+	rounds = 4
+)
+
+type keyed struct {
+	key      []byte
+	alphabet []byte
+	index    map[byte]uint64
+}
+
+// NewKeyed returns a codec that permutes identifiers under a key and spells
+// them out in an alphabet shuffled by the same key, in the style of hashids.
+// Each deployment should use its own key; identifiers encoded under one key
+// do not decode under another.
+func NewKeyed(key []byte) Codec {
+	c := &keyed{
+		key:      key,
+		alphabet: []byte(alphabet),
+		index:    make(map[byte]uint64),
+	}
+
+	// Shuffle the alphabet by sorting on the keyed hash of each character.
+	sums := make(map[byte][]byte)
+	for _, b := range c.alphabet {
+		sums[b] = c.sum([]byte{'a', b})
+	}
+	sort.Slice(c.alphabet, func(i, j int) bool {
+		return string(sums[c.alphabet[i]]) < string(sums[c.alphabet[j]])
+	})
+
+	for i, b := range c.alphabet {
+		c.index[b] = uint64(i)
+	}
+
+	return c
+}
+
+func (c *keyed) Encode(n uint64) string {
+	x := c.permute(n)
+
+	base := uint64(len(c.alphabet))
+	b := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		b[i] = c.alphabet[x%base]
+		x /= base
+	}
+	return string(b)
+}
+
+func (c *keyed) Decode(s string) (uint64, error) {
+	if len(s) != width {
+		return 0, ErrMalformed
+	}
+
+	base := uint64(len(c.alphabet))
+
+	var x uint64
+	for i := 0; i < len(s); i++ {
+		d, ok := c.index[s[i]]
+		if !ok {
+			return 0, ErrMalformed
+		}
+		if x > (math.MaxUint64-d)/base {
+			return 0, ErrMalformed
+		}
+		x = x*base + d
+	}
+
+	return c.unpermute(x), nil
+}
+
+// permute runs n through a Feistel network, which is a bijection on uint64
+// whatever the round function.
+func (c *keyed) permute(n uint64) uint64 {
+	l, r := uint32(n>>32), uint32(n)
+	for i := 0; i < rounds; i++ {
+		l, r = r, l^c.round(i, r)
+	}
+	return uint64(l)<<32 | uint64(r)
+}
+
+func (c *keyed) unpermute(n uint64) uint64 {
+	l, r := uint32(n>>32), uint32(n)
+	for i := rounds - 1; i >= 0; i-- {
+		l, r = r^c.round(i, l), l
+	}
+	return uint64(l)<<32 | uint64(r)
+}
+
+func (c *keyed) round(i int, x uint32) uint32 {
+	msg := make([]byte, 6)
+	msg[0] = 'r'
+	msg[1] = byte(i)
+	binary.BigEndian.PutUint32(msg[2:], x)
+	return binary.BigEndian.Uint32(c.sum(msg))
+}
+
+func (c *keyed) sum(msg []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
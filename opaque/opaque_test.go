@@ -0,0 +1,70 @@
+package opaque
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKeyed_RoundTrip(t *testing.T) {
+	c := NewKeyed([]byte("deployment key"))
+
+	seen := make(map[string]bool)
+	for _, n := range []uint64{0, 1, 2, 3, 42, 1000, 1001, math.MaxUint32, math.MaxUint64} {
+		s := c.Encode(n)
+		if len(s) != width {
+			t.Errorf("Encode(%d) = %q; want %d characters", n, s, width)
+		}
+		if seen[s] {
+			t.Errorf("Encode(%d) = %q, which was already used", n, s)
+		}
+		seen[s] = true
+
+		got, err := c.Decode(s)
+		if err != nil {
+			t.Fatalf("Decode(%q): %v", s, err)
+		}
+		if got != n {
+			t.Errorf("Decode(Encode(%d)) = %d", n, got)
+		}
+	}
+}
+
+func TestKeyed_KeysDiffer(t *testing.T) {
+	a := NewKeyed([]byte("one"))
+	b := NewKeyed([]byte("two"))
+
+	if a.Encode(1) == b.Encode(1) {
+		t.Errorf("both keys encode 1 as %q", a.Encode(1))
+	}
+	if n, err := b.Decode(a.Encode(1)); err == nil && n == 1 {
+		t.Error("identifier encoded under one key decoded under another")
+	}
+}
+
+func TestKeyed_Malformed(t *testing.T) {
+	c := NewKeyed([]byte("deployment key"))
+
+	for _, s := range []string{"", "42", "0000000000O", "zzzzzzzzzzzz"} {
+		if _, err := c.Decode(s); err != ErrMalformed {
+			t.Errorf("Decode(%q) = %v; want %v", s, err, ErrMalformed)
+		}
+	}
+
+	// The largest string in the alphabet exceeds a uint64.
+	largest := make([]byte, width)
+	for i := range largest {
+		largest[i] = c.(*keyed).alphabet[len(alphabet)-1]
+	}
+	if _, err := c.Decode(string(largest)); err != ErrMalformed {
+		t.Errorf("Decode(%q) = %v; want %v", largest, err, ErrMalformed)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	if s := Decimal.Encode(42); s != "42" {
+		t.Errorf("Encode(42) = %q", s)
+	}
+	if _, err := Decimal.Decode("x"); err != ErrMalformed {
+		t.Errorf("err = %v; want %v", err, ErrMalformed)
+	}
+}
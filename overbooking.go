@@ -0,0 +1,82 @@
+package shipping
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// OverbookingPolicy holds how many percent beyond their physical capacity
+// voyages may be booked, since carriers expect some bookings to be cancelled
+// or not to show up. The percentage of a voyage takes precedence over that
+// of its service string, which takes precedence over the default.
+type OverbookingPolicy struct {
+	Default  float64
+	Services map[ServiceCode]float64
+	Voyages  map[VoyageNumber]float64
+}
+
+// Percent returns the overbooking percentage of a voyage, operated by one of
+// the service strings.
+func (p OverbookingPolicy) Percent(n VoyageNumber, services ServiceStrings) float64 {
+	if pct, ok := p.Voyages[n]; ok {
+		return pct
+	}
+	if s, ok := services.Find(n); ok {
+		if pct, ok := p.Services[s.Code]; ok {
+			return pct
+		}
+	}
+	return p.Default
+}
+
+// Limit returns the number of units that may be booked on a voyage with a
+// physical capacity of units.
+func (p OverbookingPolicy) Limit(units int, n VoyageNumber, services ServiceStrings) int {
+	return units + int(float64(units)*p.Percent(n, services)/100)
+}
+
+// ErrInvalidOverbookingPolicy is used when an overbooking policy cannot be
+// loaded.
+var ErrInvalidOverbookingPolicy = errors.New("invalid overbooking policy")
+
+// LoadOverbookingPolicy reads an overbooking policy as JSON, with
+// percentages by service string and voyage:
+//
+//	{
+//	    "default": 5,
+//	    "services": {"AE1": 10},
+//	    "voyages": {"V100": 0}
+//	}
+func LoadOverbookingPolicy(r io.Reader) (OverbookingPolicy, error) {
+	var v struct {
+		Default  float64                  `json:"default"`
+		Services map[ServiceCode]float64  `json:"services"`
+		Voyages  map[VoyageNumber]float64 `json:"voyages"`
+	}
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		return OverbookingPolicy{}, err
+	}
+
+	valid := func(pct float64) bool { return pct >= 0 && pct <= 100 }
+
+	if !valid(v.Default) {
+		return OverbookingPolicy{}, ErrInvalidOverbookingPolicy
+	}
+	for _, pct := range v.Services {
+		if !valid(pct) {
+			return OverbookingPolicy{}, ErrInvalidOverbookingPolicy
+		}
+	}
+	for _, pct := range v.Voyages {
+		if !valid(pct) {
+			return OverbookingPolicy{}, ErrInvalidOverbookingPolicy
+		}
+	}
+
+	return OverbookingPolicy{
+		Default:  v.Default,
+		Services: v.Services,
+		Voyages:  v.Voyages,
+	}, nil
+}
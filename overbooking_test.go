@@ -0,0 +1,59 @@
+package shipping
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOverbookingPolicy(t *testing.T) {
+	p := OverbookingPolicy{
+		Default:  5,
+		Services: map[ServiceCode]float64{"AE1": 10},
+		Voyages:  map[VoyageNumber]float64{"V300": 0},
+	}
+
+	services := ServiceStrings{
+		{Code: "AE1", Voyages: []VoyageNumber{"V300", "V301"}},
+	}
+
+	for _, tt := range []struct {
+		voyage VoyageNumber
+		want   int
+	}{
+		{voyage: "V100", want: 105},
+		{voyage: "V300", want: 100},
+		{voyage: "V301", want: 110},
+	} {
+		if got := p.Limit(100, tt.voyage, services); got != tt.want {
+			t.Errorf("Limit(100, %s) = %d; want = %d", tt.voyage, got, tt.want)
+		}
+	}
+
+	if got := (OverbookingPolicy{}).Limit(40, "V100", nil); got != 40 {
+		t.Errorf("Limit(40) = %d; want = %d", got, 40)
+	}
+}
+
+func TestLoadOverbookingPolicy(t *testing.T) {
+	p, err := LoadOverbookingPolicy(strings.NewReader(`{
+		"default": 5,
+		"services": {"AE1": 10},
+		"voyages": {"V300": 0}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Default != 5 || p.Services["AE1"] != 10 || len(p.Voyages) != 1 {
+		t.Errorf("p = %+v", p)
+	}
+
+	for _, in := range []string{
+		`{"default": -5}`,
+		`{"voyages": {"V100": 250}}`,
+		`[]`,
+	} {
+		if _, err := LoadOverbookingPolicy(strings.NewReader(in)); err == nil {
+			t.Errorf("LoadOverbookingPolicy(%q) should fail", in)
+		}
+	}
+}
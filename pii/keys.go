@@ -0,0 +1,69 @@
+package pii
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// StaticKeys is a KeyProvider holding its keys in memory, such as keys read
+// from the environment.
+type StaticKeys struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeys returns a key provider encrypting with the key of the given
+// id. The other keys are only used for decrypting values encrypted before
+// the current key was rotated in.
+func NewStaticKeys(current string, keys map[string][]byte) (*StaticKeys, error) {
+	for _, k := range keys {
+		if len(k) != 32 {
+			return nil, ErrInvalidKey
+		}
+	}
+	if _, ok := keys[current]; !ok {
+		return nil, ErrUnknownKey
+	}
+	return &StaticKeys{current: current, keys: keys}, nil
+}
+
+// ParseStaticKeys parses a comma-separated list of keys on the form
+// id=base64key. The first key is the current key.
+func ParseStaticKeys(s string) (*StaticKeys, error) {
+	var current string
+	keys := make(map[string][]byte)
+
+	for _, kv := range strings.Split(s, ",") {
+		parts := strings.SplitN(strings.TrimSpace(kv), "=", 2)
+		if len(parts) != 2 || parts[0] == "" || strings.Contains(parts[0], ":") {
+			return nil, errors.New("keys must be given as id=base64key")
+		}
+
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if current == "" {
+			current = parts[0]
+		}
+		keys[parts[0]] = key
+	}
+
+	return NewStaticKeys(current, keys)
+}
+
+// CurrentKey returns the key new values are encrypted with.
+func (k *StaticKeys) CurrentKey() (string, []byte, error) {
+	return k.current, k.keys[k.current], nil
+}
+
+// Key returns the key with the given id.
+func (k *StaticKeys) Key(id string) ([]byte, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
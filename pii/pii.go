@@ -0,0 +1,148 @@
+// Package pii provides field-level encryption of personal data at rest.
+// Repositories are wrapped so that personal data is encrypted before it is
+// stored and transparently decrypted when it is read back.
+//
+// Keys are supplied by a KeyProvider, which may be backed by a key
+// management service. Values are encrypted with the current key and carry
+// the id of the key they were encrypted with, so that keys can be rotated:
+// once a new key is made current, values encrypted with earlier keys are
+// still decrypted, and are encrypted with the new key the next time they
+// are stored.
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	// ErrUnknownKey is returned when a value was encrypted with a key that
+	// the key provider does not know of.
+	ErrUnknownKey = errors.New("unknown encryption key")
+
+	// ErrInvalidKey is returned for keys that are not 32 bytes long.
+	ErrInvalidKey = errors.New("encryption keys must be 32 bytes")
+
+	// ErrInvalidCiphertext is returned when an encrypted value has been
+	// corrupted or tampered with.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+)
+
+// KeyProvider provides the keys personal data is encrypted with.
+type KeyProvider interface {
+	// CurrentKey returns the key new values are encrypted with, and its id.
+	CurrentKey() (id string, key []byte, err error)
+
+	// Key returns the key with the given id.
+	Key(id string) ([]byte, error)
+}
+
+// prefix marks encrypted values, so that values stored before encryption
+// was enabled can still be read.
+const prefix = "pii:v1:"
+
+// Cipher encrypts and decrypts values with AES-256-GCM.
+type Cipher struct {
+	keys KeyProvider
+}
+
+// NewCipher returns a cipher using the keys of the provider.
+func NewCipher(keys KeyProvider) *Cipher {
+	return &Cipher{keys: keys}
+}
+
+// Encrypt encrypts a value with the current key. Empty values are left
+// empty.
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	id, key, err := c.keys.CurrentKey()
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	// The key id is authenticated, so that a value cannot be passed off as
+	// encrypted with another key.
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(id))
+
+	return prefix + id + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value encrypted by Encrypt. Values that are not
+// encrypted are returned as is.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	id, data, ok := split(value)
+	if !ok {
+		return value, nil
+	}
+
+	key, err := c.keys.Key(id)
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrInvalidCiphertext
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+
+	return string(plaintext), nil
+}
+
+// IsEncrypted returns whether a value has been encrypted by a Cipher.
+func IsEncrypted(value string) bool {
+	_, _, ok := split(value)
+	return ok
+}
+
+// split returns the key id and the encoded ciphertext of an encrypted value.
+func split(value string) (id, data string, ok bool) {
+	if !strings.HasPrefix(value, prefix) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(value, prefix), ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,109 @@
+package pii
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+var (
+	oldKey = bytes.Repeat([]byte{1}, 32)
+	newKey = bytes.Repeat([]byte{2}, 32)
+)
+
+func TestCipher(t *testing.T) {
+	keys, err := NewStaticKeys("k1", map[string][]byte{"k1": oldKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCipher(keys)
+
+	enc, err := c.Encrypt("jane@acme.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(enc) || strings.Contains(enc, "jane") {
+		t.Fatalf("enc = %s; want an encrypted value", enc)
+	}
+
+	if again, _ := c.Encrypt("jane@acme.example"); again == enc {
+		t.Errorf("encrypting twice gave the same ciphertext")
+	}
+
+	dec, err := c.Decrypt(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec != "jane@acme.example" {
+		t.Errorf("dec = %s; want = %s", dec, "jane@acme.example")
+	}
+
+	if empty, _ := c.Encrypt(""); empty != "" {
+		t.Errorf("Encrypt(\"\") = %s; want empty", empty)
+	}
+
+	// Values stored before encryption was enabled are read as is.
+	if plain, err := c.Decrypt("Acme Corp"); plain != "Acme Corp" || err != nil {
+		t.Errorf("Decrypt() = %s, %v; want = %s, nil", plain, err, "Acme Corp")
+	}
+
+	tampered := enc[:len(enc)-2] + "AA"
+	if _, err := c.Decrypt(tampered); err != ErrInvalidCiphertext {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidCiphertext)
+	}
+}
+
+func TestCipherRotation(t *testing.T) {
+	before, _ := NewStaticKeys("k1", map[string][]byte{"k1": oldKey})
+	after, _ := NewStaticKeys("k2", map[string][]byte{"k1": oldKey, "k2": newKey})
+
+	enc, err := NewCipher(before).Encrypt("+46 31 123 45 67")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCipher(after)
+
+	if dec, err := c.Decrypt(enc); dec != "+46 31 123 45 67" || err != nil {
+		t.Errorf("Decrypt() = %s, %v; want the value encrypted with the previous key", dec, err)
+	}
+
+	reenc, _ := c.Encrypt("+46 31 123 45 67")
+	if !strings.HasPrefix(reenc, prefix+"k2:") {
+		t.Errorf("reenc = %s; want encrypted with k2", reenc)
+	}
+
+	retired, _ := NewStaticKeys("k2", map[string][]byte{"k2": newKey})
+	if _, err := NewCipher(retired).Decrypt(enc); err != ErrUnknownKey {
+		t.Errorf("err = %v; want = %v", err, ErrUnknownKey)
+	}
+}
+
+func TestParseStaticKeys(t *testing.T) {
+	enc := base64.StdEncoding.EncodeToString
+
+	keys, err := ParseStaticKeys("k2=" + enc(newKey) + ", k1=" + enc(oldKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _, _ := keys.CurrentKey(); id != "k2" {
+		t.Errorf("current = %s; want = %s", id, "k2")
+	}
+	if k, err := keys.Key("k1"); err != nil || !bytes.Equal(k, oldKey) {
+		t.Errorf("Key(k1) = %v, %v; want the old key", k, err)
+	}
+
+	for _, s := range []string{
+		"",
+		"k1",
+		"k1=" + enc([]byte("short")),
+		"k:1=" + enc(oldKey),
+		"k1=not base64",
+	} {
+		if _, err := ParseStaticKeys(s); err == nil {
+			t.Errorf("ParseStaticKeys(%q) should fail", s)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package pii
+
+import (
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type customerRepository struct {
+	cipher *Cipher
+	next   shipping.CustomerRepository
+}
+
+// Store encrypts the name and contact details of the customer, leaving the
+// given customer unchanged.
+func (r *customerRepository) Store(c *shipping.Customer) error {
+	enc := *c
+
+	var err error
+	for _, f := range []*string{&enc.Name, &enc.Contact.Email, &enc.Contact.Phone} {
+		if *f, err = r.cipher.Encrypt(*f); err != nil {
+			return err
+		}
+	}
+
+	return r.next.Store(&enc)
+}
+
+func (r *customerRepository) Find(id shipping.CustomerID) (*shipping.Customer, error) {
+	c, err := r.next.Find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := *c
+
+	for _, f := range []*string{&dec.Name, &dec.Contact.Email, &dec.Contact.Phone} {
+		if *f, err = r.cipher.Decrypt(*f); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dec, nil
+}
+
+// NewCustomerRepository returns a customer repository that encrypts the
+// personal data of customers stored in next.
+func NewCustomerRepository(c *Cipher, next shipping.CustomerRepository) shipping.CustomerRepository {
+	return &customerRepository{cipher: c, next: next}
+}
@@ -0,0 +1,62 @@
+package pii
+
+import (
+	"testing"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+func TestCustomerRepository(t *testing.T) {
+	stored := make(map[shipping.CustomerID]shipping.Customer)
+
+	var next mock.CustomerRepository
+	next.StoreFn = func(c *shipping.Customer) error {
+		stored[c.ID] = *c
+		return nil
+	}
+	next.FindFn = func(id shipping.CustomerID) (*shipping.Customer, error) {
+		c, ok := stored[id]
+		if !ok {
+			return nil, shipping.ErrUnknownCustomer
+		}
+		return &c, nil
+	}
+
+	keys, _ := NewStaticKeys("k1", map[string][]byte{"k1": oldKey})
+	r := NewCustomerRepository(NewCipher(keys), &next)
+
+	c := &shipping.Customer{
+		ID:      "ACME",
+		Name:    "Acme Corp",
+		Contact: shipping.ContactDetails{Email: "shipping@acme.example"},
+	}
+
+	if err := r.Store(c); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Name != "Acme Corp" {
+		t.Errorf("c.Name = %s; want the customer to be left unchanged", c.Name)
+	}
+
+	at := stored["ACME"]
+	if !IsEncrypted(at.Name) || !IsEncrypted(at.Contact.Email) {
+		t.Errorf("stored = %+v; want name and email encrypted", at)
+	}
+	if at.Contact.Phone != "" || at.ID != "ACME" {
+		t.Errorf("stored = %+v; want empty phone and plain id", at)
+	}
+
+	got, err := r.Find("ACME")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != c.Name || got.Contact != c.Contact {
+		t.Errorf("got = %+v; want = %+v", got, c)
+	}
+
+	if _, err := r.Find("INITECH"); err != shipping.ErrUnknownCustomer {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownCustomer)
+	}
+}
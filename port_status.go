@@ -0,0 +1,125 @@
+package shipping
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// PortCondition describes whether a port is operating normally.
+type PortCondition int
+
+// Valid port conditions.
+const (
+	PortOpen PortCondition = iota
+	PortCongested
+	PortClosed
+)
+
+func (c PortCondition) String() string {
+	switch c {
+	case PortOpen:
+		return "Open"
+	case PortCongested:
+		return "Congested"
+	case PortClosed:
+		return "Closed"
+	}
+	return ""
+}
+
+// ErrUnknownPortCondition is used when parsing an unsupported port condition.
+var ErrUnknownPortCondition = errors.New("unknown port condition")
+
+// ParsePortCondition parses a port condition name, such as "congested".
+func ParsePortCondition(s string) (PortCondition, error) {
+	for _, c := range []PortCondition{PortOpen, PortCongested, PortClosed} {
+		if strings.EqualFold(s, c.String()) {
+			return c, nil
+		}
+	}
+	return PortOpen, ErrUnknownPortCondition
+}
+
+// PortStatus is an advisory on the condition of a port, such as congestion
+// or a closure due to weather or strikes. A newer advisory for a port
+// replaces the previous one.
+type PortStatus struct {
+	Location  UNLocode
+	Condition PortCondition
+
+	// Delay is the expected additional waiting time at a congested port.
+	Delay time.Duration
+
+	Reason string
+	Issued time.Time
+
+	// Until is when the advisory expires. The zero value means until
+	// further notice.
+	Until time.Time
+}
+
+// InEffect returns whether the advisory applies at the given time.
+func (s PortStatus) InEffect(t time.Time) bool {
+	if s.Condition == PortOpen {
+		return false
+	}
+	return s.Until.IsZero() || t.Before(s.Until)
+}
+
+// PortStatuses holds the advisories in effect, by location.
+type PortStatuses map[UNLocode]PortStatus
+
+// NewPortStatuses returns the advisories in effect at the given time.
+func NewPortStatuses(ss []PortStatus, t time.Time) PortStatuses {
+	p := make(PortStatuses)
+	for _, s := range ss {
+		if s.InEffect(t) {
+			p[s.Location] = s
+		}
+	}
+	return p
+}
+
+// Affecting returns the advisories for the ports an itinerary calls at, in
+// the order they are called at.
+func (p PortStatuses) Affecting(i Itinerary) []PortStatus {
+	var (
+		result []PortStatus
+		seen   = make(map[UNLocode]bool)
+	)
+	for _, l := range i.Legs {
+		for _, loc := range []UNLocode{l.LoadLocation, l.UnloadLocation} {
+			if s, ok := p[loc]; ok && !seen[loc] {
+				result = append(result, s)
+				seen[loc] = true
+			}
+		}
+	}
+	return result
+}
+
+// IsClosed returns whether an itinerary calls at a closed port.
+func (p PortStatuses) IsClosed(i Itinerary) bool {
+	for _, s := range p.Affecting(i) {
+		if s.Condition == PortClosed {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns the expected additional waiting time along an itinerary.
+func (p PortStatuses) Delay(i Itinerary) time.Duration {
+	var d time.Duration
+	for _, s := range p.Affecting(i) {
+		d += s.Delay
+	}
+	return d
+}
+
+// PortStatusRepository provides access a port status store.
+type PortStatusRepository interface {
+	Store(s PortStatus) error
+	FindAll() []PortStatus
+}
@@ -0,0 +1,60 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPortStatuses(t *testing.T) {
+	now := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	p := NewPortStatuses([]PortStatus{
+		{Location: SESTO, Condition: PortCongested, Delay: 12 * time.Hour},
+		{Location: CNHKG, Condition: PortClosed, Until: now.Add(-time.Hour)},
+		{Location: USNYC, Condition: PortClosed, Until: now.Add(time.Hour)},
+		{Location: FIHEL, Condition: PortOpen},
+	}, now)
+
+	if len(p) != 2 {
+		t.Fatalf("len(p) = %d; want = %d", len(p), 2)
+	}
+
+	i := Itinerary{Legs: []Leg{
+		{LoadLocation: CNHKG, UnloadLocation: SESTO},
+		{LoadLocation: SESTO, UnloadLocation: FIHEL},
+	}}
+
+	if p.IsClosed(i) {
+		t.Errorf("p.IsClosed(i) = true; want = false")
+	}
+	if got := p.Delay(i); got != 12*time.Hour {
+		t.Errorf("p.Delay(i) = %v; want = %v", got, 12*time.Hour)
+	}
+
+	i.Legs = append(i.Legs, Leg{LoadLocation: FIHEL, UnloadLocation: USNYC})
+
+	if !p.IsClosed(i) {
+		t.Errorf("p.IsClosed(i) = false; want = true")
+	}
+	if got := len(p.Affecting(i)); got != 2 {
+		t.Errorf("len(p.Affecting(i)) = %d; want = %d", got, 2)
+	}
+}
+
+func TestParsePortCondition(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want PortCondition
+		err  error
+	}{
+		{"congested", PortCongested, nil},
+		{"Closed", PortClosed, nil},
+		{"OPEN", PortOpen, nil},
+		{"flooded", PortOpen, ErrUnknownPortCondition},
+	} {
+		got, err := ParsePortCondition(tt.in)
+		if got != tt.want || err != tt.err {
+			t.Errorf("ParsePortCondition(%q) = %v, %v; want = %v, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
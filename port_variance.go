@@ -0,0 +1,98 @@
+package shipping
+
+import "time"
+
+// HandlingVariance is how a cargo was loaded or unloaded at a port compared
+// to when its itinerary planned for it.
+type HandlingVariance struct {
+	Location   UNLocode
+	TrackingID TrackingID
+	Type       HandlingEventType
+	Planned    time.Time
+	Completed  time.Time
+}
+
+// Variance returns how much later than planned the cargo was handled, or
+// negative if it was handled early.
+func (v HandlingVariance) Variance() time.Duration {
+	return v.Completed.Sub(v.Planned)
+}
+
+// PlannedCompletion returns when the itinerary planned for a cargo to be
+// handled as in the event. Only loads and unloads of legs with known times
+// are planned.
+func (i Itinerary) PlannedCompletion(e HandlingEvent) (time.Time, bool) {
+	for _, l := range i.Legs {
+		if l.VoyageNumber != e.Activity.VoyageNumber {
+			continue
+		}
+		switch e.Activity.Type {
+		case Load:
+			if l.LoadLocation == e.Activity.Location && !l.LoadTime.IsZero() {
+				return l.LoadTime, true
+			}
+		case Unload:
+			if l.UnloadLocation == e.Activity.Location && !l.UnloadTime.IsZero() {
+				return l.UnloadTime, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// PortVariance sums up the variances of the cargos handled at a port.
+type PortVariance struct {
+	Location UNLocode
+	Handled  int
+	Late     int
+
+	// TotalVariance sums up the variances of the cargos handled.
+	TotalVariance time.Duration
+}
+
+// Add records the variance of a cargo handled at the port.
+func (p *PortVariance) Add(v HandlingVariance) {
+	p.Handled++
+	if v.Variance() > 0 {
+		p.Late++
+	}
+	p.TotalVariance += v.Variance()
+}
+
+// AverageVariance returns how much later than planned cargos were handled
+// on average.
+func (p PortVariance) AverageVariance() time.Duration {
+	if p.Handled == 0 {
+		return 0
+	}
+	return p.TotalVariance / time.Duration(p.Handled)
+}
+
+// Exceeds returns whether cargos were handled later than planned by more
+// than the threshold on average, over at least n cargos.
+func (p PortVariance) Exceeds(threshold time.Duration, n int) bool {
+	return p.Handled > 0 && p.Handled >= n && p.AverageVariance() > threshold
+}
+
+// PortVariances sums up variances by port.
+func PortVariances(vs []HandlingVariance) map[UNLocode]*PortVariance {
+	result := make(map[UNLocode]*PortVariance)
+	for _, v := range vs {
+		p, ok := result[v.Location]
+		if !ok {
+			p = &PortVariance{Location: v.Location}
+			result[v.Location] = p
+		}
+		p.Add(v)
+	}
+	return result
+}
+
+// HandlingVarianceRepository provides access a handling variance store.
+type HandlingVarianceRepository interface {
+	Store(v HandlingVariance) error
+
+	// FindSince returns the variances of the cargos handled at or after the
+	// given time.
+	FindSince(t time.Time) []HandlingVariance
+}
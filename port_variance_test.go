@@ -0,0 +1,56 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestItinerary_PlannedCompletion(t *testing.T) {
+	t0 := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	i := Itinerary{Legs: []Leg{
+		NewLeg("V100", CNHKG, DEHAM, t0, t0.Add(20*24*time.Hour)),
+		NewLeg("V200", DEHAM, SESTO, time.Time{}, time.Time{}),
+	}}
+
+	for _, tt := range []struct {
+		activity HandlingActivity
+		want     time.Time
+		ok       bool
+	}{
+		{HandlingActivity{Type: Load, Location: CNHKG, VoyageNumber: "V100"}, t0, true},
+		{HandlingActivity{Type: Unload, Location: DEHAM, VoyageNumber: "V100"}, t0.Add(20 * 24 * time.Hour), true},
+		{HandlingActivity{Type: Unload, Location: NLRTM, VoyageNumber: "V100"}, time.Time{}, false},
+		{HandlingActivity{Type: Load, Location: DEHAM, VoyageNumber: "V200"}, time.Time{}, false},
+		{HandlingActivity{Type: Customs, Location: DEHAM}, time.Time{}, false},
+	} {
+		got, ok := i.PlannedCompletion(HandlingEvent{Activity: tt.activity})
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("PlannedCompletion(%+v) = %v, %v; want = %v, %v", tt.activity, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPortVariances(t *testing.T) {
+	t0 := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	ps := PortVariances([]HandlingVariance{
+		{Location: CNHKG, Planned: t0, Completed: t0.Add(10 * time.Hour)},
+		{Location: CNHKG, Planned: t0, Completed: t0.Add(-2 * time.Hour)},
+		{Location: SESTO, Planned: t0, Completed: t0},
+	})
+
+	p := ps[CNHKG]
+	if p.Handled != 2 || p.Late != 1 {
+		t.Errorf("variance = %+v", p)
+	}
+	if want := 4 * time.Hour; p.AverageVariance() != want {
+		t.Errorf("AverageVariance() = %v; want = %v", p.AverageVariance(), want)
+	}
+	if !p.Exceeds(3*time.Hour, 2) || p.Exceeds(3*time.Hour, 3) || p.Exceeds(4*time.Hour, 2) {
+		t.Errorf("Exceeds does not hold for %+v", p)
+	}
+	if ps[SESTO].Late != 0 {
+		t.Errorf("variance = %+v; want none late", ps[SESTO])
+	}
+}
@@ -0,0 +1,124 @@
+package portal
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) RegisterCustomer(ctx context.Context, id shipping.CustomerID, name, key string) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "register_customer").Add(1)
+		s.requestLatency.With("method", "register_customer").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.RegisterCustomer(ctx, id, name, key)
+}
+
+func (s *instrumentingService) Authenticate(ctx context.Context, id shipping.CustomerID, key string) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "authenticate").Add(1)
+		s.requestLatency.With("method", "authenticate").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Authenticate(ctx, id, key)
+}
+
+func (s *instrumentingService) Cargos(ctx context.Context, customer shipping.CustomerID) []Cargo {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "list_cargos").Add(1)
+		s.requestLatency.With("method", "list_cargos").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Cargos(ctx, customer)
+}
+
+func (s *instrumentingService) RecentEvents(ctx context.Context, customer shipping.CustomerID, limit int) []Event {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "recent_events").Add(1)
+		s.requestLatency.With("method", "recent_events").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.RecentEvents(ctx, customer, limit)
+}
+
+func (s *instrumentingService) Preferences(ctx context.Context, customer shipping.CustomerID) (Preferences, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "preferences").Add(1)
+		s.requestLatency.With("method", "preferences").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Preferences(ctx, customer)
+}
+
+func (s *instrumentingService) SetPreferences(ctx context.Context, customer shipping.CustomerID, p Preferences) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "set_preferences").Add(1)
+		s.requestLatency.With("method", "set_preferences").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.SetPreferences(ctx, customer, p)
+}
+
+func (s *instrumentingService) Frequency(ctx context.Context, customer shipping.CustomerID) (Frequency, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "frequency").Add(1)
+		s.requestLatency.With("method", "frequency").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Frequency(ctx, customer)
+}
+
+func (s *instrumentingService) SetFrequency(ctx context.Context, customer shipping.CustomerID, f Frequency) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "set_frequency").Add(1)
+		s.requestLatency.With("method", "set_frequency").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.SetFrequency(ctx, customer, f)
+}
+
+func (s *instrumentingService) Contact(ctx context.Context, customer shipping.CustomerID) (Contact, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "contact").Add(1)
+		s.requestLatency.With("method", "contact").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Contact(ctx, customer)
+}
+
+func (s *instrumentingService) SetContact(ctx context.Context, customer shipping.CustomerID, c Contact) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "set_contact").Add(1)
+		s.requestLatency.With("method", "set_contact").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.SetContact(ctx, customer, c)
+}
+
+func (s *instrumentingService) Unsubscribe(ctx context.Context, customer shipping.CustomerID, t shipping.NotificationType, token string) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "unsubscribe").Add(1)
+		s.requestLatency.With("method", "unsubscribe").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Unsubscribe(ctx, customer, t, token)
+}
@@ -0,0 +1,166 @@
+package portal
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service. Keys and
+// tokens are never logged.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) RegisterCustomer(ctx context.Context, id shipping.CustomerID, name, key string) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "register_customer",
+			"request_id", correlation.FromContext(ctx),
+			"customer", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.RegisterCustomer(ctx, id, name, key)
+}
+
+func (s *loggingService) Authenticate(ctx context.Context, id shipping.CustomerID, key string) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "authenticate",
+			"request_id", correlation.FromContext(ctx),
+			"customer", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Authenticate(ctx, id, key)
+}
+
+func (s *loggingService) Cargos(ctx context.Context, customer shipping.CustomerID) []Cargo {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_cargos",
+			"request_id", correlation.FromContext(ctx),
+			"customer", customer,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.Cargos(ctx, customer)
+}
+
+func (s *loggingService) RecentEvents(ctx context.Context, customer shipping.CustomerID, limit int) []Event {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "recent_events",
+			"request_id", correlation.FromContext(ctx),
+			"customer", customer,
+			"limit", limit,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.RecentEvents(ctx, customer, limit)
+}
+
+func (s *loggingService) Preferences(ctx context.Context, customer shipping.CustomerID) (p Preferences, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "preferences",
+			"request_id", correlation.FromContext(ctx),
+			"customer", customer,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Preferences(ctx, customer)
+}
+
+func (s *loggingService) SetPreferences(ctx context.Context, customer shipping.CustomerID, p Preferences) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "set_preferences",
+			"request_id", correlation.FromContext(ctx),
+			"customer", customer,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.SetPreferences(ctx, customer, p)
+}
+
+func (s *loggingService) Frequency(ctx context.Context, customer shipping.CustomerID) (f Frequency, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "frequency",
+			"request_id", correlation.FromContext(ctx),
+			"customer", customer,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Frequency(ctx, customer)
+}
+
+func (s *loggingService) SetFrequency(ctx context.Context, customer shipping.CustomerID, f Frequency) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "set_frequency",
+			"request_id", correlation.FromContext(ctx),
+			"customer", customer,
+			"digest", f.Digest,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.SetFrequency(ctx, customer, f)
+}
+
+func (s *loggingService) Contact(ctx context.Context, customer shipping.CustomerID) (c Contact, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "contact",
+			"request_id", correlation.FromContext(ctx),
+			"customer", customer,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Contact(ctx, customer)
+}
+
+func (s *loggingService) SetContact(ctx context.Context, customer shipping.CustomerID, c Contact) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "set_contact",
+			"request_id", correlation.FromContext(ctx),
+			"customer", customer,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.SetContact(ctx, customer, c)
+}
+
+func (s *loggingService) Unsubscribe(ctx context.Context, customer shipping.CustomerID, t shipping.NotificationType, token string) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "unsubscribe",
+			"request_id", correlation.FromContext(ctx),
+			"customer", customer,
+			"type", t,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Unsubscribe(ctx, customer, t, token)
+}
@@ -0,0 +1,424 @@
+// Package portal provides the use-case of customers following their own
+// cargos. Used by views facing customers.
+package portal
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/mail"
+	"sort"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// ErrInvalidToken is returned when an unsubscribe token does not match the
+// customer and notification type.
+var ErrInvalidToken = errors.New("invalid unsubscribe token")
+
+// Service is the interface that provides customer portal methods. Every
+// method but RegisterCustomer, Authenticate and Unsubscribe is scoped to an
+// authenticated customer.
+type Service interface {
+	// RegisterCustomer registers a customer that authenticates with the
+	// given key. Registering an existing customer replaces its name and key.
+	RegisterCustomer(ctx context.Context, id shipping.CustomerID, name, key string) error
+
+	// Authenticate checks the key of a customer.
+	Authenticate(ctx context.Context, id shipping.CustomerID, key string) error
+
+	// Cargos returns the cargos booked by the customer.
+	Cargos(ctx context.Context, customer shipping.CustomerID) []Cargo
+
+	// RecentEvents returns the most recent handling events of the cargos
+	// booked by the customer, latest first.
+	RecentEvents(ctx context.Context, customer shipping.CustomerID, limit int) []Event
+
+	// Preferences returns the notification channels of the customer, per
+	// notification type.
+	Preferences(ctx context.Context, customer shipping.CustomerID) (Preferences, error)
+
+	// SetPreferences replaces the notification preferences of the customer.
+	SetPreferences(ctx context.Context, customer shipping.CustomerID, p Preferences) error
+
+	// Frequency returns how often the customer is notified.
+	Frequency(ctx context.Context, customer shipping.CustomerID) (Frequency, error)
+
+	// SetFrequency replaces how often the customer is notified, by
+	// collecting notifications into digests and by throttling notifications
+	// of a type about the same cargo.
+	SetFrequency(ctx context.Context, customer shipping.CustomerID, f Frequency) error
+
+	// Contact returns how the customer can be reached.
+	Contact(ctx context.Context, customer shipping.CustomerID) (Contact, error)
+
+	// SetContact replaces how the customer can be reached.
+	SetContact(ctx context.Context, customer shipping.CustomerID, c Contact) error
+
+	// Unsubscribe stops notifications of a type, or of every type if t is
+	// empty. The token is the one included in the notifications, see
+	// UnsubscribeToken.
+	Unsubscribe(ctx context.Context, customer shipping.CustomerID, t shipping.NotificationType, token string) error
+}
+
+// defaultRecentEvents is the number of recent events returned unless a limit
+// is given.
+const defaultRecentEvents = 20
+
+type service struct {
+	customers      shipping.CustomerRepository
+	cargos         shipping.CargoRepository
+	handlingEvents shipping.HandlingEventRepository
+	secret         []byte
+	screener       shipping.Screener
+}
+
+func (s *service) RegisterCustomer(ctx context.Context, id shipping.CustomerID, name, key string) error {
+	if id == "" || key == "" {
+		return ErrInvalidArgument
+	}
+
+	c, err := s.customers.Find(id)
+	if err == shipping.ErrUnknownCustomer {
+		c = &shipping.Customer{ID: id}
+	} else if err != nil {
+		return err
+	}
+
+	if s.screener != nil && name != c.Name {
+		result, err := s.screener.Screen(ctx, shipping.ScreeningRequest{Customer: id, CustomerName: name})
+		if err != nil {
+			return err
+		}
+		if result.IsBlocked() {
+			return shipping.ErrScreeningBlocked
+		}
+	}
+
+	c.Name = name
+	c.KeyHash = shipping.HashCustomerKey(key)
+
+	return s.customers.Store(c)
+}
+
+func (s *service) Authenticate(ctx context.Context, id shipping.CustomerID, key string) error {
+	if id == "" {
+		return shipping.ErrUnauthorizedCustomer
+	}
+
+	c, err := s.customers.Find(id)
+	if err == shipping.ErrUnknownCustomer {
+		return shipping.ErrUnauthorizedCustomer
+	}
+	if err != nil {
+		return err
+	}
+
+	if !c.Authenticate(key) {
+		return shipping.ErrUnauthorizedCustomer
+	}
+
+	return nil
+}
+
+func (s *service) Cargos(ctx context.Context, customer shipping.CustomerID) []Cargo {
+	result := []Cargo{}
+	for _, c := range s.findCargos(customer) {
+		result = append(result, assemble(c))
+	}
+	return result
+}
+
+func (s *service) RecentEvents(ctx context.Context, customer shipping.CustomerID, limit int) []Event {
+	if limit <= 0 {
+		limit = defaultRecentEvents
+	}
+
+	var ids []shipping.TrackingID
+	for _, c := range s.findCargos(customer) {
+		ids = append(ids, c.TrackingID)
+	}
+
+	var events []shipping.HandlingEvent
+	if len(ids) > 0 {
+		for _, h := range s.handlingEvents.QueryHandlingHistories(ids) {
+			events = append(events, h.HandlingEvents...)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Completed.After(events[j].Completed)
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+
+	result := []Event{}
+	for _, e := range events {
+		result = append(result, assembleEvent(e))
+	}
+	return result
+}
+
+// findCargos returns the cargos booked by a customer.
+func (s *service) findCargos(customer shipping.CustomerID) []*shipping.Cargo {
+	var result []*shipping.Cargo
+	if customer == "" {
+		return result
+	}
+	for _, c := range s.cargos.FindAll() {
+		if c.Customer == customer {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func (s *service) Preferences(ctx context.Context, customer shipping.CustomerID) (Preferences, error) {
+	c, err := s.customers.Find(customer)
+	if err != nil {
+		return nil, err
+	}
+
+	p := Preferences{}
+	for t, chs := range c.Notifications {
+		for _, ch := range chs {
+			p[string(t)] = append(p[string(t)], string(ch))
+		}
+	}
+	return p, nil
+}
+
+func (s *service) SetPreferences(ctx context.Context, customer shipping.CustomerID, p Preferences) error {
+	np := make(shipping.NotificationPreferences)
+	for t, chs := range p {
+		nt := shipping.NotificationType(t)
+		if !shipping.IsValidNotificationType(nt) {
+			return ErrInvalidArgument
+		}
+		for _, ch := range chs {
+			nc := shipping.NotificationChannel(ch)
+			if !nc.IsValid() {
+				return ErrInvalidArgument
+			}
+			np[nt] = append(np[nt], nc)
+		}
+	}
+
+	c, err := s.customers.Find(customer)
+	if err != nil {
+		return err
+	}
+
+	c.Notifications = np
+
+	return s.customers.Store(c)
+}
+
+func (s *service) Frequency(ctx context.Context, customer shipping.CustomerID) (Frequency, error) {
+	c, err := s.customers.Find(customer)
+	if err != nil {
+		return Frequency{}, err
+	}
+
+	f := Frequency{Digest: string(c.Digest)}
+	for t, d := range c.Throttling {
+		if f.Throttle == nil {
+			f.Throttle = make(map[string]string)
+		}
+		f.Throttle[string(t)] = d.String()
+	}
+	return f, nil
+}
+
+func (s *service) SetFrequency(ctx context.Context, customer shipping.CustomerID, f Frequency) error {
+	digest := shipping.NotificationDigest(f.Digest)
+	if !digest.IsValid() {
+		return ErrInvalidArgument
+	}
+
+	var throttling shipping.NotificationThrottling
+	for t, v := range f.Throttle {
+		nt := shipping.NotificationType(t)
+		if !shipping.IsValidNotificationType(nt) {
+			return ErrInvalidArgument
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return ErrInvalidArgument
+		}
+		if throttling == nil {
+			throttling = make(shipping.NotificationThrottling)
+		}
+		throttling[nt] = d
+	}
+
+	c, err := s.customers.Find(customer)
+	if err != nil {
+		return err
+	}
+
+	c.Digest = digest
+	c.Throttling = throttling
+
+	return s.customers.Store(c)
+}
+
+func (s *service) Contact(ctx context.Context, customer shipping.CustomerID) (Contact, error) {
+	c, err := s.customers.Find(customer)
+	if err != nil {
+		return Contact{}, err
+	}
+
+	return Contact{
+		Email: c.Contact.Email,
+		Phone: c.Contact.Phone,
+	}, nil
+}
+
+func (s *service) SetContact(ctx context.Context, customer shipping.CustomerID, contact Contact) error {
+	if contact.Email != "" {
+		if _, err := mail.ParseAddress(contact.Email); err != nil {
+			return ErrInvalidArgument
+		}
+	}
+
+	c, err := s.customers.Find(customer)
+	if err != nil {
+		return err
+	}
+
+	c.Contact = shipping.ContactDetails{
+		Email: contact.Email,
+		Phone: contact.Phone,
+	}
+
+	return s.customers.Store(c)
+}
+
+func (s *service) Unsubscribe(ctx context.Context, customer shipping.CustomerID, t shipping.NotificationType, token string) error {
+	if customer == "" || token == "" {
+		return ErrInvalidArgument
+	}
+	if t != "" && !shipping.IsValidNotificationType(t) {
+		return ErrInvalidArgument
+	}
+
+	if !hmac.Equal([]byte(token), []byte(UnsubscribeToken(s.secret, customer, t))) {
+		return ErrInvalidToken
+	}
+
+	c, err := s.customers.Find(customer)
+	if err != nil {
+		return err
+	}
+
+	c.Notifications.Unsubscribe(t)
+
+	return s.customers.Store(c)
+}
+
+// UnsubscribeToken returns the token that lets the recipient of a
+// notification unsubscribe from notifications of a type, or of every type if
+// t is empty, without signing in.
+func UnsubscribeToken(secret []byte, customer shipping.CustomerID, t shipping.NotificationType) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(string(customer) + "/" + string(t)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Option configures the customer portal service.
+type Option func(*service)
+
+// WithScreening screens customers against sanctioned parties when they
+// register or change their name. Customers are refused registration if
+// the screening fails or blocks them.
+func WithScreening(sc shipping.Screener) Option {
+	return func(s *service) {
+		s.screener = sc
+	}
+}
+
+// NewService creates a customer portal service with necessary dependencies.
+// The secret signs unsubscribe tokens.
+func NewService(customers shipping.CustomerRepository, cargos shipping.CargoRepository, events shipping.HandlingEventRepository, secret []byte, opts ...Option) Service {
+	s := &service{
+		customers:      customers,
+		cargos:         cargos,
+		handlingEvents: events,
+		secret:         secret,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Cargo is a read model for the cargos of a customer.
+type Cargo struct {
+	TrackingID        string    `json:"tracking_id"`
+	Origin            string    `json:"origin"`
+	Destination       string    `json:"destination"`
+	ArrivalDeadline   time.Time `json:"arrival_deadline"`
+	ETA               time.Time `json:"eta"`
+	TransportStatus   string    `json:"transport_status"`
+	LastKnownLocation string    `json:"last_known_location,omitempty"`
+	Misdirected       bool      `json:"misdirected"`
+}
+
+func assemble(c *shipping.Cargo) Cargo {
+	return Cargo{
+		TrackingID:        string(c.TrackingID),
+		Origin:            string(c.Origin),
+		Destination:       string(c.RouteSpecification.Destination),
+		ArrivalDeadline:   c.RouteSpecification.ArrivalDeadline,
+		ETA:               c.Delivery.ETA,
+		TransportStatus:   c.Delivery.TransportStatus.String(),
+		LastKnownLocation: string(c.Delivery.LastKnownLocation),
+		Misdirected:       c.Delivery.IsMisdirected,
+	}
+}
+
+// Event is a read model for the handling events of the cargos of a
+// customer.
+type Event struct {
+	TrackingID string    `json:"tracking_id"`
+	Type       string    `json:"type"`
+	Location   string    `json:"location"`
+	Voyage     string    `json:"voyage,omitempty"`
+	Completed  time.Time `json:"completed"`
+}
+
+func assembleEvent(e shipping.HandlingEvent) Event {
+	return Event{
+		TrackingID: string(e.TrackingID),
+		Type:       e.Activity.Type.String(),
+		Location:   string(e.Activity.Location),
+		Voyage:     string(e.Activity.VoyageNumber),
+		Completed:  e.Completed,
+	}
+}
+
+// Preferences is a read model for notification preferences, holding the
+// channels per notification type.
+type Preferences map[string][]string
+
+// Frequency is a read model for how often a customer is notified. Digest is
+// empty, "hourly" or "daily", and Throttle holds the least time between two
+// notifications of a type about the same cargo, such as "6h".
+type Frequency struct {
+	Digest   string            `json:"digest,omitempty"`
+	Throttle map[string]string `json:"throttle,omitempty"`
+}
+
+// Contact is a read model for how a customer can be reached.
+type Contact struct {
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
@@ -0,0 +1,211 @@
+package portal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+var secret = []byte("secret")
+
+func newCustomerRepository() *mock.CustomerRepository {
+	stored := make(map[shipping.CustomerID]shipping.Customer)
+
+	var customers mock.CustomerRepository
+	customers.StoreFn = func(c *shipping.Customer) error {
+		stored[c.ID] = *c
+		return nil
+	}
+	customers.FindFn = func(id shipping.CustomerID) (*shipping.Customer, error) {
+		c, ok := stored[id]
+		if !ok {
+			return nil, shipping.ErrUnknownCustomer
+		}
+		return &c, nil
+	}
+	return &customers
+}
+
+func newCargoRepository() *mock.CargoRepository {
+	mine := shipping.NewCargo("ABC123", shipping.RouteSpecification{Origin: shipping.SESTO, Destination: shipping.CNHKG})
+	mine.Customer = "ACME"
+
+	theirs := shipping.NewCargo("FTL456", shipping.RouteSpecification{Origin: shipping.AUMEL, Destination: shipping.SESTO})
+	theirs.Customer = "INITECH"
+
+	var cargos mock.CargoRepository
+	cargos.FindAllFn = func() []*shipping.Cargo {
+		return []*shipping.Cargo{mine, theirs}
+	}
+	return &cargos
+}
+
+func TestAuthenticate(t *testing.T) {
+	s := NewService(newCustomerRepository(), nil, nil, secret)
+
+	if err := s.RegisterCustomer(context.Background(), "ACME", "Acme Corp", "key"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		id   shipping.CustomerID
+		key  string
+		want error
+	}{
+		{"ACME", "key", nil},
+		{"ACME", "wrong", shipping.ErrUnauthorizedCustomer},
+		{"INITECH", "key", shipping.ErrUnauthorizedCustomer},
+		{"", "", shipping.ErrUnauthorizedCustomer},
+	} {
+		if err := s.Authenticate(context.Background(), tt.id, tt.key); err != tt.want {
+			t.Errorf("Authenticate(%s, %s) = %v; want = %v", tt.id, tt.key, err, tt.want)
+		}
+	}
+}
+
+func TestCargosAndEvents(t *testing.T) {
+	completed := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoriesFn = func(ids []shipping.TrackingID) map[shipping.TrackingID]shipping.HandlingHistory {
+		if len(ids) != 1 || ids[0] != "ABC123" {
+			t.Errorf("ids = %v; want = [ABC123]", ids)
+		}
+		return map[shipping.TrackingID]shipping.HandlingHistory{
+			"ABC123": {HandlingEvents: []shipping.HandlingEvent{
+				{TrackingID: "ABC123", Activity: shipping.HandlingActivity{Type: shipping.Receive, Location: shipping.SESTO}, Completed: completed},
+				{TrackingID: "ABC123", Activity: shipping.HandlingActivity{Type: shipping.Load, Location: shipping.SESTO, VoyageNumber: "V100"}, Completed: completed.Add(time.Hour)},
+			}},
+		}
+	}
+
+	s := NewService(newCustomerRepository(), newCargoRepository(), &events, secret)
+
+	cs := s.Cargos(context.Background(), "ACME")
+	if len(cs) != 1 || cs[0].TrackingID != "ABC123" {
+		t.Errorf("cs = %+v; want only ABC123", cs)
+	}
+
+	es := s.RecentEvents(context.Background(), "ACME", 1)
+	if len(es) != 1 {
+		t.Fatalf("len(es) = %d; want = %d", len(es), 1)
+	}
+	if es[0].Type != "Load" {
+		t.Errorf("es[0].Type = %s; want = %s", es[0].Type, "Load")
+	}
+
+	if cs := s.Cargos(context.Background(), ""); len(cs) != 0 {
+		t.Errorf("len(cs) = %d; want = %d", len(cs), 0)
+	}
+}
+
+func TestPreferencesAndUnsubscribe(t *testing.T) {
+	s := NewService(newCustomerRepository(), nil, nil, secret)
+
+	if err := s.RegisterCustomer(context.Background(), "ACME", "Acme Corp", "key"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SetPreferences(context.Background(), "ACME", Preferences{"arrived": {"pigeon"}}); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+	if err := s.SetPreferences(context.Background(), "ACME", Preferences{"teleported": {"email"}}); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+
+	if err := s.SetPreferences(context.Background(), "ACME", Preferences{
+		"arrived": {"email", "sms"},
+		"handled": {"webhook"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	token := UnsubscribeToken(secret, "ACME", shipping.CargoHandledNotification)
+
+	if err := s.Unsubscribe(context.Background(), "ACME", shipping.CargoArrivedNotification, token); err != ErrInvalidToken {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidToken)
+	}
+
+	if err := s.Unsubscribe(context.Background(), "ACME", shipping.CargoHandledNotification, token); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := s.Preferences(context.Background(), "ACME")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p) != 1 || len(p["arrived"]) != 2 {
+		t.Errorf("p = %v; want only arrived", p)
+	}
+
+	// Registering again keeps the preferences.
+	if err := s.RegisterCustomer(context.Background(), "ACME", "Acme Corp", "new key"); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := s.Preferences(context.Background(), "ACME"); len(p) != 1 {
+		t.Errorf("len(p) = %d; want = %d", len(p), 1)
+	}
+}
+
+func TestFrequency(t *testing.T) {
+	s := NewService(newCustomerRepository(), nil, nil, secret)
+
+	if err := s.RegisterCustomer(context.Background(), "ACME", "Acme Corp", "key"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range []Frequency{
+		{Digest: "weekly"},
+		{Throttle: map[string]string{"teleported": "1h"}},
+		{Throttle: map[string]string{"handled": "soon"}},
+		{Throttle: map[string]string{"handled": "-1h"}},
+	} {
+		if err := s.SetFrequency(context.Background(), "ACME", f); err != ErrInvalidArgument {
+			t.Errorf("err = %v for %v; want = %v", err, f, ErrInvalidArgument)
+		}
+	}
+
+	if err := s.SetFrequency(context.Background(), "ACME", Frequency{
+		Digest:   "daily",
+		Throttle: map[string]string{"handled": "6h"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := s.Frequency(context.Background(), "ACME")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Digest != "daily" || f.Throttle["handled"] != "6h0m0s" {
+		t.Errorf("f = %+v; want daily digest with handled throttled to 6h", f)
+	}
+}
+
+func TestContact(t *testing.T) {
+	s := NewService(newCustomerRepository(), nil, nil, secret)
+
+	if err := s.RegisterCustomer(context.Background(), "ACME", "Acme Corp", "key"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SetContact(context.Background(), "ACME", Contact{Email: "not an address"}); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+
+	want := Contact{Email: "shipping@acme.example", Phone: "+46 31 123 45 67"}
+
+	if err := s.SetContact(context.Background(), "ACME", want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Contact(context.Background(), "ACME")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got = %+v; want = %+v", got, want)
+	}
+}
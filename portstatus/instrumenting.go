@@ -0,0 +1,89 @@
+package portstatus
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) Advise(ctx context.Context, ps shipping.PortStatus) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "advise").Add(1)
+		s.requestLatency.With("method", "advise").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Advise(ctx, ps)
+}
+
+func (s *instrumentingService) Import(ctx context.Context, r io.Reader) (int, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "import").Add(1)
+		s.requestLatency.With("method", "import").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Import(ctx, r)
+}
+
+func (s *instrumentingService) Statuses(ctx context.Context) []Status {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "list_statuses").Add(1)
+		s.requestLatency.With("method", "list_statuses").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Statuses(ctx)
+}
+
+func (s *instrumentingService) SetCalendar(ctx context.Context, c shipping.WorkingCalendar) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "set_calendar").Add(1)
+		s.requestLatency.With("method", "set_calendar").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.SetCalendar(ctx, c)
+}
+
+func (s *instrumentingService) Calendars(ctx context.Context) []Calendar {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "list_calendars").Add(1)
+		s.requestLatency.With("method", "list_calendars").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Calendars(ctx)
+}
+
+func (s *instrumentingService) RecordVariance(ctx context.Context, e shipping.HandlingEvent) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "record_variance").Add(1)
+		s.requestLatency.With("method", "record_variance").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.RecordVariance(ctx, e)
+}
+
+func (s *instrumentingService) Performance(ctx context.Context) []Performance {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "performance").Add(1)
+		s.requestLatency.With("method", "performance").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Performance(ctx)
+}
@@ -0,0 +1,130 @@
+package portstatus
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) Advise(ctx context.Context, ps shipping.PortStatus) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "advise",
+			"request_id", correlation.FromContext(ctx),
+			"location", ps.Location,
+			"condition", ps.Condition,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Advise(ctx, ps)
+}
+
+func (s *loggingService) Import(ctx context.Context, r io.Reader) (n int, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "import",
+			"request_id", correlation.FromContext(ctx),
+			"imported", n,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Import(ctx, r)
+}
+
+func (s *loggingService) Statuses(ctx context.Context) []Status {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_statuses",
+			"request_id", correlation.FromContext(ctx),
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.Statuses(ctx)
+}
+
+func (s *loggingService) SetCalendar(ctx context.Context, c shipping.WorkingCalendar) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "set_calendar",
+			"request_id", correlation.FromContext(ctx),
+			"location", c.Location,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.SetCalendar(ctx, c)
+}
+
+func (s *loggingService) Calendars(ctx context.Context) []Calendar {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_calendars",
+			"request_id", correlation.FromContext(ctx),
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.Calendars(ctx)
+}
+
+func (s *loggingService) RecordVariance(ctx context.Context, e shipping.HandlingEvent) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "record_variance",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", e.TrackingID,
+			"location", e.Activity.Location,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.RecordVariance(ctx, e)
+}
+
+func (s *loggingService) Performance(ctx context.Context) []Performance {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "performance",
+			"request_id", correlation.FromContext(ctx),
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.Performance(ctx)
+}
+
+type loggingEventHandler struct {
+	logger log.Logger
+}
+
+// NewLoggingEventHandler returns an EventHandler that alerts operations of
+// deteriorating ports through the log.
+func NewLoggingEventHandler(logger log.Logger) EventHandler {
+	return &loggingEventHandler{logger}
+}
+
+func (h *loggingEventHandler) PortVarianceExceeded(ctx context.Context, p shipping.PortVariance) {
+	h.logger.Log(
+		"msg", "port variance exceeded",
+		"request_id", correlation.FromContext(ctx),
+		"location", p.Location,
+		"handled", p.Handled,
+		"late", p.Late,
+		"average_variance", p.AverageVariance(),
+	)
+}
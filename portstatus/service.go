@@ -0,0 +1,443 @@
+// Package portstatus provides the use-case of keeping track of port
+// congestion and closures, as advised by port authorities and agents, of
+// the hours ports work, and of how far from plan ports handle cargos.
+package portstatus
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/handling"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// EventHandler provides a means of subscribing to ports deteriorating, e.g.
+// to have operations follow up with the terminal.
+type EventHandler interface {
+	PortVarianceExceeded(context.Context, shipping.PortVariance)
+}
+
+// EventHandlers notifies several event handlers in order.
+type EventHandlers []EventHandler
+
+// PortVarianceExceeded notifies every handler.
+func (hs EventHandlers) PortVarianceExceeded(ctx context.Context, p shipping.PortVariance) {
+	for _, h := range hs {
+		h.PortVarianceExceeded(ctx, p)
+	}
+}
+
+const (
+	// DefaultVarianceThreshold is how much later than planned cargos may be
+	// handled at a port on average before an alert is raised.
+	DefaultVarianceThreshold = 12 * time.Hour
+
+	// DefaultVarianceWindow is how far back the average variance of a port
+	// is taken over.
+	DefaultVarianceWindow = 7 * 24 * time.Hour
+
+	// minVarianceSample is the number of cargos a port must have handled
+	// within the window before alerts are raised, so that a single late
+	// vessel does not set one off.
+	minVarianceSample = 5
+)
+
+// Service is the interface that provides port status methods.
+type Service interface {
+	// Advise registers an advisory for a port, replacing any previous one.
+	Advise(ctx context.Context, s shipping.PortStatus) error
+
+	// Import registers the advisories of a feed and returns how many were
+	// registered. Every line of the feed holds the location, condition,
+	// delay, expiry and reason of an advisory, e.g.
+	//
+	//	CNHKG,congested,36h,2016-04-01T00:00:00Z,Typhoon
+	//
+	// Nothing is registered if any line is invalid.
+	Import(ctx context.Context, r io.Reader) (int, error)
+
+	// Statuses returns the advisories currently in effect.
+	Statuses(ctx context.Context) []Status
+
+	// SetCalendar sets the working hours and public holidays of a port,
+	// replacing any previous ones. The time zone of the port is used unless
+	// the calendar gives one.
+	SetCalendar(ctx context.Context, c shipping.WorkingCalendar) error
+
+	// Calendars returns the working calendars of all ports that have one.
+	Calendars(ctx context.Context) []Calendar
+
+	// RecordVariance records how much later than planned a cargo was loaded
+	// or unloaded. Interested parties are notified once the average variance
+	// of the port exceeds the threshold. Events not planned by the itinerary
+	// of the cargo are ignored.
+	RecordVariance(ctx context.Context, e shipping.HandlingEvent) error
+
+	// Performance reports the average variance of each port within the
+	// window, worst first.
+	Performance(ctx context.Context) []Performance
+}
+
+type service struct {
+	statuses  shipping.PortStatusRepository
+	calendars shipping.WorkingCalendarRepository
+	locations shipping.LocationRepository
+
+	cargos    shipping.CargoRepository
+	variances shipping.HandlingVarianceRepository
+	handler   EventHandler
+	threshold time.Duration
+	window    time.Duration
+}
+
+func (s *service) Advise(ctx context.Context, ps shipping.PortStatus) error {
+	if ps.Location == "" || ps.Delay < 0 {
+		return ErrInvalidArgument
+	}
+
+	if _, err := s.locations.Find(ps.Location); err != nil {
+		return err
+	}
+
+	if ps.Issued.IsZero() {
+		ps.Issued = time.Now()
+	}
+
+	return s.statuses.Store(ps)
+}
+
+func (s *service) Import(ctx context.Context, r io.Reader) (int, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 5
+	cr.TrimLeadingSpace = true
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return 0, ErrInvalidArgument
+	}
+
+	issued := time.Now()
+
+	statuses := make([]shipping.PortStatus, 0, len(records))
+	for _, rec := range records {
+		ps, err := parseRecord(rec)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := s.locations.Find(ps.Location); err != nil {
+			return 0, err
+		}
+		ps.Issued = issued
+		statuses = append(statuses, ps)
+	}
+
+	for i, ps := range statuses {
+		if err := s.statuses.Store(ps); err != nil {
+			return i, err
+		}
+	}
+
+	return len(statuses), nil
+}
+
+func parseRecord(rec []string) (shipping.PortStatus, error) {
+	var (
+		ps  = shipping.PortStatus{Location: shipping.UNLocode(rec[0]), Reason: rec[4]}
+		err error
+	)
+
+	if ps.Location == "" {
+		return ps, ErrInvalidArgument
+	}
+
+	if ps.Condition, err = shipping.ParsePortCondition(rec[1]); err != nil {
+		return ps, ErrInvalidArgument
+	}
+
+	if rec[2] != "" {
+		if ps.Delay, err = time.ParseDuration(rec[2]); err != nil || ps.Delay < 0 {
+			return ps, ErrInvalidArgument
+		}
+	}
+
+	if rec[3] != "" {
+		if ps.Until, err = time.Parse(time.RFC3339, rec[3]); err != nil {
+			return ps, ErrInvalidArgument
+		}
+	}
+
+	return ps, nil
+}
+
+func (s *service) Statuses(ctx context.Context) []Status {
+	now := time.Now()
+
+	var result []Status
+	for _, ps := range s.statuses.FindAll() {
+		if ps.InEffect(now) {
+			result = append(result, assemble(ps))
+		}
+	}
+	return result
+}
+
+func (s *service) SetCalendar(ctx context.Context, c shipping.WorkingCalendar) error {
+	if c.Location == "" {
+		return ErrInvalidArgument
+	}
+
+	loc, err := s.locations.Find(c.Location)
+	if err != nil {
+		return err
+	}
+
+	if c.TimeZone == "" {
+		c.TimeZone = loc.TimeZone
+	}
+
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	return s.calendars.Store(&c)
+}
+
+func (s *service) Calendars(ctx context.Context) []Calendar {
+	result := []Calendar{}
+	for _, c := range s.calendars.FindAll() {
+		result = append(result, assembleCalendar(c))
+	}
+	return result
+}
+
+func (s *service) RecordVariance(ctx context.Context, e shipping.HandlingEvent) error {
+	if s.variances == nil {
+		return nil
+	}
+
+	if e.Activity.Type != shipping.Load && e.Activity.Type != shipping.Unload {
+		return nil
+	}
+
+	c, err := s.cargos.Find(e.TrackingID)
+	if err != nil {
+		return err
+	}
+
+	planned, ok := c.Itinerary.PlannedCompletion(e)
+	if !ok {
+		return nil
+	}
+
+	v := shipping.HandlingVariance{
+		Location:   e.Activity.Location,
+		TrackingID: e.TrackingID,
+		Type:       e.Activity.Type,
+		Planned:    planned,
+		Completed:  e.Completed,
+	}
+
+	since := time.Now().Add(-s.window)
+
+	before := shipping.PortVariance{Location: v.Location}
+	if p, ok := shipping.PortVariances(s.variances.FindSince(since))[v.Location]; ok {
+		before = *p
+	}
+
+	if err := s.variances.Store(v); err != nil {
+		return err
+	}
+
+	if v.Completed.Before(since) {
+		return nil
+	}
+
+	after := before
+	after.Add(v)
+
+	// Alert as the port crosses the threshold, rather than for every cargo
+	// handled while above it.
+	if after.Exceeds(s.threshold, minVarianceSample) && !before.Exceeds(s.threshold, minVarianceSample) && s.handler != nil {
+		s.handler.PortVarianceExceeded(ctx, after)
+	}
+
+	return nil
+}
+
+func (s *service) Performance(ctx context.Context) []Performance {
+	result := []Performance{}
+	if s.variances == nil {
+		return result
+	}
+
+	var ports []*shipping.PortVariance
+	for _, p := range shipping.PortVariances(s.variances.FindSince(time.Now().Add(-s.window))) {
+		ports = append(ports, p)
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if a, b := ports[i].AverageVariance(), ports[j].AverageVariance(); a != b {
+			return a > b
+		}
+		return ports[i].Location < ports[j].Location
+	})
+
+	for _, p := range ports {
+		result = append(result, Performance{
+			Location:        string(p.Location),
+			Handled:         p.Handled,
+			Late:            p.Late,
+			AverageVariance: p.AverageVariance().String(),
+			Alerting:        p.Exceeds(s.threshold, minVarianceSample),
+		})
+	}
+	return result
+}
+
+// Option configures a port status service.
+type Option func(*service)
+
+// WithVariances records how much later than planned the cargos in the
+// repository are handled at each port, notifying the handler of ports
+// exceeding DefaultVarianceThreshold over DefaultVarianceWindow. The handler
+// may be nil.
+func WithVariances(cargos shipping.CargoRepository, variances shipping.HandlingVarianceRepository, handler EventHandler) Option {
+	return func(s *service) {
+		s.cargos = cargos
+		s.variances = variances
+		s.handler = handler
+	}
+}
+
+// WithVarianceThreshold sets the average variance of a port over the window
+// that alerts are raised above.
+func WithVarianceThreshold(threshold, window time.Duration) Option {
+	return func(s *service) {
+		s.threshold = threshold
+		s.window = window
+	}
+}
+
+// NewService creates a port status service with necessary dependencies.
+func NewService(statuses shipping.PortStatusRepository, calendars shipping.WorkingCalendarRepository, locations shipping.LocationRepository, opts ...Option) Service {
+	s := &service{
+		statuses:  statuses,
+		calendars: calendars,
+		locations: locations,
+		threshold: DefaultVarianceThreshold,
+		window:    DefaultVarianceWindow,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Status is a read model for port status views.
+type Status struct {
+	Location  string     `json:"location"`
+	Condition string     `json:"condition"`
+	Delay     string     `json:"delay,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	Issued    time.Time  `json:"issued"`
+	Until     *time.Time `json:"until,omitempty"`
+}
+
+func assemble(ps shipping.PortStatus) Status {
+	s := Status{
+		Location:  string(ps.Location),
+		Condition: strings.ToLower(ps.Condition.String()),
+		Reason:    ps.Reason,
+		Issued:    ps.Issued,
+	}
+	if ps.Delay > 0 {
+		s.Delay = ps.Delay.String()
+	}
+	if !ps.Until.IsZero() {
+		until := ps.Until
+		s.Until = &until
+	}
+	return s
+}
+
+// Calendar is a read model for working calendar views. Hours are given by
+// lower case weekday, and holidays as dates.
+type Calendar struct {
+	Location string             `json:"location"`
+	TimeZone string             `json:"time_zone"`
+	Hours    map[string][]Hours `json:"hours"`
+	Holidays []string           `json:"holidays,omitempty"`
+}
+
+// Hours is a read model for opening hours, in local time such as "08:00".
+// Hours lasting until midnight close at "24:00".
+type Hours struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+func assembleCalendar(c *shipping.WorkingCalendar) Calendar {
+	result := Calendar{
+		Location: string(c.Location),
+		TimeZone: c.TimeZone,
+		Hours:    make(map[string][]Hours),
+	}
+	for day, hours := range c.Hours {
+		for _, h := range hours {
+			name := strings.ToLower(time.Weekday(day).String())
+			result.Hours[name] = append(result.Hours[name], Hours{
+				Open:  formatClock(h.Open),
+				Close: formatClock(h.Close),
+			})
+		}
+	}
+	for _, d := range c.Holidays {
+		result.Holidays = append(result.Holidays, d.Format("2006-01-02"))
+	}
+	return result
+}
+
+// Performance is a read model for port performance views.
+type Performance struct {
+	Location string `json:"location"`
+
+	// Handled is the number of cargos loaded or unloaded within the window,
+	// and Late the number of them handled later than planned.
+	Handled int `json:"handled"`
+	Late    int `json:"late"`
+
+	// AverageVariance is how much later than planned cargos were handled on
+	// average, or negative if earlier.
+	AverageVariance string `json:"average_variance"`
+
+	// Alerting is set if the average variance exceeds the threshold.
+	Alerting bool `json:"alerting"`
+}
+
+// formatClock formats an offset from midnight as a time of day.
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+type handlingEventHandler struct {
+	s Service
+}
+
+func (h *handlingEventHandler) CargoWasHandled(ctx context.Context, e shipping.HandlingEvent) {
+	h.s.RecordVariance(ctx, e)
+}
+
+// NewHandlingEventHandler returns a handler that records how much later than
+// planned cargos are handled as they are.
+func NewHandlingEventHandler(s Service) handling.EventHandler {
+	return &handlingEventHandler{s: s}
+}
@@ -0,0 +1,258 @@
+package portstatus
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+func newLocationRepository() *mock.LocationRepository {
+	var locations mock.LocationRepository
+	locations.FindFn = func(loc shipping.UNLocode) (*shipping.Location, error) {
+		switch loc {
+		case shipping.CNHKG, shipping.SESTO:
+			return &shipping.Location{UNLocode: loc}, nil
+		}
+		return nil, shipping.ErrUnknownLocation
+	}
+	return &locations
+}
+
+func TestAdvise(t *testing.T) {
+	var stored []shipping.PortStatus
+
+	var statuses mock.PortStatusRepository
+	statuses.StoreFn = func(s shipping.PortStatus) error {
+		stored = append(stored, s)
+		return nil
+	}
+
+	s := NewService(&statuses, nil, newLocationRepository())
+
+	if err := s.Advise(context.Background(), shipping.PortStatus{Location: shipping.USNYC, Condition: shipping.PortClosed}); err != shipping.ErrUnknownLocation {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownLocation)
+	}
+
+	if err := s.Advise(context.Background(), shipping.PortStatus{Location: shipping.CNHKG, Condition: shipping.PortClosed}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stored) != 1 {
+		t.Fatalf("len(stored) = %d; want = %d", len(stored), 1)
+	}
+	if stored[0].Issued.IsZero() {
+		t.Errorf("stored[0].Issued should be set")
+	}
+}
+
+func TestImport(t *testing.T) {
+	var stored []shipping.PortStatus
+
+	var statuses mock.PortStatusRepository
+	statuses.StoreFn = func(s shipping.PortStatus) error {
+		stored = append(stored, s)
+		return nil
+	}
+
+	s := NewService(&statuses, nil, newLocationRepository())
+
+	feed := `# location,condition,delay,until,reason
+CNHKG,congested,36h,2016-04-01T00:00:00Z,Typhoon
+SESTO,closed,,,Strike
+`
+
+	n, err := s.Import(context.Background(), strings.NewReader(feed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("n = %d; want = %d", n, 2)
+	}
+
+	want := shipping.PortStatus{
+		Location:  shipping.CNHKG,
+		Condition: shipping.PortCongested,
+		Delay:     36 * time.Hour,
+		Until:     time.Date(2016, time.April, 1, 0, 0, 0, 0, time.UTC),
+		Reason:    "Typhoon",
+	}
+	got := stored[0]
+	got.Issued = time.Time{}
+	if got != want {
+		t.Errorf("stored[0] = %+v; want = %+v", got, want)
+	}
+
+	for _, feed := range []string{
+		"CNHKG,flooded,,,",
+		"CNHKG,congested,soon,,",
+		"CNHKG,congested",
+		"USNYC,closed,,,",
+	} {
+		stored = nil
+		if _, err := s.Import(context.Background(), strings.NewReader("SESTO,closed,,,\n"+feed)); err == nil {
+			t.Errorf("Import(%q) should fail", feed)
+		}
+		if len(stored) != 0 {
+			t.Errorf("Import(%q) stored %d advisories; want none", feed, len(stored))
+		}
+	}
+}
+
+func TestStatuses(t *testing.T) {
+	var statuses mock.PortStatusRepository
+	statuses.FindAllFn = func() []shipping.PortStatus {
+		return []shipping.PortStatus{
+			{Location: shipping.CNHKG, Condition: shipping.PortCongested, Delay: time.Hour},
+			{Location: shipping.SESTO, Condition: shipping.PortClosed, Until: time.Now().Add(-time.Hour)},
+		}
+	}
+
+	s := NewService(&statuses, nil, newLocationRepository())
+
+	got := s.Statuses(context.Background())
+	if len(got) != 1 {
+		t.Fatalf("len(got) = %d; want = %d", len(got), 1)
+	}
+	if got[0].Condition != "congested" || got[0].Delay != "1h0m0s" {
+		t.Errorf("got[0] = %+v", got[0])
+	}
+}
+
+func TestSetCalendar(t *testing.T) {
+	var stored *shipping.WorkingCalendar
+
+	var calendars mock.WorkingCalendarRepository
+	calendars.StoreFn = func(c *shipping.WorkingCalendar) error {
+		stored = c
+		return nil
+	}
+	calendars.FindAllFn = func() []*shipping.WorkingCalendar {
+		return []*shipping.WorkingCalendar{stored}
+	}
+
+	locations := newLocationRepository()
+	locations.FindFn = func(loc shipping.UNLocode) (*shipping.Location, error) {
+		if loc == shipping.SESTO {
+			return &shipping.Location{UNLocode: loc, TimeZone: "Europe/Stockholm"}, nil
+		}
+		return nil, shipping.ErrUnknownLocation
+	}
+
+	s := NewService(nil, &calendars, locations)
+
+	c := shipping.WorkingCalendar{Location: shipping.SESTO}
+	c.Hours[time.Monday] = []shipping.OpeningHours{{Open: 6 * time.Hour, Close: 24 * time.Hour}}
+	c.Holidays = []time.Time{time.Date(2016, time.December, 25, 0, 0, 0, 0, time.UTC)}
+
+	if err := s.SetCalendar(context.Background(), shipping.WorkingCalendar{Location: shipping.SESTO}); err != shipping.ErrInvalidCalendar {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrInvalidCalendar)
+	}
+
+	if err := s.SetCalendar(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if stored.TimeZone != "Europe/Stockholm" {
+		t.Errorf("stored.TimeZone = %q; want the time zone of the location", stored.TimeZone)
+	}
+
+	got := s.Calendars(context.Background())
+	if len(got) != 1 {
+		t.Fatalf("len(got) = %d; want = %d", len(got), 1)
+	}
+	if want := []Hours{{Open: "06:00", Close: "24:00"}}; len(got[0].Hours["monday"]) != 1 || got[0].Hours["monday"][0] != want[0] {
+		t.Errorf("got[0].Hours = %+v; want monday = %+v", got[0].Hours, want)
+	}
+	if len(got[0].Holidays) != 1 || got[0].Holidays[0] != "2016-12-25" {
+		t.Errorf("got[0].Holidays = %v; want = %v", got[0].Holidays, []string{"2016-12-25"})
+	}
+}
+
+type recordingEventHandler struct {
+	alerts []shipping.PortVariance
+}
+
+func (h *recordingEventHandler) PortVarianceExceeded(ctx context.Context, p shipping.PortVariance) {
+	h.alerts = append(h.alerts, p)
+}
+
+func TestRecordVariance(t *testing.T) {
+	t0 := time.Now().Add(-24 * time.Hour)
+
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{Origin: shipping.SESTO, Destination: shipping.CNHKG})
+	c.Itinerary = shipping.Itinerary{Legs: []shipping.Leg{
+		shipping.NewLeg("V100", shipping.SESTO, shipping.CNHKG, t0.Add(-10*24*time.Hour), t0),
+	}}
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return c, nil
+	}
+
+	var stored []shipping.HandlingVariance
+
+	var variances mock.HandlingVarianceRepository
+	variances.StoreFn = func(v shipping.HandlingVariance) error {
+		stored = append(stored, v)
+		return nil
+	}
+	variances.FindSinceFn = func(since time.Time) []shipping.HandlingVariance {
+		var result []shipping.HandlingVariance
+		for _, v := range stored {
+			if !v.Completed.Before(since) {
+				result = append(result, v)
+			}
+		}
+		return result
+	}
+
+	var handler recordingEventHandler
+
+	s := NewService(nil, nil, nil, WithVariances(&cargos, &variances, &handler))
+
+	unload := func(late time.Duration) shipping.HandlingEvent {
+		return shipping.HandlingEvent{
+			TrackingID: c.TrackingID,
+			Activity:   shipping.HandlingActivity{Type: shipping.Unload, Location: shipping.CNHKG, VoyageNumber: "V100"},
+			Completed:  t0.Add(late),
+		}
+	}
+
+	// Events not planned by the itinerary are ignored.
+	if err := s.RecordVariance(context.Background(), shipping.HandlingEvent{
+		TrackingID: c.TrackingID,
+		Activity:   shipping.HandlingActivity{Type: shipping.Receive, Location: shipping.SESTO},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 0 {
+		t.Fatalf("len(stored) = %d; want = %d", len(stored), 0)
+	}
+
+	for i := 0; i < minVarianceSample+2; i++ {
+		if err := s.RecordVariance(context.Background(), unload(DefaultVarianceThreshold+time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if i < minVarianceSample-1 && len(handler.alerts) > 0 {
+			t.Fatalf("alerted after %d cargos; want not before %d", i+1, minVarianceSample)
+		}
+	}
+
+	if len(handler.alerts) != 1 {
+		t.Fatalf("len(alerts) = %d; want = %d", len(handler.alerts), 1)
+	}
+	if a := handler.alerts[0]; a.Location != shipping.CNHKG || a.Handled != minVarianceSample {
+		t.Errorf("alert = %+v", a)
+	}
+
+	got := s.Performance(context.Background())
+	if len(got) != 1 {
+		t.Fatalf("len(performance) = %d; want = %d", len(got), 1)
+	}
+	if p := got[0]; p.Location != "CNHKG" || p.Handled != minVarianceSample+2 || p.Late != p.Handled || !p.Alerting || p.AverageVariance != "13h0m0s" {
+		t.Errorf("performance = %+v", p)
+	}
+}
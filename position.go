@@ -0,0 +1,21 @@
+package shipping
+
+import (
+	"time"
+)
+
+// VesselPosition is a position reported by the vessel sailing a voyage.
+type VesselPosition struct {
+	VoyageNumber VoyageNumber
+	Coordinates  Coordinates
+	Reported     time.Time
+}
+
+// VesselPositionRepository provides access a vessel position store.
+type VesselPositionRepository interface {
+	Store(p VesselPosition) error
+
+	// FindByVoyage returns at most limit of the positions most recently
+	// reported for a voyage, most recent first.
+	FindByVoyage(v VoyageNumber, limit int) []VesselPosition
+}
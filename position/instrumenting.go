@@ -0,0 +1,43 @@
+package position
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) ReportPosition(ctx context.Context, voyage shipping.VoyageNumber, c shipping.Coordinates, at time.Time) (int, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "report_position").Add(1)
+		s.requestLatency.With("method", "report_position").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.ReportPosition(ctx, voyage, c, at)
+}
+
+func (s *instrumentingService) RecordPortCall(ctx context.Context, voyage shipping.VoyageNumber, locode shipping.UNLocode, arrived, departed time.Time) (int, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "record_port_call").Add(1)
+		s.requestLatency.With("method", "record_port_call").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.RecordPortCall(ctx, voyage, locode, arrived, departed)
+}
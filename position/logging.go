@@ -0,0 +1,54 @@
+package position
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) ReportPosition(ctx context.Context, voyage shipping.VoyageNumber, c shipping.Coordinates, at time.Time) (n int, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "report_position",
+			"request_id", correlation.FromContext(ctx),
+			"voyage", voyage,
+			"latitude", c.Latitude,
+			"longitude", c.Longitude,
+			"updated", n,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.ReportPosition(ctx, voyage, c, at)
+}
+
+func (s *loggingService) RecordPortCall(ctx context.Context, voyage shipping.VoyageNumber, locode shipping.UNLocode, arrived, departed time.Time) (n int, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "record_port_call",
+			"request_id", correlation.FromContext(ctx),
+			"voyage", voyage,
+			"location", locode,
+			"arrived", arrived,
+			"departed", departed,
+			"updated", n,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.RecordPortCall(ctx, voyage, locode, arrived, departed)
+}
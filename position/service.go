@@ -0,0 +1,273 @@
+// Package position provides the use-case of tracking the reported positions
+// and actual port calls of vessels, and estimating from them when the cargos
+// onboard will be unloaded.
+package position
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/geo"
+	"github.com/marcusolsson/goddd/inspection"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// speedSamples is how many of the most recent positions of a voyage the
+// average speed is computed over.
+const speedSamples = 10
+
+// DefaultGeofenceRadius is the distance from a port in kilometres within
+// which a vessel is taken to have arrived there.
+const DefaultGeofenceRadius = 10.0
+
+// EventHandler provides a means of subscribing to voyages completing their
+// rotations, e.g. to reconcile the cargos they carried.
+type EventHandler interface {
+	VoyageCompleted(context.Context, *shipping.Voyage)
+}
+
+// Service is the interface that provides position methods.
+type Service interface {
+	// ReportPosition registers the position of the vessel of a voyage at a
+	// time, and updates the estimated unload of the cargos onboard. Cargos
+	// to be unloaded at a port whose geofence the vessel is within are
+	// marked as provisionally arrived, until the unload is reported. It
+	// returns the number of cargos updated.
+	ReportPosition(ctx context.Context, voyage shipping.VoyageNumber, c shipping.Coordinates, at time.Time) (int, error)
+
+	// RecordPortCall records when the vessel of a voyage actually arrived
+	// at and departed from a location, either of which may be zero if not
+	// yet known, and updates the estimated unload of the cargos onboard by
+	// how late the voyage runs. It returns the number of cargos updated.
+	// Interested parties are notified once the voyage arrives at the last
+	// port of its rotation.
+	RecordPortCall(ctx context.Context, voyage shipping.VoyageNumber, locode shipping.UNLocode, arrived, departed time.Time) (int, error)
+}
+
+type service struct {
+	positions shipping.VesselPositionRepository
+	voyages   shipping.VoyageRepository
+	cargos    shipping.CargoRepository
+	locations shipping.LocationRepository
+	lanes     []geo.SeaLane
+	handler   inspection.EventHandler
+	voyage    EventHandler
+	geofence  float64
+}
+
+func (s *service) ReportPosition(ctx context.Context, voyage shipping.VoyageNumber, c shipping.Coordinates, at time.Time) (int, error) {
+	if voyage == "" || c.Latitude < -90 || c.Latitude > 90 || c.Longitude < -180 || c.Longitude > 180 {
+		return 0, ErrInvalidArgument
+	}
+
+	if _, err := s.voyages.Find(voyage); err != nil {
+		return 0, err
+	}
+
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	if err := s.positions.Store(shipping.VesselPosition{VoyageNumber: voyage, Coordinates: c, Reported: at}); err != nil {
+		return 0, err
+	}
+
+	speed := averageSpeed(s.positions.FindByVoyage(voyage, speedSamples))
+
+	return s.updateEstimates(ctx, voyage, func(l shipping.Leg, d shipping.Delivery) (shipping.Delivery, bool) {
+		unload, ok := s.estimateUnload(l, c, at, speed)
+		if !ok {
+			return d, false
+		}
+		d = d.UpdateOnPosition(unload)
+		if s.withinGeofence(l.UnloadLocation, c) {
+			d = d.UpdateOnGeofence(at)
+		}
+		return d, true
+	})
+}
+
+func (s *service) RecordPortCall(ctx context.Context, voyage shipping.VoyageNumber, locode shipping.UNLocode, arrived, departed time.Time) (int, error) {
+	if voyage == "" || locode == "" || (arrived.IsZero() && departed.IsZero()) {
+		return 0, ErrInvalidArgument
+	}
+	if !arrived.IsZero() && !departed.IsZero() && departed.Before(arrived) {
+		return 0, ErrInvalidArgument
+	}
+
+	v, err := s.voyages.Find(voyage)
+	if err != nil {
+		return 0, err
+	}
+
+	schedule, err := v.Schedule.RecordActuals(locode, arrived, departed)
+	if err != nil {
+		return 0, err
+	}
+	completed := schedule.IsCompleted() && !v.Schedule.IsCompleted()
+	v.Schedule = schedule
+
+	if err := s.voyages.Store(v); err != nil {
+		return 0, err
+	}
+
+	delay := schedule.Delay()
+
+	n, err := s.updateEstimates(ctx, voyage, func(l shipping.Leg, d shipping.Delivery) (shipping.Delivery, bool) {
+		if l.UnloadTime.IsZero() {
+			return d, false
+		}
+		for _, m := range schedule.CarrierMovements {
+			if m.ArrivalLocation == l.UnloadLocation && m.ArrivalTime.Equal(l.UnloadTime) && !m.ActualArrivalTime.IsZero() {
+				return d.UpdateOnPosition(m.ActualArrivalTime), true
+			}
+		}
+		return d.UpdateOnPosition(l.UnloadTime.Add(delay)), true
+	})
+	if err != nil {
+		return n, err
+	}
+
+	if completed && s.voyage != nil {
+		s.voyage.VoyageCompleted(ctx, v)
+	}
+
+	return n, nil
+}
+
+// updateEstimates updates the deliveries of the cargos onboard a voyage
+// from the leg they are on, and reports the cargos newly projected to miss
+// their arrival deadline. It returns the number of cargos updated.
+func (s *service) updateEstimates(ctx context.Context, voyage shipping.VoyageNumber, update func(shipping.Leg, shipping.Delivery) (shipping.Delivery, bool)) (int, error) {
+	var updated int
+	err := s.cargos.ForEach(func(cargo *shipping.Cargo) error {
+		l, ok := cargo.Delivery.CurrentLeg()
+		if !ok || l.VoyageNumber != voyage {
+			return nil
+		}
+
+		d, ok := update(l, cargo.Delivery)
+		if !ok {
+			return nil
+		}
+
+		wasAtRisk := cargo.Delivery.IsDeadlineAtRisk()
+
+		cargo.Delivery = d
+		if err := s.cargos.Store(cargo); err != nil {
+			return err
+		}
+		updated++
+
+		if slip := cargo.Delivery.DeadlineSlip(); slip > 0 && !wasAtRisk {
+			s.handler.CargoDeadlineAtRisk(ctx, cargo, slip)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return updated, err
+	}
+
+	return updated, nil
+}
+
+// withinGeofence returns whether a position is within the geofence of a
+// location.
+func (s *service) withinGeofence(locode shipping.UNLocode, c shipping.Coordinates) bool {
+	l, err := s.locations.Find(locode)
+	if err != nil {
+		return false
+	}
+	return geo.GreatCircle(c, l.Coordinates) <= s.geofence
+}
+
+// estimateUnload returns when a vessel at a position is estimated to reach
+// the unload location of a leg, sailing at the given speed in kilometres per
+// hour, or else at the speed the leg was scheduled at.
+func (s *service) estimateUnload(l shipping.Leg, c shipping.Coordinates, at time.Time, speed float64) (time.Time, bool) {
+	to, err := s.locations.Find(l.UnloadLocation)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	if speed <= 0 {
+		from, err := s.locations.Find(l.LoadLocation)
+		if err != nil {
+			return time.Time{}, false
+		}
+		hours := l.UnloadTime.Sub(l.LoadTime).Hours()
+		if hours <= 0 {
+			return time.Time{}, false
+		}
+		speed = geo.Distance(from.Coordinates, to.Coordinates, s.lanes) / hours
+	}
+	if speed <= 0 {
+		return time.Time{}, false
+	}
+
+	remaining := geo.Distance(c, to.Coordinates, s.lanes)
+
+	return at.Add(time.Duration(remaining / speed * float64(time.Hour))), true
+}
+
+// averageSpeed returns the average speed in kilometres per hour over
+// positions given most recent first, or zero if it cannot be told.
+func averageSpeed(ps []shipping.VesselPosition) float64 {
+	if len(ps) < 2 {
+		return 0
+	}
+
+	hours := ps[0].Reported.Sub(ps[len(ps)-1].Reported).Hours()
+	if hours <= 0 {
+		return 0
+	}
+
+	cs := make([]shipping.Coordinates, len(ps))
+	for i, p := range ps {
+		cs[i] = p.Coordinates
+	}
+
+	return geo.Path(cs...) / hours
+}
+
+// Option configures the position service.
+type Option func(*service)
+
+// WithGeofence sets the distance from a port in kilometres within which a
+// vessel is taken to have arrived there. Defaults to DefaultGeofenceRadius.
+func WithGeofence(radius float64) Option {
+	return func(s *service) {
+		s.geofence = radius
+	}
+}
+
+// WithEventHandler notifies the handler of voyages completing their
+// rotations.
+func WithEventHandler(h EventHandler) Option {
+	return func(s *service) {
+		s.voyage = h
+	}
+}
+
+// NewService creates a position service with necessary dependencies. Sea
+// lanes are used to tell the remaining distance to the unload location.
+func NewService(positions shipping.VesselPositionRepository, voyages shipping.VoyageRepository, cargos shipping.CargoRepository, locations shipping.LocationRepository, lanes []geo.SeaLane, handler inspection.EventHandler, opts ...Option) Service {
+	s := &service{
+		positions: positions,
+		voyages:   voyages,
+		cargos:    cargos,
+		locations: locations,
+		lanes:     lanes,
+		handler:   handler,
+		geofence:  DefaultGeofenceRadius,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
@@ -0,0 +1,241 @@
+package position
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+type stubEventHandler struct {
+	atRisk []shipping.TrackingID
+}
+
+func (h *stubEventHandler) CargoWasMisdirected(ctx context.Context, c *shipping.Cargo) {}
+func (h *stubEventHandler) CargoHasArrived(ctx context.Context, c *shipping.Cargo)     {}
+func (h *stubEventHandler) CargoDeadlineAtRisk(ctx context.Context, c *shipping.Cargo, slip time.Duration) {
+	h.atRisk = append(h.atRisk, c.TrackingID)
+}
+
+type stubVoyageEventHandler struct {
+	completed []shipping.VoyageNumber
+}
+
+func (h *stubVoyageEventHandler) VoyageCompleted(ctx context.Context, v *shipping.Voyage) {
+	h.completed = append(h.completed, v.VoyageNumber)
+}
+
+func TestReportPosition(t *testing.T) {
+	var (
+		depart  = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		arrival = depart.Add(10 * 24 * time.Hour)
+	)
+
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{
+		Origin:          shipping.USNYC,
+		Destination:     shipping.SESTO,
+		ArrivalDeadline: arrival.Add(24 * time.Hour),
+	})
+	c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+		shipping.NewLeg("V100", shipping.USNYC, shipping.SESTO, depart, arrival),
+	}})
+	c.DeriveDeliveryProgress(shipping.HandlingHistory{HandlingEvents: []shipping.HandlingEvent{
+		{Activity: shipping.HandlingActivity{Type: shipping.Load, Location: shipping.USNYC, VoyageNumber: "V100"}, Completed: depart},
+	}})
+
+	var cargos mock.CargoRepository
+	cargos.StoreFn = func(*shipping.Cargo) error { return nil }
+	cargos.ForEachFn = func(fn func(*shipping.Cargo) error) error {
+		return fn(c)
+	}
+
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(v shipping.VoyageNumber) (*shipping.Voyage, error) {
+		if v == "V100" {
+			return &shipping.Voyage{VoyageNumber: v}, nil
+		}
+		return nil, shipping.ErrUnknownVoyage
+	}
+
+	var locations mock.LocationRepository
+	locations.FindFn = func(loc shipping.UNLocode) (*shipping.Location, error) {
+		switch loc {
+		case shipping.USNYC:
+			return shipping.NewYork, nil
+		case shipping.SESTO:
+			return shipping.Stockholm, nil
+		}
+		return nil, shipping.ErrUnknownLocation
+	}
+
+	var stored []shipping.VesselPosition
+	var positions mock.VesselPositionRepository
+	positions.StoreFn = func(p shipping.VesselPosition) error {
+		stored = append([]shipping.VesselPosition{p}, stored...)
+		return nil
+	}
+	positions.FindByVoyageFn = func(shipping.VoyageNumber, int) []shipping.VesselPosition {
+		return stored
+	}
+
+	var handler stubEventHandler
+
+	s := NewService(&positions, &voyages, &cargos, &locations, nil, &handler)
+
+	ctx := context.Background()
+
+	if _, err := s.ReportPosition(ctx, "V100", shipping.Coordinates{Latitude: 91}, depart); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+	if _, err := s.ReportPosition(ctx, "V999", shipping.Coordinates{}, depart); err != shipping.ErrUnknownVoyage {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownVoyage)
+	}
+
+	// Halfway at the scheduled speed, the cargo is on time.
+	halfway := shipping.Coordinates{Latitude: 54, Longitude: -30}
+	n, err := s.ReportPosition(ctx, "V100", halfway, depart.Add(5*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("n = %d; want = %d", n, 1)
+	}
+	if c.Delivery.EstimatedUnload.IsZero() {
+		t.Fatalf("EstimatedUnload should be set")
+	}
+	if len(handler.atRisk) != 0 {
+		t.Errorf("atRisk = %v; want none", handler.atRisk)
+	}
+
+	// Having barely moved for days, the vessel is slower than scheduled.
+	for _, day := range []int{6, 7} {
+		if _, err := s.ReportPosition(ctx, "V100", shipping.Coordinates{Latitude: 54, Longitude: -29.9}, depart.Add(time.Duration(day)*24*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !c.Delivery.IsDeadlineAtRisk() {
+		t.Errorf("deadline should be at risk; EstimatedUnload = %v", c.Delivery.EstimatedUnload)
+	}
+	if len(handler.atRisk) != 1 {
+		t.Errorf("len(atRisk) = %d; want the cargo reported once", len(handler.atRisk))
+	}
+	if c.Delivery.IsProvisionallyArrived() {
+		t.Errorf("cargo should not have arrived mid-ocean")
+	}
+
+	// Entering the geofence of Stockholm, the cargo has arrived until the
+	// unload is reported.
+	near := shipping.Stockholm.Coordinates
+	near.Latitude += 0.05
+	entered := depart.Add(12 * 24 * time.Hour)
+	if _, err := s.ReportPosition(ctx, "V100", near, entered); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Delivery.IsProvisionallyArrived() || !c.Delivery.ProvisionalArrival.Equal(entered) {
+		t.Errorf("ProvisionalArrival = %v; want = %v", c.Delivery.ProvisionalArrival, entered)
+	}
+}
+
+func TestRecordPortCall(t *testing.T) {
+	var (
+		depart  = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		arrival = depart.Add(10 * 24 * time.Hour)
+	)
+
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{
+		Origin:          shipping.USNYC,
+		Destination:     shipping.SESTO,
+		ArrivalDeadline: arrival.Add(24 * time.Hour),
+	})
+	c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+		shipping.NewLeg("V100", shipping.USNYC, shipping.SESTO, depart, arrival),
+	}})
+	c.DeriveDeliveryProgress(shipping.HandlingHistory{HandlingEvents: []shipping.HandlingEvent{
+		{Activity: shipping.HandlingActivity{Type: shipping.Load, Location: shipping.USNYC, VoyageNumber: "V100"}, Completed: depart},
+	}})
+
+	var cargos mock.CargoRepository
+	cargos.StoreFn = func(*shipping.Cargo) error { return nil }
+	cargos.ForEachFn = func(fn func(*shipping.Cargo) error) error {
+		return fn(c)
+	}
+
+	v := shipping.NewVoyage("V100", shipping.Schedule{CarrierMovements: []shipping.CarrierMovement{
+		{DepartureLocation: shipping.USNYC, ArrivalLocation: shipping.SESTO, DepartureTime: depart, ArrivalTime: arrival},
+	}})
+
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(n shipping.VoyageNumber) (*shipping.Voyage, error) {
+		if n == "V100" {
+			return v, nil
+		}
+		return nil, shipping.ErrUnknownVoyage
+	}
+	voyages.StoreFn = func(*shipping.Voyage) error { return nil }
+
+	var (
+		handler       stubEventHandler
+		voyageHandler stubVoyageEventHandler
+	)
+
+	s := NewService(nil, &voyages, &cargos, nil, nil, &handler, WithEventHandler(&voyageHandler))
+
+	ctx := context.Background()
+
+	if _, err := s.RecordPortCall(ctx, "V100", shipping.USNYC, time.Time{}, time.Time{}); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+	if _, err := s.RecordPortCall(ctx, "V100", shipping.USNYC, depart, depart.Add(-time.Hour)); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+	if _, err := s.RecordPortCall(ctx, "V100", shipping.CNHKG, depart, time.Time{}); err != shipping.ErrPortNotCalled {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrPortNotCalled)
+	}
+
+	// Departing two days late pushes the unload past the deadline.
+	n, err := s.RecordPortCall(ctx, "V100", shipping.USNYC, time.Time{}, depart.Add(48*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("n = %d; want = %d", n, 1)
+	}
+	if want := arrival.Add(48 * time.Hour); !c.Delivery.EstimatedUnload.Equal(want) {
+		t.Errorf("EstimatedUnload = %v; want = %v", c.Delivery.EstimatedUnload, want)
+	}
+	if !voyages.StoreInvoked || v.Schedule.CarrierMovements[0].ActualDepartureTime.IsZero() {
+		t.Errorf("the actual departure should be stored with the voyage")
+	}
+	if len(handler.atRisk) != 1 {
+		t.Errorf("len(atRisk) = %d; want the cargo reported once", len(handler.atRisk))
+	}
+	if len(voyageHandler.completed) != 0 {
+		t.Errorf("voyage completed on departure")
+	}
+
+	// Having made up for lost time, the vessel arrives as recorded.
+	actual := arrival.Add(12 * time.Hour)
+	if _, err := s.RecordPortCall(ctx, "V100", shipping.SESTO, actual, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Delivery.EstimatedUnload.Equal(actual) {
+		t.Errorf("EstimatedUnload = %v; want = %v", c.Delivery.EstimatedUnload, actual)
+	}
+	if c.Delivery.IsDeadlineAtRisk() {
+		t.Errorf("deadline should no longer be at risk")
+	}
+
+	if len(voyageHandler.completed) != 1 || voyageHandler.completed[0] != "V100" {
+		t.Errorf("completed = %v; want = [V100]", voyageHandler.completed)
+	}
+
+	// Recording the arrival again does not complete the voyage twice.
+	if _, err := s.RecordPortCall(ctx, "V100", shipping.SESTO, actual, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(voyageHandler.completed) != 1 || voyageHandler.completed[0] != "V100" {
+		t.Errorf("completed = %v; want = [V100]", voyageHandler.completed)
+	}
+}
@@ -0,0 +1,43 @@
+package privacy
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) Export(ctx context.Context, by Credentials, id shipping.CustomerID) (Archive, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "export").Add(1)
+		s.requestLatency.With("method", "export").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Export(ctx, by, id)
+}
+
+func (s *instrumentingService) Anonymize(ctx context.Context, by Credentials, id shipping.CustomerID) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "anonymize").Add(1)
+		s.requestLatency.With("method", "anonymize").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Anonymize(ctx, by, id)
+}
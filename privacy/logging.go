@@ -0,0 +1,51 @@
+package privacy
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service. The personal
+// data being exported is never logged.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) Export(ctx context.Context, by Credentials, id shipping.CustomerID) (archive Archive, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "export",
+			"request_id", correlation.FromContext(ctx),
+			"user", by.User,
+			"customer_id", id,
+			"cargos", len(archive.Cargos),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Export(ctx, by, id)
+}
+
+func (s *loggingService) Anonymize(ctx context.Context, by Credentials, id shipping.CustomerID) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "anonymize",
+			"request_id", correlation.FromContext(ctx),
+			"user", by.User,
+			"customer_id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Anonymize(ctx, by, id)
+}
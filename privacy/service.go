@@ -0,0 +1,354 @@
+// Package privacy provides the use-cases of serving the data protection
+// rights of customers: giving them a copy of everything held about them, and
+// erasing their personal data. Used by views facing the back office.
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// Credentials identify and authenticate the back-office user handling a
+// request from a customer.
+type Credentials struct {
+	User shipping.UserID
+	Key  string
+}
+
+// Service is the interface that provides privacy methods. Only users with the
+// data protection role may use it.
+type Service interface {
+	// Export gathers everything associated with a customer: its details and
+	// notification settings, the cargos it has booked, their handling
+	// events and documents, and the audit log entries about them.
+	Export(ctx context.Context, by Credentials, id shipping.CustomerID) (Archive, error)
+
+	// Anonymize scrubs the personal data of a customer. Its cargos, their
+	// handling events and the audit log are kept, so that operational
+	// statistics are unaffected.
+	Anonymize(ctx context.Context, by Credentials, id shipping.CustomerID) error
+}
+
+type service struct {
+	customers      shipping.CustomerRepository
+	cargos         shipping.CargoRepository
+	handlingEvents shipping.HandlingEventRepository
+	auditEntries   shipping.AuditRepository
+	users          shipping.UserRepository
+}
+
+func (s *service) Export(ctx context.Context, by Credentials, id shipping.CustomerID) (Archive, error) {
+	if id == "" {
+		return Archive{}, ErrInvalidArgument
+	}
+
+	if err := s.authorize(by); err != nil {
+		return Archive{}, err
+	}
+
+	c, err := s.customers.Find(id)
+	if err != nil {
+		return Archive{}, err
+	}
+
+	cargos, err := s.cargosOf(id)
+	if err != nil {
+		return Archive{}, err
+	}
+
+	ids := make([]shipping.TrackingID, len(cargos))
+	for i, c := range cargos {
+		ids[i] = c.TrackingID
+	}
+	histories := s.handlingEvents.QueryHandlingHistories(ids)
+
+	archive := Archive{
+		Exported: time.Now(),
+		Customer: assembleCustomer(c),
+		Cargos:   make([]Cargo, 0, len(cargos)),
+	}
+	for _, c := range cargos {
+		archive.Cargos = append(archive.Cargos, assembleCargo(c, histories[c.TrackingID].HandlingEvents))
+	}
+	archive.AuditEntries = s.auditEntriesOf(id, ids)
+
+	return archive, nil
+}
+
+func (s *service) Anonymize(ctx context.Context, by Credentials, id shipping.CustomerID) error {
+	if id == "" {
+		return ErrInvalidArgument
+	}
+
+	if err := s.authorize(by); err != nil {
+		return err
+	}
+
+	c, err := s.customers.Find(id)
+	if err != nil {
+		return err
+	}
+
+	cargos, err := s.cargosOf(id)
+	if err != nil {
+		return err
+	}
+
+	// The reasons for screening decisions may quote the name of the
+	// customer. The decisions themselves are kept.
+	for _, cargo := range cargos {
+		if len(cargo.Screening.Reasons) == 0 {
+			continue
+		}
+		cargo.Screening.Reasons = nil
+		if err := s.cargos.Store(cargo); err != nil {
+			return err
+		}
+	}
+
+	c.Anonymize(time.Now())
+
+	return s.customers.Store(c)
+}
+
+// cargosOf returns the cargos booked by, or transferred to, a customer.
+func (s *service) cargosOf(id shipping.CustomerID) ([]*shipping.Cargo, error) {
+	return s.cargos.FindMatching(shipping.CargoQuery{
+		Conditions: []shipping.CargoCondition{{
+			Field:  shipping.FieldCustomer,
+			Op:     shipping.OpEqual,
+			Values: []interface{}{id},
+		}},
+	})
+}
+
+// auditEntriesOf returns the audit log entries about the cargos of a
+// customer, or made by the customer itself, ordered by sequence number.
+func (s *service) auditEntriesOf(id shipping.CustomerID, cargos []shipping.TrackingID) []AuditEntry {
+	seen := make(map[uint64]bool)
+
+	var entries []*shipping.AuditEntry
+	add := func(es []*shipping.AuditEntry) {
+		for _, e := range es {
+			if !seen[e.Sequence] {
+				seen[e.Sequence] = true
+				entries = append(entries, e)
+			}
+		}
+	}
+
+	add(s.auditEntries.Find(shipping.AuditQuery{Actor: string(id)}))
+	for _, tid := range cargos {
+		add(s.auditEntries.Find(shipping.AuditQuery{TrackingID: tid}))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Sequence < entries[j].Sequence
+	})
+
+	result := make([]AuditEntry, len(entries))
+	for i, e := range entries {
+		result[i] = AuditEntry{
+			Sequence:   e.Sequence,
+			Time:       e.Time,
+			Actor:      e.Actor,
+			Action:     string(e.Action),
+			TrackingID: string(e.TrackingID),
+			Detail:     e.Detail,
+		}
+	}
+	return result
+}
+
+// authorize checks that the user is registered, authenticates and has been
+// granted the data protection role.
+func (s *service) authorize(by Credentials) error {
+	if by.User == "" {
+		return shipping.ErrUnauthorizedUser
+	}
+
+	u, err := s.users.Find(by.User)
+	if err == shipping.ErrUnknownUser {
+		return shipping.ErrUnauthorizedUser
+	}
+	if err != nil {
+		return err
+	}
+
+	if !u.Authenticate(by.Key) {
+		return shipping.ErrUnauthorizedUser
+	}
+
+	if !u.HasRole(shipping.DataProtectionRole) {
+		return shipping.ErrRoleRequired
+	}
+
+	return nil
+}
+
+// NewService creates a privacy service with necessary dependencies.
+func NewService(customers shipping.CustomerRepository, cargos shipping.CargoRepository, handlingEvents shipping.HandlingEventRepository, auditEntries shipping.AuditRepository, users shipping.UserRepository) Service {
+	return &service{
+		customers:      customers,
+		cargos:         cargos,
+		handlingEvents: handlingEvents,
+		auditEntries:   auditEntries,
+		users:          users,
+	}
+}
+
+// Archive holds everything associated with a customer at the time it was
+// exported. Notifications are sent as they happen and not kept, so only the
+// settings controlling them are included.
+type Archive struct {
+	Exported     time.Time    `json:"exported"`
+	Customer     Customer     `json:"customer"`
+	Cargos       []Cargo      `json:"cargos"`
+	AuditEntries []AuditEntry `json:"audit_entries"`
+}
+
+// Customer is a read model of the details of a customer.
+type Customer struct {
+	ID            string              `json:"id"`
+	Name          string              `json:"name,omitempty"`
+	Email         string              `json:"email,omitempty"`
+	Phone         string              `json:"phone,omitempty"`
+	Notifications map[string][]string `json:"notifications,omitempty"`
+	Digest        string              `json:"digest,omitempty"`
+	Throttling    map[string]string   `json:"throttling,omitempty"`
+	Anonymized    *time.Time          `json:"anonymized,omitempty"`
+}
+
+// Cargo is a read model of a cargo booked by a customer.
+type Cargo struct {
+	TrackingID      string    `json:"tracking_id"`
+	Origin          string    `json:"origin"`
+	Destination     string    `json:"destination"`
+	ArrivalDeadline time.Time `json:"arrival_deadline"`
+	Equipment       string    `json:"equipment,omitempty"`
+	TransportStatus string    `json:"transport_status"`
+	Commodities     []string  `json:"commodities,omitempty"`
+	Documents       []string  `json:"documents,omitempty"`
+	Screening       string    `json:"screening,omitempty"`
+	Events          []Event   `json:"events"`
+}
+
+// Event is a read model of a handling event of a cargo.
+type Event struct {
+	Type         string    `json:"type"`
+	Location     string    `json:"location"`
+	VoyageNumber string    `json:"voyage_number,omitempty"`
+	Completed    time.Time `json:"completed"`
+	Registered   time.Time `json:"registered"`
+}
+
+// AuditEntry is a read model of an audit log entry.
+type AuditEntry struct {
+	Sequence   uint64    `json:"seq"`
+	Time       time.Time `json:"time"`
+	Actor      string    `json:"actor,omitempty"`
+	Action     string    `json:"action"`
+	TrackingID string    `json:"tracking_id,omitempty"`
+	Detail     string    `json:"detail,omitempty"`
+}
+
+// WriteZip writes the archive as a zip file, holding one JSON document each
+// for the customer, its cargos and the audit log entries.
+func (a Archive) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		v    interface{}
+	}{
+		{"customer.json", a.Customer},
+		{"cargos.json", a.Cargos},
+		{"audit.json", a.AuditEntries},
+	}
+
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     f.name,
+			Method:   zip.Deflate,
+			Modified: a.Exported,
+		})
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.v); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func assembleCustomer(c *shipping.Customer) Customer {
+	result := Customer{
+		ID:     string(c.ID),
+		Name:   c.Name,
+		Email:  c.Contact.Email,
+		Phone:  c.Contact.Phone,
+		Digest: string(c.Digest),
+	}
+	if len(c.Notifications) > 0 {
+		result.Notifications = make(map[string][]string)
+		for t, channels := range c.Notifications {
+			for _, ch := range channels {
+				result.Notifications[string(t)] = append(result.Notifications[string(t)], string(ch))
+			}
+		}
+	}
+	if len(c.Throttling) > 0 {
+		result.Throttling = make(map[string]string)
+		for t, d := range c.Throttling {
+			result.Throttling[string(t)] = d.String()
+		}
+	}
+	if c.IsAnonymized() {
+		t := c.Anonymized
+		result.Anonymized = &t
+	}
+	return result
+}
+
+func assembleCargo(c *shipping.Cargo, events []shipping.HandlingEvent) Cargo {
+	result := Cargo{
+		TrackingID:      string(c.TrackingID),
+		Origin:          string(c.Origin),
+		Destination:     string(c.RouteSpecification.Destination),
+		ArrivalDeadline: c.RouteSpecification.ArrivalDeadline,
+		Equipment:       c.Equipment.String(),
+		TransportStatus: c.Delivery.TransportStatus.String(),
+		Screening:       c.Screening.String(),
+		Events:          make([]Event, len(events)),
+	}
+	for _, code := range c.Declaration.Commodities {
+		result.Commodities = append(result.Commodities, string(code))
+	}
+	for _, doc := range c.Declaration.Documents {
+		result.Documents = append(result.Documents, string(doc))
+	}
+	for i, e := range events {
+		result.Events[i] = Event{
+			Type:         e.Activity.Type.String(),
+			Location:     string(e.Activity.Location),
+			VoyageNumber: string(e.Activity.VoyageNumber),
+			Completed:    e.Completed,
+			Registered:   e.Registered,
+		}
+	}
+	return result
+}
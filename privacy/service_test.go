@@ -0,0 +1,148 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/inmem"
+)
+
+var officer = Credentials{User: "dpo", Key: "secret"}
+
+type fixture struct {
+	customers    shipping.CustomerRepository
+	cargos       shipping.CargoRepository
+	auditEntries shipping.AuditRepository
+	s            Service
+}
+
+func newFixture(t *testing.T) fixture {
+	var (
+		customers      = inmem.NewCustomerRepository()
+		cargos         = inmem.NewCargoRepository()
+		handlingEvents = inmem.NewHandlingEventRepository()
+		auditEntries   = inmem.NewAuditRepository()
+		users          = inmem.NewUserRepository()
+	)
+
+	users.Store(&shipping.User{ID: "dpo", KeyHash: shipping.HashUserKey("secret"), Roles: []shipping.Role{shipping.DataProtectionRole}})
+	users.Store(&shipping.User{ID: "jdoe", KeyHash: shipping.HashUserKey("secret")})
+
+	customers.Store(&shipping.Customer{
+		ID:            "ACME",
+		Name:          "ACME Ltd",
+		KeyHash:       shipping.HashCustomerKey("acme"),
+		Contact:       shipping.ContactDetails{Email: "ops@acme.example", Phone: "+46 8 123 45"},
+		Notifications: shipping.NotificationPreferences{shipping.CargoArrivedNotification: {shipping.EmailChannel}},
+		Digest:        shipping.DailyDigest,
+	})
+
+	c := shipping.NewCargo("ABC123", shipping.RouteSpecification{Origin: shipping.CNHKG, Destination: shipping.SESTO})
+	c.Customer = "ACME"
+	c.Declaration.Documents = []shipping.DocumentType{"certificate_of_origin"}
+	c.Screening = shipping.ScreeningResult{Decision: shipping.ScreeningFlagged, Reasons: []string{"ACME Ltd resembles a denied party"}}
+	cargos.Store(c)
+	cargos.Store(shipping.NewCargo("XYZ789", shipping.RouteSpecification{Origin: shipping.CNHKG, Destination: shipping.SESTO}))
+
+	handlingEvents.Store(shipping.HandlingEvent{
+		TrackingID: "ABC123",
+		Activity:   shipping.HandlingActivity{Type: shipping.Receive, Location: shipping.CNHKG},
+		Completed:  time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	for i, e := range []shipping.AuditEntry{
+		{Action: shipping.AuditCargoBooked, TrackingID: "ABC123"},
+		{Action: shipping.AuditCargoBooked, TrackingID: "XYZ789"},
+		{Action: shipping.AuditCargoTransferred, TrackingID: "ABC123", Actor: "ACME"},
+	} {
+		e := e
+		e.Sequence = uint64(i + 1)
+		if err := auditEntries.Append(&e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return fixture{
+		customers:    customers,
+		cargos:       cargos,
+		auditEntries: auditEntries,
+		s:            NewService(customers, cargos, handlingEvents, auditEntries, users),
+	}
+}
+
+func TestExport(t *testing.T) {
+	f := newFixture(t)
+
+	archive, err := f.s.Export(context.Background(), officer, "ACME")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if archive.Customer.Email != "ops@acme.example" || archive.Customer.Digest != "daily" {
+		t.Errorf("customer = %+v", archive.Customer)
+	}
+	if len(archive.Cargos) != 1 {
+		t.Fatalf("len(cargos) = %d; want = %d", len(archive.Cargos), 1)
+	}
+	if c := archive.Cargos[0]; c.TrackingID != "ABC123" || len(c.Events) != 1 || len(c.Documents) != 1 {
+		t.Errorf("cargo = %+v", c)
+	}
+	if len(archive.AuditEntries) != 2 || archive.AuditEntries[0].Sequence != 1 || archive.AuditEntries[1].Sequence != 3 {
+		t.Errorf("audit entries = %+v", archive.AuditEntries)
+	}
+
+	var buf bytes.Buffer
+	if err := archive.WriteZip(&buf); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zr.File) != 3 {
+		t.Errorf("len(files) = %d; want = %d", len(zr.File), 3)
+	}
+}
+
+func TestExport_RequiresRole(t *testing.T) {
+	f := newFixture(t)
+
+	if _, err := f.s.Export(context.Background(), Credentials{User: "jdoe", Key: "secret"}, "ACME"); err != shipping.ErrRoleRequired {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrRoleRequired)
+	}
+	if _, err := f.s.Export(context.Background(), Credentials{User: "dpo", Key: "wrong"}, "ACME"); err != shipping.ErrUnauthorizedUser {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnauthorizedUser)
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	f := newFixture(t)
+
+	if err := f.s.Anonymize(context.Background(), officer, "ACME"); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := f.customers.Find("ACME")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.IsAnonymized() || c.Name != "" || c.Contact != (shipping.ContactDetails{}) || c.Authenticate("acme") || len(c.Notifications) != 0 {
+		t.Errorf("customer = %+v; want personal data scrubbed", c)
+	}
+
+	cargo, err := f.cargos.Find("ABC123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cargo.Customer != "ACME" || cargo.Screening.Decision != shipping.ScreeningFlagged || len(cargo.Screening.Reasons) != 0 {
+		t.Errorf("cargo = %v, %v; want customer and decision kept, reasons scrubbed", cargo.Customer, cargo.Screening)
+	}
+
+	if n := len(f.auditEntries.Find(shipping.AuditQuery{})); n != 3 {
+		t.Errorf("len(audit entries) = %d; want = %d", n, 3)
+	}
+}
@@ -0,0 +1,148 @@
+package shipping
+
+import (
+	"time"
+)
+
+// Progress is how far a cargo has come along its itinerary.
+type Progress struct {
+	// CompletedLegs is the number of legs the cargo has been unloaded from.
+	CompletedLegs int
+	TotalLegs     int
+
+	// Percent is the share of the journey completed, from 0 to 100. The leg
+	// the cargo is onboard counts by the share of its planned time elapsed.
+	Percent float64
+
+	// Milestones are the departures and arrivals of the itinerary, in order.
+	Milestones []Milestone
+}
+
+// Milestone is a planned departure or arrival along an itinerary.
+type Milestone struct {
+	Activity HandlingActivity
+	Planned  time.Time
+	Reached  bool
+}
+
+// Progress returns the progress of the delivery along its itinerary as of
+// now. A cargo handled out of its itinerary has made no progress until it
+// is rerouted.
+func (d Delivery) Progress(now time.Time) Progress {
+	legs := d.Itinerary.Legs
+
+	p := Progress{TotalLegs: len(legs)}
+	if len(legs) == 0 {
+		return p
+	}
+
+	var (
+		reached = d.reachedMilestones()
+		partial float64
+	)
+
+	p.CompletedLegs = reached / 2
+
+	// The cargo is onboard the leg following the last completed one.
+	if reached%2 == 1 {
+		l := legs[p.CompletedLegs]
+
+		start := l.LoadTime
+		if !d.LastEvent.Completed.IsZero() {
+			start = d.LastEvent.Completed
+		}
+
+		if planned := l.UnloadTime.Sub(l.LoadTime); planned > 0 {
+			partial = float64(now.Sub(start)) / float64(planned)
+		}
+		if partial < 0 {
+			partial = 0
+		}
+		if partial > 1 {
+			partial = 1
+		}
+	}
+
+	p.Percent = 100 * (float64(p.CompletedLegs) + partial) / float64(len(legs))
+
+	for i, l := range legs {
+		p.Milestones = append(p.Milestones,
+			Milestone{
+				Activity: HandlingActivity{Type: Load, Location: l.LoadLocation, VoyageNumber: l.VoyageNumber},
+				Planned:  l.LoadTime,
+				Reached:  2*i < reached,
+			},
+			Milestone{
+				Activity: HandlingActivity{Type: Unload, Location: l.UnloadLocation, VoyageNumber: l.VoyageNumber},
+				Planned:  l.UnloadTime,
+				Reached:  2*i+1 < reached,
+			},
+		)
+	}
+
+	return p
+}
+
+// NextLeg returns the index of the first leg of the itinerary the cargo has
+// yet to be loaded onto, if any.
+func (d Delivery) NextLeg() (int, bool) {
+	k := (d.reachedMilestones() + 1) / 2
+	if k >= len(d.Itinerary.Legs) {
+		return 0, false
+	}
+	return k, true
+}
+
+// reachedMilestones returns the number of departures and arrivals of the
+// itinerary the cargo has passed, judging from its last handling event.
+func (d Delivery) reachedMilestones() int {
+	var (
+		legs = d.Itinerary.Legs
+		a    = d.LastEvent.Activity
+	)
+
+	switch a.Type {
+	case Load:
+		for i, l := range legs {
+			if l.LoadLocation == a.Location && l.VoyageNumber == a.VoyageNumber {
+				return 2*i + 1
+			}
+		}
+	case Unload:
+		for i, l := range legs {
+			if l.UnloadLocation == a.Location && l.VoyageNumber == a.VoyageNumber {
+				return 2*i + 2
+			}
+		}
+	case Customs:
+		for i, l := range legs {
+			if l.UnloadLocation == a.Location {
+				return 2*i + 2
+			}
+		}
+	case Claim:
+		if a.Location == d.Itinerary.FinalArrivalLocation() {
+			return 2 * len(legs)
+		}
+	}
+
+	return 0
+}
+
+// IsScheduledAt returns whether the cargo is yet to be loaded or unloaded at
+// a location on a voyage.
+func (d Delivery) IsScheduledAt(voyage VoyageNumber, locode UNLocode) bool {
+	reached := d.reachedMilestones()
+	for i, l := range d.Itinerary.Legs {
+		if l.VoyageNumber != voyage {
+			continue
+		}
+		if l.LoadLocation == locode && 2*i >= reached {
+			return true
+		}
+		if l.UnloadLocation == locode && 2*i+1 >= reached {
+			return true
+		}
+	}
+	return false
+}
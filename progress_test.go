@@ -0,0 +1,116 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeliveryProgress(t *testing.T) {
+	base := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	itinerary := Itinerary{Legs: []Leg{
+		{VoyageNumber: "V100", LoadLocation: CNHKG, UnloadLocation: DEHAM, LoadTime: base, UnloadTime: base.Add(100 * time.Hour)},
+		{VoyageNumber: "V200", LoadLocation: DEHAM, UnloadLocation: SESTO, LoadTime: base.Add(120 * time.Hour), UnloadTime: base.Add(140 * time.Hour)},
+	}}
+
+	rs := RouteSpecification{Origin: CNHKG, Destination: SESTO}
+
+	event := func(t HandlingEventType, loc UNLocode, v VoyageNumber, completed time.Time) HandlingHistory {
+		return HandlingHistory{HandlingEvents: []HandlingEvent{
+			{Activity: HandlingActivity{Type: t, Location: loc, VoyageNumber: v}, Completed: completed},
+		}}
+	}
+
+	tests := []struct {
+		name      string
+		history   HandlingHistory
+		now       time.Time
+		completed int
+		percent   float64
+		reached   int
+	}{
+		{"not received", HandlingHistory{}, base, 0, 0, 0},
+		{"received", event(Receive, CNHKG, "", base), base, 0, 0, 0},
+		{"onboard first leg", event(Load, CNHKG, "V100", base), base.Add(50 * time.Hour), 0, 25, 1},
+		{"overdue on first leg", event(Load, CNHKG, "V100", base), base.Add(200 * time.Hour), 0, 50, 1},
+		{"transshipment", event(Unload, DEHAM, "V100", base.Add(100*time.Hour)), base.Add(110 * time.Hour), 1, 50, 2},
+		{"onboard last leg", event(Load, DEHAM, "V200", base.Add(120*time.Hour)), base.Add(130 * time.Hour), 1, 75, 3},
+		{"claimed", event(Claim, SESTO, "", base.Add(150*time.Hour)), base.Add(150 * time.Hour), 2, 100, 4},
+		{"misdirected", event(Unload, NLRTM, "V100", base.Add(100*time.Hour)), base.Add(110 * time.Hour), 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		d := DeriveDeliveryFrom(rs, itinerary, tt.history)
+
+		p := d.Progress(tt.now)
+
+		if p.TotalLegs != 2 {
+			t.Errorf("%s: TotalLegs = %d; want = %d", tt.name, p.TotalLegs, 2)
+		}
+		if p.CompletedLegs != tt.completed {
+			t.Errorf("%s: CompletedLegs = %d; want = %d", tt.name, p.CompletedLegs, tt.completed)
+		}
+		if p.Percent != tt.percent {
+			t.Errorf("%s: Percent = %v; want = %v", tt.name, p.Percent, tt.percent)
+		}
+
+		if len(p.Milestones) != 4 {
+			t.Fatalf("%s: len(Milestones) = %d; want = %d", tt.name, len(p.Milestones), 4)
+		}
+		for i, m := range p.Milestones {
+			if want := i < tt.reached; m.Reached != want {
+				t.Errorf("%s: Milestones[%d].Reached = %v; want = %v", tt.name, i, m.Reached, want)
+			}
+		}
+	}
+}
+
+func TestDeliveryProgress_NotRouted(t *testing.T) {
+	d := DeriveDeliveryFrom(RouteSpecification{Origin: CNHKG, Destination: SESTO}, Itinerary{}, HandlingHistory{})
+
+	p := d.Progress(time.Now())
+
+	if p.TotalLegs != 0 || p.Percent != 0 || p.Milestones != nil {
+		t.Errorf("Progress = %+v; want zero", p)
+	}
+}
+
+func TestDelivery_IsScheduledAt(t *testing.T) {
+	itinerary := Itinerary{Legs: []Leg{
+		{VoyageNumber: "V100", LoadLocation: CNHKG, UnloadLocation: DEHAM},
+		{VoyageNumber: "V200", LoadLocation: DEHAM, UnloadLocation: SESTO},
+	}}
+
+	rs := RouteSpecification{Origin: CNHKG, Destination: SESTO}
+
+	event := func(t HandlingEventType, loc UNLocode, v VoyageNumber) HandlingHistory {
+		return HandlingHistory{HandlingEvents: []HandlingEvent{
+			{Activity: HandlingActivity{Type: t, Location: loc, VoyageNumber: v}},
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		history HandlingHistory
+		voyage  VoyageNumber
+		locode  UNLocode
+		want    bool
+	}{
+		{"load ahead", HandlingHistory{}, "V100", CNHKG, true},
+		{"unload ahead", HandlingHistory{}, "V100", DEHAM, true},
+		{"other voyage", HandlingHistory{}, "V200", CNHKG, false},
+		{"loaded", event(Load, CNHKG, "V100"), "V100", CNHKG, false},
+		{"onboard", event(Load, CNHKG, "V100"), "V100", DEHAM, true},
+		{"unloaded", event(Unload, DEHAM, "V100"), "V100", DEHAM, false},
+		{"next leg", event(Unload, DEHAM, "V100"), "V200", DEHAM, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := DeriveDeliveryFrom(rs, itinerary, tt.history)
+			if got := d.IsScheduledAt(tt.voyage, tt.locode); got != tt.want {
+				t.Errorf("IsScheduledAt(%s, %s) = %v; want = %v", tt.voyage, tt.locode, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package projections
+
+import (
+	"context"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type lanePerformance struct {
+	lanes          shipping.LanePerformanceRepository
+	cargos         shipping.CargoRepository
+	handlingEvents shipping.HandlingEventRepository
+	serviceStrings shipping.ServiceStringRepository
+}
+
+// NewLanePerformance returns a projection keeping the read model of how
+// lanes performed for the cargos that sailed them. Whenever a cargo is
+// routed, rolled or handled, the outcomes of the legs it has sailed are
+// derived again from its itinerary and handling history, so that applying
+// a change twice does not count a leg twice. The service strings may be
+// nil, in which case legs are told apart by voyage.
+func NewLanePerformance(lanes shipping.LanePerformanceRepository, cargos shipping.CargoRepository, handlingEvents shipping.HandlingEventRepository, serviceStrings shipping.ServiceStringRepository) Projection {
+	return &lanePerformance{
+		lanes:          lanes,
+		cargos:         cargos,
+		handlingEvents: handlingEvents,
+		serviceStrings: serviceStrings,
+	}
+}
+
+func (p *lanePerformance) Name() string {
+	return "lane_performance"
+}
+
+func (p *lanePerformance) Interests() []shipping.CargoChangeType {
+	return []shipping.CargoChangeType{
+		shipping.CargoRoutedChange,
+		shipping.CargoRolledChange,
+		shipping.CargoHandledChange,
+	}
+}
+
+func (p *lanePerformance) Apply(ctx context.Context, ch shipping.CargoChange) error {
+	c, err := p.cargos.Find(ch.TrackingID)
+	if err == shipping.ErrUnknownCargo {
+		// Nothing is left of cargos that have been erased.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var services shipping.ServiceStrings
+	if p.serviceStrings != nil {
+		services = p.serviceStrings.FindAll()
+	}
+
+	result := make(map[shipping.Lane]shipping.LanePerformance)
+	for _, o := range c.Itinerary.LegOutcomes(p.handlingEvents.QueryHandlingHistory(c.TrackingID)) {
+		k := services.Lane(o.Leg)
+		perf := result[k]
+		perf.Add(o)
+		result[k] = perf
+	}
+
+	return p.lanes.Store(c.TrackingID, result)
+}
+
+func (p *lanePerformance) Reset(ctx context.Context) error {
+	return p.lanes.Clear()
+}
@@ -0,0 +1,74 @@
+package projections
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/inmem"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+func TestLanePerformance(t *testing.T) {
+	t0 := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{Origin: shipping.DEHAM, Destination: shipping.NLRTM})
+	c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+		shipping.NewLeg("V100", shipping.DEHAM, shipping.NLRTM, t0, t0.Add(24*time.Hour)),
+	}})
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		if id != c.TrackingID {
+			return nil, shipping.ErrUnknownCargo
+		}
+		return c, nil
+	}
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryFn = func(id shipping.TrackingID) shipping.HandlingHistory {
+		return shipping.HandlingHistory{HandlingEvents: []shipping.HandlingEvent{
+			{TrackingID: id, Activity: shipping.HandlingActivity{Type: shipping.Unload, Location: shipping.NLRTM, VoyageNumber: "V100"}, Completed: t0.Add(36 * time.Hour)},
+		}}
+	}
+
+	var services mock.ServiceStringRepository
+	services.FindAllFn = func() []*shipping.ServiceString {
+		return []*shipping.ServiceString{{Code: "AE1", Voyages: []shipping.VoyageNumber{"V100", "V101"}}}
+	}
+
+	lanes := inmem.NewLanePerformanceRepository()
+
+	p := NewLanePerformance(lanes, &cargos, &events, &services)
+
+	ctx := context.Background()
+
+	// Applying the change twice counts the leg once.
+	for i := 0; i < 2; i++ {
+		if err := p.Apply(ctx, shipping.CargoChange{TrackingID: c.TrackingID, Type: shipping.CargoHandledChange}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := p.Apply(ctx, shipping.CargoChange{TrackingID: "ERASED", Type: shipping.CargoHandledChange}); err != nil {
+		t.Errorf("err = %v; want none for an erased cargo", err)
+	}
+
+	lane := shipping.Lane{Service: "AE1", From: shipping.DEHAM, To: shipping.NLRTM}
+
+	got := lanes.FindAll()
+	if len(got) != 1 {
+		t.Fatalf("len(lanes) = %d; want = %d", len(got), 1)
+	}
+	if perf := got[lane]; perf.Sailed != 1 || perf.AverageDelay() != 12*time.Hour {
+		t.Errorf("lanes[%v] = %+v; want sailed once, 12h late", lane, perf)
+	}
+
+	if err := p.Reset(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := lanes.FindAll(); len(got) != 0 {
+		t.Errorf("lanes = %v; want none after reset", got)
+	}
+}
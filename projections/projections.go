@@ -0,0 +1,175 @@
+// Package projections keeps read models up to date with the log of cargo
+// changes. Every projection keeps its own checkpoint in the log, so that it
+// picks up where it left off after a restart, and can be rebuilt from
+// scratch by replaying the log from the start.
+package projections
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// ErrUnknownProjection is returned when replaying a projection that has not
+// been registered.
+var ErrUnknownProjection = errors.New("unknown projection")
+
+// Projection maintains a read model from the changes to cargos.
+//
+// A change may be applied more than once, if the runner stops before it has
+// stored the checkpoint of a projection, so applying changes should be
+// idempotent.
+type Projection interface {
+	// Name identifies the projection and its checkpoint. It must not change
+	// between restarts.
+	Name() string
+
+	// Interests returns the types of changes the projection is fed with. A
+	// projection without interests is fed with every change.
+	Interests() []shipping.CargoChangeType
+
+	// Apply updates the read model with a change.
+	Apply(ctx context.Context, c shipping.CargoChange) error
+
+	// Reset clears the read model before it is replayed.
+	Reset(ctx context.Context) error
+}
+
+// batchSize is the number of changes read from the log at a time.
+const batchSize = 100
+
+// Runner feeds projections with the changes recorded since their
+// checkpoints.
+type Runner struct {
+	changes     shipping.CargoChangeRepository
+	checkpoints shipping.ProjectionCheckpointRepository
+	projections []Projection
+	logger      log.Logger
+
+	// mtx keeps a replay from running while projections are catching up.
+	mtx sync.Mutex
+}
+
+// NewRunner returns a new instance of a Runner.
+func NewRunner(logger log.Logger, changes shipping.CargoChangeRepository, checkpoints shipping.ProjectionCheckpointRepository, projections ...Projection) *Runner {
+	return &Runner{
+		changes:     changes,
+		checkpoints: checkpoints,
+		projections: projections,
+		logger:      logger,
+	}
+}
+
+// Run catches up the projections every interval until the context is done.
+func (r *Runner) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		r.CatchUp(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// CatchUp feeds every projection with the changes recorded since its
+// checkpoint, and returns how many changes were applied. A projection that
+// fails is logged and left at the change that failed, to be retried on the
+// next catch up, without holding up the others.
+func (r *Runner) CatchUp(ctx context.Context) int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	var n int
+	for _, p := range r.projections {
+		applied, err := r.catchUp(ctx, p)
+		if err != nil {
+			r.logger.Log("projection", p.Name(), "err", err)
+		}
+		n += applied
+	}
+	return n
+}
+
+// Replay resets the named projection and feeds it with the whole log.
+func (r *Runner) Replay(ctx context.Context, name string) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for _, p := range r.projections {
+		if p.Name() != name {
+			continue
+		}
+
+		// Rewind the checkpoint before resetting, so that a failed reset
+		// is retried by applying the log to what was left, rather than
+		// leaving the projection empty.
+		if err := r.checkpoints.Store(name, 0); err != nil {
+			return err
+		}
+		if err := p.Reset(ctx); err != nil {
+			return err
+		}
+
+		applied, err := r.catchUp(ctx, p)
+		r.logger.Log("projection", name, "replayed", applied, "err", err)
+		return err
+	}
+
+	return ErrUnknownProjection
+}
+
+func (r *Runner) catchUp(ctx context.Context, p Projection) (int, error) {
+	seq, err := r.checkpoints.Find(p.Name())
+	if err != nil {
+		return 0, err
+	}
+
+	var interests map[shipping.CargoChangeType]bool
+	if types := p.Interests(); len(types) > 0 {
+		interests = make(map[shipping.CargoChangeType]bool)
+		for _, t := range types {
+			interests[t] = true
+		}
+	}
+
+	var n int
+	for {
+		changes := r.changes.FindSince(seq, batchSize)
+
+		for _, c := range changes {
+			if interests == nil || interests[c.Type] {
+				if err := p.Apply(ctx, *c); err != nil {
+					// Keep the changes applied so far. Should this
+					// fail too, they are applied again next time.
+					r.checkpoints.Store(p.Name(), seq)
+					return n, err
+				}
+				n++
+			}
+			seq = c.Sequence
+		}
+
+		if len(changes) > 0 {
+			if err := r.checkpoints.Store(p.Name(), seq); err != nil {
+				return n, err
+			}
+		}
+
+		if len(changes) < batchSize {
+			return n, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+	}
+}
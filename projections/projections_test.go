@@ -0,0 +1,135 @@
+package projections
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+type recordingProjection struct {
+	name      string
+	interests []shipping.CargoChangeType
+	fail      uint64
+	applied   []uint64
+}
+
+func (p *recordingProjection) Name() string                          { return p.name }
+func (p *recordingProjection) Interests() []shipping.CargoChangeType { return p.interests }
+
+func (p *recordingProjection) Apply(ctx context.Context, c shipping.CargoChange) error {
+	if c.Sequence == p.fail {
+		return errors.New("apply failed")
+	}
+	p.applied = append(p.applied, c.Sequence)
+	return nil
+}
+
+func (p *recordingProjection) Reset(ctx context.Context) error {
+	p.applied = nil
+	return nil
+}
+
+func newRepositories(types ...shipping.CargoChangeType) (*mock.CargoChangeRepository, *mock.ProjectionCheckpointRepository, map[string]uint64) {
+	var entries []*shipping.CargoChange
+	for i, t := range types {
+		entries = append(entries, &shipping.CargoChange{Sequence: uint64(i + 1), TrackingID: "ABC123", Type: t})
+	}
+
+	var changes mock.CargoChangeRepository
+	changes.FindSinceFn = func(seq uint64, limit int) []*shipping.CargoChange {
+		if seq >= uint64(len(entries)) {
+			return nil
+		}
+		result := entries[seq:]
+		if len(result) > limit {
+			result = result[:limit]
+		}
+		return result
+	}
+
+	stored := make(map[string]uint64)
+
+	var checkpoints mock.ProjectionCheckpointRepository
+	checkpoints.StoreFn = func(name string, seq uint64) error {
+		stored[name] = seq
+		return nil
+	}
+	checkpoints.FindFn = func(name string) (uint64, error) {
+		return stored[name], nil
+	}
+
+	return &changes, &checkpoints, stored
+}
+
+func TestCatchUp(t *testing.T) {
+	changes, checkpoints, stored := newRepositories(
+		shipping.CargoRoutedChange,
+		shipping.CargoHandledChange,
+		shipping.CargoHandledChange,
+		shipping.CargoArrivedChange,
+	)
+
+	var (
+		all     = &recordingProjection{name: "all"}
+		handled = &recordingProjection{name: "handled", interests: []shipping.CargoChangeType{shipping.CargoHandledChange}}
+		failing = &recordingProjection{name: "failing", fail: 3}
+	)
+
+	r := NewRunner(log.NewNopLogger(), changes, checkpoints, all, handled, failing)
+
+	if n := r.CatchUp(context.Background()); n != 8 {
+		t.Errorf("n = %d; want = %d", n, 8)
+	}
+
+	if want := []uint64{1, 2, 3, 4}; !reflect.DeepEqual(all.applied, want) {
+		t.Errorf("all.applied = %v; want = %v", all.applied, want)
+	}
+	if want := []uint64{2, 3}; !reflect.DeepEqual(handled.applied, want) {
+		t.Errorf("handled.applied = %v; want = %v", handled.applied, want)
+	}
+	if want := (map[string]uint64{"all": 4, "handled": 4, "failing": 2}); !reflect.DeepEqual(stored, want) {
+		t.Errorf("stored = %v; want = %v", stored, want)
+	}
+
+	// Nothing new is applied again, but the failing projection retries
+	// from where it stopped.
+	failing.fail = 0
+	if n := r.CatchUp(context.Background()); n != 2 {
+		t.Errorf("n = %d; want = %d", n, 2)
+	}
+	if want := []uint64{1, 2, 3, 4}; !reflect.DeepEqual(failing.applied, want) {
+		t.Errorf("failing.applied = %v; want = %v", failing.applied, want)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	changes, checkpoints, stored := newRepositories(
+		shipping.CargoRoutedChange,
+		shipping.CargoHandledChange,
+	)
+
+	p := &recordingProjection{name: "tracking"}
+
+	r := NewRunner(log.NewNopLogger(), changes, checkpoints, p)
+	r.CatchUp(context.Background())
+
+	if err := r.Replay(context.Background(), "tracking"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint64{1, 2}; !reflect.DeepEqual(p.applied, want) {
+		t.Errorf("p.applied = %v; want = %v", p.applied, want)
+	}
+	if stored["tracking"] != 2 {
+		t.Errorf("checkpoint = %d; want = %d", stored["tracking"], 2)
+	}
+
+	if err := r.Replay(context.Background(), "search"); err != ErrUnknownProjection {
+		t.Errorf("err = %v; want = %v", err, ErrUnknownProjection)
+	}
+}
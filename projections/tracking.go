@@ -0,0 +1,51 @@
+package projections
+
+import (
+	"context"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/tracking"
+)
+
+type trackingIndex struct {
+	index  *tracking.Index
+	cargos shipping.CargoRepository
+}
+
+// NewTrackingIndex returns a projection keeping the index of the tracking
+// IDs suggested for mistyped ones up to date with the cargos booked. As the
+// index is kept in memory, it should be rebuilt from the cargos on startup;
+// a replay rebuilds it the same way.
+func NewTrackingIndex(index *tracking.Index, cargos shipping.CargoRepository) Projection {
+	return &trackingIndex{
+		index:  index,
+		cargos: cargos,
+	}
+}
+
+func (p *trackingIndex) Name() string {
+	return "tracking_index"
+}
+
+func (p *trackingIndex) Interests() []shipping.CargoChangeType {
+	return []shipping.CargoChangeType{
+		shipping.CargoBookedChange,
+	}
+}
+
+func (p *trackingIndex) Apply(ctx context.Context, ch shipping.CargoChange) error {
+	_, err := p.cargos.Find(ch.TrackingID)
+	if err == shipping.ErrUnknownCargo {
+		// Erased cargos are not suggested.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	p.index.Add(ch.TrackingID)
+	return nil
+}
+
+func (p *trackingIndex) Reset(ctx context.Context) error {
+	return p.index.Rebuild(p.cargos)
+}
@@ -0,0 +1,48 @@
+package projections
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/inmem"
+	"github.com/marcusolsson/goddd/tracking"
+)
+
+func TestTrackingIndex(t *testing.T) {
+	cargos := inmem.NewCargoRepository()
+	if err := cargos.Store(shipping.NewCargo("ABC123", shipping.RouteSpecification{})); err != nil {
+		t.Fatal(err)
+	}
+
+	index := tracking.NewIndex()
+
+	p := NewTrackingIndex(index, cargos)
+
+	ctx := context.Background()
+
+	for _, id := range []shipping.TrackingID{"ABC123", "ABC124"} {
+		if err := p.Apply(ctx, shipping.CargoChange{TrackingID: id, Type: shipping.CargoBookedChange}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// ABC124 is not suggested, as it has been erased.
+	want := []shipping.TrackingID{"ABC123"}
+	if got := index.Suggest("ABC12", 1, 5); !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest() = %v; want = %v", got, want)
+	}
+
+	if err := cargos.Store(shipping.NewCargo("ABC125", shipping.RouteSpecification{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Reset(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want = []shipping.TrackingID{"ABC123", "ABC125"}
+	if got := index.Suggest("ABC12", 1, 5); !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest() = %v; want = %v after reset", got, want)
+	}
+}
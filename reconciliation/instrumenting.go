@@ -0,0 +1,43 @@
+package reconciliation
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) Reconcile(ctx context.Context, voyage shipping.VoyageNumber) (Reconciliation, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "reconcile").Add(1)
+		s.requestLatency.With("method", "reconcile").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Reconcile(ctx, voyage)
+}
+
+func (s *instrumentingService) CompleteVoyage(ctx context.Context, voyage shipping.VoyageNumber) (Reconciliation, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "complete_voyage").Add(1)
+		s.requestLatency.With("method", "complete_voyage").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.CompleteVoyage(ctx, voyage)
+}
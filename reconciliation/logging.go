@@ -0,0 +1,71 @@
+package reconciliation
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) Reconcile(ctx context.Context, voyage shipping.VoyageNumber) (r Reconciliation, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "reconcile",
+			"request_id", correlation.FromContext(ctx),
+			"voyage", voyage,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Reconcile(ctx, voyage)
+}
+
+func (s *loggingService) CompleteVoyage(ctx context.Context, voyage shipping.VoyageNumber) (r Reconciliation, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "complete_voyage",
+			"request_id", correlation.FromContext(ctx),
+			"voyage", voyage,
+			"discrepancies", len(r.Discrepancies),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.CompleteVoyage(ctx, voyage)
+}
+
+type loggingEventHandler struct {
+	logger log.Logger
+}
+
+// NewLoggingEventHandler returns an EventHandler that reports the
+// discrepancies of completed voyages through the log.
+func NewLoggingEventHandler(logger log.Logger) EventHandler {
+	return &loggingEventHandler{logger}
+}
+
+func (h *loggingEventHandler) VoyageNotReconciled(ctx context.Context, r shipping.VoyageReconciliation) {
+	for _, d := range r.Discrepancies {
+		h.logger.Log(
+			"msg", "voyage discrepancy",
+			"request_id", correlation.FromContext(ctx),
+			"voyage", r.VoyageNumber,
+			"tracking_id", d.TrackingID,
+			"type", d.Type,
+			"location", d.Location,
+		)
+	}
+}
@@ -0,0 +1,151 @@
+// Package reconciliation provides the use-case of reconciling the cargos
+// booked on a voyage with the cargos loaded onto and unloaded from it, once
+// the voyage completes its rotation.
+package reconciliation
+
+import (
+	"context"
+	"errors"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/position"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// EventHandler provides a means of subscribing to discrepancies found as
+// voyages complete, e.g. to have operations locate the cargos.
+type EventHandler interface {
+	VoyageNotReconciled(context.Context, shipping.VoyageReconciliation)
+}
+
+// EventHandlers notifies several event handlers in order.
+type EventHandlers []EventHandler
+
+// VoyageNotReconciled notifies every handler.
+func (hs EventHandlers) VoyageNotReconciled(ctx context.Context, r shipping.VoyageReconciliation) {
+	for _, h := range hs {
+		h.VoyageNotReconciled(ctx, r)
+	}
+}
+
+// Service is the interface that provides reconciliation methods.
+type Service interface {
+	// Reconcile compares the cargos booked on a voyage to the cargos loaded
+	// onto and unloaded from it so far.
+	Reconcile(ctx context.Context, voyage shipping.VoyageNumber) (Reconciliation, error)
+
+	// CompleteVoyage reconciles a voyage that has completed its rotation.
+	// Interested parties are notified of any discrepancies.
+	CompleteVoyage(ctx context.Context, voyage shipping.VoyageNumber) (Reconciliation, error)
+}
+
+type service struct {
+	voyages shipping.VoyageRepository
+	cargos  shipping.CargoRepository
+	events  shipping.HandlingEventRepository
+	handler EventHandler
+}
+
+func (s *service) Reconcile(ctx context.Context, voyage shipping.VoyageNumber) (Reconciliation, error) {
+	r, err := s.reconcile(voyage)
+	if err != nil {
+		return Reconciliation{}, err
+	}
+	return assemble(r), nil
+}
+
+func (s *service) CompleteVoyage(ctx context.Context, voyage shipping.VoyageNumber) (Reconciliation, error) {
+	r, err := s.reconcile(voyage)
+	if err != nil {
+		return Reconciliation{}, err
+	}
+
+	if !r.IsReconciled() && s.handler != nil {
+		s.handler.VoyageNotReconciled(ctx, r)
+	}
+
+	return assemble(r), nil
+}
+
+func (s *service) reconcile(voyage shipping.VoyageNumber) (shipping.VoyageReconciliation, error) {
+	if voyage == "" {
+		return shipping.VoyageReconciliation{}, ErrInvalidArgument
+	}
+
+	if _, err := s.voyages.Find(voyage); err != nil {
+		return shipping.VoyageReconciliation{}, err
+	}
+
+	// Cargos handled on the voyage without a leg on it are only found in
+	// their histories, so every cargo is looked at.
+	cargos := s.cargos.FindAll()
+
+	ids := make([]shipping.TrackingID, len(cargos))
+	for i, c := range cargos {
+		ids[i] = c.TrackingID
+	}
+
+	return shipping.ReconcileVoyage(voyage, cargos, s.events.QueryHandlingHistories(ids)), nil
+}
+
+// NewService creates a reconciliation service with necessary dependencies.
+// The handler may be nil.
+func NewService(voyages shipping.VoyageRepository, cargos shipping.CargoRepository, events shipping.HandlingEventRepository, handler EventHandler) Service {
+	return &service{
+		voyages: voyages,
+		cargos:  cargos,
+		events:  events,
+		handler: handler,
+	}
+}
+
+// Reconciliation is a read model for voyage reconciliation views.
+type Reconciliation struct {
+	VoyageNumber  string        `json:"voyage_number"`
+	Booked        int           `json:"booked"`
+	Handled       int           `json:"handled"`
+	Reconciled    bool          `json:"reconciled"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
+
+// Discrepancy is a read model for a cargo handled on a voyage other than as
+// booked.
+type Discrepancy struct {
+	TrackingID string `json:"tracking_id"`
+	Type       string `json:"type"`
+	Location   string `json:"location"`
+}
+
+func assemble(r shipping.VoyageReconciliation) Reconciliation {
+	result := Reconciliation{
+		VoyageNumber:  string(r.VoyageNumber),
+		Booked:        r.Booked,
+		Handled:       r.Handled,
+		Reconciled:    r.IsReconciled(),
+		Discrepancies: make([]Discrepancy, 0, len(r.Discrepancies)),
+	}
+	for _, d := range r.Discrepancies {
+		result.Discrepancies = append(result.Discrepancies, Discrepancy{
+			TrackingID: string(d.TrackingID),
+			Type:       d.Type.String(),
+			Location:   string(d.Location),
+		})
+	}
+	return result
+}
+
+type positionEventHandler struct {
+	s Service
+}
+
+func (h *positionEventHandler) VoyageCompleted(ctx context.Context, v *shipping.Voyage) {
+	h.s.CompleteVoyage(ctx, v.VoyageNumber)
+}
+
+// NewPositionEventHandler returns a handler that reconciles voyages as they
+// complete their rotations.
+func NewPositionEventHandler(s Service) position.EventHandler {
+	return &positionEventHandler{s: s}
+}
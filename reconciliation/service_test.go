@@ -0,0 +1,101 @@
+package reconciliation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+type stubEventHandler struct {
+	notReconciled []shipping.VoyageReconciliation
+}
+
+func (h *stubEventHandler) VoyageNotReconciled(ctx context.Context, r shipping.VoyageReconciliation) {
+	h.notReconciled = append(h.notReconciled, r)
+}
+
+func newService(handler EventHandler) Service {
+	var voyages mock.VoyageRepository
+	voyages.FindFn = func(n shipping.VoyageNumber) (*shipping.Voyage, error) {
+		if n != "V100" {
+			return nil, shipping.ErrUnknownVoyage
+		}
+		return &shipping.Voyage{VoyageNumber: n}, nil
+	}
+
+	leg := shipping.NewLeg("V100", shipping.CNHKG, shipping.SESTO, time.Time{}, time.Time{})
+
+	var cargos mock.CargoRepository
+	cargos.FindAllFn = func() []*shipping.Cargo {
+		return []*shipping.Cargo{
+			{TrackingID: "ABC123", Itinerary: shipping.Itinerary{Legs: []shipping.Leg{leg}}},
+			{TrackingID: "FTL456", Itinerary: shipping.Itinerary{Legs: []shipping.Leg{leg}}},
+		}
+	}
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoriesFn = func(ids []shipping.TrackingID) map[shipping.TrackingID]shipping.HandlingHistory {
+		return map[shipping.TrackingID]shipping.HandlingHistory{
+			"ABC123": {HandlingEvents: []shipping.HandlingEvent{
+				{Activity: shipping.HandlingActivity{Type: shipping.Load, Location: shipping.CNHKG, VoyageNumber: "V100"}},
+				{Activity: shipping.HandlingActivity{Type: shipping.Unload, Location: shipping.SESTO, VoyageNumber: "V100"}},
+			}},
+			"FTL456": {HandlingEvents: []shipping.HandlingEvent{
+				{Activity: shipping.HandlingActivity{Type: shipping.Load, Location: shipping.CNHKG, VoyageNumber: "V100"}},
+			}},
+		}
+	}
+
+	return NewService(&voyages, &cargos, &events, handler)
+}
+
+func TestReconcile(t *testing.T) {
+	var handler stubEventHandler
+
+	s := newService(&handler)
+
+	r, err := s.Reconcile(context.Background(), "V100")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Booked != 2 || r.Handled != 2 || r.Reconciled {
+		t.Errorf("r = %+v", r)
+	}
+
+	want := Discrepancy{TrackingID: "FTL456", Type: "Not unloaded", Location: "CNHKG"}
+	if len(r.Discrepancies) != 1 || r.Discrepancies[0] != want {
+		t.Errorf("Discrepancies = %+v; want = [%+v]", r.Discrepancies, want)
+	}
+
+	if len(handler.notReconciled) != 0 {
+		t.Errorf("handler should only be notified as voyages complete")
+	}
+
+	if _, err := s.Reconcile(context.Background(), ""); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+	if _, err := s.Reconcile(context.Background(), "V999"); err != shipping.ErrUnknownVoyage {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownVoyage)
+	}
+}
+
+func TestCompleteVoyage(t *testing.T) {
+	var handler stubEventHandler
+
+	s := newService(&handler)
+
+	if _, err := s.CompleteVoyage(context.Background(), "V100"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(handler.notReconciled) != 1 {
+		t.Fatalf("len(notReconciled) = %d; want = %d", len(handler.notReconciled), 1)
+	}
+	if n := handler.notReconciled[0].VoyageNumber; n != "V100" {
+		t.Errorf("VoyageNumber = %s; want = %s", n, "V100")
+	}
+}
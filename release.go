@@ -0,0 +1,102 @@
+package shipping
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ReleaseCode authorizes the release of a cargo at its destination. It is
+// issued to the consignee once the cargo may be released, such as when the
+// freight has been paid and the cargo has cleared customs, and whoever
+// collects the cargo presents its PIN to the terminal claiming it.
+type ReleaseCode struct {
+	TrackingID TrackingID
+
+	// PINHash is the hash of the PIN. The PIN itself is never stored, and
+	// is only shown when the code is issued.
+	PINHash string
+	Issued  time.Time
+
+	// Attempts is the number of wrong PINs presented since the code was
+	// issued.
+	Attempts int
+}
+
+// MaxReleaseAttempts is the number of wrong PINs after which a release code
+// is locked until issued again. PINs being short, this is what keeps them
+// from being guessed.
+const MaxReleaseAttempts = 5
+
+// releasePINDigits is the length of release PINs, short enough to be read
+// out over the phone.
+const releasePINDigits = 6
+
+// NewReleaseCode issues a release code for a cargo, returned along with its
+// PIN.
+func NewReleaseCode(id TrackingID, issued time.Time) (*ReleaseCode, string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1e6))
+	if err != nil {
+		return nil, "", err
+	}
+	pin := fmt.Sprintf("%0*d", releasePINDigits, n)
+
+	return &ReleaseCode{
+		TrackingID: id,
+		PINHash:    hashKey(pin),
+		Issued:     issued,
+	}, pin, nil
+}
+
+// IsLocked returns whether too many wrong PINs have been presented for the
+// code to be used.
+func (r ReleaseCode) IsLocked() bool {
+	return r.Attempts >= MaxReleaseAttempts
+}
+
+// Verify checks a PIN presented for the release of the cargo. Wrong PINs
+// are counted, and once the code is locked even the right one is refused.
+func (r *ReleaseCode) Verify(pin string) error {
+	if r.IsLocked() {
+		return ErrReleaseCodeLocked
+	}
+	if !matchKey(pin, r.PINHash) {
+		r.Attempts++
+		return ErrInvalidReleaseCode
+	}
+	return nil
+}
+
+// ReleaseCodeRepository provides access a release code store.
+type ReleaseCodeRepository interface {
+	// Store stores the release code of a cargo, replacing any previous one.
+	Store(r *ReleaseCode) error
+
+	// Find returns the release code of a cargo, or ErrNoReleaseCode if none
+	// has been issued.
+	Find(id TrackingID) (*ReleaseCode, error)
+}
+
+var (
+	// ErrNoReleaseCode is used when claiming a cargo for which no release
+	// code has been issued.
+	ErrNoReleaseCode = errors.New("no release code issued")
+
+	// ErrReleaseCodeRequired is used when claiming a cargo without
+	// presenting its release code.
+	ErrReleaseCodeRequired = errors.New("release code required")
+
+	// ErrInvalidReleaseCode is used when the release code presented is not
+	// the one issued.
+	ErrInvalidReleaseCode = errors.New("invalid release code")
+
+	// ErrReleaseCodeLocked is used when too many wrong release codes have
+	// been presented for a cargo.
+	ErrReleaseCodeLocked = errors.New("release code locked")
+
+	// ErrAlreadyClaimed is used when releasing a cargo that has already been
+	// claimed.
+	ErrAlreadyClaimed = errors.New("cargo is already claimed")
+)
@@ -0,0 +1,38 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReleaseCode(t *testing.T) {
+	r, pin, err := NewReleaseCode("ABC123", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pin) != releasePINDigits {
+		t.Errorf("pin = %q; want %d digits", pin, releasePINDigits)
+	}
+	if r.PINHash == pin {
+		t.Errorf("the PIN should not be stored in clear text")
+	}
+
+	if err := r.Verify(pin); err != nil {
+		t.Errorf("Verify(%q) = %v", pin, err)
+	}
+
+	wrong := "x" + pin[1:]
+	for i := 0; i < MaxReleaseAttempts; i++ {
+		if err := r.Verify(wrong); err != ErrInvalidReleaseCode {
+			t.Fatalf("Verify(%q) = %v; want = %v", wrong, err, ErrInvalidReleaseCode)
+		}
+	}
+
+	if !r.IsLocked() {
+		t.Errorf("code should be locked after %d wrong PINs", MaxReleaseAttempts)
+	}
+	if err := r.Verify(pin); err != ErrReleaseCodeLocked {
+		t.Errorf("Verify(%q) = %v; want = %v", pin, err, ErrReleaseCodeLocked)
+	}
+}
@@ -0,0 +1,43 @@
+package shipping
+
+import "time"
+
+// ReplicatedType is the kind of aggregate an entry of the replication log
+// refers to.
+type ReplicatedType string
+
+// Replicated types.
+const (
+	// ReplicatedCargo is a cargo, whose entry is also appended when one of
+	// its handling events is stored.
+	ReplicatedCargo ReplicatedType = "cargo"
+
+	ReplicatedVoyage ReplicatedType = "voyage"
+)
+
+// ReplicationEntry is an entry in the log of the cargos and voyages stored by
+// a deployment, which standby deployments follow to stay in sync with it.
+// Entries only name what was stored; its state is read as it is when the
+// entry is exported.
+type ReplicationEntry struct {
+	Sequence uint64
+	Type     ReplicatedType
+	ID       string
+	Time     time.Time
+}
+
+// ReplicationLogRepository provides access to an append-only log of the
+// aggregates stored.
+type ReplicationLogRepository interface {
+	// Append numbers the entry with the next sequence number and adds it to
+	// the log.
+	Append(e *ReplicationEntry) error
+
+	// FindSince returns at most limit entries appended after the entry with
+	// the given sequence number, in order.
+	FindSince(seq uint64, limit int) []*ReplicationEntry
+
+	// Last returns the sequence number of the last entry, or zero if the log
+	// is empty.
+	Last() (uint64, error)
+}
@@ -0,0 +1,86 @@
+package replication
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) Status(ctx context.Context) (Status, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "status").Add(1)
+		s.requestLatency.With("method", "status").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Status(ctx)
+}
+
+func (s *instrumentingService) Export(ctx context.Context, token, since string, limit int) (Batch, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "export").Add(1)
+		s.requestLatency.With("method", "export").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Export(ctx, token, since, limit)
+}
+
+func (s *instrumentingService) Apply(ctx context.Context, token string, b Batch) (Status, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "apply").Add(1)
+		s.requestLatency.With("method", "apply").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Apply(ctx, token, b)
+}
+
+func (s *instrumentingService) Digest(ctx context.Context, token string) (Digest, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "digest").Add(1)
+		s.requestLatency.With("method", "digest").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Digest(ctx, token)
+}
+
+func (s *instrumentingService) Compare(ctx context.Context, token string, d Digest) (Divergence, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "compare").Add(1)
+		s.requestLatency.With("method", "compare").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Compare(ctx, token, d)
+}
+
+func (s *instrumentingService) Demote(ctx context.Context, token string) (Status, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "demote").Add(1)
+		s.requestLatency.With("method", "demote").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Demote(ctx, token)
+}
+
+func (s *instrumentingService) Promote(ctx context.Context, token, until string) (Status, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "promote").Add(1)
+		s.requestLatency.With("method", "promote").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Promote(ctx, token, until)
+}
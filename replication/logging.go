@@ -0,0 +1,119 @@
+package replication
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) Status(ctx context.Context) (st Status, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "status",
+			"request_id", correlation.FromContext(ctx),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Status(ctx)
+}
+
+func (s *loggingService) Export(ctx context.Context, token, since string, limit int) (b Batch, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "export",
+			"request_id", correlation.FromContext(ctx),
+			"since", since,
+			"limit", limit,
+			"cursor", b.Cursor,
+			"cargos", len(b.Cargos),
+			"voyages", len(b.Voyages),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Export(ctx, token, since, limit)
+}
+
+func (s *loggingService) Apply(ctx context.Context, token string, b Batch) (st Status, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "apply",
+			"request_id", correlation.FromContext(ctx),
+			"since", b.Since,
+			"cursor", b.Cursor,
+			"cargos", len(b.Cargos),
+			"voyages", len(b.Voyages),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Apply(ctx, token, b)
+}
+
+func (s *loggingService) Digest(ctx context.Context, token string) (d Digest, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "digest",
+			"request_id", correlation.FromContext(ctx),
+			"cargos", len(d.Cargos),
+			"voyages", len(d.Voyages),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Digest(ctx, token)
+}
+
+func (s *loggingService) Compare(ctx context.Context, token string, d Digest) (r Divergence, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "compare",
+			"request_id", correlation.FromContext(ctx),
+			"differences", len(r.Differences),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Compare(ctx, token, d)
+}
+
+func (s *loggingService) Demote(ctx context.Context, token string) (st Status, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "demote",
+			"request_id", correlation.FromContext(ctx),
+			"cursor", st.Cursor,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Demote(ctx, token)
+}
+
+func (s *loggingService) Promote(ctx context.Context, token, until string) (st Status, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "promote",
+			"request_id", correlation.FromContext(ctx),
+			"until", until,
+			"role", st.Role,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Promote(ctx, token, until)
+}
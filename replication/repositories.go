@@ -0,0 +1,118 @@
+package replication
+
+import (
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// The repositories below append to the replication log as they are stored
+// to. The state is stored by then, so failing to append does not fail the
+// store; the aggregate is left out of batches until it is stored again, and
+// shows in the divergence report until then.
+
+type cargoRepository struct {
+	log  shipping.ReplicationLogRepository
+	next shipping.CargoRepository
+}
+
+func (r *cargoRepository) Store(c *shipping.Cargo) error {
+	if err := r.next.Store(c); err != nil {
+		return err
+	}
+	appendEntry(r.log, shipping.ReplicatedCargo, string(c.TrackingID))
+	return nil
+}
+
+func (r *cargoRepository) Find(id shipping.TrackingID) (*shipping.Cargo, error) {
+	return r.next.Find(id)
+}
+
+func (r *cargoRepository) FindAll() []*shipping.Cargo {
+	return r.next.FindAll()
+}
+
+func (r *cargoRepository) ForEach(fn func(*shipping.Cargo) error) error {
+	return r.next.ForEach(fn)
+}
+
+func (r *cargoRepository) FindMatching(q shipping.CargoQuery) ([]*shipping.Cargo, error) {
+	return r.next.FindMatching(q)
+}
+
+// NewCargoRepository returns a cargo repository that logs the cargos stored
+// in next.
+func NewCargoRepository(log shipping.ReplicationLogRepository, next shipping.CargoRepository) shipping.CargoRepository {
+	return &cargoRepository{log: log, next: next}
+}
+
+type handlingEventRepository struct {
+	log  shipping.ReplicationLogRepository
+	next shipping.HandlingEventRepository
+}
+
+func (r *handlingEventRepository) Store(e shipping.HandlingEvent) {
+	r.next.Store(e)
+	appendEntry(r.log, shipping.ReplicatedCargo, string(e.TrackingID))
+}
+
+func (r *handlingEventRepository) QueryHandlingHistory(id shipping.TrackingID) shipping.HandlingHistory {
+	return r.next.QueryHandlingHistory(id)
+}
+
+func (r *handlingEventRepository) QueryHandlingHistoryPage(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+	return r.next.QueryHandlingHistoryPage(id, q)
+}
+
+func (r *handlingEventRepository) QueryHandlingHistories(ids []shipping.TrackingID) map[shipping.TrackingID]shipping.HandlingHistory {
+	return r.next.QueryHandlingHistories(ids)
+}
+
+func (r *handlingEventRepository) ForEach(fn func(shipping.HandlingEvent) error) error {
+	return r.next.ForEach(fn)
+}
+
+// NewHandlingEventRepository returns a handling event repository that logs
+// the cargos whose handling events are stored in next.
+func NewHandlingEventRepository(log shipping.ReplicationLogRepository, next shipping.HandlingEventRepository) shipping.HandlingEventRepository {
+	return &handlingEventRepository{log: log, next: next}
+}
+
+type voyageRepository struct {
+	log  shipping.ReplicationLogRepository
+	next shipping.VoyageRepository
+}
+
+func (r *voyageRepository) Store(v *shipping.Voyage) error {
+	if err := r.next.Store(v); err != nil {
+		return err
+	}
+	appendEntry(r.log, shipping.ReplicatedVoyage, string(v.VoyageNumber))
+	return nil
+}
+
+func (r *voyageRepository) Find(number shipping.VoyageNumber) (*shipping.Voyage, error) {
+	return r.next.Find(number)
+}
+
+func (r *voyageRepository) FindAll() []*shipping.Voyage {
+	return r.next.FindAll()
+}
+
+func (r *voyageRepository) FindCallingAt(locode shipping.UNLocode) []*shipping.Voyage {
+	return r.next.FindCallingAt(locode)
+}
+
+// NewVoyageRepository returns a voyage repository that logs the voyages
+// stored in next.
+func NewVoyageRepository(log shipping.ReplicationLogRepository, next shipping.VoyageRepository) shipping.VoyageRepository {
+	return &voyageRepository{log: log, next: next}
+}
+
+func appendEntry(log shipping.ReplicationLogRepository, t shipping.ReplicatedType, id string) {
+	log.Append(&shipping.ReplicationEntry{
+		Type: t,
+		ID:   id,
+		Time: time.Now(),
+	})
+}
@@ -0,0 +1,557 @@
+// Package replication provides the use-case of keeping a standby deployment,
+// such as one in another region, in sync with the cargos, handling events
+// and voyages of the primary, and of failing over to it.
+//
+// The primary logs the cargos and voyages it stores. Batches exported from
+// the log carry their state as it is when exported, and are applied in order
+// to the standby, which refuses other writes until it is promoted.
+package replication
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+var (
+	// ErrInvalidArgument is returned when one or more arguments are invalid.
+	ErrInvalidArgument = errors.New("invalid argument")
+
+	// ErrInvalidToken is returned when the token does not match the one
+	// shared by the deployments.
+	ErrInvalidToken = errors.New("invalid replication token")
+
+	// ErrStandby is returned when writing to a standby deployment.
+	ErrStandby = errors.New("deployment is a standby")
+
+	// ErrNotStandby is returned when applying a batch to a deployment that
+	// is not a standby.
+	ErrNotStandby = errors.New("deployment is not a standby")
+
+	// ErrOutOfSequence is returned when applying a batch exported after
+	// entries the standby has yet to apply.
+	ErrOutOfSequence = errors.New("batch out of sequence")
+
+	// ErrNotCaughtUp is returned when promoting a standby that has yet to
+	// apply the log of the primary up to the cursor given.
+	ErrNotCaughtUp = errors.New("standby has not caught up")
+)
+
+// Role is the part a deployment plays in replication.
+type Role string
+
+// Roles of deployments.
+const (
+	Primary Role = "primary"
+	Standby Role = "standby"
+)
+
+// ParseRole returns the role of the given name.
+func ParseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case Primary, Standby:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown role %q", s)
+}
+
+// Fence holds the role of a deployment, for writes to be refused while it is
+// a standby.
+type Fence struct {
+	mtx  sync.RWMutex
+	role Role
+}
+
+// NewFence returns a fence for a deployment starting out in the given role.
+func NewFence(r Role) *Fence {
+	return &Fence{role: r}
+}
+
+// Role returns the current role of the deployment.
+func (f *Fence) Role() Role {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	return f.role
+}
+
+func (f *Fence) set(r Role) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.role = r
+}
+
+// Service is the interface that provides replication methods. The token
+// authenticates the operator or deployment calling every method but Status.
+type Service interface {
+	// Status returns the role of the deployment and how far it has got in
+	// its own log and in the log of the primary.
+	Status(ctx context.Context) (Status, error)
+
+	// Export returns the state of the cargos and voyages logged after the
+	// cursor, which is empty to export from the start of the log.
+	Export(ctx context.Context, token, since string, limit int) (Batch, error)
+
+	// Apply stores a batch exported by the primary. Batches are applied in
+	// the order they were exported; batches already applied are skipped.
+	Apply(ctx context.Context, token string, b Batch) (Status, error)
+
+	// Digest returns fingerprints of the state of every cargo and voyage.
+	Digest(ctx context.Context, token string) (Digest, error)
+
+	// Compare reports where the state of the deployment diverges from the
+	// digest of another.
+	Compare(ctx context.Context, token string, d Digest) (Divergence, error)
+
+	// Demote makes the deployment a standby, refusing writes. The status
+	// returned holds the cursor the standby taking over is to be promoted
+	// at.
+	Demote(ctx context.Context, token string) (Status, error)
+
+	// Promote makes a standby the primary once it has applied the log of
+	// the primary up to the cursor. An empty cursor promotes it regardless,
+	// such as when the primary is lost.
+	Promote(ctx context.Context, token, until string) (Status, error)
+}
+
+const (
+	// defaultLimit is the number of entries exported in a batch unless a
+	// limit is given.
+	defaultLimit = 100
+
+	// maxLimit caps the number of entries exported in a batch.
+	maxLimit = 1000
+
+	// checkpoint names how far a standby has applied the log of the
+	// primary, among the projection checkpoints.
+	checkpoint = "replication"
+)
+
+type service struct {
+	fence       *Fence
+	log         shipping.ReplicationLogRepository
+	cargos      shipping.CargoRepository
+	events      shipping.HandlingEventRepository
+	voyages     shipping.VoyageRepository
+	checkpoints shipping.ProjectionCheckpointRepository
+	token       string
+
+	// mtx keeps batches from being applied concurrently, and the role from
+	// changing while they are.
+	mtx sync.Mutex
+}
+
+func (s *service) Status(ctx context.Context) (Status, error) {
+	return s.status()
+}
+
+func (s *service) Export(ctx context.Context, token, since string, limit int) (Batch, error) {
+	if err := s.authenticate(token); err != nil {
+		return Batch{}, err
+	}
+
+	seq, err := parseCursor(since)
+	if err != nil {
+		return Batch{}, err
+	}
+
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	b := Batch{
+		Since:   since,
+		Cursor:  since,
+		Cargos:  []*shipping.Cargo{},
+		Events:  []shipping.HandlingEvent{},
+		Voyages: []*shipping.Voyage{},
+	}
+
+	var (
+		ids     []shipping.TrackingID
+		voyages []shipping.VoyageNumber
+		seen    = make(map[shipping.ReplicationEntry]bool)
+	)
+	for _, e := range s.log.FindSince(seq, limit) {
+		b.Cursor = formatCursor(e.Sequence)
+
+		key := shipping.ReplicationEntry{Type: e.Type, ID: e.ID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		switch e.Type {
+		case shipping.ReplicatedCargo:
+			ids = append(ids, shipping.TrackingID(e.ID))
+		case shipping.ReplicatedVoyage:
+			voyages = append(voyages, shipping.VoyageNumber(e.ID))
+		}
+	}
+
+	for _, id := range ids {
+		c, err := s.cargos.Find(id)
+		if err != nil {
+			return Batch{}, err
+		}
+		b.Cargos = append(b.Cargos, c)
+	}
+
+	histories := s.events.QueryHandlingHistories(ids)
+	for _, id := range ids {
+		b.Events = append(b.Events, histories[id].HandlingEvents...)
+	}
+
+	for _, n := range voyages {
+		v, err := s.voyages.Find(n)
+		if err != nil {
+			return Batch{}, err
+		}
+		b.Voyages = append(b.Voyages, v)
+	}
+
+	return b, nil
+}
+
+func (s *service) Apply(ctx context.Context, token string, b Batch) (Status, error) {
+	if err := s.authenticate(token); err != nil {
+		return Status{}, err
+	}
+
+	since, err := parseCursor(b.Since)
+	if err != nil {
+		return Status{}, err
+	}
+	until, err := parseCursor(b.Cursor)
+	if err != nil {
+		return Status{}, err
+	}
+	if until < since {
+		return Status{}, ErrInvalidArgument
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.fence.Role() != Standby {
+		return Status{}, ErrNotStandby
+	}
+
+	applied, err := s.checkpoints.Find(checkpoint)
+	if err != nil {
+		return Status{}, err
+	}
+
+	// The state in a batch is as of when it was exported, so applying an
+	// earlier batch again would undo later ones.
+	if since < applied && until <= applied {
+		return s.status()
+	}
+	if since != applied {
+		return Status{}, ErrOutOfSequence
+	}
+
+	for _, v := range b.Voyages {
+		if err := s.voyages.Store(v); err != nil {
+			return Status{}, err
+		}
+	}
+
+	for _, c := range b.Cargos {
+		if err := s.cargos.Store(c); err != nil {
+			return Status{}, err
+		}
+	}
+
+	// Handling events are only ever added, so the ones already stored are
+	// left as they are.
+	var ids []shipping.TrackingID
+	for _, c := range b.Cargos {
+		ids = append(ids, c.TrackingID)
+	}
+	histories := s.events.QueryHandlingHistories(ids)
+	for _, e := range b.Events {
+		if !contains(histories[e.TrackingID], e) {
+			s.events.Store(e)
+		}
+	}
+
+	if err := s.checkpoints.Store(checkpoint, until); err != nil {
+		return Status{}, err
+	}
+
+	return s.status()
+}
+
+func (s *service) Digest(ctx context.Context, token string) (Digest, error) {
+	if err := s.authenticate(token); err != nil {
+		return Digest{}, err
+	}
+	return s.digest()
+}
+
+func (s *service) digest() (Digest, error) {
+	d := Digest{
+		Cargos:  make(map[string]string),
+		Voyages: make(map[string]string),
+	}
+
+	cargos := s.cargos.FindAll()
+
+	ids := make([]shipping.TrackingID, len(cargos))
+	for i, c := range cargos {
+		ids[i] = c.TrackingID
+	}
+
+	histories := s.events.QueryHandlingHistories(ids)
+	for _, c := range cargos {
+		f, err := fingerprint(c, histories[c.TrackingID].HandlingEvents)
+		if err != nil {
+			return Digest{}, err
+		}
+		d.Cargos[string(c.TrackingID)] = f
+	}
+
+	for _, v := range s.voyages.FindAll() {
+		f, err := fingerprint(v)
+		if err != nil {
+			return Digest{}, err
+		}
+		d.Voyages[string(v.VoyageNumber)] = f
+	}
+
+	return d, nil
+}
+
+func (s *service) Compare(ctx context.Context, token string, d Digest) (Divergence, error) {
+	if err := s.authenticate(token); err != nil {
+		return Divergence{}, err
+	}
+
+	own, err := s.digest()
+	if err != nil {
+		return Divergence{}, err
+	}
+
+	result := Divergence{Differences: []Difference{}}
+	result.Differences = append(result.Differences, compare(string(shipping.ReplicatedCargo), own.Cargos, d.Cargos)...)
+	result.Differences = append(result.Differences, compare(string(shipping.ReplicatedVoyage), own.Voyages, d.Voyages)...)
+	result.Diverged = len(result.Differences) > 0
+
+	return result, nil
+}
+
+func (s *service) Demote(ctx context.Context, token string) (Status, error) {
+	if err := s.authenticate(token); err != nil {
+		return Status{}, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.fence.Role() == Primary {
+		// The standby taking over logs what it applies in a log of its
+		// own, which the demoted deployment follows from the start.
+		if err := s.checkpoints.Store(checkpoint, 0); err != nil {
+			return Status{}, err
+		}
+		s.fence.set(Standby)
+	}
+
+	return s.status()
+}
+
+func (s *service) Promote(ctx context.Context, token, until string) (Status, error) {
+	if err := s.authenticate(token); err != nil {
+		return Status{}, err
+	}
+
+	seq, err := parseCursor(until)
+	if err != nil {
+		return Status{}, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.fence.Role() == Standby {
+		applied, err := s.checkpoints.Find(checkpoint)
+		if err != nil {
+			return Status{}, err
+		}
+		if applied < seq {
+			return Status{}, ErrNotCaughtUp
+		}
+		s.fence.set(Primary)
+	}
+
+	return s.status()
+}
+
+func (s *service) authenticate(token string) error {
+	if s.token == "" || !hmac.Equal([]byte(token), []byte(s.token)) {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+func (s *service) status() (Status, error) {
+	last, err := s.log.Last()
+	if err != nil {
+		return Status{}, err
+	}
+
+	result := Status{
+		Role:   string(s.fence.Role()),
+		Cursor: formatCursor(last),
+	}
+
+	if s.fence.Role() == Standby {
+		applied, err := s.checkpoints.Find(checkpoint)
+		if err != nil {
+			return Status{}, err
+		}
+		result.Applied = formatCursor(applied)
+	}
+
+	return result, nil
+}
+
+// NewService creates a replication service with necessary dependencies. The
+// cargo, handling event and voyage repositories are expected to append to
+// the log as they are stored to, including when applying batches, so that a
+// promoted standby has a log of its own to be followed. The token is shared
+// by the deployments and their operators; an empty token refuses all but
+// Status.
+func NewService(fence *Fence, log shipping.ReplicationLogRepository, cargos shipping.CargoRepository, events shipping.HandlingEventRepository, voyages shipping.VoyageRepository, checkpoints shipping.ProjectionCheckpointRepository, token string) Service {
+	return &service{
+		fence:       fence,
+		log:         log,
+		cargos:      cargos,
+		events:      events,
+		voyages:     voyages,
+		checkpoints: checkpoints,
+		token:       token,
+	}
+}
+
+// parseCursor returns the sequence number of the last entry read. Cursors
+// are exchanged as stored rather than encoded for clients, as deployments
+// may encode them with different keys.
+func parseCursor(cursor string) (uint64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	seq, err := strconv.ParseUint(cursor, 10, 64)
+	if err != nil {
+		return 0, shipping.ErrInvalidCursor
+	}
+	return seq, nil
+}
+
+func formatCursor(seq uint64) string {
+	if seq == 0 {
+		return ""
+	}
+	return strconv.FormatUint(seq, 10)
+}
+
+// contains returns whether the event is in the history.
+func contains(h shipping.HandlingHistory, e shipping.HandlingEvent) bool {
+	for _, o := range h.HandlingEvents {
+		if o.Activity == e.Activity && o.Completed.Equal(e.Completed) {
+			return true
+		}
+	}
+	return false
+}
+
+// fingerprint returns a hash of the JSON encoding of the values, which the
+// deployments replicating one another encode alike.
+func fingerprint(vs ...interface{}) (string, error) {
+	b, err := json.Marshal(vs)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// compare returns the differences between the fingerprints of own and
+// other, ordered by ID.
+func compare(typ string, own, other map[string]string) []Difference {
+	var result []Difference
+	for id, f := range other {
+		switch g, ok := own[id]; {
+		case !ok:
+			result = append(result, Difference{Type: typ, ID: id, Kind: "missing"})
+		case g != f:
+			result = append(result, Difference{Type: typ, ID: id, Kind: "differs"})
+		}
+	}
+	for id := range own {
+		if _, ok := other[id]; !ok {
+			result = append(result, Difference{Type: typ, ID: id, Kind: "unexpected"})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Status is a read model for the replication status of a deployment.
+type Status struct {
+	Role string `json:"role"`
+
+	// Cursor is the last entry in the log of the deployment.
+	Cursor string `json:"cursor"`
+
+	// Applied is the last entry applied from the log of the primary, for
+	// standbys.
+	Applied string `json:"applied,omitempty"`
+}
+
+// Batch is the state of the cargos and voyages logged between two cursors.
+// Aggregates are exchanged as the domain encodes them, so the deployments
+// replicating one another are to run the same version.
+type Batch struct {
+	Since   string                   `json:"since"`
+	Cursor  string                   `json:"cursor"`
+	Cargos  []*shipping.Cargo        `json:"cargos"`
+	Events  []shipping.HandlingEvent `json:"events"`
+	Voyages []*shipping.Voyage       `json:"voyages"`
+}
+
+// Digest is a read model for the fingerprints of the state of every cargo,
+// including its handling events, and voyage, by ID.
+type Digest struct {
+	Cargos  map[string]string `json:"cargos"`
+	Voyages map[string]string `json:"voyages"`
+}
+
+// Divergence is a read model for the divergence report of a deployment.
+type Divergence struct {
+	Diverged    bool         `json:"diverged"`
+	Differences []Difference `json:"differences"`
+}
+
+// Difference is a read model for a cargo or voyage whose state diverges.
+// Kind is missing if only the other deployment holds it, unexpected if only
+// this one does, and differs otherwise.
+type Difference struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+}
@@ -0,0 +1,214 @@
+package replication
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/inmem"
+)
+
+// token is shared by the deployments in the tests.
+const token = "replication"
+
+type deployment struct {
+	cargos  shipping.CargoRepository
+	events  shipping.HandlingEventRepository
+	voyages shipping.VoyageRepository
+	service Service
+}
+
+func newDeployment(role Role) *deployment {
+	log := inmem.NewReplicationLogRepository()
+
+	d := &deployment{
+		cargos:  NewCargoRepository(log, inmem.NewCargoRepository()),
+		events:  NewHandlingEventRepository(log, inmem.NewHandlingEventRepository()),
+		voyages: NewVoyageRepository(log, inmem.NewVoyageRepository()),
+	}
+	d.service = NewService(NewFence(role), log, d.cargos, d.events, d.voyages, inmem.NewProjectionCheckpointRepository(), token)
+
+	return d
+}
+
+func TestReplicate(t *testing.T) {
+	ctx := context.Background()
+
+	primary := newDeployment(Primary)
+	standby := newDeployment(Standby)
+
+	completed := time.Date(2016, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	primary.voyages.Store(&shipping.Voyage{VoyageNumber: "V100"})
+	primary.cargos.Store(&shipping.Cargo{TrackingID: "ABC", Origin: shipping.CNHKG})
+	primary.events.Store(shipping.HandlingEvent{
+		TrackingID: "ABC",
+		Activity:   shipping.HandlingActivity{Type: shipping.Receive, Location: shipping.CNHKG},
+		Completed:  completed,
+	})
+	primary.cargos.Store(&shipping.Cargo{TrackingID: "DEF", Origin: shipping.SESTO})
+
+	first, err := primary.service.Export(ctx, token, "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Voyages) != 1 || len(first.Cargos) != 1 || len(first.Events) != 1 {
+		t.Fatalf("first = %d voyages, %d cargos, %d events; want 1, 1, 1", len(first.Voyages), len(first.Cargos), len(first.Events))
+	}
+	if first.Cursor != "2" {
+		t.Errorf("first.Cursor = %q; want = %q", first.Cursor, "2")
+	}
+
+	if _, err := primary.service.Apply(ctx, token, first); err != ErrNotStandby {
+		t.Errorf("Apply to primary err = %v; want = %v", err, ErrNotStandby)
+	}
+
+	second, err := primary.service.Export(ctx, token, first.Cursor, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := standby.service.Apply(ctx, token, second); err != ErrOutOfSequence {
+		t.Errorf("Apply out of sequence err = %v; want = %v", err, ErrOutOfSequence)
+	}
+
+	for _, b := range []Batch{first, first, second} {
+		if _, err := standby.service.Apply(ctx, token, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := len(standby.events.QueryHandlingHistory("ABC").HandlingEvents); got != 1 {
+		t.Errorf("len(events) = %d; want = %d", got, 1)
+	}
+
+	st, err := standby.service.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Role != "standby" || st.Applied != second.Cursor {
+		t.Errorf("status = %+v; want standby applied at %q", st, second.Cursor)
+	}
+
+	digest, err := primary.service.Digest(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := standby.service.Compare(ctx, token, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Diverged {
+		t.Errorf("r.Diverged = true; differences = %+v", r.Differences)
+	}
+
+	primary.cargos.Store(&shipping.Cargo{TrackingID: "DEF", Origin: shipping.DEHAM})
+	primary.cargos.Store(&shipping.Cargo{TrackingID: "GHI"})
+	standby.cargos.Store(&shipping.Cargo{TrackingID: "JKL"})
+
+	if digest, err = primary.service.Digest(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	if r, err = standby.service.Compare(ctx, token, digest); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Difference{
+		{Type: "cargo", ID: "DEF", Kind: "differs"},
+		{Type: "cargo", ID: "GHI", Kind: "missing"},
+		{Type: "cargo", ID: "JKL", Kind: "unexpected"},
+	}
+	if !r.Diverged || len(r.Differences) != len(want) {
+		t.Fatalf("r = %+v; want = %+v", r, want)
+	}
+	for i := range want {
+		if r.Differences[i] != want[i] {
+			t.Errorf("r.Differences[%d] = %+v; want = %+v", i, r.Differences[i], want[i])
+		}
+	}
+}
+
+func TestFailover(t *testing.T) {
+	ctx := context.Background()
+
+	primary := newDeployment(Primary)
+	standby := newDeployment(Standby)
+
+	primary.cargos.Store(&shipping.Cargo{TrackingID: "ABC"})
+
+	st, err := primary.service.Demote(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Role != "standby" || st.Cursor != "1" {
+		t.Errorf("status = %+v; want standby at %q", st, "1")
+	}
+
+	if _, err := standby.service.Promote(ctx, token, st.Cursor); err != ErrNotCaughtUp {
+		t.Errorf("Promote err = %v; want = %v", err, ErrNotCaughtUp)
+	}
+
+	b, err := primary.service.Export(ctx, token, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := standby.service.Apply(ctx, token, b); err != nil {
+		t.Fatal(err)
+	}
+
+	promoted, err := standby.service.Promote(ctx, token, st.Cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if promoted.Role != "primary" {
+		t.Errorf("promoted.Role = %q; want = %q", promoted.Role, "primary")
+	}
+
+	// The former primary follows the log of the new one from the start.
+	b, err = standby.service.Export(ctx, token, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Cargos) != 1 {
+		t.Fatalf("len(b.Cargos) = %d; want = %d", len(b.Cargos), 1)
+	}
+	if _, err := primary.service.Apply(ctx, token, b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+
+	d := newDeployment(Primary)
+
+	for _, tt := range []string{"", "wrong"} {
+		if _, err := d.service.Export(ctx, tt, "", 0); err != ErrInvalidToken {
+			t.Errorf("Export err = %v; want = %v", err, ErrInvalidToken)
+		}
+		if _, err := d.service.Demote(ctx, tt); err != ErrInvalidToken {
+			t.Errorf("Demote err = %v; want = %v", err, ErrInvalidToken)
+		}
+	}
+
+	st, err := d.service.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Role != "primary" {
+		t.Errorf("st.Role = %q; want = %q", st.Role, "primary")
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	for _, s := range []string{"primary", "standby"} {
+		if r, err := ParseRole(s); err != nil || string(r) != s {
+			t.Errorf("ParseRole(%q) = %q, %v", s, r, err)
+		}
+	}
+	if _, err := ParseRole("secondary"); err == nil {
+		t.Error("ParseRole(secondary) err = nil")
+	}
+}
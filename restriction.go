@@ -0,0 +1,176 @@
+package shipping
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CommodityCode is a Harmonized System code classifying goods, such as
+// "0302" for fresh fish. Longer codes are more specific.
+type CommodityCode string
+
+// Covers returns whether the code covers another, more specific, code.
+func (c CommodityCode) Covers(other CommodityCode) bool {
+	return c == "" || strings.HasPrefix(string(other), string(c))
+}
+
+// DocumentType identifies a document accompanying a cargo, such as
+// "phytosanitary_certificate".
+type DocumentType string
+
+// CargoDeclaration holds what a cargo contains and the documents that come
+// with it.
+type CargoDeclaration struct {
+	Commodities []CommodityCode
+	Documents   []DocumentType
+}
+
+// HasDocument returns whether the declaration includes a document.
+func (d CargoDeclaration) HasDocument(t DocumentType) bool {
+	for _, doc := range d.Documents {
+		if doc == t {
+			return true
+		}
+	}
+	return false
+}
+
+// DocumentRequirement requires a document for the commodities covered by a
+// code, or for every cargo if the code is empty.
+type DocumentRequirement struct {
+	Commodity CommodityCode
+	Document  DocumentType
+}
+
+// ImportRestriction holds what a country prohibits the import of, and the
+// documents it requires for imports.
+type ImportRestriction struct {
+	Country    string
+	Prohibited []CommodityCode
+	Required   []DocumentRequirement
+}
+
+// ImportViolation is a reason a cargo may not be imported into a country,
+// with what is needed to resolve it.
+type ImportViolation struct {
+	Country   string
+	Commodity CommodityCode
+	Document  DocumentType
+}
+
+func (v ImportViolation) String() string {
+	if v.Document != "" {
+		if v.Commodity != "" {
+			return fmt.Sprintf("%s requires %s for %s", v.Country, v.Document, v.Commodity)
+		}
+		return fmt.Sprintf("%s requires %s", v.Country, v.Document)
+	}
+	return fmt.Sprintf("%s prohibits the import of %s", v.Country, v.Commodity)
+}
+
+// ImportViolations is returned as an error when a cargo violates the import
+// restrictions of its destination.
+type ImportViolations []ImportViolation
+
+func (vs ImportViolations) Error() string {
+	msgs := make([]string, len(vs))
+	for i, v := range vs {
+		msgs[i] = v.String()
+	}
+	return "import restrictions violated: " + strings.Join(msgs, "; ")
+}
+
+// Check returns the violations of the restriction by a declaration, if any.
+// A cargo with no commodities declared only needs the documents required
+// for every cargo.
+func (r ImportRestriction) Check(d CargoDeclaration) ImportViolations {
+	var result ImportViolations
+
+	for _, c := range d.Commodities {
+		for _, p := range r.Prohibited {
+			if p.Covers(c) {
+				result = append(result, ImportViolation{Country: r.Country, Commodity: c})
+			}
+		}
+	}
+
+	for _, req := range r.Required {
+		if d.HasDocument(req.Document) {
+			continue
+		}
+		if req.Commodity == "" {
+			result = append(result, ImportViolation{Country: r.Country, Document: req.Document})
+			continue
+		}
+		for _, c := range d.Commodities {
+			if req.Commodity.Covers(c) {
+				result = append(result, ImportViolation{Country: r.Country, Commodity: c, Document: req.Document})
+			}
+		}
+	}
+
+	return result
+}
+
+// ImportRestrictions holds the import restrictions by country.
+type ImportRestrictions map[string]ImportRestriction
+
+// Check returns the violations of the restrictions of the country of a
+// location by a declaration, as an error, or nil if there are none.
+func (rs ImportRestrictions) Check(destination UNLocode, d CargoDeclaration) error {
+	r, ok := rs[destination.Country()]
+	if !ok {
+		return nil
+	}
+	if vs := r.Check(d); len(vs) > 0 {
+		return vs
+	}
+	return nil
+}
+
+// ErrInvalidImportRestrictions is used when import restrictions cannot be
+// loaded.
+var ErrInvalidImportRestrictions = errors.New("invalid import restrictions")
+
+// LoadImportRestrictions reads import restrictions as JSON, by country:
+//
+//	{
+//	    "AU": {
+//	        "prohibited": ["0106"],
+//	        "documents": [{"commodity": "06", "document": "phytosanitary_certificate"}]
+//	    }
+//	}
+func LoadImportRestrictions(r io.Reader) (ImportRestrictions, error) {
+	var v map[string]struct {
+		Prohibited []CommodityCode `json:"prohibited"`
+		Documents  []struct {
+			Commodity CommodityCode `json:"commodity"`
+			Document  DocumentType  `json:"document"`
+		} `json:"documents"`
+	}
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		return nil, err
+	}
+
+	result := make(ImportRestrictions)
+	for country, rv := range v {
+		if len(country) != 2 {
+			return nil, ErrInvalidImportRestrictions
+		}
+		country = strings.ToUpper(country)
+
+		ir := ImportRestriction{Country: country, Prohibited: rv.Prohibited}
+		for _, d := range rv.Documents {
+			if d.Document == "" {
+				return nil, ErrInvalidImportRestrictions
+			}
+			ir.Required = append(ir.Required, DocumentRequirement{Commodity: d.Commodity, Document: d.Document})
+		}
+		result[country] = ir
+	}
+
+	return result, nil
+}
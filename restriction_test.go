@@ -0,0 +1,111 @@
+package shipping
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImportRestrictionsCheck(t *testing.T) {
+	rs := ImportRestrictions{
+		"AU": {
+			Country:    "AU",
+			Prohibited: []CommodityCode{"0106"},
+			Required: []DocumentRequirement{
+				{Commodity: "06", Document: "phytosanitary_certificate"},
+				{Document: "commercial_invoice"},
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name        string
+		destination UNLocode
+		d           CargoDeclaration
+		want        ImportViolations
+	}{
+		{
+			name:        "unrestricted",
+			destination: SESTO,
+			d:           CargoDeclaration{Commodities: []CommodityCode{"010600"}},
+		},
+		{
+			name:        "documented",
+			destination: AUMEL,
+			d: CargoDeclaration{
+				Commodities: []CommodityCode{"060311"},
+				Documents:   []DocumentType{"commercial_invoice", "phytosanitary_certificate"},
+			},
+		},
+		{
+			name:        "prohibited",
+			destination: AUMEL,
+			d: CargoDeclaration{
+				Commodities: []CommodityCode{"010600"},
+				Documents:   []DocumentType{"commercial_invoice"},
+			},
+			want: ImportViolations{{Country: "AU", Commodity: "010600"}},
+		},
+		{
+			name:        "undocumented",
+			destination: AUMEL,
+			d:           CargoDeclaration{Commodities: []CommodityCode{"060311"}},
+			want: ImportViolations{
+				{Country: "AU", Commodity: "060311", Document: "phytosanitary_certificate"},
+				{Country: "AU", Document: "commercial_invoice"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rs.Check(tt.destination, tt.d)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("err = %v; want none", err)
+				}
+				return
+			}
+
+			got, ok := err.(ImportViolations)
+			if !ok {
+				t.Fatalf("err = %v; want violations", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("len(got) = %d; want = %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got[%d] = %+v; want = %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadImportRestrictions(t *testing.T) {
+	rs, err := LoadImportRestrictions(strings.NewReader(`{
+		"au": {
+			"prohibited": ["0106"],
+			"documents": [{"commodity": "06", "document": "phytosanitary_certificate"}]
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, ok := rs["AU"]
+	if !ok {
+		t.Fatalf("rs = %+v; want restrictions for AU", rs)
+	}
+	if len(r.Prohibited) != 1 || len(r.Required) != 1 || r.Required[0].Document != "phytosanitary_certificate" {
+		t.Errorf("r = %+v", r)
+	}
+
+	for _, in := range []string{
+		`{"AUS": {}}`,
+		`{"AU": {"documents": [{"commodity": "06"}]}}`,
+		`[]`,
+	} {
+		if _, err := LoadImportRestrictions(strings.NewReader(in)); err == nil {
+			t.Errorf("LoadImportRestrictions(%q) should fail", in)
+		}
+	}
+}
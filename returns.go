@@ -0,0 +1,53 @@
+package shipping
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotDelivered is used when a cargo is rejected before it has been
+	// delivered at its destination.
+	ErrNotDelivered = errors.New("cargo is not delivered")
+
+	// ErrAlreadyRejected is used when a cargo has already been rejected.
+	ErrAlreadyRejected = errors.New("cargo is already rejected")
+)
+
+// IsDelivered returns whether the cargo has been unloaded or claimed at its
+// destination.
+func (c *Cargo) IsDelivered() bool {
+	if c.Delivery.IsUnloadedAtDestination {
+		return true
+	}
+	return c.Delivery.TransportStatus == Claimed &&
+		c.Delivery.LastKnownLocation == c.RouteSpecification.Destination
+}
+
+// IsRejected returns whether the cargo was rejected at its destination.
+func (c *Cargo) IsRejected() bool {
+	return c.ReturnedBy != ""
+}
+
+// Reject marks a delivered cargo as rejected at its destination and books a
+// return cargo back to its origin, to arrive before the given deadline.
+func (c *Cargo) Reject(id TrackingID, deadline time.Time) (*Cargo, error) {
+	if c.IsRejected() {
+		return nil, ErrAlreadyRejected
+	}
+	if !c.IsDelivered() {
+		return nil, ErrNotDelivered
+	}
+
+	r := NewCargo(id, RouteSpecification{
+		Origin:          c.RouteSpecification.Destination,
+		Destination:     c.Origin,
+		ArrivalDeadline: deadline,
+	})
+	r.ReturnOf = c.TrackingID
+	r.Equipment = c.Equipment
+
+	c.ReturnedBy = r.TrackingID
+
+	return r, nil
+}
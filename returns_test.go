@@ -0,0 +1,45 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReject(t *testing.T) {
+	deadline := time.Date(2009, time.March, 13, 0, 0, 0, 0, time.UTC)
+
+	c := NewCargo("ABC", RouteSpecification{Origin: SESTO, Destination: AUMEL})
+
+	if _, err := c.Reject("RET", deadline); err != ErrNotDelivered {
+		t.Errorf("err = %v; want = %v", err, ErrNotDelivered)
+	}
+
+	c.DeriveDeliveryProgress(HandlingHistory{HandlingEvents: []HandlingEvent{
+		{TrackingID: "ABC", Activity: HandlingActivity{Type: Claim, Location: AUMEL}},
+	}})
+
+	r, err := c.Reject("RET", deadline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Origin != AUMEL {
+		t.Errorf("r.Origin = %s; want = %s", r.Origin, AUMEL)
+	}
+	if r.RouteSpecification.Destination != SESTO {
+		t.Errorf("r.RouteSpecification.Destination = %s; want = %s", r.RouteSpecification.Destination, SESTO)
+	}
+	if !r.RouteSpecification.ArrivalDeadline.Equal(deadline) {
+		t.Errorf("r.RouteSpecification.ArrivalDeadline = %v; want = %v", r.RouteSpecification.ArrivalDeadline, deadline)
+	}
+	if r.ReturnOf != c.TrackingID {
+		t.Errorf("r.ReturnOf = %s; want = %s", r.ReturnOf, c.TrackingID)
+	}
+	if !c.IsRejected() || c.ReturnedBy != r.TrackingID {
+		t.Errorf("c.ReturnedBy = %s; want = %s", c.ReturnedBy, r.TrackingID)
+	}
+
+	if _, err := c.Reject("RET2", deadline); err != ErrAlreadyRejected {
+		t.Errorf("err = %v; want = %v", err, ErrAlreadyRejected)
+	}
+}
@@ -0,0 +1,126 @@
+package shipping
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNothingToRoll is used when rolling a cargo that has no leg left to
+	// be loaded onto.
+	ErrNothingToRoll = errors.New("no leg left to roll")
+
+	// ErrAlreadyOnVoyage is used when rolling a cargo onto the voyage it is
+	// already routed on.
+	ErrAlreadyOnVoyage = errors.New("cargo already routed on voyage")
+
+	// ErrNoLaterSailing is used when a leg cannot be moved to a later
+	// sailing that the cargo makes in time.
+	ErrNoLaterSailing = errors.New("no later sailing")
+)
+
+// Leg returns a leg on the voyage loading at a location and unloading at a
+// later port call, timed by the schedule. ErrPortNotCalled is returned if the
+// voyage does not sail between them.
+func (v *Voyage) Leg(from, to UNLocode) (Leg, error) {
+	sailings, err := v.Sailings(from, to)
+	if err != nil {
+		return Leg{}, err
+	}
+	return sailings[0], nil
+}
+
+// Sailings returns the legs on the voyage loading at a location and
+// unloading at a later port call, timed by the schedule, one for each time
+// the voyage departs the location. ErrPortNotCalled is returned if the
+// voyage does not sail between them.
+func (v *Voyage) Sailings(from, to UNLocode) ([]Leg, error) {
+	var result []Leg
+
+	ms := v.Schedule.CarrierMovements
+	for i := range ms {
+		if ms[i].DepartureLocation != from {
+			continue
+		}
+		for j := i; j < len(ms); j++ {
+			if ms[j].ArrivalLocation == to {
+				result = append(result, NewLeg(v.VoyageNumber, from, to, ms[i].DepartureTime, ms[j].ArrivalTime))
+				break
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, ErrPortNotCalled
+	}
+	return result, nil
+}
+
+// pending returns the leg awaiting confirmation by the carrier.
+func pending(l Leg) Leg {
+	l.Status = LegPending
+	l.CarrierReference = ""
+	return l
+}
+
+// NextSailingFunc returns a leg between the same ports as l on a later
+// sailing, loading no sooner than a time.
+type NextSailingFunc func(l Leg, after time.Time) (Leg, error)
+
+// Roll moves the cargo from the next leg it is to be loaded onto to a leg
+// between the same ports on another voyage, such as the next sailing of a
+// full or delayed voyage, and counts the rollover.
+//
+// Later legs on the same voyage are moved along with it. Any other later leg
+// the cargo would no longer make in time, given the connection times, is
+// replaced by the leg next returns. The legs rebuilt await confirmation by
+// the carrier.
+func (c *Cargo) Roll(v *Voyage, connections ConnectionTimes, next NextSailingFunc) error {
+	k, ok := c.Delivery.NextLeg()
+	if !ok {
+		return ErrNothingToRoll
+	}
+
+	old := c.Itinerary.Legs[k]
+	if old.VoyageNumber == v.VoyageNumber {
+		return ErrAlreadyOnVoyage
+	}
+
+	legs := append([]Leg(nil), c.Itinerary.Legs...)
+
+	leg, err := v.Leg(old.LoadLocation, old.UnloadLocation)
+	if err != nil {
+		return err
+	}
+	if k > 0 && leg.LoadTime.Sub(legs[k-1].UnloadTime) < connections.At(old.LoadLocation) {
+		return ErrConnectionTooShort
+	}
+	legs[k] = pending(leg)
+
+	for j := k + 1; j < len(legs); j++ {
+		if legs[j].VoyageNumber == old.VoyageNumber {
+			l, err := v.Leg(legs[j].LoadLocation, legs[j].UnloadLocation)
+			if err != nil {
+				return err
+			}
+			legs[j] = pending(l)
+			continue
+		}
+
+		ready := legs[j-1].UnloadTime.Add(connections.At(legs[j].LoadLocation))
+		if !legs[j].LoadTime.Before(ready) {
+			break
+		}
+
+		l, err := next(legs[j], ready)
+		if err != nil {
+			return err
+		}
+		legs[j] = pending(l)
+	}
+
+	c.AssignToRoute(Itinerary{Legs: legs})
+	c.Rollovers++
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVoyage_Leg(t *testing.T) {
+	var (
+		t1 = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		t2 = t1.Add(24 * time.Hour)
+		t3 = t2.Add(12 * time.Hour)
+		t4 = t3.Add(24 * time.Hour)
+	)
+
+	v := NewVoyage("V100", Schedule{CarrierMovements: []CarrierMovement{
+		{DepartureLocation: DEHAM, ArrivalLocation: SESTO, DepartureTime: t1, ArrivalTime: t2},
+		{DepartureLocation: SESTO, ArrivalLocation: FIHEL, DepartureTime: t3, ArrivalTime: t4},
+	}})
+
+	l, err := v.Leg(DEHAM, FIHEL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := NewLeg("V100", DEHAM, FIHEL, t1, t4); l != want {
+		t.Errorf("Leg() = %+v; want = %+v", l, want)
+	}
+
+	if _, err := v.Leg(FIHEL, DEHAM); err != ErrPortNotCalled {
+		t.Errorf("err = %v; want = %v", err, ErrPortNotCalled)
+	}
+}
+
+func TestCargo_Roll(t *testing.T) {
+	var (
+		t0  = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		day = 24 * time.Hour
+	)
+
+	c := NewCargo("ABC", RouteSpecification{
+		Origin:          CNHKG,
+		Destination:     FIHEL,
+		ArrivalDeadline: t0.Add(60 * day),
+	})
+	c.AssignToRoute(Itinerary{Legs: []Leg{
+		NewLeg("V100", CNHKG, DEHAM, t0, t0.Add(20*day)),
+		NewLeg("V100", DEHAM, SESTO, t0.Add(21*day), t0.Add(22*day)),
+		NewLeg("V200", SESTO, FIHEL, t0.Add(23*day), t0.Add(24*day)),
+	}})
+
+	v := NewVoyage("V101", Schedule{CarrierMovements: []CarrierMovement{
+		{DepartureLocation: CNHKG, ArrivalLocation: DEHAM, DepartureTime: t0.Add(7 * day), ArrivalTime: t0.Add(27 * day)},
+		{DepartureLocation: DEHAM, ArrivalLocation: SESTO, DepartureTime: t0.Add(28 * day), ArrivalTime: t0.Add(29 * day)},
+	}})
+
+	var asked time.Time
+	next := func(l Leg, after time.Time) (Leg, error) {
+		asked = after
+		return NewLeg("V201", l.LoadLocation, l.UnloadLocation, t0.Add(30*day), t0.Add(31*day)), nil
+	}
+
+	connections := ConnectionTimes{Default: 12 * time.Hour}
+
+	if err := c.Roll(&Voyage{VoyageNumber: "V100"}, connections, next); err != ErrAlreadyOnVoyage {
+		t.Errorf("err = %v; want = %v", err, ErrAlreadyOnVoyage)
+	}
+
+	if err := c.Roll(v, connections, next); err != nil {
+		t.Fatal(err)
+	}
+
+	legs := c.Itinerary.Legs
+	if legs[0].VoyageNumber != "V101" || legs[1].VoyageNumber != "V101" || legs[2].VoyageNumber != "V201" {
+		t.Errorf("legs = %+v; want V101, V101, V201", legs)
+	}
+	for _, l := range legs {
+		if l.Status != LegPending {
+			t.Errorf("leg on %s should await confirmation", l.VoyageNumber)
+		}
+	}
+	if want := t0.Add(29*day + 12*time.Hour); !asked.Equal(want) {
+		t.Errorf("next sailing after %v; want after %v", asked, want)
+	}
+	if c.Rollovers != 1 {
+		t.Errorf("Rollovers = %d; want = %d", c.Rollovers, 1)
+	}
+
+	// Once the cargo has arrived, there is nothing left to roll.
+	c.DeriveDeliveryProgress(HandlingHistory{HandlingEvents: []HandlingEvent{
+		{Activity: HandlingActivity{Type: Unload, Location: FIHEL, VoyageNumber: "V201"}, Completed: t0.Add(31 * day)},
+	}})
+
+	if err := c.Roll(v, connections, next); err != ErrNothingToRoll {
+		t.Errorf("err = %v; want = %v", err, ErrNothingToRoll)
+	}
+}
@@ -0,0 +1,31 @@
+package routing
+
+import (
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type connectionService struct {
+	connections shipping.ConnectionTimes
+	next        shipping.RoutingService
+}
+
+func (s connectionService) FetchRoutesForSpecification(rs shipping.RouteSpecification) []shipping.Itinerary {
+	itineraries := s.next.FetchRoutesForSpecification(rs)
+
+	result := make([]shipping.Itinerary, 0, len(itineraries))
+	for _, i := range itineraries {
+		if i.ValidateConnections(s.connections) == nil {
+			result = append(result, i)
+		}
+	}
+
+	return result
+}
+
+// NewConnectionMiddleware returns a middleware that discards routes not
+// leaving the minimum connection time at transshipment ports.
+func NewConnectionMiddleware(c shipping.ConnectionTimes) ServiceMiddleware {
+	return func(next shipping.RoutingService) shipping.RoutingService {
+		return connectionService{c, next}
+	}
+}
@@ -0,0 +1,57 @@
+package routing
+
+import (
+	"sort"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type localService struct {
+	voyages shipping.VoyageRepository
+}
+
+func (s localService) FetchRoutesForSpecification(rs shipping.RouteSpecification) []shipping.Itinerary {
+	var result []shipping.Itinerary
+
+	for _, v := range s.voyages.FindCallingAt(rs.Origin) {
+		if l, err := v.Leg(rs.Origin, rs.Destination); err == nil {
+			result = append(result, shipping.Itinerary{Legs: []shipping.Leg{l}})
+			continue
+		}
+
+		// Transship at one of the later ports of the voyage.
+		for _, c := range v.Schedule.PortCalls() {
+			if c.Location == rs.Origin {
+				continue
+			}
+			first, err := v.Leg(rs.Origin, c.Location)
+			if err != nil {
+				continue
+			}
+			for _, w := range s.voyages.FindCallingAt(c.Location) {
+				if w.VoyageNumber == v.VoyageNumber {
+					continue
+				}
+				second, err := w.Leg(c.Location, rs.Destination)
+				if err != nil || second.LoadTime.Before(first.UnloadTime) {
+					continue
+				}
+				result = append(result, shipping.Itinerary{Legs: []shipping.Leg{first, second}})
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].FinalArrivalTime().Before(result[j].FinalArrivalTime())
+	})
+
+	return result
+}
+
+// NewLocalService returns a routing service finding routes on the voyages of
+// the repository, directly or with a single transshipment, earliest arrival
+// first. It stands in for the routing service during development, when no
+// routing service is running.
+func NewLocalService(voyages shipping.VoyageRepository) shipping.RoutingService {
+	return localService{voyages}
+}
@@ -0,0 +1,41 @@
+package routing
+
+import (
+	"testing"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/inmem"
+)
+
+func TestLocalService(t *testing.T) {
+	s := NewLocalService(inmem.NewVoyageRepository())
+
+	itineraries := s.FetchRoutesForSpecification(shipping.RouteSpecification{
+		Origin:      shipping.CNHKG,
+		Destination: shipping.NLRTM,
+	})
+	if len(itineraries) == 0 {
+		t.Fatal("no route found")
+	}
+
+	legs := itineraries[0].Legs
+	if len(legs) != 2 || legs[0].VoyageNumber != "V100" || legs[1].VoyageNumber != "V300" {
+		t.Errorf("legs = %+v; want V100 transshipping to V300 at %s", legs, shipping.JNTKO)
+	}
+	if legs[1].LoadTime.Before(legs[0].UnloadTime) {
+		t.Errorf("second leg loads before the first one unloads")
+	}
+
+	for _, it := range itineraries[1:] {
+		if it.FinalArrivalTime().Before(itineraries[0].FinalArrivalTime()) {
+			t.Errorf("routes should be ordered by arrival")
+		}
+	}
+
+	if got := s.FetchRoutesForSpecification(shipping.RouteSpecification{
+		Origin:      shipping.SESTO,
+		Destination: "XXNOP",
+	}); len(got) != 0 {
+		t.Errorf("got %d routes; want none", len(got))
+	}
+}
@@ -0,0 +1,46 @@
+package routing
+
+import (
+	"sort"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type portStatusService struct {
+	statuses shipping.PortStatusRepository
+	next     shipping.RoutingService
+}
+
+func (s portStatusService) FetchRoutesForSpecification(rs shipping.RouteSpecification) []shipping.Itinerary {
+	itineraries := s.next.FetchRoutesForSpecification(rs)
+
+	p := shipping.NewPortStatuses(s.statuses.FindAll(), time.Now())
+	if len(p) == 0 {
+		return itineraries
+	}
+
+	result := make([]shipping.Itinerary, 0, len(itineraries))
+	for _, i := range itineraries {
+		if !p.IsClosed(i) {
+			result = append(result, i)
+		}
+	}
+
+	// Penalize congestion by moving the routes expected to wait the longest
+	// to the back.
+	sort.SliceStable(result, func(i, j int) bool {
+		return p.Delay(result[i]) < p.Delay(result[j])
+	})
+
+	return result
+}
+
+// NewPortStatusMiddleware returns a middleware that discards routes calling at
+// closed ports, and ranks the remaining routes by the expected delay at
+// congested ports.
+func NewPortStatusMiddleware(statuses shipping.PortStatusRepository) ServiceMiddleware {
+	return func(next shipping.RoutingService) shipping.RoutingService {
+		return portStatusService{statuses, next}
+	}
+}
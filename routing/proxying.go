@@ -31,17 +31,19 @@ func (s proxyService) FetchRoutesForSpecification(rs shipping.RouteSpecification
 
 	resp := response.(fetchRoutesResponse)
 
-	var itineraries []shipping.Itinerary
+	// Legs are built as in the canonical encodings, with times in UTC, so
+	// that they are stored and sent on exactly as they would be decoded.
+	itineraries := []shipping.Itinerary{}
 	for _, r := range resp.Paths {
 		var legs []shipping.Leg
 		for _, e := range r.Edges {
-			legs = append(legs, shipping.Leg{
-				VoyageNumber:   shipping.VoyageNumber(e.Voyage),
-				LoadLocation:   shipping.UNLocode(e.Origin),
-				UnloadLocation: shipping.UNLocode(e.Destination),
-				LoadTime:       e.Departure,
-				UnloadTime:     e.Arrival,
-			})
+			legs = append(legs, shipping.NewLeg(
+				shipping.VoyageNumber(e.Voyage),
+				shipping.UNLocode(e.Origin),
+				shipping.UNLocode(e.Destination),
+				e.Departure.UTC(),
+				e.Arrival.UTC(),
+			))
 		}
 
 		itineraries = append(itineraries, shipping.Itinerary{Legs: legs})
@@ -53,11 +55,12 @@ func (s proxyService) FetchRoutesForSpecification(rs shipping.RouteSpecification
 // ServiceMiddleware defines a middleware for a routing service.
 type ServiceMiddleware func(shipping.RoutingService) shipping.RoutingService
 
-// NewProxyingMiddleware returns a new instance of a proxying middleware.
-func NewProxyingMiddleware(ctx context.Context, proxyURL string) ServiceMiddleware {
+// NewProxyingMiddleware returns a new instance of a proxying middleware. The
+// options configure the HTTP client, e.g. its transport.
+func NewProxyingMiddleware(ctx context.Context, proxyURL string, opts ...kithttp.ClientOption) ServiceMiddleware {
 	return func(next shipping.RoutingService) shipping.RoutingService {
 		var e endpoint.Endpoint
-		e = makeFetchRoutesEndpoint(ctx, proxyURL)
+		e = makeFetchRoutesEndpoint(ctx, proxyURL, opts...)
 		e = circuitbreaker.Hystrix("fetch-routes")(e)
 		return proxyService{ctx, e, next}
 	}
@@ -80,7 +83,7 @@ type fetchRoutesResponse struct {
 	} `json:"paths"`
 }
 
-func makeFetchRoutesEndpoint(ctx context.Context, instance string) endpoint.Endpoint {
+func makeFetchRoutesEndpoint(ctx context.Context, instance string, opts ...kithttp.ClientOption) endpoint.Endpoint {
 	u, err := url.Parse(instance)
 	if err != nil {
 		panic(err)
@@ -92,6 +95,7 @@ func makeFetchRoutesEndpoint(ctx context.Context, instance string) endpoint.Endp
 		"GET", u,
 		encodeFetchRoutesRequest,
 		decodeFetchRoutesResponse,
+		opts...,
 	).Endpoint()
 }
 
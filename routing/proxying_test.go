@@ -0,0 +1,156 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// The contract with the routing service is recorded as fixtures in
+// testdata/contract. Each fixture holds a request made by the proxy, the
+// response of the routing service and the itineraries it should result in,
+// in their canonical JSON encoding.
+//
+// Set ROUTING_CONTRACT_URL to also check the recorded requests against a
+// running routing service.
+
+type contract struct {
+	Description string `json:"description"`
+	Request     struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"request"`
+	Response struct {
+		Status int             `json:"status"`
+		Body   json.RawMessage `json:"body"`
+	} `json:"response"`
+	Itineraries []shipping.Itinerary `json:"itineraries"`
+}
+
+func (c contract) spec() shipping.RouteSpecification {
+	return shipping.RouteSpecification{
+		Origin:      shipping.UNLocode(c.Request.From),
+		Destination: shipping.UNLocode(c.Request.To),
+	}
+}
+
+func loadContracts(t *testing.T) map[string]contract {
+	files, err := filepath.Glob(filepath.Join("testdata", "contract", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no contracts found")
+	}
+
+	contracts := make(map[string]contract)
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c contract
+		if err := json.Unmarshal(b, &c); err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+		contracts[filepath.Base(f)] = c
+	}
+	return contracts
+}
+
+func TestProxyContract(t *testing.T) {
+	for name, c := range loadContracts(t) {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "GET" {
+					t.Errorf("method = %s; want = %s", r.Method, "GET")
+				}
+				if r.URL.Path != "/paths" {
+					t.Errorf("path = %s; want = %s", r.URL.Path, "/paths")
+				}
+				q := r.URL.Query()
+				if got := q.Get("from"); got != c.Request.From {
+					t.Errorf("from = %q; want = %q", got, c.Request.From)
+				}
+				if got := q.Get("to"); got != c.Request.To {
+					t.Errorf("to = %q; want = %q", got, c.Request.To)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(c.Response.Status)
+				w.Write(c.Response.Body)
+			}))
+			defer srv.Close()
+
+			rs := NewProxyingMiddleware(context.Background(), srv.URL)(nil)
+
+			got := rs.FetchRoutesForSpecification(c.spec())
+
+			if !reflect.DeepEqual(got, c.Itineraries) {
+				t.Errorf("%s: itineraries = %v; want = %v", c.Description, got, c.Itineraries)
+			}
+		})
+	}
+}
+
+func TestProxyContract_QueryEncoding(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/paths" {
+			t.Errorf("path = %s; want = %s", r.URL.Path, "/v2/paths")
+		}
+		if got := r.URL.Query().Get("key"); got != "abc" {
+			t.Errorf("key = %q; want = %q", got, "abc")
+		}
+		if got := r.URL.RawQuery; got != "from=SESTO&key=abc&to=CNHKG" {
+			t.Errorf("query = %q; want = %q", got, "from=SESTO&key=abc&to=CNHKG")
+		}
+		w.Write([]byte(`{"paths":[]}`))
+	}))
+	defer srv.Close()
+
+	rs := NewProxyingMiddleware(context.Background(), srv.URL+"/v2/paths?key=abc")(nil)
+
+	rs.FetchRoutesForSpecification(shipping.RouteSpecification{
+		Origin:      shipping.SESTO,
+		Destination: shipping.CNHKG,
+	})
+}
+
+func TestProxyContract_Live(t *testing.T) {
+	u := os.Getenv("ROUTING_CONTRACT_URL")
+	if u == "" {
+		t.Skip("ROUTING_CONTRACT_URL not set")
+	}
+
+	for name, c := range loadContracts(t) {
+		t.Run(name, func(t *testing.T) {
+			rs := NewProxyingMiddleware(context.Background(), u)(nil)
+
+			for _, it := range rs.FetchRoutesForSpecification(c.spec()) {
+				if len(it.Legs) == 0 {
+					t.Errorf("itinerary without legs")
+					continue
+				}
+				if it.Legs[0].LoadLocation != c.spec().Origin {
+					t.Errorf("itinerary starts in %s; want = %s", it.Legs[0].LoadLocation, c.spec().Origin)
+				}
+				if last := it.Legs[len(it.Legs)-1]; last.UnloadLocation != c.spec().Destination {
+					t.Errorf("itinerary ends in %s; want = %s", last.UnloadLocation, c.spec().Destination)
+				}
+				for _, l := range it.Legs {
+					if l.VoyageNumber == "" || l.LoadTime.IsZero() || l.UnloadTime.IsZero() {
+						t.Errorf("incomplete leg %+v", l)
+					}
+				}
+			}
+		})
+	}
+}
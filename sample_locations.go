@@ -15,13 +15,13 @@ var (
 
 // Sample locations.
 var (
-	Stockholm = &Location{SESTO, "Stockholm"}
-	Melbourne = &Location{AUMEL, "Melbourne"}
-	Hongkong  = &Location{CNHKG, "Hongkong"}
-	NewYork   = &Location{USNYC, "New York"}
-	Chicago   = &Location{USCHI, "Chicago"}
-	Tokyo     = &Location{JNTKO, "Tokyo"}
-	Hamburg   = &Location{DEHAM, "Hamburg"}
-	Rotterdam = &Location{NLRTM, "Rotterdam"}
-	Helsinki  = &Location{FIHEL, "Helsinki"}
+	Stockholm = &Location{SESTO, "Stockholm", "Europe/Stockholm", Coordinates{59.33, 18.07}}
+	Melbourne = &Location{AUMEL, "Melbourne", "Australia/Melbourne", Coordinates{-37.81, 144.96}}
+	Hongkong  = &Location{CNHKG, "Hongkong", "Asia/Hong_Kong", Coordinates{22.30, 114.17}}
+	NewYork   = &Location{USNYC, "New York", "America/New_York", Coordinates{40.71, -74.01}}
+	Chicago   = &Location{USCHI, "Chicago", "America/Chicago", Coordinates{41.88, -87.63}}
+	Tokyo     = &Location{JNTKO, "Tokyo", "Asia/Tokyo", Coordinates{35.65, 139.77}}
+	Hamburg   = &Location{DEHAM, "Hamburg", "Europe/Berlin", Coordinates{53.55, 9.99}}
+	Rotterdam = &Location{NLRTM, "Rotterdam", "Europe/Amsterdam", Coordinates{51.92, 4.48}}
+	Helsinki  = &Location{FIHEL, "Helsinki", "Europe/Helsinki", Coordinates{60.17, 24.94}}
 )
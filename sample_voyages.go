@@ -1,28 +1,39 @@
 package shipping
 
+import "time"
+
+// sampleScheduleStart is the fixed day the sample schedules are anchored to.
+var sampleScheduleStart = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+// sampleTime returns the time a number of days and hours into the sample
+// schedules.
+func sampleTime(days, hours int) time.Time {
+	return sampleScheduleStart.Add(time.Duration(days*24+hours) * time.Hour)
+}
+
 // A set of sample voyages.
 var (
 	V100 = NewVoyage("V100", Schedule{
 		[]CarrierMovement{
-			{DepartureLocation: CNHKG, ArrivalLocation: JNTKO},
-			{DepartureLocation: JNTKO, ArrivalLocation: USNYC},
+			{DepartureLocation: CNHKG, ArrivalLocation: JNTKO, DepartureTime: sampleTime(1, 12), ArrivalTime: sampleTime(4, 8)},
+			{DepartureLocation: JNTKO, ArrivalLocation: USNYC, DepartureTime: sampleTime(5, 10), ArrivalTime: sampleTime(16, 6)},
 		},
 	})
 
 	V300 = NewVoyage("V300", Schedule{
 		[]CarrierMovement{
-			{DepartureLocation: JNTKO, ArrivalLocation: NLRTM},
-			{DepartureLocation: NLRTM, ArrivalLocation: DEHAM},
-			{DepartureLocation: DEHAM, ArrivalLocation: AUMEL},
-			{DepartureLocation: AUMEL, ArrivalLocation: JNTKO},
+			{DepartureLocation: JNTKO, ArrivalLocation: NLRTM, DepartureTime: sampleTime(6, 8), ArrivalTime: sampleTime(28, 10)},
+			{DepartureLocation: NLRTM, ArrivalLocation: DEHAM, DepartureTime: sampleTime(29, 6), ArrivalTime: sampleTime(30, 14)},
+			{DepartureLocation: DEHAM, ArrivalLocation: AUMEL, DepartureTime: sampleTime(31, 20), ArrivalTime: sampleTime(58, 2)},
+			{DepartureLocation: AUMEL, ArrivalLocation: JNTKO, DepartureTime: sampleTime(59, 10), ArrivalTime: sampleTime(71, 4)},
 		},
 	})
 
 	V400 = NewVoyage("V400", Schedule{
 		[]CarrierMovement{
-			{DepartureLocation: DEHAM, ArrivalLocation: SESTO},
-			{DepartureLocation: SESTO, ArrivalLocation: FIHEL},
-			{DepartureLocation: FIHEL, ArrivalLocation: DEHAM},
+			{DepartureLocation: DEHAM, ArrivalLocation: SESTO, DepartureTime: sampleTime(2, 10), ArrivalTime: sampleTime(3, 18)},
+			{DepartureLocation: SESTO, ArrivalLocation: FIHEL, DepartureTime: sampleTime(4, 20), ArrivalTime: sampleTime(5, 8)},
+			{DepartureLocation: FIHEL, ArrivalLocation: DEHAM, DepartureTime: sampleTime(5, 20), ArrivalTime: sampleTime(7, 6)},
 		},
 	})
 )
@@ -36,3 +47,27 @@ var (
 	V0301S = NewVoyage("0301S", Schedule{[]CarrierMovement{}})
 	V0400S = NewVoyage("0400S", Schedule{[]CarrierMovement{}})
 )
+
+// A set of sample service strings, grouping the sample voyages.
+var (
+	TP1 = &ServiceString{
+		Code:      "TP1",
+		Name:      "Transpacific 1",
+		TradeLane: "Transpacific",
+		Voyages:   []VoyageNumber{"V100"},
+	}
+
+	AE1 = &ServiceString{
+		Code:      "AE1",
+		Name:      "Asia-Europe 1",
+		TradeLane: "Asia-Europe",
+		Voyages:   []VoyageNumber{"V300"},
+	}
+
+	NE1 = &ServiceString{
+		Code:      "NE1",
+		Name:      "Baltic Feeder",
+		TradeLane: "Intra-Europe",
+		Voyages:   []VoyageNumber{"V400"},
+	}
+)
@@ -0,0 +1,59 @@
+package scheduling
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) Departures(ctx context.Context, locode string, from, to time.Time) ([]Movement, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "departures").Add(1)
+		s.requestLatency.With("method", "departures").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Departures(ctx, locode, from, to)
+}
+
+func (s *instrumentingService) Arrivals(ctx context.Context, locode string, from, to time.Time) ([]Movement, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "arrivals").Add(1)
+		s.requestLatency.With("method", "arrivals").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Arrivals(ctx, locode, from, to)
+}
+
+func (s *instrumentingService) Rotation(ctx context.Context, voyageNumber string) (Voyage, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "rotation").Add(1)
+		s.requestLatency.With("method", "rotation").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Rotation(ctx, voyageNumber)
+}
+
+func (s *instrumentingService) ServiceStrings(ctx context.Context, lane string) []ServiceString {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "service_strings").Add(1)
+		s.requestLatency.With("method", "service_strings").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.ServiceStrings(ctx, lane)
+}
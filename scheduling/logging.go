@@ -0,0 +1,75 @@
+package scheduling
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) Departures(ctx context.Context, locode string, from, to time.Time) (m []Movement, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "departures",
+			"request_id", correlation.FromContext(ctx),
+			"location", locode,
+			"from", from,
+			"to", to,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Departures(ctx, locode, from, to)
+}
+
+func (s *loggingService) Arrivals(ctx context.Context, locode string, from, to time.Time) (m []Movement, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "arrivals",
+			"request_id", correlation.FromContext(ctx),
+			"location", locode,
+			"from", from,
+			"to", to,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Arrivals(ctx, locode, from, to)
+}
+
+func (s *loggingService) Rotation(ctx context.Context, voyageNumber string) (v Voyage, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "rotation",
+			"request_id", correlation.FromContext(ctx),
+			"voyage_number", voyageNumber,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Rotation(ctx, voyageNumber)
+}
+
+func (s *loggingService) ServiceStrings(ctx context.Context, lane string) []ServiceString {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "service_strings",
+			"request_id", correlation.FromContext(ctx),
+			"trade_lane", lane,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.ServiceStrings(ctx, lane)
+}
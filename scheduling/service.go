@@ -0,0 +1,268 @@
+// Package scheduling provides the use-case of browsing published voyage
+// schedules. Used by the schedule picker when booking cargos.
+package scheduling
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// defaultWindow is how far ahead departures and arrivals are listed unless
+// an end time is given.
+const defaultWindow = 14 * 24 * time.Hour
+
+// Service is the interface that provides schedule queries. Times are given
+// in the local time of the port.
+type Service interface {
+	// Departures returns the carrier movements departing from a location
+	// within [from, to), in order of departure.
+	Departures(ctx context.Context, locode string, from, to time.Time) ([]Movement, error)
+
+	// Arrivals returns the carrier movements arriving at a location within
+	// [from, to), in order of arrival.
+	Arrivals(ctx context.Context, locode string, from, to time.Time) ([]Movement, error)
+
+	// Rotation returns the port calls of a voyage, with the actual times
+	// recorded next to the scheduled ones.
+	Rotation(ctx context.Context, voyageNumber string) (Voyage, error)
+
+	// ServiceStrings returns the service strings, optionally restricted to
+	// a trade lane.
+	ServiceStrings(ctx context.Context, lane string) []ServiceString
+}
+
+type service struct {
+	voyages   shipping.VoyageRepository
+	locations shipping.LocationRepository
+	services  shipping.ServiceStringRepository
+}
+
+func (s *service) Departures(ctx context.Context, locode string, from, to time.Time) ([]Movement, error) {
+	return s.movements(shipping.UNLocode(locode), from, to, func(m shipping.CarrierMovement) (shipping.UNLocode, time.Time) {
+		return m.DepartureLocation, m.DepartureTime
+	})
+}
+
+func (s *service) Arrivals(ctx context.Context, locode string, from, to time.Time) ([]Movement, error) {
+	return s.movements(shipping.UNLocode(locode), from, to, func(m shipping.CarrierMovement) (shipping.UNLocode, time.Time) {
+		return m.ArrivalLocation, m.ArrivalTime
+	})
+}
+
+// movements returns the carrier movements for which at returns the given
+// location and a time within the window, ordered by that time.
+func (s *service) movements(locode shipping.UNLocode, from, to time.Time, at func(shipping.CarrierMovement) (shipping.UNLocode, time.Time)) ([]Movement, error) {
+	if locode == "" {
+		return nil, ErrInvalidArgument
+	}
+
+	if from.IsZero() {
+		from = time.Now()
+	}
+	if to.IsZero() {
+		to = from.Add(defaultWindow)
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidArgument
+	}
+
+	if _, err := s.locations.Find(locode); err != nil {
+		return nil, err
+	}
+
+	type match struct {
+		number shipping.VoyageNumber
+		m      shipping.CarrierMovement
+		t      time.Time
+	}
+
+	var matches []match
+	for _, v := range s.voyages.FindCallingAt(locode) {
+		for _, m := range v.Schedule.CarrierMovements {
+			l, t := at(m)
+			if l != locode || t.Before(from) || !t.Before(to) {
+				continue
+			}
+			matches = append(matches, match{number: v.VoyageNumber, m: m, t: t})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].t.Before(matches[j].t)
+	})
+
+	var (
+		zone     = s.zones()
+		services = shipping.ServiceStrings(s.services.FindAll())
+	)
+
+	result := make([]Movement, 0, len(matches))
+	for _, x := range matches {
+		m := Movement{
+			VoyageNumber:  string(x.number),
+			From:          string(x.m.DepartureLocation),
+			To:            string(x.m.ArrivalLocation),
+			DepartureTime: x.m.DepartureTime.In(zone(x.m.DepartureLocation)),
+			ArrivalTime:   x.m.ArrivalTime.In(zone(x.m.ArrivalLocation)),
+		}
+		if ss, ok := services.Find(x.number); ok {
+			m.ServiceString = string(ss.Code)
+			m.TradeLane = string(ss.TradeLane)
+		}
+		result = append(result, m)
+	}
+
+	return result, nil
+}
+
+func (s *service) Rotation(ctx context.Context, voyageNumber string) (Voyage, error) {
+	if voyageNumber == "" {
+		return Voyage{}, ErrInvalidArgument
+	}
+
+	v, err := s.voyages.Find(shipping.VoyageNumber(voyageNumber))
+	if err != nil {
+		return Voyage{}, err
+	}
+
+	zone := s.zones()
+
+	calls := v.Schedule.PortCalls()
+
+	result := Voyage{
+		VoyageNumber: string(v.VoyageNumber),
+		PortCalls:    make([]PortCall, 0, len(calls)),
+	}
+	if ss, ok := shipping.ServiceStrings(s.services.FindAll()).Find(v.VoyageNumber); ok {
+		result.ServiceString = string(ss.Code)
+		result.TradeLane = string(ss.TradeLane)
+	}
+	for _, c := range calls {
+		pc := PortCall{Location: string(c.Location)}
+		if !c.ArrivalTime.IsZero() {
+			t := c.ArrivalTime.In(zone(c.Location))
+			pc.ArrivalTime = &t
+		}
+		if !c.DepartureTime.IsZero() {
+			t := c.DepartureTime.In(zone(c.Location))
+			pc.DepartureTime = &t
+		}
+		if !c.ActualArrivalTime.IsZero() {
+			t := c.ActualArrivalTime.In(zone(c.Location))
+			pc.ActualArrivalTime = &t
+			pc.ArrivalDelay = c.ArrivalDelay().String()
+		}
+		if !c.ActualDepartureTime.IsZero() {
+			t := c.ActualDepartureTime.In(zone(c.Location))
+			pc.ActualDepartureTime = &t
+			pc.DepartureDelay = c.DepartureDelay().String()
+		}
+		result.PortCalls = append(result.PortCalls, pc)
+	}
+	if d := v.Schedule.Delay(); d != 0 {
+		result.Delay = d.String()
+	}
+
+	return result, nil
+}
+
+func (s *service) ServiceStrings(ctx context.Context, lane string) []ServiceString {
+	result := make([]ServiceString, 0)
+	for _, ss := range s.services.FindAll() {
+		if lane != "" && string(ss.TradeLane) != lane {
+			continue
+		}
+
+		voyages := make([]string, len(ss.Voyages))
+		for i, v := range ss.Voyages {
+			voyages[i] = string(v)
+		}
+
+		result = append(result, ServiceString{
+			Code:      string(ss.Code),
+			Name:      ss.Name,
+			TradeLane: string(ss.TradeLane),
+			Voyages:   voyages,
+		})
+	}
+	return result
+}
+
+// zones returns a function looking up the time zone of a location. Zones
+// are cached for the lifetime of the returned function, and fall back to UTC
+// when unknown.
+func (s *service) zones() func(shipping.UNLocode) *time.Location {
+	cache := make(map[shipping.UNLocode]*time.Location)
+
+	return func(locode shipping.UNLocode) *time.Location {
+		if z, ok := cache[locode]; ok {
+			return z
+		}
+
+		z := time.UTC
+		if l, err := s.locations.Find(locode); err == nil && l.TimeZone != "" {
+			if tz, err := time.LoadLocation(l.TimeZone); err == nil {
+				z = tz
+			}
+		}
+
+		cache[locode] = z
+		return z
+	}
+}
+
+// NewService returns a new instance of the default Service.
+func NewService(voyages shipping.VoyageRepository, locations shipping.LocationRepository, services shipping.ServiceStringRepository) Service {
+	return &service{
+		voyages:   voyages,
+		locations: locations,
+		services:  services,
+	}
+}
+
+// Movement is a read model for departure and arrival boards.
+type Movement struct {
+	VoyageNumber  string    `json:"voyage_number"`
+	From          string    `json:"from"`
+	To            string    `json:"to"`
+	DepartureTime time.Time `json:"departure_time"`
+	ArrivalTime   time.Time `json:"arrival_time"`
+	ServiceString string    `json:"service_string,omitempty"`
+	TradeLane     string    `json:"trade_lane,omitempty"`
+}
+
+// Voyage is a read model for voyage rotations.
+type Voyage struct {
+	VoyageNumber  string     `json:"voyage_number"`
+	ServiceString string     `json:"service_string,omitempty"`
+	TradeLane     string     `json:"trade_lane,omitempty"`
+	Delay         string     `json:"delay,omitempty"`
+	PortCalls     []PortCall `json:"port_calls"`
+}
+
+// ServiceString is a read model for service strings.
+type ServiceString struct {
+	Code      string   `json:"code"`
+	Name      string   `json:"name"`
+	TradeLane string   `json:"trade_lane"`
+	Voyages   []string `json:"voyages"`
+}
+
+// PortCall is a read model for a visit of a voyage to a location. Delays
+// are negative when the voyage was early.
+type PortCall struct {
+	Location            string     `json:"location"`
+	ArrivalTime         *time.Time `json:"arrival_time,omitempty"`
+	DepartureTime       *time.Time `json:"departure_time,omitempty"`
+	ActualArrivalTime   *time.Time `json:"actual_arrival_time,omitempty"`
+	ActualDepartureTime *time.Time `json:"actual_departure_time,omitempty"`
+	ArrivalDelay        string     `json:"arrival_delay,omitempty"`
+	DepartureDelay      string     `json:"departure_delay,omitempty"`
+}
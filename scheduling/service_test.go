@@ -0,0 +1,208 @@
+package scheduling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+var (
+	t0 = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	v1 = shipping.NewVoyage("V1", shipping.Schedule{CarrierMovements: []shipping.CarrierMovement{
+		{DepartureLocation: shipping.DEHAM, ArrivalLocation: shipping.SESTO, DepartureTime: t0.Add(48 * time.Hour), ArrivalTime: t0.Add(72 * time.Hour)},
+		{DepartureLocation: shipping.SESTO, ArrivalLocation: shipping.FIHEL, DepartureTime: t0.Add(80 * time.Hour), ArrivalTime: t0.Add(96 * time.Hour)},
+	}})
+	v2 = shipping.NewVoyage("V2", shipping.Schedule{CarrierMovements: []shipping.CarrierMovement{
+		{DepartureLocation: shipping.DEHAM, ArrivalLocation: shipping.NLRTM, DepartureTime: t0.Add(24 * time.Hour), ArrivalTime: t0.Add(40 * time.Hour)},
+	}})
+)
+
+func newMockRepositories() (*mock.VoyageRepository, *mock.LocationRepository, *mock.ServiceStringRepository) {
+	voyages := &mock.VoyageRepository{
+		FindFn: func(n shipping.VoyageNumber) (*shipping.Voyage, error) {
+			switch n {
+			case v1.VoyageNumber:
+				return v1, nil
+			case v2.VoyageNumber:
+				return v2, nil
+			}
+			return nil, shipping.ErrUnknownVoyage
+		},
+		FindCallingAtFn: func(l shipping.UNLocode) []*shipping.Voyage {
+			var result []*shipping.Voyage
+			for _, v := range []*shipping.Voyage{v1, v2} {
+				if v.Schedule.CallsAt(l) {
+					result = append(result, v)
+				}
+			}
+			return result
+		},
+	}
+
+	locations := &mock.LocationRepository{
+		FindFn: func(l shipping.UNLocode) (*shipping.Location, error) {
+			for _, loc := range []*shipping.Location{shipping.Hamburg, shipping.Stockholm, shipping.Helsinki, shipping.Rotterdam} {
+				if loc.UNLocode == l {
+					return loc, nil
+				}
+			}
+			return nil, shipping.ErrUnknownLocation
+		},
+	}
+
+	services := &mock.ServiceStringRepository{
+		FindAllFn: func() []*shipping.ServiceString {
+			return []*shipping.ServiceString{
+				{Code: "NE1", Name: "Baltic Feeder", TradeLane: "Intra-Europe", Voyages: []shipping.VoyageNumber{"V1", "V2"}},
+				{Code: "AE1", Name: "Asia-Europe 1", TradeLane: "Asia-Europe", Voyages: []shipping.VoyageNumber{"V3"}},
+			}
+		},
+	}
+
+	return voyages, locations, services
+}
+
+func TestDepartures(t *testing.T) {
+	s := NewService(newMockRepositories())
+
+	m, err := s.Departures(context.Background(), "DEHAM", t0, t0.Add(7*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(m) != 2 {
+		t.Fatalf("len(m) = %d; want = %d", len(m), 2)
+	}
+	if m[0].VoyageNumber != "V2" || m[1].VoyageNumber != "V1" {
+		t.Errorf("departures should be ordered by departure time")
+	}
+	if m[0].ServiceString != "NE1" || m[0].TradeLane != "Intra-Europe" {
+		t.Errorf("m[0] service = %s (%s); want = %s (%s)", m[0].ServiceString, m[0].TradeLane, "NE1", "Intra-Europe")
+	}
+	if !m[0].DepartureTime.Equal(v2.Schedule.CarrierMovements[0].DepartureTime) {
+		t.Errorf("m[0].DepartureTime = %v; want = %v", m[0].DepartureTime, v2.Schedule.CarrierMovements[0].DepartureTime)
+	}
+	if got := m[0].DepartureTime.Location().String(); got != shipping.Hamburg.TimeZone {
+		t.Errorf("m[0].DepartureTime.Location() = %s; want = %s", got, shipping.Hamburg.TimeZone)
+	}
+}
+
+func TestArrivals(t *testing.T) {
+	s := NewService(newMockRepositories())
+
+	m, err := s.Arrivals(context.Background(), "SESTO", t0, t0.Add(60*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 0 {
+		t.Errorf("len(m) = %d; want = %d", len(m), 0)
+	}
+
+	m, err = s.Arrivals(context.Background(), "SESTO", t0, t0.Add(7*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 1 {
+		t.Fatalf("len(m) = %d; want = %d", len(m), 1)
+	}
+	if m[0].From != "DEHAM" || m[0].To != "SESTO" {
+		t.Errorf("m[0] = %s-%s; want = %s-%s", m[0].From, m[0].To, "DEHAM", "SESTO")
+	}
+}
+
+func TestDepartures_InvalidArguments(t *testing.T) {
+	s := NewService(newMockRepositories())
+
+	if _, err := s.Departures(context.Background(), "DEHAM", t0, t0); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+	if _, err := s.Departures(context.Background(), "XXXXX", t0, time.Time{}); err != shipping.ErrUnknownLocation {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownLocation)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	s := NewService(newMockRepositories())
+
+	v, err := s.Rotation(context.Background(), "V1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"DEHAM", "SESTO", "FIHEL"}
+	if len(v.PortCalls) != len(want) {
+		t.Fatalf("len(v.PortCalls) = %d; want = %d", len(v.PortCalls), len(want))
+	}
+	for i, l := range want {
+		if v.PortCalls[i].Location != l {
+			t.Errorf("v.PortCalls[%d].Location = %s; want = %s", i, v.PortCalls[i].Location, l)
+		}
+	}
+
+	if v.PortCalls[0].ArrivalTime != nil {
+		t.Errorf("first port call should not have an arrival time")
+	}
+	if v.PortCalls[2].DepartureTime != nil {
+		t.Errorf("last port call should not have a departure time")
+	}
+	if v.PortCalls[1].ArrivalTime == nil || v.PortCalls[1].DepartureTime == nil {
+		t.Errorf("intermediate port calls should have both arrival and departure times")
+	}
+
+	if _, err := s.Rotation(context.Background(), "V9"); err != shipping.ErrUnknownVoyage {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownVoyage)
+	}
+}
+
+func TestRotation_Actuals(t *testing.T) {
+	voyages, locations, services := newMockRepositories()
+
+	schedule, err := v1.Schedule.RecordActuals(shipping.SESTO, t0.Add(75*time.Hour), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	voyages.FindFn = func(n shipping.VoyageNumber) (*shipping.Voyage, error) {
+		return &shipping.Voyage{VoyageNumber: n, Schedule: schedule}, nil
+	}
+
+	s := NewService(voyages, locations, services)
+
+	v, err := s.Rotation(context.Background(), "V1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pc := v.PortCalls[1]
+	if pc.ActualArrivalTime == nil || !pc.ActualArrivalTime.Equal(t0.Add(75*time.Hour)) {
+		t.Errorf("pc.ActualArrivalTime = %v; want = %v", pc.ActualArrivalTime, t0.Add(75*time.Hour))
+	}
+	if pc.ArrivalDelay != "3h0m0s" {
+		t.Errorf("pc.ArrivalDelay = %q; want = %q", pc.ArrivalDelay, "3h0m0s")
+	}
+	if pc.ActualDepartureTime != nil || pc.DepartureDelay != "" {
+		t.Errorf("the departure should not be recorded")
+	}
+	if v.Delay != "3h0m0s" {
+		t.Errorf("v.Delay = %q; want = %q", v.Delay, "3h0m0s")
+	}
+}
+
+func TestServiceStrings(t *testing.T) {
+	s := NewService(newMockRepositories())
+
+	if got := s.ServiceStrings(context.Background(), ""); len(got) != 2 {
+		t.Errorf("len(ServiceStrings) = %d; want = %d", len(got), 2)
+	}
+
+	got := s.ServiceStrings(context.Background(), "Asia-Europe")
+	if len(got) != 1 {
+		t.Fatalf("len(ServiceStrings) = %d; want = %d", len(got), 1)
+	}
+	if got[0].Code != "AE1" {
+		t.Errorf("got[0].Code = %s; want = %s", got[0].Code, "AE1")
+	}
+}
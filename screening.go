@@ -0,0 +1,85 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ScreeningDecision is the outcome of screening the parties and locations
+// of a booking against sanctions and embargoes.
+type ScreeningDecision string
+
+// Screening decisions, from least to most severe.
+const (
+	ScreeningClear   ScreeningDecision = "clear"
+	ScreeningFlagged ScreeningDecision = "flagged"
+	ScreeningBlocked ScreeningDecision = "blocked"
+)
+
+// severity orders the decisions so that the most severe can be told.
+func (d ScreeningDecision) severity() int {
+	switch d {
+	case ScreeningFlagged:
+		return 1
+	case ScreeningBlocked:
+		return 2
+	}
+	return 0
+}
+
+// ErrScreeningBlocked is used when a booking or change is refused for
+// involving a sanctioned party or an embargoed location.
+var ErrScreeningBlocked = errors.New("blocked by sanctions screening")
+
+// ScreeningRequest holds what is screened: the party involved and the
+// locations the cargo travels between.
+type ScreeningRequest struct {
+	// TrackingID identifies the cargo screened, if any.
+	TrackingID TrackingID
+
+	Customer     CustomerID
+	CustomerName string
+	Locations    []UNLocode
+}
+
+// ScreeningResult is the outcome of a screening and the reasons for it.
+type ScreeningResult struct {
+	Decision ScreeningDecision
+	Reasons  []string
+}
+
+// IsFlagged returns whether the result asks for the booking to be reviewed.
+func (r ScreeningResult) IsFlagged() bool {
+	return r.Decision == ScreeningFlagged
+}
+
+// IsBlocked returns whether the result refuses the booking.
+func (r ScreeningResult) IsBlocked() bool {
+	return r.Decision == ScreeningBlocked
+}
+
+// Merge returns the most severe decision of both results, with the reasons
+// of both.
+func (r ScreeningResult) Merge(other ScreeningResult) ScreeningResult {
+	result := ScreeningResult{Decision: r.Decision}
+	if result.Decision == "" || other.Decision.severity() > result.Decision.severity() {
+		result.Decision = other.Decision
+	}
+	result.Reasons = append(append([]string(nil), r.Reasons...), other.Reasons...)
+	return result
+}
+
+// String returns the decision followed by the reasons for it.
+func (r ScreeningResult) String() string {
+	if len(r.Reasons) == 0 {
+		return string(r.Decision)
+	}
+	return string(r.Decision) + ": " + strings.Join(r.Reasons, "; ")
+}
+
+// Screener screens bookings against sanctioned parties and embargoed
+// locations.
+type Screener interface {
+	Screen(ctx context.Context, r ScreeningRequest) (ScreeningResult, error)
+}
@@ -0,0 +1,102 @@
+package screening
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/go-kit/kit/circuitbreaker"
+	"github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// ErrUnknownDecision is returned when the screening API answers with a
+// decision that is not known.
+var ErrUnknownDecision = errors.New("unknown screening decision")
+
+type remoteScreener struct {
+	screen endpoint.Endpoint
+}
+
+// NewRemoteScreener returns a Screener that asks an external screening API,
+// posting the request as JSON to the URL. The options configure the HTTP
+// client, e.g. its transport.
+func NewRemoteScreener(screenURL string, opts ...kithttp.ClientOption) (shipping.Screener, error) {
+	u, err := url.Parse(screenURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var e endpoint.Endpoint
+	e = kithttp.NewClient("POST", u, encodeScreenRequest, decodeScreenResponse, opts...).Endpoint()
+	e = circuitbreaker.Hystrix("screen")(e)
+
+	return &remoteScreener{screen: e}, nil
+}
+
+func (s *remoteScreener) Screen(ctx context.Context, r shipping.ScreeningRequest) (shipping.ScreeningResult, error) {
+	req := screenRequest{
+		TrackingID:   string(r.TrackingID),
+		Customer:     string(r.Customer),
+		CustomerName: r.CustomerName,
+		Locations:    make([]string, len(r.Locations)),
+	}
+	for i, l := range r.Locations {
+		req.Locations[i] = string(l)
+	}
+
+	response, err := s.screen(ctx, req)
+	if err != nil {
+		return shipping.ScreeningResult{}, err
+	}
+
+	resp := response.(screenResponse)
+
+	d := shipping.ScreeningDecision(resp.Decision)
+	switch d {
+	case shipping.ScreeningClear, shipping.ScreeningFlagged, shipping.ScreeningBlocked:
+	default:
+		return shipping.ScreeningResult{}, ErrUnknownDecision
+	}
+
+	return shipping.ScreeningResult{Decision: d, Reasons: resp.Reasons}, nil
+}
+
+type screenRequest struct {
+	TrackingID   string   `json:"tracking_id,omitempty"`
+	Customer     string   `json:"customer,omitempty"`
+	CustomerName string   `json:"customer_name,omitempty"`
+	Locations    []string `json:"locations"`
+}
+
+type screenResponse struct {
+	Decision string   `json:"decision"`
+	Reasons  []string `json:"reasons"`
+}
+
+func encodeScreenRequest(_ context.Context, r *http.Request, request interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(request); err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	r.Body = ioutil.NopCloser(&buf)
+	return nil
+}
+
+func decodeScreenResponse(_ context.Context, resp *http.Response) (interface{}, error) {
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(resp.Status)
+	}
+	var response screenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,89 @@
+// Package screening provides implementations of shipping.Screener, which
+// screen bookings against sanctioned parties and embargoed destinations.
+package screening
+
+import (
+	"context"
+	"strings"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// Screeners screens with several screeners in order, and returns the most
+// severe of their decisions. Screening stops at the first error.
+type Screeners []shipping.Screener
+
+// Screen screens with every screener.
+func (ss Screeners) Screen(ctx context.Context, r shipping.ScreeningRequest) (shipping.ScreeningResult, error) {
+	result := shipping.ScreeningResult{Decision: shipping.ScreeningClear}
+	for _, s := range ss {
+		res, err := s.Screen(ctx, r)
+		if err != nil {
+			return shipping.ScreeningResult{}, err
+		}
+		result = result.Merge(res)
+	}
+	return result, nil
+}
+
+// DenyList is a static list of sanctioned parties and embargoed locations.
+type DenyList struct {
+	// Parties holds the IDs or names of sanctioned customers. Names are
+	// matched regardless of case.
+	Parties []string
+
+	// Countries holds the ISO 3166 codes of embargoed countries, and
+	// Locations embargoed locations.
+	Countries []string
+	Locations []shipping.UNLocode
+
+	// Decision is what a match results in. It defaults to blocking.
+	Decision shipping.ScreeningDecision
+}
+
+// Screen screens the customer and locations of a request against the list.
+func (l DenyList) Screen(ctx context.Context, r shipping.ScreeningRequest) (shipping.ScreeningResult, error) {
+	var reasons []string
+
+	for _, p := range l.Parties {
+		if p == string(r.Customer) || r.CustomerName != "" && strings.EqualFold(p, r.CustomerName) {
+			reasons = append(reasons, "sanctioned party "+p)
+		}
+	}
+
+	for _, loc := range r.Locations {
+		for _, c := range l.Countries {
+			if strings.EqualFold(c, loc.Country()) {
+				reasons = append(reasons, "embargoed country "+loc.Country())
+			}
+		}
+		for _, denied := range l.Locations {
+			if denied == loc {
+				reasons = append(reasons, "embargoed location "+string(loc))
+			}
+		}
+	}
+
+	if len(reasons) == 0 {
+		return shipping.ScreeningResult{Decision: shipping.ScreeningClear}, nil
+	}
+
+	decision := l.Decision
+	if decision == "" {
+		decision = shipping.ScreeningBlocked
+	}
+
+	return shipping.ScreeningResult{Decision: decision, Reasons: reasons}, nil
+}
+
+// ParseList splits a comma-separated list, as given by a flag, ignoring
+// blank entries.
+func ParseList(s string) []string {
+	var result []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			result = append(result, e)
+		}
+	}
+	return result
+}
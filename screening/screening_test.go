@@ -0,0 +1,81 @@
+package screening
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+func TestDenyList(t *testing.T) {
+	l := DenyList{
+		Parties:   []string{"Evil Corp"},
+		Countries: []string{"kp"},
+		Locations: []shipping.UNLocode{"IRBND"},
+	}
+
+	tests := []struct {
+		req  shipping.ScreeningRequest
+		want shipping.ScreeningDecision
+	}{
+		{shipping.ScreeningRequest{Customer: "ACME", Locations: []shipping.UNLocode{shipping.SESTO, shipping.CNHKG}}, shipping.ScreeningClear},
+		{shipping.ScreeningRequest{Customer: "EVIL", CustomerName: "evil corp"}, shipping.ScreeningBlocked},
+		{shipping.ScreeningRequest{Customer: "ACME", Locations: []shipping.UNLocode{shipping.SESTO, "KPNAM"}}, shipping.ScreeningBlocked},
+		{shipping.ScreeningRequest{Customer: "ACME", Locations: []shipping.UNLocode{"IRBND"}}, shipping.ScreeningBlocked},
+	}
+	for _, tt := range tests {
+		got, err := l.Screen(context.Background(), tt.req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Decision != tt.want {
+			t.Errorf("Screen(%+v) = %v; want = %s", tt.req, got, tt.want)
+		}
+	}
+
+	l.Decision = shipping.ScreeningFlagged
+	if got, _ := l.Screen(context.Background(), shipping.ScreeningRequest{Locations: []shipping.UNLocode{"KPNAM"}}); !got.IsFlagged() {
+		t.Errorf("got = %v; want flagged", got)
+	}
+}
+
+func TestRemoteScreener(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req screenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		decision := "clear"
+		if req.CustomerName == "Shady Ltd" {
+			decision = "flagged"
+		}
+		json.NewEncoder(w).Encode(screenResponse{Decision: decision, Reasons: []string{"name match"}})
+	}))
+	defer srv.Close()
+
+	remote, err := NewRemoteScreener(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ss := Screeners{DenyList{Countries: []string{"KP"}}, remote}
+
+	got, err := ss.Screen(context.Background(), shipping.ScreeningRequest{CustomerName: "Shady Ltd", Locations: []shipping.UNLocode{shipping.SESTO}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsFlagged() || len(got.Reasons) != 1 {
+		t.Errorf("got = %v; want flagged for the name match", got)
+	}
+
+	got, err = ss.Screen(context.Background(), shipping.ScreeningRequest{CustomerName: "Shady Ltd", Locations: []shipping.UNLocode{"KPNAM"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsBlocked() || len(got.Reasons) != 2 {
+		t.Errorf("got = %v; want blocked with both reasons", got)
+	}
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	"github.com/marcusolsson/goddd/instrumenting"
+)
+
+type adminHandler struct {
+	volumes *instrumenting.VolumeMonitor
+
+	logger kitlog.Logger
+}
+
+func (h *adminHandler) router() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/repositories", h.repositories)
+	return r
+}
+
+// repositories reports the latest sample of the volumes of the repositories.
+func (h *adminHandler) repositories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(h.volumes.Report()); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
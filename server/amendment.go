@@ -0,0 +1,231 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/amendment"
+)
+
+type amendmentHandler struct {
+	s amendment.Service
+
+	logger kitlog.Logger
+}
+
+func (h *amendmentHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Route("/users", func(r chi.Router) {
+		r.Get("/", h.listUsers)
+		r.Put("/{userID}", h.registerUser)
+	})
+	r.Route("/cargos/{trackingID}", func(r chi.Router) {
+		r.Get("/amendments", h.listAmendments)
+		r.Post("/destination_change", h.requestDestinationChange)
+		r.Post("/deadline_extension", h.requestDeadlineExtension)
+	})
+	r.Get("/pending", h.listPending)
+	r.Route("/amendments/{amendmentID}", func(r chi.Router) {
+		r.Post("/approve", h.approve)
+		r.Post("/reject", h.reject)
+	})
+
+	r.Method("GET", "/docs", http.StripPrefix("/amendment/v1/docs", http.FileServer(http.Dir("amendment/docs"))))
+
+	return r
+}
+
+// credentials returns the user ID and key given as basic auth credentials.
+func credentials(r *http.Request) amendment.Credentials {
+	var by amendment.Credentials
+	if id, key, ok := r.BasicAuth(); ok {
+		by = amendment.Credentials{User: shipping.UserID(id), Key: key}
+	}
+	return by
+}
+
+func (h *amendmentHandler) requestDestinationChange(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Destination string `json:"destination"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	id, err := h.s.RequestDestinationChange(ctx,
+		credentials(r),
+		shipping.TrackingID(chi.URLParam(r, "trackingID")),
+		shipping.UNLocode(request.Destination),
+	)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	h.encodeAmendmentID(w, r, id)
+}
+
+func (h *amendmentHandler) requestDeadlineExtension(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		ArrivalDeadline time.Time `json:"arrival_deadline"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	id, err := h.s.RequestDeadlineExtension(ctx,
+		credentials(r),
+		shipping.TrackingID(chi.URLParam(r, "trackingID")),
+		request.ArrivalDeadline,
+	)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	h.encodeAmendmentID(w, r, id)
+}
+
+func (h *amendmentHandler) encodeAmendmentID(w http.ResponseWriter, r *http.Request, id shipping.AmendmentID) {
+	var response = struct {
+		ID shipping.AmendmentID `json:"amendment_id"`
+	}{
+		ID: id,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(r.Context(), err, w)
+		return
+	}
+}
+
+func (h *amendmentHandler) approve(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	err := h.s.Approve(ctx, credentials(r), shipping.AmendmentID(chi.URLParam(r, "amendmentID")))
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *amendmentHandler) reject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Reason string `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	err := h.s.Reject(ctx, credentials(r), shipping.AmendmentID(chi.URLParam(r, "amendmentID")), request.Reason)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *amendmentHandler) listAmendments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var response = struct {
+		Amendments []amendment.Amendment `json:"amendments"`
+	}{
+		Amendments: h.s.Amendments(ctx, shipping.TrackingID(chi.URLParam(r, "trackingID"))),
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *amendmentHandler) listPending(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var response = struct {
+		Amendments []amendment.Amendment `json:"amendments"`
+	}{
+		Amendments: h.s.Pending(ctx),
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *amendmentHandler) registerUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Name  string   `json:"name"`
+		Key   string   `json:"key"`
+		Roles []string `json:"roles"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	roles := make([]shipping.Role, len(request.Roles))
+	for i, role := range request.Roles {
+		roles[i] = shipping.Role(role)
+	}
+
+	err := h.s.RegisterUser(ctx,
+		shipping.UserID(chi.URLParam(r, "userID")),
+		request.Name,
+		request.Key,
+		roles,
+	)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *amendmentHandler) listUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var response = struct {
+		Users []amendment.User `json:"users"`
+	}{
+		Users: h.s.Users(ctx),
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/audit"
+)
+
+type auditHandler struct {
+	s audit.Service
+
+	logger kitlog.Logger
+}
+
+func (h *auditHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/entries", h.listEntries)
+	r.Get("/verify", h.verify)
+
+	r.Method("GET", "/docs", http.StripPrefix("/audit/v1/docs", http.FileServer(http.Dir("audit/docs"))))
+
+	return r
+}
+
+func (h *auditHandler) listEntries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	from, to, err := parseWindow(r)
+	if err != nil {
+		encodeError(ctx, audit.ErrInvalidArgument, w)
+		return
+	}
+
+	v := r.URL.Query()
+
+	entries, err := h.s.Entries(ctx, shipping.AuditQuery{
+		TrackingID: shipping.TrackingID(v.Get("tracking_id")),
+		Actor:      v.Get("actor"),
+		Action:     shipping.AuditAction(v.Get("action")),
+		From:       from,
+		To:         to,
+	})
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Entries []audit.Entry `json:"entries"`
+	}{
+		Entries: entries,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *auditHandler) verify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	v, err := h.s.Verify(ctx)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
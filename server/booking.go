@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi"
@@ -11,6 +12,7 @@ import (
 
 	shipping "github.com/marcusolsson/goddd"
 	"github.com/marcusolsson/goddd/booking"
+	"github.com/marcusolsson/goddd/correlation"
 )
 
 type bookingHandler struct {
@@ -25,15 +27,38 @@ func (h *bookingHandler) router() chi.Router {
 	r.Route("/cargos", func(r chi.Router) {
 		r.Post("/", h.bookCargo)
 		r.Get("/", h.listCargos)
+		r.Post("/recalculate_deliveries", h.recalculateAllDeliveries)
 		r.Route("/{trackingID}", func(r chi.Router) {
 			r.Get("/", h.loadCargo)
+			r.Get("/timeline", h.timeline)
 			r.Get("/request_routes", h.requestRoutes)
 			r.Post("/assign_to_route", h.assignToRoute)
+			r.Post("/replace_leg", h.replaceLeg)
+			r.Post("/roll", h.rollCargo)
 			r.Post("/change_destination", h.changeDestination)
+			r.Post("/change_deadline_type", h.changeDeadlineType)
+			r.Post("/transfer", h.transferCargo)
+			r.Put("/declaration", h.declareCargo)
+			r.Post("/reject", h.rejectCargo)
+			r.Post("/release_code", h.issueReleaseCode)
+			r.Post("/recalculate_delivery", h.recalculateDelivery)
 		})
 
 	})
+	r.Route("/drafts", func(r chi.Router) {
+		r.Post("/", h.saveDraft)
+		r.Get("/", h.listDrafts)
+		r.Put("/{draftID}", h.saveDraft)
+		r.Delete("/{draftID}", h.discardDraft)
+		r.Post("/{draftID}/book", h.bookDraft)
+	})
+	r.Post("/voyages/{voyageNumber}/omit_port_call", h.omitPortCall)
 	r.Get("/locations", h.listLocations)
+	r.Post("/locations/{locode}/reroute", h.rerouteAllVia)
+	r.Get("/feasibility", h.checkDeadlineFeasibility)
+	r.Get("/emissions", h.emissionsReport)
+	r.Get("/capacity", h.capacityForecast)
+	r.Get("/capacity/overbooked", h.overbookingReport)
 
 	r.Method("GET", "/docs", http.StripPrefix("/booking/v1/docs", http.FileServer(http.Dir("booking/docs"))))
 
@@ -41,12 +66,71 @@ func (h *bookingHandler) router() chi.Router {
 }
 
 func (h *bookingHandler) bookCargo(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 
 	var request struct {
+		Customer        shipping.CustomerID
 		Origin          shipping.UNLocode
 		Destination     shipping.UNLocode
 		ArrivalDeadline time.Time
+		Equipment       string
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	// Cargos are booked in 20ft containers unless told otherwise.
+	equipment := shipping.Container20
+	if request.Equipment != "" {
+		var err error
+		if equipment, err = shipping.ParseEquipmentType(request.Equipment); err != nil {
+			encodeError(ctx, err, w)
+			return
+		}
+	}
+
+	id, err := h.s.BookNewCargo(ctx, request.Customer, request.Origin, request.Destination, request.ArrivalDeadline, equipment)
+	if err == booking.ErrDuplicateBooking {
+		// Point the client to the cargo it most likely booked already.
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":       err.Error(),
+			"tracking_id": id,
+		})
+		return
+	}
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		ID shipping.TrackingID `json:"tracking_id"`
+	}{
+		ID: id,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) saveDraft(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Customer        shipping.CustomerID `json:"customer"`
+		Origin          shipping.UNLocode   `json:"origin"`
+		Destination     shipping.UNLocode   `json:"destination"`
+		ArrivalDeadline time.Time           `json:"arrival_deadline"`
+		Equipment       string              `json:"equipment"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -55,7 +139,75 @@ func (h *bookingHandler) bookCargo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := h.s.BookNewCargo(request.Origin, request.Destination, request.ArrivalDeadline)
+	// Like bookings, drafts are for 20ft containers unless told otherwise.
+	equipment := shipping.Container20
+	if request.Equipment != "" {
+		var err error
+		if equipment, err = shipping.ParseEquipmentType(request.Equipment); err != nil {
+			encodeError(ctx, err, w)
+			return
+		}
+	}
+
+	id, err := h.s.SaveDraft(ctx, shipping.BookingDraft{
+		ID:              shipping.DraftID(chi.URLParam(r, "draftID")),
+		Customer:        request.Customer,
+		Origin:          request.Origin,
+		Destination:     request.Destination,
+		ArrivalDeadline: request.ArrivalDeadline,
+		Equipment:       equipment,
+	})
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		ID shipping.DraftID `json:"draft_id"`
+	}{
+		ID: id,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) listDrafts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	drafts := h.s.Drafts(ctx, shipping.CustomerID(r.URL.Query().Get("customer")))
+
+	var response = struct {
+		Drafts []booking.Draft `json:"drafts"`
+	}{
+		Drafts: drafts,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) bookDraft(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := h.s.BookDraft(ctx, shipping.DraftID(chi.URLParam(r, "draftID")))
+	if err == booking.ErrDuplicateBooking {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":       err.Error(),
+			"tracking_id": id,
+		})
+		return
+	}
 	if err != nil {
 		encodeError(ctx, err, w)
 		return
@@ -75,12 +227,21 @@ func (h *bookingHandler) bookCargo(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *bookingHandler) discardDraft(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.s.DiscardDraft(ctx, shipping.DraftID(chi.URLParam(r, "draftID"))); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
 func (h *bookingHandler) loadCargo(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 
 	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
 
-	c, err := h.s.LoadCargo(trackingID)
+	c, err := h.s.LoadCargo(ctx, trackingID)
 	if err != nil {
 		encodeError(ctx, err, w)
 		return
@@ -100,17 +261,198 @@ func (h *bookingHandler) loadCargo(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *bookingHandler) timeline(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
+
+	entries, err := h.s.Timeline(ctx, trackingID)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Timeline []booking.TimelineEntry `json:"timeline"`
+	}{
+		Timeline: entries,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
 func (h *bookingHandler) requestRoutes(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 
 	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
 
-	itin := h.s.RequestPossibleRoutesForCargo(trackingID)
+	// Allow clients to narrow the latency budget for this call.
+	if v := r.URL.Query().Get("timeout"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			encodeError(ctx, booking.ErrInvalidArgument, w)
+			return
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Restrict the routes to a comma-separated list of service strings, e.g.
+	// the ones contracted by the customer.
+	var services []shipping.ServiceCode
+	if v := r.URL.Query().Get("services"); v != "" {
+		for _, code := range strings.Split(v, ",") {
+			services = append(services, shipping.ServiceCode(strings.TrimSpace(code)))
+		}
+	}
+
+	response := h.s.RequestPossibleRoutesForCargo(ctx, trackingID, services...)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) checkDeadlineFeasibility(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	v := r.URL.Query()
+
+	deadline, err := time.Parse(time.RFC3339, v.Get("arrival_deadline"))
+	if err != nil {
+		encodeError(ctx, booking.ErrInvalidArgument, w)
+		return
+	}
+
+	f, err := h.s.CheckDeadlineFeasibility(ctx,
+		shipping.UNLocode(v.Get("origin")),
+		shipping.UNLocode(v.Get("destination")),
+		deadline,
+	)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(f); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) emissionsReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	v := r.URL.Query()
+
+	from, err := time.Parse(time.RFC3339, v.Get("from"))
+	if err != nil {
+		encodeError(ctx, booking.ErrInvalidArgument, w)
+		return
+	}
+
+	to, err := time.Parse(time.RFC3339, v.Get("to"))
+	if err != nil {
+		encodeError(ctx, booking.ErrInvalidArgument, w)
+		return
+	}
+
+	report, err := h.s.EmissionsReport(ctx, from, to)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Report booking.EmissionsReport `json:"report"`
+	}{
+		Report: report,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) capacityForecast(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	v := r.URL.Query()
+
+	from, err := time.Parse(time.RFC3339, v.Get("from"))
+	if err != nil {
+		encodeError(ctx, booking.ErrInvalidArgument, w)
+		return
+	}
+
+	to, err := time.Parse(time.RFC3339, v.Get("to"))
+	if err != nil {
+		encodeError(ctx, booking.ErrInvalidArgument, w)
+		return
+	}
+
+	voyages, err := h.s.CapacityForecast(ctx, from, to)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Voyages []booking.VoyageUtilization `json:"voyages"`
+	}{
+		Voyages: voyages,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) overbookingReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	v := r.URL.Query()
+
+	from, err := time.Parse(time.RFC3339, v.Get("from"))
+	if err != nil {
+		encodeError(ctx, booking.ErrInvalidArgument, w)
+		return
+	}
+
+	to, err := time.Parse(time.RFC3339, v.Get("to"))
+	if err != nil {
+		encodeError(ctx, booking.ErrInvalidArgument, w)
+		return
+	}
+
+	voyages, err := h.s.OverbookingReport(ctx, from, to)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
 
 	var response = struct {
-		Routes []shipping.Itinerary `json:"routes"`
+		Voyages []booking.OverbookedVoyage `json:"voyages"`
 	}{
-		Routes: itin,
+		Voyages: voyages,
 	}
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -122,7 +464,7 @@ func (h *bookingHandler) requestRoutes(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *bookingHandler) assignToRoute(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 
 	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
 
@@ -136,7 +478,52 @@ func (h *bookingHandler) assignToRoute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.s.AssignCargoToRoute(trackingID, request.Itinerary)
+	err := h.s.AssignCargoToRoute(ctx, trackingID, request.Itinerary)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) replaceLeg(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
+
+	var request struct {
+		Index int          `json:"index"`
+		Leg   shipping.Leg `json:"leg"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	err := h.s.ReplaceLeg(ctx, trackingID, request.Index, request.Leg)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) rollCargo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
+
+	var request struct {
+		Voyage shipping.VoyageNumber `json:"voyage"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	err := h.s.RollCargo(ctx, trackingID, request.Voyage)
 	if err != nil {
 		encodeError(ctx, err, w)
 		return
@@ -144,7 +531,7 @@ func (h *bookingHandler) assignToRoute(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *bookingHandler) changeDestination(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 
 	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
 
@@ -158,17 +545,268 @@ func (h *bookingHandler) changeDestination(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err := h.s.ChangeDestination(trackingID, request.Destination)
+	err := h.s.ChangeDestination(ctx, trackingID, request.Destination)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) changeDeadlineType(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
+
+	var request struct {
+		DeadlineType string `json:"deadline_type"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	t, err := shipping.ParseDeadlineType(request.DeadlineType)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.s.ChangeDeadlineType(ctx, trackingID, t); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) transferCargo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
+
+	var request struct {
+		From shipping.CustomerID `json:"from"`
+		To   shipping.CustomerID `json:"to"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	err := h.s.TransferCargo(ctx, trackingID, request.From, request.To)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) declareCargo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
+
+	var request struct {
+		Commodities []shipping.CommodityCode `json:"commodities"`
+		Documents   []shipping.DocumentType  `json:"documents"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	err := h.s.DeclareCargo(ctx, trackingID, shipping.CargoDeclaration{
+		Commodities: request.Commodities,
+		Documents:   request.Documents,
+	})
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) rejectCargo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
+
+	var request struct {
+		ArrivalDeadline time.Time `json:"arrival_deadline"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	id, err := h.s.RejectCargo(ctx, trackingID, request.ArrivalDeadline)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		ID shipping.TrackingID `json:"return_id"`
+	}{
+		ID: id,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) issueReleaseCode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	pin, err := h.s.IssueReleaseCode(ctx, shipping.TrackingID(chi.URLParam(r, "trackingID")))
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		PIN string `json:"pin"`
+	}{
+		PIN: pin,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) omitPortCall(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	voyage := shipping.VoyageNumber(chi.URLParam(r, "voyageNumber"))
+
+	var request struct {
+		Location shipping.UNLocode `json:"location"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	ids, err := h.s.OmitPortCall(ctx, voyage, request.Location)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Rerouting []shipping.TrackingID `json:"rerouting"`
+	}{
+		Rerouting: ids,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) rerouteAllVia(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	locode := shipping.UNLocode(chi.URLParam(r, "locode"))
+
+	var request struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	result, err := h.s.RerouteAllVia(ctx, locode, request.From, request.To)
 	if err != nil {
 		encodeError(ctx, err, w)
 		return
 	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
 }
 
 func (h *bookingHandler) listCargos(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
+
+	if filter := r.URL.Query().Get("q"); filter != "" {
+		h.searchCargos(w, r, filter)
+		return
+	}
+
+	if acceptsNDJSON(r) {
+		enc := newNDJSONWriter(w)
+		if err := h.s.StreamCargos(ctx, func(c booking.Cargo) error {
+			return enc.Write(c)
+		}); err != nil {
+			h.logger.Log("error", err)
+		}
+		return
+	}
+
+	cs := h.s.Cargos(ctx)
+
+	var response = struct {
+		Cargos []booking.Cargo `json:"cargos"`
+	}{
+		Cargos: cs,
+	}
 
-	cs := h.s.Cargos()
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+// searchCargos lists the cargos matching a filter expression, streamed if
+// asked for.
+func (h *bookingHandler) searchCargos(w http.ResponseWriter, r *http.Request, filter string) {
+	ctx := r.Context()
+
+	cs, err := h.s.SearchCargos(ctx, filter)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	if acceptsNDJSON(r) {
+		enc := newNDJSONWriter(w)
+		for _, c := range cs {
+			if err := enc.Write(c); err != nil {
+				h.logger.Log("error", err)
+				return
+			}
+		}
+		return
+	}
 
 	var response = struct {
 		Cargos []booking.Cargo `json:"cargos"`
@@ -184,10 +822,62 @@ func (h *bookingHandler) listCargos(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *bookingHandler) recalculateDelivery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
+
+	c, err := h.s.RecalculateDelivery(ctx, trackingID)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Cargo booking.Cargo `json:"cargo"`
+	}{
+		Cargo: c,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *bookingHandler) recalculateAllDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	progress := func(done, total int) {
+		h.logger.Log("request_id", correlation.FromContext(ctx), "msg", "recalculating deliveries", "done", done, "total", total)
+	}
+
+	report, err := h.s.RecalculateAllDeliveries(ctx, progress)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Report booking.RecalculationReport `json:"report"`
+	}{
+		Report: report,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
 func (h *bookingHandler) listLocations(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 
-	ls := h.s.Locations()
+	ls := h.s.Locations(ctx)
 
 	var response = struct {
 		Locations []booking.Location `json:"cargos"`
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/carrier"
+)
+
+type carrierHandler struct {
+	s carrier.Service
+
+	logger kitlog.Logger
+}
+
+func (h *carrierHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/voyages/{voyageNumber}/legs", h.pendingLegs)
+	r.Route("/cargos/{trackingID}/legs/{voyageNumber}/{from}", func(r chi.Router) {
+		r.Post("/confirm", h.confirmLeg)
+		r.Post("/reject", h.rejectLeg)
+	})
+
+	r.Method("GET", "/docs", http.StripPrefix("/carrier/v1/docs", http.FileServer(http.Dir("carrier/docs"))))
+
+	return r
+}
+
+func (h *carrierHandler) pendingLegs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	legs, err := h.s.PendingLegs(ctx, bearerToken(r), shipping.VoyageNumber(chi.URLParam(r, "voyageNumber")))
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Legs []carrier.Leg `json:"legs"`
+	}{
+		Legs: legs,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *carrierHandler) confirmLeg(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Reference string `json:"reference"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	err := h.s.ConfirmLeg(ctx,
+		bearerToken(r),
+		shipping.TrackingID(chi.URLParam(r, "trackingID")),
+		shipping.VoyageNumber(chi.URLParam(r, "voyageNumber")),
+		shipping.UNLocode(chi.URLParam(r, "from")),
+		request.Reference,
+	)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *carrierHandler) rejectLeg(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	err := h.s.RejectLeg(ctx,
+		bearerToken(r),
+		shipping.TrackingID(chi.URLParam(r, "trackingID")),
+		shipping.VoyageNumber(chi.URLParam(r, "voyageNumber")),
+		shipping.UNLocode(chi.URLParam(r, "from")),
+	)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
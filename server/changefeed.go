@@ -0,0 +1,92 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/changefeed"
+	"github.com/marcusolsson/goddd/opaque"
+)
+
+type changeFeedHandler struct {
+	s   changefeed.Service
+	ids opaque.Codec
+
+	logger kitlog.Logger
+}
+
+func (h *changeFeedHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/cargos/changes", h.changes)
+
+	r.Method("GET", "/docs", http.StripPrefix("/changefeed/v1/docs", http.FileServer(http.Dir("changefeed/docs"))))
+
+	return r
+}
+
+func (h *changeFeedHandler) changes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	v := r.URL.Query()
+
+	var limit int
+	if s := v.Get("limit"); s != "" {
+		var err error
+		if limit, err = strconv.Atoi(s); err != nil {
+			encodeError(ctx, changefeed.ErrInvalidArgument, w)
+			return
+		}
+	}
+
+	// Clients long-poll by giving how long they are prepared to wait for
+	// changes, e.g. 30s.
+	var wait time.Duration
+	if s := v.Get("wait"); s != "" {
+		var err error
+		if wait, err = time.ParseDuration(s); err != nil {
+			encodeError(ctx, changefeed.ErrInvalidArgument, w)
+			return
+		}
+	}
+
+	// Cursors are sequence numbers, shown to clients as encoded by the
+	// codec of the deployment.
+	var since string
+	if s := v.Get("since"); s != "" {
+		seq, err := h.ids.Decode(s)
+		if err != nil {
+			encodeError(ctx, shipping.ErrInvalidCursor, w)
+			return
+		}
+		since = strconv.FormatUint(seq, 10)
+	}
+
+	page, err := h.s.Changes(ctx, since, limit, wait)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	if page.Cursor != "" {
+		seq, err := strconv.ParseUint(page.Cursor, 10, 64)
+		if err != nil {
+			encodeError(ctx, err, w)
+			return
+		}
+		page.Cursor = h.ids.Encode(seq)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/consolidation"
+)
+
+type consolidationHandler struct {
+	s consolidation.Service
+
+	logger kitlog.Logger
+}
+
+func (h *consolidationHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Route("/masters", func(r chi.Router) {
+		r.Post("/", h.consolidate)
+		r.Route("/{masterID}", func(r chi.Router) {
+			r.Post("/cargos", h.add)
+			r.Delete("/cargos/{trackingID}", h.remove)
+			r.Post("/split", h.split)
+		})
+	})
+
+	r.Method("GET", "/docs", http.StripPrefix("/consolidation/v1/docs", http.FileServer(http.Dir("consolidation/docs"))))
+
+	return r
+}
+
+func (h *consolidationHandler) consolidate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		TrackingIDs []shipping.TrackingID `json:"tracking_ids"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	id, err := h.s.Consolidate(ctx, request.TrackingIDs)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		ID shipping.TrackingID `json:"master_id"`
+	}{
+		ID: id,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *consolidationHandler) add(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	masterID := shipping.TrackingID(chi.URLParam(r, "masterID"))
+
+	var request struct {
+		TrackingID shipping.TrackingID `json:"tracking_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.s.Add(ctx, masterID, request.TrackingID); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *consolidationHandler) remove(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	masterID := shipping.TrackingID(chi.URLParam(r, "masterID"))
+	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
+
+	if err := h.s.Remove(ctx, masterID, trackingID); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *consolidationHandler) split(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	masterID := shipping.TrackingID(chi.URLParam(r, "masterID"))
+
+	released, err := h.s.Split(ctx, masterID)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		TrackingIDs []shipping.TrackingID `json:"tracking_ids"`
+	}{
+		TrackingIDs: released,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
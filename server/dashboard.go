@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/dashboard"
+)
+
+type dashboardHandler struct {
+	s dashboard.Service
+
+	logger kitlog.Logger
+}
+
+func (h *dashboardHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/overview", h.overview)
+	r.Get("/cargos/{trackingID}", h.cargo)
+
+	r.Method("GET", "/docs", http.StripPrefix("/dashboard/v1/docs", http.FileServer(http.Dir("dashboard/docs"))))
+
+	return r
+}
+
+func (h *dashboardHandler) overview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var response = struct {
+		Overview dashboard.Overview `json:"overview"`
+	}{
+		Overview: h.s.Overview(ctx),
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *dashboardHandler) cargo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	c, err := h.s.Cargo(ctx, shipping.TrackingID(chi.URLParam(r, "trackingID")))
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(c); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
@@ -1,9 +1,11 @@
 package server
 
 import (
-	"context"
+	"encoding/hex"
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi"
@@ -14,7 +16,8 @@ import (
 )
 
 type handlingHandler struct {
-	s handling.Service
+	s          handling.Service
+	activities shipping.ActivityTypes
 
 	logger kitlog.Logger
 }
@@ -22,12 +25,15 @@ type handlingHandler struct {
 func (h *handlingHandler) router() chi.Router {
 	r := chi.NewRouter()
 	r.Post("/incidents", h.registerIncident)
+	r.Get("/incidents", h.listIncidents)
+	r.Get("/terminals", h.listTerminals)
+	r.Put("/terminals/{terminalID}", h.registerTerminal)
 	r.Method("GET", "/docs", http.StripPrefix("/handling/v1/docs", http.FileServer(http.Dir("handling/docs"))))
 	return r
 }
 
 func (h *handlingHandler) registerIncident(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 
 	var request struct {
 		CompletionTime time.Time `json:"completion_time"`
@@ -35,20 +41,103 @@ func (h *handlingHandler) registerIncident(w http.ResponseWriter, r *http.Reques
 		VoyageNumber   string    `json:"voyage"`
 		Location       string    `json:"location"`
 		EventType      string    `json:"event_type"`
+		ReleaseCode    string    `json:"release_code"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+	// The report is kept as sent, for its signature to be verified.
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
 		h.logger.Log("error", err)
 		encodeError(ctx, err, w)
 		return
 	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	// Terminals authenticate with their ID and key as basic auth
+	// credentials.
+	var source handling.Credentials
+	if id, key, ok := r.BasicAuth(); ok {
+		source = handling.Credentials{Terminal: shipping.TerminalID(id), Key: key}
+	}
+	if sig, ok := signature(r.Header, body); ok {
+		source.Signature = &sig
+	}
+	source.ReleaseCode = request.ReleaseCode
 
-	err := h.s.RegisterHandlingEvent(
+	err = h.s.RegisterHandlingEvent(
+		ctx,
+		source,
 		request.CompletionTime,
 		shipping.TrackingID(request.TrackingID),
 		shipping.VoyageNumber(request.VoyageNumber),
 		shipping.UNLocode(request.Location),
-		stringToEventType(request.EventType),
+		h.eventType(request.EventType),
+	)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+// incident is the representation of a handling event, using the same fields
+// as when the incident was registered, along with when it was registered.
+type incident struct {
+	CompletionTime   time.Time `json:"completion_time"`
+	RegistrationTime time.Time `json:"registration_time"`
+	TrackingID       string    `json:"tracking_id"`
+	VoyageNumber     string    `json:"voyage,omitempty"`
+	Location         string    `json:"location"`
+	EventType        string    `json:"event_type"`
+	Source           string    `json:"source,omitempty"`
+}
+
+func (h *handlingHandler) listIncidents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	enc := newNDJSONWriter(w)
+	if err := h.s.StreamHandlingEvents(ctx, func(e shipping.HandlingEvent) error {
+		return enc.Write(incident{
+			CompletionTime:   e.Completed,
+			RegistrationTime: e.Registered,
+			TrackingID:       string(e.TrackingID),
+			VoyageNumber:     string(e.Activity.VoyageNumber),
+			Location:         string(e.Activity.Location),
+			EventType:        h.activities.Name(e.Activity.Type),
+			Source:           string(e.Source),
+		})
+	}); err != nil {
+		h.logger.Log("error", err)
+	}
+}
+
+func (h *handlingHandler) registerTerminal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Name          string `json:"name"`
+		Location      string `json:"location"`
+		Key           string `json:"key"`
+		SigningSecret string `json:"signing_secret"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	// Administrators authenticate with the admin token as a bearer token.
+	err := h.s.RegisterTerminal(ctx,
+		bearerToken(r),
+		shipping.TerminalID(chi.URLParam(r, "terminalID")),
+		request.Name,
+		shipping.UNLocode(request.Location),
+		request.Key,
+		request.SigningSecret,
 	)
 	if err != nil {
 		encodeError(ctx, err, w)
@@ -56,13 +145,57 @@ func (h *handlingHandler) registerIncident(w http.ResponseWriter, r *http.Reques
 	}
 }
 
-func stringToEventType(s string) shipping.HandlingEventType {
-	types := map[string]shipping.HandlingEventType{
-		shipping.Receive.String(): shipping.Receive,
-		shipping.Load.String():    shipping.Load,
-		shipping.Unload.String():  shipping.Unload,
-		shipping.Customs.String(): shipping.Customs,
-		shipping.Claim.String():   shipping.Claim,
+func (h *handlingHandler) listTerminals(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Administrators authenticate with the admin token as a bearer token.
+	terminals, err := h.s.Terminals(ctx, bearerToken(r))
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Terminals []handling.Terminal `json:"terminals"`
+	}{
+		Terminals: terminals,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+// eventType returns the handling event type of the given name, or
+// NotHandled for unknown names, which the service refuses.
+func (h *handlingHandler) eventType(s string) shipping.HandlingEventType {
+	t, err := h.activities.Parse(s)
+	if err != nil {
+		return shipping.NotHandled
+	}
+	return t
+}
+
+// signature returns the signature of a report sent in the headers of the
+// request, if signed. Malformed signatures are returned as invalid rather
+// than missing.
+func signature(h http.Header, report []byte) (handling.Signature, bool) {
+	mac := h.Get(handling.SignatureHeader)
+	if mac == "" {
+		return handling.Signature{}, false
 	}
-	return types[s]
+
+	sig := handling.Signature{
+		Nonce:  h.Get(handling.NonceHeader),
+		Report: report,
+	}
+	sig.MAC, _ = hex.DecodeString(mac)
+	if secs, err := strconv.ParseInt(h.Get(handling.TimestampHeader), 10, 64); err == nil {
+		sig.Timestamp = time.Unix(secs, 0)
+	}
+
+	return sig, true
 }
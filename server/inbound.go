@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	"github.com/marcusolsson/goddd/inbound"
+)
+
+type inboundHandler struct {
+	s inbound.Service
+
+	logger kitlog.Logger
+}
+
+func (h *inboundHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/mail", h.receiveMail)
+
+	r.Method("GET", "/docs", http.StripPrefix("/inbound/v1/docs", http.FileServer(http.Dir("inbound/docs"))))
+
+	return r
+}
+
+// receiveMail accepts mail posted by a mail provider, as form fields.
+func (h *inboundHandler) receiveMail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	m := inbound.Message{
+		ID:      r.FormValue("message_id"),
+		From:    r.FormValue("from"),
+		Subject: r.FormValue("subject"),
+		Body:    r.FormValue("text"),
+	}
+
+	n, err := h.s.Receive(ctx, r.URL.Query().Get("token"), m)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Registered int `json:"registered"`
+	}{
+		Registered: n,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/portal"
+)
+
+type portalHandler struct {
+	s portal.Service
+
+	logger kitlog.Logger
+}
+
+func (h *portalHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Put("/customers/{customerID}", h.registerCustomer)
+	r.Post("/unsubscribe", h.unsubscribe)
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.authenticate)
+		r.Get("/cargos", h.listCargos)
+		r.Get("/events", h.recentEvents)
+		r.Get("/preferences", h.preferences)
+		r.Put("/preferences", h.setPreferences)
+		r.Get("/frequency", h.frequency)
+		r.Put("/frequency", h.setFrequency)
+		r.Get("/contact", h.contact)
+		r.Put("/contact", h.setContact)
+	})
+
+	r.Method("GET", "/docs", http.StripPrefix("/portal/v1/docs", http.FileServer(http.Dir("portal/docs"))))
+
+	return r
+}
+
+type customerKey struct{}
+
+// authenticate requires customers to authenticate with their ID and key as
+// basic auth credentials, and makes the customer available through the
+// request context.
+func (h *portalHandler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, key, _ := r.BasicAuth()
+
+		if err := h.s.Authenticate(ctx, shipping.CustomerID(id), key); err != nil {
+			w.Header().Set("WWW-Authenticate", `Basic realm="portal"`)
+			encodeError(ctx, err, w)
+			return
+		}
+
+		ctx = context.WithValue(ctx, customerKey{}, shipping.CustomerID(id))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func customerFromContext(ctx context.Context) shipping.CustomerID {
+	id, _ := ctx.Value(customerKey{}).(shipping.CustomerID)
+	return id
+}
+
+func (h *portalHandler) registerCustomer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Name string `json:"name"`
+		Key  string `json:"key"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	id := shipping.CustomerID(chi.URLParam(r, "customerID"))
+
+	if err := h.s.RegisterCustomer(ctx, id, request.Name, request.Key); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *portalHandler) listCargos(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var response = struct {
+		Cargos []portal.Cargo `json:"cargos"`
+	}{
+		Cargos: h.s.Cargos(ctx, customerFromContext(ctx)),
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *portalHandler) recentEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var limit int
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			encodeError(ctx, portal.ErrInvalidArgument, w)
+			return
+		}
+		limit = n
+	}
+
+	var response = struct {
+		Events []portal.Event `json:"events"`
+	}{
+		Events: h.s.RecentEvents(ctx, customerFromContext(ctx), limit),
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *portalHandler) preferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	p, err := h.s.Preferences(ctx, customerFromContext(ctx))
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Notifications portal.Preferences `json:"notifications"`
+	}{
+		Notifications: p,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *portalHandler) setPreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Notifications portal.Preferences `json:"notifications"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.s.SetPreferences(ctx, customerFromContext(ctx), request.Notifications); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *portalHandler) frequency(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	f, err := h.s.Frequency(ctx, customerFromContext(ctx))
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Frequency portal.Frequency `json:"frequency"`
+	}{
+		Frequency: f,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *portalHandler) setFrequency(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Frequency portal.Frequency `json:"frequency"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.s.SetFrequency(ctx, customerFromContext(ctx), request.Frequency); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *portalHandler) contact(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	c, err := h.s.Contact(ctx, customerFromContext(ctx))
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Contact portal.Contact `json:"contact"`
+	}{
+		Contact: c,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *portalHandler) setContact(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Contact portal.Contact `json:"contact"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.s.SetContact(ctx, customerFromContext(ctx), request.Contact); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *portalHandler) unsubscribe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	v := r.URL.Query()
+
+	if err := h.s.Unsubscribe(ctx,
+		shipping.CustomerID(v.Get("customer")),
+		shipping.NotificationType(v.Get("type")),
+		v.Get("token"),
+	); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
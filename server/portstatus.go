@@ -0,0 +1,236 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/portstatus"
+)
+
+type portStatusHandler struct {
+	s portstatus.Service
+
+	logger kitlog.Logger
+}
+
+func (h *portStatusHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Route("/advisories", func(r chi.Router) {
+		r.Get("/", h.listStatuses)
+		r.Post("/", h.advise)
+		r.Post("/import", h.importFeed)
+	})
+	r.Route("/calendars", func(r chi.Router) {
+		r.Get("/", h.listCalendars)
+		r.Put("/{locode}", h.setCalendar)
+	})
+	r.Get("/performance", h.performance)
+
+	r.Method("GET", "/docs", http.StripPrefix("/portstatus/v1/docs", http.FileServer(http.Dir("portstatus/docs"))))
+
+	return r
+}
+
+func (h *portStatusHandler) advise(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Location  shipping.UNLocode `json:"location"`
+		Condition string            `json:"condition"`
+		Delay     string            `json:"delay"`
+		Reason    string            `json:"reason"`
+		Until     time.Time         `json:"until"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	condition, err := shipping.ParsePortCondition(request.Condition)
+	if err != nil {
+		encodeError(ctx, portstatus.ErrInvalidArgument, w)
+		return
+	}
+
+	var delay time.Duration
+	if request.Delay != "" {
+		if delay, err = time.ParseDuration(request.Delay); err != nil {
+			encodeError(ctx, portstatus.ErrInvalidArgument, w)
+			return
+		}
+	}
+
+	if err := h.s.Advise(ctx, shipping.PortStatus{
+		Location:  request.Location,
+		Condition: condition,
+		Delay:     delay,
+		Reason:    request.Reason,
+		Until:     request.Until,
+	}); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *portStatusHandler) importFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	n, err := h.s.Import(ctx, r.Body)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Imported int `json:"imported"`
+	}{
+		Imported: n,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *portStatusHandler) listStatuses(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var response = struct {
+		Advisories []portstatus.Status `json:"advisories"`
+	}{
+		Advisories: h.s.Statuses(ctx),
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *portStatusHandler) setCalendar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		TimeZone string                        `json:"time_zone"`
+		Hours    map[string][]portstatus.Hours `json:"hours"`
+		Holidays []string                      `json:"holidays"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	c := shipping.WorkingCalendar{
+		Location: shipping.UNLocode(chi.URLParam(r, "locode")),
+		TimeZone: request.TimeZone,
+	}
+
+	for name, hours := range request.Hours {
+		day, ok := parseWeekday(name)
+		if !ok {
+			encodeError(ctx, portstatus.ErrInvalidArgument, w)
+			return
+		}
+		for _, hh := range hours {
+			opens, err := parseClock(hh.Open)
+			if err != nil {
+				encodeError(ctx, portstatus.ErrInvalidArgument, w)
+				return
+			}
+			closes, err := parseClock(hh.Close)
+			if err != nil {
+				encodeError(ctx, portstatus.ErrInvalidArgument, w)
+				return
+			}
+			c.Hours[day] = append(c.Hours[day], shipping.OpeningHours{Open: opens, Close: closes})
+		}
+	}
+
+	for _, d := range request.Holidays {
+		t, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			encodeError(ctx, portstatus.ErrInvalidArgument, w)
+			return
+		}
+		c.Holidays = append(c.Holidays, t)
+	}
+
+	if err := h.s.SetCalendar(ctx, c); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *portStatusHandler) listCalendars(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var response = struct {
+		Calendars []portstatus.Calendar `json:"calendars"`
+	}{
+		Calendars: h.s.Calendars(ctx),
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *portStatusHandler) performance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var response = struct {
+		Ports []portstatus.Performance `json:"ports"`
+	}{
+		Ports: h.s.Performance(ctx),
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+// parseWeekday parses a weekday name, such as "monday".
+func parseWeekday(s string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(s, d.String()) {
+			return d, true
+		}
+	}
+	return time.Sunday, false
+}
+
+// parseClock parses a time of day, such as "08:00", as an offset from
+// midnight. Midnight at the end of the day is given as "24:00".
+func parseClock(s string) (time.Duration, error) {
+	var hh, mm int
+	if _, err := fmt.Sscanf(s, "%d:%d", &hh, &mm); err != nil {
+		return 0, err
+	}
+	if hh < 0 || mm < 0 || mm > 59 || hh*60+mm > 24*60 {
+		return 0, portstatus.ErrInvalidArgument
+	}
+	return time.Duration(hh)*time.Hour + time.Duration(mm)*time.Minute, nil
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/position"
+)
+
+type positionHandler struct {
+	s position.Service
+
+	logger kitlog.Logger
+}
+
+func (h *positionHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/voyages/{voyageNumber}/positions", h.reportPosition)
+	r.Put("/voyages/{voyageNumber}/port_calls/{locode}", h.recordPortCall)
+
+	r.Method("GET", "/docs", http.StripPrefix("/position/v1/docs", http.FileServer(http.Dir("position/docs"))))
+
+	return r
+}
+
+func (h *positionHandler) reportPosition(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Latitude  float64   `json:"latitude"`
+		Longitude float64   `json:"longitude"`
+		Reported  time.Time `json:"reported"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	n, err := h.s.ReportPosition(ctx,
+		shipping.VoyageNumber(chi.URLParam(r, "voyageNumber")),
+		shipping.Coordinates{Latitude: request.Latitude, Longitude: request.Longitude},
+		request.Reported,
+	)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Updated int `json:"updated"`
+	}{
+		Updated: n,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *positionHandler) recordPortCall(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Arrived  time.Time `json:"arrived"`
+		Departed time.Time `json:"departed"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	n, err := h.s.RecordPortCall(ctx,
+		shipping.VoyageNumber(chi.URLParam(r, "voyageNumber")),
+		shipping.UNLocode(chi.URLParam(r, "locode")),
+		request.Arrived,
+		request.Departed,
+	)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Updated int `json:"updated"`
+	}{
+		Updated: n,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/privacy"
+)
+
+type privacyHandler struct {
+	s privacy.Service
+
+	logger kitlog.Logger
+}
+
+func (h *privacyHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Route("/customers/{customerID}", func(r chi.Router) {
+		r.Get("/export", h.export)
+		r.Post("/anonymize", h.anonymize)
+	})
+
+	r.Method("GET", "/docs", http.StripPrefix("/privacy/v1/docs", http.FileServer(http.Dir("privacy/docs"))))
+
+	return r
+}
+
+func (h *privacyHandler) export(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id := shipping.CustomerID(chi.URLParam(r, "customerID"))
+
+	archive, err := h.s.Export(ctx, privacy.Credentials(credentials(r)), id)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "customer-"+string(id)+".zip"))
+	if err := archive.WriteZip(w); err != nil {
+		h.logger.Log("error", err)
+	}
+}
+
+func (h *privacyHandler) anonymize(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	err := h.s.Anonymize(ctx, privacy.Credentials(credentials(r)), shipping.CustomerID(chi.URLParam(r, "customerID")))
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
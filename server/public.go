@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+	"github.com/marcusolsson/goddd/tracking"
+)
+
+// CaptchaHeader is the header clients of the public tracking page send the
+// response to a CAPTCHA with, once they have exceeded their rate limit.
+const CaptchaHeader = "X-Captcha-Response"
+
+// CaptchaVerifier verifies the response to a CAPTCHA solved on the public
+// tracking page, e.g. with a reCAPTCHA or hCaptcha site verification API.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, response, remoteIP string) error
+}
+
+// PublicTracking configures the public tracking lookup.
+type PublicTracking struct {
+	// Interval is how often a client may look a cargo up, on average. Zero
+	// disables rate limiting.
+	Interval time.Duration
+
+	// Burst is the number of lookups a client may make at once.
+	Burst int
+
+	// Captcha, if set, lets a client exceeding its rate limit carry on
+	// after solving a CAPTCHA.
+	Captcha CaptchaVerifier
+
+	// TrustProxy identifies clients by the address a reverse proxy in front
+	// of the server appends to the X-Forwarded-For header, rather than by
+	// the address of the connection.
+	TrustProxy bool
+}
+
+// DefaultPublicTracking allows a client a lookup every six seconds, and five
+// at once.
+var DefaultPublicTracking = PublicTracking{
+	Interval: 6 * time.Second,
+	Burst:    5,
+}
+
+// missCost is the number of lookups an unknown tracking ID counts as, to slow
+// down clients guessing tracking IDs.
+const missCost = 3
+
+var errRateLimited = errors.New("too many lookups, try again later")
+
+type publicTrackingHandler struct {
+	s tracking.Service
+
+	limiter    *rateLimiter
+	captcha    CaptchaVerifier
+	trustProxy bool
+
+	logger kitlog.Logger
+}
+
+func newPublicTrackingHandler(s tracking.Service, p PublicTracking, logger kitlog.Logger) *publicTrackingHandler {
+	h := &publicTrackingHandler{
+		s:          s,
+		captcha:    p.Captcha,
+		trustProxy: p.TrustProxy,
+		logger:     logger,
+	}
+	if p.Interval > 0 {
+		h.limiter = newRateLimiter(p.Interval, p.Burst)
+	}
+	return h
+}
+
+func (h *publicTrackingHandler) router() chi.Router {
+	r := chi.NewRouter()
+	r.Use(negotiateLanguage)
+	r.Get("/cargos/{trackingID}", h.track)
+	r.Method("GET", "/docs", http.StripPrefix("/public/v1/docs", http.FileServer(http.Dir("tracking/docs"))))
+	return r
+}
+
+func (h *publicTrackingHandler) track(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	client := h.clientIP(r)
+
+	if ok, wait := h.allow(ctx, r, client); !ok {
+		h.logger.Log(
+			"request_id", correlation.FromContext(ctx),
+			"msg", "public tracking rate limited",
+			"client", client,
+		)
+		h.encodeRateLimited(w, wait)
+		return
+	}
+
+	c, err := h.s.Track(ctx, chi.URLParam(r, "trackingID"))
+	if _, ok := err.(*tracking.UnknownCargoError); ok || err == shipping.ErrUnknownCargo {
+		// Never suggest the tracking IDs of other cargos to the public.
+		if h.limiter != nil {
+			h.limiter.penalize(client, missCost-1)
+		}
+		encodeError(ctx, shipping.ErrUnknownCargo, w)
+		return
+	}
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Cargo tracking.PublicCargo `json:"cargo"`
+	}{Cargo: c.Public()}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+// allow returns whether a client may look a cargo up, either because it is
+// within its rate limit or because it solved a CAPTCHA. If not, the time
+// until it may is returned.
+func (h *publicTrackingHandler) allow(ctx context.Context, r *http.Request, client string) (bool, time.Duration) {
+	if h.limiter == nil {
+		return true, 0
+	}
+
+	ok, wait := h.limiter.take(client, 1)
+	if ok {
+		return true, 0
+	}
+
+	response := r.Header.Get(CaptchaHeader)
+	if h.captcha == nil || response == "" {
+		return false, wait
+	}
+	if err := h.captcha.Verify(ctx, response, client); err != nil {
+		h.logger.Log("request_id", correlation.FromContext(ctx), "msg", "captcha rejected", "client", client, "err", err)
+		return false, wait
+	}
+
+	h.limiter.reset(client)
+	h.limiter.take(client, 1)
+
+	return true, 0
+}
+
+// clientIP returns the address of the client making a request.
+func (h *publicTrackingHandler) clientIP(r *http.Request) string {
+	if h.trustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			addrs := strings.Split(fwd, ",")
+			return strings.TrimSpace(addrs[len(addrs)-1])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// encodeRateLimited tells a client when to look cargos up again, and whether
+// solving a CAPTCHA lets it carry on right away.
+func (h *publicTrackingHandler) encodeRateLimited(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())))
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":            errRateLimited.Error(),
+		"captcha_required": h.captcha != nil,
+	})
+}
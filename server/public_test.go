@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/servicetest"
+	"github.com/marcusolsson/goddd/tracking"
+)
+
+type captchaFunc func(ctx context.Context, response, remoteIP string) error
+
+func (f captchaFunc) Verify(ctx context.Context, response, remoteIP string) error {
+	return f(ctx, response, remoteIP)
+}
+
+func TestPublicTrack(t *testing.T) {
+	var ts servicetest.TrackingService
+	ts.TrackFn = func(ctx context.Context, id string) (tracking.Cargo, error) {
+		if id != "ABC123" {
+			return tracking.Cargo{}, &tracking.UnknownCargoError{Suggestions: []shipping.TrackingID{"ABC123"}}
+		}
+		return tracking.Cargo{
+			TrackingID:      "ABC123",
+			StatusText:      "In port SESTO",
+			Origin:          "CNHKG",
+			Destination:     "SESTO",
+			ArrivalDeadline: time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC),
+			ReturnOf:        "XYZ999",
+			Warnings:        []string{"Port closed"},
+			Events:          []tracking.Event{{Description: "Received in CNHKG", Type: "Receive", Expected: true}},
+		}, nil
+	}
+
+	var verified []string
+	captcha := captchaFunc(func(ctx context.Context, response, remoteIP string) error {
+		if response != "solved" {
+			return errors.New("wrong answer")
+		}
+		verified = append(verified, remoteIP)
+		return nil
+	})
+
+	h := New(Services{Tracking: &ts}, log.NewNopLogger(),
+		WithPublicTracking(PublicTracking{Interval: time.Hour, Burst: 4, Captcha: captcha}),
+	)
+
+	lookup := func(id, addr, captcha string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "http://example.com/public/v1/cargos/"+id, nil)
+		req.RemoteAddr = addr
+		if captcha != "" {
+			req.Header.Set(CaptchaHeader, captcha)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := lookup("ABC123", "10.0.0.1:1234", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rec.Code = %d; want = %d", rec.Code, http.StatusOK)
+	}
+
+	var response map[string]map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"arrival_deadline", "return_of", "warnings", "next_expected_activity"} {
+		if _, ok := response["cargo"][field]; ok {
+			t.Errorf("%s exposed to the public", field)
+		}
+	}
+	if events := response["cargo"]["events"].([]interface{}); len(events) != 1 {
+		t.Errorf("len(events) = %d; want = %d", len(events), 1)
+	} else if _, ok := events[0].(map[string]interface{})["type"]; ok {
+		t.Errorf("event type exposed to the public")
+	}
+
+	// An unknown tracking ID counts as three lookups, and suggests nothing.
+	rec = lookup("ABC132", "10.0.0.1:1234", "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("rec.Code = %d; want = %d", rec.Code, http.StatusNotFound)
+	}
+	var notFound map[string]interface{}
+	json.NewDecoder(rec.Body).Decode(&notFound)
+	if _, ok := notFound["suggestions"]; ok {
+		t.Errorf("tracking IDs suggested to the public")
+	}
+
+	rec = lookup("ABC123", "10.0.0.1:1234", "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("rec.Code = %d; want = %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Retry-After not set")
+	}
+
+	// Other clients are not affected.
+	if rec := lookup("ABC123", "10.0.0.2:1234", ""); rec.Code != http.StatusOK {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusOK)
+	}
+
+	if rec := lookup("ABC123", "10.0.0.1:1234", "guessed"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if rec := lookup("ABC123", "10.0.0.1:1234", "solved"); rec.Code != http.StatusOK {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusOK)
+	}
+	if len(verified) != 1 || verified[0] != "10.0.0.1" {
+		t.Errorf("verified = %v; want = %v", verified, []string{"10.0.0.1"})
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	l := newRateLimiter(10*time.Second, 2)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.take("a", 1); !ok {
+			t.Fatalf("take #%d refused", i+1)
+		}
+	}
+	if ok, wait := l.take("a", 1); ok || wait != 10*time.Second {
+		t.Errorf("take = %v, %v; want refused for 10s", ok, wait)
+	}
+
+	now = now.Add(5 * time.Second)
+	if ok, wait := l.take("a", 1); ok || wait != 5*time.Second {
+		t.Errorf("take = %v, %v; want refused for 5s", ok, wait)
+	}
+
+	now = now.Add(5 * time.Second)
+	if ok, _ := l.take("a", 1); !ok {
+		t.Errorf("take refused after refill")
+	}
+
+	now = now.Add(time.Hour)
+	l.take("b", 1)
+	if _, ok := l.buckets["a"]; ok {
+		t.Errorf("idle client not forgotten")
+	}
+}
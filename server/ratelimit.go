@@ -0,0 +1,107 @@
+package server
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often clients that have not been seen long enough to
+// be allowed a full burst again are forgotten.
+const sweepInterval = time.Minute
+
+// rateLimiter limits each client to a steady rate of requests, allowing
+// bursts of a few requests at once, using a token bucket per client.
+type rateLimiter struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a limiter allowing a request every interval, and
+// bursts of up to burst requests.
+func newRateLimiter(interval time.Duration, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    1 / interval.Seconds(),
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// take takes n tokens from the bucket of a client. If there are not enough
+// tokens, none are taken and the time until there will be is returned.
+func (l *rateLimiter) take(client string, n float64) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(client)
+	if b.tokens < n {
+		wait := (n - b.tokens) / l.rate
+		return false, time.Duration(math.Ceil(wait)) * time.Second
+	}
+	b.tokens -= n
+	return true, 0
+}
+
+// penalize takes up to n tokens from the bucket of a client, leaving it
+// empty if there are not enough.
+func (l *rateLimiter) penalize(client string, n float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(client)
+	b.tokens = math.Max(0, b.tokens-n)
+}
+
+// reset fills the bucket of a client.
+func (l *rateLimiter) reset(client string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.buckets, client)
+}
+
+// refill returns the bucket of a client, with the tokens accrued since it
+// was last used added. Must be called with the lock held.
+func (l *rateLimiter) refill(client string) *bucket {
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+		return b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	return b
+}
+
+// sweep forgets the clients whose buckets would be full by now, so that the
+// limiter does not grow with every client ever seen. Must be called with the
+// lock held.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/reconciliation"
+)
+
+type reconciliationHandler struct {
+	s reconciliation.Service
+
+	logger kitlog.Logger
+}
+
+func (h *reconciliationHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Route("/voyages/{voyageNumber}", func(r chi.Router) {
+		r.Get("/", h.reconcile)
+		r.Post("/complete", h.completeVoyage)
+	})
+
+	r.Method("GET", "/docs", http.StripPrefix("/reconciliation/v1/docs", http.FileServer(http.Dir("reconciliation/docs"))))
+
+	return r
+}
+
+func (h *reconciliationHandler) reconcile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	rec, err := h.s.Reconcile(ctx, shipping.VoyageNumber(chi.URLParam(r, "voyageNumber")))
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(rec); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *reconciliationHandler) completeVoyage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	rec, err := h.s.CompleteVoyage(ctx, shipping.VoyageNumber(chi.URLParam(r, "voyageNumber")))
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(rec); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
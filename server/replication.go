@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	"github.com/marcusolsson/goddd/replication"
+)
+
+type replicationHandler struct {
+	s replication.Service
+
+	logger kitlog.Logger
+}
+
+func (h *replicationHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/status", h.status)
+	r.Get("/export", h.export)
+	r.Post("/apply", h.apply)
+	r.Get("/digest", h.digest)
+	r.Post("/divergence", h.divergence)
+	r.Post("/demote", h.demote)
+	r.Post("/promote", h.promote)
+
+	r.Method("GET", "/docs", http.StripPrefix("/replication/v1/docs", http.FileServer(http.Dir("replication/docs"))))
+
+	return r
+}
+
+func (h *replicationHandler) status(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	st, err := h.s.Status(ctx)
+	h.encode(ctx, w, st, err)
+}
+
+func (h *replicationHandler) export(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var limit int
+	if s := r.URL.Query().Get("limit"); s != "" {
+		var err error
+		if limit, err = strconv.Atoi(s); err != nil {
+			encodeError(ctx, replication.ErrInvalidArgument, w)
+			return
+		}
+	}
+
+	b, err := h.s.Export(ctx, bearerToken(r), r.URL.Query().Get("since"), limit)
+	h.encode(ctx, w, b, err)
+}
+
+func (h *replicationHandler) apply(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var b replication.Batch
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	st, err := h.s.Apply(ctx, bearerToken(r), b)
+	h.encode(ctx, w, st, err)
+}
+
+func (h *replicationHandler) digest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	d, err := h.s.Digest(ctx, bearerToken(r))
+	h.encode(ctx, w, d, err)
+}
+
+// divergence compares the deployment to the digest of another, typically
+// the primary, posted by the operator.
+func (h *replicationHandler) divergence(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var d replication.Digest
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	rep, err := h.s.Compare(ctx, bearerToken(r), d)
+	h.encode(ctx, w, rep, err)
+}
+
+func (h *replicationHandler) demote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	st, err := h.s.Demote(ctx, bearerToken(r))
+	h.encode(ctx, w, st, err)
+}
+
+func (h *replicationHandler) promote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Until string `json:"until"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	st, err := h.s.Promote(ctx, bearerToken(r), request.Until)
+	h.encode(ctx, w, st, err)
+}
+
+// encode writes the response to a request, or the error it failed with.
+func (h *replicationHandler) encode(ctx context.Context, w http.ResponseWriter, response interface{}, err error) {
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
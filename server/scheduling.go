@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	"github.com/marcusolsson/goddd/scheduling"
+)
+
+type schedulingHandler struct {
+	s scheduling.Service
+
+	logger kitlog.Logger
+}
+
+func (h *schedulingHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Route("/locations/{locode}", func(r chi.Router) {
+		r.Get("/departures", h.departures)
+		r.Get("/arrivals", h.arrivals)
+	})
+	r.Get("/voyages/{voyageNumber}", h.rotation)
+	r.Get("/services", h.serviceStrings)
+
+	r.Method("GET", "/docs", http.StripPrefix("/scheduling/v1/docs", http.FileServer(http.Dir("scheduling/docs"))))
+
+	return r
+}
+
+func (h *schedulingHandler) departures(w http.ResponseWriter, r *http.Request) {
+	h.movements(w, r, h.s.Departures)
+}
+
+func (h *schedulingHandler) arrivals(w http.ResponseWriter, r *http.Request) {
+	h.movements(w, r, h.s.Arrivals)
+}
+
+func (h *schedulingHandler) movements(w http.ResponseWriter, r *http.Request, find func(ctx context.Context, locode string, from, to time.Time) ([]scheduling.Movement, error)) {
+	ctx := r.Context()
+
+	from, to, err := parseWindow(r)
+	if err != nil {
+		encodeError(ctx, scheduling.ErrInvalidArgument, w)
+		return
+	}
+
+	movements, err := find(ctx, chi.URLParam(r, "locode"), from, to)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Movements []scheduling.Movement `json:"movements"`
+	}{
+		Movements: movements,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *schedulingHandler) rotation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	v, err := h.s.Rotation(ctx, chi.URLParam(r, "voyageNumber"))
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Voyage scheduling.Voyage `json:"voyage"`
+	}{
+		Voyage: v,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *schedulingHandler) serviceStrings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var response = struct {
+		ServiceStrings []scheduling.ServiceString `json:"services"`
+	}{
+		ServiceStrings: h.s.ServiceStrings(ctx, r.URL.Query().Get("trade_lane")),
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+// parseWindow reads the from and to query parameters, given in RFC 3339.
+func parseWindow(r *http.Request) (from, to time.Time, err error) {
+	v := r.URL.Query()
+
+	if s := v.Get("from"); s != "" {
+		if from, err = time.Parse(time.RFC3339, s); err != nil {
+			return
+		}
+	}
+	if s := v.Get("to"); s != "" {
+		if to, err = time.Parse(time.RFC3339, s); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -3,41 +3,170 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi"
 	kitlog "github.com/go-kit/kit/log"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/amendment"
+	"github.com/marcusolsson/goddd/audit"
 	"github.com/marcusolsson/goddd/booking"
+	"github.com/marcusolsson/goddd/carrier"
+	"github.com/marcusolsson/goddd/changefeed"
+	"github.com/marcusolsson/goddd/command"
+	"github.com/marcusolsson/goddd/consolidation"
+	"github.com/marcusolsson/goddd/correlation"
+	"github.com/marcusolsson/goddd/dashboard"
 	"github.com/marcusolsson/goddd/handling"
+	"github.com/marcusolsson/goddd/inbound"
+	"github.com/marcusolsson/goddd/instrumenting"
+	"github.com/marcusolsson/goddd/opaque"
+	"github.com/marcusolsson/goddd/portal"
+	"github.com/marcusolsson/goddd/portstatus"
+	"github.com/marcusolsson/goddd/position"
+	"github.com/marcusolsson/goddd/privacy"
+	"github.com/marcusolsson/goddd/reconciliation"
+	"github.com/marcusolsson/goddd/replication"
+	"github.com/marcusolsson/goddd/scheduling"
+	"github.com/marcusolsson/goddd/sla"
+	"github.com/marcusolsson/goddd/task"
+	"github.com/marcusolsson/goddd/telemetry"
 	"github.com/marcusolsson/goddd/tracking"
+	"github.com/marcusolsson/goddd/webui"
 )
 
+// Services holds the services served by a HTTP server.
+type Services struct {
+	Booking        booking.Service
+	Tracking       tracking.Service
+	Handling       handling.Service
+	Scheduling     scheduling.Service
+	Consolidation  consolidation.Service
+	PortStatus     portstatus.Service
+	SLA            sla.Service
+	Portal         portal.Service
+	Amendment      amendment.Service
+	Task           task.Service
+	Inbound        inbound.Service
+	Audit          audit.Service
+	ChangeFeed     changefeed.Service
+	Carrier        carrier.Service
+	Position       position.Service
+	Telemetry      telemetry.Service
+	Privacy        privacy.Service
+	Dashboard      dashboard.Service
+	Reconciliation reconciliation.Service
+	Replication    replication.Service
+}
+
 // Server holds the dependencies for a HTTP server.
 type Server struct {
-	Booking  booking.Service
-	Tracking tracking.Service
-	Handling handling.Service
+	Services
 
 	Logger kitlog.Logger
 
-	router chi.Router
+	public      PublicTracking
+	activities  shipping.ActivityTypes
+	volumes     *instrumenting.VolumeMonitor
+	ids         opaque.Codec
+	fence       *replication.Fence
+	webUI       bool
+	maxBodySize int64
+	router      chi.Router
+}
+
+// DefaultMaxBodySize is the largest request body accepted, unless configured
+// otherwise.
+const DefaultMaxBodySize = 4 << 20
+
+// Option configures a server.
+type Option func(*Server)
+
+// WithPublicTracking configures the public tracking lookup, rather than
+// using DefaultPublicTracking.
+func WithPublicTracking(p PublicTracking) Option {
+	return func(s *Server) {
+		s.public = p
+	}
+}
+
+// WithActivityTypes accepts the custom activities of an operator in handling
+// reports, by name.
+func WithActivityTypes(ts shipping.ActivityTypes) Option {
+	return func(s *Server) {
+		s.activities = ts
+	}
+}
+
+// WithMaxBodySize sets the largest request body accepted, in bytes. Larger
+// requests are refused with 413 Request Entity Too Large.
+func WithMaxBodySize(n int64) Option {
+	return func(s *Server) {
+		s.maxBodySize = n
+	}
+}
+
+// WithVolumes serves the size report of the repositories sampled by a
+// monitor.
+func WithVolumes(m *instrumenting.VolumeMonitor) Option {
+	return func(s *Server) {
+		s.volumes = m
+	}
+}
+
+// WithIDCodec shows the sequence numbers of the service, such as the
+// cursors of the change feed, as encoded by a codec rather than as they are
+// stored.
+func WithIDCodec(c opaque.Codec) Option {
+	return func(s *Server) {
+		s.ids = c
+	}
+}
+
+// WithFence refuses writes while the deployment is a standby, other than
+// those replicating the primary.
+func WithFence(f *replication.Fence) Option {
+	return func(s *Server) {
+		s.fence = f
+	}
+}
+
+// WithWebUI serves the embedded web interface for booking and tracking
+// cargos at /ui, for demo deployments without a frontend of their own.
+func WithWebUI() Option {
+	return func(s *Server) {
+		s.webUI = true
+	}
 }
 
 // New returns a new HTTP server.
-func New(bs booking.Service, ts tracking.Service, hs handling.Service, logger kitlog.Logger) *Server {
+func New(services Services, logger kitlog.Logger, opts ...Option) *Server {
 	s := &Server{
-		Booking:  bs,
-		Tracking: ts,
-		Handling: hs,
-		Logger:   logger,
+		Services:    services,
+		Logger:      logger,
+		public:      DefaultPublicTracking,
+		ids:         opaque.Decimal,
+		maxBodySize: DefaultMaxBodySize,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
 
 	r := chi.NewRouter()
 
+	r.Use(requestID)
+	r.Use(idempotencyKey)
+	r.Use(accessLog(s.Logger))
 	r.Use(accessControl)
+	r.Use(limitBody(s.maxBodySize))
+	if s.fence != nil {
+		r.Use(fenced(s.fence))
+	}
 
 	r.Route("/booking", func(r chi.Router) {
 		h := bookingHandler{s.Booking, s.Logger}
@@ -47,13 +176,117 @@ func New(bs booking.Service, ts tracking.Service, hs handling.Service, logger ki
 		h := trackingHandler{s.Tracking, s.Logger}
 		r.Mount("/v1", h.router())
 	})
+	r.Route("/public", func(r chi.Router) {
+		h := newPublicTrackingHandler(s.Tracking, s.public, s.Logger)
+		r.Mount("/v1", h.router())
+	})
 	r.Route("/handling", func(r chi.Router) {
-		h := handlingHandler{s.Handling, s.Logger}
+		h := handlingHandler{s.Handling, s.activities, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+	r.Route("/scheduling", func(r chi.Router) {
+		h := schedulingHandler{s.Scheduling, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+	r.Route("/consolidation", func(r chi.Router) {
+		h := consolidationHandler{s.Consolidation, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+	r.Route("/portstatus", func(r chi.Router) {
+		h := portStatusHandler{s.PortStatus, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+
+	r.Route("/sla", func(r chi.Router) {
+		h := slaHandler{s.SLA, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+
+	r.Route("/portal", func(r chi.Router) {
+		h := portalHandler{s.Portal, s.Logger}
 		r.Mount("/v1", h.router())
 	})
 
+	r.Route("/amendment", func(r chi.Router) {
+		h := amendmentHandler{s.Amendment, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+
+	r.Route("/task", func(r chi.Router) {
+		h := taskHandler{s.Task, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+
+	r.Route("/inbound", func(r chi.Router) {
+		h := inboundHandler{s.Inbound, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+
+	r.Route("/audit", func(r chi.Router) {
+		h := auditHandler{s.Audit, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+
+	r.Route("/changefeed", func(r chi.Router) {
+		h := changeFeedHandler{s.ChangeFeed, s.ids, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+
+	r.Route("/carrier", func(r chi.Router) {
+		h := carrierHandler{s.Carrier, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+
+	r.Route("/position", func(r chi.Router) {
+		h := positionHandler{s.Position, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+
+	r.Route("/telemetry", func(r chi.Router) {
+		h := telemetryHandler{s.Telemetry, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+
+	r.Route("/privacy", func(r chi.Router) {
+		h := privacyHandler{s.Privacy, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+
+	r.Route("/dashboard", func(r chi.Router) {
+		h := dashboardHandler{s.Dashboard, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+
+	r.Route("/reconciliation", func(r chi.Router) {
+		h := reconciliationHandler{s.Reconciliation, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+
+	r.Route("/replication", func(r chi.Router) {
+		h := replicationHandler{s.Replication, s.Logger}
+		r.Mount("/v1", h.router())
+	})
+
+	if s.volumes != nil {
+		r.Route("/admin", func(r chi.Router) {
+			h := adminHandler{s.volumes, s.Logger}
+			r.Mount("/v1", h.router())
+		})
+	}
+
+	if s.webUI {
+		ui := http.RedirectHandler("/ui/", http.StatusFound)
+		r.Method("GET", "/", ui)
+		r.Method("GET", "/ui", ui)
+		r.Mount("/ui/", http.StripPrefix("/ui", webui.New()))
+	}
+
 	r.Method("GET", "/metrics", promhttp.Handler())
 
+	// Set last, for the handlers to reach every mounted router.
+	r.NotFound(notFound)
+	r.MethodNotAllowed(methodNotAllowed)
+
 	s.router = r
 
 	return s
@@ -63,11 +296,63 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.router.ServeHTTP(w, r)
 }
 
+// Route is an endpoint of the API.
+type Route struct {
+	Method  string
+	Pattern string
+}
+
+// Routes returns every endpoint of the API, with patterns as routed, such as
+// /booking/v1/cargos/{trackingID}.
+func (s *Server) Routes() []Route {
+	var routes []Route
+	chi.Walk(s.router, func(method, pattern string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes = append(routes, Route{Method: method, Pattern: strings.Replace(pattern, "/*/", "/", -1)})
+		return nil
+	})
+	return routes
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	encodeStatus(http.StatusNotFound, w)
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	encodeStatus(http.StatusMethodNotAllowed, w)
+}
+
+// encodeStatus writes an error named by its status code, for requests
+// failing before reaching a handler.
+func encodeStatus(code int, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": strings.ToLower(http.StatusText(code)),
+	})
+}
+
+// limitBody refuses requests with bodies larger than n bytes. Requests not
+// declaring their length are cut off once n bytes have been read, failing
+// to decode.
+func limitBody(n int64) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > n {
+				encodeStatus(http.StatusRequestEntityTooLarge, w)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
 func accessControl(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, "+correlation.Header+", "+command.IdempotencyHeader+", "+CaptchaHeader)
+		w.Header().Set("Access-Control-Expose-Headers", correlation.Header+", Retry-After")
 
 		if r.Method == "OPTIONS" {
 			return
@@ -77,13 +362,172 @@ func accessControl(h http.Handler) http.Handler {
 	})
 }
 
+// fenced refuses requests writing to a standby deployment, other than to
+// replicate the primary or fail over, which would be lost when the primary
+// next replicates the cargos and voyages written to.
+func fenced(f *replication.Fence) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case "GET", "HEAD", "OPTIONS":
+			default:
+				if f.Role() == replication.Standby && !strings.HasPrefix(r.URL.Path, "/replication/") {
+					encodeError(r.Context(), replication.ErrStandby, w)
+					return
+				}
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// idempotencyKey makes the idempotency key sent by the client, if any,
+// available through the request context, so that a retried command is only
+// handled once.
+func idempotencyKey(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get(command.IdempotencyHeader); key != "" {
+			r = r.WithContext(command.NewContext(r.Context(), key))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// requestID assigns a correlation ID to every request, unless the client
+// already provided a valid one, and makes it available through the request
+// context.
+func requestID(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(correlation.Header)
+		if !correlation.Valid(id) {
+			id = correlation.NewID()
+		}
+
+		w.Header().Set(correlation.Header, id)
+
+		h.ServeHTTP(w, r.WithContext(correlation.NewContext(r.Context(), id)))
+	})
+}
+
+func accessLog(logger kitlog.Logger) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+			defer func(begin time.Time) {
+				logger.Log(
+					"request_id", correlation.FromContext(r.Context()),
+					"method", r.Method,
+					"path", r.URL.Path,
+					"status", sw.status,
+					"took", time.Since(begin),
+				)
+			}(time.Now())
+
+			h.ServeHTTP(sw, r)
+		})
+	}
+}
+
+// statusWriter records the status code written to a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+const contentTypeNDJSON = "application/x-ndjson"
+
+// acceptsNDJSON reports whether the client asked for a streamed response.
+func acceptsNDJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), contentTypeNDJSON)
+}
+
+// ndjsonWriter writes values as newline-delimited JSON and flushes after
+// each value, so that large result sets never need to be held in memory.
+type ndjsonWriter struct {
+	enc     *json.Encoder
+	flusher http.Flusher
+}
+
+func newNDJSONWriter(w http.ResponseWriter) *ndjsonWriter {
+	w.Header().Set("Content-Type", contentTypeNDJSON+"; charset=utf-8")
+	f, _ := w.(http.Flusher)
+	return &ndjsonWriter{enc: json.NewEncoder(w), flusher: f}
+}
+
+func (w *ndjsonWriter) Write(v interface{}) error {
+	if err := w.enc.Encode(v); err != nil {
+		return err
+	}
+	if w.flusher != nil {
+		w.flusher.Flush()
+	}
+	return nil
+}
+
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if vs, ok := err.(shipping.ImportViolations); ok {
+		encodeImportViolations(vs, w)
+		return
+	}
+	if _, ok := err.(*shipping.CargoQueryError); ok {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	if e, ok := err.(*tracking.UnknownCargoError); ok {
+		encodeSuggestions(e, w)
+		return
+	}
+	if code, ok := decodeErrorStatus(err); ok {
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
 	switch err {
-	case shipping.ErrUnknownCargo:
+	case shipping.ErrUnknownCargo, shipping.ErrUnknownLocation, shipping.ErrUnknownVoyage, shipping.ErrUnknownServiceLevel, shipping.ErrUnknownCustomer,
+		shipping.ErrUnknownAmendment, shipping.ErrUnknownUser, shipping.ErrUnknownTask, shipping.ErrUnknownLeg, shipping.ErrUnknownCalendar,
+		shipping.ErrUnknownDraft:
 		w.WriteHeader(http.StatusNotFound)
-	case tracking.ErrInvalidArgument:
+	case tracking.ErrInvalidArgument, booking.ErrInvalidArgument, dashboard.ErrInvalidArgument, reconciliation.ErrInvalidArgument, handling.ErrInvalidArgument, scheduling.ErrInvalidArgument, shipping.ErrInvalidCursor, shipping.ErrConnectionTooShort, shipping.ErrUnknownEquipmentType, shipping.ErrUnknownDeadlineType, consolidation.ErrInvalidArgument, portstatus.ErrInvalidArgument, sla.ErrInvalidArgument, portal.ErrInvalidArgument, amendment.ErrInvalidArgument,
+		task.ErrInvalidArgument, shipping.ErrUnknownTaskStatus, shipping.ErrInvalidCalendar, inbound.ErrInvalidArgument, audit.ErrInvalidArgument, changefeed.ErrInvalidArgument,
+		carrier.ErrInvalidArgument, position.ErrInvalidArgument, telemetry.ErrInvalidArgument, privacy.ErrInvalidArgument, replication.ErrInvalidArgument, shipping.ErrInvalidSensorLimits,
+		shipping.ErrLegNotContiguous, shipping.ErrArrivalDeadlineMissed, shipping.ErrLegOffSchedule:
 		w.WriteHeader(http.StatusBadRequest)
+	case shipping.ErrUnauthorizedTerminal, shipping.ErrUnauthorizedCustomer, shipping.ErrUnauthorizedUser,
+		handling.ErrUnsignedReport, handling.ErrInvalidSignature, handling.ErrReplayedReport:
+		w.WriteHeader(http.StatusUnauthorized)
+	case shipping.ErrTerminalLocation, portal.ErrInvalidToken, shipping.ErrRoleRequired, inbound.ErrInvalidToken, carrier.ErrInvalidToken, handling.ErrInvalidToken, replication.ErrInvalidToken,
+		shipping.ErrScreeningBlocked, shipping.ErrNotCargoOwner, shipping.ErrReleaseCodeRequired, shipping.ErrInvalidReleaseCode, shipping.ErrReleaseCodeLocked:
+		w.WriteHeader(http.StatusForbidden)
+	case shipping.ErrConsolidated, shipping.ErrIncompatibleCargo, consolidation.ErrNotMaster, consolidation.ErrNotAtDestination,
+		shipping.ErrNotDelivered, shipping.ErrAlreadyRejected, booking.ErrDuplicateBooking, shipping.ErrPortNotCalled,
+		shipping.ErrCapacityExceeded, shipping.ErrAmendmentDecided, shipping.ErrApprovalRequired, shipping.ErrTaskDone,
+		shipping.ErrLegDecided, shipping.ErrLegHandled, shipping.ErrIncompleteDraft, shipping.ErrNothingToRoll, shipping.ErrAlreadyOnVoyage,
+		shipping.ErrNoLaterSailing, shipping.ErrNoReleaseCode, shipping.ErrAlreadyClaimed, replication.ErrNotStandby, replication.ErrOutOfSequence,
+		replication.ErrNotCaughtUp:
+		w.WriteHeader(http.StatusConflict)
+	case handling.ErrBusy:
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	case replication.ErrStandby:
+		w.WriteHeader(http.StatusServiceUnavailable)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -91,3 +535,60 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 		"error": err.Error(),
 	})
 }
+
+// decodeErrorStatus returns the status of an error decoding a request body,
+// which is the fault of the client.
+func decodeErrorStatus(err error) (int, bool) {
+	switch err.(type) {
+	case *http.MaxBytesError:
+		return http.StatusRequestEntityTooLarge, true
+	case *json.SyntaxError, *json.UnmarshalTypeError:
+		return http.StatusBadRequest, true
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return http.StatusBadRequest, true
+	}
+	return 0, false
+}
+
+// encodeSuggestions reports the tracking IDs closest to that of an unknown
+// cargo, so that the customer can be asked which one they meant.
+func encodeSuggestions(e *tracking.UnknownCargoError, w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":       e.Error(),
+		"suggestions": e.Suggestions,
+	})
+}
+
+// encodeImportViolations reports each violation of import restrictions, so
+// that the shipper can tell what to change.
+func encodeImportViolations(vs shipping.ImportViolations, w http.ResponseWriter) {
+	type violation struct {
+		Country   string `json:"country"`
+		Commodity string `json:"commodity,omitempty"`
+		Document  string `json:"document,omitempty"`
+		Message   string `json:"message"`
+	}
+
+	violations := make([]violation, len(vs))
+	for i, v := range vs {
+		violations[i] = violation{
+			Country:   v.Country,
+			Commodity: string(v.Commodity),
+			Document:  string(v.Document),
+			Message:   v.String(),
+		}
+	}
+
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":      vs.Error(),
+		"violations": violations,
+	})
+}
+
+// bearerToken returns the token given as a bearer token.
+func bearerToken(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
@@ -0,0 +1,254 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/changefeed"
+	"github.com/marcusolsson/goddd/correlation"
+	"github.com/marcusolsson/goddd/handling"
+	"github.com/marcusolsson/goddd/mock"
+	"github.com/marcusolsson/goddd/opaque"
+	"github.com/marcusolsson/goddd/replication"
+	"github.com/marcusolsson/goddd/servicetest"
+	"github.com/marcusolsson/goddd/tracking"
+)
+
+func TestRequestID(t *testing.T) {
+	var cargos mockCargoRepository
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryPageFn = func(shipping.TrackingID, shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+		return shipping.HandlingHistoryPage{}, nil
+	}
+
+	s := tracking.NewService(&cargos, &events)
+
+	h := New(Services{Tracking: s}, log.NewLogfmtLogger(ioutil.Discard))
+
+	req, _ := http.NewRequest("GET", "http://example.com/tracking/v1/cargos/not_found", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Header().Get(correlation.Header) == "" {
+		t.Errorf("%s should be assigned", correlation.Header)
+	}
+
+	req, _ = http.NewRequest("GET", "http://example.com/tracking/v1/cargos/not_found", nil)
+	req.Header.Set(correlation.Header, "abc")
+	rec = httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(correlation.Header); got != "abc" {
+		t.Errorf("%s = %q; want = %q", correlation.Header, got, "abc")
+	}
+
+	for _, id := range []string{"abc\nlevel=error", strings.Repeat("a", 129)} {
+		req, _ = http.NewRequest("GET", "http://example.com/tracking/v1/cargos/not_found", nil)
+		req.Header.Set(correlation.Header, id)
+		rec = httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get(correlation.Header); got == id || got == "" {
+			t.Errorf("%s = %q; want a new correlation ID", correlation.Header, got)
+		}
+	}
+}
+
+func TestChangeFeedCursorEncoded(t *testing.T) {
+	ids := opaque.NewKeyed([]byte("test"))
+
+	var cf servicetest.ChangeFeedService
+	cf.ChangesFn = func(ctx context.Context, since string, limit int, wait time.Duration) (changefeed.Page, error) {
+		if since != "41" {
+			t.Errorf("since = %q; want = %q", since, "41")
+		}
+		return changefeed.Page{Changes: []changefeed.Change{}, Cursor: "42"}, nil
+	}
+
+	h := New(Services{ChangeFeed: &cf}, log.NewNopLogger(), WithIDCodec(ids))
+
+	req, _ := http.NewRequest("GET", "http://example.com/changefeed/v1/cargos/changes?since="+ids.Encode(41), nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rec.Code = %d; want = %d", rec.Code, http.StatusOK)
+	}
+
+	var page changefeed.Page
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if want := ids.Encode(42); page.Cursor != want {
+		t.Errorf("cursor = %q; want = %q", page.Cursor, want)
+	}
+
+	// Sequence numbers as stored are not accepted in place of cursors.
+	req, _ = http.NewRequest("GET", "http://example.com/changefeed/v1/cargos/changes?since=41", nil)
+	rec = httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestWebUI(t *testing.T) {
+	h := New(Services{}, log.NewNopLogger(), WithWebUI())
+
+	for _, tt := range []struct {
+		path     string
+		want     int
+		location string
+	}{
+		{"/", http.StatusFound, "/ui/"},
+		{"/ui", http.StatusFound, "/ui/"},
+		{"/ui/", http.StatusOK, ""},
+		{"/ui/track", http.StatusOK, ""},
+		{"/ui/static/app.js", http.StatusOK, ""},
+	} {
+		req, _ := http.NewRequest("GET", "http://example.com"+tt.path, nil)
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("GET %s: rec.Code = %d; want = %d", tt.path, rec.Code, tt.want)
+		}
+		if loc := rec.Header().Get("Location"); loc != tt.location {
+			t.Errorf("GET %s: Location = %q; want = %q", tt.path, loc, tt.location)
+		}
+	}
+
+	// The interface is only served when asked for.
+	h = New(Services{}, log.NewNopLogger())
+
+	req, _ := http.NewRequest("GET", "http://example.com/ui/", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestStandbyFenced(t *testing.T) {
+	var rs servicetest.ReplicationService
+	rs.PromoteFn = func(ctx context.Context, token, until string) (replication.Status, error) {
+		if token != "secret" {
+			return replication.Status{}, replication.ErrInvalidToken
+		}
+		return replication.Status{Role: "primary"}, nil
+	}
+
+	fence := replication.NewFence(replication.Standby)
+
+	h := New(Services{Replication: &rs}, log.NewNopLogger(), WithFence(fence))
+
+	req, _ := http.NewRequest("POST", "http://example.com/booking/v1/cargos", strings.NewReader("{}"))
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	req, _ = http.NewRequest("POST", "http://example.com/replication/v1/promote", strings.NewReader(`{"until":"42"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusOK)
+	}
+	if !rs.PromoteInvoked {
+		t.Error("expected promote to be invoked")
+	}
+}
+
+func TestListIncidents(t *testing.T) {
+	var (
+		completed  = time.Date(2016, time.March, 1, 8, 0, 0, 0, time.UTC)
+		registered = completed.Add(2 * time.Second)
+	)
+
+	var hs servicetest.HandlingService
+	hs.StreamHandlingEventsFn = func(ctx context.Context, fn func(shipping.HandlingEvent) error) error {
+		return fn(shipping.HandlingEvent{
+			TrackingID: "ABC123",
+			Activity:   shipping.HandlingActivity{Type: shipping.Receive, Location: shipping.CNHKG},
+			Completed:  completed,
+			Registered: registered,
+			Source:     "cnhkg-1",
+		})
+	}
+
+	h := New(Services{Handling: &hs}, log.NewNopLogger())
+
+	req, _ := http.NewRequest("GET", "http://example.com/handling/v1/incidents", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	var got incident
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.CompletionTime.Equal(completed) {
+		t.Errorf("CompletionTime = %v; want = %v", got.CompletionTime, completed)
+	}
+	if !got.RegistrationTime.Equal(registered) {
+		t.Errorf("RegistrationTime = %v; want = %v", got.RegistrationTime, registered)
+	}
+}
+
+func TestListTerminals(t *testing.T) {
+	var hs servicetest.HandlingService
+	hs.TerminalsFn = func(ctx context.Context, token string) ([]handling.Terminal, error) {
+		if token != "admin" {
+			return nil, handling.ErrInvalidToken
+		}
+		return []handling.Terminal{}, nil
+	}
+
+	h := New(Services{Handling: &hs}, log.NewNopLogger())
+
+	req, _ := http.NewRequest("GET", "http://example.com/handling/v1/terminals", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusForbidden)
+	}
+
+	req.Header.Set("Authorization", "Bearer admin")
+	rec = httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusOK)
+	}
+	if got, want := strings.TrimSpace(rec.Body.String()), `{"terminals":[]}`; got != want {
+		t.Errorf("body = %s; want = %s", got, want)
+	}
+}
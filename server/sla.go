@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/sla"
+)
+
+type slaHandler struct {
+	s sla.Service
+
+	logger kitlog.Logger
+}
+
+func (h *slaHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Route("/levels", func(r chi.Router) {
+		r.Get("/", h.listSLAs)
+		r.Put("/{level}", h.defineSLA)
+	})
+	r.Route("/cargos/{trackingID}", func(r chi.Router) {
+		r.Post("/service_level", h.assignServiceLevel)
+		r.Post("/evaluate", h.evaluateCargo)
+	})
+	r.Get("/report", h.report)
+
+	r.Method("GET", "/docs", http.StripPrefix("/sla/v1/docs", http.FileServer(http.Dir("sla/docs"))))
+
+	return r
+}
+
+func (h *slaHandler) defineSLA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		MaxTransit string `json:"max_transit"`
+		MaxDwell   string `json:"max_dwell"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	s := shipping.SLA{Level: shipping.ServiceLevel(chi.URLParam(r, "level"))}
+
+	var err error
+	if request.MaxTransit != "" {
+		if s.MaxTransit, err = time.ParseDuration(request.MaxTransit); err != nil {
+			encodeError(ctx, sla.ErrInvalidArgument, w)
+			return
+		}
+	}
+	if request.MaxDwell != "" {
+		if s.MaxDwell, err = time.ParseDuration(request.MaxDwell); err != nil {
+			encodeError(ctx, sla.ErrInvalidArgument, w)
+			return
+		}
+	}
+
+	if err := h.s.DefineSLA(ctx, s); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *slaHandler) listSLAs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var response = struct {
+		Levels []sla.SLA `json:"levels"`
+	}{
+		Levels: h.s.SLAs(ctx),
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *slaHandler) assignServiceLevel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
+
+	var request struct {
+		Level shipping.ServiceLevel `json:"level"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.s.AssignServiceLevel(ctx, trackingID, request.Level); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *slaHandler) evaluateCargo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	trackingID := shipping.TrackingID(chi.URLParam(r, "trackingID"))
+
+	if err := h.s.EvaluateCargo(ctx, trackingID); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *slaHandler) report(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var response = struct {
+		Report sla.Report `json:"report"`
+	}{
+		Report: h.s.Report(ctx),
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
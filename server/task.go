@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/task"
+)
+
+type taskHandler struct {
+	s task.Service
+
+	logger kitlog.Logger
+}
+
+func (h *taskHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Route("/tasks", func(r chi.Router) {
+		r.Get("/", h.listTasks)
+		r.Route("/{taskID}", func(r chi.Router) {
+			r.Get("/", h.loadTask)
+			r.Post("/assign", h.assignTask)
+			r.Post("/status", h.updateStatus)
+		})
+	})
+
+	r.Method("GET", "/docs", http.StripPrefix("/task/v1/docs", http.FileServer(http.Dir("task/docs"))))
+
+	return r
+}
+
+func (h *taskHandler) listTasks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	v := r.URL.Query()
+
+	var status shipping.TaskStatus
+	if s := v.Get("status"); s != "" {
+		var err error
+		if status, err = shipping.ParseTaskStatus(s); err != nil {
+			encodeError(ctx, err, w)
+			return
+		}
+	}
+
+	var response = struct {
+		Tasks []task.Task `json:"tasks"`
+	}{
+		Tasks: h.s.Tasks(ctx, status, shipping.UserID(v.Get("assignee"))),
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *taskHandler) loadTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	t, err := h.s.LoadTask(ctx, shipping.TaskID(chi.URLParam(r, "taskID")))
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Task task.Task `json:"task"`
+	}{
+		Task: t,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *taskHandler) assignTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Assignee string `json:"assignee"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	err := h.s.AssignTask(ctx, shipping.TaskID(chi.URLParam(r, "taskID")), shipping.UserID(request.Assignee))
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *taskHandler) updateStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Status string `json:"status"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	status, err := shipping.ParseTaskStatus(request.Status)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.s.UpdateStatus(ctx, shipping.TaskID(chi.URLParam(r, "taskID")), status); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
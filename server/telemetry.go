@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	kitlog "github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/telemetry"
+)
+
+type telemetryHandler struct {
+	s telemetry.Service
+
+	logger kitlog.Logger
+}
+
+func (h *telemetryHandler) router() chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/readings", h.recordReadings)
+	r.Route("/cargos/{trackingID}", func(r chi.Router) {
+		r.Get("/readings", h.listReadings)
+		r.Put("/limits", h.setLimits)
+	})
+
+	r.Method("GET", "/docs", http.StripPrefix("/telemetry/v1/docs", http.FileServer(http.Dir("telemetry/docs"))))
+
+	return r
+}
+
+func (h *telemetryHandler) recordReadings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request struct {
+		Readings []struct {
+			TrackingID string    `json:"tracking_id"`
+			Sensor     string    `json:"sensor"`
+			DeviceID   string    `json:"device_id"`
+			Value      float64   `json:"value"`
+			Recorded   time.Time `json:"recorded"`
+		} `json:"readings"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	readings := make([]shipping.SensorReading, len(request.Readings))
+	for i, rr := range request.Readings {
+		readings[i] = shipping.SensorReading{
+			TrackingID: shipping.TrackingID(rr.TrackingID),
+			Sensor:     shipping.SensorType(rr.Sensor),
+			DeviceID:   rr.DeviceID,
+			Value:      rr.Value,
+			Recorded:   rr.Recorded,
+		}
+	}
+
+	n, err := h.s.Record(ctx, readings)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Recorded int `json:"recorded"`
+	}{
+		Recorded: n,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *telemetryHandler) listReadings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	from, to, err := parseWindow(r)
+	if err != nil {
+		encodeError(ctx, telemetry.ErrInvalidArgument, w)
+		return
+	}
+
+	readings, err := h.s.Readings(ctx,
+		shipping.TrackingID(chi.URLParam(r, "trackingID")),
+		shipping.SensorType(r.URL.Query().Get("sensor")),
+		from, to,
+	)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	var response = struct {
+		Readings []telemetry.Reading `json:"readings"`
+	}{
+		Readings: readings,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+func (h *telemetryHandler) setLimits(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var request map[string]struct {
+		Min float64 `json:"min"`
+		Max float64 `json:"max"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+
+	limits := make(shipping.SensorLimits)
+	for sensor, lim := range request {
+		limits[shipping.SensorType(sensor)] = shipping.SensorRange{Min: lim.Min, Max: lim.Max}
+	}
+
+	if err := h.s.SetLimits(ctx, shipping.TrackingID(chi.URLParam(r, "trackingID")), limits); err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+}
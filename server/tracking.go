@@ -1,13 +1,17 @@
 package server
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi"
 	kitlog "github.com/go-kit/kit/log"
 
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/locale"
 	"github.com/marcusolsson/goddd/tracking"
 )
 
@@ -19,17 +23,32 @@ type trackingHandler struct {
 
 func (h *trackingHandler) router() chi.Router {
 	r := chi.NewRouter()
+	r.Use(negotiateLanguage)
 	r.Get("/cargos/{trackingID}", h.track)
+	r.Get("/cargos/{trackingID}/events", h.events)
 	r.Method("GET", "/docs", http.StripPrefix("/tracking/v1/docs", http.FileServer(http.Dir("tracking/docs"))))
 	return r
 }
 
+// negotiateLanguage picks the language of tracking views from the
+// Accept-Language header of the request.
+func negotiateLanguage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := locale.Negotiate(r.Header.Get(locale.Header), tracking.Languages)
+
+		w.Header().Set("Content-Language", lang)
+		w.Header().Add("Vary", locale.Header)
+
+		next.ServeHTTP(w, r.WithContext(locale.NewContext(r.Context(), lang)))
+	})
+}
+
 func (h *trackingHandler) track(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 
 	trackingID := chi.URLParam(r, "trackingID")
 
-	c, err := h.s.Track(trackingID)
+	c, err := h.s.Track(ctx, trackingID)
 	if err != nil {
 		encodeError(ctx, err, w)
 		return
@@ -46,3 +65,66 @@ func (h *trackingHandler) track(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (h *trackingHandler) events(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	trackingID := chi.URLParam(r, "trackingID")
+
+	q, err := parseHistoryQuery(r)
+	if err != nil {
+		encodeError(ctx, tracking.ErrInvalidArgument, w)
+		return
+	}
+
+	page, err := h.s.Events(ctx, trackingID, q)
+	if err != nil {
+		encodeError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		h.logger.Log("error", err)
+		encodeError(ctx, err, w)
+		return
+	}
+}
+
+// parseHistoryQuery reads the from, to, cursor, limit and type query
+// parameters. Times are given in RFC 3339 and types are separated by commas.
+func parseHistoryQuery(r *http.Request) (shipping.HandlingHistoryQuery, error) {
+	v := r.URL.Query()
+
+	q := shipping.HandlingHistoryQuery{
+		Cursor: v.Get("cursor"),
+	}
+
+	var err error
+	if s := v.Get("from"); s != "" {
+		if q.From, err = time.Parse(time.RFC3339, s); err != nil {
+			return q, err
+		}
+	}
+	if s := v.Get("to"); s != "" {
+		if q.To, err = time.Parse(time.RFC3339, s); err != nil {
+			return q, err
+		}
+	}
+	if s := v.Get("limit"); s != "" {
+		if q.Limit, err = strconv.Atoi(s); err != nil {
+			return q, err
+		}
+	}
+	if s := v.Get("type"); s != "" {
+		for _, name := range strings.Split(s, ",") {
+			t, err := shipping.ParseHandlingEventType(strings.TrimSpace(name))
+			if err != nil {
+				return q, err
+			}
+			q.Types = append(q.Types, t)
+		}
+	}
+
+	return q, nil
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 
 	shipping "github.com/marcusolsson/goddd"
 	"github.com/marcusolsson/goddd/mock"
+	"github.com/marcusolsson/goddd/servicetest"
 	"github.com/marcusolsson/goddd/tracking"
 )
 
@@ -20,8 +22,8 @@ func TestTrackCargo(t *testing.T) {
 	var cargos mockCargoRepository
 
 	var events mock.HandlingEventRepository
-	events.QueryHandlingHistoryFn = func(shipping.TrackingID) shipping.HandlingHistory {
-		return shipping.HandlingHistory{}
+	events.QueryHandlingHistoryPageFn = func(shipping.TrackingID, shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+		return shipping.HandlingHistoryPage{}, nil
 	}
 
 	s := tracking.NewService(&cargos, &events)
@@ -36,7 +38,7 @@ func TestTrackCargo(t *testing.T) {
 
 	logger := log.NewLogfmtLogger(ioutil.Discard)
 
-	h := New(nil, s, nil, logger)
+	h := New(Services{Tracking: s}, logger)
 
 	req, _ := http.NewRequest("GET", "http://example.com/tracking/v1/cargos/TEST", nil)
 	rec := httptest.NewRecorder()
@@ -70,6 +72,7 @@ func TestTrackCargo(t *testing.T) {
 		Origin:               "SESTO",
 		Destination:          "FIHEL",
 		ArrivalDeadline:      time.Date(2005, 12, 4, 0, 0, 0, 0, time.UTC),
+		DeadlineType:         "Soft",
 		ETA:                  eta.In(time.UTC),
 		StatusText:           "Not received",
 		NextExpectedActivity: "There are currently no expected activities for this shipping.",
@@ -85,15 +88,15 @@ func TestTrackUnknownCargo(t *testing.T) {
 	var cargos mockCargoRepository
 
 	var events mock.HandlingEventRepository
-	events.QueryHandlingHistoryFn = func(shipping.TrackingID) shipping.HandlingHistory {
-		return shipping.HandlingHistory{}
+	events.QueryHandlingHistoryPageFn = func(shipping.TrackingID, shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+		return shipping.HandlingHistoryPage{}, nil
 	}
 
 	s := tracking.NewService(&cargos, &events)
 
 	logger := log.NewLogfmtLogger(ioutil.Discard)
 
-	h := New(nil, s, nil, logger)
+	h := New(Services{Tracking: s}, logger)
 
 	req, _ := http.NewRequest("GET", "http://example.com/tracking/v1/cargos/not_found", nil)
 	rec := httptest.NewRecorder()
@@ -123,6 +126,95 @@ func TestTrackUnknownCargo(t *testing.T) {
 	}
 }
 
+func TestTrackSuggestions(t *testing.T) {
+	var ts servicetest.TrackingService
+	ts.TrackFn = func(ctx context.Context, id string) (tracking.Cargo, error) {
+		return tracking.Cargo{}, &tracking.UnknownCargoError{Suggestions: []shipping.TrackingID{"ABC123"}}
+	}
+
+	h := New(Services{Tracking: &ts}, log.NewNopLogger())
+
+	req, _ := http.NewRequest("GET", "http://example.com/tracking/v1/cargos/ABC132", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("rec.Code = %d; want = %d", rec.Code, http.StatusNotFound)
+	}
+
+	var response struct {
+		Error       string   `json:"error"`
+		Suggestions []string `json:"suggestions"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Error != "unknown cargo" || !reflect.DeepEqual(response.Suggestions, []string{"ABC123"}) {
+		t.Errorf("response = %+v; want ABC123 suggested", response)
+	}
+}
+
+func TestTrackEventsInvalidQuery(t *testing.T) {
+	var cargos mockCargoRepository
+	cargos.Store(shipping.NewCargo("TEST", shipping.RouteSpecification{}))
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryPageFn = func(shipping.TrackingID, shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+		return shipping.HandlingHistoryPage{}, shipping.ErrInvalidCursor
+	}
+
+	s := tracking.NewService(&cargos, &events)
+
+	h := New(Services{Tracking: s}, log.NewLogfmtLogger(ioutil.Discard))
+
+	for _, query := range []string{"from=yesterday", "limit=ten", "cursor=bogus", "type=load,teleport"} {
+		req, _ := http.NewRequest("GET", "http://example.com/tracking/v1/cargos/TEST/events?"+query, nil)
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: rec.Code = %d; want = %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestTrackCargoLanguage(t *testing.T) {
+	var cargos mockCargoRepository
+	cargos.Store(shipping.NewCargo("TEST", shipping.RouteSpecification{}))
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryPageFn = func(shipping.TrackingID, shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+		return shipping.HandlingHistoryPage{}, nil
+	}
+
+	s := tracking.NewService(&cargos, &events)
+
+	h := New(Services{Tracking: s}, log.NewLogfmtLogger(ioutil.Discard))
+
+	req, _ := http.NewRequest("GET", "http://example.com/tracking/v1/cargos/TEST", nil)
+	req.Header.Set("Accept-Language", "sv-SE, en;q=0.8")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if lang := rec.Header().Get("Content-Language"); lang != "sv" {
+		t.Errorf("Content-Language = %q; want = %q", lang, "sv")
+	}
+
+	var response struct {
+		Cargo *tracking.Cargo `json:"cargo"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Ej mottaget"; response.Cargo.StatusText != want {
+		t.Errorf("response.Cargo.StatusText = %q; want = %q", response.Cargo.StatusText, want)
+	}
+}
+
 type mockCargoRepository struct {
 	cargo *shipping.Cargo
 }
@@ -142,3 +234,17 @@ func (r *mockCargoRepository) Find(id shipping.TrackingID) (*shipping.Cargo, err
 func (r *mockCargoRepository) FindAll() []*shipping.Cargo {
 	return []*shipping.Cargo{r.cargo}
 }
+
+func (r *mockCargoRepository) ForEach(fn func(*shipping.Cargo) error) error {
+	if r.cargo == nil {
+		return nil
+	}
+	return fn(r.cargo)
+}
+
+func (r *mockCargoRepository) FindMatching(q shipping.CargoQuery) ([]*shipping.Cargo, error) {
+	if r.cargo == nil || !q.Matches(r.cargo) {
+		return nil, nil
+	}
+	return []*shipping.Cargo{r.cargo}, nil
+}
@@ -0,0 +1,181 @@
+package shipping
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// ServiceLevel is a level of service agreed with a customer, such as
+// "express" or "standard".
+type ServiceLevel string
+
+// SLA holds the targets of a service level agreement.
+type SLA struct {
+	Level ServiceLevel
+
+	// MaxTransit is the longest a cargo may take from being received to
+	// being unloaded at its destination. Zero means no target.
+	MaxTransit time.Duration
+
+	// MaxDwell is the longest a cargo may wait at a transshipment port
+	// between being unloaded and loaded again, counting only the hours the
+	// port works. Zero means no target.
+	MaxDwell time.Duration
+}
+
+// SLATarget identifies a target of a service level agreement.
+type SLATarget int
+
+// Valid SLA targets.
+const (
+	TransitTarget SLATarget = iota
+	DwellTarget
+
+	// DeadlineTarget is the hard arrival deadline of the cargo, which every
+	// service level holds it to.
+	DeadlineTarget
+)
+
+func (t SLATarget) String() string {
+	switch t {
+	case TransitTarget:
+		return "Transit"
+	case DwellTarget:
+		return "Dwell"
+	case DeadlineTarget:
+		return "Deadline"
+	}
+	return ""
+}
+
+// SLABreach describes a target of an SLA that a cargo failed to meet. A cargo
+// still under way breaches a target as soon as it can no longer be met.
+type SLABreach struct {
+	Target SLATarget
+
+	// Location is the transshipment port of a dwell breach.
+	Location UNLocode
+
+	// Actual is how long the cargo took, or dwelled, or for a deadline
+	// breach how late it arrived, or is so far. Limit is the target, zero
+	// for a deadline breach.
+	Actual time.Duration
+	Limit  time.Duration
+}
+
+// Evaluate returns the targets breached by a cargo with the given route
+// specification and handling history, as of now. The calendars may be nil.
+// Arriving after a hard deadline is a breach, while a soft deadline may be
+// missed.
+func (s SLA) Evaluate(rs RouteSpecification, history HandlingHistory, now time.Time, calendars WorkingCalendars) []SLABreach {
+	events := make([]HandlingEvent, len(history.HandlingEvents))
+	copy(events, history.HandlingEvents)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Completed.Before(events[j].Completed)
+	})
+
+	var breaches []SLABreach
+
+	if b, ok := s.evaluateTransit(rs, events, now); ok {
+		breaches = append(breaches, b)
+	}
+
+	breaches = append(breaches, s.evaluateDwell(rs, events, now, calendars)...)
+
+	if b, ok := evaluateDeadline(rs, events, now); ok {
+		breaches = append(breaches, b)
+	}
+
+	return breaches
+}
+
+func evaluateDeadline(rs RouteSpecification, events []HandlingEvent, now time.Time) (SLABreach, bool) {
+	if rs.DeadlineType != HardDeadline || rs.ArrivalDeadline.IsZero() {
+		return SLABreach{}, false
+	}
+
+	arrival := now
+	for _, e := range events {
+		if e.Activity.Type == Unload && e.Activity.Location == rs.Destination {
+			arrival = e.Completed
+			break
+		}
+	}
+
+	if arrival.After(rs.ArrivalDeadline) {
+		return SLABreach{Target: DeadlineTarget, Actual: arrival.Sub(rs.ArrivalDeadline)}, true
+	}
+
+	return SLABreach{}, false
+}
+
+func (s SLA) evaluateTransit(rs RouteSpecification, events []HandlingEvent, now time.Time) (SLABreach, bool) {
+	if s.MaxTransit <= 0 || len(events) == 0 {
+		return SLABreach{}, false
+	}
+
+	var (
+		start = events[0].Completed
+		end   = now
+	)
+	for _, e := range events {
+		if e.Activity.Type == Unload && e.Activity.Location == rs.Destination {
+			end = e.Completed
+			break
+		}
+	}
+
+	if transit := end.Sub(start); transit > s.MaxTransit {
+		return SLABreach{Target: TransitTarget, Actual: transit, Limit: s.MaxTransit}, true
+	}
+
+	return SLABreach{}, false
+}
+
+func (s SLA) evaluateDwell(rs RouteSpecification, events []HandlingEvent, now time.Time, calendars WorkingCalendars) []SLABreach {
+	if s.MaxDwell <= 0 {
+		return nil
+	}
+
+	var breaches []SLABreach
+
+	check := func(loc UNLocode, from, to time.Time) {
+		if dwell := calendars.WorkingTime(loc, from, to); dwell > s.MaxDwell {
+			breaches = append(breaches, SLABreach{Target: DwellTarget, Location: loc, Actual: dwell, Limit: s.MaxDwell})
+		}
+	}
+
+	var unloaded *HandlingEvent
+	for i, e := range events {
+		switch e.Activity.Type {
+		case Unload:
+			if e.Activity.Location == rs.Destination {
+				return breaches
+			}
+			unloaded = &events[i]
+		case Load:
+			if unloaded != nil && unloaded.Activity.Location == e.Activity.Location {
+				check(e.Activity.Location, unloaded.Completed, e.Completed)
+			}
+			unloaded = nil
+		}
+	}
+
+	// The cargo is still waiting to be loaded.
+	if unloaded != nil {
+		check(unloaded.Activity.Location, unloaded.Completed, now)
+	}
+
+	return breaches
+}
+
+// SLARepository provides access to the agreed service levels.
+type SLARepository interface {
+	Store(s *SLA) error
+	Find(l ServiceLevel) (*SLA, error)
+	FindAll() []*SLA
+}
+
+// ErrUnknownServiceLevel is used when a service level could not be found.
+var ErrUnknownServiceLevel = errors.New("unknown service level")
@@ -0,0 +1,165 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSLAEvaluate(t *testing.T) {
+	var (
+		start = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		day   = 24 * time.Hour
+		rs    = RouteSpecification{Origin: CNHKG, Destination: SESTO}
+	)
+
+	event := func(d int, typ HandlingEventType, loc UNLocode) HandlingEvent {
+		return HandlingEvent{
+			Activity:  HandlingActivity{Type: typ, Location: loc},
+			Completed: start.Add(time.Duration(d) * day),
+		}
+	}
+
+	history := HandlingHistory{HandlingEvents: []HandlingEvent{
+		event(0, Receive, CNHKG),
+		event(1, Load, CNHKG),
+		event(5, Unload, USNYC),
+		event(9, Load, USNYC),
+		event(15, Unload, SESTO),
+	}}
+
+	for _, tt := range []struct {
+		name string
+		sla  SLA
+		now  time.Time
+		want []SLABreach
+	}{
+		{
+			name: "met",
+			sla:  SLA{MaxTransit: 20 * day, MaxDwell: 5 * day},
+			now:  start.Add(30 * day),
+		},
+		{
+			name: "transit",
+			sla:  SLA{MaxTransit: 14 * day},
+			now:  start.Add(30 * day),
+			want: []SLABreach{{Target: TransitTarget, Actual: 15 * day, Limit: 14 * day}},
+		},
+		{
+			name: "dwell",
+			sla:  SLA{MaxDwell: 3 * day},
+			now:  start.Add(30 * day),
+			want: []SLABreach{{Target: DwellTarget, Location: USNYC, Actual: 4 * day, Limit: 3 * day}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.sla.Evaluate(rs, history, tt.now, nil)
+			if len(got) != len(tt.want) {
+				t.Fatalf("len(got) = %d; want = %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got[%d] = %+v; want = %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSLAEvaluateUnderWay(t *testing.T) {
+	var (
+		start = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		day   = 24 * time.Hour
+		rs    = RouteSpecification{Origin: CNHKG, Destination: SESTO}
+		sla   = SLA{MaxTransit: 10 * day, MaxDwell: 2 * day}
+	)
+
+	// Registered out of order, still waiting at the transshipment port.
+	history := HandlingHistory{HandlingEvents: []HandlingEvent{
+		{Activity: HandlingActivity{Type: Unload, Location: USNYC}, Completed: start.Add(4 * day)},
+		{Activity: HandlingActivity{Type: Receive, Location: CNHKG}, Completed: start},
+	}}
+
+	if got := sla.Evaluate(rs, history, start.Add(5*day), nil); len(got) != 0 {
+		t.Errorf("got = %+v; want no breaches", got)
+	}
+
+	got := sla.Evaluate(rs, history, start.Add(11*day), nil)
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %d; want = %d", len(got), 2)
+	}
+	if got[0].Target != TransitTarget || got[0].Actual != 11*day {
+		t.Errorf("got[0] = %+v", got[0])
+	}
+	if got[1].Target != DwellTarget || got[1].Location != USNYC || got[1].Actual != 7*day {
+		t.Errorf("got[1] = %+v", got[1])
+	}
+
+	if got := sla.Evaluate(rs, HandlingHistory{}, start.Add(100*day), nil); len(got) != 0 {
+		t.Errorf("got = %+v; want no breaches before the cargo is received", got)
+	}
+}
+
+func TestSLAEvaluateWorkingHours(t *testing.T) {
+	var (
+		start = time.Date(2016, time.April, 1, 0, 0, 0, 0, time.UTC)
+		day   = 24 * time.Hour
+		rs    = RouteSpecification{Origin: CNHKG, Destination: SESTO}
+		sla   = SLA{MaxDwell: 12 * time.Hour}
+	)
+
+	history := HandlingHistory{HandlingEvents: []HandlingEvent{
+		{Activity: HandlingActivity{Type: Receive, Location: CNHKG}, Completed: start},
+		{Activity: HandlingActivity{Type: Unload, Location: USNYC}, Completed: start.Add(17 * time.Hour)},
+	}}
+
+	// Unloaded as the port closed on Friday, the cargo has only waited
+	// through the working hours of Monday.
+	now := start.Add(3*day + 17*time.Hour)
+
+	if got := sla.Evaluate(rs, history, now, nil); len(got) != 1 {
+		t.Errorf("len(got) = %d; want the dwell to be breached around the clock", len(got))
+	}
+
+	calendars := NewWorkingCalendars([]*WorkingCalendar{weekdays()})
+	calendars[USNYC] = calendars[SESTO]
+
+	if got := sla.Evaluate(rs, history, now, calendars); len(got) != 0 {
+		t.Errorf("got = %+v; want no breaches in working hours", got)
+	}
+}
+
+func TestSLAEvaluateDeadline(t *testing.T) {
+	var (
+		start    = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		day      = 24 * time.Hour
+		deadline = start.Add(10 * day)
+		sla      = SLA{}
+	)
+
+	history := HandlingHistory{HandlingEvents: []HandlingEvent{
+		{Activity: HandlingActivity{Type: Receive, Location: CNHKG}, Completed: start},
+		{Activity: HandlingActivity{Type: Unload, Location: SESTO}, Completed: start.Add(12 * day)},
+	}}
+
+	soft := RouteSpecification{Origin: CNHKG, Destination: SESTO, ArrivalDeadline: deadline}
+	if got := sla.Evaluate(soft, history, start.Add(30*day), nil); len(got) != 0 {
+		t.Errorf("got = %+v; want no breaches of a soft deadline", got)
+	}
+
+	hard := soft
+	hard.DeadlineType = HardDeadline
+
+	got := sla.Evaluate(hard, history, start.Add(30*day), nil)
+	if want := (SLABreach{Target: DeadlineTarget, Actual: 2 * day}); len(got) != 1 || got[0] != want {
+		t.Errorf("got = %+v; want = %+v", got, want)
+	}
+
+	// Under way, the deadline is breached once it has passed.
+	underway := HandlingHistory{HandlingEvents: history.HandlingEvents[:1]}
+	if got := sla.Evaluate(hard, underway, start.Add(9*day), nil); len(got) != 0 {
+		t.Errorf("got = %+v; want no breaches before the deadline", got)
+	}
+	if got := sla.Evaluate(hard, underway, start.Add(11*day), nil); len(got) != 1 || got[0].Actual != day {
+		t.Errorf("got = %+v; want a breach of a day", got)
+	}
+}
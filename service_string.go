@@ -0,0 +1,87 @@
+package shipping
+
+import "errors"
+
+// ServiceCode uniquely identifies a service string, e.g. "AE1".
+type ServiceCode string
+
+// TradeLane is a group of service strings serving the same markets, e.g.
+// "Asia-Europe".
+type TradeLane string
+
+// ServiceString is a named loop of ports, such as "Asia-Europe 1", and the
+// voyages sailing it.
+type ServiceString struct {
+	Code      ServiceCode
+	Name      string
+	TradeLane TradeLane
+	Voyages   []VoyageNumber
+}
+
+// Operates returns whether a voyage belongs to the service string.
+func (s ServiceString) Operates(n VoyageNumber) bool {
+	for _, v := range s.Voyages {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceStrings is a set of service strings, e.g. the ones contracted by a
+// customer.
+type ServiceStrings []*ServiceString
+
+// Operates returns whether a voyage belongs to any of the service strings.
+func (ss ServiceStrings) Operates(n VoyageNumber) bool {
+	for _, s := range ss {
+		if s.Operates(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns the service string operating a voyage, if any.
+func (ss ServiceStrings) Find(n VoyageNumber) (*ServiceString, bool) {
+	for _, s := range ss {
+		if s.Operates(n) {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// Lane returns the lane a leg is sailed on: the stretch between its ports on
+// the service string of its voyage, or on its voyage if no service string
+// operates it.
+func (ss ServiceStrings) Lane(l Leg) Lane {
+	result := Lane{From: l.LoadLocation, To: l.UnloadLocation}
+	if s, ok := ss.Find(l.VoyageNumber); ok {
+		result.Service = s.Code
+	} else {
+		result.Voyage = l.VoyageNumber
+	}
+	return result
+}
+
+// Permits returns whether every leg of an itinerary is sailed by a voyage of
+// one of the service strings.
+func (ss ServiceStrings) Permits(i Itinerary) bool {
+	for _, l := range i.Legs {
+		if !ss.Operates(l.VoyageNumber) {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrUnknownServiceString is used when a service string could not be found.
+var ErrUnknownServiceString = errors.New("unknown service string")
+
+// ServiceStringRepository provides access a service string store.
+type ServiceStringRepository interface {
+	Store(s *ServiceString) error
+	Find(ServiceCode) (*ServiceString, error)
+	FindAll() []*ServiceString
+}
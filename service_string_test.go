@@ -0,0 +1,27 @@
+package shipping
+
+import "testing"
+
+func TestServiceStrings_Permits(t *testing.T) {
+	ss := ServiceStrings{AE1, NE1}
+
+	permitted := Itinerary{Legs: []Leg{
+		{VoyageNumber: "V300", LoadLocation: JNTKO, UnloadLocation: DEHAM},
+		{VoyageNumber: "V400", LoadLocation: DEHAM, UnloadLocation: SESTO},
+	}}
+	if !ss.Permits(permitted) {
+		t.Errorf("itinerary on %s and %s should be permitted", AE1.Code, NE1.Code)
+	}
+
+	rejected := Itinerary{Legs: []Leg{
+		{VoyageNumber: "V100", LoadLocation: CNHKG, UnloadLocation: JNTKO},
+		{VoyageNumber: "V300", LoadLocation: JNTKO, UnloadLocation: DEHAM},
+	}}
+	if ss.Permits(rejected) {
+		t.Errorf("itinerary on %s should not be permitted", TP1.Code)
+	}
+
+	if s, ok := ss.Find("V400"); !ok || s != NE1 {
+		t.Errorf("Find(V400) = %v; want = %v", s, NE1)
+	}
+}
@@ -0,0 +1,84 @@
+package servicetest
+
+import (
+	"context"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/amendment"
+)
+
+// AmendmentService is a mock amendment service.
+type AmendmentService struct {
+	RequestDestinationChangeFn      func(context.Context, amendment.Credentials, shipping.TrackingID, shipping.UNLocode) (shipping.AmendmentID, error)
+	RequestDestinationChangeInvoked bool
+
+	RequestDeadlineExtensionFn      func(context.Context, amendment.Credentials, shipping.TrackingID, time.Time) (shipping.AmendmentID, error)
+	RequestDeadlineExtensionInvoked bool
+
+	ApproveFn      func(context.Context, amendment.Credentials, shipping.AmendmentID) error
+	ApproveInvoked bool
+
+	RejectFn      func(context.Context, amendment.Credentials, shipping.AmendmentID, string) error
+	RejectInvoked bool
+
+	AmendmentsFn      func(context.Context, shipping.TrackingID) []amendment.Amendment
+	AmendmentsInvoked bool
+
+	PendingFn      func(context.Context) []amendment.Amendment
+	PendingInvoked bool
+
+	RegisterUserFn      func(context.Context, shipping.UserID, string, string, []shipping.Role) error
+	RegisterUserInvoked bool
+
+	UsersFn      func(context.Context) []amendment.User
+	UsersInvoked bool
+}
+
+// RequestDestinationChange calls the RequestDestinationChangeFn.
+func (s *AmendmentService) RequestDestinationChange(ctx context.Context, by amendment.Credentials, id shipping.TrackingID, destination shipping.UNLocode) (shipping.AmendmentID, error) {
+	s.RequestDestinationChangeInvoked = true
+	return s.RequestDestinationChangeFn(ctx, by, id, destination)
+}
+
+// RequestDeadlineExtension calls the RequestDeadlineExtensionFn.
+func (s *AmendmentService) RequestDeadlineExtension(ctx context.Context, by amendment.Credentials, id shipping.TrackingID, deadline time.Time) (shipping.AmendmentID, error) {
+	s.RequestDeadlineExtensionInvoked = true
+	return s.RequestDeadlineExtensionFn(ctx, by, id, deadline)
+}
+
+// Approve calls the ApproveFn.
+func (s *AmendmentService) Approve(ctx context.Context, by amendment.Credentials, id shipping.AmendmentID) error {
+	s.ApproveInvoked = true
+	return s.ApproveFn(ctx, by, id)
+}
+
+// Reject calls the RejectFn.
+func (s *AmendmentService) Reject(ctx context.Context, by amendment.Credentials, id shipping.AmendmentID, reason string) error {
+	s.RejectInvoked = true
+	return s.RejectFn(ctx, by, id, reason)
+}
+
+// Amendments calls the AmendmentsFn.
+func (s *AmendmentService) Amendments(ctx context.Context, id shipping.TrackingID) []amendment.Amendment {
+	s.AmendmentsInvoked = true
+	return s.AmendmentsFn(ctx, id)
+}
+
+// Pending calls the PendingFn.
+func (s *AmendmentService) Pending(ctx context.Context) []amendment.Amendment {
+	s.PendingInvoked = true
+	return s.PendingFn(ctx)
+}
+
+// RegisterUser calls the RegisterUserFn.
+func (s *AmendmentService) RegisterUser(ctx context.Context, id shipping.UserID, name string, key string, roles []shipping.Role) error {
+	s.RegisterUserInvoked = true
+	return s.RegisterUserFn(ctx, id, name, key, roles)
+}
+
+// Users calls the UsersFn.
+func (s *AmendmentService) Users(ctx context.Context) []amendment.User {
+	s.UsersInvoked = true
+	return s.UsersFn(ctx)
+}
@@ -0,0 +1,38 @@
+package servicetest
+
+import (
+	"context"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/audit"
+)
+
+// AuditService is a mock audit service.
+type AuditService struct {
+	RecordFn      func(context.Context, string, shipping.AuditAction, shipping.TrackingID, string) error
+	RecordInvoked bool
+
+	EntriesFn      func(context.Context, shipping.AuditQuery) ([]audit.Entry, error)
+	EntriesInvoked bool
+
+	VerifyFn      func(context.Context) (audit.Verification, error)
+	VerifyInvoked bool
+}
+
+// Record calls the RecordFn.
+func (s *AuditService) Record(ctx context.Context, actor string, action shipping.AuditAction, id shipping.TrackingID, detail string) error {
+	s.RecordInvoked = true
+	return s.RecordFn(ctx, actor, action, id, detail)
+}
+
+// Entries calls the EntriesFn.
+func (s *AuditService) Entries(ctx context.Context, q shipping.AuditQuery) ([]audit.Entry, error) {
+	s.EntriesInvoked = true
+	return s.EntriesFn(ctx, q)
+}
+
+// Verify calls the VerifyFn.
+func (s *AuditService) Verify(ctx context.Context) (audit.Verification, error) {
+	s.VerifyInvoked = true
+	return s.VerifyFn(ctx)
+}
@@ -0,0 +1,312 @@
+package servicetest
+
+import (
+	"context"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/booking"
+)
+
+// BookingService is a mock booking service.
+type BookingService struct {
+	BookNewCargoFn      func(context.Context, shipping.CustomerID, shipping.UNLocode, shipping.UNLocode, time.Time, shipping.EquipmentType) (shipping.TrackingID, error)
+	BookNewCargoInvoked bool
+
+	SaveDraftFn      func(context.Context, shipping.BookingDraft) (shipping.DraftID, error)
+	SaveDraftInvoked bool
+
+	DraftsFn      func(context.Context, shipping.CustomerID) []booking.Draft
+	DraftsInvoked bool
+
+	BookDraftFn      func(context.Context, shipping.DraftID) (shipping.TrackingID, error)
+	BookDraftInvoked bool
+
+	DiscardDraftFn      func(context.Context, shipping.DraftID) error
+	DiscardDraftInvoked bool
+
+	LoadCargoFn      func(context.Context, shipping.TrackingID) (booking.Cargo, error)
+	LoadCargoInvoked bool
+
+	TimelineFn      func(context.Context, shipping.TrackingID) ([]booking.TimelineEntry, error)
+	TimelineInvoked bool
+
+	RequestPossibleRoutesForCargoFn      func(context.Context, shipping.TrackingID, ...shipping.ServiceCode) booking.RouteCandidates
+	RequestPossibleRoutesForCargoInvoked bool
+
+	CheckDeadlineFeasibilityFn      func(context.Context, shipping.UNLocode, shipping.UNLocode, time.Time) (booking.Feasibility, error)
+	CheckDeadlineFeasibilityInvoked bool
+
+	AssignCargoToRouteFn      func(context.Context, shipping.TrackingID, shipping.Itinerary) error
+	AssignCargoToRouteInvoked bool
+
+	ReplaceLegFn      func(context.Context, shipping.TrackingID, int, shipping.Leg) error
+	ReplaceLegInvoked bool
+
+	RollCargoFn      func(context.Context, shipping.TrackingID, shipping.VoyageNumber) error
+	RollCargoInvoked bool
+
+	ChangeDestinationFn      func(context.Context, shipping.TrackingID, shipping.UNLocode) error
+	ChangeDestinationInvoked bool
+
+	ChangeDeadlineTypeFn      func(context.Context, shipping.TrackingID, shipping.DeadlineType) error
+	ChangeDeadlineTypeInvoked bool
+
+	TransferCargoFn      func(context.Context, shipping.TrackingID, shipping.CustomerID, shipping.CustomerID) error
+	TransferCargoInvoked bool
+
+	DeclareCargoFn      func(context.Context, shipping.TrackingID, shipping.CargoDeclaration) error
+	DeclareCargoInvoked bool
+
+	RejectCargoFn      func(context.Context, shipping.TrackingID, time.Time) (shipping.TrackingID, error)
+	RejectCargoInvoked bool
+
+	IssueReleaseCodeFn      func(context.Context, shipping.TrackingID) (string, error)
+	IssueReleaseCodeInvoked bool
+
+	OmitPortCallFn      func(context.Context, shipping.VoyageNumber, shipping.UNLocode) ([]shipping.TrackingID, error)
+	OmitPortCallInvoked bool
+
+	RerouteAllViaFn      func(context.Context, shipping.UNLocode, time.Time, time.Time) (booking.RerouteResult, error)
+	RerouteAllViaInvoked bool
+
+	CargosFn      func(context.Context) []booking.Cargo
+	CargosInvoked bool
+
+	StreamCargosFn      func(context.Context, func(booking.Cargo) error) error
+	StreamCargosInvoked bool
+
+	SearchCargosFn      func(context.Context, string) ([]booking.Cargo, error)
+	SearchCargosInvoked bool
+
+	LocationsFn      func(context.Context) []booking.Location
+	LocationsInvoked bool
+
+	RecalculateDeliveryFn      func(context.Context, shipping.TrackingID) (booking.Cargo, error)
+	RecalculateDeliveryInvoked bool
+
+	RecalculateAllDeliveriesFn      func(context.Context, booking.ProgressFunc) (booking.RecalculationReport, error)
+	RecalculateAllDeliveriesInvoked bool
+
+	EmissionsReportFn      func(context.Context, time.Time, time.Time) (booking.EmissionsReport, error)
+	EmissionsReportInvoked bool
+
+	CapacityForecastFn      func(context.Context, time.Time, time.Time) ([]booking.VoyageUtilization, error)
+	CapacityForecastInvoked bool
+
+	OverbookingReportFn      func(context.Context, time.Time, time.Time) ([]booking.OverbookedVoyage, error)
+	OverbookingReportInvoked bool
+}
+
+// BookNewCargo calls the BookNewCargoFn.
+func (s *BookingService) BookNewCargo(ctx context.Context, customer shipping.CustomerID, origin shipping.UNLocode, destination shipping.UNLocode, deadline time.Time, equipment shipping.EquipmentType) (shipping.TrackingID, error) {
+	s.BookNewCargoInvoked = true
+	return s.BookNewCargoFn(ctx, customer, origin, destination, deadline, equipment)
+}
+
+// SaveDraft calls the SaveDraftFn.
+func (s *BookingService) SaveDraft(ctx context.Context, d shipping.BookingDraft) (shipping.DraftID, error) {
+	s.SaveDraftInvoked = true
+	return s.SaveDraftFn(ctx, d)
+}
+
+// Drafts calls the DraftsFn.
+func (s *BookingService) Drafts(ctx context.Context, customer shipping.CustomerID) []booking.Draft {
+	s.DraftsInvoked = true
+	return s.DraftsFn(ctx, customer)
+}
+
+// BookDraft calls the BookDraftFn.
+func (s *BookingService) BookDraft(ctx context.Context, id shipping.DraftID) (shipping.TrackingID, error) {
+	s.BookDraftInvoked = true
+	return s.BookDraftFn(ctx, id)
+}
+
+// DiscardDraft calls the DiscardDraftFn.
+func (s *BookingService) DiscardDraft(ctx context.Context, id shipping.DraftID) error {
+	s.DiscardDraftInvoked = true
+	return s.DiscardDraftFn(ctx, id)
+}
+
+// LoadCargo calls the LoadCargoFn.
+func (s *BookingService) LoadCargo(ctx context.Context, id shipping.TrackingID) (booking.Cargo, error) {
+	s.LoadCargoInvoked = true
+	return s.LoadCargoFn(ctx, id)
+}
+
+// Timeline calls the TimelineFn.
+func (s *BookingService) Timeline(ctx context.Context, id shipping.TrackingID) ([]booking.TimelineEntry, error) {
+	s.TimelineInvoked = true
+	return s.TimelineFn(ctx, id)
+}
+
+// RequestPossibleRoutesForCargo calls the RequestPossibleRoutesForCargoFn.
+func (s *BookingService) RequestPossibleRoutesForCargo(ctx context.Context, id shipping.TrackingID, services ...shipping.ServiceCode) booking.RouteCandidates {
+	s.RequestPossibleRoutesForCargoInvoked = true
+	return s.RequestPossibleRoutesForCargoFn(ctx, id, services...)
+}
+
+// CheckDeadlineFeasibility calls the CheckDeadlineFeasibilityFn.
+func (s *BookingService) CheckDeadlineFeasibility(ctx context.Context, origin shipping.UNLocode, destination shipping.UNLocode, deadline time.Time) (booking.Feasibility, error) {
+	s.CheckDeadlineFeasibilityInvoked = true
+	return s.CheckDeadlineFeasibilityFn(ctx, origin, destination, deadline)
+}
+
+// AssignCargoToRoute calls the AssignCargoToRouteFn.
+func (s *BookingService) AssignCargoToRoute(ctx context.Context, id shipping.TrackingID, itinerary shipping.Itinerary) error {
+	s.AssignCargoToRouteInvoked = true
+	return s.AssignCargoToRouteFn(ctx, id, itinerary)
+}
+
+// ReplaceLeg calls the ReplaceLegFn.
+func (s *BookingService) ReplaceLeg(ctx context.Context, id shipping.TrackingID, index int, leg shipping.Leg) error {
+	s.ReplaceLegInvoked = true
+	return s.ReplaceLegFn(ctx, id, index, leg)
+}
+
+// RollCargo calls the RollCargoFn.
+func (s *BookingService) RollCargo(ctx context.Context, id shipping.TrackingID, voyage shipping.VoyageNumber) error {
+	s.RollCargoInvoked = true
+	return s.RollCargoFn(ctx, id, voyage)
+}
+
+// ChangeDestination calls the ChangeDestinationFn.
+func (s *BookingService) ChangeDestination(ctx context.Context, id shipping.TrackingID, destination shipping.UNLocode) error {
+	s.ChangeDestinationInvoked = true
+	return s.ChangeDestinationFn(ctx, id, destination)
+}
+
+// ChangeDeadlineType calls the ChangeDeadlineTypeFn.
+func (s *BookingService) ChangeDeadlineType(ctx context.Context, id shipping.TrackingID, t shipping.DeadlineType) error {
+	s.ChangeDeadlineTypeInvoked = true
+	return s.ChangeDeadlineTypeFn(ctx, id, t)
+}
+
+// TransferCargo calls the TransferCargoFn.
+func (s *BookingService) TransferCargo(ctx context.Context, id shipping.TrackingID, from, to shipping.CustomerID) error {
+	s.TransferCargoInvoked = true
+	return s.TransferCargoFn(ctx, id, from, to)
+}
+
+// DeclareCargo calls the DeclareCargoFn.
+func (s *BookingService) DeclareCargo(ctx context.Context, id shipping.TrackingID, d shipping.CargoDeclaration) error {
+	s.DeclareCargoInvoked = true
+	return s.DeclareCargoFn(ctx, id, d)
+}
+
+// RejectCargo calls the RejectCargoFn.
+func (s *BookingService) RejectCargo(ctx context.Context, id shipping.TrackingID, deadline time.Time) (shipping.TrackingID, error) {
+	s.RejectCargoInvoked = true
+	return s.RejectCargoFn(ctx, id, deadline)
+}
+
+// IssueReleaseCode calls the IssueReleaseCodeFn.
+func (s *BookingService) IssueReleaseCode(ctx context.Context, id shipping.TrackingID) (string, error) {
+	s.IssueReleaseCodeInvoked = true
+	return s.IssueReleaseCodeFn(ctx, id)
+}
+
+// OmitPortCall calls the OmitPortCallFn.
+func (s *BookingService) OmitPortCall(ctx context.Context, voyage shipping.VoyageNumber, locode shipping.UNLocode) ([]shipping.TrackingID, error) {
+	s.OmitPortCallInvoked = true
+	return s.OmitPortCallFn(ctx, voyage, locode)
+}
+
+// RerouteAllVia calls the RerouteAllViaFn.
+func (s *BookingService) RerouteAllVia(ctx context.Context, locode shipping.UNLocode, from, to time.Time) (booking.RerouteResult, error) {
+	s.RerouteAllViaInvoked = true
+	return s.RerouteAllViaFn(ctx, locode, from, to)
+}
+
+// Cargos calls the CargosFn.
+func (s *BookingService) Cargos(ctx context.Context) []booking.Cargo {
+	s.CargosInvoked = true
+	return s.CargosFn(ctx)
+}
+
+// StreamCargos calls the StreamCargosFn.
+func (s *BookingService) StreamCargos(ctx context.Context, fn func(booking.Cargo) error) error {
+	s.StreamCargosInvoked = true
+	return s.StreamCargosFn(ctx, fn)
+}
+
+// SearchCargos calls the SearchCargosFn.
+func (s *BookingService) SearchCargos(ctx context.Context, filter string) ([]booking.Cargo, error) {
+	s.SearchCargosInvoked = true
+	return s.SearchCargosFn(ctx, filter)
+}
+
+// Locations calls the LocationsFn.
+func (s *BookingService) Locations(ctx context.Context) []booking.Location {
+	s.LocationsInvoked = true
+	return s.LocationsFn(ctx)
+}
+
+// RecalculateDelivery calls the RecalculateDeliveryFn.
+func (s *BookingService) RecalculateDelivery(ctx context.Context, id shipping.TrackingID) (booking.Cargo, error) {
+	s.RecalculateDeliveryInvoked = true
+	return s.RecalculateDeliveryFn(ctx, id)
+}
+
+// RecalculateAllDeliveries calls the RecalculateAllDeliveriesFn.
+func (s *BookingService) RecalculateAllDeliveries(ctx context.Context, progress booking.ProgressFunc) (booking.RecalculationReport, error) {
+	s.RecalculateAllDeliveriesInvoked = true
+	return s.RecalculateAllDeliveriesFn(ctx, progress)
+}
+
+// EmissionsReport calls the EmissionsReportFn.
+func (s *BookingService) EmissionsReport(ctx context.Context, from time.Time, to time.Time) (booking.EmissionsReport, error) {
+	s.EmissionsReportInvoked = true
+	return s.EmissionsReportFn(ctx, from, to)
+}
+
+// CapacityForecast calls the CapacityForecastFn.
+func (s *BookingService) CapacityForecast(ctx context.Context, from time.Time, to time.Time) ([]booking.VoyageUtilization, error) {
+	s.CapacityForecastInvoked = true
+	return s.CapacityForecastFn(ctx, from, to)
+}
+
+// OverbookingReport calls the OverbookingReportFn.
+func (s *BookingService) OverbookingReport(ctx context.Context, from time.Time, to time.Time) ([]booking.OverbookedVoyage, error) {
+	s.OverbookingReportInvoked = true
+	return s.OverbookingReportFn(ctx, from, to)
+}
+
+// BookingEventHandler is a mock booking event handler.
+type BookingEventHandler struct {
+	CargoWasBookedFn      func(context.Context, *shipping.Cargo)
+	CargoWasBookedInvoked bool
+
+	CargoWasRoutedFn      func(context.Context, *shipping.Cargo)
+	CargoWasRoutedInvoked bool
+
+	CargoWasRolledFn      func(context.Context, *shipping.Cargo, shipping.VoyageNumber)
+	CargoWasRolledInvoked bool
+
+	CargoWasTransferredFn      func(context.Context, *shipping.Cargo, shipping.CustomerID)
+	CargoWasTransferredInvoked bool
+}
+
+// CargoWasBooked calls the CargoWasBookedFn.
+func (h *BookingEventHandler) CargoWasBooked(ctx context.Context, c *shipping.Cargo) {
+	h.CargoWasBookedInvoked = true
+	h.CargoWasBookedFn(ctx, c)
+}
+
+// CargoWasRouted calls the CargoWasRoutedFn.
+func (h *BookingEventHandler) CargoWasRouted(ctx context.Context, c *shipping.Cargo) {
+	h.CargoWasRoutedInvoked = true
+	h.CargoWasRoutedFn(ctx, c)
+}
+
+// CargoWasRolled calls the CargoWasRolledFn.
+func (h *BookingEventHandler) CargoWasRolled(ctx context.Context, c *shipping.Cargo, from shipping.VoyageNumber) {
+	h.CargoWasRolledInvoked = true
+	h.CargoWasRolledFn(ctx, c, from)
+}
+
+// CargoWasTransferred calls the CargoWasTransferredFn.
+func (h *BookingEventHandler) CargoWasTransferred(ctx context.Context, c *shipping.Cargo, from shipping.CustomerID) {
+	h.CargoWasTransferredInvoked = true
+	h.CargoWasTransferredFn(ctx, c, from)
+}
@@ -0,0 +1,38 @@
+package servicetest
+
+import (
+	"context"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/carrier"
+)
+
+// CarrierService is a mock carrier service.
+type CarrierService struct {
+	PendingLegsFn      func(context.Context, string, shipping.VoyageNumber) ([]carrier.Leg, error)
+	PendingLegsInvoked bool
+
+	ConfirmLegFn      func(context.Context, string, shipping.TrackingID, shipping.VoyageNumber, shipping.UNLocode, string) error
+	ConfirmLegInvoked bool
+
+	RejectLegFn      func(context.Context, string, shipping.TrackingID, shipping.VoyageNumber, shipping.UNLocode) error
+	RejectLegInvoked bool
+}
+
+// PendingLegs calls the PendingLegsFn.
+func (s *CarrierService) PendingLegs(ctx context.Context, token string, voyage shipping.VoyageNumber) ([]carrier.Leg, error) {
+	s.PendingLegsInvoked = true
+	return s.PendingLegsFn(ctx, token, voyage)
+}
+
+// ConfirmLeg calls the ConfirmLegFn.
+func (s *CarrierService) ConfirmLeg(ctx context.Context, token string, id shipping.TrackingID, voyage shipping.VoyageNumber, from shipping.UNLocode, reference string) error {
+	s.ConfirmLegInvoked = true
+	return s.ConfirmLegFn(ctx, token, id, voyage, from, reference)
+}
+
+// RejectLeg calls the RejectLegFn.
+func (s *CarrierService) RejectLeg(ctx context.Context, token string, id shipping.TrackingID, voyage shipping.VoyageNumber, from shipping.UNLocode) error {
+	s.RejectLegInvoked = true
+	return s.RejectLegFn(ctx, token, id, voyage, from)
+}
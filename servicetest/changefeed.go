@@ -0,0 +1,30 @@
+package servicetest
+
+import (
+	"context"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/changefeed"
+)
+
+// ChangeFeedService is a mock change feed service.
+type ChangeFeedService struct {
+	RecordFn      func(context.Context, shipping.CargoChange) error
+	RecordInvoked bool
+
+	ChangesFn      func(context.Context, string, int, time.Duration) (changefeed.Page, error)
+	ChangesInvoked bool
+}
+
+// Record calls the RecordFn.
+func (s *ChangeFeedService) Record(ctx context.Context, c shipping.CargoChange) error {
+	s.RecordInvoked = true
+	return s.RecordFn(ctx, c)
+}
+
+// Changes calls the ChangesFn.
+func (s *ChangeFeedService) Changes(ctx context.Context, since string, limit int, wait time.Duration) (changefeed.Page, error) {
+	s.ChangesInvoked = true
+	return s.ChangesFn(ctx, since, limit, wait)
+}
@@ -0,0 +1,46 @@
+package servicetest
+
+import (
+	"context"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// ConsolidationService is a mock consolidation service.
+type ConsolidationService struct {
+	ConsolidateFn      func(context.Context, []shipping.TrackingID) (shipping.TrackingID, error)
+	ConsolidateInvoked bool
+
+	AddFn      func(context.Context, shipping.TrackingID, shipping.TrackingID) error
+	AddInvoked bool
+
+	RemoveFn      func(context.Context, shipping.TrackingID, shipping.TrackingID) error
+	RemoveInvoked bool
+
+	SplitFn      func(context.Context, shipping.TrackingID) ([]shipping.TrackingID, error)
+	SplitInvoked bool
+}
+
+// Consolidate calls the ConsolidateFn.
+func (s *ConsolidationService) Consolidate(ctx context.Context, ids []shipping.TrackingID) (shipping.TrackingID, error) {
+	s.ConsolidateInvoked = true
+	return s.ConsolidateFn(ctx, ids)
+}
+
+// Add calls the AddFn.
+func (s *ConsolidationService) Add(ctx context.Context, master shipping.TrackingID, id shipping.TrackingID) error {
+	s.AddInvoked = true
+	return s.AddFn(ctx, master, id)
+}
+
+// Remove calls the RemoveFn.
+func (s *ConsolidationService) Remove(ctx context.Context, master shipping.TrackingID, id shipping.TrackingID) error {
+	s.RemoveInvoked = true
+	return s.RemoveFn(ctx, master, id)
+}
+
+// Split calls the SplitFn.
+func (s *ConsolidationService) Split(ctx context.Context, master shipping.TrackingID) ([]shipping.TrackingID, error) {
+	s.SplitInvoked = true
+	return s.SplitFn(ctx, master)
+}
@@ -0,0 +1,29 @@
+package servicetest
+
+import (
+	"context"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/dashboard"
+)
+
+// DashboardService is a mock dashboard service.
+type DashboardService struct {
+	OverviewFn      func(context.Context) dashboard.Overview
+	OverviewInvoked bool
+
+	CargoFn      func(context.Context, shipping.TrackingID) (dashboard.Cargo, error)
+	CargoInvoked bool
+}
+
+// Overview calls the OverviewFn.
+func (s *DashboardService) Overview(ctx context.Context) dashboard.Overview {
+	s.OverviewInvoked = true
+	return s.OverviewFn(ctx)
+}
+
+// Cargo calls the CargoFn.
+func (s *DashboardService) Cargo(ctx context.Context, id shipping.TrackingID) (dashboard.Cargo, error) {
+	s.CargoInvoked = true
+	return s.CargoFn(ctx, id)
+}
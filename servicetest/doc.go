@@ -0,0 +1,59 @@
+// Package servicetest provides mock implementations of the service
+// interfaces, for unit testing code that depends on the services. Mocks of
+// the domain repositories are found in package mock, which the services test
+// against.
+//
+// Each method calls the function in the corresponding Fn field and records
+// that it was invoked.
+package servicetest
+
+import (
+	"github.com/marcusolsson/goddd/amendment"
+	"github.com/marcusolsson/goddd/audit"
+	"github.com/marcusolsson/goddd/booking"
+	"github.com/marcusolsson/goddd/carrier"
+	"github.com/marcusolsson/goddd/changefeed"
+	"github.com/marcusolsson/goddd/consolidation"
+	"github.com/marcusolsson/goddd/dashboard"
+	"github.com/marcusolsson/goddd/handling"
+	"github.com/marcusolsson/goddd/inbound"
+	"github.com/marcusolsson/goddd/inspection"
+	"github.com/marcusolsson/goddd/portal"
+	"github.com/marcusolsson/goddd/portstatus"
+	"github.com/marcusolsson/goddd/position"
+	"github.com/marcusolsson/goddd/reconciliation"
+	"github.com/marcusolsson/goddd/replication"
+	"github.com/marcusolsson/goddd/scheduling"
+	"github.com/marcusolsson/goddd/sla"
+	"github.com/marcusolsson/goddd/task"
+	"github.com/marcusolsson/goddd/telemetry"
+	"github.com/marcusolsson/goddd/tracking"
+)
+
+var (
+	_ booking.Service         = (*BookingService)(nil)
+	_ booking.EventHandler    = (*BookingEventHandler)(nil)
+	_ handling.Service        = (*HandlingService)(nil)
+	_ handling.EventHandler   = (*HandlingEventHandler)(nil)
+	_ tracking.Service        = (*TrackingService)(nil)
+	_ inspection.Service      = (*InspectionService)(nil)
+	_ inspection.EventHandler = (*InspectionEventHandler)(nil)
+	_ scheduling.Service      = (*SchedulingService)(nil)
+	_ consolidation.Service   = (*ConsolidationService)(nil)
+	_ portstatus.Service      = (*PortStatusService)(nil)
+	_ sla.Service             = (*SLAService)(nil)
+	_ sla.EventHandler        = (*SLAEventHandler)(nil)
+	_ portal.Service          = (*PortalService)(nil)
+	_ amendment.Service       = (*AmendmentService)(nil)
+	_ task.Service            = (*TaskService)(nil)
+	_ inbound.Service         = (*InboundService)(nil)
+	_ audit.Service           = (*AuditService)(nil)
+	_ changefeed.Service      = (*ChangeFeedService)(nil)
+	_ carrier.Service         = (*CarrierService)(nil)
+	_ position.Service        = (*PositionService)(nil)
+	_ telemetry.Service       = (*TelemetryService)(nil)
+	_ telemetry.EventHandler  = (*TelemetryEventHandler)(nil)
+	_ dashboard.Service       = (*DashboardService)(nil)
+	_ reconciliation.Service  = (*ReconciliationService)(nil)
+	_ replication.Service     = (*ReplicationService)(nil)
+)
@@ -0,0 +1,60 @@
+package servicetest
+
+import (
+	"context"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/handling"
+)
+
+// HandlingService is a mock handling service.
+type HandlingService struct {
+	RegisterHandlingEventFn      func(context.Context, handling.Credentials, time.Time, shipping.TrackingID, shipping.VoyageNumber, shipping.UNLocode, shipping.HandlingEventType) error
+	RegisterHandlingEventInvoked bool
+
+	StreamHandlingEventsFn      func(context.Context, func(shipping.HandlingEvent) error) error
+	StreamHandlingEventsInvoked bool
+
+	RegisterTerminalFn      func(context.Context, string, shipping.TerminalID, string, shipping.UNLocode, string, string) error
+	RegisterTerminalInvoked bool
+
+	TerminalsFn      func(context.Context, string) ([]handling.Terminal, error)
+	TerminalsInvoked bool
+}
+
+// RegisterHandlingEvent calls the RegisterHandlingEventFn.
+func (s *HandlingService) RegisterHandlingEvent(ctx context.Context, source handling.Credentials, completed time.Time, id shipping.TrackingID, voyageNumber shipping.VoyageNumber, unLocode shipping.UNLocode, eventType shipping.HandlingEventType) error {
+	s.RegisterHandlingEventInvoked = true
+	return s.RegisterHandlingEventFn(ctx, source, completed, id, voyageNumber, unLocode, eventType)
+}
+
+// StreamHandlingEvents calls the StreamHandlingEventsFn.
+func (s *HandlingService) StreamHandlingEvents(ctx context.Context, fn func(shipping.HandlingEvent) error) error {
+	s.StreamHandlingEventsInvoked = true
+	return s.StreamHandlingEventsFn(ctx, fn)
+}
+
+// RegisterTerminal calls the RegisterTerminalFn.
+func (s *HandlingService) RegisterTerminal(ctx context.Context, token string, id shipping.TerminalID, name string, loc shipping.UNLocode, key, secret string) error {
+	s.RegisterTerminalInvoked = true
+	return s.RegisterTerminalFn(ctx, token, id, name, loc, key, secret)
+}
+
+// Terminals calls the TerminalsFn.
+func (s *HandlingService) Terminals(ctx context.Context, token string) ([]handling.Terminal, error) {
+	s.TerminalsInvoked = true
+	return s.TerminalsFn(ctx, token)
+}
+
+// HandlingEventHandler is a mock handling event handler.
+type HandlingEventHandler struct {
+	CargoWasHandledFn      func(context.Context, shipping.HandlingEvent)
+	CargoWasHandledInvoked bool
+}
+
+// CargoWasHandled calls the CargoWasHandledFn.
+func (h *HandlingEventHandler) CargoWasHandled(ctx context.Context, e shipping.HandlingEvent) {
+	h.CargoWasHandledInvoked = true
+	h.CargoWasHandledFn(ctx, e)
+}
@@ -0,0 +1,19 @@
+package servicetest
+
+import (
+	"context"
+
+	"github.com/marcusolsson/goddd/inbound"
+)
+
+// InboundService is a mock inbound mail service.
+type InboundService struct {
+	ReceiveFn      func(context.Context, string, inbound.Message) (int, error)
+	ReceiveInvoked bool
+}
+
+// Receive calls the ReceiveFn.
+func (s *InboundService) Receive(ctx context.Context, token string, m inbound.Message) (int, error) {
+	s.ReceiveInvoked = true
+	return s.ReceiveFn(ctx, token, m)
+}
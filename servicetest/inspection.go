@@ -0,0 +1,50 @@
+package servicetest
+
+import (
+	"context"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// InspectionService is a mock inspection service.
+type InspectionService struct {
+	InspectCargoFn      func(context.Context, shipping.TrackingID)
+	InspectCargoInvoked bool
+}
+
+// InspectCargo calls the InspectCargoFn.
+func (s *InspectionService) InspectCargo(ctx context.Context, id shipping.TrackingID) {
+	s.InspectCargoInvoked = true
+	s.InspectCargoFn(ctx, id)
+}
+
+// InspectionEventHandler is a mock inspection event handler.
+type InspectionEventHandler struct {
+	CargoWasMisdirectedFn      func(context.Context, *shipping.Cargo)
+	CargoWasMisdirectedInvoked bool
+
+	CargoHasArrivedFn      func(context.Context, *shipping.Cargo)
+	CargoHasArrivedInvoked bool
+
+	CargoDeadlineAtRiskFn      func(context.Context, *shipping.Cargo, time.Duration)
+	CargoDeadlineAtRiskInvoked bool
+}
+
+// CargoWasMisdirected calls the CargoWasMisdirectedFn.
+func (h *InspectionEventHandler) CargoWasMisdirected(ctx context.Context, c *shipping.Cargo) {
+	h.CargoWasMisdirectedInvoked = true
+	h.CargoWasMisdirectedFn(ctx, c)
+}
+
+// CargoHasArrived calls the CargoHasArrivedFn.
+func (h *InspectionEventHandler) CargoHasArrived(ctx context.Context, c *shipping.Cargo) {
+	h.CargoHasArrivedInvoked = true
+	h.CargoHasArrivedFn(ctx, c)
+}
+
+// CargoDeadlineAtRisk calls the CargoDeadlineAtRiskFn.
+func (h *InspectionEventHandler) CargoDeadlineAtRisk(ctx context.Context, c *shipping.Cargo, slip time.Duration) {
+	h.CargoDeadlineAtRiskInvoked = true
+	h.CargoDeadlineAtRiskFn(ctx, c, slip)
+}
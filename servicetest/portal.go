@@ -0,0 +1,110 @@
+package servicetest
+
+import (
+	"context"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/portal"
+)
+
+// PortalService is a mock customer portal service.
+type PortalService struct {
+	RegisterCustomerFn      func(context.Context, shipping.CustomerID, string, string) error
+	RegisterCustomerInvoked bool
+
+	AuthenticateFn      func(context.Context, shipping.CustomerID, string) error
+	AuthenticateInvoked bool
+
+	CargosFn      func(context.Context, shipping.CustomerID) []portal.Cargo
+	CargosInvoked bool
+
+	RecentEventsFn      func(context.Context, shipping.CustomerID, int) []portal.Event
+	RecentEventsInvoked bool
+
+	PreferencesFn      func(context.Context, shipping.CustomerID) (portal.Preferences, error)
+	PreferencesInvoked bool
+
+	SetPreferencesFn      func(context.Context, shipping.CustomerID, portal.Preferences) error
+	SetPreferencesInvoked bool
+
+	FrequencyFn      func(context.Context, shipping.CustomerID) (portal.Frequency, error)
+	FrequencyInvoked bool
+
+	SetFrequencyFn      func(context.Context, shipping.CustomerID, portal.Frequency) error
+	SetFrequencyInvoked bool
+
+	ContactFn      func(context.Context, shipping.CustomerID) (portal.Contact, error)
+	ContactInvoked bool
+
+	SetContactFn      func(context.Context, shipping.CustomerID, portal.Contact) error
+	SetContactInvoked bool
+
+	UnsubscribeFn      func(context.Context, shipping.CustomerID, shipping.NotificationType, string) error
+	UnsubscribeInvoked bool
+}
+
+// RegisterCustomer calls the RegisterCustomerFn.
+func (s *PortalService) RegisterCustomer(ctx context.Context, id shipping.CustomerID, name string, key string) error {
+	s.RegisterCustomerInvoked = true
+	return s.RegisterCustomerFn(ctx, id, name, key)
+}
+
+// Authenticate calls the AuthenticateFn.
+func (s *PortalService) Authenticate(ctx context.Context, id shipping.CustomerID, key string) error {
+	s.AuthenticateInvoked = true
+	return s.AuthenticateFn(ctx, id, key)
+}
+
+// Cargos calls the CargosFn.
+func (s *PortalService) Cargos(ctx context.Context, customer shipping.CustomerID) []portal.Cargo {
+	s.CargosInvoked = true
+	return s.CargosFn(ctx, customer)
+}
+
+// RecentEvents calls the RecentEventsFn.
+func (s *PortalService) RecentEvents(ctx context.Context, customer shipping.CustomerID, limit int) []portal.Event {
+	s.RecentEventsInvoked = true
+	return s.RecentEventsFn(ctx, customer, limit)
+}
+
+// Preferences calls the PreferencesFn.
+func (s *PortalService) Preferences(ctx context.Context, customer shipping.CustomerID) (portal.Preferences, error) {
+	s.PreferencesInvoked = true
+	return s.PreferencesFn(ctx, customer)
+}
+
+// SetPreferences calls the SetPreferencesFn.
+func (s *PortalService) SetPreferences(ctx context.Context, customer shipping.CustomerID, p portal.Preferences) error {
+	s.SetPreferencesInvoked = true
+	return s.SetPreferencesFn(ctx, customer, p)
+}
+
+// Frequency calls the FrequencyFn.
+func (s *PortalService) Frequency(ctx context.Context, customer shipping.CustomerID) (portal.Frequency, error) {
+	s.FrequencyInvoked = true
+	return s.FrequencyFn(ctx, customer)
+}
+
+// SetFrequency calls the SetFrequencyFn.
+func (s *PortalService) SetFrequency(ctx context.Context, customer shipping.CustomerID, f portal.Frequency) error {
+	s.SetFrequencyInvoked = true
+	return s.SetFrequencyFn(ctx, customer, f)
+}
+
+// Contact calls the ContactFn.
+func (s *PortalService) Contact(ctx context.Context, customer shipping.CustomerID) (portal.Contact, error) {
+	s.ContactInvoked = true
+	return s.ContactFn(ctx, customer)
+}
+
+// SetContact calls the SetContactFn.
+func (s *PortalService) SetContact(ctx context.Context, customer shipping.CustomerID, c portal.Contact) error {
+	s.SetContactInvoked = true
+	return s.SetContactFn(ctx, customer, c)
+}
+
+// Unsubscribe calls the UnsubscribeFn.
+func (s *PortalService) Unsubscribe(ctx context.Context, customer shipping.CustomerID, t shipping.NotificationType, token string) error {
+	s.UnsubscribeInvoked = true
+	return s.UnsubscribeFn(ctx, customer, t, token)
+}
@@ -0,0 +1,75 @@
+package servicetest
+
+import (
+	"context"
+	"io"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/portstatus"
+)
+
+// PortStatusService is a mock port status service.
+type PortStatusService struct {
+	AdviseFn      func(context.Context, shipping.PortStatus) error
+	AdviseInvoked bool
+
+	ImportFn      func(context.Context, io.Reader) (int, error)
+	ImportInvoked bool
+
+	StatusesFn      func(context.Context) []portstatus.Status
+	StatusesInvoked bool
+
+	SetCalendarFn      func(context.Context, shipping.WorkingCalendar) error
+	SetCalendarInvoked bool
+
+	CalendarsFn      func(context.Context) []portstatus.Calendar
+	CalendarsInvoked bool
+
+	RecordVarianceFn      func(context.Context, shipping.HandlingEvent) error
+	RecordVarianceInvoked bool
+
+	PerformanceFn      func(context.Context) []portstatus.Performance
+	PerformanceInvoked bool
+}
+
+// Advise calls the AdviseFn.
+func (s *PortStatusService) Advise(ctx context.Context, status shipping.PortStatus) error {
+	s.AdviseInvoked = true
+	return s.AdviseFn(ctx, status)
+}
+
+// Import calls the ImportFn.
+func (s *PortStatusService) Import(ctx context.Context, r io.Reader) (int, error) {
+	s.ImportInvoked = true
+	return s.ImportFn(ctx, r)
+}
+
+// Statuses calls the StatusesFn.
+func (s *PortStatusService) Statuses(ctx context.Context) []portstatus.Status {
+	s.StatusesInvoked = true
+	return s.StatusesFn(ctx)
+}
+
+// SetCalendar calls the SetCalendarFn.
+func (s *PortStatusService) SetCalendar(ctx context.Context, c shipping.WorkingCalendar) error {
+	s.SetCalendarInvoked = true
+	return s.SetCalendarFn(ctx, c)
+}
+
+// Calendars calls the CalendarsFn.
+func (s *PortStatusService) Calendars(ctx context.Context) []portstatus.Calendar {
+	s.CalendarsInvoked = true
+	return s.CalendarsFn(ctx)
+}
+
+// RecordVariance calls the RecordVarianceFn.
+func (s *PortStatusService) RecordVariance(ctx context.Context, e shipping.HandlingEvent) error {
+	s.RecordVarianceInvoked = true
+	return s.RecordVarianceFn(ctx, e)
+}
+
+// Performance calls the PerformanceFn.
+func (s *PortStatusService) Performance(ctx context.Context) []portstatus.Performance {
+	s.PerformanceInvoked = true
+	return s.PerformanceFn(ctx)
+}
@@ -0,0 +1,29 @@
+package servicetest
+
+import (
+	"context"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// PositionService is a mock position service.
+type PositionService struct {
+	ReportPositionFn      func(context.Context, shipping.VoyageNumber, shipping.Coordinates, time.Time) (int, error)
+	ReportPositionInvoked bool
+
+	RecordPortCallFn      func(context.Context, shipping.VoyageNumber, shipping.UNLocode, time.Time, time.Time) (int, error)
+	RecordPortCallInvoked bool
+}
+
+// ReportPosition calls the ReportPositionFn.
+func (s *PositionService) ReportPosition(ctx context.Context, voyage shipping.VoyageNumber, c shipping.Coordinates, at time.Time) (int, error) {
+	s.ReportPositionInvoked = true
+	return s.ReportPositionFn(ctx, voyage, c, at)
+}
+
+// RecordPortCall calls the RecordPortCallFn.
+func (s *PositionService) RecordPortCall(ctx context.Context, voyage shipping.VoyageNumber, locode shipping.UNLocode, arrived, departed time.Time) (int, error) {
+	s.RecordPortCallInvoked = true
+	return s.RecordPortCallFn(ctx, voyage, locode, arrived, departed)
+}
@@ -0,0 +1,29 @@
+package servicetest
+
+import (
+	"context"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/reconciliation"
+)
+
+// ReconciliationService is a mock reconciliation service.
+type ReconciliationService struct {
+	ReconcileFn      func(context.Context, shipping.VoyageNumber) (reconciliation.Reconciliation, error)
+	ReconcileInvoked bool
+
+	CompleteVoyageFn      func(context.Context, shipping.VoyageNumber) (reconciliation.Reconciliation, error)
+	CompleteVoyageInvoked bool
+}
+
+// Reconcile calls the ReconcileFn.
+func (s *ReconciliationService) Reconcile(ctx context.Context, voyage shipping.VoyageNumber) (reconciliation.Reconciliation, error) {
+	s.ReconcileInvoked = true
+	return s.ReconcileFn(ctx, voyage)
+}
+
+// CompleteVoyage calls the CompleteVoyageFn.
+func (s *ReconciliationService) CompleteVoyage(ctx context.Context, voyage shipping.VoyageNumber) (reconciliation.Reconciliation, error) {
+	s.CompleteVoyageInvoked = true
+	return s.CompleteVoyageFn(ctx, voyage)
+}
@@ -0,0 +1,73 @@
+package servicetest
+
+import (
+	"context"
+
+	"github.com/marcusolsson/goddd/replication"
+)
+
+// ReplicationService is a mock replication service.
+type ReplicationService struct {
+	StatusFn      func(context.Context) (replication.Status, error)
+	StatusInvoked bool
+
+	ExportFn      func(context.Context, string, string, int) (replication.Batch, error)
+	ExportInvoked bool
+
+	ApplyFn      func(context.Context, string, replication.Batch) (replication.Status, error)
+	ApplyInvoked bool
+
+	DigestFn      func(context.Context, string) (replication.Digest, error)
+	DigestInvoked bool
+
+	CompareFn      func(context.Context, string, replication.Digest) (replication.Divergence, error)
+	CompareInvoked bool
+
+	DemoteFn      func(context.Context, string) (replication.Status, error)
+	DemoteInvoked bool
+
+	PromoteFn      func(context.Context, string, string) (replication.Status, error)
+	PromoteInvoked bool
+}
+
+// Status calls the StatusFn.
+func (s *ReplicationService) Status(ctx context.Context) (replication.Status, error) {
+	s.StatusInvoked = true
+	return s.StatusFn(ctx)
+}
+
+// Export calls the ExportFn.
+func (s *ReplicationService) Export(ctx context.Context, token, since string, limit int) (replication.Batch, error) {
+	s.ExportInvoked = true
+	return s.ExportFn(ctx, token, since, limit)
+}
+
+// Apply calls the ApplyFn.
+func (s *ReplicationService) Apply(ctx context.Context, token string, b replication.Batch) (replication.Status, error) {
+	s.ApplyInvoked = true
+	return s.ApplyFn(ctx, token, b)
+}
+
+// Digest calls the DigestFn.
+func (s *ReplicationService) Digest(ctx context.Context, token string) (replication.Digest, error) {
+	s.DigestInvoked = true
+	return s.DigestFn(ctx, token)
+}
+
+// Compare calls the CompareFn.
+func (s *ReplicationService) Compare(ctx context.Context, token string, d replication.Digest) (replication.Divergence, error) {
+	s.CompareInvoked = true
+	return s.CompareFn(ctx, token, d)
+}
+
+// Demote calls the DemoteFn.
+func (s *ReplicationService) Demote(ctx context.Context, token string) (replication.Status, error) {
+	s.DemoteInvoked = true
+	return s.DemoteFn(ctx, token)
+}
+
+// Promote calls the PromoteFn.
+func (s *ReplicationService) Promote(ctx context.Context, token, until string) (replication.Status, error) {
+	s.PromoteInvoked = true
+	return s.PromoteFn(ctx, token, until)
+}
@@ -0,0 +1,47 @@
+package servicetest
+
+import (
+	"context"
+	"time"
+
+	"github.com/marcusolsson/goddd/scheduling"
+)
+
+// SchedulingService is a mock scheduling service.
+type SchedulingService struct {
+	DeparturesFn      func(context.Context, string, time.Time, time.Time) ([]scheduling.Movement, error)
+	DeparturesInvoked bool
+
+	ArrivalsFn      func(context.Context, string, time.Time, time.Time) ([]scheduling.Movement, error)
+	ArrivalsInvoked bool
+
+	RotationFn      func(context.Context, string) (scheduling.Voyage, error)
+	RotationInvoked bool
+
+	ServiceStringsFn      func(context.Context, string) []scheduling.ServiceString
+	ServiceStringsInvoked bool
+}
+
+// Departures calls the DeparturesFn.
+func (s *SchedulingService) Departures(ctx context.Context, locode string, from time.Time, to time.Time) ([]scheduling.Movement, error) {
+	s.DeparturesInvoked = true
+	return s.DeparturesFn(ctx, locode, from, to)
+}
+
+// Arrivals calls the ArrivalsFn.
+func (s *SchedulingService) Arrivals(ctx context.Context, locode string, from time.Time, to time.Time) ([]scheduling.Movement, error) {
+	s.ArrivalsInvoked = true
+	return s.ArrivalsFn(ctx, locode, from, to)
+}
+
+// Rotation calls the RotationFn.
+func (s *SchedulingService) Rotation(ctx context.Context, voyageNumber string) (scheduling.Voyage, error) {
+	s.RotationInvoked = true
+	return s.RotationFn(ctx, voyageNumber)
+}
+
+// ServiceStrings calls the ServiceStringsFn.
+func (s *SchedulingService) ServiceStrings(ctx context.Context, lane string) []scheduling.ServiceString {
+	s.ServiceStringsInvoked = true
+	return s.ServiceStringsFn(ctx, lane)
+}
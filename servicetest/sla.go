@@ -0,0 +1,68 @@
+package servicetest
+
+import (
+	"context"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/sla"
+)
+
+// SLAService is a mock SLA service.
+type SLAService struct {
+	DefineSLAFn      func(context.Context, shipping.SLA) error
+	DefineSLAInvoked bool
+
+	SLAsFn      func(context.Context) []sla.SLA
+	SLAsInvoked bool
+
+	AssignServiceLevelFn      func(context.Context, shipping.TrackingID, shipping.ServiceLevel) error
+	AssignServiceLevelInvoked bool
+
+	EvaluateCargoFn      func(context.Context, shipping.TrackingID) error
+	EvaluateCargoInvoked bool
+
+	ReportFn      func(context.Context) sla.Report
+	ReportInvoked bool
+}
+
+// DefineSLA calls the DefineSLAFn.
+func (s *SLAService) DefineSLA(ctx context.Context, sla shipping.SLA) error {
+	s.DefineSLAInvoked = true
+	return s.DefineSLAFn(ctx, sla)
+}
+
+// SLAs calls the SLAsFn.
+func (s *SLAService) SLAs(ctx context.Context) []sla.SLA {
+	s.SLAsInvoked = true
+	return s.SLAsFn(ctx)
+}
+
+// AssignServiceLevel calls the AssignServiceLevelFn.
+func (s *SLAService) AssignServiceLevel(ctx context.Context, id shipping.TrackingID, level shipping.ServiceLevel) error {
+	s.AssignServiceLevelInvoked = true
+	return s.AssignServiceLevelFn(ctx, id, level)
+}
+
+// EvaluateCargo calls the EvaluateCargoFn.
+func (s *SLAService) EvaluateCargo(ctx context.Context, id shipping.TrackingID) error {
+	s.EvaluateCargoInvoked = true
+	return s.EvaluateCargoFn(ctx, id)
+}
+
+// Report calls the ReportFn.
+func (s *SLAService) Report(ctx context.Context) sla.Report {
+	s.ReportInvoked = true
+	return s.ReportFn(ctx)
+}
+
+// SLAEventHandler is a mock SLA event handler.
+type SLAEventHandler struct {
+	CargoBreachedSLAFn      func(context.Context, *shipping.Cargo, []shipping.SLABreach)
+	CargoBreachedSLAInvoked bool
+}
+
+// CargoBreachedSLA calls the CargoBreachedSLAFn.
+func (h *SLAEventHandler) CargoBreachedSLA(ctx context.Context, c *shipping.Cargo, breaches []shipping.SLABreach) {
+	h.CargoBreachedSLAInvoked = true
+	h.CargoBreachedSLAFn(ctx, c, breaches)
+}
@@ -0,0 +1,65 @@
+package servicetest
+
+import (
+	"context"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/task"
+)
+
+// TaskService is a mock task service.
+type TaskService struct {
+	OpenTaskFn      func(context.Context, shipping.TaskKind, shipping.TrackingID, string) (shipping.TaskID, error)
+	OpenTaskInvoked bool
+
+	OpenReferenceTaskFn      func(context.Context, shipping.TaskKind, string, string) (shipping.TaskID, error)
+	OpenReferenceTaskInvoked bool
+
+	AssignTaskFn      func(context.Context, shipping.TaskID, shipping.UserID) error
+	AssignTaskInvoked bool
+
+	UpdateStatusFn      func(context.Context, shipping.TaskID, shipping.TaskStatus) error
+	UpdateStatusInvoked bool
+
+	LoadTaskFn      func(context.Context, shipping.TaskID) (task.Task, error)
+	LoadTaskInvoked bool
+
+	TasksFn      func(context.Context, shipping.TaskStatus, shipping.UserID) []task.Task
+	TasksInvoked bool
+}
+
+// OpenTask calls the OpenTaskFn.
+func (s *TaskService) OpenTask(ctx context.Context, kind shipping.TaskKind, id shipping.TrackingID, description string) (shipping.TaskID, error) {
+	s.OpenTaskInvoked = true
+	return s.OpenTaskFn(ctx, kind, id, description)
+}
+
+// OpenReferenceTask calls the OpenReferenceTaskFn.
+func (s *TaskService) OpenReferenceTask(ctx context.Context, kind shipping.TaskKind, ref, description string) (shipping.TaskID, error) {
+	s.OpenReferenceTaskInvoked = true
+	return s.OpenReferenceTaskFn(ctx, kind, ref, description)
+}
+
+// AssignTask calls the AssignTaskFn.
+func (s *TaskService) AssignTask(ctx context.Context, id shipping.TaskID, to shipping.UserID) error {
+	s.AssignTaskInvoked = true
+	return s.AssignTaskFn(ctx, id, to)
+}
+
+// UpdateStatus calls the UpdateStatusFn.
+func (s *TaskService) UpdateStatus(ctx context.Context, id shipping.TaskID, status shipping.TaskStatus) error {
+	s.UpdateStatusInvoked = true
+	return s.UpdateStatusFn(ctx, id, status)
+}
+
+// LoadTask calls the LoadTaskFn.
+func (s *TaskService) LoadTask(ctx context.Context, id shipping.TaskID) (task.Task, error) {
+	s.LoadTaskInvoked = true
+	return s.LoadTaskFn(ctx, id)
+}
+
+// Tasks calls the TasksFn.
+func (s *TaskService) Tasks(ctx context.Context, status shipping.TaskStatus, assignee shipping.UserID) []task.Task {
+	s.TasksInvoked = true
+	return s.TasksFn(ctx, status, assignee)
+}
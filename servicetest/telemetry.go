@@ -0,0 +1,51 @@
+package servicetest
+
+import (
+	"context"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/telemetry"
+)
+
+// TelemetryService is a mock telemetry service.
+type TelemetryService struct {
+	RecordFn      func(context.Context, []shipping.SensorReading) (int, error)
+	RecordInvoked bool
+
+	ReadingsFn      func(context.Context, shipping.TrackingID, shipping.SensorType, time.Time, time.Time) ([]telemetry.Reading, error)
+	ReadingsInvoked bool
+
+	SetLimitsFn      func(context.Context, shipping.TrackingID, shipping.SensorLimits) error
+	SetLimitsInvoked bool
+}
+
+// Record calls the RecordFn.
+func (s *TelemetryService) Record(ctx context.Context, readings []shipping.SensorReading) (int, error) {
+	s.RecordInvoked = true
+	return s.RecordFn(ctx, readings)
+}
+
+// Readings calls the ReadingsFn.
+func (s *TelemetryService) Readings(ctx context.Context, id shipping.TrackingID, sensor shipping.SensorType, from, to time.Time) ([]telemetry.Reading, error) {
+	s.ReadingsInvoked = true
+	return s.ReadingsFn(ctx, id, sensor, from, to)
+}
+
+// SetLimits calls the SetLimitsFn.
+func (s *TelemetryService) SetLimits(ctx context.Context, id shipping.TrackingID, limits shipping.SensorLimits) error {
+	s.SetLimitsInvoked = true
+	return s.SetLimitsFn(ctx, id, limits)
+}
+
+// TelemetryEventHandler is a mock telemetry event handler.
+type TelemetryEventHandler struct {
+	CargoSensorOutOfRangeFn      func(context.Context, *shipping.Cargo, shipping.SensorReading, shipping.SensorRange)
+	CargoSensorOutOfRangeInvoked bool
+}
+
+// CargoSensorOutOfRange calls the CargoSensorOutOfRangeFn.
+func (h *TelemetryEventHandler) CargoSensorOutOfRange(ctx context.Context, c *shipping.Cargo, r shipping.SensorReading, limit shipping.SensorRange) {
+	h.CargoSensorOutOfRangeInvoked = true
+	h.CargoSensorOutOfRangeFn(ctx, c, r, limit)
+}
@@ -0,0 +1,29 @@
+package servicetest
+
+import (
+	"context"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/tracking"
+)
+
+// TrackingService is a mock tracking service.
+type TrackingService struct {
+	TrackFn      func(context.Context, string) (tracking.Cargo, error)
+	TrackInvoked bool
+
+	EventsFn      func(context.Context, string, shipping.HandlingHistoryQuery) (tracking.EventPage, error)
+	EventsInvoked bool
+}
+
+// Track calls the TrackFn.
+func (s *TrackingService) Track(ctx context.Context, id string) (tracking.Cargo, error) {
+	s.TrackInvoked = true
+	return s.TrackFn(ctx, id)
+}
+
+// Events calls the EventsFn.
+func (s *TrackingService) Events(ctx context.Context, id string, q shipping.HandlingHistoryQuery) (tracking.EventPage, error) {
+	s.EventsInvoked = true
+	return s.EventsFn(ctx, id, q)
+}
@@ -0,0 +1,70 @@
+package sla
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) DefineSLA(ctx context.Context, sla shipping.SLA) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "define_sla").Add(1)
+		s.requestLatency.With("method", "define_sla").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.DefineSLA(ctx, sla)
+}
+
+func (s *instrumentingService) SLAs(ctx context.Context) []SLA {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "list_slas").Add(1)
+		s.requestLatency.With("method", "list_slas").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.SLAs(ctx)
+}
+
+func (s *instrumentingService) AssignServiceLevel(ctx context.Context, id shipping.TrackingID, level shipping.ServiceLevel) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "assign_service_level").Add(1)
+		s.requestLatency.With("method", "assign_service_level").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.AssignServiceLevel(ctx, id, level)
+}
+
+func (s *instrumentingService) EvaluateCargo(ctx context.Context, id shipping.TrackingID) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "evaluate").Add(1)
+		s.requestLatency.With("method", "evaluate").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.EvaluateCargo(ctx, id)
+}
+
+func (s *instrumentingService) Report(ctx context.Context) Report {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "report").Add(1)
+		s.requestLatency.With("method", "report").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Report(ctx)
+}
@@ -0,0 +1,112 @@
+package sla
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) DefineSLA(ctx context.Context, sla shipping.SLA) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "define_sla",
+			"request_id", correlation.FromContext(ctx),
+			"level", sla.Level,
+			"max_transit", sla.MaxTransit,
+			"max_dwell", sla.MaxDwell,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.DefineSLA(ctx, sla)
+}
+
+func (s *loggingService) SLAs(ctx context.Context) []SLA {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_slas",
+			"request_id", correlation.FromContext(ctx),
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.SLAs(ctx)
+}
+
+func (s *loggingService) AssignServiceLevel(ctx context.Context, id shipping.TrackingID, level shipping.ServiceLevel) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "assign_service_level",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"level", level,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.AssignServiceLevel(ctx, id, level)
+}
+
+func (s *loggingService) EvaluateCargo(ctx context.Context, id shipping.TrackingID) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "evaluate",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.EvaluateCargo(ctx, id)
+}
+
+func (s *loggingService) Report(ctx context.Context) (r Report) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "report",
+			"request_id", correlation.FromContext(ctx),
+			"levels", len(r.Levels),
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.Report(ctx)
+}
+
+type loggingEventHandler struct {
+	logger log.Logger
+}
+
+// NewLoggingEventHandler returns an EventHandler that notifies account
+// managers of SLA breaches through the log.
+func NewLoggingEventHandler(logger log.Logger) EventHandler {
+	return &loggingEventHandler{logger}
+}
+
+func (h *loggingEventHandler) CargoBreachedSLA(ctx context.Context, c *shipping.Cargo, breaches []shipping.SLABreach) {
+	for _, b := range breaches {
+		h.logger.Log(
+			"msg", "cargo breached SLA",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", c.TrackingID,
+			"customer", c.Customer,
+			"level", c.ServiceLevel,
+			"target", b.Target,
+			"location", b.Location,
+			"actual", b.Actual,
+			"limit", b.Limit,
+		)
+	}
+}
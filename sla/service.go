@@ -0,0 +1,280 @@
+// Package sla provides the use-case of tracking cargos against the service
+// levels agreed with customers. Used by views facing account managers.
+package sla
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/handling"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// EventHandler provides a means of subscribing to SLA breaches, e.g. to
+// notify the account manager of the customer.
+type EventHandler interface {
+	CargoBreachedSLA(context.Context, *shipping.Cargo, []shipping.SLABreach)
+}
+
+// EventHandlers notifies several event handlers in order.
+type EventHandlers []EventHandler
+
+// CargoBreachedSLA notifies every handler.
+func (hs EventHandlers) CargoBreachedSLA(ctx context.Context, c *shipping.Cargo, breaches []shipping.SLABreach) {
+	for _, h := range hs {
+		h.CargoBreachedSLA(ctx, c, breaches)
+	}
+}
+
+// Service is the interface that provides SLA methods.
+type Service interface {
+	// DefineSLA registers the targets of a service level, replacing any
+	// previous ones.
+	DefineSLA(ctx context.Context, s shipping.SLA) error
+
+	// SLAs returns the targets of all service levels.
+	SLAs(ctx context.Context) []SLA
+
+	// AssignServiceLevel sets the level of service agreed for a cargo and
+	// evaluates the cargo against it.
+	AssignServiceLevel(ctx context.Context, id shipping.TrackingID, level shipping.ServiceLevel) error
+
+	// EvaluateCargo evaluates a cargo against the targets of its service
+	// level and records the targets breached. Interested parties are
+	// notified of new breaches.
+	EvaluateCargo(ctx context.Context, id shipping.TrackingID) error
+
+	// Report summarizes how cargos perform against each service level.
+	Report(ctx context.Context) Report
+}
+
+type service struct {
+	cargos  shipping.CargoRepository
+	events  shipping.HandlingEventRepository
+	slas    shipping.SLARepository
+	handler EventHandler
+
+	calendars shipping.WorkingCalendarRepository
+}
+
+func (s *service) DefineSLA(ctx context.Context, sla shipping.SLA) error {
+	if sla.Level == "" || sla.MaxTransit < 0 || sla.MaxDwell < 0 {
+		return ErrInvalidArgument
+	}
+
+	return s.slas.Store(&sla)
+}
+
+func (s *service) SLAs(ctx context.Context) []SLA {
+	var result []SLA
+	for _, sla := range s.slas.FindAll() {
+		result = append(result, assemble(sla))
+	}
+	return result
+}
+
+func (s *service) AssignServiceLevel(ctx context.Context, id shipping.TrackingID, level shipping.ServiceLevel) error {
+	if id == "" || level == "" {
+		return ErrInvalidArgument
+	}
+
+	if _, err := s.slas.Find(level); err != nil {
+		return err
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return err
+	}
+
+	c.ServiceLevel = level
+	c.SLABreaches = nil
+
+	return s.evaluate(ctx, c)
+}
+
+func (s *service) EvaluateCargo(ctx context.Context, id shipping.TrackingID) error {
+	if id == "" {
+		return ErrInvalidArgument
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return err
+	}
+
+	if c.ServiceLevel == "" {
+		return nil
+	}
+
+	return s.evaluate(ctx, c)
+}
+
+func (s *service) evaluate(ctx context.Context, c *shipping.Cargo) error {
+	sla, err := s.slas.Find(c.ServiceLevel)
+	if err != nil {
+		return err
+	}
+
+	var calendars shipping.WorkingCalendars
+	if s.calendars != nil {
+		calendars = shipping.NewWorkingCalendars(s.calendars.FindAll())
+	}
+
+	breaches := sla.Evaluate(c.RouteSpecification, s.events.QueryHandlingHistory(c.TrackingID), time.Now(), calendars)
+
+	fresh := newBreaches(c.SLABreaches, breaches)
+
+	c.SLABreaches = breaches
+
+	if err := s.cargos.Store(c); err != nil {
+		return err
+	}
+
+	if len(fresh) > 0 && s.handler != nil {
+		s.handler.CargoBreachedSLA(ctx, c, fresh)
+	}
+
+	return nil
+}
+
+// newBreaches returns the breaches of targets not breached before.
+func newBreaches(before, after []shipping.SLABreach) []shipping.SLABreach {
+	type key struct {
+		target   shipping.SLATarget
+		location shipping.UNLocode
+	}
+
+	known := make(map[key]bool)
+	for _, b := range before {
+		known[key{b.Target, b.Location}] = true
+	}
+
+	var result []shipping.SLABreach
+	for _, b := range after {
+		if !known[key{b.Target, b.Location}] {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+func (s *service) Report(ctx context.Context) Report {
+	levels := make(map[shipping.ServiceLevel]*LevelReport)
+
+	var r Report
+	for _, sla := range s.slas.FindAll() {
+		r.Levels = append(r.Levels, LevelReport{Level: string(sla.Level)})
+	}
+	for i := range r.Levels {
+		levels[shipping.ServiceLevel(r.Levels[i].Level)] = &r.Levels[i]
+	}
+
+	for _, c := range s.cargos.FindAll() {
+		lr, ok := levels[c.ServiceLevel]
+		if !ok {
+			continue
+		}
+
+		lr.Cargos++
+		if len(c.SLABreaches) > 0 {
+			lr.Breached = append(lr.Breached, string(c.TrackingID))
+		}
+		if c.Rollovers > 0 {
+			lr.Rolled = append(lr.Rolled, string(c.TrackingID))
+			lr.Rollovers += c.Rollovers
+		}
+	}
+
+	for i := range r.Levels {
+		if lr := &r.Levels[i]; lr.Cargos > 0 {
+			lr.Compliance = float64(lr.Cargos-len(lr.Breached)) / float64(lr.Cargos)
+		}
+	}
+
+	return r
+}
+
+// Option configures an SLA service.
+type Option func(*service)
+
+// WithCalendars counts the dwell of cargos at transshipment ports in the
+// working hours of the ports only.
+func WithCalendars(r shipping.WorkingCalendarRepository) Option {
+	return func(s *service) {
+		s.calendars = r
+	}
+}
+
+// NewService creates an SLA service with necessary dependencies. The handler
+// may be nil.
+func NewService(cargos shipping.CargoRepository, events shipping.HandlingEventRepository, slas shipping.SLARepository, handler EventHandler, opts ...Option) Service {
+	s := &service{
+		cargos:  cargos,
+		events:  events,
+		slas:    slas,
+		handler: handler,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// SLA is a read model for service level views.
+type SLA struct {
+	Level      string `json:"level"`
+	MaxTransit string `json:"max_transit,omitempty"`
+	MaxDwell   string `json:"max_dwell,omitempty"`
+}
+
+func assemble(s *shipping.SLA) SLA {
+	r := SLA{Level: string(s.Level)}
+	if s.MaxTransit > 0 {
+		r.MaxTransit = s.MaxTransit.String()
+	}
+	if s.MaxDwell > 0 {
+		r.MaxDwell = s.MaxDwell.String()
+	}
+	return r
+}
+
+// Report summarizes how cargos perform against their service levels.
+type Report struct {
+	Levels []LevelReport `json:"levels"`
+}
+
+// LevelReport summarizes how cargos perform against a service level.
+type LevelReport struct {
+	Level  string `json:"level"`
+	Cargos int    `json:"cargos"`
+
+	// Breached lists the cargos that have breached a target.
+	Breached []string `json:"breached,omitempty"`
+
+	// Rolled lists the cargos rolled to a later sailing at least once, and
+	// Rollovers counts the times the cargos were rolled.
+	Rolled    []string `json:"rolled,omitempty"`
+	Rollovers int      `json:"rollovers"`
+
+	// Compliance is the share of cargos meeting every target.
+	Compliance float64 `json:"compliance"`
+}
+
+type handlingEventHandler struct {
+	s Service
+}
+
+func (h *handlingEventHandler) CargoWasHandled(ctx context.Context, e shipping.HandlingEvent) {
+	h.s.EvaluateCargo(ctx, e.TrackingID)
+}
+
+// NewHandlingEventHandler returns a handler that evaluates cargos against
+// their service levels as they are handled.
+func NewHandlingEventHandler(s Service) handling.EventHandler {
+	return &handlingEventHandler{s: s}
+}
@@ -0,0 +1,136 @@
+package sla
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+type stubEventHandler struct {
+	breaches []shipping.SLABreach
+}
+
+func (h *stubEventHandler) CargoBreachedSLA(ctx context.Context, c *shipping.Cargo, bs []shipping.SLABreach) {
+	h.breaches = append(h.breaches, bs...)
+}
+
+func TestEvaluateCargo(t *testing.T) {
+	var (
+		id       = shipping.TrackingID("ABC123")
+		received = time.Now().Add(-10 * 24 * time.Hour)
+		express  = &shipping.SLA{Level: "express", MaxTransit: 7 * 24 * time.Hour}
+	)
+
+	cargo := shipping.NewCargo(id, shipping.RouteSpecification{
+		Origin:      shipping.CNHKG,
+		Destination: shipping.SESTO,
+	})
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(shipping.TrackingID) (*shipping.Cargo, error) {
+		return cargo, nil
+	}
+	cargos.StoreFn = func(c *shipping.Cargo) error {
+		cargo = c
+		return nil
+	}
+	cargos.FindAllFn = func() []*shipping.Cargo {
+		return []*shipping.Cargo{cargo}
+	}
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryFn = func(shipping.TrackingID) shipping.HandlingHistory {
+		return shipping.HandlingHistory{HandlingEvents: []shipping.HandlingEvent{
+			{TrackingID: id, Activity: shipping.HandlingActivity{Type: shipping.Receive, Location: shipping.CNHKG}, Completed: received},
+		}}
+	}
+
+	var slas mock.SLARepository
+	slas.FindFn = func(l shipping.ServiceLevel) (*shipping.SLA, error) {
+		if l != express.Level {
+			return nil, shipping.ErrUnknownServiceLevel
+		}
+		return express, nil
+	}
+	slas.FindAllFn = func() []*shipping.SLA {
+		return []*shipping.SLA{express}
+	}
+
+	h := &stubEventHandler{}
+
+	s := NewService(&cargos, &events, &slas, h)
+
+	// Cargos without a service level are not evaluated.
+	if err := s.EvaluateCargo(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	if len(cargo.SLABreaches) != 0 {
+		t.Errorf("len(cargo.SLABreaches) = %d; want = %d", len(cargo.SLABreaches), 0)
+	}
+
+	if err := s.AssignServiceLevel(context.Background(), id, "economy"); err != shipping.ErrUnknownServiceLevel {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownServiceLevel)
+	}
+
+	if err := s.AssignServiceLevel(context.Background(), id, express.Level); err != nil {
+		t.Fatal(err)
+	}
+	if len(cargo.SLABreaches) != 1 {
+		t.Fatalf("len(cargo.SLABreaches) = %d; want = %d", len(cargo.SLABreaches), 1)
+	}
+	if len(h.breaches) != 1 {
+		t.Errorf("len(h.breaches) = %d; want = %d", len(h.breaches), 1)
+	}
+
+	// Breaches are only notified once.
+	if err := s.EvaluateCargo(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	if len(h.breaches) != 1 {
+		t.Errorf("len(h.breaches) = %d; want = %d", len(h.breaches), 1)
+	}
+
+	cargo.Rollovers = 2
+
+	r := s.Report(context.Background())
+	if len(r.Levels) != 1 {
+		t.Fatalf("len(r.Levels) = %d; want = %d", len(r.Levels), 1)
+	}
+	if got := r.Levels[0]; got.Cargos != 1 || len(got.Breached) != 1 || got.Compliance != 0 {
+		t.Errorf("r.Levels[0] = %+v", got)
+	}
+	if got := r.Levels[0]; len(got.Rolled) != 1 || got.Rollovers != 2 {
+		t.Errorf("r.Levels[0] = %+v; want the rollovers counted", got)
+	}
+}
+
+func TestDefineSLA(t *testing.T) {
+	var stored []*shipping.SLA
+
+	var slas mock.SLARepository
+	slas.StoreFn = func(s *shipping.SLA) error {
+		stored = append(stored, s)
+		return nil
+	}
+
+	s := NewService(nil, nil, &slas, nil)
+
+	if err := s.DefineSLA(context.Background(), shipping.SLA{MaxTransit: time.Hour}); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+
+	if err := s.DefineSLA(context.Background(), shipping.SLA{Level: "express", MaxDwell: -time.Hour}); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+
+	if err := s.DefineSLA(context.Background(), shipping.SLA{Level: "express", MaxTransit: 7 * 24 * time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stored) != 1 {
+		t.Errorf("len(stored) = %d; want = %d", len(stored), 1)
+	}
+}
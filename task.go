@@ -0,0 +1,165 @@
+package shipping
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pborman/uuid"
+)
+
+// TaskID uniquely identifies an operator task.
+type TaskID string
+
+// NextTaskID generates a new task id.
+func NextTaskID() TaskID {
+	return TaskID(strings.Split(strings.ToUpper(uuid.New()), "-")[0])
+}
+
+// TaskKind describes the finding that requires an operator to act.
+type TaskKind int
+
+// Valid task kinds.
+const (
+	// RerouteTask is opened when a cargo has been misdirected and needs to
+	// be assigned a new route.
+	RerouteTask TaskKind = iota
+
+	// SLABreachTask is opened when a cargo has breached its service level
+	// agreement and the customer needs to be followed up.
+	SLABreachTask
+
+	// IngestionTask is opened when a file delivered by a partner could not
+	// be imported.
+	IngestionTask
+
+	// InboundMailTask is opened when a mail from an agent was not recognized,
+	// or reported events that could not be registered.
+	InboundMailTask
+
+	// PortVarianceTask is opened when a port handles cargos later than
+	// planned by more than the threshold on average, and the terminal needs
+	// to be followed up.
+	PortVarianceTask
+
+	// VoyageDiscrepancyTask is opened when a cargo was handled on a
+	// completed voyage other than as booked, and needs to be located.
+	VoyageDiscrepancyTask
+)
+
+func (k TaskKind) String() string {
+	switch k {
+	case RerouteTask:
+		return "Reroute"
+	case SLABreachTask:
+		return "SLA breach"
+	case IngestionTask:
+		return "Ingestion failure"
+	case InboundMailTask:
+		return "Inbound mail"
+	case PortVarianceTask:
+		return "Port variance"
+	case VoyageDiscrepancyTask:
+		return "Voyage discrepancy"
+	}
+	return ""
+}
+
+// TaskStatus describes how far an operator has come with a task.
+type TaskStatus string
+
+// Valid task statuses.
+const (
+	TaskOpen       TaskStatus = "open"
+	TaskInProgress TaskStatus = "in-progress"
+	TaskDone       TaskStatus = "done"
+)
+
+// ParseTaskStatus returns the task status with the given name.
+func ParseTaskStatus(s string) (TaskStatus, error) {
+	switch st := TaskStatus(s); st {
+	case TaskOpen, TaskInProgress, TaskDone:
+		return st, nil
+	}
+	return "", ErrUnknownTaskStatus
+}
+
+// Task is a piece of operations work raised by the system, such as a cargo
+// that needs rerouting, to be picked up by a user of the back office.
+type Task struct {
+	ID         TaskID
+	Kind       TaskKind
+	TrackingID TrackingID
+
+	// Reference identifies what a task not about a cargo is about, such as
+	// the name of a file that failed to import.
+	Reference string
+
+	Description string
+	Status      TaskStatus
+	Assignee    UserID
+	Opened      time.Time
+	Updated     time.Time
+}
+
+// NewTask creates an open task about a cargo.
+func NewTask(kind TaskKind, id TrackingID, description string, at time.Time) *Task {
+	return &Task{
+		ID:          NextTaskID(),
+		Kind:        kind,
+		TrackingID:  id,
+		Description: description,
+		Status:      TaskOpen,
+		Opened:      at,
+		Updated:     at,
+	}
+}
+
+var (
+	// ErrUnknownTask is used when a task could not be found.
+	ErrUnknownTask = errors.New("unknown task")
+
+	// ErrUnknownTaskStatus is used when parsing an unknown task status.
+	ErrUnknownTaskStatus = errors.New("unknown task status")
+
+	// ErrTaskDone is used when changing a task that is already done.
+	ErrTaskDone = errors.New("task is done")
+)
+
+// IsDone returns whether the task has been completed.
+func (t Task) IsDone() bool {
+	return t.Status == TaskDone
+}
+
+// Assign hands the task over to a user.
+func (t *Task) Assign(to UserID, at time.Time) error {
+	if t.IsDone() {
+		return ErrTaskDone
+	}
+	t.Assignee = to
+	t.Updated = at
+	return nil
+}
+
+// SetStatus moves the task to another status. Tasks that are done may not be
+// changed; should the finding recur, a new task is opened.
+func (t *Task) SetStatus(s TaskStatus, at time.Time) error {
+	if t.IsDone() {
+		return ErrTaskDone
+	}
+	t.Status = s
+	t.Updated = at
+	return nil
+}
+
+// TaskRepository provides access a task store.
+type TaskRepository interface {
+	Store(t *Task) error
+	Find(id TaskID) (*Task, error)
+
+	// FindByCargo returns the tasks about a cargo, oldest first.
+	FindByCargo(id TrackingID) []*Task
+
+	// FindAll returns all tasks, oldest first.
+	FindAll() []*Task
+}
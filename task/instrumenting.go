@@ -0,0 +1,79 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) OpenTask(ctx context.Context, kind shipping.TaskKind, id shipping.TrackingID, description string) (shipping.TaskID, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "open_task").Add(1)
+		s.requestLatency.With("method", "open_task").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.OpenTask(ctx, kind, id, description)
+}
+
+func (s *instrumentingService) OpenReferenceTask(ctx context.Context, kind shipping.TaskKind, ref, description string) (shipping.TaskID, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "open_reference_task").Add(1)
+		s.requestLatency.With("method", "open_reference_task").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.OpenReferenceTask(ctx, kind, ref, description)
+}
+
+func (s *instrumentingService) AssignTask(ctx context.Context, id shipping.TaskID, to shipping.UserID) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "assign_task").Add(1)
+		s.requestLatency.With("method", "assign_task").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.AssignTask(ctx, id, to)
+}
+
+func (s *instrumentingService) UpdateStatus(ctx context.Context, id shipping.TaskID, status shipping.TaskStatus) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "update_status").Add(1)
+		s.requestLatency.With("method", "update_status").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.UpdateStatus(ctx, id, status)
+}
+
+func (s *instrumentingService) LoadTask(ctx context.Context, id shipping.TaskID) (Task, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "load_task").Add(1)
+		s.requestLatency.With("method", "load_task").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.LoadTask(ctx, id)
+}
+
+func (s *instrumentingService) Tasks(ctx context.Context, status shipping.TaskStatus, assignee shipping.UserID) []Task {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "tasks").Add(1)
+		s.requestLatency.With("method", "tasks").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Tasks(ctx, status, assignee)
+}
@@ -0,0 +1,105 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) OpenTask(ctx context.Context, kind shipping.TaskKind, id shipping.TrackingID, description string) (task shipping.TaskID, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "open_task",
+			"request_id", correlation.FromContext(ctx),
+			"kind", kind,
+			"tracking_id", id,
+			"task_id", task,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.OpenTask(ctx, kind, id, description)
+}
+
+func (s *loggingService) OpenReferenceTask(ctx context.Context, kind shipping.TaskKind, ref, description string) (task shipping.TaskID, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "open_reference_task",
+			"request_id", correlation.FromContext(ctx),
+			"kind", kind,
+			"reference", ref,
+			"task_id", task,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.OpenReferenceTask(ctx, kind, ref, description)
+}
+
+func (s *loggingService) AssignTask(ctx context.Context, id shipping.TaskID, to shipping.UserID) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "assign_task",
+			"request_id", correlation.FromContext(ctx),
+			"task_id", id,
+			"assignee", to,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.AssignTask(ctx, id, to)
+}
+
+func (s *loggingService) UpdateStatus(ctx context.Context, id shipping.TaskID, status shipping.TaskStatus) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "update_status",
+			"request_id", correlation.FromContext(ctx),
+			"task_id", id,
+			"status", status,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.UpdateStatus(ctx, id, status)
+}
+
+func (s *loggingService) LoadTask(ctx context.Context, id shipping.TaskID) (t Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "load_task",
+			"request_id", correlation.FromContext(ctx),
+			"task_id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.LoadTask(ctx, id)
+}
+
+func (s *loggingService) Tasks(ctx context.Context, status shipping.TaskStatus, assignee shipping.UserID) []Task {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "tasks",
+			"request_id", correlation.FromContext(ctx),
+			"status", status,
+			"assignee", assignee,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return s.next.Tasks(ctx, status, assignee)
+}
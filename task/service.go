@@ -0,0 +1,305 @@
+// Package task provides the use-case of working through operator tasks raised
+// by the system, such as cargos that need rerouting. Used by views facing the
+// back office.
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/inbound"
+	"github.com/marcusolsson/goddd/ingest"
+	"github.com/marcusolsson/goddd/inspection"
+	"github.com/marcusolsson/goddd/portstatus"
+	"github.com/marcusolsson/goddd/reconciliation"
+	"github.com/marcusolsson/goddd/sla"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// Service is the interface that provides task methods.
+type Service interface {
+	// OpenTask opens a task about a cargo. If a task of the same kind is
+	// already under way for the cargo, its id is returned instead.
+	OpenTask(ctx context.Context, kind shipping.TaskKind, id shipping.TrackingID, description string) (shipping.TaskID, error)
+
+	// OpenReferenceTask opens a task about something other than a cargo,
+	// identified by ref. If a task of the same kind is already under way for
+	// it, its id is returned instead.
+	OpenReferenceTask(ctx context.Context, kind shipping.TaskKind, ref, description string) (shipping.TaskID, error)
+
+	// AssignTask hands a task over to a registered user.
+	AssignTask(ctx context.Context, id shipping.TaskID, to shipping.UserID) error
+
+	// UpdateStatus moves a task to another status.
+	UpdateStatus(ctx context.Context, id shipping.TaskID, status shipping.TaskStatus) error
+
+	// LoadTask returns a read model of a task.
+	LoadTask(ctx context.Context, id shipping.TaskID) (Task, error)
+
+	// Tasks returns the tasks with the given status and assignee, oldest
+	// first. An empty status or assignee matches any.
+	Tasks(ctx context.Context, status shipping.TaskStatus, assignee shipping.UserID) []Task
+}
+
+type service struct {
+	tasks shipping.TaskRepository
+	users shipping.UserRepository
+}
+
+func (s *service) OpenTask(ctx context.Context, kind shipping.TaskKind, id shipping.TrackingID, description string) (shipping.TaskID, error) {
+	if id == "" || kind.String() == "" {
+		return "", ErrInvalidArgument
+	}
+
+	for _, t := range s.tasks.FindByCargo(id) {
+		if t.Kind == kind && !t.IsDone() {
+			return t.ID, nil
+		}
+	}
+
+	t := shipping.NewTask(kind, id, description, time.Now())
+
+	if err := s.tasks.Store(t); err != nil {
+		return "", err
+	}
+
+	return t.ID, nil
+}
+
+func (s *service) OpenReferenceTask(ctx context.Context, kind shipping.TaskKind, ref, description string) (shipping.TaskID, error) {
+	if ref == "" || kind.String() == "" {
+		return "", ErrInvalidArgument
+	}
+
+	for _, t := range s.tasks.FindAll() {
+		if t.Kind == kind && t.Reference == ref && !t.IsDone() {
+			return t.ID, nil
+		}
+	}
+
+	t := shipping.NewTask(kind, "", description, time.Now())
+	t.Reference = ref
+
+	if err := s.tasks.Store(t); err != nil {
+		return "", err
+	}
+
+	return t.ID, nil
+}
+
+func (s *service) AssignTask(ctx context.Context, id shipping.TaskID, to shipping.UserID) error {
+	if id == "" || to == "" {
+		return ErrInvalidArgument
+	}
+
+	if _, err := s.users.Find(to); err != nil {
+		return err
+	}
+
+	t, err := s.tasks.Find(id)
+	if err != nil {
+		return err
+	}
+
+	if err := t.Assign(to, time.Now()); err != nil {
+		return err
+	}
+
+	return s.tasks.Store(t)
+}
+
+func (s *service) UpdateStatus(ctx context.Context, id shipping.TaskID, status shipping.TaskStatus) error {
+	if id == "" {
+		return ErrInvalidArgument
+	}
+
+	if _, err := shipping.ParseTaskStatus(string(status)); err != nil {
+		return err
+	}
+
+	t, err := s.tasks.Find(id)
+	if err != nil {
+		return err
+	}
+
+	if err := t.SetStatus(status, time.Now()); err != nil {
+		return err
+	}
+
+	return s.tasks.Store(t)
+}
+
+func (s *service) LoadTask(ctx context.Context, id shipping.TaskID) (Task, error) {
+	if id == "" {
+		return Task{}, ErrInvalidArgument
+	}
+
+	t, err := s.tasks.Find(id)
+	if err != nil {
+		return Task{}, err
+	}
+
+	return assemble(t), nil
+}
+
+func (s *service) Tasks(ctx context.Context, status shipping.TaskStatus, assignee shipping.UserID) []Task {
+	result := []Task{}
+	for _, t := range s.tasks.FindAll() {
+		if status != "" && t.Status != status {
+			continue
+		}
+		if assignee != "" && t.Assignee != assignee {
+			continue
+		}
+		result = append(result, assemble(t))
+	}
+	return result
+}
+
+// NewService creates a task service with necessary dependencies.
+func NewService(tasks shipping.TaskRepository, users shipping.UserRepository) Service {
+	return &service{
+		tasks: tasks,
+		users: users,
+	}
+}
+
+// Task is a read model for task views.
+type Task struct {
+	ID          string    `json:"id"`
+	Kind        string    `json:"kind"`
+	TrackingID  string    `json:"tracking_id,omitempty"`
+	Reference   string    `json:"reference,omitempty"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	Assignee    string    `json:"assignee,omitempty"`
+	Opened      time.Time `json:"opened"`
+	Updated     time.Time `json:"updated"`
+}
+
+func assemble(t *shipping.Task) Task {
+	return Task{
+		ID:          string(t.ID),
+		Kind:        t.Kind.String(),
+		TrackingID:  string(t.TrackingID),
+		Reference:   t.Reference,
+		Description: t.Description,
+		Status:      string(t.Status),
+		Assignee:    string(t.Assignee),
+		Opened:      t.Opened,
+		Updated:     t.Updated,
+	}
+}
+
+type inspectionEventHandler struct {
+	s Service
+}
+
+func (h *inspectionEventHandler) CargoWasMisdirected(ctx context.Context, c *shipping.Cargo) {
+	h.s.OpenTask(ctx, shipping.RerouteTask, c.TrackingID,
+		fmt.Sprintf("Misdirected to %s, needs a new route to %s.", c.Delivery.LastKnownLocation, c.RouteSpecification.Destination))
+}
+
+func (h *inspectionEventHandler) CargoHasArrived(context.Context, *shipping.Cargo) {
+}
+
+func (h *inspectionEventHandler) CargoDeadlineAtRisk(context.Context, *shipping.Cargo, time.Duration) {
+}
+
+// NewInspectionEventHandler returns a handler that opens a reroute task for
+// every misdirected cargo.
+func NewInspectionEventHandler(s Service) inspection.EventHandler {
+	return &inspectionEventHandler{s: s}
+}
+
+type slaEventHandler struct {
+	s Service
+}
+
+func (h *slaEventHandler) CargoBreachedSLA(ctx context.Context, c *shipping.Cargo, breaches []shipping.SLABreach) {
+	var targets []string
+	for _, b := range breaches {
+		targets = append(targets, fmt.Sprintf("%s %s (limit %s)", b.Target, b.Actual, b.Limit))
+	}
+	h.s.OpenTask(ctx, shipping.SLABreachTask, c.TrackingID,
+		fmt.Sprintf("Breached %s service level: %s.", c.ServiceLevel, strings.Join(targets, ", ")))
+}
+
+// NewSLAEventHandler returns a handler that opens a task for every cargo
+// breaching its service level, so that the customer is followed up.
+func NewSLAEventHandler(s Service) sla.EventHandler {
+	return &slaEventHandler{s: s}
+}
+
+type ingestionReporter struct {
+	s Service
+}
+
+func (r *ingestionReporter) FileFailed(ctx context.Context, source, name string, err error) {
+	r.s.OpenReferenceTask(ctx, shipping.IngestionTask, source+"/"+name,
+		fmt.Sprintf("Could not import %s from %s: %v.", name, source, err))
+}
+
+// NewIngestionReporter returns a reporter that opens a task for every file
+// delivered by a partner that failed to import.
+func NewIngestionReporter(s Service) ingest.Reporter {
+	return &ingestionReporter{s: s}
+}
+
+type mailReporter struct {
+	s Service
+}
+
+func (r *mailReporter) MailFailed(ctx context.Context, m inbound.Message, err error) {
+	ref := m.ID
+	if ref == "" {
+		ref = m.From + ": " + m.Subject
+	}
+	r.s.OpenReferenceTask(ctx, shipping.InboundMailTask, "mail/"+ref,
+		fmt.Sprintf("Could not process mail from %s with subject %q: %v.", m.From, m.Subject, err))
+}
+
+// NewMailReporter returns a reporter that opens a task for every mail from
+// an agent that could not be processed.
+func NewMailReporter(s Service) inbound.Reporter {
+	return &mailReporter{s: s}
+}
+
+type portStatusEventHandler struct {
+	s Service
+}
+
+func (h *portStatusEventHandler) PortVarianceExceeded(ctx context.Context, p shipping.PortVariance) {
+	h.s.OpenReferenceTask(ctx, shipping.PortVarianceTask, "port/"+string(p.Location),
+		fmt.Sprintf("Cargos handled in %s %s later than planned on average, %d of %d late.", p.Location, p.AverageVariance(), p.Late, p.Handled))
+}
+
+// NewPortStatusEventHandler returns a handler that opens a task for every
+// port handling cargos later than planned, so that the terminal is followed
+// up.
+func NewPortStatusEventHandler(s Service) portstatus.EventHandler {
+	return &portStatusEventHandler{s: s}
+}
+
+type reconciliationEventHandler struct {
+	s Service
+}
+
+func (h *reconciliationEventHandler) VoyageNotReconciled(ctx context.Context, r shipping.VoyageReconciliation) {
+	for _, d := range r.Discrepancies {
+		h.s.OpenTask(ctx, shipping.VoyageDiscrepancyTask, d.TrackingID,
+			fmt.Sprintf("%s on voyage %s at %s.", d.Type, r.VoyageNumber, d.Location))
+	}
+}
+
+// NewReconciliationEventHandler returns a handler that opens a task for every
+// cargo handled on a completed voyage other than as booked.
+func NewReconciliationEventHandler(s Service) reconciliation.EventHandler {
+	return &reconciliationEventHandler{s: s}
+}
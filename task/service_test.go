@@ -0,0 +1,201 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/inbound"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+func newUserRepository() *mock.UserRepository {
+	var users mock.UserRepository
+	users.FindFn = func(id shipping.UserID) (*shipping.User, error) {
+		if id != "jdoe" {
+			return nil, shipping.ErrUnknownUser
+		}
+		return &shipping.User{ID: id}, nil
+	}
+	return &users
+}
+
+func newTaskRepository() *mock.TaskRepository {
+	var stored []*shipping.Task
+
+	var tasks mock.TaskRepository
+	tasks.StoreFn = func(t *shipping.Task) error {
+		for _, st := range stored {
+			if st.ID == t.ID {
+				*st = *t
+				return nil
+			}
+		}
+		stored = append(stored, t)
+		return nil
+	}
+	tasks.FindFn = func(id shipping.TaskID) (*shipping.Task, error) {
+		for _, t := range stored {
+			if t.ID == id {
+				return t, nil
+			}
+		}
+		return nil, shipping.ErrUnknownTask
+	}
+	tasks.FindByCargoFn = func(id shipping.TrackingID) []*shipping.Task {
+		var result []*shipping.Task
+		for _, t := range stored {
+			if t.TrackingID == id {
+				result = append(result, t)
+			}
+		}
+		return result
+	}
+	tasks.FindAllFn = func() []*shipping.Task {
+		return stored
+	}
+	return &tasks
+}
+
+func TestOpenTask(t *testing.T) {
+	s := NewService(newTaskRepository(), newUserRepository())
+
+	first, err := s.OpenTask(context.Background(), shipping.RerouteTask, "ABC", "Misdirected")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := s.OpenTask(context.Background(), shipping.RerouteTask, "ABC", "Misdirected")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != first {
+		t.Errorf("task id = %s; want = %s", again, first)
+	}
+
+	breach, err := s.OpenTask(context.Background(), shipping.SLABreachTask, "ABC", "Breached")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if breach == first {
+		t.Error("expected a separate task for another kind of finding")
+	}
+
+	if err := s.UpdateStatus(context.Background(), first, shipping.TaskDone); err != nil {
+		t.Fatal(err)
+	}
+
+	recurred, err := s.OpenTask(context.Background(), shipping.RerouteTask, "ABC", "Misdirected")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recurred == first {
+		t.Error("expected a new task once the previous one was done")
+	}
+
+	if got := s.Tasks(context.Background(), shipping.TaskOpen, ""); len(got) != 2 {
+		t.Errorf("len(open) = %d; want = %d", len(got), 2)
+	}
+	if got := s.Tasks(context.Background(), "", ""); len(got) != 3 {
+		t.Errorf("len(all) = %d; want = %d", len(got), 3)
+	}
+}
+
+func TestAssignTask(t *testing.T) {
+	s := NewService(newTaskRepository(), newUserRepository())
+
+	id, err := s.OpenTask(context.Background(), shipping.RerouteTask, "ABC", "Misdirected")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.AssignTask(context.Background(), id, "nobody"); err != shipping.ErrUnknownUser {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownUser)
+	}
+
+	if err := s.AssignTask(context.Background(), id, "jdoe"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.UpdateStatus(context.Background(), id, shipping.TaskInProgress); err != nil {
+		t.Fatal(err)
+	}
+
+	got := s.Tasks(context.Background(), "", "jdoe")
+	if len(got) != 1 {
+		t.Fatalf("len(got) = %d; want = %d", len(got), 1)
+	}
+	if got[0].Status != string(shipping.TaskInProgress) {
+		t.Errorf("Status = %s; want = %s", got[0].Status, shipping.TaskInProgress)
+	}
+
+	if err := s.UpdateStatus(context.Background(), id, "closed"); err != shipping.ErrUnknownTaskStatus {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownTaskStatus)
+	}
+}
+
+func TestEventHandlers(t *testing.T) {
+	s := NewService(newTaskRepository(), newUserRepository())
+
+	c := shipping.NewCargo("ABC", shipping.RouteSpecification{Origin: shipping.SESTO, Destination: shipping.CNHKG})
+	c.ServiceLevel = "express"
+
+	NewInspectionEventHandler(s).CargoWasMisdirected(context.Background(), c)
+	NewSLAEventHandler(s).CargoBreachedSLA(context.Background(), c, []shipping.SLABreach{
+		{Target: shipping.TransitTarget, Actual: 30 * time.Hour, Limit: 24 * time.Hour},
+	})
+
+	got := s.Tasks(context.Background(), shipping.TaskOpen, "")
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %d; want = %d", len(got), 2)
+	}
+
+	kinds := map[string]bool{got[0].Kind: true, got[1].Kind: true}
+	for _, k := range []shipping.TaskKind{shipping.RerouteTask, shipping.SLABreachTask} {
+		if !kinds[k.String()] {
+			t.Errorf("no %s task opened", k)
+		}
+	}
+}
+
+func TestIngestionReporter(t *testing.T) {
+	s := NewService(newTaskRepository(), newUserRepository())
+
+	r := NewIngestionReporter(s)
+	r.FileFailed(context.Background(), "handling", "2016-03-02.csv", errors.New("invalid file"))
+	r.FileFailed(context.Background(), "handling", "2016-03-02.csv", errors.New("invalid file"))
+	r.FileFailed(context.Background(), "handling", "2016-03-03.csv", errors.New("invalid file"))
+
+	got := s.Tasks(context.Background(), shipping.TaskOpen, "")
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %d; want = %d", len(got), 2)
+	}
+	if got[0].Reference != "handling/2016-03-02.csv" || got[0].Kind != shipping.IngestionTask.String() {
+		t.Errorf("got[0] = %+v; want an ingestion task about handling/2016-03-02.csv", got[0])
+	}
+	if got[0].TrackingID != "" {
+		t.Errorf("TrackingID = %s; want none", got[0].TrackingID)
+	}
+}
+
+func TestMailReporter(t *testing.T) {
+	s := NewService(newTaskRepository(), newUserRepository())
+
+	r := NewMailReporter(s)
+	r.MailFailed(context.Background(), inbound.Message{ID: "<1@agent.se>", From: "ops@agent.se"}, inbound.ErrUnrecognized)
+	r.MailFailed(context.Background(), inbound.Message{ID: "<1@agent.se>", From: "ops@agent.se"}, inbound.ErrUnrecognized)
+	r.MailFailed(context.Background(), inbound.Message{From: "ops@agent.se", Subject: "Movements"}, shipping.ErrUnknownCargo)
+
+	got := s.Tasks(context.Background(), shipping.TaskOpen, "")
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %d; want = %d", len(got), 2)
+	}
+	if got[0].Reference != "mail/<1@agent.se>" || got[0].Kind != shipping.InboundMailTask.String() {
+		t.Errorf("got[0] = %+v; want an inbound mail task about mail/<1@agent.se>", got[0])
+	}
+	if got[1].Reference != "mail/ops@agent.se: Movements" {
+		t.Errorf("Reference = %s; want = %s", got[1].Reference, "mail/ops@agent.se: Movements")
+	}
+}
@@ -0,0 +1,56 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskLifecycle(t *testing.T) {
+	opened := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	task := NewTask(RerouteTask, "ABC", "Misdirected", opened)
+
+	if task.Status != TaskOpen {
+		t.Errorf("task.Status = %s; want = %s", task.Status, TaskOpen)
+	}
+
+	if err := task.Assign("jdoe", opened.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := task.SetStatus(TaskDone, opened.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if task.Assignee != "jdoe" {
+		t.Errorf("task.Assignee = %s; want = %s", task.Assignee, "jdoe")
+	}
+	if want := opened.Add(2 * time.Hour); !task.Updated.Equal(want) {
+		t.Errorf("task.Updated = %v; want = %v", task.Updated, want)
+	}
+
+	if err := task.SetStatus(TaskOpen, opened); err != ErrTaskDone {
+		t.Errorf("err = %v; want = %v", err, ErrTaskDone)
+	}
+	if err := task.Assign("asmith", opened); err != ErrTaskDone {
+		t.Errorf("err = %v; want = %v", err, ErrTaskDone)
+	}
+}
+
+func TestParseTaskStatus(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want TaskStatus
+		err  error
+	}{
+		{"open", TaskOpen, nil},
+		{"in-progress", TaskInProgress, nil},
+		{"done", TaskDone, nil},
+		{"closed", "", ErrUnknownTaskStatus},
+		{"", "", ErrUnknownTaskStatus},
+	} {
+		got, err := ParseTaskStatus(tt.in)
+		if got != tt.want || err != tt.err {
+			t.Errorf("ParseTaskStatus(%q) = %s, %v; want = %s, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package shipping
+
+import (
+	"errors"
+	"time"
+)
+
+// SensorType identifies what a sensor measures.
+type SensorType string
+
+// Sensor types, and the units their readings are given in.
+const (
+	// TemperatureSensor reads the temperature in degrees Celsius.
+	TemperatureSensor SensorType = "temperature"
+
+	// HumiditySensor reads the relative humidity in percent.
+	HumiditySensor SensorType = "humidity"
+
+	// ShockSensor reads the acceleration in g.
+	ShockSensor SensorType = "shock"
+)
+
+// SensorTypes are the known sensor types.
+var SensorTypes = []SensorType{
+	TemperatureSensor,
+	HumiditySensor,
+	ShockSensor,
+}
+
+// IsValidSensorType returns whether t is a known sensor type.
+func IsValidSensorType(t SensorType) bool {
+	for _, st := range SensorTypes {
+		if st == t {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrInvalidSensorLimits is used when the limits of a sensor are for an
+// unknown sensor type, or have a minimum above the maximum.
+var ErrInvalidSensorLimits = errors.New("invalid sensor limits")
+
+// SensorReading is a measurement by a sensor traveling with a cargo, such as
+// one fitted to its container.
+type SensorReading struct {
+	TrackingID TrackingID
+	Sensor     SensorType
+
+	// DeviceID identifies the device that took the reading.
+	DeviceID string
+
+	Value    float64
+	Recorded time.Time
+}
+
+// SensorRange is the range, inclusive, that the readings of a sensor are
+// required to stay within.
+type SensorRange struct {
+	Min float64
+	Max float64
+}
+
+// Contains returns whether v is within the range.
+func (r SensorRange) Contains(v float64) bool {
+	return v >= r.Min && v <= r.Max
+}
+
+// SensorLimits holds the ranges the readings of each sensor type of a cargo
+// are required to stay within. Sensor types without a range are not
+// limited.
+type SensorLimits map[SensorType]SensorRange
+
+// Validate returns ErrInvalidSensorLimits unless every range is for a known
+// sensor type and has its minimum at or below its maximum.
+func (l SensorLimits) Validate() error {
+	for t, r := range l {
+		if !IsValidSensorType(t) || r.Min > r.Max {
+			return ErrInvalidSensorLimits
+		}
+	}
+	return nil
+}
+
+// Exceeds returns the range a reading falls outside of, if any.
+func (l SensorLimits) Exceeds(r SensorReading) (SensorRange, bool) {
+	lim, ok := l[r.Sensor]
+	if !ok || lim.Contains(r.Value) {
+		return SensorRange{}, false
+	}
+	return lim, true
+}
+
+// TelemetryRepository provides access a sensor reading store.
+type TelemetryRepository interface {
+	Store(r SensorReading) error
+
+	// Find returns the readings of a sensor type of a cargo, oldest first,
+	// recorded in the window from and to. A zero time leaves that end of the
+	// window open, and an empty sensor type selects every sensor.
+	Find(id TrackingID, sensor SensorType, from, to time.Time) []SensorReading
+}
@@ -0,0 +1,52 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type instrumentingService struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           Service
+}
+
+// NewInstrumentingService returns an instance of an instrumenting Service.
+func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram, s Service) Service {
+	return &instrumentingService{
+		requestCount:   counter,
+		requestLatency: latency,
+		next:           s,
+	}
+}
+
+func (s *instrumentingService) Record(ctx context.Context, readings []shipping.SensorReading) (int, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "record").Add(1)
+		s.requestLatency.With("method", "record").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Record(ctx, readings)
+}
+
+func (s *instrumentingService) Readings(ctx context.Context, id shipping.TrackingID, sensor shipping.SensorType, from, to time.Time) ([]Reading, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "readings").Add(1)
+		s.requestLatency.With("method", "readings").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Readings(ctx, id, sensor, from, to)
+}
+
+func (s *instrumentingService) SetLimits(ctx context.Context, id shipping.TrackingID, limits shipping.SensorLimits) error {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "set_limits").Add(1)
+		s.requestLatency.With("method", "set_limits").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.SetLimits(ctx, id, limits)
+}
@@ -0,0 +1,89 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
+)
+
+type loggingService struct {
+	logger log.Logger
+	next   Service
+}
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(logger log.Logger, s Service) Service {
+	return &loggingService{logger, s}
+}
+
+func (s *loggingService) Record(ctx context.Context, readings []shipping.SensorReading) (n int, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "record",
+			"request_id", correlation.FromContext(ctx),
+			"readings", len(readings),
+			"recorded", n,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Record(ctx, readings)
+}
+
+func (s *loggingService) Readings(ctx context.Context, id shipping.TrackingID, sensor shipping.SensorType, from, to time.Time) (readings []Reading, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "readings",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"sensor", sensor,
+			"from", from,
+			"to", to,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.Readings(ctx, id, sensor, from, to)
+}
+
+func (s *loggingService) SetLimits(ctx context.Context, id shipping.TrackingID, limits shipping.SensorLimits) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "set_limits",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"limits", len(limits),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.next.SetLimits(ctx, id, limits)
+}
+
+type loggingEventHandler struct {
+	logger log.Logger
+}
+
+// NewLoggingEventHandler returns an EventHandler that reports readings
+// outside of the limits of cargos through the log.
+func NewLoggingEventHandler(logger log.Logger) EventHandler {
+	return &loggingEventHandler{logger}
+}
+
+func (h *loggingEventHandler) CargoSensorOutOfRange(ctx context.Context, c *shipping.Cargo, r shipping.SensorReading, limit shipping.SensorRange) {
+	h.logger.Log(
+		"msg", "cargo sensor out of range",
+		"request_id", correlation.FromContext(ctx),
+		"tracking_id", c.TrackingID,
+		"sensor", r.Sensor,
+		"device_id", r.DeviceID,
+		"value", r.Value,
+		"min", limit.Min,
+		"max", limit.Max,
+		"recorded", r.Recorded,
+	)
+}
@@ -0,0 +1,163 @@
+// Package telemetry provides the use-case of collecting the readings of
+// sensors traveling with cargos, such as the temperature inside a reefer
+// container, and alerting when they leave the limits set for the cargo.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// ErrInvalidArgument is returned when one or more arguments are invalid.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// EventHandler provides a means of subscribing to readings outside of the
+// limits of a cargo.
+type EventHandler interface {
+	CargoSensorOutOfRange(ctx context.Context, c *shipping.Cargo, r shipping.SensorReading, limit shipping.SensorRange)
+}
+
+// EventHandlers notifies several event handlers in order.
+type EventHandlers []EventHandler
+
+// CargoSensorOutOfRange notifies every handler.
+func (hs EventHandlers) CargoSensorOutOfRange(ctx context.Context, c *shipping.Cargo, r shipping.SensorReading, limit shipping.SensorRange) {
+	for _, h := range hs {
+		h.CargoSensorOutOfRange(ctx, c, r, limit)
+	}
+}
+
+// Service is the interface that provides telemetry methods.
+type Service interface {
+	// Record stores sensor readings, which may be for several cargos. Either
+	// all readings are valid or none are recorded. It returns the number of
+	// readings recorded.
+	Record(ctx context.Context, readings []shipping.SensorReading) (int, error)
+
+	// Readings returns the readings of a cargo recorded in the window from
+	// and to, oldest first. An empty sensor type selects every sensor.
+	Readings(ctx context.Context, id shipping.TrackingID, sensor shipping.SensorType, from, to time.Time) ([]Reading, error)
+
+	// SetLimits sets the ranges the sensors of a cargo are required to read
+	// within, replacing any previous ones.
+	SetLimits(ctx context.Context, id shipping.TrackingID, limits shipping.SensorLimits) error
+}
+
+type service struct {
+	readings shipping.TelemetryRepository
+	cargos   shipping.CargoRepository
+	handler  EventHandler
+}
+
+func (s *service) Record(ctx context.Context, readings []shipping.SensorReading) (int, error) {
+	if len(readings) == 0 {
+		return 0, ErrInvalidArgument
+	}
+
+	now := time.Now()
+
+	cargos := make(map[shipping.TrackingID]*shipping.Cargo)
+	for i, r := range readings {
+		if r.TrackingID == "" || !shipping.IsValidSensorType(r.Sensor) {
+			return 0, ErrInvalidArgument
+		}
+		if r.Recorded.IsZero() {
+			readings[i].Recorded = now
+		}
+		if _, ok := cargos[r.TrackingID]; ok {
+			continue
+		}
+		c, err := s.cargos.Find(r.TrackingID)
+		if err != nil {
+			return 0, err
+		}
+		cargos[r.TrackingID] = c
+	}
+
+	var n int
+	for _, r := range readings {
+		if err := s.readings.Store(r); err != nil {
+			return n, err
+		}
+		n++
+
+		c := cargos[r.TrackingID]
+		if lim, ok := c.SensorLimits.Exceeds(r); ok {
+			s.handler.CargoSensorOutOfRange(ctx, c, r, lim)
+		}
+	}
+
+	return n, nil
+}
+
+func (s *service) Readings(ctx context.Context, id shipping.TrackingID, sensor shipping.SensorType, from, to time.Time) ([]Reading, error) {
+	if id == "" || sensor != "" && !shipping.IsValidSensorType(sensor) {
+		return nil, ErrInvalidArgument
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return nil, ErrInvalidArgument
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Reading, 0)
+	for _, r := range s.readings.Find(id, sensor, from, to) {
+		result = append(result, assemble(r, c.SensorLimits))
+	}
+
+	return result, nil
+}
+
+func (s *service) SetLimits(ctx context.Context, id shipping.TrackingID, limits shipping.SensorLimits) error {
+	if id == "" {
+		return ErrInvalidArgument
+	}
+
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+
+	c, err := s.cargos.Find(id)
+	if err != nil {
+		return err
+	}
+
+	c.SensorLimits = limits
+
+	return s.cargos.Store(c)
+}
+
+// NewService creates a telemetry service with necessary dependencies.
+func NewService(readings shipping.TelemetryRepository, cargos shipping.CargoRepository, handler EventHandler) Service {
+	return &service{
+		readings: readings,
+		cargos:   cargos,
+		handler:  handler,
+	}
+}
+
+// Reading is a read model for telemetry views.
+type Reading struct {
+	Sensor     string    `json:"sensor"`
+	DeviceID   string    `json:"device_id,omitempty"`
+	Value      float64   `json:"value"`
+	Recorded   time.Time `json:"recorded"`
+	OutOfRange bool      `json:"out_of_range,omitempty"`
+}
+
+func assemble(r shipping.SensorReading, limits shipping.SensorLimits) Reading {
+	_, exceeded := limits.Exceeds(r)
+	return Reading{
+		Sensor:     string(r.Sensor),
+		DeviceID:   r.DeviceID,
+		Value:      r.Value,
+		Recorded:   r.Recorded,
+		OutOfRange: exceeded,
+	}
+}
@@ -0,0 +1,116 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/mock"
+)
+
+type stubEventHandler struct {
+	alerts []shipping.SensorReading
+}
+
+func (h *stubEventHandler) CargoSensorOutOfRange(ctx context.Context, c *shipping.Cargo, r shipping.SensorReading, limit shipping.SensorRange) {
+	h.alerts = append(h.alerts, r)
+}
+
+func newCargoRepository(c *shipping.Cargo) *mock.CargoRepository {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		if id != c.TrackingID {
+			return nil, shipping.ErrUnknownCargo
+		}
+		return c, nil
+	}
+	cargos.StoreFn = func(*shipping.Cargo) error { return nil }
+	return &cargos
+}
+
+func newTelemetryRepository() *mock.TelemetryRepository {
+	var stored []shipping.SensorReading
+
+	var readings mock.TelemetryRepository
+	readings.StoreFn = func(r shipping.SensorReading) error {
+		stored = append(stored, r)
+		return nil
+	}
+	readings.FindFn = func(id shipping.TrackingID, sensor shipping.SensorType, from, to time.Time) []shipping.SensorReading {
+		var result []shipping.SensorReading
+		for _, r := range stored {
+			if r.TrackingID == id && (sensor == "" || r.Sensor == sensor) && !r.Recorded.Before(from) && (to.IsZero() || !r.Recorded.After(to)) {
+				result = append(result, r)
+			}
+		}
+		return result
+	}
+	return &readings
+}
+
+func TestRecord(t *testing.T) {
+	var (
+		c       = shipping.NewCargo("ABC", shipping.RouteSpecification{Origin: shipping.SESTO, Destination: shipping.CNHKG})
+		handler stubEventHandler
+		start   = time.Date(2016, time.March, 6, 12, 0, 0, 0, time.UTC)
+	)
+
+	s := NewService(newTelemetryRepository(), newCargoRepository(c), &handler)
+
+	ctx := context.Background()
+
+	if err := s.SetLimits(ctx, "ABC", shipping.SensorLimits{shipping.TemperatureSensor: {Min: 8, Max: 2}}); err != shipping.ErrInvalidSensorLimits {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrInvalidSensorLimits)
+	}
+	if err := s.SetLimits(ctx, "ABC", shipping.SensorLimits{shipping.TemperatureSensor: {Min: 2, Max: 8}}); err != nil {
+		t.Fatal(err)
+	}
+
+	reading := func(id shipping.TrackingID, sensor shipping.SensorType, v float64, min int) shipping.SensorReading {
+		return shipping.SensorReading{TrackingID: id, Sensor: sensor, Value: v, Recorded: start.Add(time.Duration(min) * time.Minute)}
+	}
+
+	if _, err := s.Record(ctx, []shipping.SensorReading{
+		reading("ABC", shipping.TemperatureSensor, 4, 0),
+		reading("XYZ", shipping.TemperatureSensor, 4, 0),
+	}); err != shipping.ErrUnknownCargo {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownCargo)
+	}
+	if _, err := s.Record(ctx, []shipping.SensorReading{reading("ABC", "pressure", 1, 0)}); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+
+	n, err := s.Record(ctx, []shipping.SensorReading{
+		reading("ABC", shipping.TemperatureSensor, 4, 0),
+		reading("ABC", shipping.HumiditySensor, 60, 0),
+		reading("ABC", shipping.TemperatureSensor, 9.5, 5),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("n = %d; want = %d", n, 3)
+	}
+	if len(handler.alerts) != 1 || handler.alerts[0].Value != 9.5 {
+		t.Errorf("alerts = %+v; want the reading of 9.5", handler.alerts)
+	}
+
+	got, err := s.Readings(ctx, "ABC", shipping.TemperatureSensor, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %d; want = %d", len(got), 2)
+	}
+	if got[0].OutOfRange || !got[1].OutOfRange {
+		t.Errorf("got = %+v; want only the second out of range", got)
+	}
+
+	if got, _ := s.Readings(ctx, "ABC", "", start.Add(time.Minute), time.Time{}); len(got) != 1 {
+		t.Errorf("len(got) = %d; want = %d", len(got), 1)
+	}
+	if _, err := s.Readings(ctx, "XYZ", "", time.Time{}, time.Time{}); err != shipping.ErrUnknownCargo {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownCargo)
+	}
+}
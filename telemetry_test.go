@@ -0,0 +1,36 @@
+package shipping
+
+import "testing"
+
+func TestSensorLimits(t *testing.T) {
+	limits := SensorLimits{TemperatureSensor: {Min: 2, Max: 8}}
+
+	if err := limits.Validate(); err != nil {
+		t.Errorf("Validate() = %v; want = %v", err, nil)
+	}
+
+	for _, l := range []SensorLimits{
+		{"pressure": {Min: 0, Max: 1}},
+		{TemperatureSensor: {Min: 8, Max: 2}},
+	} {
+		if err := l.Validate(); err != ErrInvalidSensorLimits {
+			t.Errorf("%v: Validate() = %v; want = %v", l, err, ErrInvalidSensorLimits)
+		}
+	}
+
+	tests := []struct {
+		reading  SensorReading
+		exceeded bool
+	}{
+		{SensorReading{Sensor: TemperatureSensor, Value: 2}, false},
+		{SensorReading{Sensor: TemperatureSensor, Value: 8}, false},
+		{SensorReading{Sensor: TemperatureSensor, Value: 8.5}, true},
+		{SensorReading{Sensor: TemperatureSensor, Value: -1}, true},
+		{SensorReading{Sensor: ShockSensor, Value: 100}, false},
+	}
+	for _, tt := range tests {
+		if _, got := limits.Exceeds(tt.reading); got != tt.exceeded {
+			t.Errorf("Exceeds(%+v) = %v; want = %v", tt.reading, got, tt.exceeded)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package shipping
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// TerminalID uniquely identifies a terminal reporting handling events.
+type TerminalID string
+
+// Terminal is a party authorized to report the handling of cargos at a
+// location, such as a container terminal or a depot.
+type Terminal struct {
+	ID       TerminalID
+	Name     string
+	Location UNLocode
+
+	// KeyHash is the hash of the key the terminal authenticates with. The
+	// key itself is never stored.
+	KeyHash string
+
+	// SigningSecret is shared with the terminal for signing its reports, if
+	// it signs them. Unlike the key, it is kept to verify the signatures.
+	SigningSecret string
+}
+
+// HashTerminalKey returns the hash of a terminal key to be stored.
+func HashTerminalKey(key string) string {
+	return hashKey(key)
+}
+
+// Authenticate returns whether the key is the one of the terminal.
+func (t Terminal) Authenticate(key string) bool {
+	return matchKey(key, t.KeyHash)
+}
+
+// SignsReports returns whether the terminal signs the handling it reports.
+func (t Terminal) SignsReports() bool {
+	return t.SigningSecret != ""
+}
+
+// VerifySignature returns whether mac is the signature of the message made
+// with the signing secret of the terminal.
+func (t Terminal) VerifySignature(message, mac []byte) bool {
+	return t.SignsReports() && hmac.Equal(mac, SignTerminalMessage(t.SigningSecret, message))
+}
+
+// SignTerminalMessage returns the signature of a message made with the
+// signing secret of a terminal, an HMAC-SHA256.
+func SignTerminalMessage(secret string, message []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// matchKey compares a key to a stored hash in constant time.
+func matchKey(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashKey(key)), []byte(hash)) == 1
+}
+
+// Reports returns whether the terminal may report handling at a location.
+func (t Terminal) Reports(loc UNLocode) bool {
+	return t.Location == loc
+}
+
+var (
+	// ErrUnknownTerminal is used when a terminal could not be found.
+	ErrUnknownTerminal = errors.New("unknown terminal")
+
+	// ErrUnauthorizedTerminal is used when a terminal fails to
+	// authenticate.
+	ErrUnauthorizedTerminal = errors.New("unauthorized terminal")
+
+	// ErrTerminalLocation is used when a terminal reports handling at a
+	// location it is not associated with.
+	ErrTerminalLocation = errors.New("terminal does not report for location")
+)
+
+// TerminalRepository provides access a terminal store.
+type TerminalRepository interface {
+	Store(t *Terminal) error
+	Find(id TerminalID) (*Terminal, error)
+	FindAll() []*Terminal
+}
+
+// ReportNonceRepository remembers the nonces of the signed reports received
+// from terminals until the reports would no longer be accepted, so that a
+// report cannot be sent again, not even to another instance or after a
+// restart.
+type ReportNonceRepository interface {
+	// Add remembers a nonce until it expires. It returns ErrNonceUsed if
+	// the nonce is remembered already and has not expired by now.
+	Add(nonce string, now, expires time.Time) error
+
+	// Remove forgets a nonce.
+	Remove(nonce string) error
+}
+
+// ErrNonceUsed is used when adding a nonce that is remembered already.
+var ErrNonceUsed = errors.New("nonce already used")
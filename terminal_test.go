@@ -0,0 +1,43 @@
+package shipping
+
+import "testing"
+
+func TestTerminal(t *testing.T) {
+	term := Terminal{
+		ID:       "HAM-CTA",
+		Location: DEHAM,
+		KeyHash:  HashTerminalKey("secret"),
+	}
+
+	if !term.Authenticate("secret") {
+		t.Errorf("term.Authenticate(%q) = false; want = true", "secret")
+	}
+	if term.Authenticate("guess") {
+		t.Errorf("term.Authenticate(%q) = true; want = false", "guess")
+	}
+	if term.KeyHash == "secret" {
+		t.Errorf("the key should not be stored in clear text")
+	}
+
+	if !term.Reports(DEHAM) {
+		t.Errorf("term.Reports(%s) = false; want = true", DEHAM)
+	}
+	if term.Reports(SESTO) {
+		t.Errorf("term.Reports(%s) = true; want = false", SESTO)
+	}
+}
+
+func TestTerminal_VerifySignature(t *testing.T) {
+	term := Terminal{ID: "HAM-CTA", SigningSecret: "signing"}
+
+	msg := []byte("1457287931\nn1\n{}")
+	if !term.VerifySignature(msg, SignTerminalMessage("signing", msg)) {
+		t.Errorf("signature made with the secret of the terminal does not verify")
+	}
+	if term.VerifySignature(msg, SignTerminalMessage("guess", msg)) {
+		t.Errorf("signature made with another secret verifies")
+	}
+	if (Terminal{}).VerifySignature(msg, SignTerminalMessage("", msg)) {
+		t.Errorf("terminal without a secret verifies signatures")
+	}
+}
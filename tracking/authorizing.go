@@ -0,0 +1,33 @@
+package tracking
+
+import (
+	"context"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+type authorizingService struct {
+	authorize Authorizer
+	next      Service
+}
+
+// NewAuthorizingService returns a new instance of an authorizing Service,
+// refusing the calls the authorizer returns an error for. Methods that cannot
+// fail return nothing when refused.
+func NewAuthorizingService(a Authorizer, s Service) Service {
+	return &authorizingService{authorize: a, next: s}
+}
+
+func (s *authorizingService) Track(ctx context.Context, id string) (Cargo, error) {
+	if err := s.authorize(ctx, "track"); err != nil {
+		return Cargo{}, err
+	}
+	return s.next.Track(ctx, id)
+}
+
+func (s *authorizingService) Events(ctx context.Context, id string, q shipping.HandlingHistoryQuery) (EventPage, error) {
+	if err := s.authorize(ctx, "events"); err != nil {
+		return EventPage{}, err
+	}
+	return s.next.Events(ctx, id, q)
+}
@@ -0,0 +1,113 @@
+package tracking
+
+import (
+	"sort"
+	"sync"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+// Index holds the tracking IDs of the cargos booked, to suggest the ones
+// closest to a tracking ID that is not known, e.g. because of a typo.
+type Index struct {
+	mtx sync.RWMutex
+	ids map[shipping.TrackingID]bool
+}
+
+// NewIndex returns a new, empty instance of an Index.
+func NewIndex() *Index {
+	return &Index{ids: make(map[shipping.TrackingID]bool)}
+}
+
+// Add adds a tracking ID to the index.
+func (i *Index) Add(id shipping.TrackingID) {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	i.ids[id] = true
+}
+
+// Rebuild replaces the tracking IDs in the index with those of the cargos
+// in the repository.
+func (i *Index) Rebuild(cargos shipping.CargoRepository) error {
+	ids := make(map[shipping.TrackingID]bool)
+	if err := cargos.ForEach(func(c *shipping.Cargo) error {
+		ids[c.TrackingID] = true
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	i.ids = ids
+
+	return nil
+}
+
+// Suggest returns at most limit of the tracking IDs within the given edit
+// distance of id, closest first.
+func (i *Index) Suggest(id shipping.TrackingID, maxDistance, limit int) []shipping.TrackingID {
+	type match struct {
+		id       shipping.TrackingID
+		distance int
+	}
+
+	var matches []match
+
+	i.mtx.RLock()
+	for known := range i.ids {
+		if d := distance(string(id), string(known)); d <= maxDistance {
+			matches = append(matches, match{known, d})
+		}
+	}
+	i.mtx.RUnlock()
+
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].distance != matches[b].distance {
+			return matches[a].distance < matches[b].distance
+		}
+		return matches[a].id < matches[b].id
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	result := make([]shipping.TrackingID, len(matches))
+	for k, m := range matches {
+		result[k] = m.id
+	}
+	return result
+}
+
+// distance returns the number of characters that need to be inserted,
+// deleted, substituted or swapped with their neighbour to turn a into b.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// d[i][j] is the distance between the first i runes of a and the first
+	// j runes of b.
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,22 @@
+package tracking
+
+import "testing"
+
+func TestDistance(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"ABC123", "ABC123", 0},
+		{"ABC123", "ABC124", 1},
+		{"ABC123", "ABC1234", 1},
+		{"ABC123", "ABC13", 1},
+		{"ABC123", "ACB123", 1},
+		{"ABC123", "XYZ789", 6},
+		{"", "ABC", 3},
+	} {
+		if got := distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("distance(%q, %q) = %d; want = %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
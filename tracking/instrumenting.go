@@ -1,9 +1,12 @@
 package tracking
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-kit/kit/metrics"
+
+	shipping "github.com/marcusolsson/goddd"
 )
 
 type instrumentingService struct {
@@ -21,11 +24,20 @@ func NewInstrumentingService(counter metrics.Counter, latency metrics.Histogram,
 	}
 }
 
-func (s *instrumentingService) Track(id string) (Cargo, error) {
+func (s *instrumentingService) Track(ctx context.Context, id string) (Cargo, error) {
 	defer func(begin time.Time) {
 		s.requestCount.With("method", "track").Add(1)
 		s.requestLatency.With("method", "track").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return s.next.Track(id)
+	return s.next.Track(ctx, id)
+}
+
+func (s *instrumentingService) Events(ctx context.Context, id string, q shipping.HandlingHistoryQuery) (EventPage, error) {
+	defer func(begin time.Time) {
+		s.requestCount.With("method", "events").Add(1)
+		s.requestLatency.With("method", "events").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return s.next.Events(ctx, id, q)
 }
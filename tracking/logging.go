@@ -1,9 +1,13 @@
 package tracking
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-kit/kit/log"
+
+	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/correlation"
 )
 
 type loggingService struct {
@@ -16,9 +20,25 @@ func NewLoggingService(logger log.Logger, s Service) Service {
 	return &loggingService{logger, s}
 }
 
-func (s *loggingService) Track(id string) (c Cargo, err error) {
+func (s *loggingService) Track(ctx context.Context, id string) (c Cargo, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log("method", "track", "request_id", correlation.FromContext(ctx), "tracking_id", id, "took", time.Since(begin), "err", err)
+	}(time.Now())
+	return s.next.Track(ctx, id)
+}
+
+func (s *loggingService) Events(ctx context.Context, id string, q shipping.HandlingHistoryQuery) (p EventPage, err error) {
 	defer func(begin time.Time) {
-		s.logger.Log("method", "track", "tracking_id", id, "took", time.Since(begin), "err", err)
+		s.logger.Log(
+			"method", "events",
+			"request_id", correlation.FromContext(ctx),
+			"tracking_id", id,
+			"from", q.From,
+			"to", q.To,
+			"limit", q.Limit,
+			"took", time.Since(begin),
+			"err", err,
+		)
 	}(time.Now())
-	return s.next.Track(id)
+	return s.next.Events(ctx, id, q)
 }
@@ -0,0 +1,174 @@
+package tracking
+
+import (
+	"strings"
+)
+
+// messageKey identifies a message in the catalog.
+type messageKey int
+
+const (
+	msgEventNotHandled messageKey = iota
+	msgEventReceived
+	msgEventLoaded
+	msgEventUnloaded
+	msgEventClaimed
+	msgEventCustoms
+	msgEventUnknown
+
+	msgStatusNotReceived
+	msgStatusInPort
+	msgStatusOnboard
+	msgStatusArrived
+	msgStatusClaimed
+	msgStatusRejected
+	msgStatusUnknown
+
+	msgNextNone
+	msgNextReceive
+	msgNextLoad
+	msgNextUnload
+	msgNextClaim
+	msgNextCustoms
+
+	msgWarningOpen
+	msgWarningCongested
+	msgWarningClosed
+	msgWarningDelay
+	msgWarningReason
+	msgWarningEnd
+)
+
+// catalog holds the messages of tracking views per language. Arguments are
+// referred to by position, e.g. {0}.
+var catalog = map[string]map[messageKey]string{
+	"en": {
+		msgEventNotHandled: "Cargo has not yet been received.",
+		msgEventReceived:   "Received in {0}, at {1}",
+		msgEventLoaded:     "Loaded onto voyage {0} in {1}, at {2}.",
+		msgEventUnloaded:   "Unloaded off voyage {0} in {1}, at {2}.",
+		msgEventClaimed:    "Claimed in {0}, at {1}.",
+		msgEventCustoms:    "Cleared customs in {0}, at {1}.",
+		msgEventUnknown:    "[Unknown status]",
+
+		msgStatusNotReceived: "Not received",
+		msgStatusInPort:      "In port {0}",
+		msgStatusOnboard:     "Onboard voyage {0}",
+		msgStatusArrived:     "Onboard voyage {0}, arrived in {1} (unconfirmed)",
+		msgStatusClaimed:     "Claimed",
+		msgStatusRejected:    "Rejected, returned as {0}",
+		msgStatusUnknown:     "Unknown",
+
+		msgNextNone:    "There are currently no expected activities for this shipping.",
+		msgNextReceive: "Next expected activity is to receive cargo in {0}.",
+		msgNextLoad:    "Next expected activity is to load cargo onto voyage {0} in {1}.",
+		msgNextUnload:  "Next expected activity is to unload cargo off of voyage {0} in {1}.",
+		msgNextClaim:   "Next expected activity is to claim cargo in {0}.",
+		msgNextCustoms: "Next expected activity is to customs cargo in {0}.",
+
+		msgWarningOpen:      "Port {0} is open",
+		msgWarningCongested: "Port {0} is congested",
+		msgWarningClosed:    "Port {0} is closed",
+		msgWarningDelay:     ", expect delays of {0}",
+		msgWarningReason:    " ({0})",
+		msgWarningEnd:       ".",
+	},
+	"sv": {
+		msgEventNotHandled: "Godset har ännu inte tagits emot.",
+		msgEventReceived:   "Mottaget i {0}, {1}",
+		msgEventLoaded:     "Lastat på resa {0} i {1}, {2}.",
+		msgEventUnloaded:   "Lossat från resa {0} i {1}, {2}.",
+		msgEventClaimed:    "Utlämnat i {0}, {1}.",
+		msgEventCustoms:    "Tullklarerat i {0}, {1}.",
+		msgEventUnknown:    "[Okänd status]",
+
+		msgStatusNotReceived: "Ej mottaget",
+		msgStatusInPort:      "I hamn {0}",
+		msgStatusOnboard:     "Ombord på resa {0}",
+		msgStatusArrived:     "Ombord på resa {0}, anlänt till {1} (obekräftat)",
+		msgStatusClaimed:     "Utlämnat",
+		msgStatusRejected:    "Avvisat, returneras som {0}",
+		msgStatusUnknown:     "Okänd",
+
+		msgNextNone:    "Det finns för närvarande inga förväntade aktiviteter för denna sändning.",
+		msgNextReceive: "Nästa förväntade aktivitet är mottagning i {0}.",
+		msgNextLoad:    "Nästa förväntade aktivitet är lastning på resa {0} i {1}.",
+		msgNextUnload:  "Nästa förväntade aktivitet är lossning från resa {0} i {1}.",
+		msgNextClaim:   "Nästa förväntade aktivitet är utlämning i {0}.",
+		msgNextCustoms: "Nästa förväntade aktivitet är tullklarering i {0}.",
+
+		msgWarningOpen:      "Hamnen {0} är öppen",
+		msgWarningCongested: "Hamnen {0} är överbelastad",
+		msgWarningClosed:    "Hamnen {0} är stängd",
+		msgWarningDelay:     ", räkna med förseningar på {0}",
+		msgWarningReason:    " ({0})",
+		msgWarningEnd:       ".",
+	},
+}
+
+// Languages are the languages tracking views are translated to, the default
+// one first.
+var Languages = []string{"en", "sv"}
+
+// messages renders the messages of a language.
+type messages map[messageKey][]string
+
+// compiled holds the catalog split into literal text around the arguments,
+// so that rendering is plain concatenation.
+var compiled = compileCatalog()
+
+func compileCatalog() map[string]messages {
+	result := make(map[string]messages, len(catalog))
+	for lang, msgs := range catalog {
+		m := make(messages, len(msgs))
+		for k, s := range msgs {
+			m[k] = compile(s)
+		}
+		result[lang] = m
+	}
+	return result
+}
+
+// compile splits a message into literal text and argument references. Even
+// elements are literal text and odd elements are argument positions.
+func compile(s string) []string {
+	var parts []string
+	for {
+		i := strings.Index(s, "{")
+		j := strings.Index(s, "}")
+		if i < 0 || j < i {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:i], s[i+1:j])
+		s = s[j+1:]
+	}
+}
+
+// messagesFor returns the messages of a language, falling back to the
+// default language.
+func messagesFor(lang string) messages {
+	if m, ok := compiled[lang]; ok {
+		return m
+	}
+	return compiled[Languages[0]]
+}
+
+// render returns the message with its arguments filled in.
+func (m messages) render(k messageKey, args ...string) string {
+	parts := m[k]
+	if len(parts) == 1 {
+		return parts[0]
+	}
+
+	var b strings.Builder
+	for i, p := range parts {
+		if i%2 == 0 {
+			b.WriteString(p)
+			continue
+		}
+		if n := int(p[0] - '0'); len(p) == 1 && n >= 0 && n < len(args) {
+			b.WriteString(args[n])
+		}
+	}
+	return b.String()
+}
@@ -3,57 +3,268 @@
 package tracking
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"strings"
+	"math"
 	"time"
 
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+
 	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/locale"
 )
 
 // ErrInvalidArgument is returned when one or more arguments are invalid.
 var ErrInvalidArgument = errors.New("invalid argument")
 
+// UnknownCargoError is returned when tracking a cargo that is not known, if
+// there are known tracking IDs close to the one given, to ask e.g. "did you
+// mean ABC123?".
+type UnknownCargoError struct {
+	Suggestions []shipping.TrackingID
+}
+
+func (e *UnknownCargoError) Error() string {
+	return shipping.ErrUnknownCargo.Error()
+}
+
 // Service is the interface that provides the basic Track method.
 type Service interface {
-	// Track returns a cargo matching a tracking ID.
-	Track(id string) (Cargo, error)
+	// Track returns a cargo matching a tracking ID, along with the first page
+	// of its handling events. Tracking IDs are matched as given, or else
+	// normalized, as they are often pasted with whitespace or in the wrong
+	// case. If suggestions are enabled and the cargo is still not found,
+	// the closest known tracking IDs are returned in an UnknownCargoError.
+	Track(ctx context.Context, id string) (Cargo, error)
+
+	// Events returns a page of the handling events of a cargo, optionally
+	// restricted to some types of events.
+	Events(ctx context.Context, id string, q shipping.HandlingHistoryQuery) (EventPage, error)
 }
 
+const (
+	// defaultEventLimit is the number of events in a page unless a limit is
+	// given.
+	defaultEventLimit = 50
+
+	// maxEventLimit caps the number of events in a page.
+	maxEventLimit = 500
+)
+
 type service struct {
 	cargos         shipping.CargoRepository
 	handlingEvents shipping.HandlingEventRepository
+	portStatuses   shipping.PortStatusRepository
+	calendars      shipping.WorkingCalendarRepository
+
+	index       *Index
+	maxDistance int
+
+	now func() time.Time
+
+	// Decorators assembled around the service by NewService.
+	authorizer     Authorizer
+	logger         log.Logger
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+}
+
+// maxSuggestions caps the number of tracking IDs suggested for an unknown
+// tracking ID.
+const maxSuggestions = 3
+
+// Option configures a tracking service.
+type Option func(*service)
+
+// WithPortStatuses warns about port advisories affecting the itinerary of a
+// tracked cargo.
+func WithPortStatuses(r shipping.PortStatusRepository) Option {
+	return func(s *service) {
+		s.portStatuses = r
+	}
 }
 
-func (s *service) Track(id string) (Cargo, error) {
+// WithCalendars narrows the expected handling windows of tracked cargos to
+// the working hours of their locations.
+func WithCalendars(r shipping.WorkingCalendarRepository) Option {
+	return func(s *service) {
+		s.calendars = r
+	}
+}
+
+// WithSuggestions suggests the tracking IDs in the index within the given
+// edit distance of a tracking ID that is not known.
+func WithSuggestions(index *Index, maxDistance int) Option {
+	return func(s *service) {
+		s.index = index
+		s.maxDistance = maxDistance
+	}
+}
+
+func (s *service) Track(ctx context.Context, id string) (Cargo, error) {
 	if id == "" {
 		return Cargo{}, ErrInvalidArgument
 	}
-	c, err := s.cargos.Find(shipping.TrackingID(id))
+	c, err := s.find(id)
+	if err == shipping.ErrUnknownCargo && s.index != nil && s.maxDistance > 0 {
+		if ids := s.index.Suggest(shipping.NormalizeTrackingID(id), s.maxDistance, maxSuggestions); len(ids) > 0 {
+			return Cargo{}, &UnknownCargoError{Suggestions: ids}
+		}
+	}
 	if err != nil {
 		return Cargo{}, err
 	}
-	return assemble(c, s.handlingEvents), nil
+
+	page, err := s.handlingEvents.QueryHandlingHistoryPage(c.TrackingID, shipping.HandlingHistoryQuery{
+		Limit: defaultEventLimit,
+	})
+	if err != nil {
+		return Cargo{}, err
+	}
+
+	m := messagesFor(locale.FromContext(ctx))
+
+	result := assemble(c, shipping.HandlingHistory{HandlingEvents: page.HandlingEvents}, m, s.now())
+	result.EventsCursor = page.NextCursor
+
+	if a := result.NextActivity; a != nil && s.calendars != nil {
+		if cal, err := s.calendars.Find(shipping.UNLocode(a.Location)); err == nil {
+			a.narrow(cal)
+		}
+	}
+
+	if s.portStatuses != nil && !c.IsDelivered() {
+		p := shipping.NewPortStatuses(s.portStatuses.FindAll(), s.now())
+		result.Warnings = assembleWarnings(p.Affecting(c.Itinerary), m)
+	}
+
+	return result, nil
+}
+
+func (s *service) Events(ctx context.Context, id string, q shipping.HandlingHistoryQuery) (EventPage, error) {
+	if id == "" || q.Limit < 0 {
+		return EventPage{}, ErrInvalidArgument
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return EventPage{}, ErrInvalidArgument
+	}
+
+	if q.Limit == 0 {
+		q.Limit = defaultEventLimit
+	}
+	if q.Limit > maxEventLimit {
+		q.Limit = maxEventLimit
+	}
+
+	c, err := s.find(id)
+	if err != nil {
+		return EventPage{}, err
+	}
+
+	page, err := s.handlingEvents.QueryHandlingHistoryPage(c.TrackingID, q)
+	if err != nil {
+		return EventPage{}, err
+	}
+
+	return EventPage{
+		Events:     assembleEvents(c, shipping.HandlingHistory{HandlingEvents: page.HandlingEvents}, messagesFor(locale.FromContext(ctx))),
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+// find returns the cargo with the tracking ID as given, or else normalized.
+func (s *service) find(id string) (*shipping.Cargo, error) {
+	c, err := s.cargos.Find(shipping.TrackingID(id))
+	if err == shipping.ErrUnknownCargo {
+		if n := shipping.NormalizeTrackingID(id); string(n) != id && n != "" {
+			return s.cargos.Find(n)
+		}
+	}
+	return c, err
+}
+
+// Authorizer decides whether the caller may call a method of the tracking
+// service, named as in the logs and metrics, returning an error if not.
+type Authorizer func(ctx context.Context, method string) error
+
+// WithAuthorizer refuses the calls the authorizer returns an error for.
+func WithAuthorizer(a Authorizer) Option {
+	return func(s *service) {
+		s.authorizer = a
+	}
+}
+
+// WithLogger logs the calls to the service.
+func WithLogger(l log.Logger) Option {
+	return func(s *service) {
+		s.logger = l
+	}
+}
+
+// WithMetrics counts the calls to the service and records their latency.
+func WithMetrics(counter metrics.Counter, latency metrics.Histogram) Option {
+	return func(s *service) {
+		s.requestCount = counter
+		s.requestLatency = latency
+	}
+}
+
+// WithClock sets the clock the service tells the time by, rather than the
+// system clock.
+func WithClock(now func() time.Time) Option {
+	return func(s *service) {
+		s.now = now
+	}
 }
 
 // NewService returns a new instance of the default Service.
-func NewService(cargos shipping.CargoRepository, events shipping.HandlingEventRepository) Service {
-	return &service{
+// Calls are authorized, then logged, then counted, as configured by the
+// options, so that refused calls show in the logs and metrics.
+func NewService(cargos shipping.CargoRepository, events shipping.HandlingEventRepository, opts ...Option) Service {
+	s := &service{
 		cargos:         cargos,
 		handlingEvents: events,
+		now:            time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	var svc Service = s
+	if s.authorizer != nil {
+		svc = NewAuthorizingService(s.authorizer, svc)
 	}
+	if s.logger != nil {
+		svc = NewLoggingService(s.logger, svc)
+	}
+	if s.requestCount != nil {
+		svc = NewInstrumentingService(s.requestCount, s.requestLatency, svc)
+	}
+	return svc
 }
 
 // Cargo is a read model for tracking views.
 type Cargo struct {
-	TrackingID           string    `json:"tracking_id"`
-	StatusText           string    `json:"status_text"`
-	Origin               string    `json:"origin"`
-	Destination          string    `json:"destination"`
-	ETA                  time.Time `json:"eta"`
-	NextExpectedActivity string    `json:"next_expected_activity"`
-	ArrivalDeadline      time.Time `json:"arrival_deadline"`
-	Events               []Event   `json:"events"`
+	TrackingID           string     `json:"tracking_id"`
+	StatusText           string     `json:"status_text"`
+	Origin               string     `json:"origin"`
+	Destination          string     `json:"destination"`
+	ETA                  time.Time  `json:"eta"`
+	ProjectedArrival     *time.Time `json:"projected_arrival,omitempty"`
+	ProvisionalArrival   *time.Time `json:"provisional_arrival,omitempty"`
+	DeadlineAtRisk       bool       `json:"deadline_at_risk,omitempty"`
+	DeadlineSlip         string     `json:"deadline_slip,omitempty"`
+	NextExpectedActivity string     `json:"next_expected_activity"`
+	NextActivity         *Activity  `json:"next_activity,omitempty"`
+	ArrivalDeadline      time.Time  `json:"arrival_deadline"`
+	DeadlineType         string     `json:"deadline_type"`
+	Events               []Event    `json:"events"`
+	EventsCursor         string     `json:"events_cursor,omitempty"`
+	ReturnOf             string     `json:"return_of,omitempty"`
+	ReturnedBy           string     `json:"returned_by,omitempty"`
+	Warnings             []string   `json:"warnings,omitempty"`
+	Progress             *Progress  `json:"progress,omitempty"`
 }
 
 // Leg is a read model for booking views.
@@ -67,21 +278,186 @@ type Leg struct {
 
 // Event is a read model for tracking views.
 type Event struct {
-	Description string `json:"description"`
-	Expected    bool   `json:"expected"`
+	Description string    `json:"description"`
+	Type        string    `json:"type"`
+	Expected    bool      `json:"expected"`
+	Completed   time.Time `json:"completed"`
+}
+
+// PublicCargo is a read model for the public tracking page, which anyone
+// knowing a tracking ID may look a cargo up on. It is limited to what a
+// consignee needs to follow the cargo, leaving out deadlines, warnings and
+// the tracking IDs of other cargos.
+type PublicCargo struct {
+	TrackingID  string        `json:"tracking_id"`
+	StatusText  string        `json:"status_text"`
+	Origin      string        `json:"origin"`
+	Destination string        `json:"destination"`
+	ETA         time.Time     `json:"eta"`
+	Events      []PublicEvent `json:"events"`
+}
+
+// PublicEvent is a read model for the handling events on the public tracking
+// page.
+type PublicEvent struct {
+	Description string    `json:"description"`
+	Completed   time.Time `json:"completed"`
+}
+
+// Public returns the cargo as shown on the public tracking page.
+func (c Cargo) Public() PublicCargo {
+	events := make([]PublicEvent, len(c.Events))
+	for i, e := range c.Events {
+		events[i] = PublicEvent{Description: e.Description, Completed: e.Completed}
+	}
+	return PublicCargo{
+		TrackingID:  c.TrackingID,
+		StatusText:  c.StatusText,
+		Origin:      c.Origin,
+		Destination: c.Destination,
+		ETA:         c.ETA,
+		Events:      events,
+	}
+}
+
+// EventPage is a page of handling events for tracking views.
+type EventPage struct {
+	Events     []Event `json:"events"`
+	NextCursor string  `json:"next_cursor,omitempty"`
 }
 
-func assemble(c *shipping.Cargo, events shipping.HandlingEventRepository) Cargo {
-	return Cargo{
+func assemble(c *shipping.Cargo, history shipping.HandlingHistory, m messages, now time.Time) Cargo {
+	result := Cargo{
 		TrackingID:           string(c.TrackingID),
 		Origin:               string(c.Origin),
 		Destination:          string(c.RouteSpecification.Destination),
 		ETA:                  c.Delivery.ETA,
-		NextExpectedActivity: nextExpectedActivity(c),
+		NextExpectedActivity: nextExpectedActivity(c, m),
+		NextActivity:         assembleActivity(c.Delivery),
 		ArrivalDeadline:      c.RouteSpecification.ArrivalDeadline,
-		StatusText:           assembleStatusText(c),
-		Events:               assembleEvents(c, events),
+		DeadlineType:         c.RouteSpecification.DeadlineType.String(),
+		StatusText:           assembleStatusText(c, m),
+		Events:               assembleEvents(c, history, m),
+		ReturnOf:             string(c.ReturnOf),
+		ReturnedBy:           string(c.ReturnedBy),
+		Progress:             assembleProgress(c.Delivery, now),
 	}
+
+	if t := c.Delivery.ProjectedArrival(); !t.IsZero() {
+		result.ProjectedArrival = &t
+	}
+	if c.Delivery.IsProvisionallyArrived() {
+		t := c.Delivery.ProvisionalArrival
+		result.ProvisionalArrival = &t
+	}
+	if slip := c.Delivery.DeadlineSlip(); slip > 0 {
+		result.DeadlineAtRisk = true
+		result.DeadlineSlip = slip.String()
+	}
+
+	return result
+}
+
+// Activity is a read model for an expected handling activity. From and To
+// bound when it is expected to take place, and are left out when open.
+type Activity struct {
+	Type     string     `json:"type"`
+	Location string     `json:"location"`
+	Voyage   string     `json:"voyage_number,omitempty"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+}
+
+// assembleActivity returns a read model of the next expected activity of a
+// delivery, or nil if no activity is expected.
+func assembleActivity(d shipping.Delivery) *Activity {
+	a := d.NextExpectedActivity
+	if a.Type == shipping.NotHandled {
+		return nil
+	}
+
+	result := &Activity{
+		Type:     a.Type.String(),
+		Location: string(a.Location),
+		Voyage:   string(a.VoyageNumber),
+	}
+
+	from, to := d.Itinerary.ExpectedWindow(a)
+	if !from.IsZero() {
+		result.From = &from
+	}
+	if !to.IsZero() {
+		result.To = &to
+	}
+
+	return result
+}
+
+// narrow narrows the window of the activity to the working hours of its
+// location. A nil calendar leaves the window as is.
+func (a *Activity) narrow(cal *shipping.WorkingCalendar) {
+	if a == nil || cal == nil {
+		return
+	}
+
+	var from, to time.Time
+	if a.From != nil {
+		from = *a.From
+	}
+	if a.To != nil {
+		to = *a.To
+	}
+
+	from, to = cal.AdjustWindow(from, to)
+	if !from.IsZero() {
+		a.From = &from
+	}
+	if !to.IsZero() {
+		a.To = &to
+	}
+}
+
+// Progress is a read model for the progress of a cargo along its itinerary.
+type Progress struct {
+	Percent       float64     `json:"percent"`
+	CompletedLegs int         `json:"completed_legs"`
+	TotalLegs     int         `json:"total_legs"`
+	Milestones    []Milestone `json:"milestones"`
+}
+
+// Milestone is a read model for a planned departure or arrival.
+type Milestone struct {
+	Type     string    `json:"type"`
+	Location string    `json:"location"`
+	Voyage   string    `json:"voyage_number"`
+	Planned  time.Time `json:"planned"`
+	Reached  bool      `json:"reached"`
+}
+
+// assembleProgress returns a read model of the progress of a delivery, or
+// nil if the cargo has not been routed.
+func assembleProgress(d shipping.Delivery, now time.Time) *Progress {
+	if d.Itinerary.IsEmpty() {
+		return nil
+	}
+
+	p := d.Progress(now)
+
+	result := &Progress{
+		Percent:       math.Round(p.Percent*10) / 10,
+		CompletedLegs: p.CompletedLegs,
+		TotalLegs:     p.TotalLegs,
+	}
+	for _, m := range p.Milestones {
+		result.Milestones = append(result.Milestones, Milestone{
+			Type:     m.Activity.Type.String(),
+			Location: string(m.Activity.Location),
+			Voyage:   string(m.Activity.VoyageNumber),
+			Planned:  m.Planned,
+			Reached:  m.Reached,
+		})
+	}
+	return result
 }
 
 func assembleLegs(c shipping.Cargo) []Leg {
@@ -98,64 +474,109 @@ func assembleLegs(c shipping.Cargo) []Leg {
 	return legs
 }
 
-func nextExpectedActivity(c *shipping.Cargo) string {
+func nextExpectedActivity(c *shipping.Cargo, m messages) string {
 	a := c.Delivery.NextExpectedActivity
-	prefix := "Next expected activity is to"
 
 	switch a.Type {
+	case shipping.NotHandled:
+		return m.render(msgNextNone)
+	case shipping.Receive:
+		return m.render(msgNextReceive, string(a.Location))
 	case shipping.Load:
-		return fmt.Sprintf("%s %s cargo onto voyage %s in %s.", prefix, strings.ToLower(a.Type.String()), a.VoyageNumber, a.Location)
+		return m.render(msgNextLoad, string(a.VoyageNumber), string(a.Location))
 	case shipping.Unload:
-		return fmt.Sprintf("%s %s cargo off of voyage %s in %s.", prefix, strings.ToLower(a.Type.String()), a.VoyageNumber, a.Location)
-	case shipping.NotHandled:
-		return "There are currently no expected activities for this shipping."
+		return m.render(msgNextUnload, string(a.VoyageNumber), string(a.Location))
+	case shipping.Claim:
+		return m.render(msgNextClaim, string(a.Location))
+	case shipping.Customs:
+		return m.render(msgNextCustoms, string(a.Location))
 	}
 
-	return fmt.Sprintf("%s %s cargo in %s.", prefix, strings.ToLower(a.Type.String()), a.Location)
+	return m.render(msgNextNone)
 }
 
-func assembleStatusText(c *shipping.Cargo) string {
+func assembleStatusText(c *shipping.Cargo, m messages) string {
+	if c.IsRejected() {
+		return m.render(msgStatusRejected, string(c.ReturnedBy))
+	}
+
 	switch c.Delivery.TransportStatus {
 	case shipping.NotReceived:
-		return "Not received"
+		return m.render(msgStatusNotReceived)
 	case shipping.InPort:
-		return fmt.Sprintf("In port %s", c.Delivery.LastKnownLocation)
+		return m.render(msgStatusInPort, string(c.Delivery.LastKnownLocation))
 	case shipping.OnboardCarrier:
-		return fmt.Sprintf("Onboard voyage %s", c.Delivery.CurrentVoyage)
+		if l, ok := c.Delivery.CurrentLeg(); ok && c.Delivery.IsProvisionallyArrived() {
+			return m.render(msgStatusArrived, string(c.Delivery.CurrentVoyage), string(l.UnloadLocation))
+		}
+		return m.render(msgStatusOnboard, string(c.Delivery.CurrentVoyage))
 	case shipping.Claimed:
-		return "Claimed"
+		return m.render(msgStatusClaimed)
 	default:
-		return "Unknown"
+		return m.render(msgStatusUnknown)
 	}
 }
 
-func assembleEvents(c *shipping.Cargo, handlingEvents shipping.HandlingEventRepository) []Event {
-	h := handlingEvents.QueryHandlingHistory(c.TrackingID)
+func assembleWarnings(ss []shipping.PortStatus, m messages) []string {
+	var warnings []string
+	for _, s := range ss {
+		var w string
+		switch s.Condition {
+		case shipping.PortCongested:
+			w = m.render(msgWarningCongested, string(s.Location))
+		case shipping.PortClosed:
+			w = m.render(msgWarningClosed, string(s.Location))
+		default:
+			w = m.render(msgWarningOpen, string(s.Location))
+		}
+		if s.Delay > 0 {
+			w += m.render(msgWarningDelay, s.Delay.String())
+		}
+		if s.Reason != "" {
+			w += m.render(msgWarningReason, s.Reason)
+		}
+		warnings = append(warnings, w+m.render(msgWarningEnd))
+	}
+	return warnings
+}
+
+func assembleEvents(c *shipping.Cargo, h shipping.HandlingHistory, m messages) []Event {
+	if len(h.HandlingEvents) == 0 {
+		return nil
+	}
 
-	var events []Event
+	// Descriptions are rendered from precompiled messages rather than with
+	// fmt.Sprintf, since this runs for every event of every tracked cargo.
+	events := make([]Event, 0, len(h.HandlingEvents))
 	for _, e := range h.HandlingEvents {
-		var description string
+		var (
+			description string
+			at          = e.Completed.Format(time.RFC3339)
+			loc         = string(e.Activity.Location)
+		)
 
 		switch e.Activity.Type {
 		case shipping.NotHandled:
-			description = "Cargo has not yet been received."
+			description = m.render(msgEventNotHandled)
 		case shipping.Receive:
-			description = fmt.Sprintf("Received in %s, at %s", e.Activity.Location, time.Now().Format(time.RFC3339))
+			description = m.render(msgEventReceived, loc, at)
 		case shipping.Load:
-			description = fmt.Sprintf("Loaded onto voyage %s in %s, at %s.", e.Activity.VoyageNumber, e.Activity.Location, time.Now().Format(time.RFC3339))
+			description = m.render(msgEventLoaded, string(e.Activity.VoyageNumber), loc, at)
 		case shipping.Unload:
-			description = fmt.Sprintf("Unloaded off voyage %s in %s, at %s.", e.Activity.VoyageNumber, e.Activity.Location, time.Now().Format(time.RFC3339))
+			description = m.render(msgEventUnloaded, string(e.Activity.VoyageNumber), loc, at)
 		case shipping.Claim:
-			description = fmt.Sprintf("Claimed in %s, at %s.", e.Activity.Location, time.Now().Format(time.RFC3339))
+			description = m.render(msgEventClaimed, loc, at)
 		case shipping.Customs:
-			description = fmt.Sprintf("Cleared customs in %s, at %s.", e.Activity.Location, time.Now().Format(time.RFC3339))
+			description = m.render(msgEventCustoms, loc, at)
 		default:
-			description = "[Unknown status]"
+			description = m.render(msgEventUnknown)
 		}
 
 		events = append(events, Event{
 			Description: description,
+			Type:        e.Activity.Type.String(),
 			Expected:    c.Itinerary.IsExpected(e),
+			Completed:   e.Completed,
 		})
 	}
 
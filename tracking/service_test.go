@@ -1,9 +1,13 @@
 package tracking
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	shipping "github.com/marcusolsson/goddd"
+	"github.com/marcusolsson/goddd/builders"
+	"github.com/marcusolsson/goddd/locale"
 	"github.com/marcusolsson/goddd/mock"
 )
 
@@ -17,13 +21,16 @@ func TestTrack(t *testing.T) {
 	}
 
 	var events mock.HandlingEventRepository
-	events.QueryHandlingHistoryFn = func(id shipping.TrackingID) shipping.HandlingHistory {
-		return shipping.HandlingHistory{}
+	events.QueryHandlingHistoryPageFn = func(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+		if q.Limit != defaultEventLimit {
+			t.Errorf("q.Limit = %d; want = %d", q.Limit, defaultEventLimit)
+		}
+		return shipping.HandlingHistoryPage{NextCursor: "next"}, nil
 	}
 
 	s := NewService(&cargos, &events)
 
-	c, err := s.Track("FTL456")
+	c, err := s.Track(context.Background(), "FTL456")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -40,4 +47,372 @@ func TestTrack(t *testing.T) {
 	if c.StatusText != shipping.NotReceived.String() {
 		t.Errorf("c.StatusText = %v; want = %v", c.StatusText, shipping.NotReceived.String())
 	}
+	if c.EventsCursor != "next" {
+		t.Errorf("c.EventsCursor = %q; want = %q", c.EventsCursor, "next")
+	}
+	if c.Progress != nil {
+		t.Errorf("c.Progress = %+v; want = nil for an unrouted cargo", c.Progress)
+	}
+	if events.QueryHandlingHistoryInvoked {
+		t.Errorf("the complete handling history should not be queried")
+	}
+}
+
+func TestTrack_Suggestions(t *testing.T) {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		if id != "FTL456" {
+			return nil, shipping.ErrUnknownCargo
+		}
+		return shipping.NewCargo(id, shipping.RouteSpecification{}), nil
+	}
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryPageFn = func(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+		return shipping.HandlingHistoryPage{}, nil
+	}
+
+	index := NewIndex()
+	for _, id := range []shipping.TrackingID{"ABC123", "FTL456", "FTL457"} {
+		index.Add(id)
+	}
+
+	s := NewService(&cargos, &events, WithSuggestions(index, 1))
+
+	// Tracking IDs are normalized before suggesting others.
+	if c, err := s.Track(context.Background(), " ftl-456\n"); err != nil || c.TrackingID != "FTL456" {
+		t.Errorf("c.TrackingID, err = %q, %v; want = %q, nil", c.TrackingID, err, "FTL456")
+	}
+
+	_, err := s.Track(context.Background(), "ftl465")
+	e, ok := err.(*UnknownCargoError)
+	if !ok {
+		t.Fatalf("err = %v; want suggestions", err)
+	}
+	if len(e.Suggestions) != 1 || e.Suggestions[0] != "FTL456" {
+		t.Errorf("e.Suggestions = %v; want = [FTL456]", e.Suggestions)
+	}
+
+	if _, err := s.Track(context.Background(), "XYZ789"); err != shipping.ErrUnknownCargo {
+		t.Errorf("err = %v; want = %v", err, shipping.ErrUnknownCargo)
+	}
+}
+
+func TestTrack_Rejected(t *testing.T) {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		c := shipping.NewCargo("FTL456", shipping.RouteSpecification{
+			Origin:      shipping.AUMEL,
+			Destination: shipping.SESTO,
+		})
+		c.ReturnedBy = "RET789"
+		return c, nil
+	}
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryPageFn = func(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+		return shipping.HandlingHistoryPage{}, nil
+	}
+
+	s := NewService(&cargos, &events)
+
+	c, err := s.Track(context.Background(), "FTL456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.ReturnedBy != "RET789" {
+		t.Errorf("c.ReturnedBy = %q; want = %q", c.ReturnedBy, "RET789")
+	}
+	if want := "Rejected, returned as RET789"; c.StatusText != want {
+		t.Errorf("c.StatusText = %q; want = %q", c.StatusText, want)
+	}
+}
+
+func TestTrack_NextActivity(t *testing.T) {
+	loadTime := time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		c := shipping.NewCargo("FTL456", shipping.RouteSpecification{
+			Origin:      shipping.AUMEL,
+			Destination: shipping.SESTO,
+		})
+		c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+			shipping.NewLeg("V100", shipping.AUMEL, shipping.SESTO, loadTime, loadTime.Add(240*time.Hour)),
+		}})
+		return c, nil
+	}
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryPageFn = func(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+		return shipping.HandlingHistoryPage{}, nil
+	}
+
+	s := NewService(&cargos, &events)
+
+	c, err := s.Track(context.Background(), "FTL456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := c.NextActivity
+	if a == nil {
+		t.Fatal("c.NextActivity = nil")
+	}
+	if a.Type != "Receive" || a.Location != "AUMEL" {
+		t.Errorf("c.NextActivity = %+v; want Receive in AUMEL", a)
+	}
+	if a.From != nil {
+		t.Errorf("a.From = %v; want = nil", a.From)
+	}
+	if a.To == nil || !a.To.Equal(loadTime) {
+		t.Errorf("a.To = %v; want = %v", a.To, loadTime)
+	}
+}
+
+func TestTrack_ProvisionalArrival(t *testing.T) {
+	var (
+		loadTime = time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+		entered  = loadTime.Add(239 * time.Hour)
+	)
+
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		c := shipping.NewCargo("FTL456", shipping.RouteSpecification{
+			Origin:      shipping.AUMEL,
+			Destination: shipping.SESTO,
+		})
+		c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+			shipping.NewLeg("V100", shipping.AUMEL, shipping.SESTO, loadTime, loadTime.Add(240*time.Hour)),
+		}})
+		c.DeriveDeliveryProgress(shipping.HandlingHistory{HandlingEvents: []shipping.HandlingEvent{
+			{Activity: shipping.HandlingActivity{Type: shipping.Load, Location: shipping.AUMEL, VoyageNumber: "V100"}, Completed: loadTime},
+		}})
+		c.Delivery = c.Delivery.UpdateOnGeofence(entered)
+		return c, nil
+	}
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryPageFn = func(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+		return shipping.HandlingHistoryPage{}, nil
+	}
+
+	s := NewService(&cargos, &events)
+
+	c, err := s.Track(context.Background(), "FTL456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Onboard voyage V100, arrived in SESTO (unconfirmed)"; c.StatusText != want {
+		t.Errorf("c.StatusText = %q; want = %q", c.StatusText, want)
+	}
+	if c.ProvisionalArrival == nil || !c.ProvisionalArrival.Equal(entered) {
+		t.Errorf("c.ProvisionalArrival = %v; want = %v", c.ProvisionalArrival, entered)
+	}
+}
+
+func TestTrack_PortStatuses(t *testing.T) {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		c := shipping.NewCargo("FTL456", shipping.RouteSpecification{
+			Origin:      shipping.AUMEL,
+			Destination: shipping.SESTO,
+		})
+		c.AssignToRoute(shipping.Itinerary{Legs: []shipping.Leg{
+			{LoadLocation: shipping.AUMEL, UnloadLocation: shipping.CNHKG},
+			{LoadLocation: shipping.CNHKG, UnloadLocation: shipping.SESTO},
+		}})
+		return c, nil
+	}
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryPageFn = func(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+		return shipping.HandlingHistoryPage{}, nil
+	}
+
+	var statuses mock.PortStatusRepository
+	statuses.FindAllFn = func() []shipping.PortStatus {
+		return []shipping.PortStatus{
+			{Location: shipping.CNHKG, Condition: shipping.PortCongested, Delay: 36 * time.Hour, Reason: "typhoon"},
+			{Location: shipping.USNYC, Condition: shipping.PortClosed},
+		}
+	}
+
+	s := NewService(&cargos, &events, WithPortStatuses(&statuses))
+
+	c, err := s.Track(context.Background(), "FTL456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Port CNHKG is congested, expect delays of 36h0m0s (typhoon)."
+	if len(c.Warnings) != 1 || c.Warnings[0] != want {
+		t.Errorf("c.Warnings = %q; want = %q", c.Warnings, []string{want})
+	}
+}
+
+func TestEvents(t *testing.T) {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return shipping.NewCargo(id, shipping.RouteSpecification{
+			Origin:      shipping.AUMEL,
+			Destination: shipping.SESTO,
+		}), nil
+	}
+
+	completed := time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryPageFn = func(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+		if q.Limit != maxEventLimit {
+			t.Errorf("q.Limit = %d; want = %d", q.Limit, maxEventLimit)
+		}
+		if q.Cursor != "abc" {
+			t.Errorf("q.Cursor = %q; want = %q", q.Cursor, "abc")
+		}
+		return shipping.HandlingHistoryPage{
+			HandlingEvents: []shipping.HandlingEvent{
+				{TrackingID: id, Activity: shipping.HandlingActivity{Type: shipping.Receive, Location: shipping.AUMEL}, Completed: completed},
+			},
+			NextCursor: "def",
+		}, nil
+	}
+
+	s := NewService(&cargos, &events)
+
+	p, err := s.Events(context.Background(), "FTL456", shipping.HandlingHistoryQuery{Cursor: "abc", Limit: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p.Events) != 1 {
+		t.Fatalf("len(p.Events) = %d; want = %d", len(p.Events), 1)
+	}
+	if !p.Events[0].Completed.Equal(completed) {
+		t.Errorf("p.Events[0].Completed = %v; want = %v", p.Events[0].Completed, completed)
+	}
+	if want := "Received in AUMEL, at 2016-03-01T12:00:00Z"; p.Events[0].Description != want {
+		t.Errorf("p.Events[0].Description = %q; want = %q", p.Events[0].Description, want)
+	}
+	if p.NextCursor != "def" {
+		t.Errorf("p.NextCursor = %q; want = %q", p.NextCursor, "def")
+	}
+}
+
+func TestEvents_Localized(t *testing.T) {
+	var cargos mock.CargoRepository
+	cargos.FindFn = func(id shipping.TrackingID) (*shipping.Cargo, error) {
+		return shipping.NewCargo(id, shipping.RouteSpecification{
+			Origin:      shipping.AUMEL,
+			Destination: shipping.SESTO,
+		}), nil
+	}
+
+	completed := time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	var events mock.HandlingEventRepository
+	events.QueryHandlingHistoryPageFn = func(id shipping.TrackingID, q shipping.HandlingHistoryQuery) (shipping.HandlingHistoryPage, error) {
+		if len(q.Types) != 1 || q.Types[0] != shipping.Receive {
+			t.Errorf("q.Types = %v; want = %v", q.Types, []shipping.HandlingEventType{shipping.Receive})
+		}
+		return shipping.HandlingHistoryPage{
+			HandlingEvents: []shipping.HandlingEvent{
+				{TrackingID: id, Activity: shipping.HandlingActivity{Type: shipping.Receive, Location: shipping.AUMEL}, Completed: completed},
+			},
+		}, nil
+	}
+
+	s := NewService(&cargos, &events)
+
+	ctx := locale.NewContext(context.Background(), "sv")
+
+	p, err := s.Events(ctx, "FTL456", shipping.HandlingHistoryQuery{Types: []shipping.HandlingEventType{shipping.Receive}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p.Events) != 1 {
+		t.Fatalf("len(p.Events) = %d; want = %d", len(p.Events), 1)
+	}
+	if want := "Mottaget i AUMEL, 2016-03-01T12:00:00Z"; p.Events[0].Description != want {
+		t.Errorf("p.Events[0].Description = %q; want = %q", p.Events[0].Description, want)
+	}
+	if want := shipping.Receive.String(); p.Events[0].Type != want {
+		t.Errorf("p.Events[0].Type = %q; want = %q", p.Events[0].Type, want)
+	}
+}
+
+func TestEvents_InvalidRange(t *testing.T) {
+	s := NewService(nil, nil)
+
+	from := time.Date(2016, time.March, 2, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-time.Hour)
+
+	if _, err := s.Events(context.Background(), "FTL456", shipping.HandlingHistoryQuery{From: from, To: to}); err != ErrInvalidArgument {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidArgument)
+	}
+}
+
+// TestAllocations guards the allocations of assembling views, which the
+// benchmarks below measure, so that regressions fail the build rather than
+// going unnoticed in benchmark output.
+func TestAllocations(t *testing.T) {
+	c, h := benchmarkCargo()
+	m := messagesFor("en")
+
+	for _, tt := range []struct {
+		name string
+		fn   func()
+		max  float64
+	}{
+		{"assemble", func() { assemble(c, h, m, time.Now()) }, 37},
+		{"assembleEvents", func() { assembleEvents(c, h, m) }, 21},
+		{"nextExpectedActivity", func() { nextExpectedActivity(c, m) }, 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if n := testing.AllocsPerRun(100, tt.fn); n > tt.max {
+				t.Errorf("allocs = %v; want at most %v", n, tt.max)
+			}
+		})
+	}
+}
+
+func benchmarkCargo() (*shipping.Cargo, shipping.HandlingHistory) {
+	return builders.Cargo("ABC123").
+		Route(builders.Itinerary().
+			Leg("V100", shipping.CNHKG, shipping.JNTKO).
+			Leg("V300", shipping.JNTKO, shipping.DEHAM).
+			Leg("V400", shipping.DEHAM, shipping.SESTO).
+			Build()).
+		Unloaded(1).
+		Build()
+}
+
+func BenchmarkAssemble(b *testing.B) {
+	c, h := benchmarkCargo()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		assemble(c, h, messagesFor("en"), time.Now())
+	}
+}
+
+func BenchmarkAssembleEvents(b *testing.B) {
+	c, h := benchmarkCargo()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		assembleEvents(c, h, messagesFor("en"))
+	}
+}
+
+func BenchmarkNextExpectedActivity(b *testing.B) {
+	c, _ := benchmarkCargo()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		nextExpectedActivity(c, messagesFor("en"))
+	}
 }
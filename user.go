@@ -0,0 +1,72 @@
+package shipping
+
+import (
+	"errors"
+)
+
+// UserID uniquely identifies a user of the back office.
+type UserID string
+
+// Role grants a user permission to perform restricted operations.
+type Role string
+
+// Valid roles.
+const (
+	// ApproverRole permits approving and rejecting booking amendments.
+	ApproverRole Role = "approver"
+
+	// DataProtectionRole permits exporting and anonymizing the personal
+	// data of customers.
+	DataProtectionRole Role = "data_protection"
+)
+
+// User is a member of staff operating the back office, such as a booking
+// clerk or a supervisor.
+type User struct {
+	ID    UserID
+	Name  string
+	Roles []Role
+
+	// KeyHash is the hash of the key the user authenticates with. The key
+	// itself is never stored.
+	KeyHash string
+}
+
+// HashUserKey returns the hash of a user key to be stored.
+func HashUserKey(key string) string {
+	return hashKey(key)
+}
+
+// Authenticate returns whether the key is the one of the user.
+func (u User) Authenticate(key string) bool {
+	return matchKey(key, u.KeyHash)
+}
+
+// HasRole returns whether the user has been granted a role.
+func (u User) HasRole(r Role) bool {
+	for _, role := range u.Roles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// ErrUnknownUser is used when a user could not be found.
+	ErrUnknownUser = errors.New("unknown user")
+
+	// ErrUnauthorizedUser is used when a user fails to authenticate.
+	ErrUnauthorizedUser = errors.New("unauthorized user")
+
+	// ErrRoleRequired is used when a user lacks the role required for an
+	// operation.
+	ErrRoleRequired = errors.New("user lacks the required role")
+)
+
+// UserRepository provides access a user store.
+type UserRepository interface {
+	Store(u *User) error
+	Find(id UserID) (*User, error)
+	FindAll() []*User
+}
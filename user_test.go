@@ -0,0 +1,28 @@
+package shipping
+
+import "testing"
+
+func TestUserAuthenticate(t *testing.T) {
+	u := User{ID: "jdoe", KeyHash: HashUserKey("secret")}
+
+	if !u.Authenticate("secret") {
+		t.Errorf("u.Authenticate(%q) = false; want = true", "secret")
+	}
+	if u.Authenticate("wrong") {
+		t.Errorf("u.Authenticate(%q) = true; want = false", "wrong")
+	}
+}
+
+func TestUserHasRole(t *testing.T) {
+	u := User{ID: "jdoe"}
+
+	if u.HasRole(ApproverRole) {
+		t.Errorf("u.HasRole(%s) = true; want = false", ApproverRole)
+	}
+
+	u.Roles = []Role{ApproverRole}
+
+	if !u.HasRole(ApproverRole) {
+		t.Errorf("u.HasRole(%s) = false; want = true", ApproverRole)
+	}
+}
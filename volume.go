@@ -0,0 +1,24 @@
+package shipping
+
+import "time"
+
+// RepositoryVolume is how much data a repository holds.
+type RepositoryVolume struct {
+	Repository string
+
+	// Records is the number of records stored, e.g. rows or documents.
+	Records int
+
+	// Bytes is the storage the records take up, or zero if not known.
+	Bytes int64
+
+	// Oldest is when the oldest record still held was stored, or zero if
+	// not known.
+	Oldest time.Time
+}
+
+// VolumeReporter reports how much data the repositories of a storage backend
+// hold, for capacity planning.
+type VolumeReporter interface {
+	Volumes() ([]RepositoryVolume, error)
+}
@@ -12,6 +12,37 @@ type VoyageNumber string
 type Voyage struct {
 	VoyageNumber VoyageNumber
 	Schedule     Schedule
+	Mode         TransportMode
+
+	// Capacity holds the units of each equipment type the voyage can carry.
+	// Equipment types not listed cannot be carried, unless no capacity is
+	// declared at all.
+	Capacity []Capacity
+}
+
+// TransportMode is the kind of carrier operating a voyage.
+type TransportMode int
+
+// Valid transport modes.
+const (
+	Sea TransportMode = iota
+	Rail
+	Road
+	Air
+)
+
+func (m TransportMode) String() string {
+	switch m {
+	case Sea:
+		return "Sea"
+	case Rail:
+		return "Rail"
+	case Road:
+		return "Road"
+	case Air:
+		return "Air"
+	}
+	return ""
 }
 
 // NewVoyage creates a voyage with a voyage number and a provided schedule.
@@ -30,6 +61,194 @@ type CarrierMovement struct {
 	ArrivalLocation   UNLocode
 	DepartureTime     time.Time
 	ArrivalTime       time.Time
+
+	// ActualDepartureTime and ActualArrivalTime are when the carrier
+	// actually departed and arrived, as opposed to the schedule. They are
+	// zero until recorded.
+	ActualDepartureTime time.Time
+	ActualArrivalTime   time.Time
+}
+
+// CallsAt returns whether the schedule includes a port call at a location.
+func (s Schedule) CallsAt(locode UNLocode) bool {
+	for _, m := range s.CarrierMovements {
+		if m.DepartureLocation == locode || m.ArrivalLocation == locode {
+			return true
+		}
+	}
+	return false
+}
+
+// PortCalls returns the rotation of the schedule, i.e. the ports in the order
+// they are visited.
+func (s Schedule) PortCalls() []PortCall {
+	if len(s.CarrierMovements) == 0 {
+		return nil
+	}
+
+	calls := make([]PortCall, 0, len(s.CarrierMovements)+1)
+	calls = append(calls, PortCall{
+		Location:            s.CarrierMovements[0].DepartureLocation,
+		DepartureTime:       s.CarrierMovements[0].DepartureTime,
+		ActualDepartureTime: s.CarrierMovements[0].ActualDepartureTime,
+	})
+
+	for i, m := range s.CarrierMovements {
+		c := PortCall{
+			Location:          m.ArrivalLocation,
+			ArrivalTime:       m.ArrivalTime,
+			ActualArrivalTime: m.ActualArrivalTime,
+		}
+		if i+1 < len(s.CarrierMovements) {
+			c.DepartureTime = s.CarrierMovements[i+1].DepartureTime
+			c.ActualDepartureTime = s.CarrierMovements[i+1].ActualDepartureTime
+		}
+		calls = append(calls, c)
+	}
+
+	return calls
+}
+
+// ErrPortNotCalled is used when a voyage does not call at a location.
+var ErrPortNotCalled = errors.New("voyage does not call at location")
+
+// OmitPortCall returns the schedule without the port calls at a location, as
+// when the carrier skips the port. The movements to and from the port are
+// merged into one, while skipping the first or last port shortens the voyage.
+func (s Schedule) OmitPortCall(locode UNLocode) (Schedule, error) {
+	if !s.CallsAt(locode) {
+		return s, ErrPortNotCalled
+	}
+
+	var movements []CarrierMovement
+	for _, m := range s.CarrierMovements {
+		if m.DepartureLocation != locode {
+			movements = append(movements, m)
+			continue
+		}
+		if n := len(movements); n > 0 {
+			movements[n-1].ArrivalLocation = m.ArrivalLocation
+			movements[n-1].ArrivalTime = m.ArrivalTime
+			movements[n-1].ActualArrivalTime = m.ActualArrivalTime
+		}
+	}
+
+	for n := len(movements); n > 0 && movements[n-1].ArrivalLocation == locode; n-- {
+		movements = movements[:n-1]
+	}
+
+	return Schedule{CarrierMovements: movements}, nil
+}
+
+// RecordActuals returns the schedule with when the voyage actually arrived
+// at and departed from a location. Zero times are left unrecorded, as are an
+// arrival at the first port and a departure from the last. For voyages
+// calling at a location more than once, the port call scheduled closest to
+// the actual times is updated.
+func (s Schedule) RecordActuals(locode UNLocode, arrived, departed time.Time) (Schedule, error) {
+	if !s.CallsAt(locode) {
+		return s, ErrPortNotCalled
+	}
+
+	at := arrived
+	if at.IsZero() {
+		at = departed
+	}
+
+	var (
+		calls   = s.PortCalls()
+		closest = -1
+	)
+	for i, c := range calls {
+		if c.Location != locode {
+			continue
+		}
+		if closest < 0 || timeBetween(c.scheduled(), at) < timeBetween(calls[closest].scheduled(), at) {
+			closest = i
+		}
+	}
+
+	movements := make([]CarrierMovement, len(s.CarrierMovements))
+	copy(movements, s.CarrierMovements)
+
+	// Port call i is the arrival of movement i-1 and the departure of
+	// movement i.
+	if closest > 0 && !arrived.IsZero() {
+		movements[closest-1].ActualArrivalTime = arrived
+	}
+	if closest < len(movements) && !departed.IsZero() {
+		movements[closest].ActualDepartureTime = departed
+	}
+
+	return Schedule{CarrierMovements: movements}, nil
+}
+
+// Delay returns how much later than scheduled the voyage was at the most
+// recently recorded arrival or departure, negative if early, or zero if
+// nothing has been recorded.
+func (s Schedule) Delay() time.Duration {
+	var delay time.Duration
+	for _, m := range s.CarrierMovements {
+		if !m.ActualDepartureTime.IsZero() {
+			delay = m.ActualDepartureTime.Sub(m.DepartureTime)
+		}
+		if !m.ActualArrivalTime.IsZero() {
+			delay = m.ActualArrivalTime.Sub(m.ArrivalTime)
+		}
+	}
+	return delay
+}
+
+// IsCompleted returns whether the voyage has arrived at the last port of its
+// rotation.
+func (s Schedule) IsCompleted() bool {
+	n := len(s.CarrierMovements)
+	return n > 0 && !s.CarrierMovements[n-1].ActualArrivalTime.IsZero()
+}
+
+// PortCall is a visit of a voyage to a location. The first port call has no
+// arrival time, and the last has no departure time. Actual times are zero
+// until recorded.
+type PortCall struct {
+	Location            UNLocode
+	ArrivalTime         time.Time
+	DepartureTime       time.Time
+	ActualArrivalTime   time.Time
+	ActualDepartureTime time.Time
+}
+
+// ArrivalDelay returns how much later than scheduled the voyage arrived,
+// negative if early, or zero if the arrival has not been recorded.
+func (c PortCall) ArrivalDelay() time.Duration {
+	if c.ActualArrivalTime.IsZero() || c.ArrivalTime.IsZero() {
+		return 0
+	}
+	return c.ActualArrivalTime.Sub(c.ArrivalTime)
+}
+
+// DepartureDelay returns how much later than scheduled the voyage departed,
+// negative if early, or zero if the departure has not been recorded.
+func (c PortCall) DepartureDelay() time.Duration {
+	if c.ActualDepartureTime.IsZero() || c.DepartureTime.IsZero() {
+		return 0
+	}
+	return c.ActualDepartureTime.Sub(c.DepartureTime)
+}
+
+// scheduled returns the scheduled arrival at the port call, or the
+// scheduled departure from the first.
+func (c PortCall) scheduled() time.Time {
+	if c.ArrivalTime.IsZero() {
+		return c.DepartureTime
+	}
+	return c.ArrivalTime
+}
+
+func timeBetween(a, b time.Time) time.Duration {
+	if d := a.Sub(b); d > 0 {
+		return d
+	}
+	return b.Sub(a)
 }
 
 // ErrUnknownVoyage is used when a voyage could not be found.
@@ -37,5 +256,10 @@ var ErrUnknownVoyage = errors.New("unknown voyage")
 
 // VoyageRepository provides access a voyage store.
 type VoyageRepository interface {
+	Store(*Voyage) error
 	Find(VoyageNumber) (*Voyage, error)
+	FindAll() []*Voyage
+
+	// FindCallingAt returns the voyages with a port call at a location.
+	FindCallingAt(UNLocode) []*Voyage
 }
@@ -0,0 +1,138 @@
+package shipping
+
+import "sort"
+
+// DiscrepancyType describes how the cargos handled on a voyage differ from
+// the cargos booked on it.
+type DiscrepancyType int
+
+// Valid discrepancy types.
+const (
+	// CargoNotHandled is a cargo booked on the voyage that was neither loaded
+	// onto nor unloaded from it.
+	CargoNotHandled DiscrepancyType = iota
+
+	// CargoNotBooked is a cargo handled on the voyage without a leg on it.
+	CargoNotBooked
+
+	// CargoNotUnloaded is a cargo loaded onto the voyage that was never
+	// unloaded from it.
+	CargoNotUnloaded
+
+	// CargoNotLoaded is a cargo unloaded from the voyage that was never loaded
+	// onto it.
+	CargoNotLoaded
+)
+
+func (t DiscrepancyType) String() string {
+	switch t {
+	case CargoNotHandled:
+		return "Not handled"
+	case CargoNotBooked:
+		return "Not booked"
+	case CargoNotUnloaded:
+		return "Not unloaded"
+	case CargoNotLoaded:
+		return "Not loaded"
+	}
+	return ""
+}
+
+// VoyageDiscrepancy is a cargo handled on a voyage other than as booked.
+type VoyageDiscrepancy struct {
+	TrackingID TrackingID
+	Type       DiscrepancyType
+
+	// Location is where the cargo was loaded or unloaded, for cargos not
+	// unloaded or not loaded, or else where the leg of the cargo on the
+	// voyage loads it.
+	Location UNLocode
+}
+
+// VoyageReconciliation compares the cargos booked on a voyage to the cargos
+// loaded onto and unloaded from it.
+type VoyageReconciliation struct {
+	VoyageNumber VoyageNumber
+
+	// Booked and Handled are the number of cargos with a leg on the voyage
+	// and with events on it.
+	Booked  int
+	Handled int
+
+	Discrepancies []VoyageDiscrepancy
+}
+
+// IsReconciled returns whether every cargo was handled on the voyage as
+// booked.
+func (r VoyageReconciliation) IsReconciled() bool {
+	return len(r.Discrepancies) == 0
+}
+
+// ReconcileVoyage compares the legs of the cargos on a voyage to their
+// handling histories. Discrepancies are ordered by tracking ID.
+func ReconcileVoyage(voyage VoyageNumber, cargos []*Cargo, histories map[TrackingID]HandlingHistory) VoyageReconciliation {
+	r := VoyageReconciliation{VoyageNumber: voyage}
+
+	for _, c := range cargos {
+		var (
+			booked  bool
+			from    UNLocode
+			loads   []UNLocode
+			unloads []UNLocode
+		)
+		for _, l := range c.Itinerary.Legs {
+			if l.VoyageNumber == voyage {
+				booked = true
+				from = l.LoadLocation
+				break
+			}
+		}
+		for _, e := range histories[c.TrackingID].HandlingEvents {
+			if e.Activity.VoyageNumber != voyage {
+				continue
+			}
+			switch e.Activity.Type {
+			case Load:
+				loads = append(loads, e.Activity.Location)
+			case Unload:
+				unloads = append(unloads, e.Activity.Location)
+			}
+		}
+
+		handled := len(loads) > 0 || len(unloads) > 0
+		if booked {
+			r.Booked++
+		}
+		if handled {
+			r.Handled++
+		}
+
+		add := func(t DiscrepancyType, loc UNLocode) {
+			r.Discrepancies = append(r.Discrepancies, VoyageDiscrepancy{TrackingID: c.TrackingID, Type: t, Location: loc})
+		}
+
+		switch {
+		case booked && !handled:
+			add(CargoNotHandled, from)
+			continue
+		case !booked && handled:
+			if len(loads) > 0 {
+				add(CargoNotBooked, loads[0])
+			} else {
+				add(CargoNotBooked, unloads[0])
+			}
+		}
+
+		if n := len(loads) - len(unloads); n > 0 {
+			add(CargoNotUnloaded, loads[len(loads)-1])
+		} else if n < 0 {
+			add(CargoNotLoaded, unloads[len(unloads)-1])
+		}
+	}
+
+	sort.SliceStable(r.Discrepancies, func(i, j int) bool {
+		return r.Discrepancies[i].TrackingID < r.Discrepancies[j].TrackingID
+	})
+
+	return r
+}
@@ -0,0 +1,72 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconcileVoyage(t *testing.T) {
+	leg := NewLeg("V100", CNHKG, SESTO, time.Time{}, time.Time{})
+
+	var (
+		load   = HandlingEvent{Activity: HandlingActivity{Type: Load, Location: CNHKG, VoyageNumber: "V100"}}
+		unload = HandlingEvent{Activity: HandlingActivity{Type: Unload, Location: SESTO, VoyageNumber: "V100"}}
+		other  = HandlingEvent{Activity: HandlingActivity{Type: Load, Location: CNHKG, VoyageNumber: "V200"}}
+	)
+
+	cargos := []*Cargo{
+		{TrackingID: "E", Itinerary: Itinerary{Legs: []Leg{leg}}},
+		{TrackingID: "A", Itinerary: Itinerary{Legs: []Leg{leg}}},
+		{TrackingID: "B", Itinerary: Itinerary{Legs: []Leg{leg}}},
+		{TrackingID: "C", Itinerary: Itinerary{Legs: []Leg{leg}}},
+		{TrackingID: "D"},
+		{TrackingID: "F"},
+	}
+	histories := map[TrackingID]HandlingHistory{
+		"A": {HandlingEvents: []HandlingEvent{load, unload}},
+		"B": {HandlingEvents: []HandlingEvent{load}},
+		"C": {HandlingEvents: []HandlingEvent{unload}},
+		"D": {HandlingEvents: []HandlingEvent{load, unload}},
+		"E": {HandlingEvents: []HandlingEvent{other}},
+		"F": {HandlingEvents: []HandlingEvent{other}},
+	}
+
+	r := ReconcileVoyage("V100", cargos, histories)
+
+	if r.Booked != 4 || r.Handled != 4 {
+		t.Errorf("Booked, Handled = %d, %d; want = 4, 4", r.Booked, r.Handled)
+	}
+	if r.IsReconciled() {
+		t.Errorf("voyage should not be reconciled")
+	}
+
+	want := []VoyageDiscrepancy{
+		{TrackingID: "B", Type: CargoNotUnloaded, Location: CNHKG},
+		{TrackingID: "C", Type: CargoNotLoaded, Location: SESTO},
+		{TrackingID: "D", Type: CargoNotBooked, Location: CNHKG},
+		{TrackingID: "E", Type: CargoNotHandled, Location: CNHKG},
+	}
+	if len(r.Discrepancies) != len(want) {
+		t.Fatalf("Discrepancies = %+v; want = %+v", r.Discrepancies, want)
+	}
+	for i, d := range r.Discrepancies {
+		if d != want[i] {
+			t.Errorf("Discrepancies[%d] = %+v; want = %+v", i, d, want[i])
+		}
+	}
+}
+
+func TestReconcileVoyage_Reconciled(t *testing.T) {
+	c := &Cargo{TrackingID: "A", Itinerary: Itinerary{Legs: []Leg{NewLeg("V100", CNHKG, SESTO, time.Time{}, time.Time{})}}}
+
+	r := ReconcileVoyage("V100", []*Cargo{c}, map[TrackingID]HandlingHistory{
+		"A": {HandlingEvents: []HandlingEvent{
+			{Activity: HandlingActivity{Type: Load, Location: CNHKG, VoyageNumber: "V100"}},
+			{Activity: HandlingActivity{Type: Unload, Location: SESTO, VoyageNumber: "V100"}},
+		}},
+	})
+
+	if !r.IsReconciled() {
+		t.Errorf("Discrepancies = %+v; want none", r.Discrepancies)
+	}
+}
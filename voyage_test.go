@@ -0,0 +1,173 @@
+package shipping
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSchedule_PortCalls(t *testing.T) {
+	var (
+		t1 = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		t2 = t1.Add(24 * time.Hour)
+		t3 = t2.Add(12 * time.Hour)
+		t4 = t3.Add(24 * time.Hour)
+	)
+
+	s := Schedule{CarrierMovements: []CarrierMovement{
+		{DepartureLocation: DEHAM, ArrivalLocation: SESTO, DepartureTime: t1, ArrivalTime: t2},
+		{DepartureLocation: SESTO, ArrivalLocation: FIHEL, DepartureTime: t3, ArrivalTime: t4},
+	}}
+
+	want := []PortCall{
+		{Location: DEHAM, DepartureTime: t1},
+		{Location: SESTO, ArrivalTime: t2, DepartureTime: t3},
+		{Location: FIHEL, ArrivalTime: t4},
+	}
+
+	if got := s.PortCalls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("PortCalls() = %v; want = %v", got, want)
+	}
+
+	if !s.CallsAt(SESTO) {
+		t.Errorf("CallsAt(%s) = false; want = true", SESTO)
+	}
+	if s.CallsAt(CNHKG) {
+		t.Errorf("CallsAt(%s) = true; want = false", CNHKG)
+	}
+}
+
+func TestSchedule_PortCalls_Empty(t *testing.T) {
+	if got := (Schedule{}).PortCalls(); got != nil {
+		t.Errorf("PortCalls() = %v; want = nil", got)
+	}
+}
+
+func TestSchedule_OmitPortCall(t *testing.T) {
+	var (
+		t1 = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		t2 = t1.Add(24 * time.Hour)
+		t3 = t2.Add(12 * time.Hour)
+		t4 = t3.Add(24 * time.Hour)
+	)
+
+	s := Schedule{CarrierMovements: []CarrierMovement{
+		{DepartureLocation: DEHAM, ArrivalLocation: SESTO, DepartureTime: t1, ArrivalTime: t2},
+		{DepartureLocation: SESTO, ArrivalLocation: FIHEL, DepartureTime: t3, ArrivalTime: t4},
+	}}
+
+	tests := []struct {
+		locode UNLocode
+		want   []CarrierMovement
+	}{
+		{SESTO, []CarrierMovement{
+			{DepartureLocation: DEHAM, ArrivalLocation: FIHEL, DepartureTime: t1, ArrivalTime: t4},
+		}},
+		{DEHAM, []CarrierMovement{
+			{DepartureLocation: SESTO, ArrivalLocation: FIHEL, DepartureTime: t3, ArrivalTime: t4},
+		}},
+		{FIHEL, []CarrierMovement{
+			{DepartureLocation: DEHAM, ArrivalLocation: SESTO, DepartureTime: t1, ArrivalTime: t2},
+		}},
+	}
+
+	for _, tt := range tests {
+		got, err := s.OmitPortCall(tt.locode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got.CarrierMovements, tt.want) {
+			t.Errorf("OmitPortCall(%s) = %v; want = %v", tt.locode, got.CarrierMovements, tt.want)
+		}
+	}
+
+	if len(s.CarrierMovements) != 2 {
+		t.Errorf("the original schedule was modified")
+	}
+
+	if _, err := s.OmitPortCall(CNHKG); err != ErrPortNotCalled {
+		t.Errorf("err = %v; want = %v", err, ErrPortNotCalled)
+	}
+}
+
+func TestSchedule_RecordActuals(t *testing.T) {
+	var (
+		t1 = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		t2 = t1.Add(24 * time.Hour)
+		t3 = t2.Add(12 * time.Hour)
+		t4 = t3.Add(24 * time.Hour)
+	)
+
+	s := Schedule{CarrierMovements: []CarrierMovement{
+		{DepartureLocation: DEHAM, ArrivalLocation: SESTO, DepartureTime: t1, ArrivalTime: t2},
+		{DepartureLocation: SESTO, ArrivalLocation: FIHEL, DepartureTime: t3, ArrivalTime: t4},
+	}}
+
+	got, err := s.RecordActuals(SESTO, t2.Add(3*time.Hour), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := got.PortCalls()
+	if d := calls[1].ArrivalDelay(); d != 3*time.Hour {
+		t.Errorf("calls[1].ArrivalDelay() = %v; want = %v", d, 3*time.Hour)
+	}
+	if !calls[1].ActualDepartureTime.IsZero() {
+		t.Errorf("calls[1].ActualDepartureTime = %v; want it unrecorded", calls[1].ActualDepartureTime)
+	}
+	if d := got.Delay(); d != 3*time.Hour {
+		t.Errorf("Delay() = %v; want = %v", d, 3*time.Hour)
+	}
+
+	got, err = got.RecordActuals(SESTO, time.Time{}, t3.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := got.Delay(); d != time.Hour {
+		t.Errorf("Delay() = %v; want = %v", d, time.Hour)
+	}
+	if d := got.PortCalls()[1].ArrivalDelay(); d != 3*time.Hour {
+		t.Errorf("the recorded arrival was lost")
+	}
+
+	if got.IsCompleted() {
+		t.Errorf("voyage should not be completed before arriving at %s", FIHEL)
+	}
+	got, err = got.RecordActuals(FIHEL, t4, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsCompleted() {
+		t.Errorf("voyage should be completed on arriving at %s", FIHEL)
+	}
+
+	if !s.CarrierMovements[0].ActualArrivalTime.IsZero() {
+		t.Errorf("the original schedule was modified")
+	}
+
+	if _, err := s.RecordActuals(CNHKG, t1, time.Time{}); err != ErrPortNotCalled {
+		t.Errorf("err = %v; want = %v", err, ErrPortNotCalled)
+	}
+}
+
+func TestSchedule_RecordActuals_Loop(t *testing.T) {
+	var (
+		t1 = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+		t2 = t1.Add(24 * time.Hour)
+		t3 = t2.Add(24 * time.Hour)
+	)
+
+	// A round trip calls at Hamburg both first and last.
+	s := Schedule{CarrierMovements: []CarrierMovement{
+		{DepartureLocation: DEHAM, ArrivalLocation: SESTO, DepartureTime: t1, ArrivalTime: t2},
+		{DepartureLocation: SESTO, ArrivalLocation: DEHAM, DepartureTime: t2, ArrivalTime: t3},
+	}}
+
+	got, err := s.RecordActuals(DEHAM, t3.Add(time.Hour), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CarrierMovements[1].ActualArrivalTime.IsZero() {
+		t.Errorf("the arrival at the end of the round trip should be recorded")
+	}
+}
@@ -0,0 +1,170 @@
+// The pages call the booking and tracking APIs of the server they are
+// served from.
+(function () {
+  'use strict';
+
+  var errorBox = document.getElementById('error');
+
+  function showError(message) {
+    errorBox.textContent = message;
+    errorBox.hidden = false;
+  }
+
+  function api(method, path, body) {
+    var init = {method: method, headers: {'Accept': 'application/json'}};
+    if (body !== undefined) {
+      init.headers['Content-Type'] = 'application/json';
+      init.body = JSON.stringify(body);
+    }
+    return fetch(path, init).then(function (resp) {
+      return resp.json().then(function (data) {
+        if (!resp.ok) {
+          throw new Error(data.error || resp.statusText);
+        }
+        return data;
+      });
+    });
+  }
+
+  function date(s) {
+    if (!s || s.indexOf('0001-01-01') === 0) {
+      return '';
+    }
+    return new Date(s).toLocaleString();
+  }
+
+  function cell(row, text) {
+    var td = document.createElement('td');
+    if (text instanceof Node) {
+      td.appendChild(text);
+    } else {
+      td.textContent = text;
+    }
+    row.appendChild(td);
+  }
+
+  function link(href, text) {
+    var a = document.createElement('a');
+    a.href = href;
+    a.textContent = text;
+    return a;
+  }
+
+  var pages = {
+    cargos: function () {
+      var body = document.querySelector('#cargos tbody');
+      api('GET', '/booking/v1/cargos').then(function (data) {
+        var cargos = data.cargos || [];
+        document.getElementById('empty').hidden = cargos.length > 0;
+        cargos.forEach(function (c) {
+          var row = document.createElement('tr');
+          cell(row, link('track?id=' + encodeURIComponent(c.tracking_id), c.tracking_id));
+          cell(row, c.origin);
+          cell(row, c.destination);
+          cell(row, date(c.arrival_deadline));
+          cell(row, c.equipment);
+          cell(row, c.transport_status);
+          cell(row, c.misrouted ? 'Misrouted' : c.routed ? 'Yes' : 'No');
+          body.appendChild(row);
+        });
+      }).catch(function (err) {
+        showError(err.message);
+      });
+    },
+
+    book: function () {
+      var form = document.getElementById('book');
+
+      // The locations are listed under "cargos" by the booking API.
+      api('GET', '/booking/v1/locations').then(function (data) {
+        var locations = data.cargos || [];
+        form.querySelectorAll('select[data-locations]').forEach(function (sel) {
+          locations.forEach(function (l) {
+            var opt = document.createElement('option');
+            opt.value = l.locode;
+            opt.textContent = l.name + ' (' + l.locode + ')';
+            sel.appendChild(opt);
+          });
+        });
+      }).catch(function (err) {
+        showError(err.message);
+      });
+
+      form.addEventListener('submit', function (e) {
+        e.preventDefault();
+        errorBox.hidden = true;
+
+        var f = form.elements;
+        api('POST', '/booking/v1/cargos', {
+          Customer: f.customer.value,
+          Origin: f.origin.value,
+          Destination: f.destination.value,
+          ArrivalDeadline: new Date(f.deadline.value).toISOString(),
+          Equipment: f.equipment.value
+        }).then(function (data) {
+          window.location.href = 'track?id=' + encodeURIComponent(data.tracking_id);
+        }).catch(function (err) {
+          showError(err.message);
+        });
+      });
+    },
+
+    track: function () {
+      var form = document.getElementById('track');
+
+      function track(id) {
+        errorBox.hidden = true;
+        document.getElementById('cargo').hidden = true;
+
+        api('GET', '/tracking/v1/cargos/' + encodeURIComponent(id)).then(function (data) {
+          var c = data.cargo;
+          document.getElementById('status').textContent = c.status_text;
+          document.getElementById('origin').textContent = c.origin;
+          document.getElementById('destination').textContent = c.destination;
+          document.getElementById('eta').textContent = date(c.eta);
+          document.getElementById('deadline').textContent = date(c.arrival_deadline);
+          document.getElementById('next').textContent = c.next_expected_activity;
+
+          var warnings = document.getElementById('warnings');
+          warnings.textContent = '';
+          (c.warnings || []).forEach(function (w) {
+            var li = document.createElement('li');
+            li.textContent = w;
+            warnings.appendChild(li);
+          });
+
+          var events = document.getElementById('events');
+          events.textContent = '';
+          (c.events || []).forEach(function (ev) {
+            var li = document.createElement('li');
+            li.textContent = ev.description;
+            if (ev.expected) {
+              li.className = 'expected';
+            }
+            events.appendChild(li);
+          });
+
+          document.getElementById('cargo').hidden = false;
+        }).catch(function (err) {
+          showError(err.message);
+        });
+      }
+
+      form.addEventListener('submit', function (e) {
+        e.preventDefault();
+        var id = form.elements.id.value.trim();
+        history.replaceState(null, '', 'track?id=' + encodeURIComponent(id));
+        track(id);
+      });
+
+      if (form.elements.id.value) {
+        track(form.elements.id.value);
+      }
+    }
+  };
+
+  var page = pages[document.body.dataset.page];
+  if (page) {
+    page();
+  }
+})();
@@ -0,0 +1,115 @@
+// Package webui provides a minimal web interface for booking, listing and
+// tracking cargos, so that the application runs as a self-contained demo
+// without a separate frontend. The pages and their assets are embedded in
+// the binary, and call the booking and tracking APIs from the browser.
+package webui
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	shipping "github.com/marcusolsson/goddd"
+)
+
+//go:embed templates/*.html
+var templates embed.FS
+
+//go:embed static
+var static embed.FS
+
+// page is a page of the interface, rendered within the layout.
+type page struct {
+	Name  string
+	Title string
+	tmpl  *template.Template
+}
+
+// pageData is what pages are rendered with.
+type pageData struct {
+	Page       string
+	Title      string
+	Equipment  []string
+	TrackingID string
+}
+
+// equipment lists the equipment cargos may be booked in, the default first.
+var equipment = []shipping.EquipmentType{
+	shipping.Container20,
+	shipping.Container40,
+	shipping.Reefer,
+	shipping.FlatRack,
+}
+
+type handler struct {
+	pages  map[string]*page
+	static http.Handler
+}
+
+// New returns a handler serving the interface. Pages link to one another
+// and to their assets relatively, so the handler may be mounted at any
+// path, e.g. with http.StripPrefix.
+func New() http.Handler {
+	h := &handler{
+		pages: make(map[string]*page),
+	}
+
+	for path, p := range map[string]*page{
+		"/":      {Name: "cargos", Title: "Cargos"},
+		"/book":  {Name: "book", Title: "Book cargo"},
+		"/track": {Name: "track", Title: "Track cargo"},
+	} {
+		p.tmpl = template.Must(template.ParseFS(templates, "templates/layout.html", "templates/"+p.Name+".html"))
+		h.pages[path] = p
+	}
+
+	sub, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	h.static = http.StripPrefix("/static/", http.FileServer(http.FS(sub)))
+
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/static/") {
+		h.static.ServeHTTP(w, r)
+		return
+	}
+
+	p, ok := h.pages[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	data := pageData{
+		Page:       p.Name,
+		Title:      p.Title,
+		TrackingID: r.URL.Query().Get("id"),
+	}
+	for _, e := range equipment {
+		data.Equipment = append(data.Equipment, e.String())
+	}
+
+	// Render before writing, so that a failing template does not leave a
+	// half-written page behind.
+	var buf bytes.Buffer
+	if err := p.tmpl.ExecuteTemplate(&buf, "layout", data); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
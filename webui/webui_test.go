@@ -0,0 +1,70 @@
+package webui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPages(t *testing.T) {
+	h := New()
+
+	for _, tt := range []struct {
+		path string
+		want string
+	}{
+		{"/", `data-page="cargos"`},
+		{"/book", `<option>Reefer</option>`},
+		{"/track?id=ABC123", `value="ABC123"`},
+		{"/track?id=%22%3E%3Cscript%3E", `value="&#34;&gt;&lt;script&gt;"`},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET %s: status = %d; want = %d", tt.path, rec.Code, http.StatusOK)
+			continue
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+			t.Errorf("GET %s: Content-Type = %q", tt.path, ct)
+		}
+		if !strings.Contains(rec.Body.String(), tt.want) {
+			t.Errorf("GET %s: body does not contain %s", tt.path, tt.want)
+		}
+	}
+}
+
+func TestStatic(t *testing.T) {
+	h := New()
+
+	for _, path := range []string{"/static/app.js", "/static/style.css"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+
+		if rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+			t.Errorf("GET %s: status = %d, %d bytes", path, rec.Code, rec.Body.Len())
+		}
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	h := New()
+
+	for _, tt := range []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{"GET", "/nope", http.StatusNotFound},
+		{"GET", "/static/nope.js", http.StatusNotFound},
+		{"POST", "/book", http.StatusMethodNotAllowed},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if rec.Code != tt.want {
+			t.Errorf("%s %s: status = %d; want = %d", tt.method, tt.path, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,544 @@
+package shipping
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+)
+
+// The canonical wire encodings of itineraries, legs, route specifications and
+// handling activities are defined here rather than derived from the layout of
+// the structs, so that renaming or adding a field does not silently change
+// what the routing integration, event payloads and API responses exchange.
+//
+// In JSON, field names are explicit and zero values are omitted, except for
+// the location fields, the fields of a leg that the API has always had and
+// the legs of an itinerary, which are always present. Times are encoded in
+// UTC with RFC 3339 and nanosecond precision. A zero time is never encoded as
+// year 1: it is null in a leg, and an absent field elsewhere. Unknown fields
+// are ignored when decoding.
+//
+// The protobuf encodings follow the messages in wire.proto. Zero values,
+// including zero times, are not written, as in proto3. Unknown fields are
+// skipped when decoding.
+
+// ErrInvalidEncoding is used when decoding a malformed wire encoding.
+var ErrInvalidEncoding = errors.New("invalid encoding")
+
+// wireTime returns the JSON encoding of a time, or nil if it is zero.
+func wireTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// fromWireTime returns the time a JSON encoded time decodes to.
+func fromWireTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
+
+type legJSON struct {
+	VoyageNumber     VoyageNumber `json:"voyage_number"`
+	LoadLocation     UNLocode     `json:"from"`
+	UnloadLocation   UNLocode     `json:"to"`
+	LoadTime         *time.Time   `json:"load_time"`
+	UnloadTime       *time.Time   `json:"unload_time"`
+	Status           LegStatus    `json:"status,omitempty"`
+	CarrierReference string       `json:"carrier_reference,omitempty"`
+}
+
+// MarshalJSON returns the canonical JSON encoding of the leg.
+func (l Leg) MarshalJSON() ([]byte, error) {
+	return json.Marshal(legJSON{
+		VoyageNumber:     l.VoyageNumber,
+		LoadLocation:     l.LoadLocation,
+		UnloadLocation:   l.UnloadLocation,
+		LoadTime:         wireTime(l.LoadTime),
+		UnloadTime:       wireTime(l.UnloadTime),
+		Status:           l.Status,
+		CarrierReference: l.CarrierReference,
+	})
+}
+
+// UnmarshalJSON decodes the canonical JSON encoding of a leg.
+func (l *Leg) UnmarshalJSON(b []byte) error {
+	var w legJSON
+	if err := json.Unmarshal(b, &w); err != nil {
+		return err
+	}
+	*l = Leg{
+		VoyageNumber:     w.VoyageNumber,
+		LoadLocation:     w.LoadLocation,
+		UnloadLocation:   w.UnloadLocation,
+		LoadTime:         fromWireTime(w.LoadTime),
+		UnloadTime:       fromWireTime(w.UnloadTime),
+		Status:           w.Status,
+		CarrierReference: w.CarrierReference,
+	}
+	return nil
+}
+
+type itineraryJSON struct {
+	Legs      []Leg `json:"legs"`
+	Disrupted bool  `json:"disrupted,omitempty"`
+}
+
+// MarshalJSON returns the canonical JSON encoding of the itinerary. An
+// itinerary without legs is encoded with an empty list of legs.
+func (i Itinerary) MarshalJSON() ([]byte, error) {
+	legs := i.Legs
+	if legs == nil {
+		legs = []Leg{}
+	}
+	return json.Marshal(itineraryJSON{Legs: legs, Disrupted: i.Disrupted})
+}
+
+// UnmarshalJSON decodes the canonical JSON encoding of an itinerary. An empty
+// list of legs decodes to an itinerary without legs, i.e. with nil Legs.
+func (i *Itinerary) UnmarshalJSON(b []byte) error {
+	var w itineraryJSON
+	if err := json.Unmarshal(b, &w); err != nil {
+		return err
+	}
+	if len(w.Legs) == 0 {
+		w.Legs = nil
+	}
+	*i = Itinerary{Legs: w.Legs, Disrupted: w.Disrupted}
+	return nil
+}
+
+type routeSpecificationJSON struct {
+	Origin          UNLocode   `json:"origin"`
+	Destination     UNLocode   `json:"destination"`
+	ArrivalDeadline *time.Time `json:"arrival_deadline,omitempty"`
+	DeadlineType    string     `json:"deadline_type,omitempty"`
+}
+
+// MarshalJSON returns the canonical JSON encoding of the route
+// specification.
+func (s RouteSpecification) MarshalJSON() ([]byte, error) {
+	w := routeSpecificationJSON{
+		Origin:          s.Origin,
+		Destination:     s.Destination,
+		ArrivalDeadline: wireTime(s.ArrivalDeadline),
+	}
+	if s.DeadlineType != SoftDeadline {
+		w.DeadlineType = s.DeadlineType.String()
+	}
+	return json.Marshal(w)
+}
+
+// UnmarshalJSON decodes the canonical JSON encoding of a route
+// specification.
+func (s *RouteSpecification) UnmarshalJSON(b []byte) error {
+	var w routeSpecificationJSON
+	if err := json.Unmarshal(b, &w); err != nil {
+		return err
+	}
+	result := RouteSpecification{
+		Origin:          w.Origin,
+		Destination:     w.Destination,
+		ArrivalDeadline: fromWireTime(w.ArrivalDeadline),
+	}
+	if w.DeadlineType != "" {
+		t, err := ParseDeadlineType(w.DeadlineType)
+		if err != nil {
+			return err
+		}
+		result.DeadlineType = t
+	}
+	*s = result
+	return nil
+}
+
+type handlingActivityJSON struct {
+	Type         string       `json:"type,omitempty"`
+	Location     UNLocode     `json:"location"`
+	VoyageNumber VoyageNumber `json:"voyage_number,omitempty"`
+}
+
+// MarshalJSON returns the canonical JSON encoding of the handling activity.
+// The type is encoded by name, e.g. "Load", and left out if not handled.
+func (a HandlingActivity) MarshalJSON() ([]byte, error) {
+	w := handlingActivityJSON{
+		Location:     a.Location,
+		VoyageNumber: a.VoyageNumber,
+	}
+	if a.Type != NotHandled {
+		w.Type = a.Type.String()
+	}
+	return json.Marshal(w)
+}
+
+// UnmarshalJSON decodes the canonical JSON encoding of a handling activity.
+// Type names are matched ignoring case.
+func (a *HandlingActivity) UnmarshalJSON(b []byte) error {
+	var w handlingActivityJSON
+	if err := json.Unmarshal(b, &w); err != nil {
+		return err
+	}
+	t := NotHandled
+	if w.Type != "" {
+		var err error
+		if t, err = ParseHandlingEventType(w.Type); err != nil {
+			return err
+		}
+	}
+	*a = HandlingActivity{
+		Type:         t,
+		Location:     w.Location,
+		VoyageNumber: w.VoyageNumber,
+	}
+	return nil
+}
+
+// MarshalProto returns the protobuf encoding of the leg, as the Leg message.
+func (l Leg) MarshalProto() []byte {
+	var e protoEncoder
+	l.encodeProto(&e)
+	return e.b
+}
+
+func (l Leg) encodeProto(e *protoEncoder) {
+	e.string(1, string(l.VoyageNumber))
+	e.string(2, string(l.LoadLocation))
+	e.string(3, string(l.UnloadLocation))
+	e.time(4, l.LoadTime)
+	e.time(5, l.UnloadTime)
+	e.string(6, string(l.Status))
+	e.string(7, l.CarrierReference)
+}
+
+// UnmarshalProto decodes the protobuf encoding of a leg.
+func (l *Leg) UnmarshalProto(b []byte) error {
+	var result Leg
+	err := decodeProto(b, func(field int, d *protoDecoder) error {
+		switch field {
+		case 1:
+			return d.string((*string)(&result.VoyageNumber))
+		case 2:
+			return d.string((*string)(&result.LoadLocation))
+		case 3:
+			return d.string((*string)(&result.UnloadLocation))
+		case 4:
+			return d.time(&result.LoadTime)
+		case 5:
+			return d.time(&result.UnloadTime)
+		case 6:
+			return d.string((*string)(&result.Status))
+		case 7:
+			return d.string(&result.CarrierReference)
+		}
+		return d.skip()
+	})
+	if err != nil {
+		return err
+	}
+	*l = result
+	return nil
+}
+
+// MarshalProto returns the protobuf encoding of the itinerary, as the
+// Itinerary message.
+func (i Itinerary) MarshalProto() []byte {
+	var e protoEncoder
+	for _, l := range i.Legs {
+		var le protoEncoder
+		l.encodeProto(&le)
+		e.message(1, le.b)
+	}
+	e.bool(2, i.Disrupted)
+	return e.b
+}
+
+// UnmarshalProto decodes the protobuf encoding of an itinerary.
+func (i *Itinerary) UnmarshalProto(b []byte) error {
+	var result Itinerary
+	err := decodeProto(b, func(field int, d *protoDecoder) error {
+		switch field {
+		case 1:
+			msg, err := d.bytes()
+			if err != nil {
+				return err
+			}
+			var l Leg
+			if err := l.UnmarshalProto(msg); err != nil {
+				return err
+			}
+			result.Legs = append(result.Legs, l)
+			return nil
+		case 2:
+			return d.bool(&result.Disrupted)
+		}
+		return d.skip()
+	})
+	if err != nil {
+		return err
+	}
+	*i = result
+	return nil
+}
+
+// MarshalProto returns the protobuf encoding of the route specification, as
+// the RouteSpecification message.
+func (s RouteSpecification) MarshalProto() []byte {
+	var e protoEncoder
+	e.string(1, string(s.Origin))
+	e.string(2, string(s.Destination))
+	e.time(3, s.ArrivalDeadline)
+	e.varint(4, uint64(s.DeadlineType))
+	return e.b
+}
+
+// UnmarshalProto decodes the protobuf encoding of a route specification.
+func (s *RouteSpecification) UnmarshalProto(b []byte) error {
+	var result RouteSpecification
+	err := decodeProto(b, func(field int, d *protoDecoder) error {
+		switch field {
+		case 1:
+			return d.string((*string)(&result.Origin))
+		case 2:
+			return d.string((*string)(&result.Destination))
+		case 3:
+			return d.time(&result.ArrivalDeadline)
+		case 4:
+			v, err := d.varint()
+			if err != nil {
+				return err
+			}
+			t := DeadlineType(v)
+			if t.String() == "" {
+				return ErrUnknownDeadlineType
+			}
+			result.DeadlineType = t
+			return nil
+		}
+		return d.skip()
+	})
+	if err != nil {
+		return err
+	}
+	*s = result
+	return nil
+}
+
+// MarshalProto returns the protobuf encoding of the handling activity, as the
+// HandlingActivity message.
+func (a HandlingActivity) MarshalProto() []byte {
+	var e protoEncoder
+	e.varint(1, uint64(a.Type))
+	e.string(2, string(a.Location))
+	e.string(3, string(a.VoyageNumber))
+	return e.b
+}
+
+// UnmarshalProto decodes the protobuf encoding of a handling activity.
+func (a *HandlingActivity) UnmarshalProto(b []byte) error {
+	var result HandlingActivity
+	err := decodeProto(b, func(field int, d *protoDecoder) error {
+		switch field {
+		case 1:
+			v, err := d.varint()
+			if err != nil {
+				return err
+			}
+			t := HandlingEventType(v)
+			if t != NotHandled && t.String() == "" {
+				return ErrUnknownHandlingEventType
+			}
+			result.Type = t
+			return nil
+		case 2:
+			return d.string((*string)(&result.Location))
+		case 3:
+			return d.string((*string)(&result.VoyageNumber))
+		}
+		return d.skip()
+	})
+	if err != nil {
+		return err
+	}
+	*a = result
+	return nil
+}
+
+// Protobuf wire types.
+const (
+	wireVarint = 0
+	wire64Bit  = 1
+	wireBytes  = 2
+	wire32Bit  = 5
+)
+
+// protoEncoder appends fields in the protobuf wire format, leaving out zero
+// values.
+type protoEncoder struct {
+	b []byte
+}
+
+func (e *protoEncoder) tag(field, wireType int) {
+	e.b = binary.AppendUvarint(e.b, uint64(field)<<3|uint64(wireType))
+}
+
+func (e *protoEncoder) varint(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.b = binary.AppendUvarint(e.b, v)
+}
+
+func (e *protoEncoder) bool(field int, v bool) {
+	if v {
+		e.varint(field, 1)
+	}
+}
+
+func (e *protoEncoder) string(field int, s string) {
+	if s == "" {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.b = binary.AppendUvarint(e.b, uint64(len(s)))
+	e.b = append(e.b, s...)
+}
+
+// message appends an embedded message, even if it is empty, since repeated
+// messages are told apart by their count.
+func (e *protoEncoder) message(field int, msg []byte) {
+	e.tag(field, wireBytes)
+	e.b = binary.AppendUvarint(e.b, uint64(len(msg)))
+	e.b = append(e.b, msg...)
+}
+
+// time appends a time as a google.protobuf.Timestamp.
+func (e *protoEncoder) time(field int, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	var ts protoEncoder
+	ts.varint(1, uint64(t.Unix()))
+	ts.varint(2, uint64(t.Nanosecond()))
+	e.message(field, ts.b)
+}
+
+// protoDecoder reads the value of a field in the protobuf wire format.
+type protoDecoder struct {
+	b        []byte
+	wireType int
+}
+
+// decodeProto calls fn with the number of each field in a message, for it to
+// read the value of the field from the decoder.
+func decodeProto(b []byte, fn func(field int, d *protoDecoder) error) error {
+	d := &protoDecoder{b: b}
+	for len(d.b) > 0 {
+		key, n := binary.Uvarint(d.b)
+		if n <= 0 || key>>3 == 0 || key>>3 > math.MaxInt32 {
+			return ErrInvalidEncoding
+		}
+		d.b = d.b[n:]
+		d.wireType = int(key & 7)
+		if err := fn(int(key>>3), d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *protoDecoder) varint() (uint64, error) {
+	if d.wireType != wireVarint {
+		return 0, ErrInvalidEncoding
+	}
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		return 0, ErrInvalidEncoding
+	}
+	d.b = d.b[n:]
+	return v, nil
+}
+
+func (d *protoDecoder) bool(v *bool) error {
+	u, err := d.varint()
+	*v = u != 0
+	return err
+}
+
+func (d *protoDecoder) bytes() ([]byte, error) {
+	if d.wireType != wireBytes {
+		return nil, ErrInvalidEncoding
+	}
+	l, n := binary.Uvarint(d.b)
+	if n <= 0 || l > uint64(len(d.b)-n) {
+		return nil, ErrInvalidEncoding
+	}
+	v := d.b[n : n+int(l)]
+	d.b = d.b[n+int(l):]
+	return v, nil
+}
+
+func (d *protoDecoder) string(s *string) error {
+	b, err := d.bytes()
+	*s = string(b)
+	return err
+}
+
+// time reads a google.protobuf.Timestamp. Times are decoded in UTC.
+func (d *protoDecoder) time(t *time.Time) error {
+	msg, err := d.bytes()
+	if err != nil {
+		return err
+	}
+	var sec, nsec uint64
+	err = decodeProto(msg, func(field int, d *protoDecoder) error {
+		var err error
+		switch field {
+		case 1:
+			sec, err = d.varint()
+		case 2:
+			nsec, err = d.varint()
+		default:
+			err = d.skip()
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if nsec >= uint64(time.Second) {
+		return ErrInvalidEncoding
+	}
+	*t = time.Unix(int64(sec), int64(nsec)).UTC()
+	return nil
+}
+
+// skip skips the value of an unknown field.
+func (d *protoDecoder) skip() error {
+	var n int
+	switch d.wireType {
+	case wireVarint:
+		_, err := d.varint()
+		return err
+	case wireBytes:
+		_, err := d.bytes()
+		return err
+	case wire64Bit:
+		n = 8
+	case wire32Bit:
+		n = 4
+	default:
+		return ErrInvalidEncoding
+	}
+	if len(d.b) < n {
+		return ErrInvalidEncoding
+	}
+	d.b = d.b[n:]
+	return nil
+}
@@ -0,0 +1,149 @@
+package shipping
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	wireLoad   = time.Date(2016, 3, 6, 18, 12, 11, 15796120, time.UTC)
+	wireUnload = time.Date(2016, 3, 8, 2, 13, 11, 0, time.UTC)
+)
+
+func TestLeg_JSON(t *testing.T) {
+	l := Leg{
+		VoyageNumber:   "V100",
+		LoadLocation:   CNHKG,
+		UnloadLocation: SESTO,
+		LoadTime:       wireLoad.In(time.FixedZone("HKT", 8*60*60)),
+		UnloadTime:     wireUnload,
+		Status:         LegConfirmed,
+	}
+
+	b, err := json.Marshal(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"voyage_number":"V100","from":"CNHKG","to":"SESTO","load_time":"2016-03-06T18:12:11.01579612Z","unload_time":"2016-03-08T02:13:11Z","status":"confirmed"}`
+	if string(b) != want {
+		t.Errorf("json = %s; want = %s", b, want)
+	}
+
+	var got Leg
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.LoadTime.Equal(l.LoadTime) || got.LoadTime.Location() != time.UTC {
+		t.Errorf("LoadTime = %v; want = %v in UTC", got.LoadTime, l.LoadTime)
+	}
+	l.LoadTime = l.LoadTime.UTC()
+	if !reflect.DeepEqual(got, l) {
+		t.Errorf("got = %+v; want = %+v", got, l)
+	}
+}
+
+func TestZeroValues_JSON(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{Leg{}, `{"voyage_number":"","from":"","to":"","load_time":null,"unload_time":null}`},
+		{Itinerary{}, `{"legs":[]}`},
+		{RouteSpecification{}, `{"origin":"","destination":""}`},
+		{HandlingActivity{}, `{"location":""}`},
+		{HandlingActivity{Type: Receive, Location: SESTO}, `{"type":"Receive","location":"SESTO"}`},
+	}
+
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.want {
+			t.Errorf("json(%#v) = %s; want = %s", tt.in, b, tt.want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		in  interface{}
+		out interface{}
+	}{
+		{Itinerary{
+			Legs: []Leg{
+				NewLeg("V100", CNHKG, JNTKO, wireLoad, wireUnload),
+				{VoyageNumber: "V200", LoadLocation: JNTKO, UnloadLocation: SESTO, Status: LegPending, CarrierReference: "REF-1"},
+			},
+			Disrupted: true,
+		}, &Itinerary{}},
+		{Itinerary{}, &Itinerary{}},
+		{RouteSpecification{Origin: CNHKG, Destination: SESTO, ArrivalDeadline: wireUnload}, &RouteSpecification{}},
+		{RouteSpecification{Origin: CNHKG, Destination: SESTO}, &RouteSpecification{}},
+		{RouteSpecification{Origin: CNHKG, Destination: SESTO, ArrivalDeadline: wireUnload, DeadlineType: HardDeadline}, &RouteSpecification{}},
+		{HandlingActivity{Type: Load, Location: CNHKG, VoyageNumber: "V100"}, &HandlingActivity{}},
+		{HandlingActivity{Type: Customs, Location: SESTO}, &HandlingActivity{}},
+		{HandlingActivity{Type: CustomActivityBase, Location: SESTO}, &HandlingActivity{}},
+		{HandlingActivity{}, &HandlingActivity{}},
+	}
+
+	type protoMarshaler interface {
+		MarshalProto() []byte
+	}
+	type protoUnmarshaler interface {
+		UnmarshalProto([]byte) error
+	}
+
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := reflect.New(reflect.TypeOf(tt.out).Elem()).Interface()
+		if err := json.Unmarshal(b, got); err != nil {
+			t.Fatalf("%s: %v", b, err)
+		}
+		if !reflect.DeepEqual(reflect.ValueOf(got).Elem().Interface(), tt.in) {
+			t.Errorf("json round trip of %+v = %+v", tt.in, got)
+		}
+
+		got = reflect.New(reflect.TypeOf(tt.out).Elem()).Interface()
+		if err := got.(protoUnmarshaler).UnmarshalProto(tt.in.(protoMarshaler).MarshalProto()); err != nil {
+			t.Fatalf("%+v: %v", tt.in, err)
+		}
+		if !reflect.DeepEqual(reflect.ValueOf(got).Elem().Interface(), tt.in) {
+			t.Errorf("proto round trip of %+v = %+v", tt.in, got)
+		}
+	}
+}
+
+func TestHandlingActivity_Proto(t *testing.T) {
+	a := HandlingActivity{Type: Load, Location: CNHKG, VoyageNumber: "V1"}
+
+	// As encoded by protoc generated code for the HandlingActivity message.
+	want := []byte{0x08, 0x01, 0x12, 0x05, 'C', 'N', 'H', 'K', 'G', 0x1a, 0x02, 'V', '1'}
+	if got := a.MarshalProto(); !bytes.Equal(got, want) {
+		t.Errorf("proto = %x; want = %x", got, want)
+	}
+
+	// Fields added to the message later are skipped.
+	unknown := append(append([]byte{}, want...), 0x20, 0x2a, 0x2d, 1, 2, 3, 4, 0x32, 0x01, 'x')
+	var got HandlingActivity
+	if err := got.UnmarshalProto(unknown); err != nil {
+		t.Fatal(err)
+	}
+	if got != a {
+		t.Errorf("got = %+v; want = %+v", got, a)
+	}
+
+	if err := got.UnmarshalProto([]byte{0x08, 0x09}); err != ErrUnknownHandlingEventType {
+		t.Errorf("err = %v; want = %v", err, ErrUnknownHandlingEventType)
+	}
+	if err := got.UnmarshalProto([]byte{0x12, 0x05, 'C'}); err != ErrInvalidEncoding {
+		t.Errorf("err = %v; want = %v", err, ErrInvalidEncoding)
+	}
+}